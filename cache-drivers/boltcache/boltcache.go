@@ -0,0 +1,485 @@
+//Package boltcache provides cache driver uses bbolt to store cache data on disk.
+//Using go.etcd.io/bbolt as driver.
+//Cached data survives restarts,so it can be used as persistent cache in small deployments.
+package boltcache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+	bolt "go.etcd.io/bbolt"
+)
+
+var defaultBucket = "cache"
+var defaultGCPeriod = 5 * time.Minute
+var defaultGCLimit = 1000
+var defaultFileMode = os.FileMode(0600)
+
+//CounterEncoding encoding used to store counter values.
+var CounterEncoding cache.CounterEncoding = cache.BigEndianCounterEncoding{}
+
+//errStop error used to stop update transaction without writing.
+var errStop = errors.New("boltcache: stop")
+
+//encodeEntry encode value with expired time.
+//Expired time is stored as 8 bytes big endian unix nano before data.
+//Zero expired time means entry never expires.
+func encodeEntry(data []byte, ttl time.Duration) []byte {
+	bs := make([]byte, 8+len(data))
+	if ttl > 0 {
+		binary.BigEndian.PutUint64(bs[0:8], uint64(time.Now().Add(ttl).UnixNano()))
+	}
+	copy(bs[8:], data)
+	return bs
+}
+
+//decodeEntry decode stored entry.
+//Return copied data,expired time and whether entry is valid and not expired.
+func decodeEntry(bs []byte, now time.Time) ([]byte, time.Time, bool) {
+	if len(bs) < 8 {
+		return nil, time.Time{}, false
+	}
+	var expired time.Time
+	if nano := int64(binary.BigEndian.Uint64(bs[0:8])); nano != 0 {
+		expired = time.Unix(0, nano)
+		if !now.Before(expired) {
+			return nil, expired, false
+		}
+	}
+	data := make([]byte, len(bs)-8)
+	copy(data, bs[8:])
+	return data, expired, true
+}
+
+//Cache The bbolt cache Driver.
+type Cache struct {
+	cache.DriverUtil
+	DB           *bolt.DB
+	bucket       []byte
+	ticker       *time.Ticker
+	quit         chan int
+	gcErrHandler func(err error)
+	gcLimit      int
+}
+
+//SetGCErrHandler Set callback to handler error raised when gc.
+func (c *Cache) SetGCErrHandler(f func(err error)) {
+	c.gcErrHandler = f
+	return
+}
+
+func (c *Cache) get(tx *bolt.Tx, key string) ([]byte, bool) {
+	data, _, ok := decodeEntry(tx.Bucket(c.bucket).Get([]byte(key)), time.Now())
+	return data, ok
+}
+
+func (c *Cache) put(tx *bolt.Tx, key string, data []byte, ttl time.Duration) error {
+	return tx.Bucket(c.bucket).Put([]byte(key), encodeEntry(data, ttl))
+}
+
+//gc delete no more than gc limit expired entries.
+//Return any error raised.
+func (c *Cache) gc() error {
+	now := time.Now()
+	keys := [][]byte{}
+	err := c.DB.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(c.bucket).Cursor()
+		for k, v := cursor.First(); k != nil && len(keys) < c.gcLimit; k, v = cursor.Next() {
+			if _, _, ok := decodeEntry(v, now); !ok {
+				key := make([]byte, len(k))
+				copy(key, k)
+				keys = append(keys, key)
+			}
+		}
+		return nil
+	})
+	if err != nil || len(keys) == 0 {
+		return err
+	}
+	return c.DB.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(c.bucket)
+		now := time.Now()
+		for _, k := range keys {
+			if _, _, ok := decodeEntry(b.Get(k), now); ok {
+				continue
+			}
+			err := b.Delete(k)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+//SetBytesValue Set bytes data to cache by given key.
+//Return any error raised.
+func (c *Cache) SetBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	return c.DB.Update(func(tx *bolt.Tx) error {
+		return c.put(tx, key, bytes, ttl)
+	})
+}
+
+//UpdateBytesValue Update bytes data to cache by given key only if the cache exist.
+//Return any error raised.
+func (c *Cache) UpdateBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	return c.DB.Update(func(tx *bolt.Tx) error {
+		if _, ok := c.get(tx, key); !ok {
+			return nil
+		}
+		return c.put(tx, key, bytes, ttl)
+	})
+}
+
+//SetIfNotExists Set bytes data to cache by given key only if the key does not exist.
+//Return whether data is set and any error raised.
+func (c *Cache) SetIfNotExists(key string, bytes []byte, ttl time.Duration) (bool, error) {
+	var result bool
+	err := c.DB.Update(func(tx *bolt.Tx) error {
+		if _, ok := c.get(tx, key); ok {
+			return nil
+		}
+		result = true
+		return c.put(tx, key, bytes, ttl)
+	})
+	return result, err
+}
+
+//SetIfVersion Set bytes data to cache by given key only if current value version equals given version.
+//Empty version means key should not exist.
+//Return whether data is set and any error raised.
+func (c *Cache) SetIfVersion(key string, bytes []byte, version string, ttl time.Duration) (bool, error) {
+	var result bool
+	err := c.DB.Update(func(tx *bolt.Tx) error {
+		data, ok := c.get(tx, key)
+		if ok {
+			if version != cache.ValueVersion(data) {
+				return nil
+			}
+		} else if version != "" {
+			return nil
+		}
+		result = true
+		return c.put(tx, key, bytes, ttl)
+	})
+	return result, err
+}
+
+//GetBytesValue Get bytes data from cache by given key.
+//Return data bytes and any error raised.
+func (c *Cache) GetBytesValue(key string) ([]byte, error) {
+	var data []byte
+	var ok bool
+	err := c.DB.View(func(tx *bolt.Tx) error {
+		data, ok = c.get(tx, key)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, cache.ErrNotFound
+	}
+	return data, nil
+}
+
+//MGetBytesValue get multiple bytes data from cache by given keys.
+//Return data bytes map and any error if raised.
+func (c *Cache) MGetBytesValue(keys ...string) (map[string][]byte, error) {
+	var result = make(map[string][]byte, len(keys))
+	err := c.DB.View(func(tx *bolt.Tx) error {
+		for _, k := range keys {
+			if data, ok := c.get(tx, k); ok {
+				result[k] = data
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+//MSetBytesValue set multiple bytes data to cache with given key-value map in one transaction.
+//Return  any error if raised.
+func (c *Cache) MSetBytesValue(data map[string][]byte, ttl time.Duration) error {
+	return c.DB.Update(func(tx *bolt.Tx) error {
+		for k := range data {
+			err := c.put(tx, k, data[k], ttl)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+//Capabilities return capabilities supported by cache.
+func (c *Cache) Capabilities() cache.Capabilities {
+	return cache.CapabilityMGet | cache.CapabilityCounter | cache.CapabilityFlush | cache.CapabilityTTL | cache.CapabilityNX | cache.CapabilityCAS | cache.CapabilityIteration | cache.CapabilityRename
+}
+
+//Keys list keys with given prefix from given cursor.
+//Keys are returned in lexical order,and cursor is the last key returned.
+//Return keys,next cursor and any error raised.
+//Empty next cursor means iteration finished.
+func (c *Cache) Keys(prefix string, cursor string, count int) ([]string, string, error) {
+	keys := []string{}
+	var next string
+	err := c.DB.View(func(tx *bolt.Tx) error {
+		now := time.Now()
+		p := []byte(prefix)
+		start := p
+		if cursor > prefix {
+			start = []byte(cursor)
+		}
+		bc := tx.Bucket(c.bucket).Cursor()
+		for k, v := bc.Seek(start); k != nil && bytes.HasPrefix(k, p); k, v = bc.Next() {
+			if string(k) <= cursor {
+				continue
+			}
+			if _, _, ok := decodeEntry(v, now); !ok {
+				continue
+			}
+			if count > 0 && len(keys) == count {
+				next = keys[count-1]
+				return nil
+			}
+			keys = append(keys, string(k))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return keys, next, nil
+}
+
+//Flush Delete all data in cache.
+//Return any error if raised
+func (c *Cache) Flush() error {
+	return c.DB.Update(func(tx *bolt.Tx) error {
+		err := tx.DeleteBucket(c.bucket)
+		if err != nil && err != bolt.ErrBucketNotFound {
+			return err
+		}
+		_, err = tx.CreateBucket(c.bucket)
+		return err
+	})
+}
+
+//Close Close cache.
+//Return any error if raised
+func (c *Cache) Close() error {
+	close(c.quit)
+	return c.DB.Close()
+}
+
+//Del Delete data in cache by given key.
+//Return any error raised.
+func (c *Cache) Del(key string) error {
+	return c.DB.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(c.bucket).Delete([]byte(key))
+	})
+}
+
+//Rename move value of old key to new key in one transaction.
+//Remaining ttl of old key is kept.
+//Return cache.ErrNotFound if old key not exists.
+func (c *Cache) Rename(oldKey string, newKey string) error {
+	err := c.DB.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(c.bucket)
+		v := b.Get([]byte(oldKey))
+		if _, _, ok := decodeEntry(v, time.Now()); !ok {
+			return errStop
+		}
+		entry := make([]byte, len(v))
+		copy(entry, v)
+		err := b.Delete([]byte(oldKey))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(newKey), entry)
+	})
+	if err == errStop {
+		return cache.ErrNotFound
+	}
+	return err
+}
+
+//IncrCounter Increase int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Return int data value and any error raised.
+func (c *Cache) IncrCounter(key string, increment int64, ttl time.Duration) (int64, error) {
+	var v int64
+	err := c.DB.Update(func(tx *bolt.Tx) error {
+		data, ok := c.get(tx, key)
+		if ok {
+			current, err := CounterEncoding.DecodeCounter(data)
+			if err == nil {
+				v = current
+			}
+		}
+		v = v + increment
+		return c.put(tx, key, CounterEncoding.EncodeCounter(v), ttl)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return v, nil
+}
+
+//SetCounter Set int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Return any error raised.
+func (c *Cache) SetCounter(key string, v int64, ttl time.Duration) error {
+	return c.SetBytesValue(key, CounterEncoding.EncodeCounter(v), ttl)
+}
+
+//GetCounter Get int val from cache by given key.Count cache and data cache are in two independent namespace.
+//Return int data value and any error raised.
+func (c *Cache) GetCounter(key string) (int64, error) {
+	data, err := c.GetBytesValue(key)
+	if err != nil {
+		return 0, err
+	}
+	v, err := CounterEncoding.DecodeCounter(data)
+	if err != nil {
+		return 0, cache.ErrNotFound
+	}
+	return v, nil
+}
+
+//DelCounter Delete int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Return any error raised.
+func (c *Cache) DelCounter(key string) error {
+	return c.Del(key)
+}
+
+//Expire set cache value expire duration by given key and ttl
+func (c *Cache) Expire(key string, ttl time.Duration) error {
+	err := c.DB.Update(func(tx *bolt.Tx) error {
+		data, ok := c.get(tx, key)
+		if !ok {
+			return errStop
+		}
+		return c.put(tx, key, data, ttl)
+	})
+	if err == errStop {
+		return cache.ErrNotFound
+	}
+	return err
+}
+
+//ExpireCounter set cache counter  expire duration by given key and ttl
+func (c *Cache) ExpireCounter(key string, ttl time.Duration) error {
+	return c.Expire(key, ttl)
+}
+
+//GetTTL get remaining ttl of given key.
+//Return ttl and any error raised.
+//Zero ttl means entry never expires.
+func (c *Cache) GetTTL(key string) (time.Duration, error) {
+	var expired time.Time
+	var ok bool
+	now := time.Now()
+	err := c.DB.View(func(tx *bolt.Tx) error {
+		_, expired, ok = decodeEntry(tx.Bucket(c.bucket).Get([]byte(key)), now)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, cache.ErrNotFound
+	}
+	if expired.IsZero() {
+		return 0, nil
+	}
+	return expired.Sub(now), nil
+}
+
+//Config Cache driver config.
+type Config struct {
+	//Path database file path.
+	Path string
+	//Bucket bucket name which stores cache data.Default value is "cache".
+	Bucket string
+	//TimeoutInSecond timeout waiting file lock when opening database.
+	//Waiting is not limited if not positive.
+	TimeoutInSecond int64
+	//NoSync whether skip fsync after every commit.
+	//Write performance is improved,but recent data may be lost when system crashed.
+	NoSync bool
+	//GCPeriodInSecond period of gc.Default value is 5 minute.
+	GCPeriodInSecond int64
+	//GCLimit max delete limit in every gc call.Default value is 1000.
+	GCLimit int
+}
+
+//Create create new cache driver.
+//Return driver created and any error if raised.
+func (config *Config) Create() (cache.Driver, error) {
+	db, err := bolt.Open(config.Path, defaultFileMode, &bolt.Options{
+		Timeout: time.Duration(config.TimeoutInSecond) * time.Second,
+		NoSync:  config.NoSync,
+	})
+	if err != nil {
+		return nil, err
+	}
+	bucket := config.Bucket
+	if bucket == "" {
+		bucket = defaultBucket
+	}
+	c := &Cache{
+		DB:      db,
+		bucket:  []byte(bucket),
+		quit:    make(chan int),
+		gcLimit: config.GCLimit,
+	}
+	if c.gcLimit <= 0 {
+		c.gcLimit = defaultGCLimit
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(c.bucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	period := time.Duration(config.GCPeriodInSecond) * time.Second
+	if period <= 0 {
+		period = defaultGCPeriod
+	}
+	c.ticker = time.NewTicker(period)
+	go func() {
+		for {
+			select {
+			case <-c.ticker.C:
+				err := c.gc()
+				if err != nil && c.gcErrHandler != nil {
+					c.gcErrHandler(err)
+				}
+			case <-c.quit:
+				c.ticker.Stop()
+				return
+			}
+		}
+	}()
+	return c, nil
+}
+
+func init() {
+	cache.Register("boltcache", func(loader func(interface{}) error) (cache.Driver, error) {
+		c := &Config{}
+		err := loader(c)
+		if err != nil {
+			return nil, err
+		}
+		return c.Create()
+	})
+}
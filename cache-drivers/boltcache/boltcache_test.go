@@ -0,0 +1,99 @@
+package boltcache
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+func newTestCache(t *testing.T, path string) *cache.Cache {
+	c := cache.New()
+	oc := cache.NewOptionConfig()
+	oc.Driver = "boltcache"
+	oc.TTL = 3600
+	oc.Config = func(v interface{}) error {
+		v.(*Config).Path = path
+		return nil
+	}
+	oc.Marshaler = "json"
+	err := c.Init(oc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestBoltCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boltcache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cache.db")
+	c := newTestCache(t, path)
+	err = c.Set("test", "value", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.Set("expired", "value", time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.IncrCounter("counter", 2, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := c.IncrCounter("counter", 3, time.Hour)
+	if err != nil || v != 5 {
+		t.Fatal(v, err)
+	}
+	ok, err := c.SetIfNotExists("test", []byte("new"), time.Hour)
+	if ok || err != nil {
+		t.Fatal(ok, err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	var result string
+	err = c.Get("expired", &result)
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	err = c.Driver.(*Cache).gc()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, next, err := c.Driver.(*Cache).Keys("", "", 0)
+	if err != nil || next != "" || len(keys) != 2 {
+		t.Fatal(keys, next, err)
+	}
+	err = c.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c = newTestCache(t, path)
+	defer c.Close()
+	err = c.Get("test", &result)
+	if err != nil || result != "value" {
+		t.Fatal(result, err)
+	}
+	v, err = c.GetCounter("counter")
+	if err != nil || v != 5 {
+		t.Fatal(v, err)
+	}
+	ttl, err := c.GetTTL("test")
+	if err != nil || ttl <= 0 || ttl > time.Hour {
+		t.Fatal(ttl, err)
+	}
+	err = c.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.Get("test", &result)
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+}
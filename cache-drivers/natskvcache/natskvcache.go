@@ -0,0 +1,468 @@
+//Package natskvcache provides cache driver uses NATS JetStream key-value bucket to store cache data.
+//Using github.com/nats-io/nats.go as driver.
+//It can be used as shared cache by services already running NATS without deploying redis.
+package natskvcache
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+	"github.com/nats-io/nats.go"
+)
+
+var defaultBucket = "cache"
+
+//maxRetries max times to retry optimistic update when entry is changed by others.
+var maxRetries = 10
+
+//CounterEncoding encoding used to store counter values.
+var CounterEncoding cache.CounterEncoding = cache.BigEndianCounterEncoding{}
+
+//ErrConflict raised when entry is changed by others too many times during optimistic update.
+var ErrConflict = errors.New("natskvcache: too many conflicts")
+
+//encodeKey encode cache key to key-value bucket key.
+//Bucket keys only allow limited chars,so cache keys are encoded by url safe base64.
+func encodeKey(key string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+func decodeKey(key string) (string, error) {
+	bs, err := base64.RawURLEncoding.DecodeString(key)
+	if err != nil {
+		return "", err
+	}
+	return string(bs), nil
+}
+
+//encodeEntry encode value with expired time.
+//Expired time is stored as 8 bytes big endian unix nano before data.
+//Zero expired time means entry never expires before bucket ttl.
+func encodeEntry(data []byte, ttl time.Duration) []byte {
+	bs := make([]byte, 8+len(data))
+	if ttl > 0 {
+		binary.BigEndian.PutUint64(bs[0:8], uint64(time.Now().Add(ttl).UnixNano()))
+	}
+	copy(bs[8:], data)
+	return bs
+}
+
+//decodeEntry decode stored entry.
+//Return data,expired time and whether entry is valid and not expired.
+func decodeEntry(bs []byte, now time.Time) ([]byte, time.Time, bool) {
+	if len(bs) < 8 {
+		return nil, time.Time{}, false
+	}
+	var expired time.Time
+	if nano := int64(binary.BigEndian.Uint64(bs[0:8])); nano != 0 {
+		expired = time.Unix(0, nano)
+		if !now.Before(expired) {
+			return nil, expired, false
+		}
+	}
+	return bs[8:], expired, true
+}
+
+//Cache The NATS key-value cache Driver.
+type Cache struct {
+	cache.DriverUtil
+	Conn    *nats.Conn
+	KV      nats.KeyValue
+	locker  sync.Mutex
+	watcher nats.KeyWatcher
+}
+
+//SetGCErrHandler Set callback to handler error raised when gc.
+//Entries are removed by bucket ttl.
+func (c *Cache) SetGCErrHandler(f func(err error)) {
+	return
+}
+
+//entry get entry of given key.
+//Return entry,data,expired time,whether entry exists and not expired,and any error raised.
+//Entry is nil if key not exists or deleted.
+func (c *Cache) entry(key string) (nats.KeyValueEntry, []byte, time.Time, bool, error) {
+	e, err := c.KV.Get(encodeKey(key))
+	if err != nil {
+		if errors.Is(err, nats.ErrKeyNotFound) || errors.Is(err, nats.ErrKeyDeleted) {
+			return nil, nil, time.Time{}, false, nil
+		}
+		return nil, nil, time.Time{}, false, err
+	}
+	data, expired, ok := decodeEntry(e.Value(), time.Now())
+	return e, data, expired, ok, nil
+}
+
+//swap write value to given key only if entry is not changed since read.
+//Entry created if given entry is nil.
+//Return whether value is written and any error raised.
+func (c *Cache) swap(key string, e nats.KeyValueEntry, value []byte) (bool, error) {
+	var err error
+	if e == nil {
+		_, err = c.KV.Create(encodeKey(key), value)
+	} else {
+		_, err = c.KV.Update(encodeKey(key), value, e.Revision())
+	}
+	if errors.Is(err, nats.ErrKeyExists) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+//modify modify entry of given key by optimistic update.
+//Given function is called with current data and whether entry exists,
+//and returns new entry value and whether value should be written.
+//Return any error raised.
+func (c *Cache) modify(key string, f func(data []byte, ok bool) ([]byte, bool, error)) error {
+	for i := 0; i < maxRetries; i++ {
+		e, data, _, ok, err := c.entry(key)
+		if err != nil {
+			return err
+		}
+		value, write, err := f(data, ok)
+		if err != nil || !write {
+			return err
+		}
+		written, err := c.swap(key, e, value)
+		if err != nil || written {
+			return err
+		}
+	}
+	return ErrConflict
+}
+
+//SetBytesValue Set bytes data to cache by given key.
+//Return any error raised.
+func (c *Cache) SetBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	_, err := c.KV.Put(encodeKey(key), encodeEntry(bytes, ttl))
+	return err
+}
+
+//UpdateBytesValue Update bytes data to cache by given key only if the cache exist.
+//Return any error raised.
+func (c *Cache) UpdateBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	return c.modify(key, func(data []byte, ok bool) ([]byte, bool, error) {
+		return encodeEntry(bytes, ttl), ok, nil
+	})
+}
+
+//SetIfNotExists Set bytes data to cache by given key only if the key does not exist.
+//Return whether data is set and any error raised.
+func (c *Cache) SetIfNotExists(key string, bytes []byte, ttl time.Duration) (bool, error) {
+	e, _, _, ok, err := c.entry(key)
+	if err != nil || ok {
+		return false, err
+	}
+	return c.swap(key, e, encodeEntry(bytes, ttl))
+}
+
+//SetIfVersion Set bytes data to cache by given key only if current value version equals given version.
+//Empty version means key should not exist.
+//Return whether data is set and any error raised.
+func (c *Cache) SetIfVersion(key string, bytes []byte, version string, ttl time.Duration) (bool, error) {
+	e, data, _, ok, err := c.entry(key)
+	if err != nil {
+		return false, err
+	}
+	if ok {
+		if version != cache.ValueVersion(data) {
+			return false, nil
+		}
+	} else if version != "" {
+		return false, nil
+	}
+	return c.swap(key, e, encodeEntry(bytes, ttl))
+}
+
+//GetBytesValue Get bytes data from cache by given key.
+//Return data bytes and any error raised.
+func (c *Cache) GetBytesValue(key string) ([]byte, error) {
+	_, data, _, ok, err := c.entry(key)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, cache.ErrNotFound
+	}
+	return data, nil
+}
+
+//MGetBytesValue get multiple bytes data from cache by given keys.
+//Return data bytes map and any error if raised.
+func (c *Cache) MGetBytesValue(keys ...string) (map[string][]byte, error) {
+	var result = make(map[string][]byte, len(keys))
+	for _, k := range keys {
+		_, data, _, ok, err := c.entry(k)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			result[k] = data
+		}
+	}
+	return result, nil
+}
+
+//MSetBytesValue set multiple bytes data to cache with given key-value map.
+//Return  any error if raised.
+func (c *Cache) MSetBytesValue(data map[string][]byte, ttl time.Duration) error {
+	for k := range data {
+		err := c.SetBytesValue(k, data[k], ttl)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Capabilities return capabilities supported by cache.
+func (c *Cache) Capabilities() cache.Capabilities {
+	return cache.CapabilityCounter | cache.CapabilityFlush | cache.CapabilityTTL | cache.CapabilityNX | cache.CapabilityCAS | cache.CapabilityPing
+}
+
+//Ping check if nats server is reachable before context done.
+//Return any error raised.
+func (c *Cache) Ping(ctx context.Context) error {
+	return c.Conn.FlushWithContext(ctx)
+}
+
+//OnInvalidated watch bucket and call given handler with driver key of every entry changed or deleted after watch started,
+//including changes made by this cache.
+//Local caches in front of this cache can be invalidated by handler.
+//Previous watch is stopped.
+//Return any error raised.
+func (c *Cache) OnInvalidated(handler func(key string)) error {
+	w, err := c.KV.WatchAll(nats.UpdatesOnly())
+	if err != nil {
+		return err
+	}
+	c.locker.Lock()
+	if c.watcher != nil {
+		c.watcher.Stop()
+	}
+	c.watcher = w
+	c.locker.Unlock()
+	go func() {
+		for e := range w.Updates() {
+			if e == nil {
+				continue
+			}
+			key, err := decodeKey(e.Key())
+			if err != nil {
+				continue
+			}
+			handler(key)
+		}
+	}()
+	return nil
+}
+
+//Flush Delete all data in bucket.
+//Return any error if raised
+func (c *Cache) Flush() error {
+	keys, err := c.KV.Keys()
+	if err != nil {
+		if errors.Is(err, nats.ErrNoKeysFound) {
+			return nil
+		}
+		return err
+	}
+	for _, k := range keys {
+		err = c.KV.Purge(k)
+		if err != nil && !errors.Is(err, nats.ErrKeyNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+//Close stop watching and close nats connection.
+//Return any error if raised
+func (c *Cache) Close() error {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	if c.watcher != nil {
+		c.watcher.Stop()
+		c.watcher = nil
+	}
+	c.Conn.Close()
+	return nil
+}
+
+//Del Delete data in cache by given key.
+//Return any error raised.
+func (c *Cache) Del(key string) error {
+	err := c.KV.Delete(encodeKey(key))
+	if err != nil && !errors.Is(err, nats.ErrKeyNotFound) {
+		return err
+	}
+	return nil
+}
+
+//IncrCounter Increase int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Counter is increased by optimistic update,which is atomic across processes.
+//Return int data value and any error raised.
+func (c *Cache) IncrCounter(key string, increment int64, ttl time.Duration) (int64, error) {
+	var v int64
+	err := c.modify(key, func(data []byte, ok bool) ([]byte, bool, error) {
+		v = 0
+		if ok {
+			current, err := CounterEncoding.DecodeCounter(data)
+			if err == nil {
+				v = current
+			}
+		}
+		v = v + increment
+		return encodeEntry(CounterEncoding.EncodeCounter(v), ttl), true, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return v, nil
+}
+
+//SetCounter Set int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Return any error raised.
+func (c *Cache) SetCounter(key string, v int64, ttl time.Duration) error {
+	return c.SetBytesValue(key, CounterEncoding.EncodeCounter(v), ttl)
+}
+
+//GetCounter Get int val from cache by given key.Count cache and data cache are in two independent namespace.
+//Return int data value and any error raised.
+func (c *Cache) GetCounter(key string) (int64, error) {
+	data, err := c.GetBytesValue(key)
+	if err != nil {
+		return 0, err
+	}
+	v, err := CounterEncoding.DecodeCounter(data)
+	if err != nil {
+		return 0, cache.ErrNotFound
+	}
+	return v, nil
+}
+
+//DelCounter Delete int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Return any error raised.
+func (c *Cache) DelCounter(key string) error {
+	return c.Del(key)
+}
+
+//Expire set cache value expire duration by given key and ttl
+func (c *Cache) Expire(key string, ttl time.Duration) error {
+	return c.modify(key, func(data []byte, ok bool) ([]byte, bool, error) {
+		if !ok {
+			return nil, false, cache.ErrNotFound
+		}
+		return encodeEntry(data, ttl), true, nil
+	})
+}
+
+//ExpireCounter set cache counter  expire duration by given key and ttl
+func (c *Cache) ExpireCounter(key string, ttl time.Duration) error {
+	return c.Expire(key, ttl)
+}
+
+//GetTTL get remaining ttl of given key.
+//Return ttl and any error raised.
+//Zero ttl means entry never expires before bucket ttl.
+func (c *Cache) GetTTL(key string) (time.Duration, error) {
+	_, _, expired, ok, err := c.entry(key)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, cache.ErrNotFound
+	}
+	if expired.IsZero() {
+		return 0, nil
+	}
+	return time.Until(expired), nil
+}
+
+//Config Cache driver config.
+type Config struct {
+	//URL nats server url.
+	//nats.DefaultURL will be used if empty.
+	URL string
+	//Bucket key-value bucket name.Default value is "cache".
+	//Bucket is created with following settings if not exists.
+	Bucket string
+	//TTLInSecond max age of entries in bucket.
+	//Entries are removed by server after ttl,even if longer ttl is set.
+	//Entries never expire by bucket if not positive.
+	TTLInSecond int64
+	//MaxBytes max bytes of bucket.Not limited if not positive.
+	MaxBytes int64
+	//MaxValueSize max size of every value.Not limited if not positive.
+	MaxValueSize int32
+	//Replicas replicas count of bucket in cluster.Default value is 1.
+	Replicas int
+	//Memory whether store bucket in memory instead of file.
+	Memory bool
+}
+
+//Create create new cache driver.
+//Return driver created and any error if raised.
+func (config *Config) Create() (cache.Driver, error) {
+	url := config.URL
+	if url == "" {
+		url = nats.DefaultURL
+	}
+	bucket := config.Bucket
+	if bucket == "" {
+		bucket = defaultBucket
+	}
+	conn, err := nats.Connect(url)
+	if err != nil {
+		return nil, err
+	}
+	js, err := conn.JetStream()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	kv, err := js.KeyValue(bucket)
+	if errors.Is(err, nats.ErrBucketNotFound) {
+		kvconfig := &nats.KeyValueConfig{
+			Bucket:   bucket,
+			History:  1,
+			TTL:      time.Duration(config.TTLInSecond) * time.Second,
+			Replicas: config.Replicas,
+			Storage:  nats.FileStorage,
+		}
+		if config.MaxBytes > 0 {
+			kvconfig.MaxBytes = config.MaxBytes
+		}
+		if config.MaxValueSize > 0 {
+			kvconfig.MaxValueSize = config.MaxValueSize
+		}
+		if config.Memory {
+			kvconfig.Storage = nats.MemoryStorage
+		}
+		kv, err = js.CreateKeyValue(kvconfig)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &Cache{
+		Conn: conn,
+		KV:   kv,
+	}, nil
+}
+
+func init() {
+	cache.Register("natskvcache", func(loader func(interface{}) error) (cache.Driver, error) {
+		c := &Config{}
+		err := loader(c)
+		if err != nil {
+			return nil, err
+		}
+		return c.Create()
+	})
+}
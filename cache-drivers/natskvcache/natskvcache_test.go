@@ -0,0 +1,135 @@
+package natskvcache
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+func newTestCache(ttl int64) *cache.Cache {
+	decoder := json.NewDecoder(bytes.NewBufferString(testConfig))
+	c := cache.New()
+	oc := cache.NewOptionConfig()
+	oc.Driver = "natskvcache"
+	oc.TTL = int64(ttl)
+	oc.Config = decoder.Decode
+	oc.Marshaler = "json"
+	err := c.Init(oc)
+	if err != nil {
+		panic(err)
+	}
+	err = c.Flush()
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func TestKey(t *testing.T) {
+	key := cache.Key("test\x00key/.*>")
+	encoded := encodeKey(key)
+	for _, v := range encoded {
+		if !(v >= 'a' && v <= 'z' || v >= 'A' && v <= 'Z' || v >= '0' && v <= '9' || v == '-' || v == '_') {
+			t.Fatal(encoded)
+		}
+	}
+	decoded, err := decodeKey(encoded)
+	if err != nil || decoded != key {
+		t.Fatal(decoded, err)
+	}
+	data, _, ok := decodeEntry(encodeEntry([]byte("value"), -1), time.Now())
+	if !ok || string(data) != "value" {
+		t.Fatal(string(data), ok)
+	}
+	_, _, ok = decodeEntry(encodeEntry([]byte("value"), time.Millisecond), time.Now().Add(time.Second))
+	if ok {
+		t.Fatal(ok)
+	}
+}
+
+func TestNATSKVCache(t *testing.T) {
+	c := newTestCache(3600)
+	defer c.Close()
+	d := c.Driver.(*Cache)
+	invalidated := make(chan string, 10)
+	err := d.OnInvalidated(func(key string) {
+		invalidated <- key
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.Set("test", "value", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case key := <-invalidated:
+		if key != cache.Key("test") {
+			t.Fatal(key)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("not invalidated")
+	}
+	var result string
+	err = c.Get("test", &result)
+	if err != nil || result != "value" {
+		t.Fatal(result, err)
+	}
+	ttl, err := c.GetTTL("test")
+	if err != nil || ttl <= 59*time.Minute || ttl > time.Hour {
+		t.Fatal(ttl, err)
+	}
+	err = c.Set("expired", "value", time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	err = c.Get("expired", &result)
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	ok, err := c.SetIfNotExists("expired", []byte("new"), time.Hour)
+	if err != nil || !ok {
+		t.Fatal(ok, err)
+	}
+	ok, err = c.SetIfNotExists("expired", []byte("new"), time.Hour)
+	if err != nil || ok {
+		t.Fatal(ok, err)
+	}
+	ok, err = c.SetIfVersion("expired", []byte("newer"), cache.ValueVersion([]byte("new")), time.Hour)
+	if err != nil || !ok {
+		t.Fatal(ok, err)
+	}
+	ok, err = c.SetIfVersion("expired", []byte("newest"), cache.ValueVersion([]byte("new")), time.Hour)
+	if err != nil || ok {
+		t.Fatal(ok, err)
+	}
+	v, err := c.IncrCounter("counter", 2, time.Hour)
+	if err != nil || v != 2 {
+		t.Fatal(v, err)
+	}
+	v, err = c.IncrCounter("counter", 3, time.Hour)
+	if err != nil || v != 5 {
+		t.Fatal(v, err)
+	}
+	err = c.Del("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.Get("test", &result)
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	err = c.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.GetCounter("counter")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,291 @@
+package rediscache
+
+import (
+	"errors"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+//ErrNoClusterNodeAvailable error raised when no cluster node returns slots.
+var ErrNoClusterNodeAvailable = errors.New("rediscache: no cluster node available")
+
+//ErrInvalidClusterCursor error raised when iteration cursor is not a valid cluster cursor.
+var ErrInvalidClusterCursor = errors.New("rediscache: invalid cluster cursor")
+
+const clusterSlots = 16384
+
+//maxRedirects max MOVED or ASK redirects followed by one command.
+const maxRedirects = 5
+
+var crc16tab [256]uint16
+
+func init() {
+	for i := range crc16tab {
+		crc := uint16(i) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc = crc << 1
+			}
+		}
+		crc16tab[i] = crc
+	}
+}
+
+func crc16(s string) uint16 {
+	var crc uint16
+	for i := 0; i < len(s); i++ {
+		crc = crc<<8 ^ crc16tab[byte(crc>>8)^s[i]]
+	}
+	return crc
+}
+
+//keySlot return cluster hash slot of given key.
+//Only content in first non-empty hash tag is hashed if key contains hash tag.
+func keySlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	return int(crc16(key) % clusterSlots)
+}
+
+//parseRedirect parse MOVED or ASK error returned by cluster node.
+//Return whether error is redirect,whether redirect is ASK,slot and node address.
+func parseRedirect(err error) (ok bool, ask bool, slot int, addr string) {
+	rerr, isRedisError := err.(redis.Error)
+	if !isRedisError {
+		return false, false, 0, ""
+	}
+	fields := strings.Fields(string(rerr))
+	if len(fields) != 3 || (fields[0] != "MOVED" && fields[0] != "ASK") {
+		return false, false, 0, ""
+	}
+	slot, converr := strconv.Atoi(fields[1])
+	if converr != nil || slot < 0 || slot >= clusterSlots {
+		return false, false, 0, ""
+	}
+	return true, fields[0] == "ASK", slot, fields[2]
+}
+
+//isRedirect check if given error is MOVED or ASK redirect.
+func isRedirect(err error) bool {
+	ok, _, _, _ := parseRedirect(err)
+	return ok
+}
+
+//cluster redis cluster client which routes commands to node pools by key slot.
+type cluster struct {
+	locker  sync.RWMutex
+	seeds   []string
+	newPool func(addr string) *redis.Pool
+	pools   map[string]*redis.Pool
+	slots   [clusterSlots]string
+}
+
+func newCluster(seeds []string, newPool func(addr string) *redis.Pool) *cluster {
+	return &cluster{
+		seeds:   seeds,
+		newPool: newPool,
+		pools:   map[string]*redis.Pool{},
+	}
+}
+
+func (cl *cluster) pool(addr string) *redis.Pool {
+	cl.locker.RLock()
+	p, ok := cl.pools[addr]
+	cl.locker.RUnlock()
+	if ok {
+		return p
+	}
+	cl.locker.Lock()
+	defer cl.locker.Unlock()
+	p, ok = cl.pools[addr]
+	if !ok {
+		p = cl.newPool(addr)
+		cl.pools[addr] = p
+	}
+	return p
+}
+
+//refresh load slots from first available node by CLUSTER SLOTS command.
+//Return any error raised.
+func (cl *cluster) refresh() error {
+	var lasterr = ErrNoClusterNodeAvailable
+	for _, addr := range cl.knownAddrs() {
+		err := cl.loadSlots(addr)
+		if err == nil {
+			return nil
+		}
+		lasterr = err
+	}
+	return lasterr
+}
+
+func (cl *cluster) knownAddrs() []string {
+	cl.locker.RLock()
+	defer cl.locker.RUnlock()
+	addrs := make([]string, 0, len(cl.seeds)+len(cl.pools))
+	addrs = append(addrs, cl.seeds...)
+	for addr := range cl.pools {
+		addrs = append(addrs, addr)
+	}
+	return addrs
+}
+
+func (cl *cluster) loadSlots(addr string) error {
+	conn := cl.pool(addr).Get()
+	defer conn.Close()
+	ranges, err := redis.Values(conn.Do("CLUSTER", "SLOTS"))
+	if err != nil {
+		return err
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	var slots [clusterSlots]string
+	for _, v := range ranges {
+		r, err := redis.Values(v, nil)
+		if err != nil {
+			return err
+		}
+		if len(r) < 3 {
+			continue
+		}
+		start, err := redis.Int(r[0], nil)
+		if err != nil {
+			return err
+		}
+		end, err := redis.Int(r[1], nil)
+		if err != nil {
+			return err
+		}
+		node, err := redis.Values(r[2], nil)
+		if err != nil {
+			return err
+		}
+		if len(node) < 2 || start < 0 || end >= clusterSlots {
+			continue
+		}
+		ip, err := redis.String(node[0], nil)
+		if err != nil {
+			return err
+		}
+		port, err := redis.Int(node[1], nil)
+		if err != nil {
+			return err
+		}
+		if ip == "" {
+			ip = host
+		}
+		master := net.JoinHostPort(ip, strconv.Itoa(port))
+		for i := start; i <= end; i++ {
+			slots[i] = master
+		}
+	}
+	cl.locker.Lock()
+	cl.slots = slots
+	cl.locker.Unlock()
+	return nil
+}
+
+func (cl *cluster) setSlot(slot int, addr string) {
+	cl.locker.Lock()
+	cl.slots[slot] = addr
+	cl.locker.Unlock()
+}
+
+//addr return node address serving given slot.
+//First seed is returned if slot is not assigned.
+func (cl *cluster) addr(slot int) string {
+	cl.locker.RLock()
+	defer cl.locker.RUnlock()
+	if cl.slots[slot] != "" {
+		return cl.slots[slot]
+	}
+	return cl.seeds[0]
+}
+
+//masters return sorted master addresses serving slots.
+func (cl *cluster) masters() []string {
+	cl.locker.RLock()
+	defer cl.locker.RUnlock()
+	exists := map[string]bool{}
+	result := []string{}
+	for _, v := range cl.slots {
+		if v != "" && !exists[v] {
+			exists[v] = true
+			result = append(result, v)
+		}
+	}
+	if len(result) == 0 {
+		result = append(result, cl.seeds[0])
+	}
+	sort.Strings(result)
+	return result
+}
+
+//Get get connection to node serving given key.
+//Commands sent by Do follow MOVED and ASK redirects.
+func (cl *cluster) Get(key string) redis.Conn {
+	return cl.conn(cl.addr(keySlot(key)))
+}
+
+func (cl *cluster) conn(addr string) redis.Conn {
+	return &clusterConn{Conn: cl.pool(addr).Get(), cluster: cl}
+}
+
+//Close close all node pools.
+//Return last error raised.
+func (cl *cluster) Close() error {
+	cl.locker.Lock()
+	defer cl.locker.Unlock()
+	var err error
+	for addr, p := range cl.pools {
+		if closeerr := p.Close(); closeerr != nil {
+			err = closeerr
+		}
+		delete(cl.pools, addr)
+	}
+	return err
+}
+
+//clusterConn connection to cluster node which follows redirects.
+type clusterConn struct {
+	redis.Conn
+	cluster *cluster
+}
+
+//Do send command to node and return reply.
+//Slot is updated and command is resent to new node if MOVED error returned.
+//Command is resent to given node after ASKING if ASK error returned.
+func (c *clusterConn) Do(cmd string, args ...interface{}) (interface{}, error) {
+	reply, err := c.Conn.Do(cmd, args...)
+	for i := 0; i < maxRedirects; i++ {
+		ok, ask, slot, addr := parseRedirect(err)
+		if !ok {
+			break
+		}
+		if !ask {
+			c.cluster.setSlot(slot, addr)
+		}
+		c.Conn.Close()
+		c.Conn = c.cluster.pool(addr).Get()
+		if ask {
+			_, err = c.Conn.Do("ASKING")
+			if err != nil {
+				return nil, err
+			}
+		}
+		reply, err = c.Conn.Do(cmd, args...)
+	}
+	return reply, err
+}
@@ -0,0 +1,153 @@
+package rediscache
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+func TestKeySlot(t *testing.T) {
+	if crc16("123456789") != 0x31C3 {
+		t.Fatal(crc16("123456789"))
+	}
+	if keySlot("foo") != 12182 {
+		t.Fatal(keySlot("foo"))
+	}
+	if keySlot("{user1000}.following") != keySlot("{user1000}.followers") {
+		t.Fatal(keySlot("{user1000}.following"))
+	}
+	if keySlot("foo{}{bar}") != int(crc16("foo{}{bar}")%clusterSlots) {
+		t.Fatal(keySlot("foo{}{bar}"))
+	}
+}
+
+func TestParseRedirect(t *testing.T) {
+	ok, ask, slot, addr := parseRedirect(redis.Error("MOVED 3999 127.0.0.1:6381"))
+	if !ok || ask || slot != 3999 || addr != "127.0.0.1:6381" {
+		t.Fatal(ok, ask, slot, addr)
+	}
+	ok, ask, slot, addr = parseRedirect(redis.Error("ASK 3999 127.0.0.1:6381"))
+	if !ok || !ask || slot != 3999 || addr != "127.0.0.1:6381" {
+		t.Fatal(ok, ask, slot, addr)
+	}
+	ok, _, _, _ = parseRedirect(redis.Error("ERR unknown command"))
+	if ok {
+		t.Fatal(ok)
+	}
+	ok, _, _, _ = parseRedirect(errors.New("MOVED 3999 127.0.0.1:6381"))
+	if ok {
+		t.Fatal(ok)
+	}
+}
+
+func TestConfigVerify(t *testing.T) {
+	c := &Config{Cluster: []string{"127.0.0.1:7000"}, SentinelAddrs: []string{"127.0.0.1:26379"}}
+	if c.verify() != ErrClusterWithSentinel {
+		t.Fatal(c.verify())
+	}
+	c = &Config{SentinelAddrs: []string{"127.0.0.1:26379"}}
+	if c.verify() != ErrSentinelMasterNameRequired {
+		t.Fatal(c.verify())
+	}
+	c.SentinelMasterName = "mymaster"
+	if c.verify() != nil {
+		t.Fatal(c.verify())
+	}
+}
+
+func TestChunks(t *testing.T) {
+	c := &Cache{BatchSize: 2}
+	chunks := c.chunks([]string{"a", "b", "c", "d", "e"})
+	if len(chunks) != 3 || len(chunks[2]) != 1 || chunks[2][0] != "e" {
+		t.Fatal(chunks)
+	}
+	c.cluster = newCluster([]string{"127.0.0.1:7000"}, nil)
+	chunks = c.chunks([]string{"{a}1", "{b}1", "{a}2", "{a}3"})
+	if len(chunks) != 3 || chunks[0][0] != "{a}1" || chunks[0][1] != "{a}2" || chunks[1][0] != "{a}3" || chunks[2][0] != "{b}1" {
+		t.Fatal(chunks)
+	}
+}
+
+//testNode fake cluster node which serves all slots or redirects all keys to other node.
+type testNode struct {
+	locker  sync.Mutex
+	movedTo string
+	data    map[string][]byte
+}
+
+func (n *testNode) exec(cmd string, args ...interface{}) (interface{}, error) {
+	n.locker.Lock()
+	defer n.locker.Unlock()
+	if n.movedTo != "" && len(args) > 0 {
+		return nil, redis.Error(fmt.Sprintf("MOVED %d %s", keySlot(args[0].(string)), n.movedTo))
+	}
+	switch cmd {
+	case "MGET":
+		result := make([]interface{}, len(args))
+		for k, v := range args {
+			if bs, ok := n.data[v.(string)]; ok {
+				result[k] = bs
+			}
+		}
+		return result, nil
+	case "SETEX":
+		n.data[args[0].(string)] = args[2].([]byte)
+		return "OK", nil
+	}
+	return nil, redis.Error("ERR unknown command")
+}
+
+//testNodeConn fake connection to testNode.
+type testNodeConn struct {
+	node    *testNode
+	pending []func() (interface{}, error)
+}
+
+func (c *testNodeConn) Close() error { return nil }
+func (c *testNodeConn) Err() error   { return nil }
+func (c *testNodeConn) Flush() error { return nil }
+func (c *testNodeConn) Do(cmd string, args ...interface{}) (interface{}, error) {
+	return c.node.exec(cmd, args...)
+}
+func (c *testNodeConn) Send(cmd string, args ...interface{}) error {
+	c.pending = append(c.pending, func() (interface{}, error) { return c.node.exec(cmd, args...) })
+	return nil
+}
+func (c *testNodeConn) Receive() (interface{}, error) {
+	f := c.pending[0]
+	c.pending = c.pending[1:]
+	return f()
+}
+
+func TestClusterRedirect(t *testing.T) {
+	nodes := map[string]*testNode{
+		"127.0.0.1:7000": {movedTo: "127.0.0.1:7001", data: map[string][]byte{}},
+		"127.0.0.1:7001": {data: map[string][]byte{}},
+	}
+	c := &Cache{BatchSize: 2, Separtor: defaultSepartor, name: "test"}
+	c.cluster = newCluster([]string{"127.0.0.1:7000"}, func(addr string) *redis.Pool {
+		return &redis.Pool{Dial: func() (redis.Conn, error) {
+			return &testNodeConn{node: nodes[addr]}, nil
+		}}
+	})
+	data := map[string][]byte{"{a}1": []byte("a1"), "{a}2": []byte("a2"), "{a}3": []byte("a3"), "{b}1": []byte("b1")}
+	err := c.MSetBytesValue(data, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes["127.0.0.1:7001"].data) != 4 {
+		t.Fatal(nodes["127.0.0.1:7001"].data)
+	}
+	c.cluster.slots = [clusterSlots]string{}
+	result, err := c.MGetBytesValue("{a}1", "{a}2", "{a}3", "{b}1", "{b}2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 4 || string(result["{a}3"]) != "a3" || string(result["{b}1"]) != "b1" {
+		t.Fatal(result)
+	}
+}
@@ -0,0 +1,187 @@
+package rediscache
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/herb-go/datasource/redis/redispool"
+)
+
+//ErrSentinelMasterNameRequired error raised when sentinel addresses set without master name.
+var ErrSentinelMasterNameRequired = errors.New("rediscache: sentinel master name required")
+
+//ErrClusterWithSentinel error raised when both cluster and sentinel are configured.
+var ErrClusterWithSentinel = errors.New("rediscache: cluster and sentinel can not be used together")
+
+//ErrNoSentinelAvailable error raised when no sentinel returns master address.
+var ErrNoSentinelAvailable = errors.New("rediscache: no sentinel available")
+
+//ErrNotMaster error raised when address returned by sentinel is not a master.
+var ErrNotMaster = errors.New("rediscache: sentinel returned address is not master")
+
+func (c *Config) verify() error {
+	if len(c.Cluster) > 0 && len(c.SentinelAddrs) > 0 {
+		return ErrClusterWithSentinel
+	}
+	if len(c.SentinelAddrs) > 0 && c.SentinelMasterName == "" {
+		return ErrSentinelMasterNameRequired
+	}
+	return nil
+}
+
+func (c *Config) customDial() bool {
+	return len(c.Cluster) > 0 || len(c.SentinelAddrs) > 0 || c.TLS || c.Username != ""
+}
+
+func (c *Config) network() string {
+	if c.Network == "" {
+		return "tcp"
+	}
+	return c.Network
+}
+
+func (c *Config) dialOptions(addr string) []redis.DialOption {
+	opts := []redis.DialOption{
+		redis.DialConnectTimeout(time.Duration(c.ConnectTimeoutInSecond) * time.Second),
+		redis.DialReadTimeout(time.Duration(c.ReadTimeoutInSecond) * time.Second),
+		redis.DialWriteTimeout(time.Duration(c.WriteTimeoutInSecond) * time.Second),
+	}
+	if c.TLS {
+		servername := c.TLSServerName
+		if servername == "" {
+			servername, _, _ = net.SplitHostPort(addr)
+		}
+		opts = append(opts,
+			redis.DialUseTLS(true),
+			redis.DialTLSSkipVerify(c.TLSSkipVerify),
+			redis.DialTLSConfig(&tls.Config{ServerName: servername}),
+		)
+	}
+	return opts
+}
+
+//dial dial redis node with given address.
+//Username and password are sent by AUTH command,database is selected if db is positive.
+func (c *Config) dial(addr string, db int) (redis.Conn, error) {
+	conn, err := redis.Dial(c.network(), addr, c.dialOptions(addr)...)
+	if err != nil {
+		return nil, err
+	}
+	err = auth(conn, c.Username, c.Password)
+	if err == nil && db > 0 {
+		_, err = conn.Do("SELECT", db)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func auth(conn redis.Conn, username string, password string) error {
+	var err error
+	if username != "" {
+		_, err = conn.Do("AUTH", username, password)
+	} else if password != "" {
+		_, err = conn.Do("AUTH", password)
+	}
+	return err
+}
+
+//masterAddr query sentinels for master address in order.
+//Return first master address returned and any error raised.
+func (c *Config) masterAddr() (string, error) {
+	var lasterr = ErrNoSentinelAvailable
+	for _, v := range c.SentinelAddrs {
+		addr, err := c.querySentinel(v)
+		if err == nil {
+			return addr, nil
+		}
+		lasterr = err
+	}
+	return "", lasterr
+}
+
+func (c *Config) querySentinel(sentinel string) (string, error) {
+	conn, err := redis.Dial(c.network(), sentinel,
+		redis.DialConnectTimeout(time.Duration(c.ConnectTimeoutInSecond)*time.Second),
+		redis.DialReadTimeout(time.Duration(c.ReadTimeoutInSecond)*time.Second),
+		redis.DialWriteTimeout(time.Duration(c.WriteTimeoutInSecond)*time.Second),
+	)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	err = auth(conn, "", c.SentinelPassword)
+	if err != nil {
+		return "", err
+	}
+	result, err := redis.Strings(conn.Do("SENTINEL", "get-master-addr-by-name", c.SentinelMasterName))
+	if err != nil {
+		return "", err
+	}
+	if len(result) != 2 {
+		return "", ErrNoSentinelAvailable
+	}
+	return net.JoinHostPort(result[0], result[1]), nil
+}
+
+//dialMaster dial master node resolved by sentinels.
+//Role of node is checked so that stale master after failover is not used.
+func (c *Config) dialMaster() (redis.Conn, error) {
+	addr, err := c.masterAddr()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := c.dial(addr, c.Db)
+	if err != nil {
+		return nil, err
+	}
+	role, err := redis.Values(conn.Do("ROLE"))
+	if err == nil && len(role) > 0 {
+		var r string
+		r, err = redis.String(role[0], nil)
+		if err == nil && r != "master" {
+			err = ErrNotMaster
+		}
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+//newPool create redis pool with pool settings in config and given dial function.
+func (c *Config) newPool(dial func() (redis.Conn, error)) *redis.Pool {
+	p := redispool.New()
+	c.Config.ApplyTo(p)
+	pool := p.Open()
+	pool.Dial = dial
+	return pool
+}
+
+//createPool create redis pool or cluster by config.
+func (c *Config) createPool(cache *Cache) {
+	switch {
+	case len(c.Cluster) > 0:
+		cache.cluster = newCluster(c.Cluster, func(addr string) *redis.Pool {
+			return c.newPool(func() (redis.Conn, error) {
+				return c.dial(addr, 0)
+			})
+		})
+	case len(c.SentinelAddrs) > 0:
+		cache.Pool = c.newPool(c.dialMaster)
+	case c.customDial():
+		cache.Pool = c.newPool(func() (redis.Conn, error) {
+			return c.dial(c.Address, c.Db)
+		})
+	default:
+		p := redispool.New()
+		c.Config.ApplyTo(p)
+		cache.Pool = p.Open()
+	}
+}
@@ -3,7 +3,9 @@
 package rediscache
 
 import (
+	"context"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gomodule/redigo/redis"
@@ -13,13 +15,25 @@ import (
 
 var defaultSepartor = string(0)
 
+const defaultBatchSize = 500
+
+const setIfVersionLua = `
+local v=redis.call("GET",KEYS[1])
+if (v==false and ARGV[2]=="") or (v~=false and redis.sha1hex(v)==ARGV[2]) then
+	redis.call("SET",KEYS[1],ARGV[1],"EX",ARGV[3])
+	return 1
+end
+return 0
+`
+
 const modeSet = 0
 const modeUpdate = 1
 
 //Cache The redis cache Driver.
 type Cache struct {
 	cache.DriverUtil
-	Pool           *redis.Pool //Redis pool.
+	Pool           *redis.Pool //Redis pool.Pool is nil in cluster mode.
+	cluster        *cluster
 	name           string
 	quit           chan int
 	network        string
@@ -30,10 +44,17 @@ type Cache struct {
 	readTimeout    time.Duration
 	writeTimeout   time.Duration
 	Separtor       string //Separtor in redis key.
+	BatchSize      int    //Max keys sent in one MGET command or one SETEX pipeline.Default value is 500.
 }
 
 func (c *Cache) start() error {
-	conn := c.Pool.Get()
+	if c.cluster != nil {
+		err := c.cluster.refresh()
+		if err != nil {
+			return err
+		}
+	}
+	conn := c.conn("")
 	defer conn.Close()
 	_, err := conn.Do("PING")
 	return err
@@ -42,6 +63,43 @@ func (c *Cache) getKey(key string) string {
 	return c.name + c.Separtor + key
 }
 
+//conn get connection to redis node which serves given redis key.
+func (c *Cache) conn(k string) redis.Conn {
+	if c.cluster != nil {
+		return c.cluster.Get(k)
+	}
+	return c.Pool.Get()
+}
+
+//Ping check if redis server is reachable before context done.
+//Return any error raised.
+func (c *Cache) Ping(ctx context.Context) error {
+	result := make(chan error, 1)
+	go func() {
+		conn := c.conn("")
+		defer conn.Close()
+		_, err := conn.Do("PING")
+		result <- err
+	}()
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+//Capabilities return capabilities supported by cache.
+//Flush is not supported.
+//Rename is not supported in cluster mode,as keys may be in different slots.
+func (c *Cache) Capabilities() cache.Capabilities {
+	capabilities := cache.CapabilityMGet | cache.CapabilityCounter | cache.CapabilityFloatCounter | cache.CapabilityTTL | cache.CapabilityNX | cache.CapabilityCAS | cache.CapabilityIteration | cache.CapabilityPing
+	if c.cluster == nil {
+		capabilities = capabilities | cache.CapabilityRename
+	}
+	return capabilities
+}
+
 //Flush Flush not supported.
 func (c *Cache) Flush() error {
 	return cache.ErrFeatureNotSupported
@@ -50,6 +108,9 @@ func (c *Cache) Flush() error {
 //Close Close cache.
 //Return any error if raised
 func (c *Cache) Close() error {
+	if c.cluster != nil {
+		return c.cluster.Close()
+	}
 	return c.Pool.Close()
 }
 
@@ -57,12 +118,25 @@ func (c *Cache) Close() error {
 //Return any error raised.
 func (c *Cache) Del(key string) error {
 	k := c.getKey(key)
-	conn := c.Pool.Get()
+	conn := c.conn(k)
 	defer conn.Close()
 	_, err := conn.Do("DEL", k)
 	return err
 }
 
+//Rename move value of old key to new key atomically by RENAME command.
+//Return cache.ErrNotFound if old key not exists.
+func (c *Cache) Rename(oldKey string, newKey string) error {
+	k := c.getKey(oldKey)
+	conn := c.conn(k)
+	defer conn.Close()
+	_, err := conn.Do("RENAME", k, c.getKey(newKey))
+	if rerr, ok := err.(redis.Error); ok && strings.Contains(string(rerr), "no such key") {
+		return cache.ErrNotFound
+	}
+	return err
+}
+
 //SetCounter Set int val in cache by given key.Count cache and data cache are in two independent namespace.
 //Return any error raised.
 func (c *Cache) SetCounter(key string, v int64, ttl time.Duration) error {
@@ -85,7 +159,7 @@ func (c *Cache) GetCounter(key string) (int64, error) {
 //Return any error raised.
 func (c *Cache) DelCounter(key string) error {
 	k := c.getKey(key)
-	conn := c.Pool.Get()
+	conn := c.conn(k)
 	defer conn.Close()
 	_, err := conn.Do("DEL", k)
 	return err
@@ -96,9 +170,9 @@ func (c *Cache) DelCounter(key string) error {
 func (c *Cache) IncrCounter(key string, increment int64, ttl time.Duration) (int64, error) {
 	var err error
 	var v int64
-	conn := c.Pool.Get()
-	defer conn.Close()
 	k := c.getKey(key)
+	conn := c.conn(k)
+	defer conn.Close()
 
 	v, err = redis.Int64(conn.Do("INCRBY", k, increment))
 	if err != nil {
@@ -112,11 +186,44 @@ func (c *Cache) IncrCounter(key string, increment int64, ttl time.Duration) (int
 
 	return v, err
 }
-func (c *Cache) doSet(key string, bytes []byte, ttl time.Duration, mode int) error {
+
+//IncrFloatCounter Increase float val in cache by given key with INCRBYFLOAT.
+//Return float data value and any error raised.
+func (c *Cache) IncrFloatCounter(key string, increment float64, ttl time.Duration) (float64, error) {
 	var err error
-	conn := c.Pool.Get()
+	var v float64
+	k := c.getKey(key)
+	conn := c.conn(k)
 	defer conn.Close()
+
+	v, err = redis.Float64(conn.Do("INCRBYFLOAT", k, increment))
+	if err != nil {
+		return v, err
+	}
+
+	_, err = conn.Do("EXPIRE", k, int64(ttl/time.Second))
+	if err != nil {
+		return v, err
+	}
+
+	return v, err
+}
+
+//GetFloatCounter Get float val from cache by given key.
+//Return float data value and any error raised.
+func (c *Cache) GetFloatCounter(key string) (float64, error) {
+	var v float64
+	bytes, err := c.GetBytesValue(key)
+	if err != nil {
+		return v, err
+	}
+	return strconv.ParseFloat(string(bytes), 64)
+}
+func (c *Cache) doSet(key string, bytes []byte, ttl time.Duration, mode int) error {
+	var err error
 	k := c.getKey(key)
+	conn := c.conn(k)
+	defer conn.Close()
 
 	if mode == modeUpdate {
 		_, err = conn.Do("SET", k, bytes, "EX", int64(ttl/time.Second), "XX")
@@ -140,66 +247,267 @@ func (c *Cache) UpdateBytesValue(key string, bytes []byte, ttl time.Duration) er
 	return c.doSet(key, bytes, ttl, modeUpdate)
 }
 
+//SetIfNotExists Set bytes data to cache by given key only if the key does not exist.
+//Return whether data is set and any error raised.
+func (c *Cache) SetIfNotExists(key string, bytes []byte, ttl time.Duration) (bool, error) {
+	k := c.getKey(key)
+	conn := c.conn(k)
+	defer conn.Close()
+	_, err := redis.String(conn.Do("SET", k, bytes, "EX", int64(ttl/time.Second), "NX"))
+	if err == redis.ErrNil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+//SetIfVersion Set bytes data to cache by given key only if current value version equals given version.
+//Empty version means key should not exist.
+//Return whether data is set and any error raised.
+func (c *Cache) SetIfVersion(key string, bytes []byte, version string, ttl time.Duration) (bool, error) {
+	k := c.getKey(key)
+	conn := c.conn(k)
+	defer conn.Close()
+	result, err := redis.Int64(conn.Do("EVAL", setIfVersionLua, 1, k, bytes, version, int64(ttl/time.Second)))
+	if err != nil {
+		return false, err
+	}
+	return result == 1, nil
+}
+
 //MGetBytesValue get multiple bytes data from cache by given keys.
+//Keys are fetched by MGET commands in chunks of batch size,pipelined in one round trip per node.
 //Return data bytes map and any error if raised.
 func (c *Cache) MGetBytesValue(keys ...string) (map[string][]byte, error) {
 	var data = make(map[string][]byte, len(keys))
-	var err error
-	conn := c.Pool.Get()
-	defer conn.Close()
-	for key := range keys {
-		k := c.getKey(keys[key])
-		err := (conn.Send("GET", k))
+	for _, group := range c.groupKeys(keys) {
+		err := c.mget(group, data)
 		if err != nil {
 			return nil, err
 		}
 	}
+	return data, nil
+}
+
+//groupKeys group given keys by redis node which serves them.
+//All keys are in one group if cluster mode is not enabled.
+func (c *Cache) groupKeys(keys []string) [][]string {
+	if c.cluster == nil || len(keys) == 0 {
+		return [][]string{keys}
+	}
+	indexes := map[string]int{}
+	groups := [][]string{}
+	for _, key := range keys {
+		addr := c.cluster.addr(keySlot(c.getKey(key)))
+		i, ok := indexes[addr]
+		if !ok {
+			i = len(groups)
+			indexes[addr] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], key)
+	}
+	return groups
+}
+
+//chunks split given keys into chunks which can be sent by one MGET command.
+//Chunk size is limited by batch size,and keys in same chunk have same hash slot in cluster mode.
+func (c *Cache) chunks(keys []string) [][]string {
+	size := c.BatchSize
+	if size <= 0 {
+		size = defaultBatchSize
+	}
+	groups := [][]string{keys}
+	if c.cluster != nil {
+		indexes := map[int]int{}
+		groups = [][]string{}
+		for _, key := range keys {
+			slot := keySlot(c.getKey(key))
+			i, ok := indexes[slot]
+			if !ok {
+				i = len(groups)
+				indexes[slot] = i
+				groups = append(groups, nil)
+			}
+			groups[i] = append(groups[i], key)
+		}
+	}
+	result := [][]string{}
+	for _, group := range groups {
+		for len(group) > size {
+			result = append(result, group[:size])
+			group = group[size:]
+		}
+		if len(group) > 0 {
+			result = append(result, group)
+		}
+	}
+	return result
+}
 
+//mget get keys served by same node with pipelined MGET commands in one round trip.
+//Chunks redirected by MOVED or ASK in cluster mode are resent by Do,which follows redirects.
+func (c *Cache) mget(keys []string, data map[string][]byte) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	var err error
+	conn := c.conn(c.getKey(keys[0]))
+	defer conn.Close()
+	chunks := c.chunks(keys)
+	for _, chunk := range chunks {
+		err = conn.Send("MGET", c.chunkArgs(chunk)...)
+		if err != nil {
+			return err
+		}
+	}
 	err = conn.Flush()
 	if err != nil {
-		return nil, err
+		return err
 	}
-	for key := range keys {
-		bs, err := redis.Bytes((conn.Receive()))
-		if err == redis.ErrNil {
+	var redirected [][]string
+	var lasterr error
+	for _, chunk := range chunks {
+		values, err := redis.Values(conn.Receive())
+		if isRedirect(err) {
+			redirected = append(redirected, chunk)
 			continue
 		}
 		if err != nil {
-			return nil, err
+			lasterr = err
+			continue
+		}
+		err = fillValues(chunk, values, data)
+		if err != nil {
+			lasterr = err
 		}
-		data[keys[key]] = bs
 	}
+	if lasterr != nil {
+		return lasterr
+	}
+	for _, chunk := range redirected {
+		values, err := redis.Values(c.redo(chunk[0], "MGET", c.chunkArgs(chunk)...))
+		if err != nil {
+			return err
+		}
+		err = fillValues(chunk, values, data)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	return data, nil
+//redo resend redirected command on new connection to node serving given key by Do,
+//so that pipelined connection is not moved to other node.
+func (c *Cache) redo(key string, cmd string, args ...interface{}) (interface{}, error) {
+	conn := c.conn(c.getKey(key))
+	defer conn.Close()
+	return conn.Do(cmd, args...)
+}
+
+func (c *Cache) chunkArgs(chunk []string) redis.Args {
+	args := make(redis.Args, 0, len(chunk))
+	for _, key := range chunk {
+		args = append(args, c.getKey(key))
+	}
+	return args
+}
+
+//fillValues fill MGET reply values of given chunk into data map.
+func fillValues(chunk []string, values []interface{}, data map[string][]byte) error {
+	for i, v := range values {
+		if v == nil || i >= len(chunk) {
+			continue
+		}
+		bs, err := redis.Bytes(v, nil)
+		if err != nil {
+			return err
+		}
+		data[chunk[i]] = bs
+	}
+	return nil
 }
 
 //MSetBytesValue set multiple bytes data to cache with given key-value map.
+//Values are set by pipelined SETEX commands flushed in chunks of batch size.
 //Return  any error if raised.
 func (c *Cache) MSetBytesValue(data map[string][]byte, ttl time.Duration) error {
-	var err error
-	conn := c.Pool.Get()
-	defer conn.Close()
-	var ttlInSecond = int64(ttl / time.Second)
+	keys := make([]string, 0, len(data))
 	for key := range data {
-		k := c.getKey(key)
-
-		err = conn.Send("SET", k, data[key], "EX", ttlInSecond)
-
+		keys = append(keys, key)
+	}
+	for _, group := range c.groupKeys(keys) {
+		err := c.mset(group, data, ttl)
 		if err != nil {
 			return err
 		}
 	}
-	return conn.Flush()
+	return nil
+}
 
+//mset set keys served by same node with pipelined SETEX commands.
+//Commands are flushed every batch size keys,and all replies are checked.
+//Keys redirected by MOVED or ASK in cluster mode are resent by Do,which follows redirects.
+func (c *Cache) mset(keys []string, data map[string][]byte, ttl time.Duration) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	size := c.BatchSize
+	if size <= 0 {
+		size = defaultBatchSize
+	}
+	conn := c.conn(c.getKey(keys[0]))
+	defer conn.Close()
+	var ttlInSecond = int64(ttl / time.Second)
+	for len(keys) > 0 {
+		chunk := keys
+		if len(chunk) > size {
+			chunk = chunk[:size]
+		}
+		keys = keys[len(chunk):]
+		for _, key := range chunk {
+			err := conn.Send("SETEX", c.getKey(key), ttlInSecond, data[key])
+			if err != nil {
+				return err
+			}
+		}
+		err := conn.Flush()
+		if err != nil {
+			return err
+		}
+		var redirected []string
+		var lasterr error
+		for _, key := range chunk {
+			_, err = conn.Receive()
+			if isRedirect(err) {
+				redirected = append(redirected, key)
+			} else if err != nil {
+				lasterr = err
+			}
+		}
+		if lasterr != nil {
+			return lasterr
+		}
+		for _, key := range redirected {
+			_, err = c.redo(key, "SETEX", c.getKey(key), ttlInSecond, data[key])
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 //GetBytesValue Get bytes data from cache by given key.
 //Return data bytes and any error raised.
 func (c *Cache) GetBytesValue(key string) ([]byte, error) {
 	var bs []byte
-	conn := c.Pool.Get()
-	defer conn.Close()
 	k := c.getKey(key)
+	conn := c.conn(k)
+	defer conn.Close()
 	bs, err := redis.Bytes((conn.Do("GET", k)))
 	if err == redis.ErrNil {
 		return nil, cache.ErrNotFound
@@ -213,24 +521,132 @@ func (c *Cache) GetBytesValue(key string) ([]byte, error) {
 	return bs, err
 }
 
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+//Keys list keys with given prefix from given cursor by SCAN command.
+//Count is passed to SCAN as hint,so more or less keys may be returned.
+//In cluster mode masters are scanned one by one,and cursor contains master index.
+//Return keys,next cursor and any error raised.
+//Empty next cursor means iteration finished.
+func (c *Cache) Keys(prefix string, cursor string, count int) ([]string, string, error) {
+	if c.cluster != nil {
+		return c.clusterKeys(prefix, cursor, count)
+	}
+	conn := c.Pool.Get()
+	defer conn.Close()
+	return c.scan(conn, prefix, cursor, count)
+}
+
+func (c *Cache) clusterKeys(prefix string, cursor string, count int) ([]string, string, error) {
+	var index int
+	var err error
+	if cursor != "" {
+		sep := strings.IndexByte(cursor, ':')
+		if sep < 0 {
+			return nil, "", ErrInvalidClusterCursor
+		}
+		index, err = strconv.Atoi(cursor[:sep])
+		if err != nil {
+			return nil, "", ErrInvalidClusterCursor
+		}
+		cursor = cursor[sep+1:]
+	}
+	masters := c.cluster.masters()
+	if index < 0 || index >= len(masters) {
+		return nil, "", ErrInvalidClusterCursor
+	}
+	conn := c.cluster.conn(masters[index])
+	defer conn.Close()
+	keys, next, err := c.scan(conn, prefix, cursor, count)
+	if err != nil {
+		return nil, "", err
+	}
+	if next == "" {
+		index++
+		if index == len(masters) {
+			return keys, "", nil
+		}
+		next = "0"
+	}
+	return keys, strconv.Itoa(index) + ":" + next, nil
+}
+
+func (c *Cache) scan(conn redis.Conn, prefix string, cursor string, count int) ([]string, string, error) {
+	if cursor == "" {
+		cursor = "0"
+	}
+	args := redis.Args{}.Add(cursor, "MATCH", escapeGlob(c.getKey(prefix))+"*")
+	if count > 0 {
+		args = args.Add("COUNT", count)
+	}
+	result, err := redis.Values(conn.Do("SCAN", args...))
+	if err != nil {
+		return nil, "", err
+	}
+	var next string
+	var rawkeys []string
+	_, err = redis.Scan(result, &next, &rawkeys)
+	if err != nil {
+		return nil, "", err
+	}
+	base := c.getKey("")
+	keys := make([]string, 0, len(rawkeys))
+	for _, k := range rawkeys {
+		keys = append(keys, k[len(base):])
+	}
+	if next == "0" {
+		next = ""
+	}
+	return keys, next, nil
+}
+
 //Expire set cache value expire duration by given key and ttl
 func (c *Cache) Expire(key string, ttl time.Duration) error {
 	var err error
-	conn := c.Pool.Get()
-	defer conn.Close()
 	k := c.getKey(key)
+	conn := c.conn(k)
+	defer conn.Close()
 
 	_, err = conn.Do("EXPIRE", k, int64(ttl/time.Second))
 
 	return err
 }
 
+//GetTTL get remaining ttl of given key.
+//Return ttl and any error raised.
+func (c *Cache) GetTTL(key string) (time.Duration, error) {
+	k := c.getKey(key)
+	conn := c.conn(k)
+	defer conn.Close()
+	ttl, err := redis.Int64(conn.Do("PTTL", k))
+	if err != nil {
+		return 0, err
+	}
+	if ttl == -2 {
+		return 0, cache.ErrNotFound
+	}
+	if ttl < 0 {
+		return 0, nil
+	}
+	return time.Duration(ttl) * time.Millisecond, nil
+}
+
 //ExpireCounter set cache counter  expire duration by given key and ttl
 func (c *Cache) ExpireCounter(key string, ttl time.Duration) error {
 	var err error
-	conn := c.Pool.Get()
-	defer conn.Close()
 	k := c.getKey(key)
+	conn := c.conn(k)
+	defer conn.Close()
 
 	_, err = conn.Do("EXPIRE", k, int64(ttl/time.Second))
 
@@ -245,20 +661,32 @@ func (c *Cache) SetGCErrHandler(f func(err error)) {
 //Config Cache driver config.
 type Config struct {
 	redispool.Config
-	GCPeriod int64 //Period of gc.Default value is 30 second.
-	GCLimit  int64 //Max delete limit in every gc call.Default value is 100.
+	Cluster            []string //Cluster seed node addresses.Cluster mode is enabled if not empty.Db is ignored in cluster mode.
+	SentinelAddrs      []string //Sentinel addresses.Master is resolved by sentinels if not empty.
+	SentinelMasterName string   //Master name monitored by sentinels.
+	SentinelPassword   string   //Password used to auth sentinels.
+	Username           string   //Username used to auth redis with ACL.
+	TLS                bool     //Whether connect redis with TLS.
+	TLSSkipVerify      bool     //Whether skip server certificate verification.
+	TLSServerName      string   //Server name used to verify certificate.Host of node address is used if empty.
+	BatchSize          int      //Max keys sent in one MGET command or one SETEX pipeline.Default value is 500.
+	GCPeriod           int64    //Period of gc.Default value is 30 second.
+	GCLimit            int64    //Max delete limit in every gc call.Default value is 100.
 }
 
 //Create create new cache driver.
 //Return driver created and any error if raised.
 func (c *Config) Create() (cache.Driver, error) {
-
-	cache := Cache{}
-	p := redispool.New()
-	c.Config.ApplyTo(p)
-	cache.Pool = p.Open()
+	err := c.verify()
+	if err != nil {
+		return nil, err
+	}
+	cache := Cache{
+		BatchSize: c.BatchSize,
+	}
+	c.createPool(&cache)
 	cache.quit = make(chan int)
-	err := cache.start()
+	err = cache.start()
 	if err != nil {
 		return &cache, err
 	}
@@ -1,6 +1,8 @@
 package rediscache
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/herb-go/deprecated/cache"
@@ -81,7 +83,7 @@ func TestMSetMGet(t *testing.T) {
 		}
 	}
 	_, err = c.GetBytesValue(unusedKey)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 
@@ -184,11 +186,11 @@ func TestCloseAndFlush(t *testing.T) {
 		t.Errorf("Cache get result error %s", resultDataModel)
 	}
 	err = c.Flush()
-	if err != cache.ErrFeatureNotSupported {
+	if !errors.Is(err, cache.ErrFeatureNotSupported) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKey, &resultDataModel)
-	if err == cache.ErrNotFound {
+	if errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 
@@ -234,7 +236,7 @@ func TestSearchUpdate(t *testing.T) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKeyUpdate, &resultDataModel)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 
@@ -263,7 +265,7 @@ func TestSearchUpdate(t *testing.T) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(testKeyBytesUpdate)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 }
@@ -307,11 +309,42 @@ func TestCounter(t *testing.T) {
 		t.Fatal(err)
 	}
 	resultDataInt, err = c.GetCounter(testKey)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 
 }
+func TestPing(t *testing.T) {
+	c := newTestCache(1)
+	defer c.Close()
+	err := c.Ping(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFloatCounter(t *testing.T) {
+	testKey := "testFloatKey"
+	c := newTestCache(1)
+	defer c.Close()
+	err := c.DelCounter(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := c.IncrFloatCounter(testKey, 1.5, cache.DefaultTTL)
+	if err != nil || v != 1.5 {
+		t.Fatal(v, err)
+	}
+	v, err = c.IncrFloatCounter(testKey, 0.25, cache.DefaultTTL)
+	if err != nil || v != 1.75 {
+		t.Fatal(v, err)
+	}
+	v, err = c.GetFloatCounter(testKey)
+	if err != nil || v != 1.75 {
+		t.Fatal(v, err)
+	}
+}
+
 func TestDefaulTTL(t *testing.T) {
 	defaultTTL := int64(1)
 	testKey := "testKey"
@@ -360,15 +393,15 @@ func TestDefaulTTL(t *testing.T) {
 	}
 	time.Sleep(2000 * time.Millisecond)
 	err = c.Get(testKey, &resultDataModel)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(testKey2)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	resultDataInt, err = c.GetCounter(testKey3)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 }
@@ -496,15 +529,15 @@ func TestTTL(t *testing.T) {
 
 	time.Sleep(2000 * time.Millisecond)
 	err = c.Get(testKeyTTL1Second, &resultModelData)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(testKeyTTL1SecondBytes)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetCounter(testKeyTTL1SecondCounter)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKeyTTL3Second, &resultModelData)
@@ -559,27 +592,27 @@ func TestTTL(t *testing.T) {
 
 	time.Sleep(2000 * time.Millisecond)
 	err = c.Get(testKeyTTL1Second, &resultModelData)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(testKeyTTL1SecondBytes)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetCounter(testKeyTTL1SecondCounter)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKeyTTL3Second, &resultModelData)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(testKeyTTL3SecondBytes)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetCounter(testKeyTTL3SecondCounter)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKeyTTLRefresh, &resultModelData)
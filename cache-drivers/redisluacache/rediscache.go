@@ -3,6 +3,7 @@
 package redisluacache
 
 import (
+	"context"
 	"sync"
 	"time"
 
@@ -42,6 +43,7 @@ var gcLua = `
 
 const modeSet = 0
 const modeUpdate = 1
+const modeSetIfNotExists = 2
 
 //Cache The redis cache Driver.
 type Cache struct {
@@ -129,6 +131,29 @@ func (c *Cache) gc() error {
 	return err
 }
 
+//Ping check if redis server is reachable before context done.
+//Return any error raised.
+func (c *Cache) Ping(ctx context.Context) error {
+	result := make(chan error, 1)
+	go func() {
+		conn := c.Pool.Get()
+		defer conn.Close()
+		_, err := conn.Do("PING")
+		result <- err
+	}()
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+//Capabilities return capabilities supported by cache.
+func (c *Cache) Capabilities() cache.Capabilities {
+	return cache.CapabilityMGet | cache.CapabilityCounter | cache.CapabilityFlush | cache.CapabilityTTL | cache.CapabilityNX | cache.CapabilityPing
+}
+
 //Close Close cache.
 //Return any error if raised
 func (c *Cache) Close() error {
@@ -228,7 +253,7 @@ func (c *Cache) IncrCounter(key string, increment int64, ttl time.Duration) (int
 	_, err = redis.Scan(values, &v)
 	return v, err
 }
-func (c *Cache) doSet(key string, bytes []byte, ttl time.Duration, mode int) error {
+func (c *Cache) doSet(key string, bytes []byte, ttl time.Duration, mode int) (bool, error) {
 	var err error
 	var version string
 	conn := c.Pool.Get()
@@ -236,56 +261,67 @@ func (c *Cache) doSet(key string, bytes []byte, ttl time.Duration, mode int) err
 	k := c.getKey(key)
 	_, err = conn.Do("MULTI")
 	if err != nil {
-		return err
+		return false, err
 	}
 	vk := c.getVersionKey()
 	_, err = conn.Do("GET", vk)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	if mode == modeUpdate {
 		_, err = conn.Do("SET", k, bytes, "EX", int64(ttl/time.Second), "XX")
 
+	} else if mode == modeSetIfNotExists {
+		_, err = conn.Do("SET", k, bytes, "EX", int64(ttl/time.Second), "NX")
+
 	} else {
 		_, err = conn.Do("SET", k, bytes, "EX", int64(ttl/time.Second))
 
 	}
 
 	if err != nil {
-		return err
+		return false, err
 	}
 	values, err := redis.Values(conn.Do("EXEC"))
 	if err != nil {
-		return err
+		return false, err
 	}
 	_, err = redis.Scan(values, &version)
 	if err == redis.ErrNil {
 		version = ""
 	} else if err != nil {
-		return err
+		return false, err
 	}
 	if version != c.version {
 		c.version = version
 		_, err = conn.Do("DEL", k)
 		if err != nil {
-			return err
+			return false, err
 		}
 		return c.doSet(key, bytes, ttl, mode)
 	}
-	return nil
+	return len(values) > 1 && values[1] != nil, nil
 }
 
 //SetBytesValue Set bytes data to cache by given key.
 //Return any error raised.
 func (c *Cache) SetBytesValue(key string, bytes []byte, ttl time.Duration) error {
-	return c.doSet(key, bytes, ttl, modeSet)
+	_, err := c.doSet(key, bytes, ttl, modeSet)
+	return err
 }
 
 //UpdateBytesValue Update bytes data to cache by given key only if the cache exist.
 //Return any error raised.
 func (c *Cache) UpdateBytesValue(key string, bytes []byte, ttl time.Duration) error {
-	return c.doSet(key, bytes, ttl, modeUpdate)
+	_, err := c.doSet(key, bytes, ttl, modeUpdate)
+	return err
+}
+
+//SetIfNotExists Set bytes data to cache by given key only if the key does not exist.
+//Return whether data is set and any error raised.
+func (c *Cache) SetIfNotExists(key string, bytes []byte, ttl time.Duration) (bool, error) {
+	return c.doSet(key, bytes, ttl, modeSetIfNotExists)
 }
 
 //GetBytesValue Get bytes data from cache by given key.
@@ -425,6 +461,25 @@ func (c *Cache) Expire(key string, ttl time.Duration) error {
 	return err
 }
 
+//GetTTL get remaining ttl of given key.
+//Return ttl and any error raised.
+func (c *Cache) GetTTL(key string) (time.Duration, error) {
+	conn := c.Pool.Get()
+	defer conn.Close()
+	k := c.getKey(key)
+	ttl, err := redis.Int64(conn.Do("PTTL", k))
+	if err != nil {
+		return 0, err
+	}
+	if ttl == -2 {
+		return 0, cache.ErrNotFound
+	}
+	if ttl < 0 {
+		return 0, nil
+	}
+	return time.Duration(ttl) * time.Millisecond, nil
+}
+
 //ExpireCounter set cache counter  expire duration by given key and ttl
 func (c *Cache) ExpireCounter(key string, ttl time.Duration) error {
 	var err error
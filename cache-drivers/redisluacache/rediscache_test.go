@@ -1,6 +1,7 @@
 package redisluacache
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/herb-go/deprecated/cache"
@@ -85,7 +86,7 @@ func TestMSetMGet(t *testing.T) {
 		}
 	}
 	_, err = c.GetBytesValue(unusedKey)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 
@@ -192,7 +193,7 @@ func TestCloseAndFlush(t *testing.T) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKey, &resultDataModel)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 
@@ -238,7 +239,7 @@ func TestSearchUpdate(t *testing.T) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKeyUpdate, &resultDataModel)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 
@@ -267,7 +268,7 @@ func TestSearchUpdate(t *testing.T) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(testKeyBytesUpdate)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 }
@@ -311,7 +312,7 @@ func TestCounter(t *testing.T) {
 		t.Fatal(err)
 	}
 	resultDataInt, err = c.GetCounter(testKey)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 
@@ -364,15 +365,15 @@ func TestDefaulTTL(t *testing.T) {
 	}
 	time.Sleep(2000 * time.Millisecond)
 	err = c.Get(testKey, &resultDataModel)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(testKey2)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	resultDataInt, err = c.GetCounter(testKey3)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 }
@@ -500,15 +501,15 @@ func TestTTL(t *testing.T) {
 
 	time.Sleep(2000 * time.Millisecond)
 	err = c.Get(testKeyTTL1Second, &resultModelData)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(testKeyTTL1SecondBytes)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetCounter(testKeyTTL1SecondCounter)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKeyTTL3Second, &resultModelData)
@@ -564,27 +565,27 @@ func TestTTL(t *testing.T) {
 	time.Sleep(2000 * time.Millisecond)
 
 	err = c.Get(testKeyTTL1Second, &resultModelData)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(testKeyTTL1SecondBytes)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetCounter(testKeyTTL1SecondCounter)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKeyTTL3Second, &resultModelData)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(testKeyTTL3SecondBytes)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetCounter(testKeyTTL3SecondCounter)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKeyTTLRefresh, &resultModelData)
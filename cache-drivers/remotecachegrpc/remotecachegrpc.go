@@ -0,0 +1,145 @@
+//Package remotecachegrpc provides grpc transport of remote cache.
+//Requests and responses are json encoded by "json" codec,so no generated code is required.
+package remotecachegrpc
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/herb-go/deprecated/cache/drivers/remotecache"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/metadata"
+)
+
+//ServiceName grpc service name of remote cache.
+//Every operation is served as a method of service,like "/remotecache.Cache/get".
+const ServiceName = "remotecache.Cache"
+
+//CodecName name of json codec used by remote cache.
+const CodecName = "json"
+
+type codec struct{}
+
+func (codec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (codec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (codec) Name() string {
+	return CodecName
+}
+
+type handler interface {
+	Authorize(token string) bool
+	Serve(ctx context.Context, op string, req *remotecache.Request) *remotecache.Response
+}
+
+func tokenFromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(values[0], "Bearer ")
+}
+
+func newMethodDesc(op string) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: op,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			req := &remotecache.Request{}
+			err := dec(req)
+			if err != nil {
+				return nil, err
+			}
+			h := func(ctx context.Context, req interface{}) (interface{}, error) {
+				s := srv.(handler)
+				if !s.Authorize(tokenFromContext(ctx)) {
+					resp := &remotecache.Response{}
+					resp.SetError(remotecache.ErrUnauthorized)
+					return resp, nil
+				}
+				return s.Serve(ctx, op, req.(*remotecache.Request)), nil
+			}
+			if interceptor == nil {
+				return h(ctx, req)
+			}
+			info := &grpc.UnaryServerInfo{
+				Server:     srv,
+				FullMethod: "/" + ServiceName + "/" + op,
+			}
+			return interceptor(ctx, req, info, h)
+		},
+	}
+}
+
+//ServiceDesc grpc service description of remote cache.
+var ServiceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*handler)(nil),
+	Streams:     []grpc.StreamDesc{},
+}
+
+//RegisterServer register remote cache server to grpc server.
+func RegisterServer(r grpc.ServiceRegistrar, s *remotecache.Server) {
+	r.RegisterService(&ServiceDesc, s)
+}
+
+//DialOptions options used when dialing remote cache server.
+//Insecure transport credentials are used by default.
+var DialOptions = []grpc.DialOption{
+	grpc.WithTransportCredentials(insecure.NewCredentials()),
+}
+
+//Transport remote cache transport which calls server by grpc.
+type Transport struct {
+	//Conn grpc client conn.
+	Conn *grpc.ClientConn
+	//Token token sent by authorization metadata.
+	Token string
+}
+
+//Call call given operation with given request on remote cache server.
+//Return response and any transport error raised.
+func (t *Transport) Call(ctx context.Context, op string, req *remotecache.Request) (*remotecache.Response, error) {
+	if t.Token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+t.Token)
+	}
+	resp := &remotecache.Response{}
+	err := t.Conn.Invoke(ctx, "/"+ServiceName+"/"+op, req, resp, grpc.CallContentSubtype(CodecName))
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+//Close close transport.
+func (t *Transport) Close() error {
+	return t.Conn.Close()
+}
+
+func init() {
+	encoding.RegisterCodec(codec{})
+	for _, v := range remotecache.Operations {
+		ServiceDesc.Methods = append(ServiceDesc.Methods, newMethodDesc(v))
+	}
+	remotecache.RegisterTransport("grpc", func(c *remotecache.Config) (remotecache.Transport, error) {
+		conn, err := grpc.Dial(c.Address, DialOptions...)
+		if err != nil {
+			return nil, err
+		}
+		return &Transport{
+			Conn:  conn,
+			Token: c.Token,
+		}, nil
+	})
+}
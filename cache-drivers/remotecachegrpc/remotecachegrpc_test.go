@@ -0,0 +1,89 @@
+package remotecachegrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+	"github.com/herb-go/deprecated/cache/drivers/remotecache"
+	_ "github.com/herb-go/deprecated/cache/drivers/syncmapcache"
+	"google.golang.org/grpc"
+)
+
+func newTestServer(token string) (*grpc.Server, string) {
+	oc := cache.NewOptionConfig()
+	oc.Driver = "syncmapcache"
+	oc.TTL = 3600
+	oc.Marshaler = "json"
+	oc.Config = json.NewDecoder(bytes.NewBufferString(`{"Size":10000000}`)).Decode
+	config := &remotecache.ServerConfig{
+		Cache: oc,
+		Token: token,
+	}
+	s, err := config.CreateServer()
+	if err != nil {
+		panic(err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+	gs := grpc.NewServer()
+	RegisterServer(gs, s)
+	go gs.Serve(l)
+	return gs, l.Addr().String()
+}
+
+func newTestCache(address string, token string) *cache.Cache {
+	c := cache.New()
+	oc := cache.NewOptionConfig()
+	oc.Driver = "remotecache"
+	oc.TTL = 3600
+	oc.Marshaler = "json"
+	oc.Config = func(v interface{}) error {
+		config := v.(*remotecache.Config)
+		config.Transport = "grpc"
+		config.Address = address
+		config.Token = token
+		return nil
+	}
+	err := c.Init(oc)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func TestGRPC(t *testing.T) {
+	gs, address := newTestServer("secret")
+	defer gs.Stop()
+	c := newTestCache(address, "secret")
+	defer c.Close()
+	var result string
+	err := c.Get("test", &result)
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	err = c.Set("test", "value", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.Get("test", &result)
+	if err != nil || result != "value" {
+		t.Fatal(result, err)
+	}
+	v, err := c.IncrCounter("counter", 2, time.Hour)
+	if err != nil || v != 2 {
+		t.Fatal(v, err)
+	}
+	unauthorized := newTestCache(address, "wrong")
+	defer unauthorized.Close()
+	err = unauthorized.Get("test", &result)
+	if !errors.Is(err, remotecache.ErrUnauthorized) {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,452 @@
+//Package s3cache provides cache driver uses S3 compatible object storage to store cache data.
+//Using github.com/minio/minio-go/v7 as driver.
+//Every entry is stored as an object,so it can be used to cache multi-megabyte artifacts
+//which are too large for memory drivers.
+package s3cache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+//ErrBucketRequired error raised when bucket is empty.
+var ErrBucketRequired = errors.New("s3cache: bucket required")
+
+//MaxShardLevels max levels of hash sharded prefixes.
+const MaxShardLevels = 4
+
+const lockStripes = 256
+
+//metadataExpired user metadata name of expired time in unix nano.
+const metadataExpired = "Expired"
+
+var defaultShardLevels = 2
+var defaultPrefix = "cache/"
+var defaultContentType = "application/octet-stream"
+
+//CounterEncoding encoding used to store counter values.
+var CounterEncoding cache.CounterEncoding = cache.BigEndianCounterEncoding{}
+
+func expiredTime(ttl time.Duration) int64 {
+	if ttl <= 0 {
+		return 0
+	}
+	return time.Now().Add(ttl).UnixNano()
+}
+
+//parseExpired parse expired time from object metadata.
+//Return expired time and whether entry is not expired.
+func parseExpired(info minio.ObjectInfo, now time.Time) (time.Time, bool) {
+	v := info.Metadata.Get("X-Amz-Meta-" + metadataExpired)
+	if v == "" {
+		v = info.UserMetadata[metadataExpired]
+	}
+	nano, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || nano == 0 {
+		return time.Time{}, true
+	}
+	expired := time.Unix(0, nano)
+	return expired, now.Before(expired)
+}
+
+func isNotFound(err error) bool {
+	code := minio.ToErrorResponse(err).Code
+	return code == "NoSuchKey" || code == "NotFound"
+}
+
+//Cache The S3 cache Driver.
+type Cache struct {
+	cache.DriverUtil
+	Client      *minio.Client
+	bucket      string
+	prefix      string
+	shardLevels int
+	locks       [lockStripes]sync.Mutex
+}
+
+//SetGCErrHandler Set callback to handler error raised when gc.
+//Expired objects are deleted lazily when read,or by bucket lifecycle rules.
+func (c *Cache) SetGCErrHandler(f func(err error)) {
+	return
+}
+
+//object return object name and locker of given key.
+//Object name is hex encoded sha256 hash of key under prefix,and every shard level uses next 2 hex chars as sub prefix.
+func (c *Cache) object(key string) (string, *sync.Mutex) {
+	sum := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(sum[:])
+	buf := bytes.NewBufferString(c.prefix)
+	for i := 0; i < c.shardLevels; i++ {
+		buf.WriteString(name[i*2 : i*2+2])
+		buf.WriteByte('/')
+	}
+	buf.WriteString(name)
+	return buf.String(), &c.locks[sum[0]]
+}
+
+//read read object by given name.
+//Expired object is deleted.
+//Return data,expired time,whether entry exists and not expired and any error raised.
+func (c *Cache) read(name string) ([]byte, time.Time, bool, error) {
+	ctx := context.Background()
+	obj, err := c.Client.GetObject(ctx, c.bucket, name, minio.GetObjectOptions{})
+	if err != nil {
+		if isNotFound(err) {
+			return nil, time.Time{}, false, nil
+		}
+		return nil, time.Time{}, false, err
+	}
+	defer obj.Close()
+	info, err := obj.Stat()
+	if err != nil {
+		if isNotFound(err) {
+			return nil, time.Time{}, false, nil
+		}
+		return nil, time.Time{}, false, err
+	}
+	expired, ok := parseExpired(info, time.Now())
+	if !ok {
+		c.Client.RemoveObject(ctx, c.bucket, name, minio.RemoveObjectOptions{})
+		return nil, expired, false, nil
+	}
+	data, err := ioutil.ReadAll(obj)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, time.Time{}, false, nil
+		}
+		return nil, time.Time{}, false, err
+	}
+	return data, expired, true, nil
+}
+
+//stat read expired time of object by given name without reading data.
+//Return expired time,whether entry exists and not expired and any error raised.
+func (c *Cache) stat(name string) (time.Time, bool, error) {
+	info, err := c.Client.StatObject(context.Background(), c.bucket, name, minio.StatObjectOptions{})
+	if err != nil {
+		if isNotFound(err) {
+			return time.Time{}, false, nil
+		}
+		return time.Time{}, false, err
+	}
+	expired, ok := parseExpired(info, time.Now())
+	return expired, ok, nil
+}
+
+func (c *Cache) write(name string, data []byte, ttl time.Duration) error {
+	_, err := c.Client.PutObject(context.Background(), c.bucket, name, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
+		ContentType: defaultContentType,
+		UserMetadata: map[string]string{
+			metadataExpired: strconv.FormatInt(expiredTime(ttl), 10),
+		},
+	})
+	return err
+}
+
+func (c *Cache) remove(name string) error {
+	err := c.Client.RemoveObject(context.Background(), c.bucket, name, minio.RemoveObjectOptions{})
+	if err != nil && !isNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+//SetBytesValue Set bytes data to cache by given key.
+//Return any error raised.
+func (c *Cache) SetBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	name, _ := c.object(key)
+	return c.write(name, bytes, ttl)
+}
+
+//UpdateBytesValue Update bytes data to cache by given key only if the cache exist.
+//Return any error raised.
+func (c *Cache) UpdateBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	name, locker := c.object(key)
+	locker.Lock()
+	defer locker.Unlock()
+	_, ok, err := c.stat(name)
+	if err != nil || !ok {
+		return err
+	}
+	return c.write(name, bytes, ttl)
+}
+
+//GetBytesValue Get bytes data from cache by given key.
+//Return data bytes and any error raised.
+func (c *Cache) GetBytesValue(key string) ([]byte, error) {
+	name, _ := c.object(key)
+	data, _, ok, err := c.read(name)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, cache.ErrNotFound
+	}
+	return data, nil
+}
+
+//MGetBytesValue get multiple bytes data from cache by given keys.
+//Return data bytes map and any error if raised.
+func (c *Cache) MGetBytesValue(keys ...string) (map[string][]byte, error) {
+	var result = make(map[string][]byte, len(keys))
+	for _, k := range keys {
+		data, err := c.GetBytesValue(k)
+		if err == cache.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		result[k] = data
+	}
+	return result, nil
+}
+
+//MSetBytesValue set multiple bytes data to cache with given key-value map.
+//Return  any error if raised.
+func (c *Cache) MSetBytesValue(data map[string][]byte, ttl time.Duration) error {
+	for k := range data {
+		err := c.SetBytesValue(k, data[k], ttl)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Capabilities return capabilities supported by cache.
+func (c *Cache) Capabilities() cache.Capabilities {
+	return cache.CapabilityCounter | cache.CapabilityFlush | cache.CapabilityTTL | cache.CapabilityPing
+}
+
+//Ping check if bucket is reachable before context done.
+//Return any error raised.
+func (c *Cache) Ping(ctx context.Context) error {
+	_, err := c.Client.BucketExists(ctx, c.bucket)
+	return err
+}
+
+//Flush Delete all objects under prefix.
+//Return any error if raised
+func (c *Cache) Flush() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	objects := c.Client.ListObjects(ctx, c.bucket, minio.ListObjectsOptions{
+		Prefix:    c.prefix,
+		Recursive: true,
+	})
+	var listErr error
+	removing := make(chan minio.ObjectInfo)
+	go func() {
+		defer close(removing)
+		for v := range objects {
+			if v.Err != nil {
+				listErr = v.Err
+				return
+			}
+			removing <- v
+		}
+	}()
+	for e := range c.Client.RemoveObjects(ctx, c.bucket, removing, minio.RemoveObjectsOptions{}) {
+		if e.Err != nil && !isNotFound(e.Err) {
+			cancel()
+			for range removing {
+			}
+			return e.Err
+		}
+	}
+	return listErr
+}
+
+//Close Close cache.
+//Return any error if raised
+func (c *Cache) Close() error {
+	return nil
+}
+
+//Del Delete data in cache by given key.
+//Return any error raised.
+func (c *Cache) Del(key string) error {
+	name, _ := c.object(key)
+	return c.remove(name)
+}
+
+//IncrCounter Increase int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Counter is increased by reading and writing object,which is only atomic in current process.
+//Return int data value and any error raised.
+func (c *Cache) IncrCounter(key string, increment int64, ttl time.Duration) (int64, error) {
+	name, locker := c.object(key)
+	locker.Lock()
+	defer locker.Unlock()
+	var v int64
+	data, _, ok, err := c.read(name)
+	if err != nil {
+		return 0, err
+	}
+	if ok {
+		current, err := CounterEncoding.DecodeCounter(data)
+		if err == nil {
+			v = current
+		}
+	}
+	v = v + increment
+	err = c.write(name, CounterEncoding.EncodeCounter(v), ttl)
+	if err != nil {
+		return 0, err
+	}
+	return v, nil
+}
+
+//SetCounter Set int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Return any error raised.
+func (c *Cache) SetCounter(key string, v int64, ttl time.Duration) error {
+	return c.SetBytesValue(key, CounterEncoding.EncodeCounter(v), ttl)
+}
+
+//GetCounter Get int val from cache by given key.Count cache and data cache are in two independent namespace.
+//Return int data value and any error raised.
+func (c *Cache) GetCounter(key string) (int64, error) {
+	data, err := c.GetBytesValue(key)
+	if err != nil {
+		return 0, err
+	}
+	v, err := CounterEncoding.DecodeCounter(data)
+	if err != nil {
+		return 0, cache.ErrNotFound
+	}
+	return v, nil
+}
+
+//DelCounter Delete int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Return any error raised.
+func (c *Cache) DelCounter(key string) error {
+	return c.Del(key)
+}
+
+//Expire set cache value expire duration by given key and ttl.
+//Object is copied to itself on server with new metadata,so data is not transferred.
+func (c *Cache) Expire(key string, ttl time.Duration) error {
+	name, locker := c.object(key)
+	locker.Lock()
+	defer locker.Unlock()
+	_, ok, err := c.stat(name)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return cache.ErrNotFound
+	}
+	_, err = c.Client.CopyObject(context.Background(), minio.CopyDestOptions{
+		Bucket:          c.bucket,
+		Object:          name,
+		ReplaceMetadata: true,
+		UserMetadata: map[string]string{
+			metadataExpired: strconv.FormatInt(expiredTime(ttl), 10),
+		},
+	}, minio.CopySrcOptions{
+		Bucket: c.bucket,
+		Object: name,
+	})
+	return err
+}
+
+//ExpireCounter set cache counter  expire duration by given key and ttl
+func (c *Cache) ExpireCounter(key string, ttl time.Duration) error {
+	return c.Expire(key, ttl)
+}
+
+//GetTTL get remaining ttl of given key.
+//Return ttl and any error raised.
+//Zero ttl means entry never expires.
+func (c *Cache) GetTTL(key string) (time.Duration, error) {
+	name, _ := c.object(key)
+	expired, ok, err := c.stat(name)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, cache.ErrNotFound
+	}
+	if expired.IsZero() {
+		return 0, nil
+	}
+	return time.Until(expired), nil
+}
+
+//Config Cache driver config.
+type Config struct {
+	//Endpoint S3 compatible service endpoint without scheme,for example "s3.amazonaws.com".
+	Endpoint string
+	//AccessKeyID access key id.
+	AccessKeyID string
+	//SecretAccessKey secret access key.
+	SecretAccessKey string
+	//SessionToken optional session token of temporary credentials.
+	SessionToken string
+	//Region bucket region.
+	Region string
+	//Secure whether use https.
+	Secure bool
+	//Bucket bucket which stores objects.Bucket should exist.
+	Bucket string
+	//Prefix prefix of object names.Default value is "cache/".
+	//All objects under prefix are deleted when flushed.
+	Prefix string
+	//ShardLevels levels of hash sharded prefixes.Default value is 2.
+	//Every level has up to 256 prefixes.
+	ShardLevels int
+}
+
+//Create create new cache driver.
+//Return driver created and any error if raised.
+func (config *Config) Create() (cache.Driver, error) {
+	if config.Bucket == "" {
+		return nil, ErrBucketRequired
+	}
+	client, err := minio.New(config.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(config.AccessKeyID, config.SecretAccessKey, config.SessionToken),
+		Secure: config.Secure,
+		Region: config.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+	c := &Cache{
+		Client:      client,
+		bucket:      config.Bucket,
+		prefix:      config.Prefix,
+		shardLevels: config.ShardLevels,
+	}
+	if c.prefix == "" {
+		c.prefix = defaultPrefix
+	}
+	if c.shardLevels <= 0 {
+		c.shardLevels = defaultShardLevels
+	}
+	if c.shardLevels > MaxShardLevels {
+		c.shardLevels = MaxShardLevels
+	}
+	return c, nil
+}
+
+func init() {
+	cache.Register("s3cache", func(loader func(interface{}) error) (cache.Driver, error) {
+		c := &Config{}
+		err := loader(c)
+		if err != nil {
+			return nil, err
+		}
+		return c.Create()
+	})
+}
@@ -0,0 +1,118 @@
+package s3cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+func newTestCache(ttl int64) *cache.Cache {
+	decoder := json.NewDecoder(bytes.NewBufferString(testConfig))
+	c := cache.New()
+	oc := cache.NewOptionConfig()
+	oc.Driver = "s3cache"
+	oc.TTL = int64(ttl)
+	oc.Config = decoder.Decode
+	oc.Marshaler = "json"
+	err := c.Init(oc)
+	if err != nil {
+		panic(err)
+	}
+	err = c.Flush()
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func TestObjectName(t *testing.T) {
+	c := &Cache{prefix: "cache/", shardLevels: 2}
+	name, _ := c.object("test")
+	if !strings.HasPrefix(name, "cache/") || strings.Count(name, "/") != 3 || len(name) != len("cache/")+6+64 {
+		t.Fatal(name)
+	}
+	name2, _ := c.object("test")
+	if name2 != name {
+		t.Fatal(name, name2)
+	}
+	_, err := (&Config{}).Create()
+	if err != ErrBucketRequired {
+		t.Fatal(err)
+	}
+}
+
+func TestS3Cache(t *testing.T) {
+	c := newTestCache(3600)
+	defer c.Close()
+	large := bytes.Repeat([]byte("0123456789"), 500000)
+	err := c.SetBytesValue("large", large, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := c.GetBytesValue("large")
+	if err != nil || !bytes.Equal(bs, large) {
+		t.Fatal(len(bs), err)
+	}
+	ttl, err := c.GetTTL("large")
+	if err != nil || ttl <= 59*time.Minute || ttl > time.Hour {
+		t.Fatal(ttl, err)
+	}
+	err = c.Expire("large", 2*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ttl, err = c.GetTTL("large")
+	if err != nil || ttl <= time.Hour {
+		t.Fatal(ttl, err)
+	}
+	bs, err = c.GetBytesValue("large")
+	if err != nil || !bytes.Equal(bs, large) {
+		t.Fatal(len(bs), err)
+	}
+	err = c.SetBytesValue("expired", []byte("value"), time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(1100 * time.Millisecond)
+	_, err = c.GetBytesValue("expired")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	err = c.UpdateBytesValue("notexists", []byte("value"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.GetBytesValue("notexists")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	v, err := c.IncrCounter("counter", 2, time.Hour)
+	if err != nil || v != 2 {
+		t.Fatal(v, err)
+	}
+	v, err = c.IncrCounter("counter", 3, time.Hour)
+	if err != nil || v != 5 {
+		t.Fatal(v, err)
+	}
+	err = c.Del("large")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.GetBytesValue("large")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	err = c.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.GetCounter("counter")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+}
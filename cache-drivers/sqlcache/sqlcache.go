@@ -4,6 +4,7 @@
 package sqlcache
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"time"
@@ -32,6 +33,12 @@ type Cache struct {
 	gcLimit      int64
 }
 
+//Ping check if database is reachable before context done.
+//Return any error raised.
+func (c *Cache) Ping(ctx context.Context) error {
+	return c.DB.PingContext(ctx)
+}
+
 //SetGCErrHandler Set callback to handler error raised when gc.
 func (c *Cache) SetGCErrHandler(f func(err error)) {
 	c.gcErrHandler = f
@@ -1,6 +1,7 @@
 package sqlcache
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/herb-go/deprecated/cache"
@@ -90,7 +91,7 @@ func TestMSetMGet(t *testing.T) {
 		}
 	}
 	_, err = c.GetBytesValue(unusedKey)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 
@@ -197,7 +198,7 @@ func TestCloseAndFlush(t *testing.T) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKey, &resultDataModel)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 
@@ -243,7 +244,7 @@ func TestSearchUpdate(t *testing.T) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKeyUpdate, &resultDataModel)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 
@@ -272,7 +273,7 @@ func TestSearchUpdate(t *testing.T) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(testKeyBytesUpdate)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 }
@@ -315,7 +316,7 @@ func TestCounter(t *testing.T) {
 		t.Fatal(err)
 	}
 	resultDataInt, err = c.GetCounter(testKey)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 
@@ -368,15 +369,15 @@ func TestDefaulTTL(t *testing.T) {
 	}
 	time.Sleep(2000 * time.Millisecond)
 	err = c.Get(testKey, &resultDataModel)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(testKey2)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	resultDataInt, err = c.GetCounter(testKey3)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 }
@@ -505,15 +506,15 @@ func TestTTL(t *testing.T) {
 	time.Sleep(2000 * time.Millisecond)
 
 	err = c.Get(testKeyTTL1Second, &resultModelData)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(testKeyTTL1SecondBytes)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetCounter(testKeyTTL1SecondCounter)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKeyTTL3Second, &resultModelData)
@@ -568,27 +569,27 @@ func TestTTL(t *testing.T) {
 
 	time.Sleep(2000 * time.Millisecond)
 	err = c.Get(testKeyTTL1Second, &resultModelData)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(testKeyTTL1SecondBytes)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetCounter(testKeyTTL1SecondCounter)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKeyTTL3Second, &resultModelData)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(testKeyTTL3SecondBytes)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetCounter(testKeyTTL3SecondCounter)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKeyTTLRefresh, &resultModelData)
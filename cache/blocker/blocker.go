@@ -1,6 +1,8 @@
 package blocker
 
 import (
+	"errors"
+	"fmt"
 	"net"
 	"net/http"
 	"time"
@@ -21,9 +23,12 @@ const defaultBlockedStatus = http.StatusTooManyRequests
 func New(cache cache.Cacheable) *Blocker {
 	return &Blocker{
 		config:            map[int]statusConfig{},
+		scores:            map[int]float64{},
+		honeypots:         map[string]time.Duration{},
 		Cache:             cache,
 		StatusCodeBlocked: defaultBlockedStatus,
 		Identifier:        IPIdentifier,
+		StartedAt:         time.Now(),
 	}
 }
 
@@ -35,7 +40,9 @@ type statusConfig struct {
 
 //Blocker blocker struct.
 type Blocker struct {
-	config map[int]statusConfig
+	config    map[int]statusConfig
+	scores    map[int]float64
+	honeypots map[string]time.Duration
 	//Cache cache which store blcok data
 	Cache cache.Cacheable
 	//StatusCodeBlocked error status which will returned when request blcoker.Default value is 429.
@@ -44,6 +51,35 @@ type Blocker struct {
 	Identifier func(r *http.Request) (string, error)
 	//OnBlock acitons execed when access blocked
 	OnBlock func(w http.ResponseWriter, r *http.Request)
+	//Weight units which request counts against rules.
+	//Every request counts as 1 unit if Weight is nil.
+	//Request will not be counted if weight is not positive.
+	Weight func(r *http.Request) int
+	//GracePeriod warm-up duration after StartedAt.
+	//Requests are counted but not blocked in grace period.
+	//Default value is 0,which means no grace period.
+	GracePeriod time.Duration
+	//StartedAt time when blocker started.Default value is the time blocker created.
+	StartedAt time.Time
+	//ScoreThreshold requester will be blocked when score reaches threshold.
+	//Scoring is disabled if not positive.
+	ScoreThreshold float64
+	//ScoreDecay points decayed per second.
+	ScoreDecay float64
+	//HistorySize max count of history entries kept for every requester.
+	//History is disabled if not positive.
+	HistorySize int
+	//HistoryTTL ttl of requester history.
+	//DefaultHistoryTTL will be used if not positive.
+	HistoryTTL time.Duration
+}
+
+//InGracePeriod check if blocker is still in warm-up grace period.
+func (b *Blocker) InGracePeriod() bool {
+	if b.GracePeriod <= 0 {
+		return false
+	}
+	return time.Now().Before(b.StartedAt.Add(b.GracePeriod))
 }
 
 //Block block config method.
@@ -66,7 +102,7 @@ func (b *Blocker) isBlocked(id string) bool {
 		if ok == true {
 			key := b.buildCacheKey(id, k, config)
 			count, err := b.Cache.GetCounter(key)
-			if err != cache.ErrNotFound {
+			if !errors.Is(err, cache.ErrNotFound) {
 				if err != nil {
 					panic(err)
 				}
@@ -83,7 +119,14 @@ func (b *Blocker) isBlocked(id string) bool {
 func (b *Blocker) DefaultBlockAction(w http.ResponseWriter, r *http.Request) {
 	http.Error(w, http.StatusText(b.StatusCodeBlocked), b.StatusCodeBlocked)
 }
-func (b *Blocker) incr(ip string, status int) {
+func (b *Blocker) requestWeight(r *http.Request) int {
+	if b.Weight == nil {
+		return 1
+	}
+	return b.Weight(r)
+}
+func (b *Blocker) incr(ip string, status int, weight int) []string {
+	var triggered []string
 	checklist := []int{status, StatusAny}
 	if status >= 400 {
 		checklist = append(checklist, StatusAnyError)
@@ -92,12 +135,21 @@ func (b *Blocker) incr(ip string, status int) {
 		config, ok := b.config[checklist[k]]
 		if ok == true {
 			key := b.buildCacheKey(ip, status, config)
-			_, err := b.Cache.IncrCounter(key, 1, time.Duration(config.ttlSecond)*time.Second)
+			count, err := b.Cache.IncrCounter(key, int64(weight), time.Duration(config.ttlSecond)*time.Second)
 			if err != nil {
 				panic(err)
 			}
+			if count >= config.max {
+				triggered = append(triggered, fmt.Sprintf("status %d count %d/%d in %ds", checklist[k], count, config.max, config.ttlSecond))
+				timeHash := time.Now().Unix() / config.ttlSecond
+				err = b.markBlocked(ip, time.Unix((timeHash+1)*config.ttlSecond, 0))
+				if err != nil {
+					panic(err)
+				}
+			}
 		}
 	}
+	return triggered
 }
 
 //IPIdentifier identify http request by ip address.
@@ -112,7 +164,17 @@ func (b *Blocker) ServeMiddleware(w http.ResponseWriter, r *http.Request, next h
 	if err != nil {
 		panic(err)
 	}
-	if b.isBlocked(id) {
+	var reason string
+	honeypot := b.touchHoneypot(id, r)
+	if !b.InGracePeriod() {
+		if honeypot {
+			reason = ReasonHoneypot
+		} else {
+			reason = b.blockedReason(id)
+		}
+	}
+	if reason != "" {
+		b.recordBlocked(id, reason)
 		if b.OnBlock != nil {
 			b.OnBlock(w, r)
 		} else {
@@ -124,8 +186,16 @@ func (b *Blocker) ServeMiddleware(w http.ResponseWriter, r *http.Request, next h
 		w,
 		200,
 	}
+	weight := b.requestWeight(r)
 	next(&writer, r)
-	b.incr(id, writer.status)
+	if weight <= 0 {
+		return
+	}
+	triggered := b.incr(id, writer.status, weight)
+	if rule := b.incrScore(id, writer.status, weight); rule != "" {
+		triggered = append(triggered, rule)
+	}
+	b.recordCounted(id, writer.status, weight, triggered)
 }
 
 type blockWriter struct {
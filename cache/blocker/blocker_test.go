@@ -298,3 +298,101 @@ func TestIPIdentifier(t *testing.T) {
 	}
 	time.Sleep(10 * time.Millisecond)
 }
+
+func TestGracePeriod(t *testing.T) {
+	var rep *http.Response
+	var err error
+	blocker := New(newTestCache(1 * 3600))
+	blocker.Identifier = testIdentifier
+	blocker.Block(403, 2, 1*time.Hour)
+	blocker.GracePeriod = 1 * time.Hour
+	if !blocker.InGracePeriod() {
+		t.Fatal(blocker.StartedAt)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		blocker.ServeMiddleware(w, r, func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, http.StatusText(403), 403)
+		})
+	}))
+	defer server.Close()
+	req, err := http.NewRequest("get", server.URL+"/403", nil)
+	if err != nil {
+		panic(err)
+	}
+	req.Header.Add("name", "test1")
+	for i := 0; i < 5; i++ {
+		rep, err = http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rep.Body.Close()
+		if rep.StatusCode != 403 {
+			t.Error(rep.StatusCode)
+		}
+	}
+	blocker.StartedAt = time.Now().Add(-2 * time.Hour)
+	if blocker.InGracePeriod() {
+		t.Fatal(blocker.StartedAt)
+	}
+	rep, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep.Body.Close()
+	if rep.StatusCode != 429 {
+		t.Error(rep.StatusCode)
+	}
+}
+
+func TestWeight(t *testing.T) {
+	blocker := New(newTestCache(1 * 3600))
+	blocker.Identifier = testIdentifier
+	blocker.Block(StatusAny, 10, 1*time.Hour)
+	blocker.Weight = func(r *http.Request) int {
+		switch r.URL.Path {
+		case "/export":
+			return 5
+		case "/free":
+			return 0
+		}
+		return 1
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		blocker.ServeMiddleware(w, r, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("ok"))
+		})
+	}))
+	defer server.Close()
+	get := func(path string) int {
+		req, err := http.NewRequest("get", server.URL+path, nil)
+		if err != nil {
+			panic(err)
+		}
+		req.Header.Add("name", "test1")
+		rep, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rep.Body.Close()
+		return rep.StatusCode
+	}
+	for i := 0; i < 20; i++ {
+		if status := get("/free"); status != 200 {
+			t.Fatal(status)
+		}
+	}
+	if status := get("/export"); status != 200 {
+		t.Fatal(status)
+	}
+	for i := 0; i < 4; i++ {
+		if status := get("/"); status != 200 {
+			t.Fatal(status)
+		}
+	}
+	if status := get("/"); status != 200 {
+		t.Fatal(status)
+	}
+	if status := get("/"); status != blocker.StatusCodeBlocked {
+		t.Fatal(status)
+	}
+}
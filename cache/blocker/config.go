@@ -36,3 +36,58 @@ func NewRules() *Rules {
 	var r Rules = []*Rule{}
 	return &r
 }
+
+//HoneypotRule blocker honeypot path rule
+type HoneypotRule struct {
+	Path             string
+	DurationInSecond int64
+}
+
+//ApplyTo apply honeypot rule to blocker
+func (r *HoneypotRule) ApplyTo(b *Blocker) error {
+	b.Honeypot(r.Path, time.Duration(r.DurationInSecond)*time.Second)
+	return nil
+}
+
+//HoneypotRules blocker honeypot rule list
+type HoneypotRules []*HoneypotRule
+
+//ApplyTo apply honeypot rule list to blocker
+func (r *HoneypotRules) ApplyTo(b *Blocker) error {
+	for _, v := range *r {
+		err := v.ApplyTo(b)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//NewHoneypotRules create new honeypot rule list
+func NewHoneypotRules() *HoneypotRules {
+	var r HoneypotRules = []*HoneypotRule{}
+	return &r
+}
+
+//ScoreRule blocker leaky bucket scoring rule
+type ScoreRule struct {
+	StatusCode int
+	Points     float64
+}
+
+//ScoreConfig blocker leaky bucket scoring config
+type ScoreConfig struct {
+	Threshold      float64
+	DecayPerSecond float64
+	Rules          []*ScoreRule
+}
+
+//ApplyTo apply scoring config to blocker
+func (c *ScoreConfig) ApplyTo(b *Blocker) error {
+	b.ScoreThreshold = c.Threshold
+	b.ScoreDecay = c.DecayPerSecond
+	for _, v := range c.Rules {
+		b.Score(v.StatusCode, v.Points)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package blocker
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+const blockedKeyPrefix = "blocked"
+
+//ExportPageSize count of keys fetched from cache in one page when exporting.
+var ExportPageSize = 1000
+
+//BlockedEntry blocked requester entry.
+type BlockedEntry struct {
+	//ID requester id.
+	ID string
+	//Expired time when block expires.
+	Expired time.Time
+}
+
+func (b *Blocker) buildBlockedKey(id string) string {
+	return blockedKeyPrefix + cache.KeyPrefix + id
+}
+
+func (b *Blocker) getBlockedExpired(id string) (time.Time, error) {
+	bs, err := b.Cache.GetBytesValue(b.buildBlockedKey(id))
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(bs) != 8 {
+		return time.Time{}, cache.ErrNotFound
+	}
+	return time.Unix(0, int64(binary.BigEndian.Uint64(bs))), nil
+}
+
+func (b *Blocker) markBlocked(id string, expired time.Time) error {
+	ttl := expired.Sub(time.Now())
+	if ttl <= 0 {
+		return nil
+	}
+	old, err := b.getBlockedExpired(id)
+	if err == nil && !old.Before(expired) {
+		return nil
+	}
+	if err != nil && !errors.Is(err, cache.ErrNotFound) {
+		return err
+	}
+	bs := make([]byte, 8)
+	binary.BigEndian.PutUint64(bs, uint64(expired.UnixNano()))
+	if ttl%time.Second != 0 {
+		ttl = ttl - ttl%time.Second + time.Second
+	}
+	return b.Cache.SetBytesValue(b.buildBlockedKey(id), bs, ttl)
+}
+
+func (b *Blocker) isMarkedBlocked(id string) bool {
+	expired, err := b.getBlockedExpired(id)
+	if errors.Is(err, cache.ErrNotFound) {
+		return false
+	}
+	if err != nil {
+		panic(err)
+	}
+	return time.Now().Before(expired)
+}
+
+//Export export current blocked requesters with expiries.
+//Exported entries can be imported into another blocker or after cache flushed.
+//Cache must support key iteration,otherwise cache.ErrFeatureNotSupported will be returned.
+//Return blocked entries and any error if raised.
+func (b *Blocker) Export() ([]*BlockedEntry, error) {
+	prefix := blockedKeyPrefix + cache.KeyPrefix
+	now := time.Now()
+	result := []*BlockedEntry{}
+	cursor := ""
+	for {
+		keys, next, err := cache.Keys(b.Cache, prefix, cursor, ExportPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range keys {
+			id := k[len(prefix):]
+			expired, err := b.getBlockedExpired(id)
+			if errors.Is(err, cache.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if now.Before(expired) {
+				result = append(result, &BlockedEntry{ID: id, Expired: expired})
+			}
+		}
+		if next == "" {
+			return result, nil
+		}
+		cursor = next
+	}
+}
+
+//Import import blocked requesters.
+//Requesters will be blocked until entry expired.
+//Expired entries will be ignored.
+//Return any error if raised.
+func (b *Blocker) Import(entries []*BlockedEntry) error {
+	for _, e := range entries {
+		err := b.markBlocked(e.ID, e.Expired)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
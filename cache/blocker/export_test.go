@@ -0,0 +1,70 @@
+package blocker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestExportImport(t *testing.T) {
+	blocker := New(newTestCache(1 * 3600))
+	blocker.Identifier = testIdentifier
+	blocker.Block(403, 2, 1*time.Hour)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		blocker.ServeMiddleware(w, r, func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, http.StatusText(403), 403)
+		})
+	}))
+	defer server.Close()
+	req, err := http.NewRequest("get", server.URL+"/403", nil)
+	if err != nil {
+		panic(err)
+	}
+	req.Header.Add("name", "test1")
+	for i := 0; i < 3; i++ {
+		rep, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rep.Body.Close()
+	}
+	entries, err := blocker.Export()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].ID != "test1" || !entries[0].Expired.After(time.Now()) {
+		t.Fatal(entries)
+	}
+	err = blocker.Cache.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep.Body.Close()
+	if rep.StatusCode != 403 {
+		t.Fatal(rep.StatusCode)
+	}
+	err = blocker.Import(append(entries, &BlockedEntry{ID: "test2", Expired: time.Now().Add(-time.Hour)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep.Body.Close()
+	if rep.StatusCode != 429 {
+		t.Fatal(rep.StatusCode)
+	}
+	imported, err := blocker.Export()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(imported) != 1 || imported[0].ID != "test1" || !imported[0].Expired.Equal(entries[0].Expired) {
+		t.Fatal(imported)
+	}
+}
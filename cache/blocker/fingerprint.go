@@ -0,0 +1,41 @@
+package blocker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+)
+
+//DefaultFingerprintHeaders headers hashed by FingerprintIdentifier if no header given.
+var DefaultFingerprintHeaders = []string{"User-Agent", "Accept-Language"}
+
+//FingerprintSeparator separator between ip address and header hash in fingerprint id.
+const FingerprintSeparator = "#"
+
+//Fingerprint return hex encoded hash of given headers of http request.
+func Fingerprint(r *http.Request, headers ...string) string {
+	h := sha256.New()
+	for _, v := range headers {
+		h.Write([]byte(http.CanonicalHeaderKey(v)))
+		h.Write([]byte{0})
+		h.Write([]byte(r.Header.Get(v)))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+//FingerprintIdentifier create identifier which identifies http request by ip address and hash of given headers,
+//so that devices behind same NAT are blocked separately.
+//DefaultFingerprintHeaders will be used if no header given.
+func FingerprintIdentifier(headers ...string) func(r *http.Request) (string, error) {
+	if len(headers) == 0 {
+		headers = DefaultFingerprintHeaders
+	}
+	return func(r *http.Request) (string, error) {
+		ip, err := IPIdentifier(r)
+		if err != nil {
+			return "", err
+		}
+		return ip + FingerprintSeparator + Fingerprint(r, headers...), nil
+	}
+}
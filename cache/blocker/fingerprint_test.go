@@ -0,0 +1,43 @@
+package blocker
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestFingerprintIdentifier(t *testing.T) {
+	newRequest := func(ua string, lang string) *http.Request {
+		r, err := http.NewRequest("GET", "http://127.0.0.1/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.RemoteAddr = "10.0.0.1:1234"
+		r.Header.Set("User-Agent", ua)
+		r.Header.Set("Accept-Language", lang)
+		return r
+	}
+	identifier := FingerprintIdentifier()
+	id1, err := identifier(newRequest("ua1", "en"))
+	if err != nil || !strings.HasPrefix(id1, "10.0.0.1"+FingerprintSeparator) {
+		t.Fatal(id1, err)
+	}
+	id2, err := identifier(newRequest("ua1", "en"))
+	if err != nil || id1 != id2 {
+		t.Fatal(id1, id2, err)
+	}
+	id3, err := identifier(newRequest("ua2", "en"))
+	if err != nil || id1 == id3 {
+		t.Fatal(id1, id3, err)
+	}
+	id4, err := identifier(newRequest("ua1", "zh"))
+	if err != nil || id1 == id4 {
+		t.Fatal(id1, id4, err)
+	}
+	uaonly := FingerprintIdentifier("user-agent")
+	id5, _ := uaonly(newRequest("ua1", "en"))
+	id6, _ := uaonly(newRequest("ua1", "zh"))
+	if id5 != id6 || id5 == id1 {
+		t.Fatal(id5, id6)
+	}
+}
@@ -0,0 +1,162 @@
+package blocker
+
+import (
+	"errors"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+const historyKeyPrefix = "history"
+
+//DefaultHistoryTTL default ttl of requester history.
+var DefaultHistoryTTL = time.Hour
+
+//Blocked reasons recorded in history.
+const (
+	//ReasonMarked requester was marked as blocked by earlier triggered rules or import.
+	ReasonMarked = "marked"
+	//ReasonCounter requester reached status counter limit.
+	ReasonCounter = "counter"
+	//ReasonScore requester score reached score threshold.
+	ReasonScore = "score"
+	//ReasonHoneypot requester touched honeypot path.
+	ReasonHoneypot = "honeypot"
+)
+
+//HistoryEntry history entry of requester.
+type HistoryEntry struct {
+	//Time time when entry was recorded.
+	//Time of last request if entry is repeated.
+	Time time.Time
+	//Status response status counted.
+	//Blocked status code if request was blocked.
+	Status int
+	//Weight units which response counted against rules.
+	//Weight is 0 if request was blocked.
+	Weight int
+	//Blocked whether request was rejected by blocker.
+	Blocked bool
+	//Reason reason why request was rejected.
+	Reason string
+	//Triggered rules triggered by this response.
+	Triggered []string
+	//Repeated count of same entries merged into this entry.
+	Repeated int
+}
+
+func (b *Blocker) buildHistoryKey(id string) string {
+	return historyKeyPrefix + cache.KeyPrefix + id
+}
+
+func (b *Blocker) blockedReason(id string) string {
+	if b.isMarkedBlocked(id) {
+		return ReasonMarked
+	}
+	if b.isBlocked(id) {
+		return ReasonCounter
+	}
+	if b.isScoreBlocked(id) {
+		return ReasonScore
+	}
+	return ""
+}
+
+//History return recent history entries of given requester id,oldest first.
+//Empty list will be returned if history is disabled or expired.
+//Return history and any error if raised.
+func (b *Blocker) History(id string) ([]*HistoryEntry, error) {
+	bs, err := b.Cache.GetBytesValue(b.buildHistoryKey(id))
+	if errors.Is(err, cache.ErrNotFound) {
+		return []*HistoryEntry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var result []*HistoryEntry
+	err = b.Cache.Util().Unmarshal(bs, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (b *Blocker) addHistory(id string, e *HistoryEntry) error {
+	key := b.buildHistoryKey(id)
+	ttl := b.HistoryTTL
+	if ttl <= 0 {
+		ttl = DefaultHistoryTTL
+	}
+	for i := 0; i < scoreRetry; i++ {
+		bs, version, err := cache.GetWithVersion(b.Cache, key)
+		if err != nil && !errors.Is(err, cache.ErrNotFound) {
+			return err
+		}
+		var entries []*HistoryEntry
+		if len(bs) > 0 {
+			err = b.Cache.Util().Unmarshal(bs, &entries)
+			if err != nil {
+				entries = nil
+			}
+		}
+		merged := false
+		if len(entries) > 0 && e.Blocked {
+			last := entries[len(entries)-1]
+			if last.Blocked && last.Reason == e.Reason && last.Status == e.Status {
+				last.Repeated = last.Repeated + e.Repeated
+				last.Time = e.Time
+				merged = true
+			}
+		}
+		if !merged {
+			entries = append(entries, e)
+		}
+		if len(entries) > b.HistorySize {
+			entries = entries[len(entries)-b.HistorySize:]
+		}
+		data, err := b.Cache.Util().Marshal(entries)
+		if err != nil {
+			return err
+		}
+		ok, err := cache.SetIfVersion(b.Cache, key, data, version, ttl)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (b *Blocker) recordBlocked(id string, reason string) {
+	if b.HistorySize <= 0 {
+		return
+	}
+	err := b.addHistory(id, &HistoryEntry{
+		Time:     time.Now(),
+		Status:   b.StatusCodeBlocked,
+		Blocked:  true,
+		Reason:   reason,
+		Repeated: 1,
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (b *Blocker) recordCounted(id string, status int, weight int, triggered []string) {
+	if b.HistorySize <= 0 {
+		return
+	}
+	err := b.addHistory(id, &HistoryEntry{
+		Time:      time.Now(),
+		Status:    status,
+		Weight:    weight,
+		Triggered: triggered,
+		Repeated:  1,
+	})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,80 @@
+package blocker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	blocker := New(newTestCache(1 * 3600))
+	blocker.Identifier = testIdentifier
+	blocker.Block(404, 2, 1*time.Hour)
+	blocker.HistorySize = 3
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		blocker.ServeMiddleware(w, r, func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, http.StatusText(404), 404)
+		})
+	}))
+	defer server.Close()
+	history, err := blocker.History("test1")
+	if err != nil || len(history) != 0 {
+		t.Fatal(history, err)
+	}
+	req, err := http.NewRequest("get", server.URL, nil)
+	if err != nil {
+		panic(err)
+	}
+	req.Header.Add("name", "test1")
+	for i := 0; i < 5; i++ {
+		rep, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rep.Body.Close()
+	}
+	history, err = blocker.History("test1")
+	if err != nil || len(history) != 3 {
+		t.Fatal(history, err)
+	}
+	if history[0].Blocked || history[0].Status != 404 || len(history[0].Triggered) != 0 {
+		t.Fatal(history[0])
+	}
+	if history[1].Blocked || history[1].Status != 404 || len(history[1].Triggered) != 1 {
+		t.Fatal(history[1])
+	}
+	if !history[2].Blocked || history[2].Reason != ReasonMarked || history[2].Repeated != 3 || history[2].Status != blocker.StatusCodeBlocked {
+		t.Fatal(history[2])
+	}
+	history, err = blocker.History("test2")
+	if err != nil || len(history) != 0 {
+		t.Fatal(history, err)
+	}
+}
+
+func TestHistoryDisabled(t *testing.T) {
+	blocker := New(newTestCache(1 * 3600))
+	blocker.Identifier = testIdentifier
+	blocker.Block(404, 2, 1*time.Hour)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		blocker.ServeMiddleware(w, r, func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, http.StatusText(404), 404)
+		})
+	}))
+	defer server.Close()
+	req, err := http.NewRequest("get", server.URL, nil)
+	if err != nil {
+		panic(err)
+	}
+	req.Header.Add("name", "test1")
+	rep, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep.Body.Close()
+	history, err := blocker.History("test1")
+	if err != nil || len(history) != 0 {
+		t.Fatal(history, err)
+	}
+}
@@ -0,0 +1,48 @@
+package blocker
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+//Honeypot honeypot config method.
+//Requester which requests param path or any sub path of it will be blocked in param ttl immediately,
+//regardless of counters and scores.
+func (b *Blocker) Honeypot(path string, ttl time.Duration) {
+	b.honeypots[path] = ttl
+}
+
+//honeypotTTL return max block ttl of honeypots matching given path.
+//Return ttl and whether any honeypot matched.
+func (b *Blocker) honeypotTTL(path string) (time.Duration, bool) {
+	var ttl time.Duration
+	var matched bool
+	for k, v := range b.honeypots {
+		base := strings.TrimSuffix(k, "/")
+		if path == k || path == base || strings.HasPrefix(path, base+"/") {
+			if !matched || v > ttl {
+				ttl = v
+			}
+			matched = true
+		}
+	}
+	return ttl, matched
+}
+
+//touchHoneypot mark requester blocked if request touched honeypot.
+//Return whether honeypot is touched.
+func (b *Blocker) touchHoneypot(id string, r *http.Request) bool {
+	if len(b.honeypots) == 0 {
+		return false
+	}
+	ttl, ok := b.honeypotTTL(r.URL.Path)
+	if !ok {
+		return false
+	}
+	err := b.markBlocked(id, time.Now().Add(ttl))
+	if err != nil {
+		panic(err)
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+package blocker
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHoneypot(t *testing.T) {
+	blocker := New(newTestCache(1 * 3600))
+	blocker.Identifier = testIdentifier
+	blocker.HistorySize = 10
+	blocker.Honeypot("/.env", time.Hour)
+	blocker.Honeypot("/wp-admin/", time.Minute)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		blocker.ServeMiddleware(w, r, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("ok"))
+		})
+	}))
+	defer server.Close()
+	get := func(name string, path string) int {
+		req, err := http.NewRequest("GET", server.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Add("name", name)
+		rep, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rep.Body.Close()
+		return rep.StatusCode
+	}
+	if status := get("test1", "/.envfile"); status != 200 {
+		t.Fatal(status)
+	}
+	if status := get("test1", "/wp-admin"); status != 429 {
+		t.Fatal(status)
+	}
+	if status := get("test1", "/"); status != 429 {
+		t.Fatal(status)
+	}
+	expired, err := blocker.getBlockedExpired("test1")
+	if err != nil || expired.After(time.Now().Add(time.Minute)) {
+		t.Fatal(expired, err)
+	}
+	if status := get("test2", "/.env"); status != 429 {
+		t.Fatal(status)
+	}
+	expired, err = blocker.getBlockedExpired("test2")
+	if err != nil || expired.Before(time.Now().Add(59*time.Minute)) {
+		t.Fatal(expired, err)
+	}
+	history, err := blocker.History("test1")
+	if err != nil || len(history) != 3 || history[1].Reason != ReasonHoneypot || history[2].Reason != ReasonMarked {
+		t.Fatal(history, err)
+	}
+	blocker.GracePeriod = time.Hour
+	if status := get("test3", "/.env"); status != 200 {
+		t.Fatal(status)
+	}
+	if !blocker.isMarkedBlocked("test3") {
+		t.Fatal()
+	}
+}
+
+func TestHoneypotConfig(t *testing.T) {
+	var config = `
+	[{
+		"Path":"/.env",
+		"DurationInSecond":3600
+	}]`
+	r := NewHoneypotRules()
+	err := json.Unmarshal([]byte(config), r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := New(newTestCache(1 * 3600))
+	err = r.ApplyTo(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.honeypots["/.env"] != time.Hour {
+		t.Fatal(b.honeypots)
+	}
+}
@@ -0,0 +1,145 @@
+package blocker
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+const scoreKeyPrefix = "score"
+
+const scoreRetry = 5
+
+type scoreEntry struct {
+	score     float64
+	updatedAt time.Time
+}
+
+func (e *scoreEntry) decay(rate float64, now time.Time) float64 {
+	score := e.score - rate*now.Sub(e.updatedAt).Seconds()
+	if score < 0 {
+		return 0
+	}
+	return score
+}
+
+func (e *scoreEntry) encode() []byte {
+	bs := make([]byte, 16)
+	binary.BigEndian.PutUint64(bs[0:8], math.Float64bits(e.score))
+	binary.BigEndian.PutUint64(bs[8:16], uint64(e.updatedAt.UnixNano()))
+	return bs
+}
+
+func decodeScoreEntry(bs []byte) *scoreEntry {
+	if len(bs) != 16 {
+		return nil
+	}
+	return &scoreEntry{
+		score:     math.Float64frombits(binary.BigEndian.Uint64(bs[0:8])),
+		updatedAt: time.Unix(0, int64(binary.BigEndian.Uint64(bs[8:16]))),
+	}
+}
+
+//Score leaky bucket scoring config method.
+//Every response which status is param status adds param points multiplied by request weight to requester score.
+//Score decays continuously by ScoreDecay points per second,
+//and requester will be blocked when score reaches ScoreThreshold.
+func (b *Blocker) Score(status int, points float64) {
+	b.scores[status] = points
+}
+
+func (b *Blocker) buildScoreKey(id string) string {
+	return scoreKeyPrefix + cache.KeyPrefix + id
+}
+
+//CurrentScore return current decayed score of given requester id.
+//Return score and any error if raised.
+func (b *Blocker) CurrentScore(id string) (float64, error) {
+	bs, err := b.Cache.GetBytesValue(b.buildScoreKey(id))
+	if errors.Is(err, cache.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	e := decodeScoreEntry(bs)
+	if e == nil {
+		return 0, nil
+	}
+	return e.decay(b.ScoreDecay, time.Now()), nil
+}
+
+func (b *Blocker) isScoreBlocked(id string) bool {
+	if len(b.scores) == 0 || b.ScoreThreshold <= 0 {
+		return false
+	}
+	score, err := b.CurrentScore(id)
+	if err != nil {
+		panic(err)
+	}
+	return score >= b.ScoreThreshold
+}
+
+func (b *Blocker) addScore(id string, points float64) (float64, error) {
+	key := b.buildScoreKey(id)
+	for i := 0; i < scoreRetry; i++ {
+		now := time.Now()
+		bs, version, err := cache.GetWithVersion(b.Cache, key)
+		if err != nil && !errors.Is(err, cache.ErrNotFound) {
+			return 0, err
+		}
+		e := &scoreEntry{updatedAt: now}
+		if old := decodeScoreEntry(bs); old != nil {
+			e.score = old.decay(b.ScoreDecay, now)
+		}
+		e.score = e.score + points
+		ttl := time.Hour
+		if b.ScoreDecay > 0 {
+			ttl = time.Duration(math.Ceil(e.score/b.ScoreDecay)+1) * time.Second
+		}
+		ok, err := cache.SetIfVersion(b.Cache, key, e.encode(), version, ttl)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			if b.ScoreThreshold > 0 && e.score >= b.ScoreThreshold {
+				expired := now.Add(ttl)
+				if b.ScoreDecay > 0 {
+					expired = now.Add(time.Duration((e.score - b.ScoreThreshold) / b.ScoreDecay * float64(time.Second)))
+				}
+				return e.score, b.markBlocked(id, expired)
+			}
+			return e.score, nil
+		}
+	}
+	return 0, nil
+}
+
+func (b *Blocker) incrScore(id string, status int, weight int) string {
+	if len(b.scores) == 0 {
+		return ""
+	}
+	var points float64
+	checklist := []int{status, StatusAny}
+	if status >= 400 {
+		checklist = append(checklist, StatusAnyError)
+	}
+	for _, v := range checklist {
+		points = points + b.scores[v]
+	}
+	if points == 0 {
+		return ""
+	}
+	score, err := b.addScore(id, points*float64(weight))
+	if err != nil {
+		panic(err)
+	}
+	if b.ScoreThreshold > 0 && score >= b.ScoreThreshold {
+		return fmt.Sprintf("score %.2f/%.2f", score, b.ScoreThreshold)
+	}
+	return ""
+}
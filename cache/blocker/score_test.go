@@ -0,0 +1,89 @@
+package blocker
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestScore(t *testing.T) {
+	blocker := New(newTestCache(1 * 3600))
+	blocker.Identifier = testIdentifier
+	blocker.ScoreThreshold = 10
+	blocker.ScoreDecay = 10
+	blocker.Score(404, 3)
+	blocker.Score(StatusAnyError, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		blocker.ServeMiddleware(w, r, func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, http.StatusText(404), 404)
+		})
+	}))
+	defer server.Close()
+	req, err := http.NewRequest("get", server.URL, nil)
+	if err != nil {
+		panic(err)
+	}
+	req.Header.Add("name", "test1")
+	for i := 0; i < 3; i++ {
+		rep, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rep.Body.Close()
+		if rep.StatusCode != 404 {
+			t.Fatal(rep.StatusCode)
+		}
+	}
+	score, err := blocker.CurrentScore("test1")
+	if err != nil || score < 11 || score > 12 {
+		t.Fatal(score, err)
+	}
+	rep, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep.Body.Close()
+	if rep.StatusCode != 429 {
+		t.Fatal(rep.StatusCode)
+	}
+	time.Sleep(300 * time.Millisecond)
+	score, err = blocker.CurrentScore("test1")
+	if err != nil || score >= 10 {
+		t.Fatal(score, err)
+	}
+	rep, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep.Body.Close()
+	if rep.StatusCode != 404 {
+		t.Fatal(rep.StatusCode)
+	}
+	score, err = blocker.CurrentScore("notexists")
+	if err != nil || score != 0 {
+		t.Fatal(score, err)
+	}
+}
+
+func TestScoreConfig(t *testing.T) {
+	var config = `{
+		"Threshold":100,
+		"DecayPerSecond":1.5,
+		"Rules":[{"StatusCode":404,"Points":5}]
+	}`
+	c := &ScoreConfig{}
+	err := json.Unmarshal([]byte(config), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := New(newTestCache(1 * 3600))
+	err = c.ApplyTo(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.ScoreThreshold != 100 || b.ScoreDecay != 1.5 || b.scores[404] != 5 {
+		t.Fatal(b)
+	}
+}
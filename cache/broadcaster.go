@@ -0,0 +1,191 @@
+package cache
+
+import (
+	"encoding/json"
+	"time"
+)
+
+//BroadcastTransport transport which delivers invalidation messages between cache instances.
+type BroadcastTransport interface {
+	//Publish publish message data to all peers.
+	//Return any error if raised.
+	Publish(data []byte) error
+	//Subscribe start receiving message data from peers.
+	//Handler will be called with every message received.
+	//Return any error if raised.
+	Subscribe(handler func(data []byte)) error
+	//Close close transport.
+	//Return any error if raised.
+	Close() error
+}
+
+//Invalidation invalidation message published by broadcaster.
+type Invalidation struct {
+	//Source id of broadcaster which published message.
+	Source string
+	//Name cache name.
+	Name string
+	//Op operation name.Avaliable values are OpDel and OpFlush.
+	Op string
+	//Key invalidated key.
+	Key string
+}
+
+//Broadcaster cacheable which publishes Del and Flush operations to peers,
+//and applies operations published by peers to wrapped cacheable.
+//Broadcaster is used to keep local caches like syncmapcache consistent on multi-node deployments.
+type Broadcaster struct {
+	*Proxy
+	//ID broadcaster id used to ignore messages published by self.
+	ID string
+	//Name cache name.Only messages with same name will be applied.
+	Name string
+	//Transport broadcast transport.
+	Transport BroadcastTransport
+	//InvalidateOnWrite whether publish Del operation after data set or updated.
+	InvalidateOnWrite bool
+	//OnError hook called with error raised when applying message published by peers.
+	OnError func(err error)
+}
+
+//NewBroadcaster create new broadcaster with given cacheable,cache name and transport.
+func NewBroadcaster(c Cacheable, name string, t BroadcastTransport) *Broadcaster {
+	id, err := RandMaskedBytes(TokenMask, 16)
+	if err != nil {
+		panic(err)
+	}
+	return &Broadcaster{
+		Proxy:     NewProxy(c),
+		ID:        string(id),
+		Name:      name,
+		Transport: t,
+	}
+}
+
+//Start start receiving messages from peers.
+//Return any error if raised.
+func (b *Broadcaster) Start() error {
+	return b.Transport.Subscribe(b.handle)
+}
+
+func (b *Broadcaster) handle(data []byte) {
+	i := &Invalidation{}
+	err := json.Unmarshal(data, i)
+	if err == nil {
+		err = b.Apply(i)
+	}
+	if err != nil && b.OnError != nil {
+		b.OnError(err)
+	}
+}
+
+//Apply apply invalidation message to wrapped cacheable without publishing.
+//Messages published by self or with different cache name will be ignored.
+//Return any error if raised.
+func (b *Broadcaster) Apply(i *Invalidation) error {
+	if i.Source == b.ID || i.Name != b.Name {
+		return nil
+	}
+	switch i.Op {
+	case OpDel:
+		return b.Cacheable.Del(i.Key)
+	case OpFlush:
+		return b.Cacheable.Flush()
+	}
+	return nil
+}
+
+func (b *Broadcaster) publish(op string, key string) error {
+	data, err := json.Marshal(&Invalidation{
+		Source: b.ID,
+		Name:   b.Name,
+		Op:     op,
+		Key:    key,
+	})
+	if err != nil {
+		return err
+	}
+	return b.Transport.Publish(data)
+}
+
+//Del Delete data in cache by given name and publish to peers.
+//Return any error raised.
+func (b *Broadcaster) Del(key string) error {
+	err := b.Cacheable.Del(key)
+	if err != nil {
+		return err
+	}
+	return b.publish(OpDel, key)
+}
+
+//Flush Delete all data in cache and publish to peers.
+//Return any error if raised.
+func (b *Broadcaster) Flush() error {
+	err := b.Cacheable.Flush()
+	if err != nil {
+		return err
+	}
+	return b.publish(OpFlush, "")
+}
+
+func (b *Broadcaster) afterWrite(key string, err error) error {
+	if err != nil || !b.InvalidateOnWrite {
+		return err
+	}
+	return b.publish(OpDel, key)
+}
+
+//Set Set data model to cache by given key.
+//Del operation will be published if InvalidateOnWrite is true.
+//Return any error raised.
+func (b *Broadcaster) Set(key string, v interface{}, ttl time.Duration) error {
+	return b.afterWrite(key, b.Cacheable.Set(key, v, ttl))
+}
+
+//Update Update data model to cache by given key only if the cache exist.
+//Del operation will be published if InvalidateOnWrite is true.
+//Return any error raised.
+func (b *Broadcaster) Update(key string, v interface{}, ttl time.Duration) error {
+	return b.afterWrite(key, b.Cacheable.Update(key, v, ttl))
+}
+
+//SetBytesValue Set bytes data to cache by given key.
+//Del operation will be published if InvalidateOnWrite is true.
+//Return any error raised.
+func (b *Broadcaster) SetBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	return b.afterWrite(key, b.Cacheable.SetBytesValue(key, bytes, ttl))
+}
+
+//UpdateBytesValue Update bytes data to cache by given key only if the cache exist.
+//Del operation will be published if InvalidateOnWrite is true.
+//Return any error raised.
+func (b *Broadcaster) UpdateBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	return b.afterWrite(key, b.Cacheable.UpdateBytesValue(key, bytes, ttl))
+}
+
+//MSetBytesValue set multiple bytes data to cache with given key-value map.
+//Del operations will be published if InvalidateOnWrite is true.
+//Return  any error if raised.
+func (b *Broadcaster) MSetBytesValue(data map[string][]byte, ttl time.Duration) error {
+	err := b.Cacheable.MSetBytesValue(data, ttl)
+	if err != nil {
+		return err
+	}
+	for k := range data {
+		err = b.afterWrite(k, nil)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Close close transport and wrapped cacheable.
+//Return any error if raised.
+func (b *Broadcaster) Close() error {
+	err := b.Transport.Close()
+	if err != nil {
+		return err
+	}
+	return b.Cacheable.Close()
+}
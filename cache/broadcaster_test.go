@@ -0,0 +1,104 @@
+package cache_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+type testBus struct {
+	locker   sync.Mutex
+	handlers []func(data []byte)
+}
+
+func (b *testBus) Transport() *testTransport {
+	return &testTransport{bus: b}
+}
+
+type testTransport struct {
+	bus *testBus
+}
+
+func (t *testTransport) Publish(data []byte) error {
+	t.bus.locker.Lock()
+	handlers := t.bus.handlers
+	t.bus.locker.Unlock()
+	for _, h := range handlers {
+		h(data)
+	}
+	return nil
+}
+
+func (t *testTransport) Subscribe(handler func(data []byte)) error {
+	t.bus.locker.Lock()
+	t.bus.handlers = append(t.bus.handlers, handler)
+	t.bus.locker.Unlock()
+	return nil
+}
+
+func (t *testTransport) Close() error {
+	return nil
+}
+
+func TestBroadcaster(t *testing.T) {
+	bus := &testBus{}
+	c1 := newTestCache(3600)
+	c2 := newTestCache(3600)
+	c3 := newTestCache(3600)
+	b1 := cache.NewBroadcaster(c1, "test", bus.Transport())
+	b2 := cache.NewBroadcaster(c2, "test", bus.Transport())
+	b3 := cache.NewBroadcaster(c3, "other", bus.Transport())
+	for _, b := range []*cache.Broadcaster{b1, b2, b3} {
+		err := b.Start()
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = b.SetBytesValue("test", []byte("value"), cache.DefaultTTL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = b.SetBytesValue("test2", []byte("value"), cache.DefaultTTL)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := b1.Del("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c1.GetBytesValue("test")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	_, err = c2.GetBytesValue("test")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	_, err = c3.GetBytesValue("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b2.InvalidateOnWrite = true
+	err = b2.SetBytesValue("test2", []byte("newvalue"), cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := c2.GetBytesValue("test2")
+	if err != nil || string(bs) != "newvalue" {
+		t.Fatal(string(bs), err)
+	}
+	_, err = c1.GetBytesValue("test2")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	err = b3.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c3.GetBytesValue("test")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+}
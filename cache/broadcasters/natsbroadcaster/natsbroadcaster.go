@@ -0,0 +1,87 @@
+//Package natsbroadcaster provides cache broadcast transport uses nats.
+package natsbroadcaster
+
+import (
+	"sync"
+
+	"github.com/nats-io/nats.go"
+)
+
+//DefaultSubject default nats subject.
+var DefaultSubject = "herb.cache.invalidation"
+
+//Transport nats broadcast transport.
+type Transport struct {
+	//Conn nats connection.
+	Conn *nats.Conn
+	//Subject nats subject.
+	Subject string
+	sub     *nats.Subscription
+	locker  sync.Mutex
+}
+
+//Publish publish message data to all peers.
+//Return any error if raised.
+func (t *Transport) Publish(data []byte) error {
+	return t.Conn.Publish(t.Subject, data)
+}
+
+//Subscribe start receiving message data from peers.
+//Handler will be called with every message received.
+//Return any error if raised.
+func (t *Transport) Subscribe(handler func(data []byte)) error {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+	sub, err := t.Conn.Subscribe(t.Subject, func(m *nats.Msg) {
+		handler(m.Data)
+	})
+	if err != nil {
+		return err
+	}
+	t.sub = sub
+	return nil
+}
+
+//Close close transport.
+//Return any error if raised.
+func (t *Transport) Close() error {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+	if t.sub != nil {
+		t.sub.Unsubscribe()
+		t.sub = nil
+	}
+	t.Conn.Close()
+	return nil
+}
+
+//Config nats transport config.
+type Config struct {
+	//URL nats server url.
+	//nats.DefaultURL will be used if empty.
+	URL string
+	//Subject nats subject.
+	//DefaultSubject will be used if empty.
+	Subject string
+}
+
+//Create create new transport.
+//Return transport created and any error if raised.
+func (c *Config) Create() (*Transport, error) {
+	url := c.URL
+	if url == "" {
+		url = nats.DefaultURL
+	}
+	conn, err := nats.Connect(url)
+	if err != nil {
+		return nil, err
+	}
+	t := &Transport{
+		Conn:    conn,
+		Subject: c.Subject,
+	}
+	if t.Subject == "" {
+		t.Subject = DefaultSubject
+	}
+	return t, nil
+}
@@ -0,0 +1,93 @@
+//Package redisbroadcaster provides cache broadcast transport uses redis pub/sub.
+package redisbroadcaster
+
+import (
+	"sync"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/herb-go/datasource/redis/redispool"
+)
+
+//DefaultChannel default redis channel.
+var DefaultChannel = "herb-cache-invalidation"
+
+//Transport redis pub/sub broadcast transport.
+type Transport struct {
+	//Pool redis pool.
+	Pool *redis.Pool
+	//Channel redis channel.
+	Channel string
+	psc     *redis.PubSubConn
+	locker  sync.Mutex
+}
+
+//Publish publish message data to all peers.
+//Return any error if raised.
+func (t *Transport) Publish(data []byte) error {
+	conn := t.Pool.Get()
+	defer conn.Close()
+	_, err := conn.Do("PUBLISH", t.Channel, data)
+	return err
+}
+
+//Subscribe start receiving message data from peers.
+//Handler will be called with every message received.
+//Return any error if raised.
+func (t *Transport) Subscribe(handler func(data []byte)) error {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+	psc := &redis.PubSubConn{Conn: t.Pool.Get()}
+	err := psc.Subscribe(t.Channel)
+	if err != nil {
+		psc.Close()
+		return err
+	}
+	t.psc = psc
+	go func() {
+		for {
+			switch v := psc.Receive().(type) {
+			case redis.Message:
+				handler(v.Data)
+			case error:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+//Close close transport.
+//Return any error if raised.
+func (t *Transport) Close() error {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+	if t.psc != nil {
+		t.psc.Unsubscribe()
+		t.psc.Close()
+		t.psc = nil
+	}
+	return t.Pool.Close()
+}
+
+//Config redis transport config.
+type Config struct {
+	redispool.Config
+	//Channel redis channel.
+	//DefaultChannel will be used if empty.
+	Channel string
+}
+
+//Create create new transport.
+//Return transport created and any error if raised.
+func (c *Config) Create() (*Transport, error) {
+	p := redispool.New()
+	c.Config.ApplyTo(p)
+	t := &Transport{
+		Pool:    p.Open(),
+		Channel: c.Channel,
+	}
+	if t.Channel == "" {
+		t.Channel = DefaultChannel
+	}
+	return t, nil
+}
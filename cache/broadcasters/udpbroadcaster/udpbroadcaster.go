@@ -0,0 +1,104 @@
+//Package udpbroadcaster provides cache broadcast transport uses udp multicast.
+package udpbroadcaster
+
+import (
+	"net"
+	"sync"
+)
+
+//DefaultAddress default multicast address.
+var DefaultAddress = "239.255.77.77:7777"
+
+//MaxMessageSize max size of message received.
+var MaxMessageSize = 65507
+
+//Transport udp multicast broadcast transport.
+type Transport struct {
+	addr     *net.UDPAddr
+	iface    *net.Interface
+	sender   *net.UDPConn
+	listener *net.UDPConn
+	locker   sync.Mutex
+}
+
+//Publish publish message data to all peers.
+//Return any error if raised.
+func (t *Transport) Publish(data []byte) error {
+	_, err := t.sender.Write(data)
+	return err
+}
+
+//Subscribe start receiving message data from peers.
+//Handler will be called with every message received.
+//Return any error if raised.
+func (t *Transport) Subscribe(handler func(data []byte)) error {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+	listener, err := net.ListenMulticastUDP("udp", t.iface, t.addr)
+	if err != nil {
+		return err
+	}
+	t.listener = listener
+	go func() {
+		buf := make([]byte, MaxMessageSize)
+		for {
+			n, _, err := listener.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+			data := make([]byte, n)
+			copy(data, buf[:n])
+			handler(data)
+		}
+	}()
+	return nil
+}
+
+//Close close transport.
+//Return any error if raised.
+func (t *Transport) Close() error {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+	if t.listener != nil {
+		t.listener.Close()
+		t.listener = nil
+	}
+	return t.sender.Close()
+}
+
+//Config udp transport config.
+type Config struct {
+	//Address multicast address.
+	//DefaultAddress will be used if empty.
+	Address string
+	//Interface network interface name used to receive multicast messages.
+	//System default interface will be used if empty.
+	Interface string
+}
+
+//Create create new transport.
+//Return transport created and any error if raised.
+func (c *Config) Create() (*Transport, error) {
+	address := c.Address
+	if address == "" {
+		address = DefaultAddress
+	}
+	addr, err := net.ResolveUDPAddr("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	t := &Transport{
+		addr: addr,
+	}
+	if c.Interface != "" {
+		t.iface, err = net.InterfaceByName(c.Interface)
+		if err != nil {
+			return nil, err
+		}
+	}
+	t.sender, err = net.DialUDP("udp", nil, addr)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
@@ -0,0 +1,36 @@
+package udpbroadcaster
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTransport(t *testing.T) {
+	c := &Config{
+		Address: "239.255.77.78:17777",
+	}
+	transport, err := c.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer transport.Close()
+	received := make(chan []byte, 1)
+	err = transport.Subscribe(func(data []byte) {
+		received <- data
+	})
+	if err != nil {
+		t.Skip("multicast not avaliable", err)
+	}
+	err = transport.Publish([]byte("test"))
+	if err != nil {
+		t.Skip("multicast not avaliable", err)
+	}
+	select {
+	case data := <-received:
+		if string(data) != "test" {
+			t.Fatal(string(data))
+		}
+	case <-time.After(time.Second):
+		t.Skip("multicast loopback not avaliable")
+	}
+}
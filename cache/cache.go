@@ -2,8 +2,14 @@
 package cache
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"reflect"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 )
@@ -23,6 +29,9 @@ var (
 	ErrFeatureNotSupported = errors.New("Feature is not supported")
 	//ErrTTLNotAvaliable raised when ttl not avaliable.
 	ErrTTLNotAvaliable = errors.New("TTL not avaliable")
+	//ErrNegativeCached raised when loader "not found" result is cached.
+	//errors.Is(ErrNegativeCached, ErrNotFound) returns true.
+	ErrNegativeCached = fmt.Errorf("Negative cached: %w", ErrNotFound)
 )
 
 //DefaultTTL means use cache default ttl setting.
@@ -30,8 +39,10 @@ var DefaultTTL = time.Duration(0)
 
 var (
 	//KeyPrefix default key prefix
-	KeyPrefix    = string([]byte{0})
-	intKeyPrefix = string([]byte{69, 0})
+	KeyPrefix         = string([]byte{0})
+	intKeyPrefix      = string([]byte{69, 0})
+	hashedKeyPrefix   = string([]byte{72, 0})
+	negativeKeySuffix = string([]byte{0, 78})
 )
 
 //Key return cache key
@@ -45,17 +56,96 @@ func New() *Cache {
 	hit := int64(0)
 	miss := int64(0)
 	return &Cache{
-		hit:  &hit,
-		miss: &miss,
+		hit:   &hit,
+		miss:  &miss,
+		hooks: NewHooks(DefaultHooksQueueSize),
+		calls: &sync.WaitGroup{},
 	}
 }
 
 //Cache Cache stores the cache Driver and default ttl.
 type Cache struct {
 	Driver
-	TTL  time.Duration
-	hit  *int64
-	miss *int64
+	TTL time.Duration
+	//Compressor value compressor.
+	//Values will not be compressed if nil.
+	Compressor *Compressor
+	//Encryptor value encryptor.
+	//Values will be encrypted after compressed.
+	//Values will not be encrypted if nil.
+	Encryptor *Encryptor
+	//MaxEntrySize max size in bytes of value stored in driver,after compressed and encrypted.
+	//Larger values will be rejected with ErrEntryTooLarge.
+	//Value size will not be limited if not positive.
+	MaxEntrySize int
+	//KeyHashThreshold max size in bytes of final key passed to driver.
+	//Larger keys will be replaced by prefixed hex encoded sha256 sum,which is 66 bytes.
+	//Keys will not be hashed if not positive.
+	KeyHashThreshold int
+	//MinTTL min ttl of values and counters.
+	//Smaller ttl will be clamped to MinTTL.
+	//Ttl will not be clamped if not positive.
+	MinTTL time.Duration
+	//MaxTTL max ttl of values and counters.
+	//Larger ttl will be clamped to MaxTTL.
+	//Ttl will not be clamped if not positive.
+	MaxTTL time.Duration
+	//OnTTLClamped hook called with key,given ttl and clamped ttl when ttl is clamped.
+	//Key is empty when called by MSetBytesValue.
+	OnTTLClamped func(key string, ttl time.Duration, clamped time.Duration)
+	//OperationTimeout max duration of every driver call.
+	//Calls not finished in time fail with ErrOperationTimeout.
+	//Calls are not limited if not positive.
+	OperationTimeout time.Duration
+	//RetryCount max times to retry driver calls failed with retryable error.
+	//Non-idempotent calls like IncrCounter and calls timed out are never retried.
+	RetryCount int
+	//RetryBackoff duration to wait before first retry,doubled after every retry.
+	RetryBackoff time.Duration
+	hit          *int64
+	miss         *int64
+	hooks        *Hooks
+	reloadLocker sync.RWMutex
+	onEvicted    func(key string, value []byte)
+	calls        *sync.WaitGroup
+}
+
+func (c *Cache) encode(bs []byte) ([]byte, error) {
+	var err error
+	if c.Compressor != nil {
+		bs, err = c.Compressor.Encode(bs)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if c.Encryptor != nil {
+		bs, err = c.Encryptor.Encrypt(bs)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if c.MaxEntrySize > 0 && len(bs) > c.MaxEntrySize {
+		return nil, ErrEntryTooLarge
+	}
+	return bs, nil
+}
+
+func (c *Cache) decode(bs []byte) ([]byte, error) {
+	return decodeValue(bs, c.Encryptor, c.Compressor)
+}
+
+func decodeValue(bs []byte, encryptor *Encryptor, compressor *Compressor) ([]byte, error) {
+	var err error
+	if encryptor != nil {
+		bs, err = encryptor.Decrypt(bs)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if compressor != nil {
+		return compressor.Decode(bs)
+	}
+	return bs, nil
 }
 
 //Hit return cache hit count
@@ -68,8 +158,33 @@ func (c *Cache) Miss() int64 {
 	return atomic.LoadInt64(c.miss)
 }
 
+func (c *Cache) hashKey(k string) string {
+	if c.KeyHashThreshold <= 0 || len(k) <= c.KeyHashThreshold {
+		return k
+	}
+	sum := sha256.Sum256([]byte(k))
+	return hashedKeyPrefix + hex.EncodeToString(sum[:])
+}
+
+func (c *Cache) clampTTL(key string, ttl time.Duration) time.Duration {
+	if ttl < 0 {
+		return ttl
+	}
+	clamped := ttl
+	if c.MinTTL > 0 && clamped < c.MinTTL {
+		clamped = c.MinTTL
+	}
+	if c.MaxTTL > 0 && clamped > c.MaxTTL {
+		clamped = c.MaxTTL
+	}
+	if clamped != ttl && c.OnTTLClamped != nil {
+		c.OnTTLClamped(key, ttl, clamped)
+	}
+	return clamped
+}
+
 func (c *Cache) getKey(key string) string {
-	return Key(key)
+	return c.hashKey(Key(key))
 }
 
 //Init init cache with option
@@ -77,96 +192,241 @@ func (c *Cache) Init(option Option) error {
 	return option.ApplyTo(c)
 }
 
+//Reload apply option to a new driver,then swap driver and settings into cache atomically.
+//Hooks,hit and miss counters and OnTTLClamped hook are kept.
+//Old driver will be closed after in-flight operations finish,including operations timed out but still running.
+//Cache will not be changed if option cannot be applied.
+//Return any error if raised.
+func (c *Cache) Reload(option Option) error {
+	n := New()
+	err := option.ApplyTo(n)
+	if err != nil {
+		return err
+	}
+	c.reloadLocker.Lock()
+	old := c.Driver
+	c.Driver = n.Driver
+	c.TTL = n.TTL
+	c.Compressor = n.Compressor
+	c.Encryptor = n.Encryptor
+	c.MaxEntrySize = n.MaxEntrySize
+	c.KeyHashThreshold = n.KeyHashThreshold
+	c.MinTTL = n.MinTTL
+	c.MaxTTL = n.MaxTTL
+	c.OperationTimeout = n.OperationTimeout
+	c.RetryCount = n.RetryCount
+	c.RetryBackoff = n.RetryBackoff
+	calls := c.calls
+	c.calls = n.calls
+	c.registerEvicted()
+	c.reloadLocker.Unlock()
+	if old == nil {
+		return nil
+	}
+	waitCalls(calls)
+	return old.Close()
+}
+
+//Util return util of cache driver.
+func (c *Cache) Util() *Util {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
+	return c.Driver.Util()
+}
+
+//SetUtil set util of cache driver.
+func (c *Cache) SetUtil(u *Util) {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
+	c.Driver.SetUtil(u)
+}
+
+//SetGCErrHandler Set callback to handler error raised when gc.
+func (c *Cache) SetGCErrHandler(f func(err error)) {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
+	c.Driver.SetGCErrHandler(f)
+}
+
+//Flush Delete all data in cache.
+//Return any error if raised.
+func (c *Cache) Flush() error {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
+	return c.Driver.Flush()
+}
+
+//getBytesValue get raw bytes from driver with operation timeout and retry policy.
+//Reload locker should be held by caller.
+func (c *Cache) getBytesValue(key string) ([]byte, error) {
+	v, err := c.call(func(d Driver) (interface{}, error) {
+		return d.GetBytesValue(key)
+	})
+	bs, _ := v.([]byte)
+	return bs, err
+}
+
 //Set Set data model to cache by given key.
 //If ttl is DefaultTTL(0),use default ttl in config instead.
 //Return any error raised.
 func (c *Cache) Set(key string, v interface{}, ttl time.Duration) error {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
 	if key == "" {
 		return ErrKeyUnavailable
 	}
 	if ttl == DefaultTTL {
 		ttl = c.TTL
 	}
+	ttl = c.clampTTL(key, ttl)
 	bs, err := c.Driver.Util().Marshaler.Marshal(v)
 	if err != nil {
-		return err
+		return WrapKeyError(LayerMarshaler, key, err)
 	}
-	return c.Driver.SetBytesValue(c.getKey(key), bs, ttl)
+	data, err := c.encode(bs)
+	if err != nil {
+		return WrapKeyError(LayerCodec, key, err)
+	}
+	k := c.getKey(key)
+	err = c.exec(func(d Driver) error {
+		return d.SetBytesValue(k, data, ttl)
+	})
+	err = WrapKeyError(LayerDriver, key, err)
+	c.hooks.emit(OpSet, key, len(bs), err)
+	return err
 }
 
 //Update Update data model to cache by given key only if the cache exist.
 //If ttl is DefaultTTL(0),use default ttl in config instead.
 //Return any error raised.
 func (c *Cache) Update(key string, v interface{}, ttl time.Duration) error {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
 	if key == "" {
 		return ErrKeyUnavailable
 	}
 	if ttl == DefaultTTL {
 		ttl = c.TTL
 	}
+	ttl = c.clampTTL(key, ttl)
 	bs, err := c.Driver.Util().Marshaler.Marshal(v)
 	if err != nil {
-		return err
+		return WrapKeyError(LayerMarshaler, key, err)
+	}
+	data, err := c.encode(bs)
+	if err != nil {
+		return WrapKeyError(LayerCodec, key, err)
 	}
-	return c.Driver.UpdateBytesValue(c.getKey(key), bs, ttl)
+	k := c.getKey(key)
+	err = c.exec(func(d Driver) error {
+		return d.UpdateBytesValue(k, data, ttl)
+	})
+	err = WrapKeyError(LayerDriver, key, err)
+	c.hooks.emit(OpUpdate, key, len(bs), err)
+	return err
 }
 
 //Get Get data model from cache by given key.
 //Parameter v should be pointer to empty data model which data filled in.
 //Return any error raised.
 func (c *Cache) Get(key string, v interface{}) error {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
 	if key == "" {
 		return ErrKeyUnavailable
 	}
-	bs, err := c.Driver.GetBytesValue(c.getKey(key))
+	data, err := c.getBytesValue(c.getKey(key))
+	if err != nil {
+		err = WrapKeyError(LayerDriver, key, err)
+		c.hooks.emit(OpGet, key, 0, err)
+		return err
+	}
+	bs, err := c.decode(data)
+	err = WrapKeyError(LayerCodec, key, err)
+	c.hooks.emit(OpGet, key, len(bs), err)
 	if err != nil {
 		return err
 	}
-	return c.Driver.Util().Marshaler.Unmarshal(bs, v)
+	return WrapKeyError(LayerMarshaler, key, c.Driver.Util().Marshaler.Unmarshal(bs, v))
 }
 
 //SetBytesValue Set bytes data to cache by given key.
 //If ttl is DefaultTTL(0),use default ttl in config instead.
 //Return any error raised.
 func (c *Cache) SetBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
 	if key == "" {
 		return ErrKeyUnavailable
 	}
 	if ttl == DefaultTTL {
 		ttl = c.TTL
 	}
+	ttl = c.clampTTL(key, ttl)
 	if ttl < 0 {
 		return ErrTTLNotAvaliable
 	}
-	return c.Driver.SetBytesValue(c.getKey(key), bytes, ttl)
+	data, err := c.encode(bytes)
+	if err != nil {
+		return WrapKeyError(LayerCodec, key, err)
+	}
+	k := c.getKey(key)
+	err = c.exec(func(d Driver) error {
+		return d.SetBytesValue(k, data, ttl)
+	})
+	err = WrapKeyError(LayerDriver, key, err)
+	c.hooks.emit(OpSet, key, len(bytes), err)
+	return err
 }
 
 //UpdateBytesValue Update bytes data to cache by given key only if the cache exist.
 //If ttl is DefaultTTL(0),use default ttl in config instead.
 //Return any error raised.
 func (c *Cache) UpdateBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
 	if key == "" {
 		return ErrKeyUnavailable
 	}
 	if ttl == DefaultTTL {
 		ttl = c.TTL
 	}
+	ttl = c.clampTTL(key, ttl)
 	if ttl < 0 {
 		return ErrTTLNotAvaliable
 	}
-	return c.Driver.UpdateBytesValue(c.getKey(key), bytes, ttl)
+	data, err := c.encode(bytes)
+	if err != nil {
+		return WrapKeyError(LayerCodec, key, err)
+	}
+	k := c.getKey(key)
+	err = c.exec(func(d Driver) error {
+		return d.UpdateBytesValue(k, data, ttl)
+	})
+	err = WrapKeyError(LayerDriver, key, err)
+	c.hooks.emit(OpUpdate, key, len(bytes), err)
+	return err
 }
 
 //GetBytesValue Get bytes data from cache by given key.
 //Return data bytes and any error raised.
 func (c *Cache) GetBytesValue(key string) ([]byte, error) {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
 	if key == "" {
 		return nil, ErrKeyUnavailable
 	}
-	bs, err := c.Driver.GetBytesValue(c.getKey(key))
-	if err != nil {
+	bs, err := c.getBytesValue(c.getKey(key))
+	if err == nil {
+		bs, err = c.decode(bs)
+		err = WrapKeyError(LayerCodec, key, err)
+	} else {
+		err = WrapKeyError(LayerDriver, key, err)
+	}
+	c.hooks.emit(OpGet, key, len(bs), err)
+	if err == nil {
 		atomic.AddInt64(c.hit, 1)
-	} else if err == ErrNotFound {
+	} else if errors.Is(err, ErrNotFound) {
 		atomic.AddInt64(c.miss, 1)
 	}
 	return bs, err
@@ -175,18 +435,29 @@ func (c *Cache) GetBytesValue(key string) ([]byte, error) {
 //MGetBytesValue get multiple bytes data from cache by given keys.
 //Return data bytes map and any error if raised.
 func (c *Cache) MGetBytesValue(keys ...string) (map[string][]byte, error) {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
 	var result map[string][]byte
 	var prefixedKeys = make([]string, len(keys))
+	var originalKeys = make(map[string]string, len(keys))
 	for k := range keys {
 		prefixedKeys[k] = c.getKey(keys[k])
+		originalKeys[prefixedKeys[k]] = keys[k]
 	}
-	data, err := c.Driver.MGetBytesValue(prefixedKeys...)
+	v, err := c.call(func(d Driver) (interface{}, error) {
+		return d.MGetBytesValue(prefixedKeys...)
+	})
 	if err != nil {
 		return result, err
 	}
+	data, _ := v.(map[string][]byte)
 	result = make(map[string][]byte, len(data))
 	for k := range data {
-		result[k[len(KeyPrefix):]] = data[k]
+		bs, err := c.decode(data[k])
+		if err != nil {
+			return nil, WrapKeyError(LayerCodec, originalKeys[k], err)
+		}
+		result[originalKeys[k]] = bs
 	}
 	atomic.AddInt64(c.hit, int64(len(data)))
 	atomic.AddInt64(c.miss, int64(len(keys)-len(data)))
@@ -196,114 +467,525 @@ func (c *Cache) MGetBytesValue(keys ...string) (map[string][]byte, error) {
 //MSetBytesValue set multiple bytes data to cache with given key-value map.
 //Return  any error if raised.
 func (c *Cache) MSetBytesValue(data map[string][]byte, ttl time.Duration) error {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
 	var prefixed = make(map[string][]byte, len(data))
 	for k := range data {
-		prefixed[c.getKey(k)] = data[k]
+		bs, err := c.encode(data[k])
+		if err != nil {
+			return WrapKeyError(LayerCodec, k, err)
+		}
+		prefixed[c.getKey(k)] = bs
 	}
 	if ttl == DefaultTTL {
 		ttl = c.TTL
 	}
+	ttl = c.clampTTL("", ttl)
 	if ttl < 0 {
 		return ErrTTLNotAvaliable
 	}
-	return c.Driver.MSetBytesValue(prefixed, ttl)
+	err := c.exec(func(d Driver) error {
+		return d.MSetBytesValue(prefixed, ttl)
+	})
+	for k := range data {
+		c.hooks.emit(OpSet, k, len(data[k]), err)
+	}
+	return err
 }
 
 //Del Delete data in cache by given name.
 //Return any error raised.
 func (c *Cache) Del(key string) error {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
 	if key == "" {
 		return ErrKeyUnavailable
 	}
-	return c.Driver.Del(c.getKey(key))
+	k := c.getKey(key)
+	err := c.exec(func(d Driver) error {
+		return d.Del(k)
+	})
+	err = WrapKeyError(LayerDriver, key, err)
+	c.hooks.emit(OpDel, key, 0, err)
+	return err
 }
 
 //Expire set cache value expire duration by given key and ttl
 func (c *Cache) Expire(key string, ttl time.Duration) error {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
 	if key == "" {
 		return ErrKeyUnavailable
 	}
 	if ttl == DefaultTTL {
 		ttl = c.TTL
 	}
+	ttl = c.clampTTL(key, ttl)
 	if ttl < 0 {
 		return ErrTTLNotAvaliable
 	}
-	err := c.Driver.Expire(c.getKey(key), ttl)
-	if err == ErrNotFound {
+	k := c.getKey(key)
+	err := c.exec(func(d Driver) error {
+		return d.Expire(k, ttl)
+	})
+	if errors.Is(err, ErrNotFound) {
 		err = nil
 	}
+	err = WrapKeyError(LayerDriver, key, err)
+	c.hooks.emit(OpExpire, key, 0, err)
 	return err
 }
 
+//GetTTL get remaining ttl of given key.
+//Return ttl and any error raised.
+//Return ErrFeatureNotSupported if driver does not support CapabilityTTL.
+func (c *Cache) GetTTL(key string) (time.Duration, error) {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
+	if key == "" {
+		return 0, ErrKeyUnavailable
+	}
+	d, ok := c.Driver.(TTLGetter)
+	if !ok || !DriverCapabilities(c.Driver).Has(CapabilityTTL) {
+		return 0, ErrFeatureNotSupported
+	}
+	ttl, err := d.GetTTL(c.getKey(key))
+	return ttl, WrapKeyError(LayerDriver, key, err)
+}
+
+//SetIfNotExists Set bytes data to cache by given key only if the key does not exist.
+//If ttl is DefaultTTL(0),use default ttl in config instead.
+//Driver native implement will be used if driver implements NXSetter,
+//otherwise util locker will be used,which is only atomic in current process.
+//Return whether data is set and any error raised.
+func (c *Cache) SetIfNotExists(key string, bytes []byte, ttl time.Duration) (bool, error) {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
+	if key == "" {
+		return false, ErrKeyUnavailable
+	}
+	if ttl == DefaultTTL {
+		ttl = c.TTL
+	}
+	ttl = c.clampTTL(key, ttl)
+	if ttl < 0 {
+		return false, ErrTTLNotAvaliable
+	}
+	k := c.getKey(key)
+	bytes, err := c.encode(bytes)
+	if err != nil {
+		return false, WrapKeyError(LayerCodec, key, err)
+	}
+	d, ok := c.Driver.(NXSetter)
+	if ok && DriverCapabilities(c.Driver).Has(CapabilityNX) {
+		ok, err := d.SetIfNotExists(k, bytes, ttl)
+		return ok, WrapKeyError(LayerDriver, key, err)
+	}
+	locker, _ := c.Driver.Util().Locker(k)
+	locker.Lock()
+	defer locker.Unlock()
+	_, err = c.Driver.GetBytesValue(k)
+	if err == nil {
+		return false, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return false, WrapKeyError(LayerDriver, key, err)
+	}
+	err = c.Driver.SetBytesValue(k, bytes, ttl)
+	if err != nil {
+		return false, WrapKeyError(LayerDriver, key, err)
+	}
+	return true, nil
+}
+
+//GetWithVersion Get bytes data and value version from cache by given key.
+//Version is generated by ValueVersion function with bytes stored in driver.
+//Return data bytes,version and any error raised.
+func (c *Cache) GetWithVersion(key string) ([]byte, string, error) {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
+	if key == "" {
+		return nil, "", ErrKeyUnavailable
+	}
+	data, err := c.getBytesValue(c.getKey(key))
+	if err != nil {
+		err = WrapKeyError(LayerDriver, key, err)
+		c.hooks.emit(OpGet, key, 0, err)
+		return nil, "", err
+	}
+	bs, err := c.decode(data)
+	err = WrapKeyError(LayerCodec, key, err)
+	c.hooks.emit(OpGet, key, len(bs), err)
+	if err != nil {
+		return nil, "", err
+	}
+	return bs, ValueVersion(data), nil
+}
+
+//SetIfVersion Set bytes data to cache by given key only if current value version equals given version.
+//Empty version means key should not exist.
+//If ttl is DefaultTTL(0),use default ttl in config instead.
+//Driver native implement will be used if driver implements CASSetter,
+//otherwise util locker will be used,which is only atomic in current process.
+//Return whether data is set and any error raised.
+func (c *Cache) SetIfVersion(key string, bytes []byte, version string, ttl time.Duration) (bool, error) {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
+	if key == "" {
+		return false, ErrKeyUnavailable
+	}
+	if ttl == DefaultTTL {
+		ttl = c.TTL
+	}
+	ttl = c.clampTTL(key, ttl)
+	if ttl < 0 {
+		return false, ErrTTLNotAvaliable
+	}
+	k := c.getKey(key)
+	bytes, err := c.encode(bytes)
+	if err != nil {
+		return false, WrapKeyError(LayerCodec, key, err)
+	}
+	d, ok := c.Driver.(CASSetter)
+	if ok && DriverCapabilities(c.Driver).Has(CapabilityCAS) {
+		ok, err := d.SetIfVersion(k, bytes, version, ttl)
+		return ok, WrapKeyError(LayerDriver, key, err)
+	}
+	locker, _ := c.Driver.Util().Locker(k)
+	locker.Lock()
+	defer locker.Unlock()
+	bs, err := c.Driver.GetBytesValue(k)
+	if errors.Is(err, ErrNotFound) {
+		if version != "" {
+			return false, nil
+		}
+	} else if err != nil {
+		return false, WrapKeyError(LayerDriver, key, err)
+	} else if version != ValueVersion(bs) {
+		return false, nil
+	}
+	err = c.Driver.SetBytesValue(k, bytes, ttl)
+	if err != nil {
+		return false, WrapKeyError(LayerDriver, key, err)
+	}
+	return true, nil
+}
+
+//Keys list keys with given prefix from given cursor.
+//Empty cursor means start from beginning.
+//Count is a hint of max keys returned in one page.
+//Counter keys and keys hashed by KeyHashThreshold are not included.
+//Return keys,next cursor and any error raised.
+//Empty next cursor means iteration finished.
+//Return ErrFeatureNotSupported if driver does not support CapabilityIteration.
+func (c *Cache) Keys(prefix string, cursor string, count int) ([]string, string, error) {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
+	d, ok := c.Driver.(Iterable)
+	if !ok || !DriverCapabilities(c.Driver).Has(CapabilityIteration) {
+		return nil, "", ErrFeatureNotSupported
+	}
+	keys, next, err := d.Keys(Key(prefix), cursor, count)
+	if err != nil {
+		return nil, "", err
+	}
+	return trimKeys(keys, KeyPrefix), next, nil
+}
+
+//trimKeys trim given prefix from keys.
+//Keys without prefix and negative cache markers are skipped.
+func trimKeys(keys []string, prefix string) []string {
+	result := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if strings.HasPrefix(k, prefix) && !strings.HasSuffix(k, negativeKeySuffix) {
+			result = append(result, k[len(prefix):])
+		}
+	}
+	return result
+}
+
 func (c *Cache) getIntKey(key string) string {
-	return intKeyPrefix + key
+	return c.hashKey(intKeyPrefix + key)
 }
 
 //IncrCounter Increase int val in cache by given key.Count cache and data cache are in two independent namespace.
 //If ttl is DefaultTTL(0),use default ttl in config instead.
 //Return int data value and any error raised.
 func (c *Cache) IncrCounter(key string, increment int64, ttl time.Duration) (int64, error) {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
 	if key == "" {
 		return 0, ErrKeyUnavailable
 	}
 	if ttl == DefaultTTL {
 		ttl = c.TTL
 	}
-	return c.Driver.IncrCounter(c.getIntKey(key), increment, ttl)
+	ttl = c.clampTTL(key, ttl)
+	k := c.getIntKey(key)
+	v, err := c.callNoRetry(func(d Driver) (interface{}, error) {
+		return d.IncrCounter(k, increment, ttl)
+	})
+	result, _ := v.(int64)
+	return result, WrapKeyError(LayerDriver, key, err)
 }
 
 //SetCounter Set int val in cache by given key.Count cache and data cache are in two independent namespace.
 //If ttl is DefaultTTL(0),use default ttl in config instead.
 //Return any error raised.
 func (c *Cache) SetCounter(key string, v int64, ttl time.Duration) error {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
 	if key == "" {
 		return ErrKeyUnavailable
 	}
 	if ttl == DefaultTTL {
 		ttl = c.TTL
 	}
+	ttl = c.clampTTL(key, ttl)
 	if ttl < 0 {
 		return ErrTTLNotAvaliable
 	}
-	return c.Driver.SetCounter(c.getIntKey(key), v, ttl)
+	k := c.getIntKey(key)
+	err := c.exec(func(d Driver) error {
+		return d.SetCounter(k, v, ttl)
+	})
+	return WrapKeyError(LayerDriver, key, err)
 }
 
 //GetCounter Get int val from cache by given key.Count cache and data cache are in two independent namespace.
 //Return int data value and any error raised.
 func (c *Cache) GetCounter(key string) (int64, error) {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
 	if key == "" {
 		return 0, ErrKeyUnavailable
 	}
-	return c.Driver.GetCounter(c.getIntKey(key))
+	k := c.getIntKey(key)
+	v, err := c.call(func(d Driver) (interface{}, error) {
+		return d.GetCounter(k)
+	})
+	result, _ := v.(int64)
+	return result, WrapKeyError(LayerDriver, key, err)
 }
 
 //DelCounter Delete int val in cache by given name.Count cache and data cache are in two independent namespace.
 //Return any error raised.
 func (c *Cache) DelCounter(key string) error {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
 	if key == "" {
 		return ErrKeyUnavailable
 	}
-	err := c.Driver.DelCounter(c.getIntKey(key))
-	if err == ErrNotFound {
+	k := c.getIntKey(key)
+	err := c.exec(func(d Driver) error {
+		return d.DelCounter(k)
+	})
+	if errors.Is(err, ErrNotFound) {
 		return nil
 	}
-	return err
+	return WrapKeyError(LayerDriver, key, err)
 }
 
 //ExpireCounter set cache counter  expire duration by given key and ttl
 func (c *Cache) ExpireCounter(key string, ttl time.Duration) error {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
 	if key == "" {
 		return ErrKeyUnavailable
 	}
 	if ttl < 0 {
 		return ErrTTLNotAvaliable
 	}
-	err := c.Driver.ExpireCounter(c.getIntKey(key), ttl)
-	if err == ErrNotFound {
+	ttl = c.clampTTL(key, ttl)
+	k := c.getIntKey(key)
+	err := c.exec(func(d Driver) error {
+		return d.ExpireCounter(k, ttl)
+	})
+	if errors.Is(err, ErrNotFound) {
 		return nil
 	}
-	return err
+	return WrapKeyError(LayerDriver, key, err)
+}
+
+//IncrFloatCounter Increase float val in cache by given key.Float counters share namespace with int counters.
+//If ttl is DefaultTTL(0),use default ttl in config instead.
+//Driver native implement will be used if driver implements FloatCounter,
+//otherwise value will be updated by SetIfVersion loop if driver implements CASSetter,
+//or by util locker,which is only atomic in current process.
+//Return float data value and any error raised.
+func (c *Cache) IncrFloatCounter(key string, increment float64, ttl time.Duration) (float64, error) {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
+	if key == "" {
+		return 0, ErrKeyUnavailable
+	}
+	if ttl == DefaultTTL {
+		ttl = c.TTL
+	}
+	ttl = c.clampTTL(key, ttl)
+	if ttl < 0 {
+		return 0, ErrTTLNotAvaliable
+	}
+	k := c.getIntKey(key)
+	d, ok := c.Driver.(FloatCounter)
+	if ok && DriverCapabilities(c.Driver).Has(CapabilityFloatCounter) {
+		v, err := d.IncrFloatCounter(k, increment, ttl)
+		return v, WrapKeyError(LayerDriver, key, err)
+	}
+	v, err := incrFloatCounter(c.Driver, k, increment, ttl)
+	return v, WrapKeyError(LayerDriver, key, err)
+}
+
+//GetFloatCounter Get float val from cache by given key.Float counters share namespace with int counters.
+//Return float data value and any error raised.
+func (c *Cache) GetFloatCounter(key string) (float64, error) {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
+	if key == "" {
+		return 0, ErrKeyUnavailable
+	}
+	k := c.getIntKey(key)
+	d, ok := c.Driver.(FloatCounter)
+	if ok && DriverCapabilities(c.Driver).Has(CapabilityFloatCounter) {
+		v, err := d.GetFloatCounter(k)
+		return v, WrapKeyError(LayerDriver, key, err)
+	}
+	v, err := getFloatCounter(c.Driver, k)
+	return v, WrapKeyError(LayerDriver, key, err)
+}
+
+//Ping check if cache backend is reachable before context done.
+//Driver which does not implement Pinger is treated as always reachable.
+//Return any error raised.
+func (c *Cache) Ping(ctx context.Context) error {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
+	d, ok := c.Driver.(Pinger)
+	if !ok || !DriverCapabilities(c.Driver).Has(CapabilityPing) {
+		return ctx.Err()
+	}
+	return d.Ping(ctx)
+}
+
+//Capabilities return capabilities supported by cache driver.
+//Return empty set if driver is not initialized.
+func (c *Cache) Capabilities() Capabilities {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
+	if c.Driver == nil {
+		return 0
+	}
+	return DriverCapabilities(c.Driver)
+}
+
+//OnEvicted register callback called with key and value of every data entry evicted by driver due to size limit or expiration.
+//Values are decoded before passed to callback,entries failed to decode,counters and hashed keys are not reported.
+//Callback is kept after Reload.
+//Return ErrFeatureNotSupported if driver does not implement EvictionNotifier.
+func (c *Cache) OnEvicted(f func(key string, value []byte)) error {
+	c.reloadLocker.Lock()
+	defer c.reloadLocker.Unlock()
+	if _, ok := c.Driver.(EvictionNotifier); !ok {
+		return ErrFeatureNotSupported
+	}
+	c.onEvicted = f
+	c.registerEvicted()
+	return nil
+}
+
+//registerEvicted register eviction callback to driver.
+//Reload locker should be held by caller for writing.
+func (c *Cache) registerEvicted() {
+	d, ok := c.Driver.(EvictionNotifier)
+	if !ok || c.onEvicted == nil {
+		return
+	}
+	f := c.onEvicted
+	compressor := c.Compressor
+	encryptor := c.Encryptor
+	d.OnEvicted(func(key string, value []byte) {
+		if !strings.HasPrefix(key, KeyPrefix) {
+			return
+		}
+		bs, err := decodeValue(value, encryptor, compressor)
+		if err != nil {
+			return
+		}
+		f(key[len(KeyPrefix):], bs)
+	})
+}
+
+//Stats cache statistics.
+type Stats struct {
+	//Entries entry count reported by driver.
+	Entries int64
+	//Bytes approximate bytes used reported by driver.
+	Bytes int64
+	//Hit cache hit count.
+	Hit int64
+	//Miss cache miss count.
+	Miss int64
+}
+
+//Stats return entry count,memory usage and hit statistics of cache.
+//Return stats and any error raised.
+//Return ErrFeatureNotSupported if driver does not support CapabilitySize.
+func (c *Cache) Stats() (*Stats, error) {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
+	d, ok := c.Driver.(Sizer)
+	if !ok || !DriverCapabilities(c.Driver).Has(CapabilitySize) {
+		return nil, ErrFeatureNotSupported
+	}
+	entries, bytes, err := d.Usage()
+	if err != nil {
+		return nil, err
+	}
+	return &Stats{
+		Entries: entries,
+		Bytes:   bytes,
+		Hit:     atomic.LoadInt64(c.hit),
+		Miss:    atomic.LoadInt64(c.miss),
+	}, nil
+}
+
+//Hooks return cache hooks.
+func (c *Cache) Hooks() *Hooks {
+	return c.hooks
+}
+
+//OnSet register hook which will be called after data set to cache.
+//Hooks are called asynchronously and never block cache operations.
+func (c *Cache) OnSet(hook Hook) {
+	c.hooks.Subscribe(OpSet, hook)
+}
+
+//OnGet register hook which will be called after data got from cache.
+//Hooks are called asynchronously and never block cache operations.
+func (c *Cache) OnGet(hook Hook) {
+	c.hooks.Subscribe(OpGet, hook)
+}
+
+//OnDel register hook which will be called after data deleted from cache.
+//Hooks are called asynchronously and never block cache operations.
+func (c *Cache) OnDel(hook Hook) {
+	c.hooks.Subscribe(OpDel, hook)
+}
+
+//OnExpire register hook which will be called after data expire duration changed.
+//Hooks are called asynchronously and never block cache operations.
+func (c *Cache) OnExpire(hook Hook) {
+	c.hooks.Subscribe(OpExpire, hook)
+}
+
+//Close stop hooks dispatching and close cache driver after operations timed out but still running finish.
+//Return any error if raised.
+func (c *Cache) Close() error {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
+	c.hooks.Stop()
+	waitCalls(c.calls)
+	return c.Driver.Close()
 }
 
 //Locker create new locker with given key.
@@ -311,33 +993,69 @@ func (c *Cache) ExpireCounter(key string, ttl time.Duration) error {
 func (c *Cache) Locker(key string) (*Locker, bool) {
 	return c.Util().Locker(key)
 }
+
+//negativeMarkerReader cacheable which reads negative cache marker without counting hits and misses.
+type negativeMarkerReader interface {
+	negativeCached(key string) bool
+}
+
+//negativeCached check if loader "not found" result of given key is cached.
+//Marker is read through driver if cacheable supports,otherwise by GetBytesValue.
+func negativeCached(c Cacheable, key string) bool {
+	if r, ok := c.(negativeMarkerReader); ok {
+		return r.negativeCached(key)
+	}
+	_, err := c.GetBytesValue(key + negativeKeySuffix)
+	return err == nil
+}
+
+func (c *Cache) negativeCached(key string) bool {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
+	_, err := c.getBytesValue(c.getKey(key + negativeKeySuffix))
+	return err == nil
+}
+
 func loadFromCache(c Cacheable, key string, v interface{}, ttl time.Duration, loader Loader) error {
 	var err error
 	if key == "" {
 		return ErrKeyUnavailable
 	}
 	err = c.Get(key, v)
-	if err == ErrNotFound || err == ErrKeyTooLarge {
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrKeyTooLarge) {
 		k := c.FinalKey(key)
 		locker, ok := c.Util().Locker(k)
 		if ok {
-			locker.RLock()
+			err = c.Util().RLock(locker)
+			if err != nil {
+				return err
+			}
 			defer locker.RUnlock()
 			err = c.Get(key, v)
-			if err == nil || (err != ErrNotFound && err != ErrKeyTooLarge) {
+			if err == nil || (!errors.Is(err, ErrNotFound) && !errors.Is(err, ErrKeyTooLarge)) {
 				return err
 			}
 		} else {
-			locker.Lock()
+			err = c.Util().Lock(locker)
+			if err != nil {
+				return err
+			}
 			defer locker.Unlock()
 		}
+		negativettl := c.Util().NegativeTTL
+		if negativettl > 0 && negativeCached(c, key) {
+			return ErrNegativeCached
+		}
 		v2, err2 := loader(key)
 		if err2 != nil {
+			if negativettl > 0 && errors.Is(err2, ErrNotFound) {
+				c.SetBytesValue(key+negativeKeySuffix, []byte{1}, negativettl)
+			}
 			return err2
 		}
 		reflect.Indirect(reflect.ValueOf(v)).Set(reflect.Indirect(reflect.ValueOf(v2)))
 		err3 := c.Set(key, v, ttl)
-		if err3 == ErrNotCacheable || err3 == ErrEntryTooLarge || err3 == ErrKeyTooLarge {
+		if errors.Is(err3, ErrNotCacheable) || errors.Is(err3, ErrEntryTooLarge) || errors.Is(err3, ErrKeyTooLarge) {
 			return nil
 		} else if err3 != nil {
 			return err3
@@ -350,13 +1068,26 @@ func loadFromCache(c Cacheable, key string, v interface{}, ttl time.Duration, lo
 
 //Load Get data model from cache by given key.If data not found,call loader to get current data value and save to cache.
 //If ttl is DefaultTTL(0),use default ttl in config instead.
+//If util NegativeTTL is positive and loader returns ErrNotFound,the miss will be cached
+//and ErrNegativeCached will be returned without calling loader until negative ttl expired.
 //Return any error raised.
 func (c *Cache) Load(key string, v interface{}, ttl time.Duration, loader Loader) error {
 	return loadFromCache(c, key, v, ttl, loader)
 }
 
+//MLoad Get data models from cache by given keys.Data not found will be loaded by one loader call and saved to cache.
+//Parameter v should be map of key and pointer to empty data model which data filled in.
+//Keys not found by loader will be deleted from v.
+//If ttl is DefaultTTL(0),use default ttl in config instead.
+//Return any error raised.
+func (c *Cache) MLoad(keys []string, v map[string]interface{}, ttl time.Duration, loader MLoader) error {
+	return mloadFromCache(c, keys, v, ttl, loader)
+}
+
 //FinalKey get final key which passed to cache driver .
 func (c *Cache) FinalKey(key string) string {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
 	return c.getKey(key)
 }
 
@@ -370,17 +1101,21 @@ func (c *Cache) Field(fieldname string) *Field {
 
 //DefaultTTL return cache default ttl
 func (c *Cache) DefaultTTL() time.Duration {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
 	return c.TTL
 }
 
 //Proxy get a cache proxy with given prefix
 func (c *Cache) Proxy(prefix string) *Proxy {
-	return NewProxy(NewCollection(c, prefix, c.TTL))
+	return NewProxy(NewCollection(c, prefix, c.DefaultTTL()))
 }
 
 //Marshal Marshal data model to  bytes.
 //Return marshaled bytes and any error rasied.
 func (c *Cache) Marshal(v interface{}) ([]byte, error) {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
 	return c.Driver.Util().Marshaler.Marshal(v)
 }
 
@@ -388,9 +1123,17 @@ func (c *Cache) Marshal(v interface{}) ([]byte, error) {
 //Parameter v should be pointer to empty data model which data filled in.
 //Return any error raseid.
 func (c *Cache) Unmarshal(bytes []byte, v interface{}) error {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
 	return c.Driver.Util().Marshaler.Unmarshal(bytes, v)
 }
 
+//MLoader cache value loader used in cache mload method.
+//Load values with given missing keys.
+//Return loaded values map and any error if raised.
+//Keys not found should not be included in map.
+type MLoader func(missing []string) (map[string]interface{}, error)
+
 //Loader cache value loader used in cache load method.
 //Load value with given key.
 //Return loaded value and any error if raised.
@@ -2,11 +2,15 @@ package cache_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"math/rand"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/herb-go/deprecated/cache/drivers/syncmapcache"
 
@@ -80,60 +84,60 @@ func TestCacheEmptyKey(t *testing.T) {
 	c := newTestCache(3600)
 	var data = []byte{}
 	err = c.Set("", data, 0)
-	if err != cache.ErrKeyUnavailable {
+	if !errors.Is(err, cache.ErrKeyUnavailable) {
 		t.Fatal(err)
 	}
 	err = c.Update("", data, 0)
-	if err != cache.ErrKeyUnavailable {
+	if !errors.Is(err, cache.ErrKeyUnavailable) {
 		t.Fatal(err)
 	}
 	err = c.Get("", data)
-	if err != cache.ErrKeyUnavailable {
+	if !errors.Is(err, cache.ErrKeyUnavailable) {
 		t.Fatal(err)
 	}
 	err = c.SetBytesValue("", data, 0)
-	if err != cache.ErrKeyUnavailable {
+	if !errors.Is(err, cache.ErrKeyUnavailable) {
 		t.Fatal(err)
 	}
 	err = c.UpdateBytesValue("", data, 0)
-	if err != cache.ErrKeyUnavailable {
+	if !errors.Is(err, cache.ErrKeyUnavailable) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue("")
-	if err != cache.ErrKeyUnavailable {
+	if !errors.Is(err, cache.ErrKeyUnavailable) {
 		t.Fatal(err)
 	}
 	err = c.Del("")
-	if err != cache.ErrKeyUnavailable {
+	if !errors.Is(err, cache.ErrKeyUnavailable) {
 		t.Fatal(err)
 	}
 	err = c.SetCounter("", 0, 0)
-	if err != cache.ErrKeyUnavailable {
+	if !errors.Is(err, cache.ErrKeyUnavailable) {
 		t.Fatal(err)
 	}
 
 	_, err = c.GetCounter("")
-	if err != cache.ErrKeyUnavailable {
+	if !errors.Is(err, cache.ErrKeyUnavailable) {
 		t.Fatal(err)
 	}
 	_, err = c.IncrCounter("", 0, 0)
-	if err != cache.ErrKeyUnavailable {
+	if !errors.Is(err, cache.ErrKeyUnavailable) {
 		t.Fatal(err)
 	}
 	err = c.DelCounter("")
-	if err != cache.ErrKeyUnavailable {
+	if !errors.Is(err, cache.ErrKeyUnavailable) {
 		t.Fatal(err)
 	}
 	err = c.Expire("", 1000)
-	if err != cache.ErrKeyUnavailable {
+	if !errors.Is(err, cache.ErrKeyUnavailable) {
 		t.Fatal(err)
 	}
 	err = c.ExpireCounter("", 1000)
-	if err != cache.ErrKeyUnavailable {
+	if !errors.Is(err, cache.ErrKeyUnavailable) {
 		t.Fatal(err)
 	}
 	err = c.Load("", nil, 0, testLoader)
-	if err != cache.ErrKeyUnavailable {
+	if !errors.Is(err, cache.ErrKeyUnavailable) {
 		t.Fatal(err)
 	}
 }
@@ -158,6 +162,70 @@ func TestLoader(t *testing.T) {
 	}
 }
 
+func TestNegativeLoader(t *testing.T) {
+	var err error
+	var result string
+	var called int
+	var loader = func(key string) (interface{}, error) {
+		called++
+		return nil, cache.ErrNotFound
+	}
+	c := newTestCache(3600)
+	err = c.Load("test", &result, 0, loader)
+	if !errors.Is(err, cache.ErrNotFound) || called != 1 {
+		t.Fatal(err, called)
+	}
+	err = c.Load("test", &result, 0, loader)
+	if !errors.Is(err, cache.ErrNotFound) || called != 2 {
+		t.Fatal(err, called)
+	}
+	c.Util().NegativeTTL = 3600 * time.Second
+	err = c.Load("test", &result, 0, loader)
+	if !errors.Is(err, cache.ErrNotFound) || called != 3 {
+		t.Fatal(err, called)
+	}
+	err = c.Load("test", &result, 0, loader)
+	if !errors.Is(err, cache.ErrNegativeCached) || !errors.Is(err, cache.ErrNotFound) || called != 3 {
+		t.Fatal(err, called)
+	}
+	miss := c.Miss()
+	_, err = c.GetBytesValue("test")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	getmiss := c.Miss() - miss
+	miss = c.Miss()
+	err = c.Load("test", &result, 0, loader)
+	if !errors.Is(err, cache.ErrNegativeCached) || called != 3 {
+		t.Fatal(err, called)
+	}
+	if c.Miss()-miss > 2*getmiss {
+		t.Fatal(c.Miss()-miss, getmiss)
+	}
+	collection := cache.NewCollection(c, "collection", cache.DefaultTTL)
+	err = collection.Load("test", &result, 0, loader)
+	if !errors.Is(err, cache.ErrNotFound) || called != 4 {
+		t.Fatal(err, called)
+	}
+	err = collection.Load("test", &result, 0, loader)
+	if !errors.Is(err, cache.ErrNegativeCached) || called != 4 {
+		t.Fatal(err, called)
+	}
+	keys, _, err := cache.Keys(c, "", "", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range keys {
+		if strings.HasPrefix(k, "test") {
+			t.Fatal(keys)
+		}
+	}
+	err = c.Load("test2", &result, 0, testLoader)
+	if err != nil || result != "test2" {
+		t.Fatal(err, result)
+	}
+}
+
 func TestLoaderEntiyTooLarge(t *testing.T) {
 	var err error
 	var result string
@@ -196,7 +264,7 @@ func TestNotFound(t *testing.T) {
 		t.Fatal(err)
 	}
 	err = c.Get("notexists", &result)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	err = c.UpdateBytesValue("notexists", result, 0)
@@ -204,7 +272,7 @@ func TestNotFound(t *testing.T) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue("notexists")
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	err = c.Del("notexists")
@@ -251,3 +319,370 @@ func TestMutliConcurrent(t *testing.T) {
 	})
 
 }
+
+type testNoTTLDriver struct {
+	cache.Driver
+}
+
+func TestGetTTL(t *testing.T) {
+	c := newTestCache(3600)
+	_, err := c.GetTTL("")
+	if !errors.Is(err, cache.ErrKeyUnavailable) {
+		t.Fatal(err)
+	}
+	_, err = c.GetTTL("notexists")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	err = c.SetBytesValue("test", []byte("test"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ttl, err := c.GetTTL("test")
+	if err != nil || ttl <= 59*time.Minute || ttl > time.Hour {
+		t.Fatal(ttl, err)
+	}
+	n := cache.NewNode(c, "node")
+	err = n.SetBytesValue("test", []byte("test"), 10*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ttl, err = n.GetTTL("test")
+	if err != nil || ttl <= 9*time.Minute || ttl > 10*time.Minute {
+		t.Fatal(ttl, err)
+	}
+	collection := cache.NewCollection(c, "collection", time.Hour)
+	err = collection.SetBytesValue("test", []byte("test"), 10*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ttl, err = collection.GetTTL("test")
+	if err != nil || ttl <= 9*time.Minute || ttl > 10*time.Minute {
+		t.Fatal(ttl, err)
+	}
+	_, err = cache.GetTTL(cache.Dummy(), "test")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	c.Driver = &testNoTTLDriver{c.Driver}
+	_, err = c.GetTTL("test")
+	if !errors.Is(err, cache.ErrFeatureNotSupported) {
+		t.Fatal(err)
+	}
+}
+
+func TestSetIfNotExists(t *testing.T) {
+	c := newTestCache(3600)
+	_, err := c.SetIfNotExists("", []byte("test"), 0)
+	if !errors.Is(err, cache.ErrKeyUnavailable) {
+		t.Fatal(err)
+	}
+	testSetIfNotExists(t, c)
+	testSetIfNotExists(t, cache.NewNode(c, "node"))
+	testSetIfNotExists(t, cache.NewCollection(c, "collection", 0))
+	emulated := newTestCache(3600)
+	emulated.Driver = &testNoTTLDriver{emulated.Driver}
+	testSetIfNotExists(t, emulated)
+}
+
+func testSetIfNotExists(t *testing.T, c cache.Cacheable) {
+	ok, err := cache.SetIfNotExists(c, "nx", []byte("first"), 0)
+	if !ok || err != nil {
+		t.Fatal(ok, err)
+	}
+	ok, err = cache.SetIfNotExists(c, "nx", []byte("second"), 0)
+	if ok || err != nil {
+		t.Fatal(ok, err)
+	}
+	bs, err := c.GetBytesValue("nx")
+	if err != nil || string(bs) != "first" {
+		t.Fatal(string(bs), err)
+	}
+	err = c.Del("nx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, err = cache.SetIfNotExists(c, "nx", []byte("third"), 0)
+	if !ok || err != nil {
+		t.Fatal(ok, err)
+	}
+}
+
+func TestSetIfVersion(t *testing.T) {
+	c := newTestCache(3600)
+	_, err := c.SetIfVersion("", []byte("test"), "", 0)
+	if !errors.Is(err, cache.ErrKeyUnavailable) {
+		t.Fatal(err)
+	}
+	testSetIfVersion(t, c)
+	testSetIfVersion(t, cache.NewNode(c, "node"))
+	testSetIfVersion(t, cache.NewCollection(c, "collection", 0))
+	emulated := newTestCache(3600)
+	emulated.Driver = &testNoTTLDriver{emulated.Driver}
+	testSetIfVersion(t, emulated)
+}
+
+func testSetIfVersion(t *testing.T, c cache.Cacheable) {
+	_, _, err := cache.GetWithVersion(c, "cas")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	ok, err := cache.SetIfVersion(c, "cas", []byte("first"), "", 0)
+	if !ok || err != nil {
+		t.Fatal(ok, err)
+	}
+	ok, err = cache.SetIfVersion(c, "cas", []byte("second"), "", 0)
+	if ok || err != nil {
+		t.Fatal(ok, err)
+	}
+	bs, version, err := cache.GetWithVersion(c, "cas")
+	if err != nil || string(bs) != "first" || version != cache.ValueVersion(bs) {
+		t.Fatal(string(bs), version, err)
+	}
+	ok, err = cache.SetIfVersion(c, "cas", []byte("second"), version, 0)
+	if !ok || err != nil {
+		t.Fatal(ok, err)
+	}
+	ok, err = cache.SetIfVersion(c, "cas", []byte("third"), version, 0)
+	if ok || err != nil {
+		t.Fatal(ok, err)
+	}
+	bs, _, err = cache.GetWithVersion(c, "cas")
+	if err != nil || string(bs) != "second" {
+		t.Fatal(string(bs), err)
+	}
+}
+
+func TestMaxEntrySize(t *testing.T) {
+	c := newTestCache(3600)
+	c.MaxEntrySize = 10
+	err := c.SetBytesValue("small", []byte("small"), cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.SetBytesValue("large", []byte("largelargelarge"), cache.DefaultTTL)
+	if !errors.Is(err, cache.ErrEntryTooLarge) {
+		t.Fatal(err)
+	}
+	err = c.Set("large", "largelargelarge", cache.DefaultTTL)
+	if !errors.Is(err, cache.ErrEntryTooLarge) {
+		t.Fatal(err)
+	}
+	err = c.MSetBytesValue(map[string][]byte{"small2": []byte("small"), "large": []byte("largelargelarge")}, cache.DefaultTTL)
+	if !errors.Is(err, cache.ErrEntryTooLarge) {
+		t.Fatal(err)
+	}
+	_, err = c.GetBytesValue("small2")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	_, err = c.GetBytesValue("large")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+}
+
+func TestKeyHashThreshold(t *testing.T) {
+	c := newTestCache(3600)
+	c.KeyHashThreshold = 100
+	short := "short"
+	long := strings.Repeat("long", 50)
+	if c.FinalKey(short) != cache.Key(short) {
+		t.Fatal(c.FinalKey(short))
+	}
+	if len(c.FinalKey(long)) != 66 || c.FinalKey(long) == c.FinalKey(long+"2") {
+		t.Fatal(c.FinalKey(long))
+	}
+	err := c.SetBytesValue(long, []byte("longvalue"), cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.SetBytesValue(short, []byte("shortvalue"), cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := c.GetBytesValue(long)
+	if err != nil || string(bs) != "longvalue" {
+		t.Fatal(string(bs), err)
+	}
+	data, err := c.MGetBytesValue(short, long, long+"2")
+	if err != nil || len(data) != 2 || string(data[long]) != "longvalue" || string(data[short]) != "shortvalue" {
+		t.Fatal(data, err)
+	}
+	n := cache.NewNode(c, long)
+	err = n.SetBytesValue("key", []byte("nodevalue"), cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(n.FinalKey("key")) != 66 {
+		t.Fatal(n.FinalKey("key"))
+	}
+	bs, err = n.GetBytesValue("key")
+	if err != nil || string(bs) != "nodevalue" {
+		t.Fatal(string(bs), err)
+	}
+	_, err = c.IncrCounter(long, 2, cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := c.GetCounter(long)
+	if err != nil || v != 2 {
+		t.Fatal(v, err)
+	}
+}
+
+func TestClampTTL(t *testing.T) {
+	c := newTestCache(3600)
+	c.MinTTL = time.Minute
+	c.MaxTTL = 2 * time.Hour
+	var clamped []time.Duration
+	c.OnTTLClamped = func(key string, ttl time.Duration, to time.Duration) {
+		clamped = append(clamped, to)
+	}
+	err := c.SetBytesValue("short", []byte("short"), time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ttl, err := c.GetTTL("short")
+	if err != nil || ttl <= time.Second || ttl > time.Minute {
+		t.Fatal(ttl, err)
+	}
+	err = c.Set("long", "long", 10*365*24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ttl, err = c.GetTTL("long")
+	if err != nil || ttl > 2*time.Hour || ttl < time.Hour {
+		t.Fatal(ttl, err)
+	}
+	err = c.SetBytesValue("normal", []byte("normal"), cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clamped) != 2 || clamped[0] != time.Minute || clamped[1] != 2*time.Hour {
+		t.Fatal(clamped)
+	}
+	err = c.SetBytesValue("negative", []byte("negative"), -time.Second)
+	if !errors.Is(err, cache.ErrTTLNotAvaliable) {
+		t.Fatal(err)
+	}
+}
+
+func TestMLoad(t *testing.T) {
+	c := newTestCache(3600)
+	n := cache.NewNode(c, "mload")
+	var loaded [][]string
+	loader := func(missing []string) (map[string]interface{}, error) {
+		loaded = append(loaded, missing)
+		result := map[string]interface{}{}
+		for _, k := range missing {
+			if k != "notexist" {
+				v := "value-" + k
+				result[k] = &v
+			}
+		}
+		return result, nil
+	}
+	for _, c := range []cache.Cacheable{c, n} {
+		loaded = nil
+		err := c.Set("cached", "cachedvalue", cache.DefaultTTL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var cached, key1, key2, notexist string
+		v := map[string]interface{}{"cached": &cached, "key1": &key1, "key2": &key2, "notexist": &notexist}
+		err = cache.MLoad(c, []string{"cached", "key1", "key2", "key1", "notexist"}, v, cache.DefaultTTL, loader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cached != "cachedvalue" || key1 != "value-key1" || key2 != "value-key2" || len(v) != 3 {
+			t.Fatal(cached, key1, key2, v)
+		}
+		if len(loaded) != 1 || len(loaded[0]) != 3 {
+			t.Fatal(loaded)
+		}
+		key1 = ""
+		v = map[string]interface{}{"key1": &key1}
+		err = cache.MLoad(c, []string{"key1"}, v, cache.DefaultTTL, loader)
+		if err != nil || key1 != "value-key1" || len(loaded) != 1 {
+			t.Fatal(key1, loaded, err)
+		}
+	}
+}
+
+func TestPing(t *testing.T) {
+	c := newTestCache(3600)
+	err := c.Ping(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = c.Ping(ctx)
+	if err != context.Canceled {
+		t.Fatal(err)
+	}
+}
+
+func TestReload(t *testing.T) {
+	c := newTestCache(3600)
+	err := c.SetBytesValue("test", []byte("test"), cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oc := cache.NewOptionConfig()
+	oc.Driver = "notexist"
+	err = c.Reload(oc)
+	if err == nil {
+		t.Fatal(err)
+	}
+	if c.DefaultTTL() != 3600*time.Second {
+		t.Fatal(c.DefaultTTL())
+	}
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				err := c.SetBytesValue("concurrent", []byte("test"), cache.DefaultTTL)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				_, err = c.GetBytesValue("concurrent")
+				if err != nil && !errors.Is(err, cache.ErrNotFound) {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	oc = cache.NewOptionConfig()
+	oc.Driver = "syncmapcache"
+	oc.TTL = 1800
+	oc.Config = func(v interface{}) error {
+		v.(*syncmapcache.Config).Size = 10000000
+		return nil
+	}
+	oc.Marshaler = "json"
+	err = c.Reload(oc)
+	close(stop)
+	wg.Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.DefaultTTL() != 1800*time.Second {
+		t.Fatal(c.DefaultTTL())
+	}
+	_, err = c.GetBytesValue("test")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+}
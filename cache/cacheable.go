@@ -78,3 +78,139 @@ type Cacheable interface {
 	// // Locker return locker by given key
 	// Locker(key string) (*Locker, bool)
 }
+
+//VersionGetter optional cacheable interface which can get value with its version.
+type VersionGetter interface {
+	//GetWithVersion Get bytes data and value version from cache by given key.
+	//Return data bytes,version and any error raised.
+	GetWithVersion(key string) ([]byte, string, error)
+}
+
+//Explainer optional cacheable interface which can explain how key would be served.
+type Explainer interface {
+	//Explain explain how Get of given key would be served without changing cache.
+	//Return explanation and any error raised.
+	Explain(key string) (*Explanation, error)
+}
+
+//BatchLoader optional cacheable interface which can load multiple keys in one call.
+type BatchLoader interface {
+	//MLoad Get data models from cache by given keys.Data not found will be loaded by one loader call and saved to cache.
+	//Parameter v should be map of key and pointer to empty data model which data filled in.
+	//Keys not found by loader will be deleted from v.
+	//If ttl is DefaultTTL(0),use default ttl in config instead.
+	//Return any error raised.
+	MLoad(keys []string, v map[string]interface{}, ttl time.Duration, loader MLoader) error
+}
+
+//GetTTL get remaining ttl of given key from given cacheable.
+//Return ttl and any error raised.
+//Return ErrFeatureNotSupported if cacheable does not implement TTLGetter.
+func GetTTL(c Cacheable, key string) (time.Duration, error) {
+	g, ok := c.(TTLGetter)
+	if !ok {
+		return 0, ErrFeatureNotSupported
+	}
+	return g.GetTTL(key)
+}
+
+//SetIfNotExists Set bytes data to given cacheable by given key only if the key does not exist.
+//Return whether data is set and any error raised.
+//Return ErrFeatureNotSupported if cacheable does not implement NXSetter.
+func SetIfNotExists(c Cacheable, key string, bytes []byte, ttl time.Duration) (bool, error) {
+	s, ok := c.(NXSetter)
+	if !ok {
+		return false, ErrFeatureNotSupported
+	}
+	return s.SetIfNotExists(key, bytes, ttl)
+}
+
+//GetWithVersion Get bytes data and value version from given cacheable by given key.
+//Version is computed by ValueVersion if cacheable does not implement VersionGetter.
+//Return data bytes,version and any error raised.
+func GetWithVersion(c Cacheable, key string) ([]byte, string, error) {
+	if g, ok := c.(VersionGetter); ok {
+		return g.GetWithVersion(key)
+	}
+	bs, err := c.GetBytesValue(key)
+	if err != nil {
+		return nil, "", err
+	}
+	return bs, ValueVersion(bs), nil
+}
+
+//SetIfVersion Set bytes data to given cacheable by given key only if current value version equals given version.
+//Return whether data is set and any error raised.
+//Return ErrFeatureNotSupported if cacheable does not implement CASSetter.
+func SetIfVersion(c Cacheable, key string, bytes []byte, version string, ttl time.Duration) (bool, error) {
+	s, ok := c.(CASSetter)
+	if !ok {
+		return false, ErrFeatureNotSupported
+	}
+	return s.SetIfVersion(key, bytes, version, ttl)
+}
+
+//IncrFloatCounter Increase float val in given cacheable by given key.
+//Return float data value and any error raised.
+//Return ErrFeatureNotSupported if cacheable does not implement FloatCounter.
+func IncrFloatCounter(c Cacheable, key string, increment float64, ttl time.Duration) (float64, error) {
+	f, ok := c.(FloatCounter)
+	if !ok {
+		return 0, ErrFeatureNotSupported
+	}
+	return f.IncrFloatCounter(key, increment, ttl)
+}
+
+//GetFloatCounter Get float val from given cacheable by given key.
+//Return float data value and any error raised.
+//Return ErrFeatureNotSupported if cacheable does not implement FloatCounter.
+func GetFloatCounter(c Cacheable, key string) (float64, error) {
+	f, ok := c.(FloatCounter)
+	if !ok {
+		return 0, ErrFeatureNotSupported
+	}
+	return f.GetFloatCounter(key)
+}
+
+//Keys list keys with given prefix from given cursor in given cacheable.
+//Return keys,next cursor and any error raised.
+//Return ErrFeatureNotSupported if cacheable does not implement Iterable.
+func Keys(c Cacheable, prefix string, cursor string, count int) ([]string, string, error) {
+	i, ok := c.(Iterable)
+	if !ok {
+		return nil, "", ErrFeatureNotSupported
+	}
+	return i.Keys(prefix, cursor, count)
+}
+
+//Rename move value of old key to new key in given cacheable.
+//Return any error raised.
+//Return ErrFeatureNotSupported if cacheable does not implement Renamer.
+func Rename(c Cacheable, oldKey string, newKey string) error {
+	r, ok := c.(Renamer)
+	if !ok {
+		return ErrFeatureNotSupported
+	}
+	return r.Rename(oldKey, newKey)
+}
+
+//Explain explain how Get of given key would be served by given cacheable.
+//Return explanation and any error raised.
+//Return ErrFeatureNotSupported if cacheable does not implement Explainer.
+func Explain(c Cacheable, key string) (*Explanation, error) {
+	e, ok := c.(Explainer)
+	if !ok {
+		return nil, ErrFeatureNotSupported
+	}
+	return e.Explain(key)
+}
+
+//MLoad Get data models from given cacheable by given keys.Data not found will be loaded by one loader call and saved to cache.
+//Data are read by MGetBytesValue of cacheable if it does not implement BatchLoader.
+//Return any error raised.
+func MLoad(c Cacheable, keys []string, v map[string]interface{}, ttl time.Duration, loader MLoader) error {
+	if l, ok := c.(BatchLoader); ok {
+		return l.MLoad(keys, v, ttl, loader)
+	}
+	return mloadFromCache(c, keys, v, ttl, loader)
+}
@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"fmt"
+	"strings"
+)
+
+//Capabilities set of features supported by cache driver.
+type Capabilities uint64
+
+const (
+	//CapabilityMGet driver gets and sets multiple values in one operation instead of one call per key.
+	CapabilityMGet Capabilities = 1 << iota
+	//CapabilityCounter driver supports int counters.
+	CapabilityCounter
+	//CapabilityFloatCounter driver increases float counters natively.
+	CapabilityFloatCounter
+	//CapabilityFlush driver supports Flush.
+	CapabilityFlush
+	//CapabilityTTL driver can inspect remaining ttl of entries.
+	CapabilityTTL
+	//CapabilityNX driver sets entry only if key not exists atomically.
+	CapabilityNX
+	//CapabilityCAS driver compares and swaps entry atomically.
+	CapabilityCAS
+	//CapabilityIteration driver can enumerate keys.
+	CapabilityIteration
+	//CapabilityPing driver can check if cache backend is reachable.
+	CapabilityPing
+	//CapabilitySize driver can report entry count and memory usage.
+	CapabilitySize
+	//CapabilityRename driver renames keys atomically.
+	CapabilityRename
+)
+
+var capabilityNames = []struct {
+	capability Capabilities
+	name       string
+}{
+	{CapabilityMGet, "mget"},
+	{CapabilityCounter, "counter"},
+	{CapabilityFloatCounter, "floatcounter"},
+	{CapabilityFlush, "flush"},
+	{CapabilityTTL, "ttl"},
+	{CapabilityNX, "nx"},
+	{CapabilityCAS, "cas"},
+	{CapabilityIteration, "iteration"},
+	{CapabilityPing, "ping"},
+	{CapabilitySize, "size"},
+	{CapabilityRename, "rename"},
+}
+
+//Has return whether all given capabilities are in set.
+func (c Capabilities) Has(capabilities Capabilities) bool {
+	return c&capabilities == capabilities
+}
+
+//Missing return capabilities in given set but not in c.
+func (c Capabilities) Missing(capabilities Capabilities) Capabilities {
+	return capabilities &^ c
+}
+
+//String return comma separated capability names.
+func (c Capabilities) String() string {
+	names := []string{}
+	for _, v := range capabilityNames {
+		if c.Has(v.capability) {
+			names = append(names, v.name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+//CapabilityReporter optional driver interface which reports supported capabilities.
+//Wrapper drivers should implement this interface,
+//as they usually implement optional interfaces whether wrapped caches support them or not.
+type CapabilityReporter interface {
+	//Capabilities return capabilities supported by driver.
+	Capabilities() Capabilities
+}
+
+//DriverCapabilities return capabilities supported by given driver.
+//Capabilities reported by driver will be used if driver implements CapabilityReporter,
+//otherwise capabilities are detected by optional driver interfaces,
+//and counters and flush are treated as supported.
+func DriverCapabilities(d Driver) Capabilities {
+	return detectCapabilities(d)
+}
+
+//CacheableCapabilities return capabilities supported by given cacheable.
+//Capabilities reported by cacheable will be used if cacheable implements CapabilityReporter,
+//otherwise capabilities are detected by optional interfaces as DriverCapabilities does.
+func CacheableCapabilities(c Cacheable) Capabilities {
+	return detectCapabilities(c)
+}
+
+func detectCapabilities(d interface{}) Capabilities {
+	if r, ok := d.(CapabilityReporter); ok {
+		return r.Capabilities()
+	}
+	c := CapabilityCounter | CapabilityFlush
+	if _, ok := d.(FloatCounter); ok {
+		c = c | CapabilityFloatCounter
+	}
+	if _, ok := d.(TTLGetter); ok {
+		c = c | CapabilityTTL
+	}
+	if _, ok := d.(NXSetter); ok {
+		c = c | CapabilityNX
+	}
+	if _, ok := d.(CASSetter); ok {
+		c = c | CapabilityCAS
+	}
+	if _, ok := d.(Iterable); ok {
+		c = c | CapabilityIteration
+	}
+	if _, ok := d.(Pinger); ok {
+		c = c | CapabilityPing
+	}
+	if _, ok := d.(Sizer); ok {
+		c = c | CapabilitySize
+	}
+	if _, ok := d.(Renamer); ok {
+		c = c | CapabilityRename
+	}
+	return c
+}
+
+//RequireCapabilities check if given cache supports all given capabilities.
+//Return error wrapping ErrFeatureNotSupported with missing capability names if any capability is missing.
+func RequireCapabilities(c Cacheable, capabilities Capabilities) error {
+	missing := CacheableCapabilities(c).Missing(capabilities)
+	if missing != 0 {
+		return fmt.Errorf("%w: %s", ErrFeatureNotSupported, missing)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package cache_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/herb-go/deprecated/cache"
+	"github.com/herb-go/deprecated/cache/drivers/syncmapcache"
+)
+
+type limitedDriver struct {
+	*syncmapcache.Cache
+	capabilities cache.Capabilities
+}
+
+func (d *limitedDriver) Capabilities() cache.Capabilities {
+	return d.capabilities
+}
+
+func TestCapabilities(t *testing.T) {
+	c := newTestCache(3600)
+	if !c.Capabilities().Has(cache.CapabilityMGet|cache.CapabilityCAS|cache.CapabilityIteration) || c.Capabilities().Has(cache.CapabilityPing) {
+		t.Fatal(c.Capabilities())
+	}
+	caps := cache.DriverCapabilities(&cache.DummyCache{})
+	if !caps.Has(cache.CapabilityCounter|cache.CapabilityFlush|cache.CapabilityTTL) || caps.Has(cache.CapabilityPing|cache.CapabilityMGet) {
+		t.Fatal(caps)
+	}
+	if s := (cache.CapabilityCounter | cache.CapabilityTTL).String(); s != "counter,ttl" {
+		t.Fatal(s)
+	}
+	err := cache.RequireCapabilities(c, cache.CapabilityMGet|cache.CapabilityPing)
+	if !errors.Is(err, cache.ErrFeatureNotSupported) || err.Error() != "Feature is not supported: ping" {
+		t.Fatal(err)
+	}
+	if err := cache.RequireCapabilities(c, cache.CapabilityMGet|cache.CapabilityTTL); err != nil {
+		t.Fatal(err)
+	}
+	c.Driver = &limitedDriver{Cache: c.Driver.(*syncmapcache.Cache), capabilities: cache.CapabilityFlush}
+	_, _, err = c.Keys("", "", 10)
+	if !errors.Is(err, cache.ErrFeatureNotSupported) {
+		t.Fatal(err)
+	}
+	_, err = c.Stats()
+	if !errors.Is(err, cache.ErrFeatureNotSupported) {
+		t.Fatal(err)
+	}
+	_, err = c.GetTTL("test")
+	if !errors.Is(err, cache.ErrFeatureNotSupported) {
+		t.Fatal(err)
+	}
+	ok, err := c.SetIfNotExists("test", []byte("test"), 0)
+	if !ok || err != nil {
+		t.Fatal(ok, err)
+	}
+	ok, err = c.SetIfNotExists("test", []byte("test"), 0)
+	if ok || err != nil {
+		t.Fatal(ok, err)
+	}
+	n := cache.NewNode(c, "node")
+	if n.Capabilities().Has(cache.CapabilityFlush) {
+		t.Fatal(n.Capabilities())
+	}
+	g, err := n.Generation()
+	if g != 0 || err != nil {
+		t.Fatal(g, err)
+	}
+	collection := cache.NewCollection(c, "collection", 3600)
+	if !collection.Capabilities().Has(cache.CapabilityFlush) {
+		t.Fatal(collection.Capabilities())
+	}
+}
+
+type optionalCacheable interface {
+	cache.Cacheable
+	cache.TTLGetter
+	cache.NXSetter
+	cache.VersionGetter
+	cache.CASSetter
+	cache.FloatCounter
+	cache.Iterable
+	cache.Renamer
+	cache.Explainer
+	cache.BatchLoader
+	cache.CapabilityReporter
+}
+
+var (
+	_ optionalCacheable = &cache.Cache{}
+	_ optionalCacheable = &cache.Node{}
+	_ optionalCacheable = &cache.Collection{}
+	_ optionalCacheable = &cache.Proxy{}
+)
+
+type minimalCacheable struct {
+	cache.Cacheable
+}
+
+func TestOptionalInterfaces(t *testing.T) {
+	c := newTestCache(3600)
+	m := minimalCacheable{Cacheable: c}
+	if _, ok := cache.Cacheable(m).(cache.Iterable); ok {
+		t.Fatal(m)
+	}
+	_, _, err := cache.Keys(m, "", "", 10)
+	if !errors.Is(err, cache.ErrFeatureNotSupported) {
+		t.Fatal(err)
+	}
+	if !errors.Is(cache.Rename(m, "old", "new"), cache.ErrFeatureNotSupported) {
+		t.Fatal(err)
+	}
+	_, err = cache.SetIfNotExists(m, "test", []byte("test"), cache.DefaultTTL)
+	if !errors.Is(err, cache.ErrFeatureNotSupported) {
+		t.Fatal(err)
+	}
+	if cache.CacheableCapabilities(m) != cache.CapabilityCounter|cache.CapabilityFlush {
+		t.Fatal(cache.CacheableCapabilities(m))
+	}
+	err = m.SetBytesValue("test", []byte("test"), cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, version, err := cache.GetWithVersion(m, "test")
+	if err != nil || string(bs) != "test" || version != cache.ValueVersion(bs) {
+		t.Fatal(string(bs), version, err)
+	}
+	var v1, v2 string
+	err = c.Set("key1", "value1", cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := map[string]interface{}{"key1": &v1, "key2": &v2}
+	err = cache.MLoad(m, []string{"key1", "key2"}, v, cache.DefaultTTL, func(keys []string) (map[string]interface{}, error) {
+		return map[string]interface{}{"key2": "value2"}, nil
+	})
+	if err != nil || v1 != "value1" || v2 != "value2" {
+		t.Fatal(v1, v2, err)
+	}
+	p := cache.NewProxy(m)
+	_, _, err = p.Keys("", "", 10)
+	if !errors.Is(err, cache.ErrFeatureNotSupported) {
+		t.Fatal(err)
+	}
+	p = cache.NewProxy(c)
+	if !p.Capabilities().Has(cache.CapabilityIteration) {
+		t.Fatal(p.Capabilities())
+	}
+}
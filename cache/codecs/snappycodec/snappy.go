@@ -0,0 +1,27 @@
+//Package snappycodec provides cache compression codec uses snappy.
+package snappycodec
+
+import (
+	"github.com/golang/snappy"
+	"github.com/herb-go/deprecated/cache"
+)
+
+//SnappyCodec compression codec uses snappy.
+type SnappyCodec struct {
+}
+
+//Compress compress given data.
+//Return compressed data and any error if raised.
+func (c *SnappyCodec) Compress(data []byte) ([]byte, error) {
+	return snappy.Encode(nil, data), nil
+}
+
+//Decompress decompress given data.
+//Return decompressed data and any error if raised.
+func (c *SnappyCodec) Decompress(data []byte) ([]byte, error) {
+	return snappy.Decode(nil, data)
+}
+
+func init() {
+	cache.RegisterCodec("snappy", cache.CodecHeaderSnappy, &SnappyCodec{})
+}
@@ -0,0 +1,30 @@
+package snappycodec
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+func TestSnappy(t *testing.T) {
+	c, err := cache.NewCompressor("snappy", 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := bytes.Repeat([]byte("testdata"), 100)
+	bs, err := c.Encode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bs[0] != cache.CodecHeaderSnappy || len(bs) >= len(data) {
+		t.Fatal(bs)
+	}
+	result, err := c.Decode(bs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(result, data) {
+		t.Fatal(result)
+	}
+}
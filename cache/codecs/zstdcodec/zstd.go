@@ -0,0 +1,50 @@
+//Package zstdcodec provides cache compression codec uses zstd.
+package zstdcodec
+
+import (
+	"github.com/herb-go/deprecated/cache"
+	"github.com/klauspost/compress/zstd"
+)
+
+//ZstdCodec compression codec uses zstd.
+type ZstdCodec struct {
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+}
+
+//New create new zstd codec.
+//Return codec and any error if raised.
+func New() (*ZstdCodec, error) {
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		return nil, err
+	}
+	decoder, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ZstdCodec{
+		encoder: encoder,
+		decoder: decoder,
+	}, nil
+}
+
+//Compress compress given data.
+//Return compressed data and any error if raised.
+func (c *ZstdCodec) Compress(data []byte) ([]byte, error) {
+	return c.encoder.EncodeAll(data, nil), nil
+}
+
+//Decompress decompress given data.
+//Return decompressed data and any error if raised.
+func (c *ZstdCodec) Decompress(data []byte) ([]byte, error) {
+	return c.decoder.DecodeAll(data, nil)
+}
+
+func init() {
+	c, err := New()
+	if err != nil {
+		panic(err)
+	}
+	cache.RegisterCodec("zstd", cache.CodecHeaderZstd, c)
+}
@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"errors"
 	"strconv"
 	"time"
 )
@@ -44,12 +45,12 @@ func NewNestedCollection(cache Cacheable, path ...string) Cacheable {
 func (c *Collection) GetCacheKey(key string) (string, error) {
 	var ts string
 	err := c.Cache.Get(c.Prefix, &ts)
-	if err == ErrNotFound {
+	if errors.Is(err, ErrNotFound) {
 		ts = strconv.FormatInt(time.Now().UnixNano(), 32)
 		ttl := c.TTL
 		ttl = ttl * time.Duration(CollectionTTLMultiple)
 		err = c.Cache.Set(c.Prefix, ts, ttl)
-		if err == ErrNotCacheable {
+		if errors.Is(err, ErrNotCacheable) {
 			err = nil
 		}
 	}
@@ -207,6 +208,30 @@ func (c *Collection) IncrCounter(key string, increment int64, TTL time.Duration)
 
 }
 
+//IncrFloatCounter Increase float val in cache by given key.Float counters share namespace with int counters.
+//If ttl is DefaultTTL(0),use default ttl in config instead.
+//Return float data value and any error raised.
+func (c *Collection) IncrFloatCounter(key string, increment float64, TTL time.Duration) (float64, error) {
+	if TTL < 0 {
+		return 0, ErrTTLNotAvaliable
+	}
+	k, err := c.GetCacheKey(key)
+	if err != nil {
+		return 0, err
+	}
+	return IncrFloatCounter(c.Cache, k, increment, TTL)
+}
+
+//GetFloatCounter Get float val from cache by given key.Float counters share namespace with int counters.
+//Return float data value and any error raised.
+func (c *Collection) GetFloatCounter(key string) (float64, error) {
+	k, err := c.GetCacheKey(key)
+	if err != nil {
+		return 0, err
+	}
+	return GetFloatCounter(c.Cache, k)
+}
+
 //SetCounter Set int val in cache by given key.Count cache and data cache are in two independent namespace.
 //If ttl is DefaultTTL(0),use default ttl in config instead.
 //Return any error raised.
@@ -262,6 +287,12 @@ func (c *Collection) Flush() error {
 	return c.Cache.Del(c.Prefix)
 }
 
+//Capabilities return capabilities supported by underlying cache.
+//Collection always supports flush.
+func (c *Collection) Capabilities() Capabilities {
+	return CacheableCapabilities(c.Cache) | CapabilityFlush
+}
+
 //DefaultTTL return cache default ttl
 func (c *Collection) DefaultTTL() time.Duration {
 	return c.Cache.DefaultTTL()
@@ -279,6 +310,102 @@ func (c *Collection) Expire(key string, TTL time.Duration) error {
 	return c.Cache.Expire(k, TTL)
 }
 
+//GetTTL get remaining ttl of given key.
+//Return ttl and any error raised.
+func (c *Collection) GetTTL(key string) (time.Duration, error) {
+	k, err := c.GetCacheKey(key)
+	if err != nil {
+		return 0, err
+	}
+	return GetTTL(c.Cache, k)
+}
+
+//SetIfNotExists Set bytes data to cache by given key only if the key does not exist.
+//If ttl is DefaultTTL(0),use default ttl in config instead.
+//Return whether data is set and any error raised.
+func (c *Collection) SetIfNotExists(key string, bytes []byte, TTL time.Duration) (bool, error) {
+	if TTL < 0 {
+		return false, ErrTTLNotAvaliable
+	}
+	k, err := c.GetCacheKey(key)
+	if err != nil {
+		return false, err
+	}
+	return SetIfNotExists(c.Cache, k, bytes, TTL)
+}
+
+//GetWithVersion Get bytes data and value version from cache by given key.
+//Return data bytes,version and any error raised.
+func (c *Collection) GetWithVersion(key string) ([]byte, string, error) {
+	k, err := c.GetCacheKey(key)
+	if err != nil {
+		return nil, "", err
+	}
+	return GetWithVersion(c.Cache, k)
+}
+
+//SetIfVersion Set bytes data to cache by given key only if current value version equals given version.
+//Empty version means key should not exist.
+//If ttl is DefaultTTL(0),use default ttl in config instead.
+//Return whether data is set and any error raised.
+func (c *Collection) SetIfVersion(key string, bytes []byte, version string, TTL time.Duration) (bool, error) {
+	if TTL < 0 {
+		return false, ErrTTLNotAvaliable
+	}
+	k, err := c.GetCacheKey(key)
+	if err != nil {
+		return false, err
+	}
+	return SetIfVersion(c.Cache, k, bytes, version, TTL)
+}
+
+//Keys list keys in collection with given prefix from given cursor.
+//Empty cursor means start from beginning.
+//Keys of flushed collection are not included.
+//Return keys,next cursor and any error raised.
+//Empty next cursor means iteration finished.
+func (c *Collection) Keys(prefix string, cursor string, count int) ([]string, string, error) {
+	base, err := c.GetCacheKey("")
+	if err != nil {
+		return nil, "", err
+	}
+	keys, next, err := Keys(c.Cache, base+prefix, cursor, count)
+	if err != nil {
+		return nil, "", err
+	}
+	return trimKeys(keys, base), next, nil
+}
+
+//Rename move value of old key to new key in collection.
+//Return ErrNotFound if old key not exists.
+//Return any error raised.
+func (c *Collection) Rename(oldKey string, newKey string) error {
+	oldk, err := c.GetCacheKey(oldKey)
+	if err != nil {
+		return err
+	}
+	newk, err := c.GetCacheKey(newKey)
+	if err != nil {
+		return err
+	}
+	return Rename(c.Cache, oldk, newk)
+}
+
+//Explain explain how Get of given key in collection would be served.
+//Return explanation and any error raised.
+func (c *Collection) Explain(key string) (*Explanation, error) {
+	k, err := c.GetCacheKey(key)
+	if err != nil {
+		return nil, err
+	}
+	e, err := Explain(c.Cache, k)
+	if err != nil {
+		return nil, err
+	}
+	e.Key = key
+	return e, nil
+}
+
 //ExpireCounter set cache counter  expire duration by given key and ttl
 func (c *Collection) ExpireCounter(key string, TTL time.Duration) error {
 	if TTL < 0 {
@@ -316,7 +443,24 @@ func (c *Collection) Field(fieldname string) *Field {
 	}
 }
 
+//MLoad Get data models from cache by given keys.Data not found will be loaded by one loader call and saved to cache.
+//Parameter v should be map of key and pointer to empty data model which data filled in.
+//Keys not found by loader will be deleted from v.
+//If ttl is DefaultTTL(0),use default ttl in config instead.
+//Return any error raised.
+func (c *Collection) MLoad(keys []string, v map[string]interface{}, ttl time.Duration, loader MLoader) error {
+	return mloadFromCache(c, keys, v, ttl, loader)
+}
+
 //FinalKey get final key which passed to cache driver .
 func (c *Collection) FinalKey(key string) string {
 	return c.Cache.FinalKey(c.Prefix + KeyPrefix + key)
 }
+
+func (c *Collection) negativeCached(key string) bool {
+	k, err := c.GetCacheKey(key)
+	if err != nil {
+		return false
+	}
+	return negativeCached(c.Cache, k)
+}
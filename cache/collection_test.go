@@ -3,6 +3,7 @@ package cache_test
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"testing"
 	"time"
 
@@ -76,7 +77,7 @@ func TestCollectionMSetMGet(t *testing.T) {
 		}
 	}
 	_, err = c.GetBytesValue(unusedKey)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 
@@ -100,7 +101,7 @@ func TestCollectionMSetMGet(t *testing.T) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(unusedKey)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	d, err = c.MGetBytesValue(mixedKeys...)
@@ -111,7 +112,7 @@ func TestCollectionMSetMGet(t *testing.T) {
 		t.Errorf("%s", d[unusedKey])
 	}
 	err = c.SetBytesValue(unusedKey, []byte{}, -1)
-	if err != cache.ErrTTLNotAvaliable {
+	if !errors.Is(err, cache.ErrTTLNotAvaliable) {
 		t.Fatal(err)
 	}
 }
@@ -203,7 +204,7 @@ func TestCollectionCloseAndFlush(t *testing.T) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKey, &resultDataModel)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	ttl := c.DefaultTTL()
@@ -247,7 +248,7 @@ func TestCollectionUpdate(t *testing.T) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKeyUpdate, &resultDataModel)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 
@@ -276,7 +277,7 @@ func TestCollectionUpdate(t *testing.T) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(testKeyBytesUpdate)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 }
@@ -362,15 +363,15 @@ func TestCollectionDefaulTTL(t *testing.T) {
 	}
 	time.Sleep(2000 * time.Millisecond)
 	err = c.Get(testKey, &resultDataModel)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(testKey2)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	resultDataInt, err = c.GetCounter(testKey3)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 }
@@ -498,15 +499,15 @@ func TestCollectionTTL(t *testing.T) {
 	time.Sleep(2000 * time.Millisecond)
 
 	err = c.Get(testKeyTTL1Second, &resultModelData)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(testKeyTTL1SecondBytes)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetCounter(testKeyTTL1SecondCounter)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKeyTTL3Second, &resultModelData)
@@ -561,27 +562,27 @@ func TestCollectionTTL(t *testing.T) {
 
 	time.Sleep(2000 * time.Millisecond)
 	err = c.Get(testKeyTTL1Second, &resultModelData)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(testKeyTTL1SecondBytes)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetCounter(testKeyTTL1SecondCounter)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKeyTTL3Second, &resultModelData)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(testKeyTTL3SecondBytes)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetCounter(testKeyTTL3SecondCounter)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKeyTTLRefresh, &resultModelData)
@@ -614,7 +615,7 @@ func TestCollectionTTL(t *testing.T) {
 		t.Fatal(err)
 	}
 	_, err = c.GetCounter(testKeyTTLExpireCounter)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 }
@@ -675,39 +676,67 @@ func TestCollectionForever(t *testing.T) {
 	var result = []byte{}
 	c := newCollectionTestCache(3600)
 	err = c.Set("test", result, -1)
-	if err != cache.ErrTTLNotAvaliable {
+	if !errors.Is(err, cache.ErrTTLNotAvaliable) {
 		t.Fatal(err)
 	}
 	err = c.Update("test", result, -1)
-	if err != cache.ErrTTLNotAvaliable {
+	if !errors.Is(err, cache.ErrTTLNotAvaliable) {
 		t.Fatal(err)
 	}
 	err = c.SetBytesValue("test", result, -1)
-	if err != cache.ErrTTLNotAvaliable {
+	if !errors.Is(err, cache.ErrTTLNotAvaliable) {
 		t.Fatal(err)
 	}
 	err = c.UpdateBytesValue("test", result, -1)
-	if err != cache.ErrTTLNotAvaliable {
+	if !errors.Is(err, cache.ErrTTLNotAvaliable) {
 		t.Fatal(err)
 	}
 	err = c.Expire("test", -1)
-	if err != cache.ErrTTLNotAvaliable {
+	if !errors.Is(err, cache.ErrTTLNotAvaliable) {
 		t.Fatal(err)
 	}
 	err = c.SetCounter("test", 1, -1)
-	if err != cache.ErrTTLNotAvaliable {
+	if !errors.Is(err, cache.ErrTTLNotAvaliable) {
 		t.Fatal(err)
 	}
 	_, err = c.IncrCounter("test", 1, -1)
-	if err != cache.ErrTTLNotAvaliable {
+	if !errors.Is(err, cache.ErrTTLNotAvaliable) {
 		t.Fatal(err)
 	}
 	err = c.ExpireCounter("test", -1)
-	if err != cache.ErrTTLNotAvaliable {
+	if !errors.Is(err, cache.ErrTTLNotAvaliable) {
 		t.Fatal(err)
 	}
 	err = c.Load("test", &result, -1, testLoader)
-	if err != cache.ErrTTLNotAvaliable {
+	if !errors.Is(err, cache.ErrTTLNotAvaliable) {
 		t.Fatal(err)
 	}
 }
+
+func TestCollectionKeys(t *testing.T) {
+	c := newCollectionTestCache(3600)
+	for _, k := range []string{"a1", "a2", "b1"} {
+		err := c.Set(k, k, cache.DefaultTTL)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	keys, cursor, err := c.Keys("a", "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || keys[0] != "a1" || keys[1] != "a2" || cursor != "" {
+		t.Fatal(keys, cursor)
+	}
+	err = c.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, _, err = c.Keys("", "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 0 {
+		t.Fatal(keys)
+	}
+}
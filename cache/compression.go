@@ -0,0 +1,177 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/ioutil"
+	"sort"
+	"sync"
+)
+
+//Codec compression codec interface.
+type Codec interface {
+	//Compress compress given data.
+	//Return compressed data and any error if raised.
+	Compress(data []byte) ([]byte, error)
+	//Decompress decompress given data.
+	//Return decompressed data and any error if raised.
+	Decompress(data []byte) ([]byte, error)
+}
+
+//Codec header bytes.
+//Header byte is prepended to every value written by cache with compression enabled.
+const (
+	//CodecHeaderNone header of uncompressed value.
+	CodecHeaderNone = byte(0)
+	//CodecHeaderGzip header of value compressed by gzip codec.
+	CodecHeaderGzip = byte(1)
+	//CodecHeaderSnappy header of value compressed by snappy codec.
+	CodecHeaderSnappy = byte(2)
+	//CodecHeaderZstd header of value compressed by zstd codec.
+	CodecHeaderZstd = byte(3)
+)
+
+//ErrUnknownCodec error raised when codec of compressed value is not registered.
+var ErrUnknownCodec = errors.New("cache: unknown compression codec")
+
+type registeredCodec struct {
+	header byte
+	codec  Codec
+}
+
+var (
+	codecsMu      sync.RWMutex
+	codecs        = map[string]*registeredCodec{}
+	codecsByBytes = map[byte]*registeredCodec{}
+)
+
+// RegisterCodec makes a compression codec available by the provided name and header byte.
+// If RegisterCodec is called twice with the same name or header,or if codec is nil,
+// it panics.
+func RegisterCodec(name string, header byte, c Codec) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	if c == nil {
+		panic(errors.New("cache: Register codec is nil"))
+	}
+	if header == CodecHeaderNone {
+		panic(errors.New("cache: Register codec with reserved header for codec " + name))
+	}
+	if _, dup := codecs[name]; dup {
+		panic(errors.New("cache: Register codec twice for codec " + name))
+	}
+	if _, dup := codecsByBytes[header]; dup {
+		panic(errors.New("cache: Register codec header twice for codec " + name))
+	}
+	rc := &registeredCodec{header: header, codec: c}
+	codecs[name] = rc
+	codecsByBytes[header] = rc
+}
+
+//Codecs returns a sorted list of the names of the registered codecs.
+func Codecs() []string {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	var list []string
+	for name := range codecs {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
+
+//Compressor value compressor used by cache.
+//Values not smaller than MinSize will be compressed by codec,
+//and every value will be prefixed with a header byte,so that mixed compressed and uncompressed values decode correctly.
+type Compressor struct {
+	header byte
+	codec  Codec
+	//MinSize min size in bytes of value to compress.
+	MinSize int
+}
+
+//NewCompressor create new compressor with given registered codec name and min size.
+//Return compressor and any error if raised.
+func NewCompressor(name string, minsize int) (*Compressor, error) {
+	codecsMu.RLock()
+	rc, ok := codecs[name]
+	codecsMu.RUnlock()
+	if !ok {
+		return nil, errors.New("cache: unknown codec " + name + " (forgotten import?)")
+	}
+	return &Compressor{
+		header:  rc.header,
+		codec:   rc.codec,
+		MinSize: minsize,
+	}, nil
+}
+
+//Encode encode value to bytes stored in driver.
+//Return encoded bytes and any error if raised.
+func (c *Compressor) Encode(data []byte) ([]byte, error) {
+	if len(data) < c.MinSize {
+		return append([]byte{CodecHeaderNone}, data...), nil
+	}
+	compressed, err := c.codec.Compress(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(compressed) >= len(data) {
+		return append([]byte{CodecHeaderNone}, data...), nil
+	}
+	return append([]byte{c.header}, compressed...), nil
+}
+
+//Decode decode bytes stored in driver to value.
+//Value compressed by any registered codec can be decoded.
+//Return decoded value and any error if raised.
+func (c *Compressor) Decode(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return data, nil
+	}
+	if data[0] == CodecHeaderNone {
+		return data[1:], nil
+	}
+	codecsMu.RLock()
+	rc, ok := codecsByBytes[data[0]]
+	codecsMu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownCodec
+	}
+	return rc.codec.Decompress(data[1:])
+}
+
+//GzipCodec compression codec uses gzip.
+type GzipCodec struct{}
+
+//Compress compress given data.
+//Return compressed data and any error if raised.
+func (GzipCodec) Compress(data []byte) ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	w := gzip.NewWriter(buf)
+	_, err := w.Write(data)
+	if err != nil {
+		return nil, err
+	}
+	err = w.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+//Decompress decompress given data.
+//Return decompressed data and any error if raised.
+func (GzipCodec) Decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewBuffer(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+func init() {
+	RegisterCodec("gzip", CodecHeaderGzip, GzipCodec{})
+}
@@ -0,0 +1,94 @@
+package cache_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+func newCompressedTestCache(ttl int64, minsize int) *cache.Cache {
+	c := cache.New()
+	oc := cache.NewOptionConfig()
+	oc.Driver = "syncmapcache"
+	oc.TTL = int64(ttl)
+	oc.Config = json.NewDecoder(bytes.NewBufferString(`{"Size":10000000}`)).Decode
+	oc.Marshaler = "json"
+	oc.Compression = "gzip"
+	oc.CompressionMinSize = minsize
+	err := c.Init(oc)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func TestCompression(t *testing.T) {
+	c := newCompressedTestCache(3600, 64)
+	large := strings.Repeat("testdata", 100)
+	err := c.Set("large", large, cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.SetBytesValue("small", []byte("small"), cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := c.Driver.GetBytesValue(c.FinalKey("large"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw[0] != cache.CodecHeaderGzip || len(raw) >= len(large) {
+		t.Fatal(raw)
+	}
+	raw, err = c.Driver.GetBytesValue(c.FinalKey("small"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw[0] != cache.CodecHeaderNone || string(raw[1:]) != "small" {
+		t.Fatal(raw)
+	}
+	var v string
+	err = c.Get("large", &v)
+	if err != nil || v != large {
+		t.Fatal(v, err)
+	}
+	data, err := c.MGetBytesValue("small", "large", "notexist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 2 || string(data["small"]) != "small" {
+		t.Fatal(data)
+	}
+	bs, version, err := c.GetWithVersion("small")
+	if err != nil || string(bs) != "small" {
+		t.Fatal(bs, err)
+	}
+	ok, err := c.SetIfVersion("small", []byte("newsmall"), version, cache.DefaultTTL)
+	if err != nil || !ok {
+		t.Fatal(ok, err)
+	}
+	bs, err = c.GetBytesValue("small")
+	if err != nil || string(bs) != "newsmall" {
+		t.Fatal(bs, err)
+	}
+	err = c.Driver.SetBytesValue(c.FinalKey("unknown"), []byte{255, 1}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.GetBytesValue("unknown")
+	if !errors.Is(err, cache.ErrUnknownCodec) {
+		t.Fatal(err)
+	}
+	oc := cache.NewOptionConfig()
+	oc.Driver = "dummycache"
+	oc.Compression = "notexist"
+	err = oc.ApplyTo(cache.New())
+	if err == nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,364 @@
+//Package config load cache option tree from config files and environment variables.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+//ErrUnknownFormat error raised when config format has no registered decoder.
+var ErrUnknownFormat = errors.New("cache config: unknown format")
+
+//ErrInvalidConfig error raised when config tree does not match option structure.
+var ErrInvalidConfig = errors.New("cache config: invalid config")
+
+//Decoder config decoder which unmarshals data into v,like json.Unmarshal.
+type Decoder func(data []byte, v interface{}) error
+
+var (
+	decodersMu sync.RWMutex
+	decoders   = map[string]Decoder{
+		"json": json.Unmarshal,
+	}
+)
+
+//RegisterDecoder register decoder by format name.
+//Json decoder is registered by default.
+func RegisterDecoder(format string, d Decoder) {
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+	decoders[strings.ToLower(format)] = d
+}
+
+//Config cache option tree.
+//Keys are matched case-insensitively with cache.OptionConfig fields.
+//Value of "Config" key is driver config,which can contain nested option trees of sub caches.
+type Config map[string]interface{}
+
+//Load decode config tree from data with given format.
+//Return config and any error if raised.
+func Load(format string, data []byte) (Config, error) {
+	decodersMu.RLock()
+	d, ok := decoders[strings.ToLower(format)]
+	decodersMu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownFormat
+	}
+	var v map[string]interface{}
+	err := d(data, &v)
+	if err != nil {
+		return nil, err
+	}
+	return Config(normalize(v).(map[string]interface{})), nil
+}
+
+//LoadFile load config tree from given file.
+//Format is detected by file extension,".yml" is treated as yaml.
+//Return config and any error if raised.
+func LoadFile(path string) (Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	format := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+	if format == "yml" {
+		format = "yaml"
+	}
+	return Load(format, data)
+}
+
+//normalize convert map[interface{}]interface{} decoded by some yaml decoders to map[string]interface{}.
+func normalize(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, v := range t {
+			m[fmt.Sprint(k)] = normalize(v)
+		}
+		return m
+	case map[string]interface{}:
+		for k, v := range t {
+			t[k] = normalize(v)
+		}
+		return t
+	case []interface{}:
+		for k, v := range t {
+			t[k] = normalize(v)
+		}
+		return t
+	}
+	return v
+}
+
+func findKey(m map[string]interface{}, name string) (string, bool) {
+	if _, ok := m[name]; ok {
+		return name, true
+	}
+	for k := range m {
+		if strings.EqualFold(k, name) {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+//ApplyEnv override config tree with environment variables which starts with given prefix and "_".
+//Variable name after prefix is path of value separated by "_",
+//for example PREFIX_TTL,PREFIX_CONFIG_SIZE,PREFIX_CONFIG_0_DRIVER.
+//Path segments are matched case-insensitively with existing keys,keys containing "_" are supported.
+//New keys are created with rest of name if no existing key matched.
+//Values are converted to type of existing value,or guessed as json value if key is new.
+//Environ should be in "key=value" form,like os.Environ().
+//Return any error if raised.
+func (c Config) ApplyEnv(prefix string, environ []string) error {
+	prefix = prefix + "_"
+	for _, e := range environ {
+		i := strings.Index(e, "=")
+		if i < 0 {
+			continue
+		}
+		name, value := e[:i], e[i+1:]
+		if len(name) <= len(prefix) || !strings.EqualFold(name[:len(prefix)], prefix) {
+			continue
+		}
+		err := setPath(map[string]interface{}(c), strings.Split(name[len(prefix):], "_"), value)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %s", ErrInvalidConfig, name, err)
+		}
+	}
+	return nil
+}
+
+func setPath(node interface{}, path []string, value string) error {
+	switch t := node.(type) {
+	case map[string]interface{}:
+		for n := len(path); n > 0; n-- {
+			key, ok := findKey(t, strings.Join(path[:n], "_"))
+			if !ok {
+				continue
+			}
+			if n == len(path) {
+				v, err := convert(t[key], value)
+				if err != nil {
+					return err
+				}
+				t[key] = v
+				return nil
+			}
+			return setPath(t[key], path[n:], value)
+		}
+		t[strings.Join(path, "_")] = guess(value)
+		return nil
+	case []interface{}:
+		i, err := strconv.Atoi(path[0])
+		if err != nil || i < 0 || i >= len(t) {
+			return errors.New("index out of range")
+		}
+		if len(path) == 1 {
+			v, err := convert(t[i], value)
+			if err != nil {
+				return err
+			}
+			t[i] = v
+			return nil
+		}
+		return setPath(t[i], path[1:], value)
+	}
+	return errors.New("value is not a map or list")
+}
+
+func guess(value string) interface{} {
+	var v interface{}
+	if json.Unmarshal([]byte(value), &v) == nil {
+		return v
+	}
+	return value
+}
+
+func convert(current interface{}, value string) (interface{}, error) {
+	switch current.(type) {
+	case string:
+		return value, nil
+	case bool:
+		return strconv.ParseBool(value)
+	case float64:
+		return strconv.ParseFloat(value, 64)
+	case int64:
+		return strconv.ParseInt(value, 10, 64)
+	case int:
+		return strconv.Atoi(value)
+	}
+	return guess(value), nil
+}
+
+var optionType = reflect.TypeOf(cache.OptionConfig{})
+
+func containsOption(t reflect.Type, visited map[reflect.Type]bool) bool {
+	if t == optionType {
+		return true
+	}
+	if visited[t] {
+		return false
+	}
+	visited[t] = true
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return containsOption(t.Elem(), visited)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if containsOption(t.Field(i).Type, visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func jsonDecode(value interface{}, rv reflect.Value) error {
+	bs, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bs, rv.Addr().Interface())
+}
+
+//decode decode config value into rv.
+//Nested option configs are built by optionConfig,other values are decoded by json.
+func decode(value interface{}, rv reflect.Value) error {
+	if value == nil {
+		return nil
+	}
+	t := rv.Type()
+	if !containsOption(t, map[reflect.Type]bool{}) {
+		return jsonDecode(value, rv)
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			rv.Set(reflect.New(t.Elem()))
+		}
+		return decode(value, rv.Elem())
+	case reflect.Slice:
+		list, ok := value.([]interface{})
+		if !ok {
+			return ErrInvalidConfig
+		}
+		s := reflect.MakeSlice(t, len(list), len(list))
+		for k := range list {
+			err := decode(list[k], s.Index(k))
+			if err != nil {
+				return err
+			}
+		}
+		rv.Set(s)
+		return nil
+	case reflect.Map:
+		m, ok := value.(map[string]interface{})
+		if !ok || t.Key().Kind() != reflect.String {
+			return ErrInvalidConfig
+		}
+		result := reflect.MakeMapWithSize(t, len(m))
+		for k := range m {
+			v := reflect.New(t.Elem()).Elem()
+			err := decode(m[k], v)
+			if err != nil {
+				return err
+			}
+			result.SetMapIndex(reflect.ValueOf(k).Convert(t.Key()), v)
+		}
+		rv.Set(result)
+		return nil
+	case reflect.Struct:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return ErrInvalidConfig
+		}
+		if t == optionType {
+			o, err := Config(m).OptionConfig()
+			if err != nil {
+				return err
+			}
+			rv.Set(reflect.ValueOf(*o))
+			return nil
+		}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			key, ok := findKey(m, f.Name)
+			if !ok {
+				continue
+			}
+			err := decode(m[key], rv.Field(i))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return ErrInvalidConfig
+}
+
+//Loader return driver config loader which decodes given value.
+//Nested cache.OptionConfig values in loaded struct are built from option trees.
+func Loader(value interface{}) func(v interface{}) error {
+	return func(v interface{}) error {
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Ptr || rv.IsNil() {
+			return ErrInvalidConfig
+		}
+		return decode(value, rv.Elem())
+	}
+}
+
+//OptionConfig convert config tree to cache option config.
+//Return option config and any error if raised.
+func (c Config) OptionConfig() (*cache.OptionConfig, error) {
+	fields := make(map[string]interface{}, len(c))
+	var driverconfig interface{}
+	for k, v := range c {
+		if strings.EqualFold(k, "Config") {
+			driverconfig = v
+			continue
+		}
+		fields[k] = v
+	}
+	o := cache.NewOptionConfig()
+	err := jsonDecode(fields, reflect.ValueOf(o).Elem())
+	if err != nil {
+		return nil, err
+	}
+	o.Config = Loader(driverconfig)
+	return o, nil
+}
+
+//ApplyTo apply config tree to given cache.
+//Return any error if raised.
+func (c Config) ApplyTo(cache *cache.Cache) error {
+	o, err := c.OptionConfig()
+	if err != nil {
+		return err
+	}
+	return o.ApplyTo(cache)
+}
+
+//Create create new cache with config tree.
+//Return cache created and any error if raised.
+func (c Config) Create() (*cache.Cache, error) {
+	cc := cache.New()
+	err := cc.Init(c)
+	if err != nil {
+		return nil, err
+	}
+	return cc, nil
+}
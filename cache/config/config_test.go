@@ -0,0 +1,120 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+	"github.com/herb-go/deprecated/cache/drivers/cachegroup"
+	_ "github.com/herb-go/deprecated/cache/drivers/syncmapcache"
+)
+
+const testConfig = `{
+	"Driver": "cachegroup",
+	"TTL": 3600,
+	"Marshaler": "json",
+	"Config": {
+		"Caches": [
+			{"Driver": "syncmapcache", "Marshaler": "json", "TTL": 60, "Config": {"Size": 1000}},
+			{"Driver": "syncmapcache", "Marshaler": "json", "TTL": 600, "Config": {"Size": 100000}}
+		],
+		"Promotion": "async",
+		"Layers": [{"MaxTTLInSecond": 30}]
+	}
+}`
+
+func TestLoad(t *testing.T) {
+	_, err := Load("notexist", []byte(testConfig))
+	if err != ErrUnknownFormat {
+		t.Fatal(err)
+	}
+	c, err := Load("json", []byte(testConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc, err := c.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cc.Close()
+	if cc.DefaultTTL() != time.Hour {
+		t.Fatal(cc.DefaultTTL())
+	}
+	group, ok := cc.Driver.(*cachegroup.Cache)
+	if !ok || len(group.SubCaches) != 2 || group.SubCaches[0].DefaultTTL() != time.Minute {
+		t.Fatal(cc.Driver)
+	}
+	if group.Promotion != cachegroup.PromoteAsync || len(group.Layers) != 1 || group.Layers[0].MaxTTL != 30*time.Second {
+		t.Fatal(group)
+	}
+	err = cc.SetBytesValue("test", []byte("test"), cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := cc.GetBytesValue("test")
+	if err != nil || string(bs) != "test" {
+		t.Fatal(string(bs), err)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+	file := filepath.Join(tmpdir, "cache.json")
+	err = ioutil.WriteFile(file, []byte(testConfig), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := LoadFile(file)
+	if err != nil || c["Driver"] != "cachegroup" {
+		t.Fatal(c, err)
+	}
+	_, err = LoadFile(filepath.Join(tmpdir, "cache.toml"))
+	if err == nil {
+		t.Fatal(err)
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	c, err := Load("json", []byte(testConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.ApplyEnv("CACHE", []string{
+		"CACHE_TTL=1800",
+		"CACHE_CONFIG_CACHES_1_TTL=300",
+		"CACHE_MAXENTRYSIZE=1024",
+		"CACHE_MAXTTL=7200",
+		"OTHER_TTL=1",
+		"PATH=/bin",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	o, err := c.OptionConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.TTL != 1800 || o.MaxTTL != 7200 || o.MaxEntrySize != 1024 || o.Driver != "cachegroup" {
+		t.Fatal(o)
+	}
+	gc := &cachegroup.Config{}
+	err = o.Config(gc)
+	if err != nil || len(gc.Caches) != 2 || gc.Caches[1].TTL != 300 || gc.Caches[0].TTL != 60 {
+		t.Fatal(gc, err)
+	}
+	err = c.ApplyEnv("CACHE", []string{"CACHE_TTL=notanumber"})
+	if err == nil {
+		t.Fatal(err)
+	}
+	err = c.ApplyEnv("CACHE", []string{"CACHE_CONFIG_CACHES_5_TTL=1"})
+	if err == nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"time"
+)
+
+//ErrInvalidCounterValue error raised when encoded counter value is malformed.
+var ErrInvalidCounterValue = errors.New("cache: invalid counter value")
+
+//CounterEncoding counter value encoding interface.
+//Drivers which store counters as bytes can use counter encoding,
+//so that counter data can be converted when migrating between drivers.
+type CounterEncoding interface {
+	//EncodeCounter encode counter value to bytes.
+	EncodeCounter(v int64) []byte
+	//DecodeCounter decode bytes to counter value.
+	//Return counter value and any error if raised.
+	DecodeCounter(data []byte) (int64, error)
+}
+
+//DecimalCounterEncoding counter encoding which stores value as decimal string,like redis.
+type DecimalCounterEncoding struct{}
+
+//EncodeCounter encode counter value to bytes.
+func (DecimalCounterEncoding) EncodeCounter(v int64) []byte {
+	return []byte(strconv.FormatInt(v, 10))
+}
+
+//DecodeCounter decode bytes to counter value.
+//Return counter value and any error if raised.
+func (DecimalCounterEncoding) DecodeCounter(data []byte) (int64, error) {
+	v, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return 0, ErrInvalidCounterValue
+	}
+	return v, nil
+}
+
+//BigEndianCounterEncoding counter encoding which stores value as 8 bytes big endian integer,like freecache.
+type BigEndianCounterEncoding struct{}
+
+//EncodeCounter encode counter value to bytes.
+func (BigEndianCounterEncoding) EncodeCounter(v int64) []byte {
+	bs := make([]byte, 8)
+	binary.BigEndian.PutUint64(bs, uint64(v))
+	return bs
+}
+
+//DecodeCounter decode bytes to counter value.
+//Return counter value and any error if raised.
+func (BigEndianCounterEncoding) DecodeCounter(data []byte) (int64, error) {
+	if len(data) != 8 {
+		return 0, ErrInvalidCounterValue
+	}
+	return int64(binary.BigEndian.Uint64(data)), nil
+}
+
+//PortableCounterEncoding counter encoding used to export counters.
+var PortableCounterEncoding CounterEncoding = DecimalCounterEncoding{}
+
+//ConvertCounterValue convert counter value encoded by from encoding to to encoding.
+//Return converted bytes and any error if raised.
+func ConvertCounterValue(data []byte, from CounterEncoding, to CounterEncoding) ([]byte, error) {
+	v, err := from.DecodeCounter(data)
+	if err != nil {
+		return nil, err
+	}
+	return to.EncodeCounter(v), nil
+}
+
+//CounterEntry counter entry exported from cache.
+type CounterEntry struct {
+	//Key counter key.
+	Key string
+	//Value counter value encoded by PortableCounterEncoding.
+	Value []byte
+	//TTL ttl used when importing counter.
+	//DefaultTTL means default ttl of destination cacheable.
+	TTL time.Duration
+}
+
+//ExportCounters export counters with given keys from given cacheable.
+//Counters not found are skipped.
+//Ttl of exported entries is DefaultTTL,because counter ttl cannot be inspected.
+//Return counter entries and any error if raised.
+func ExportCounters(c Cacheable, keys ...string) ([]*CounterEntry, error) {
+	result := make([]*CounterEntry, 0, len(keys))
+	for _, k := range keys {
+		v, err := c.GetCounter(k)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, &CounterEntry{
+			Key:   k,
+			Value: PortableCounterEncoding.EncodeCounter(v),
+			TTL:   DefaultTTL,
+		})
+	}
+	return result, nil
+}
+
+//ImportCounters import counter entries to given cacheable.
+//Return any error if raised.
+func ImportCounters(c Cacheable, entries []*CounterEntry) error {
+	for _, e := range entries {
+		v, err := PortableCounterEncoding.DecodeCounter(e.Value)
+		if err != nil {
+			return err
+		}
+		err = c.SetCounter(e.Key, v, e.TTL)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//MigrateCounters copy counters with given keys from src to dst.
+//Return any error if raised.
+func MigrateCounters(src Cacheable, dst Cacheable, keys ...string) error {
+	entries, err := ExportCounters(src, keys...)
+	if err != nil {
+		return err
+	}
+	return ImportCounters(dst, entries)
+}
@@ -0,0 +1,109 @@
+package cache_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+func TestCounterEncoding(t *testing.T) {
+	for _, e := range []cache.CounterEncoding{cache.DecimalCounterEncoding{}, cache.BigEndianCounterEncoding{}} {
+		for _, v := range []int64{0, 1, -1, 1 << 62, -(1 << 62)} {
+			r, err := e.DecodeCounter(e.EncodeCounter(v))
+			if err != nil || r != v {
+				t.Fatal(r, err)
+			}
+		}
+		_, err := e.DecodeCounter([]byte("bad"))
+		if !errors.Is(err, cache.ErrInvalidCounterValue) {
+			t.Fatal(err)
+		}
+	}
+	bs, err := cache.ConvertCounterValue([]byte{0, 0, 0, 0, 0, 0, 1, 0}, cache.BigEndianCounterEncoding{}, cache.DecimalCounterEncoding{})
+	if err != nil || string(bs) != "256" {
+		t.Fatal(string(bs), err)
+	}
+}
+
+func TestMigrateCounters(t *testing.T) {
+	src := newTestCache(3600)
+	dst := cache.NewNode(newTestCache(3600), "dst")
+	err := src.SetCounter("counter1", 10, cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = src.IncrCounter("counter2", -5, cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := cache.ExportCounters(src, "counter1", "counter2", "notexist")
+	if err != nil || len(entries) != 2 || string(entries[0].Value) != "10" || string(entries[1].Value) != "-5" {
+		t.Fatal(entries, err)
+	}
+	err = cache.MigrateCounters(src, dst, "counter1", "counter2", "notexist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := dst.GetCounter("counter1")
+	if err != nil || v != 10 {
+		t.Fatal(v, err)
+	}
+	v, err = dst.GetCounter("counter2")
+	if err != nil || v != -5 {
+		t.Fatal(v, err)
+	}
+	_, err = dst.GetCounter("notexist")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+}
+
+func TestFloatCounter(t *testing.T) {
+	c := newTestCache(3600)
+	n := cache.NewNode(c, "floatcounter")
+	for _, c := range []cache.Cacheable{c, n, cache.NewCollection(c, "floatcollection", time.Hour)} {
+		_, err := cache.GetFloatCounter(c, "test")
+		if !errors.Is(err, cache.ErrNotFound) {
+			t.Fatal(err)
+		}
+		v, err := cache.IncrFloatCounter(c, "test", 1.5, cache.DefaultTTL)
+		if err != nil || v != 1.5 {
+			t.Fatal(v, err)
+		}
+		v, err = cache.IncrFloatCounter(c, "test", -0.25, cache.DefaultTTL)
+		if err != nil || v != 1.25 {
+			t.Fatal(v, err)
+		}
+		v, err = cache.GetFloatCounter(c, "test")
+		if err != nil || v != 1.25 {
+			t.Fatal(v, err)
+		}
+		err = c.DelCounter("test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = cache.GetFloatCounter(c, "test")
+		if !errors.Is(err, cache.ErrNotFound) {
+			t.Fatal(err)
+		}
+	}
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.IncrFloatCounter("concurrent", 0.5, cache.DefaultTTL)
+			if err != nil {
+				panic(err)
+			}
+		}()
+	}
+	wg.Wait()
+	v, err := c.GetFloatCounter("concurrent")
+	if err != nil || v != 5 {
+		t.Fatal(v, err)
+	}
+}
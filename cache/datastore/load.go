@@ -1,6 +1,7 @@
 package datastore
 
 import (
+	"errors"
 	"sort"
 
 	"github.com/herb-go/deprecated/cache"
@@ -81,7 +82,10 @@ func Load(s Store, c cache.Cacheable, loader func(...string) (map[string]interfa
 
 			locker, ok := c.Util().Locker(key)
 			if ok {
-				locker.RLock()
+				err := c.Util().RLock(locker)
+				if err != nil {
+					return err
+				}
 				defer locker.RUnlock()
 				bs, err := c.GetBytesValue(uncachedKeys[k])
 				if err == nil {
@@ -91,11 +95,14 @@ func Load(s Store, c cache.Cacheable, loader func(...string) (map[string]interfa
 					}
 					continue
 				}
-				if err != cache.ErrNotFound {
+				if !errors.Is(err, cache.ErrNotFound) {
 					return err
 				}
 			} else {
-				locker.Lock()
+				err := c.Util().Lock(locker)
+				if err != nil {
+					return err
+				}
 				defer locker.Unlock()
 			}
 			unreloadkeys = append(unreloadkeys, uncachedKeys[k])
@@ -0,0 +1,173 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+//ErrLockNotHeld error raised when distributed lock is not held by locker.
+var ErrLockNotHeld = errors.New("cache: distributed lock not held")
+
+//DistributedLockerCapabilities capabilities cacheable must support to store distributed lock.
+const DistributedLockerCapabilities = CapabilityCounter | CapabilityNX | CapabilityCAS
+
+//distributedLockerFenceTTL ttl of fencing token counter.
+//Cache drivers have no common way to store counter without ttl,
+//so counter is stored with ttl long enough never to expire,and ttl is extended every time lock is acquired.
+const distributedLockerFenceTTL = 10 * 365 * 24 * time.Hour
+
+//DistributedLocker lock shared by multiple service instances,backed by SetIfNotExists of cacheable.
+//Every successful lock gets a fencing token,which is increased every time lock is acquired,
+//so that resources can reject writes from former lock holders whose lease expired.
+//Fencing token counter does not expire,cacheable should not clamp counter ttl by MaxTTL.
+type DistributedLocker struct {
+	//Cache cacheable which stores lock.
+	Cache Cacheable
+	//Key lock key.
+	Key string
+	//TTL lock lease duration.
+	TTL    time.Duration
+	locker sync.Mutex
+	token  int64
+	value  []byte
+}
+
+//NewDistributedLocker create new distributed locker with given cacheable,key and lease ttl.
+//Cacheable must support all DistributedLockerCapabilities,
+//otherwise lock could not be acquired atomically.
+//Return locker created and ErrFeatureNotSupported if capabilities not supported.
+func NewDistributedLocker(c Cacheable, key string, ttl time.Duration) (*DistributedLocker, error) {
+	if !CacheableCapabilities(c).Has(DistributedLockerCapabilities) {
+		return nil, ErrFeatureNotSupported
+	}
+	return &DistributedLocker{
+		Cache: c,
+		Key:   key,
+		TTL:   ttl,
+	}, nil
+}
+
+//Lock try to acquire lock once.
+//Return whether lock is acquired and any error raised.
+func (l *DistributedLocker) Lock() (bool, error) {
+	l.locker.Lock()
+	defer l.locker.Unlock()
+	token, err := l.Cache.IncrCounter(l.Key, 1, distributedLockerFenceTTL)
+	if err != nil {
+		return false, err
+	}
+	value := PortableCounterEncoding.EncodeCounter(token)
+	ok, err := SetIfNotExists(l.Cache, l.Key, value, l.TTL)
+	if err != nil || !ok {
+		return false, err
+	}
+	l.token = token
+	l.value = value
+	return true, nil
+}
+
+//Token return fencing token of held lock.
+//Return 0 if lock is not held.
+func (l *DistributedLocker) Token() int64 {
+	l.locker.Lock()
+	defer l.locker.Unlock()
+	return l.token
+}
+
+func (l *DistributedLocker) current() (string, error) {
+	if l.value == nil {
+		return "", ErrLockNotHeld
+	}
+	bs, version, err := GetWithVersion(l.Cache, l.Key)
+	if errors.Is(err, ErrNotFound) {
+		return "", ErrLockNotHeld
+	}
+	if err != nil {
+		return "", err
+	}
+	if string(bs) != string(l.value) {
+		return "", ErrLockNotHeld
+	}
+	return version, nil
+}
+
+func (l *DistributedLocker) release() {
+	l.token = 0
+	l.value = nil
+}
+
+//Refresh extend lease of held lock to lock ttl.
+//Return ErrLockNotHeld if lock is not held or lease expired.
+//Return any error raised.
+func (l *DistributedLocker) Refresh() error {
+	l.locker.Lock()
+	defer l.locker.Unlock()
+	version, err := l.current()
+	if errors.Is(err, ErrLockNotHeld) {
+		l.release()
+	}
+	if err != nil {
+		return err
+	}
+	ok, err := SetIfVersion(l.Cache, l.Key, l.value, version, l.TTL)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		l.release()
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+//Unlock release held lock.
+//Lock is checked before deleted,but lock could still be acquired by others between checking and deleting if lease expires,
+//use fencing token to protect resources.
+//Return ErrLockNotHeld if lock is not held or lease expired.
+//Return any error raised.
+func (l *DistributedLocker) Unlock() error {
+	l.locker.Lock()
+	defer l.locker.Unlock()
+	_, err := l.current()
+	if errors.Is(err, ErrLockNotHeld) {
+		l.release()
+	}
+	if err != nil {
+		return err
+	}
+	l.release()
+	return l.Cache.Del(l.Key)
+}
+
+//StartAutoRenew refresh held lock in background every interval.
+//OnError will be called with any error raised when refreshing if not nil.
+//Auto renewal stops when lock is not held any more.
+//Return function which stops auto renewal.
+func (l *DistributedLocker) StartAutoRenew(interval time.Duration, onError func(err error)) func() {
+	stop := make(chan struct{})
+	var once sync.Once
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				err := l.Refresh()
+				if err != nil && onError != nil {
+					onError(err)
+				}
+				if errors.Is(err, ErrLockNotHeld) {
+					return
+				}
+			}
+		}
+	}()
+	return func() {
+		once.Do(func() {
+			close(stop)
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package cache_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+func TestDistributedLocker(t *testing.T) {
+	c := newTestCache(3600)
+	l1, err := cache.NewDistributedLocker(c, "lock", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l2, err := cache.NewDistributedLocker(c, "lock", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, err := l1.Lock()
+	if !ok || err != nil {
+		t.Fatal(ok, err)
+	}
+	token := l1.Token()
+	if token <= 0 {
+		t.Fatal(token)
+	}
+	ok, err = l2.Lock()
+	if ok || err != nil {
+		t.Fatal(ok, err)
+	}
+	if l2.Token() != 0 {
+		t.Fatal(l2.Token())
+	}
+	err = l2.Refresh()
+	if !errors.Is(err, cache.ErrLockNotHeld) {
+		t.Fatal(err)
+	}
+	err = l2.Unlock()
+	if !errors.Is(err, cache.ErrLockNotHeld) {
+		t.Fatal(err)
+	}
+	err = l1.Refresh()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = l1.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l1.Token() != 0 {
+		t.Fatal(l1.Token())
+	}
+	ok, err = l2.Lock()
+	if !ok || err != nil {
+		t.Fatal(ok, err)
+	}
+	if l2.Token() <= token {
+		t.Fatal(l2.Token(), token)
+	}
+	err = c.Del("lock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = l2.Refresh()
+	if !errors.Is(err, cache.ErrLockNotHeld) {
+		t.Fatal(err)
+	}
+	if l2.Token() != 0 {
+		t.Fatal(l2.Token())
+	}
+}
+
+func TestDistributedLockerAutoRenew(t *testing.T) {
+	c := newTestCache(3600)
+	l, err := cache.NewDistributedLocker(c, "lock", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, err := l.Lock()
+	if !ok || err != nil {
+		t.Fatal(ok, err)
+	}
+	errs := make(chan error, 1)
+	stop := l.StartAutoRenew(time.Millisecond, func(err error) {
+		errs <- err
+	})
+	defer stop()
+	time.Sleep(5 * time.Millisecond)
+	err = c.Del("lock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err = <-errs:
+		if !errors.Is(err, cache.ErrLockNotHeld) {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("auto renew not stopped")
+	}
+	stop()
+}
+
+type noCASCacheable struct {
+	cache.Cacheable
+}
+
+func (c noCASCacheable) Capabilities() cache.Capabilities {
+	return cache.CacheableCapabilities(c.Cacheable) &^ cache.CapabilityCAS
+}
+
+func TestDistributedLockerCapabilities(t *testing.T) {
+	_, err := cache.NewDistributedLocker(noCASCacheable{newTestCache(3600)}, "lock", time.Hour)
+	if !errors.Is(err, cache.ErrFeatureNotSupported) {
+		t.Fatal(err)
+	}
+}
@@ -1,10 +1,12 @@
 package cache
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"sort"
 	"sync"
+	"time"
 )
 
 var dummoyLoader = func(v interface{}) error {
@@ -22,6 +24,78 @@ type Driver interface {
 	SetGCErrHandler(f func(err error))
 }
 
+//TTLGetter optional driver interface which can inspect remaining ttl of cached entry.
+type TTLGetter interface {
+	//GetTTL get remaining ttl of given key.
+	//Return ttl and any error raised.
+	//Return ErrNotFound if key not exists.
+	GetTTL(key string) (time.Duration, error)
+}
+
+//NXSetter optional driver interface which can set entry only if key not exists atomically.
+type NXSetter interface {
+	//SetIfNotExists Set bytes data to cache by given key only if the key does not exist.
+	//Return whether data is set and any error raised.
+	SetIfNotExists(key string, bytes []byte, ttl time.Duration) (bool, error)
+}
+
+//CASSetter optional driver interface which can compare and swap entry atomically.
+type CASSetter interface {
+	//SetIfVersion Set bytes data to cache by given key only if current value version equals given version.
+	//Empty version means key should not exist.
+	//Return whether data is set and any error raised.
+	SetIfVersion(key string, bytes []byte, version string, ttl time.Duration) (bool, error)
+}
+
+//FloatCounter optional driver interface which can increase float counter natively.
+type FloatCounter interface {
+	//IncrFloatCounter Increase float val in cache by given key.
+	//Return float data value and any error raised.
+	IncrFloatCounter(key string, increment float64, ttl time.Duration) (float64, error)
+	//GetFloatCounter Get float val from cache by given key.
+	//Return float data value and any error raised.
+	GetFloatCounter(key string) (float64, error)
+}
+
+//Pinger optional driver interface which can check if cache backend is reachable.
+type Pinger interface {
+	//Ping check if cache backend is reachable before context done.
+	//Return any error raised.
+	Ping(ctx context.Context) error
+}
+
+//Sizer optional driver interface which reports entry count and memory usage.
+type Sizer interface {
+	//Usage return entry count and approximate bytes used by driver.
+	//Counters are counted as entries.
+	//Return entry count,bytes used and any error raised.
+	Usage() (entries int64, bytes int64, err error)
+}
+
+//EvictionNotifier optional driver interface which reports entries evicted by driver.
+type EvictionNotifier interface {
+	//OnEvicted register callback called with driver key and value of every entry evicted due to size limit or expiration.
+	OnEvicted(f func(key string, value []byte))
+}
+
+//Iterable optional driver interface which can enumerate keys.
+type Iterable interface {
+	//Keys list keys with given prefix from given cursor.
+	//Empty cursor means start from beginning.
+	//Count is a hint of max keys returned in one page.
+	//Return keys,next cursor and any error raised.
+	//Empty next cursor means iteration finished.
+	Keys(prefix string, cursor string, count int) ([]string, string, error)
+}
+
+//Renamer optional driver interface which can rename keys atomically.
+type Renamer interface {
+	//Rename move value of old key to new key atomically.
+	//Existing value of new key should be overwritten,and remaining ttl of old key should be kept.
+	//Return ErrNotFound if old key not exists.
+	Rename(oldKey string, newKey string) error
+}
+
 var (
 	factorysMu sync.RWMutex
 	factories  = make(map[string]Factory)
@@ -9,7 +9,7 @@ import (
 
 func TestDriver(t *testing.T) {
 	factories := cache.Factories()
-	if len(factories) != 2 {
+	if len(factories) != 3 {
 		t.Fatal(factories)
 	}
 	dc, err := cache.NewDriver("dummycache", nil)
@@ -27,6 +27,9 @@ func TestDriver(t *testing.T) {
 	cache.Register("dummycache", func(func(interface{}) error) (cache.Driver, error) {
 		return &cache.DummyCache{}, nil
 	})
+	cache.Register("nullcache", func(func(interface{}) error) (cache.Driver, error) {
+		return &cache.DummyCache{}, nil
+	})
 	syncmapcache.Register()
 }
 
@@ -0,0 +1,454 @@
+//Package badgercache provides cache driver uses badger to store cache data.
+//Using github.com/dgraph-io/badger/v3 as driver.
+//Entries expire by badger native ttl,and value log is garbage collected in background.
+package badgercache
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dgraph-io/badger/v3"
+	"github.com/herb-go/deprecated/cache"
+)
+
+var defaultGCPeriod = 5 * time.Minute
+var defaultGCDiscardRatio = 0.5
+
+//ConflictRetry max retry times when transaction conflicts.
+var ConflictRetry = 10
+
+//CounterEncoding encoding used to store counter values.
+var CounterEncoding cache.CounterEncoding = cache.BigEndianCounterEncoding{}
+
+//Cache The badger cache Driver.
+type Cache struct {
+	cache.DriverUtil
+	DB             *badger.DB
+	ticker         *time.Ticker
+	quit           chan int
+	gcErrHandler   func(err error)
+	gcDiscardRatio float64
+}
+
+//SetGCErrHandler Set callback to handler error raised when gc.
+func (c *Cache) SetGCErrHandler(f func(err error)) {
+	c.gcErrHandler = f
+	return
+}
+
+//gc run value log gc until no file rewritten.
+//Return any error raised.
+func (c *Cache) gc() error {
+	for {
+		err := c.DB.RunValueLogGC(c.gcDiscardRatio)
+		if err == badger.ErrNoRewrite || err == badger.ErrRejected {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+//update run fn in read-write transaction.
+//Transaction is retried if conflicted.
+func (c *Cache) update(fn func(txn *badger.Txn) error) error {
+	var err error
+	for i := 0; i <= ConflictRetry; i++ {
+		err = c.DB.Update(fn)
+		if err != badger.ErrConflict {
+			return err
+		}
+	}
+	return err
+}
+
+func get(txn *badger.Txn, key string) ([]byte, bool, error) {
+	item, err := txn.Get([]byte(key))
+	if err == badger.ErrKeyNotFound {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	data, err := item.ValueCopy(nil)
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+func set(txn *badger.Txn, key string, data []byte, ttl time.Duration) error {
+	e := badger.NewEntry([]byte(key), data)
+	if ttl > 0 {
+		e = e.WithTTL(ttl)
+	}
+	return txn.SetEntry(e)
+}
+
+//SetBytesValue Set bytes data to cache by given key.
+//Return any error raised.
+func (c *Cache) SetBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	return c.update(func(txn *badger.Txn) error {
+		return set(txn, key, bytes, ttl)
+	})
+}
+
+//UpdateBytesValue Update bytes data to cache by given key only if the cache exist.
+//Return any error raised.
+func (c *Cache) UpdateBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	return c.update(func(txn *badger.Txn) error {
+		_, ok, err := get(txn, key)
+		if err != nil || !ok {
+			return err
+		}
+		return set(txn, key, bytes, ttl)
+	})
+}
+
+//SetIfNotExists Set bytes data to cache by given key only if the key does not exist.
+//Return whether data is set and any error raised.
+func (c *Cache) SetIfNotExists(key string, bytes []byte, ttl time.Duration) (bool, error) {
+	var result bool
+	err := c.update(func(txn *badger.Txn) error {
+		result = false
+		_, ok, err := get(txn, key)
+		if err != nil || ok {
+			return err
+		}
+		result = true
+		return set(txn, key, bytes, ttl)
+	})
+	if err != nil {
+		return false, err
+	}
+	return result, nil
+}
+
+//SetIfVersion Set bytes data to cache by given key only if current value version equals given version.
+//Empty version means key should not exist.
+//Return whether data is set and any error raised.
+func (c *Cache) SetIfVersion(key string, bytes []byte, version string, ttl time.Duration) (bool, error) {
+	var result bool
+	err := c.update(func(txn *badger.Txn) error {
+		result = false
+		data, ok, err := get(txn, key)
+		if err != nil {
+			return err
+		}
+		if ok {
+			if version != cache.ValueVersion(data) {
+				return nil
+			}
+		} else if version != "" {
+			return nil
+		}
+		result = true
+		return set(txn, key, bytes, ttl)
+	})
+	if err != nil {
+		return false, err
+	}
+	return result, nil
+}
+
+//GetBytesValue Get bytes data from cache by given key.
+//Return data bytes and any error raised.
+func (c *Cache) GetBytesValue(key string) ([]byte, error) {
+	var data []byte
+	var ok bool
+	err := c.DB.View(func(txn *badger.Txn) error {
+		var err error
+		data, ok, err = get(txn, key)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, cache.ErrNotFound
+	}
+	return data, nil
+}
+
+//MGetBytesValue get multiple bytes data from cache by given keys.
+//Return data bytes map and any error if raised.
+func (c *Cache) MGetBytesValue(keys ...string) (map[string][]byte, error) {
+	var result = make(map[string][]byte, len(keys))
+	err := c.DB.View(func(txn *badger.Txn) error {
+		for _, k := range keys {
+			data, ok, err := get(txn, k)
+			if err != nil {
+				return err
+			}
+			if ok {
+				result[k] = data
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+//MSetBytesValue set multiple bytes data to cache with given key-value map.
+//Data is written by write batch,so large maps are split into multiple transactions.
+//Return  any error if raised.
+func (c *Cache) MSetBytesValue(data map[string][]byte, ttl time.Duration) error {
+	wb := c.DB.NewWriteBatch()
+	defer wb.Cancel()
+	for k := range data {
+		e := badger.NewEntry([]byte(k), data[k])
+		if ttl > 0 {
+			e = e.WithTTL(ttl)
+		}
+		err := wb.SetEntry(e)
+		if err != nil {
+			return err
+		}
+	}
+	return wb.Flush()
+}
+
+//Capabilities return capabilities supported by cache.
+func (c *Cache) Capabilities() cache.Capabilities {
+	return cache.CapabilityMGet | cache.CapabilityCounter | cache.CapabilityFlush | cache.CapabilityTTL | cache.CapabilityNX | cache.CapabilityCAS | cache.CapabilityIteration
+}
+
+//Keys list keys with given prefix from given cursor.
+//Keys are returned in lexical order,and cursor is the last key returned.
+//Return keys,next cursor and any error raised.
+//Empty next cursor means iteration finished.
+func (c *Cache) Keys(prefix string, cursor string, count int) ([]string, string, error) {
+	keys := []string{}
+	var next string
+	err := c.DB.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		opts.Prefix = []byte(prefix)
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		start := prefix
+		if cursor > prefix {
+			start = cursor
+		}
+		for it.Seek([]byte(start)); it.ValidForPrefix(opts.Prefix); it.Next() {
+			k := string(it.Item().Key())
+			if k <= cursor {
+				continue
+			}
+			if count > 0 && len(keys) == count {
+				next = keys[count-1]
+				return nil
+			}
+			keys = append(keys, k)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return keys, next, nil
+}
+
+//Flush Delete all data in cache.
+//Return any error if raised
+func (c *Cache) Flush() error {
+	return c.DB.DropAll()
+}
+
+//Close Close cache.
+//Return any error if raised
+func (c *Cache) Close() error {
+	close(c.quit)
+	return c.DB.Close()
+}
+
+//Del Delete data in cache by given key.
+//Return any error raised.
+func (c *Cache) Del(key string) error {
+	return c.update(func(txn *badger.Txn) error {
+		return txn.Delete([]byte(key))
+	})
+}
+
+//IncrCounter Increase int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Return int data value and any error raised.
+func (c *Cache) IncrCounter(key string, increment int64, ttl time.Duration) (int64, error) {
+	var v int64
+	err := c.update(func(txn *badger.Txn) error {
+		v = 0
+		data, ok, err := get(txn, key)
+		if err != nil {
+			return err
+		}
+		if ok {
+			current, err := CounterEncoding.DecodeCounter(data)
+			if err == nil {
+				v = current
+			}
+		}
+		v = v + increment
+		return set(txn, key, CounterEncoding.EncodeCounter(v), ttl)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return v, nil
+}
+
+//SetCounter Set int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Return any error raised.
+func (c *Cache) SetCounter(key string, v int64, ttl time.Duration) error {
+	return c.SetBytesValue(key, CounterEncoding.EncodeCounter(v), ttl)
+}
+
+//GetCounter Get int val from cache by given key.Count cache and data cache are in two independent namespace.
+//Return int data value and any error raised.
+func (c *Cache) GetCounter(key string) (int64, error) {
+	data, err := c.GetBytesValue(key)
+	if err != nil {
+		return 0, err
+	}
+	v, err := CounterEncoding.DecodeCounter(data)
+	if err != nil {
+		return 0, cache.ErrNotFound
+	}
+	return v, nil
+}
+
+//DelCounter Delete int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Return any error raised.
+func (c *Cache) DelCounter(key string) error {
+	return c.Del(key)
+}
+
+//Expire set cache value expire duration by given key and ttl
+func (c *Cache) Expire(key string, ttl time.Duration) error {
+	var found bool
+	err := c.update(func(txn *badger.Txn) error {
+		data, ok, err := get(txn, key)
+		found = ok
+		if err != nil || !ok {
+			return err
+		}
+		return set(txn, key, data, ttl)
+	})
+	if err != nil {
+		return err
+	}
+	if !found {
+		return cache.ErrNotFound
+	}
+	return nil
+}
+
+//ExpireCounter set cache counter  expire duration by given key and ttl
+func (c *Cache) ExpireCounter(key string, ttl time.Duration) error {
+	return c.Expire(key, ttl)
+}
+
+//GetTTL get remaining ttl of given key.
+//Badger stores expiry in seconds,so ttl is rounded to second.
+//Return ttl and any error raised.
+//Zero ttl means entry never expires.
+func (c *Cache) GetTTL(key string) (time.Duration, error) {
+	var expiresAt uint64
+	err := c.DB.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(key))
+		if err != nil {
+			return err
+		}
+		expiresAt = item.ExpiresAt()
+		return nil
+	})
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return 0, cache.ErrNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	if expiresAt == 0 {
+		return 0, nil
+	}
+	ttl := time.Until(time.Unix(int64(expiresAt), 0))
+	if ttl <= 0 {
+		return 0, cache.ErrNotFound
+	}
+	return ttl, nil
+}
+
+//Config Cache driver config.
+type Config struct {
+	//Path database directory path.
+	//Ignored in memory mode.
+	Path string
+	//InMemory whether store all data in memory without persistence.
+	InMemory bool
+	//SyncWrites whether sync writes to disk before transaction committed.
+	SyncWrites bool
+	//GCPeriodInSecond period of value log gc.Default value is 5 minute.
+	GCPeriodInSecond int64
+	//GCDiscardRatio value log file is rewritten if discardable data ratio is greater than ratio.Default value is 0.5.
+	GCDiscardRatio float64
+}
+
+//Create create new cache driver.
+//Return driver created and any error if raised.
+func (config *Config) Create() (cache.Driver, error) {
+	var opts badger.Options
+	if config.InMemory {
+		opts = badger.DefaultOptions("").WithInMemory(true)
+	} else {
+		opts = badger.DefaultOptions(config.Path).WithSyncWrites(config.SyncWrites)
+	}
+	opts = opts.WithLogger(nil)
+	db, err := badger.Open(opts)
+	if err != nil {
+		return nil, err
+	}
+	c := &Cache{
+		DB:             db,
+		quit:           make(chan int),
+		gcDiscardRatio: config.GCDiscardRatio,
+	}
+	if c.gcDiscardRatio <= 0 || c.gcDiscardRatio >= 1 {
+		c.gcDiscardRatio = defaultGCDiscardRatio
+	}
+	period := time.Duration(config.GCPeriodInSecond) * time.Second
+	if period <= 0 {
+		period = defaultGCPeriod
+	}
+	c.ticker = time.NewTicker(period)
+	go func() {
+		for {
+			select {
+			case <-c.ticker.C:
+				if config.InMemory {
+					continue
+				}
+				err := c.gc()
+				if err != nil && c.gcErrHandler != nil {
+					c.gcErrHandler(err)
+				}
+			case <-c.quit:
+				c.ticker.Stop()
+				return
+			}
+		}
+	}()
+	return c, nil
+}
+
+func init() {
+	cache.Register("badgercache", func(loader func(interface{}) error) (cache.Driver, error) {
+		c := &Config{}
+		err := loader(c)
+		if err != nil {
+			return nil, err
+		}
+		return c.Create()
+	})
+}
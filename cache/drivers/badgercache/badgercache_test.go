@@ -0,0 +1,81 @@
+package badgercache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+func newTestCache(t *testing.T) *cache.Cache {
+	c := cache.New()
+	oc := cache.NewOptionConfig()
+	oc.Driver = "badgercache"
+	oc.TTL = 3600
+	oc.Config = func(v interface{}) error {
+		v.(*Config).InMemory = true
+		return nil
+	}
+	oc.Marshaler = "json"
+	err := c.Init(oc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestBadgerCache(t *testing.T) {
+	c := newTestCache(t)
+	defer c.Close()
+	err := c.Set("test", "value", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result string
+	err = c.Get("test", &result)
+	if err != nil || result != "value" {
+		t.Fatal(result, err)
+	}
+	ttl, err := c.GetTTL("test")
+	if err != nil || ttl <= 0 || ttl > time.Hour {
+		t.Fatal(ttl, err)
+	}
+	err = c.Set("expired", "value", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := c.IncrCounter("counter", 2, time.Hour)
+	if err != nil || v != 2 {
+		t.Fatal(v, err)
+	}
+	v, err = c.IncrCounter("counter", 3, time.Hour)
+	if err != nil || v != 5 {
+		t.Fatal(v, err)
+	}
+	ok, err := c.SetIfNotExists("test", []byte("new"), time.Hour)
+	if ok || err != nil {
+		t.Fatal(ok, err)
+	}
+	time.Sleep(2 * time.Second)
+	err = c.Get("expired", &result)
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	keys, next, err := c.Driver.(*Cache).Keys("", "", 1)
+	if err != nil || len(keys) != 1 || next != keys[0] {
+		t.Fatal(keys, next, err)
+	}
+	keys, next, err = c.Driver.(*Cache).Keys("", next, 1)
+	if err != nil || len(keys) != 1 || next != "" {
+		t.Fatal(keys, next, err)
+	}
+	err = c.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.Get("test", &result)
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,111 @@
+package breakercache
+
+import (
+	"sync"
+	"time"
+)
+
+//Breaker states
+const (
+	//StateClosed operations are sent to wrapped cache.
+	StateClosed = "closed"
+	//StateOpen operations are bypassed.
+	StateOpen = "open"
+	//StateHalfOpen trial operation is sent to wrapped cache to check if it recovered.
+	StateHalfOpen = "halfopen"
+)
+
+//Breaker circuit breaker interface which decides whether operations should be sent to wrapped cache.
+type Breaker interface {
+	//Allow return whether operation should be sent to wrapped cache.
+	Allow() bool
+	//Report report result of operation which was allowed.
+	//Failed is true if operation returned unexpected error.
+	Report(duration time.Duration, failed bool)
+	//State return current breaker state.
+	State() string
+}
+
+//ConsecutiveBreaker breaker which trips open after given count of consecutive failures or slow operations,
+//and allows a trial operation after cool down.
+type ConsecutiveBreaker struct {
+	//Threshold count of consecutive failures to trip open.
+	Threshold int
+	//SlowThreshold operations slower than which are treated as failures.
+	//Slow operations are not checked if not positive.
+	SlowThreshold time.Duration
+	//CoolDown duration breaker keeps open before trial operation.
+	CoolDown time.Duration
+	locker   sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+//NewConsecutiveBreaker create new consecutive breaker with given threshold and cool down.
+func NewConsecutiveBreaker(threshold int, cooldown time.Duration) *ConsecutiveBreaker {
+	return &ConsecutiveBreaker{
+		Threshold: threshold,
+		CoolDown:  cooldown,
+		state:     StateClosed,
+	}
+}
+
+//Allow return whether operation should be sent to wrapped cache.
+func (b *ConsecutiveBreaker) Allow() bool {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+	switch b.state {
+	case StateOpen:
+		if time.Now().Sub(b.openedAt) < b.CoolDown {
+			return false
+		}
+		b.state = StateHalfOpen
+		b.probing = true
+		return true
+	case StateHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	}
+	return true
+}
+
+//Report report result of operation which was allowed.
+func (b *ConsecutiveBreaker) Report(duration time.Duration, failed bool) {
+	if b.SlowThreshold > 0 && duration > b.SlowThreshold {
+		failed = true
+	}
+	b.locker.Lock()
+	defer b.locker.Unlock()
+	if b.state == StateHalfOpen {
+		b.probing = false
+		if failed {
+			b.state = StateOpen
+			b.openedAt = time.Now()
+		} else {
+			b.state = StateClosed
+			b.failures = 0
+		}
+		return
+	}
+	if !failed {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.state == StateClosed && b.failures >= b.Threshold {
+		b.state = StateOpen
+		b.openedAt = time.Now()
+	}
+}
+
+//State return current breaker state.
+func (b *ConsecutiveBreaker) State() string {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+	return b.state
+}
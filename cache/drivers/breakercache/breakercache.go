@@ -0,0 +1,277 @@
+//Package breakercache provides a cache driver decorator which stops sending operations to flaky wrapped cache.
+//Reads are treated as misses and writes are dropped while circuit breaker is open.
+package breakercache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+//ErrCircuitOpen error raised when operation which can not be bypassed is called while circuit breaker is open.
+var ErrCircuitOpen = errors.New("cache circuit breaker is open")
+
+//DefaultThreshold default count of consecutive failures to trip open.
+var DefaultThreshold = 5
+
+//DefaultCoolDown default duration breaker keeps open.
+var DefaultCoolDown = 10 * time.Second
+
+//Cache The circuit breaker cache driver.
+type Cache struct {
+	cache.DriverUtil
+	//Cache wrapped cache.
+	Cache *cache.Cache
+	//Breaker circuit breaker.
+	Breaker Breaker
+}
+
+//IsFailure return whether given error returned by wrapped cache should be treated as failure.
+func IsFailure(err error) bool {
+	//Cache errors like ErrNotFound are results rather than failures,even wrapped with key context.
+	return cache.IsRetryableError(err)
+}
+
+func (c *Cache) report(start time.Time, err error) {
+	c.Breaker.Report(time.Now().Sub(start), IsFailure(err))
+}
+
+func (c *Cache) write(f func() error) error {
+	if !c.Breaker.Allow() {
+		return nil
+	}
+	start := time.Now()
+	err := f()
+	c.report(start, err)
+	return err
+}
+
+//SetBytesValue Set bytes data to cache by given key.
+//Data will be dropped if breaker is open.
+//Return any error raised.
+func (c *Cache) SetBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	return c.write(func() error {
+		return c.Cache.SetBytesValue(key, bytes, ttl)
+	})
+}
+
+//UpdateBytesValue Update bytes data to cache by given key only if the cache exist.
+//Data will be dropped if breaker is open.
+//Return any error raised.
+func (c *Cache) UpdateBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	return c.write(func() error {
+		return c.Cache.UpdateBytesValue(key, bytes, ttl)
+	})
+}
+
+//GetBytesValue Get bytes data from cache by given key.
+//Return ErrNotFound if breaker is open.
+//Return data bytes and any error raised.
+func (c *Cache) GetBytesValue(key string) ([]byte, error) {
+	if !c.Breaker.Allow() {
+		return nil, cache.ErrNotFound
+	}
+	start := time.Now()
+	data, err := c.Cache.GetBytesValue(key)
+	c.report(start, err)
+	return data, err
+}
+
+//MGetBytesValue get multiple bytes data from cache by given keys.
+//Return empty map if breaker is open.
+//Return data bytes map and any error if raised.
+func (c *Cache) MGetBytesValue(keys ...string) (map[string][]byte, error) {
+	if !c.Breaker.Allow() {
+		return map[string][]byte{}, nil
+	}
+	start := time.Now()
+	data, err := c.Cache.MGetBytesValue(keys...)
+	c.report(start, err)
+	return data, err
+}
+
+//MSetBytesValue set multiple bytes data to cache with given key-value map.
+//Data will be dropped if breaker is open.
+//Return  any error if raised.
+func (c *Cache) MSetBytesValue(data map[string][]byte, ttl time.Duration) error {
+	return c.write(func() error {
+		return c.Cache.MSetBytesValue(data, ttl)
+	})
+}
+
+//Del Delete data in cache by given key.
+//Operation will be dropped if breaker is open.
+//Return any error raised.
+func (c *Cache) Del(key string) error {
+	return c.write(func() error {
+		return c.Cache.Del(key)
+	})
+}
+
+//Expire set cache value expire duration by given key and ttl
+//Operation will be dropped if breaker is open.
+//Return any error raised.
+func (c *Cache) Expire(key string, ttl time.Duration) error {
+	return c.write(func() error {
+		return c.Cache.Expire(key, ttl)
+	})
+}
+
+//SetCounter Set int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Operation will be dropped if breaker is open.
+//Return any error raised.
+func (c *Cache) SetCounter(key string, v int64, ttl time.Duration) error {
+	return c.write(func() error {
+		return c.Cache.SetCounter(key, v, ttl)
+	})
+}
+
+//GetCounter Get int val from cache by given key.Count cache and data cache are in two independent namespace.
+//Return ErrNotFound if breaker is open.
+//Return int data value and any error raised.
+func (c *Cache) GetCounter(key string) (int64, error) {
+	if !c.Breaker.Allow() {
+		return 0, cache.ErrNotFound
+	}
+	start := time.Now()
+	v, err := c.Cache.GetCounter(key)
+	c.report(start, err)
+	return v, err
+}
+
+//IncrCounter Increase int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Return ErrCircuitOpen if breaker is open.
+//Return int data value and any error raised.
+func (c *Cache) IncrCounter(key string, increment int64, ttl time.Duration) (int64, error) {
+	if !c.Breaker.Allow() {
+		return 0, ErrCircuitOpen
+	}
+	start := time.Now()
+	v, err := c.Cache.IncrCounter(key, increment, ttl)
+	c.report(start, err)
+	return v, err
+}
+
+//ExpireCounter set cache counter expire duration by given key and ttl
+//Operation will be dropped if breaker is open.
+//Return any error raised.
+func (c *Cache) ExpireCounter(key string, ttl time.Duration) error {
+	return c.write(func() error {
+		return c.Cache.ExpireCounter(key, ttl)
+	})
+}
+
+//DelCounter Delete int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Operation will be dropped if breaker is open.
+//Return any error raised.
+func (c *Cache) DelCounter(key string) error {
+	return c.write(func() error {
+		return c.Cache.DelCounter(key)
+	})
+}
+
+//SetGCErrHandler Set callback to handler error raised when gc.
+func (c *Cache) SetGCErrHandler(f func(err error)) {
+	c.Cache.SetGCErrHandler(f)
+}
+
+//Ping check if wrapped cache is reachable before context done.
+//Breaker state is not changed by ping.
+//Return any error raised.
+func (c *Cache) Ping(ctx context.Context) error {
+	return c.Cache.Ping(ctx)
+}
+
+//Capabilities return capabilities supported by wrapped cache.
+func (c *Cache) Capabilities() cache.Capabilities {
+	return c.Cache.Capabilities() & (cache.CapabilityMGet | cache.CapabilityCounter | cache.CapabilityFlush | cache.CapabilityPing)
+}
+
+//Close Close wrapped cache.
+//Return any error if raised
+func (c *Cache) Close() error {
+	return c.Cache.Close()
+}
+
+//Flush Delete all data in wrapped cache.
+//Return any error if raised
+func (c *Cache) Flush() error {
+	return c.Cache.Flush()
+}
+
+//Config circuit breaker cache driver config.
+type Config struct {
+	//Cache wrapped cache config.
+	Cache *cache.OptionConfig
+	//Threshold count of consecutive failures to trip open.
+	//DefaultThreshold will be used if not positive.
+	Threshold int
+	//SlowThresholdInMilliseconds operations slower than which are treated as failures.
+	//Slow operations are not checked if not positive.
+	SlowThresholdInMilliseconds int64
+	//CoolDownInSecond duration in second breaker keeps open.
+	//DefaultCoolDown will be used if not positive.
+	CoolDownInSecond int64
+	//SLOInMilliseconds max percentile latency in milliseconds.
+	//Latency breaker will be used instead of consecutive breaker if positive.
+	SLOInMilliseconds int64
+	//Percentile percentile checked by latency breaker,from 0 to 1.
+	//DefaultPercentile will be used if not positive.
+	Percentile float64
+	//WindowSize count of latest operations tracked by latency breaker.
+	//DefaultWindowSize will be used if not positive.
+	WindowSize int
+}
+
+func (c *Config) latencyBreaker(cooldown time.Duration) *LatencyBreaker {
+	b := NewLatencyBreaker(time.Duration(c.SLOInMilliseconds)*time.Millisecond, cooldown)
+	if c.Percentile > 0 {
+		b.Percentile = c.Percentile
+	}
+	if c.WindowSize > 0 {
+		b.WindowSize = c.WindowSize
+	}
+	return b
+}
+
+//Create create new cache driver.
+//Return driver created and any error if raised.
+func (c *Config) Create() (cache.Driver, error) {
+	wrapped, err := cache.NewSubCache(c.Cache)
+	if err != nil {
+		return nil, err
+	}
+	threshold := c.Threshold
+	if threshold <= 0 {
+		threshold = DefaultThreshold
+	}
+	cooldown := time.Duration(c.CoolDownInSecond) * time.Second
+	if cooldown <= 0 {
+		cooldown = DefaultCoolDown
+	}
+	if c.SLOInMilliseconds > 0 {
+		return &Cache{
+			Cache:   wrapped,
+			Breaker: c.latencyBreaker(cooldown),
+		}, nil
+	}
+	b := NewConsecutiveBreaker(threshold, cooldown)
+	b.SlowThreshold = time.Duration(c.SlowThresholdInMilliseconds) * time.Millisecond
+	return &Cache{
+		Cache:   wrapped,
+		Breaker: b,
+	}, nil
+}
+
+func init() {
+	cache.Register("breakercache", func(loader func(interface{}) error) (cache.Driver, error) {
+		c := &Config{}
+		err := loader(c)
+		if err != nil {
+			return nil, err
+		}
+		return c.Create()
+	})
+}
@@ -0,0 +1,132 @@
+package breakercache
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+	_ "github.com/herb-go/deprecated/cache/drivers/syncmapcache"
+)
+
+var errTestDown = errors.New("test cache down")
+
+type flakyDriver struct {
+	cache.Driver
+	down  bool
+	calls int
+}
+
+func (d *flakyDriver) GetBytesValue(key string) ([]byte, error) {
+	d.calls++
+	if d.down {
+		return nil, errTestDown
+	}
+	return d.Driver.GetBytesValue(key)
+}
+
+func (d *flakyDriver) SetBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	d.calls++
+	if d.down {
+		return errTestDown
+	}
+	return d.Driver.SetBytesValue(key, bytes, ttl)
+}
+
+func newTestCache() (*cache.Cache, *flakyDriver) {
+	buf := bytes.NewBufferString(`{"Size":10000000}`)
+	oc := cache.NewOptionConfig()
+	oc.Driver = "syncmapcache"
+	oc.TTL = 3600
+	oc.Marshaler = "json"
+	oc.Config = json.NewDecoder(buf).Decode
+	wrapped, err := cache.NewSubCache(oc)
+	if err != nil {
+		panic(err)
+	}
+	flaky := &flakyDriver{Driver: wrapped.Driver}
+	wrapped.Driver = flaky
+	d := &Cache{
+		Cache:   wrapped,
+		Breaker: NewConsecutiveBreaker(2, 50*time.Millisecond),
+	}
+	d.SetUtil(wrapped.Util())
+	c := cache.New()
+	c.Driver = d
+	c.TTL = time.Hour
+	return c, flaky
+}
+
+func TestBreaker(t *testing.T) {
+	c, flaky := newTestCache()
+	d := c.Driver.(*Cache)
+	err := c.SetBytesValue("test", []byte("value"), cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.GetBytesValue("notexists")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	flaky.down = true
+	for i := 0; i < 2; i++ {
+		_, err = c.GetBytesValue("test")
+		if !errors.Is(err, errTestDown) {
+			t.Fatal(err)
+		}
+	}
+	if d.Breaker.State() != StateOpen {
+		t.Fatal(d.Breaker.State())
+	}
+	calls := flaky.calls
+	_, err = c.GetBytesValue("test")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	err = c.SetBytesValue("test", []byte("value"), cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.IncrCounter("counter", 1, cache.DefaultTTL)
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatal(err)
+	}
+	if flaky.calls != calls {
+		t.Fatal(flaky.calls, calls)
+	}
+	time.Sleep(60 * time.Millisecond)
+	_, err = c.GetBytesValue("test")
+	if !errors.Is(err, errTestDown) {
+		t.Fatal(err)
+	}
+	if d.Breaker.State() != StateOpen {
+		t.Fatal(d.Breaker.State())
+	}
+	flaky.down = false
+	time.Sleep(60 * time.Millisecond)
+	bs, err := c.GetBytesValue("test")
+	if err != nil || string(bs) != "value" {
+		t.Fatal(string(bs), err)
+	}
+	if d.Breaker.State() != StateClosed {
+		t.Fatal(d.Breaker.State())
+	}
+}
+
+func TestSlowThreshold(t *testing.T) {
+	b := NewConsecutiveBreaker(1, time.Hour)
+	b.SlowThreshold = time.Millisecond
+	if !b.Allow() {
+		t.Fatal(b.State())
+	}
+	b.Report(time.Microsecond, false)
+	if b.State() != StateClosed {
+		t.Fatal(b.State())
+	}
+	b.Report(time.Second, false)
+	if b.State() != StateOpen || b.Allow() {
+		t.Fatal(b.State())
+	}
+}
@@ -0,0 +1,164 @@
+package breakercache
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+//DefaultPercentile default percentile checked by latency breaker.
+var DefaultPercentile = 0.99
+
+//DefaultWindowSize default count of latest operations tracked by latency breaker.
+var DefaultWindowSize = 1000
+
+//DefaultRampStep default fraction of traffic restored every ramp interval.
+var DefaultRampStep = 0.1
+
+//DefaultRampInterval default interval between traffic restoring steps.
+var DefaultRampInterval = time.Second
+
+//LatencyBreaker breaker which tracks moving percentile latency of latest operations,
+//and trips open when it exceeds SLO.
+//After cool down traffic will be restored gradually while latency stays within SLO.
+type LatencyBreaker struct {
+	//SLO max percentile latency allowed.
+	SLO time.Duration
+	//Percentile percentile checked,from 0 to 1.
+	Percentile float64
+	//WindowSize count of latest operations tracked.
+	WindowSize int
+	//CoolDown duration breaker keeps open before restoring traffic.
+	CoolDown time.Duration
+	//RampStep fraction of traffic restored every ramp interval.
+	RampStep float64
+	//RampInterval interval between traffic restoring steps.
+	RampInterval time.Duration
+	locker       sync.Mutex
+	state        string
+	samples      []time.Duration
+	index        int
+	count        int
+	ratio        float64
+	changedAt    time.Time
+}
+
+//NewLatencyBreaker create new latency breaker with given slo and cool down.
+func NewLatencyBreaker(slo time.Duration, cooldown time.Duration) *LatencyBreaker {
+	return &LatencyBreaker{
+		SLO:          slo,
+		Percentile:   DefaultPercentile,
+		WindowSize:   DefaultWindowSize,
+		CoolDown:     cooldown,
+		RampStep:     DefaultRampStep,
+		RampInterval: DefaultRampInterval,
+		state:        StateClosed,
+	}
+}
+
+func (b *LatencyBreaker) reset() {
+	b.samples = make([]time.Duration, b.WindowSize)
+	b.index = 0
+	b.count = 0
+}
+
+func (b *LatencyBreaker) percentile() time.Duration {
+	n := b.count
+	if n > len(b.samples) {
+		n = len(b.samples)
+	}
+	sorted := make([]time.Duration, n)
+	copy(sorted, b.samples[:n])
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	i := int(float64(n)*b.Percentile+0.5) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= n {
+		i = n - 1
+	}
+	return sorted[i]
+}
+
+func (b *LatencyBreaker) open() {
+	b.state = StateOpen
+	b.changedAt = time.Now()
+	b.reset()
+}
+
+//Allow return whether operation should be sent to wrapped cache.
+func (b *LatencyBreaker) Allow() bool {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+	switch b.state {
+	case StateOpen:
+		if time.Now().Sub(b.changedAt) < b.CoolDown {
+			return false
+		}
+		b.state = StateHalfOpen
+		b.ratio = b.RampStep
+		b.changedAt = time.Now()
+	case StateHalfOpen:
+		if time.Now().Sub(b.changedAt) >= b.RampInterval {
+			b.ratio = b.ratio + b.RampStep
+			b.changedAt = time.Now()
+			if b.ratio >= 1 {
+				b.state = StateClosed
+				return true
+			}
+		}
+	default:
+		return true
+	}
+	return rand.Float64() < b.ratio
+}
+
+//Report report result of operation which was allowed.
+//Failed operations are tracked as operations which exceed SLO.
+func (b *LatencyBreaker) Report(duration time.Duration, failed bool) {
+	if failed && duration <= b.SLO {
+		duration = b.SLO + 1
+	}
+	b.locker.Lock()
+	defer b.locker.Unlock()
+	if b.state == StateOpen {
+		return
+	}
+	if len(b.samples) != b.WindowSize {
+		b.reset()
+	}
+	b.samples[b.index] = duration
+	b.index = (b.index + 1) % b.WindowSize
+	b.count++
+	step := b.WindowSize / 10
+	if step < 1 {
+		step = 1
+	}
+	if b.count%step != 0 {
+		return
+	}
+	if b.percentile() > b.SLO {
+		b.open()
+	}
+}
+
+//State return current breaker state.
+func (b *LatencyBreaker) State() string {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+	return b.state
+}
+
+//Ratio return fraction of traffic allowed.
+func (b *LatencyBreaker) Ratio() float64 {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+	switch b.state {
+	case StateOpen:
+		return 0
+	case StateHalfOpen:
+		return b.ratio
+	}
+	return 1
+}
@@ -0,0 +1,55 @@
+package breakercache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyBreaker(t *testing.T) {
+	b := NewLatencyBreaker(10*time.Millisecond, 20*time.Millisecond)
+	b.WindowSize = 100
+	b.RampStep = 0.5
+	b.RampInterval = 20 * time.Millisecond
+	for i := 0; i < 100; i++ {
+		if !b.Allow() {
+			t.Fatal(b.State())
+		}
+		b.Report(time.Millisecond, false)
+	}
+	if b.State() != StateClosed || b.Ratio() != 1 {
+		t.Fatal(b.State(), b.Ratio())
+	}
+	b.Report(time.Second, false)
+	for i := 0; i < 9; i++ {
+		b.Report(time.Millisecond, false)
+	}
+	if b.State() != StateClosed {
+		t.Fatal(b.State())
+	}
+	for i := 0; i < 10; i++ {
+		b.Report(time.Millisecond, true)
+	}
+	if b.State() != StateOpen || b.Allow() {
+		t.Fatal(b.State())
+	}
+	time.Sleep(30 * time.Millisecond)
+	b.Allow()
+	if b.State() != StateHalfOpen || b.Ratio() != 0.5 {
+		t.Fatal(b.State(), b.Ratio())
+	}
+	time.Sleep(30 * time.Millisecond)
+	if !b.Allow() || b.State() != StateClosed {
+		t.Fatal(b.State(), b.Ratio())
+	}
+}
+
+func TestLatencyConfig(t *testing.T) {
+	c := &Config{
+		SLOInMilliseconds: 50,
+		Percentile:        0.95,
+	}
+	b := c.latencyBreaker(time.Second)
+	if b.SLO != 50*time.Millisecond || b.Percentile != 0.95 || b.WindowSize != DefaultWindowSize {
+		t.Fatal(b)
+	}
+}
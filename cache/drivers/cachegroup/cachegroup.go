@@ -3,7 +3,11 @@
 package cachegroup
 
 import (
-	"encoding/binary"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/herb-go/deprecated/cache"
@@ -16,37 +20,75 @@ const modeUpdate = 1
 type Cache struct {
 	cache.DriverUtil
 	SubCaches []*cache.Cache
+	//ReadYourWrites read-your-writes duration.
+	//If positive,keys written by this process will be read from last sub cache in duration,
+	//so that stale values in other sub caches will be bypassed.
+	ReadYourWrites time.Duration
+	//MaxConcurrentWrites max count of sub caches written concurrently when writing entry to leading sub caches.
+	//DefaultMaxConcurrentWrites will be used if not positive.
+	MaxConcurrentWrites int
+	//Promotion policy deciding how entries hit in lower sub cache are written back to upper sub caches.
+	//Default value is PromoteSync.
+	Promotion PromotionPolicy
+	//PromotionThreshold count of lower sub cache hits before entry promoted by PromoteIfHot policy.
+	//DefaultPromotionThreshold will be used if not positive.
+	PromotionThreshold int
+	//CounterMode mode deciding which sub caches counters are stored in.
+	//Default value is CounterLast.
+	CounterMode CounterMode
+	//CounterSnapshotTTL ttl of counter snapshots written to upper sub caches in CounterLayered mode.
+	//Snapshots are disabled if not positive.
+	CounterSnapshotTTL time.Duration
+	//Layers options of sub caches by index.
+	//Default options will be used if options of sub cache is not set.
+	Layers          []*LayerOptions
+	writeMarkers    writeMarkers
+	layerCounters   layerCounters
+	hotCounter      hotCounter
+	asyncPromotions int32
 }
-type entry []byte
 
-//IncrCounter Increase int val in cache by given key.Count cache and data cache are in two independent namespace.
-//Return int data value and any error raised.
-func (e *entry) Set(bytes []byte, ttl time.Duration) int64 {
-	var expired int64
-	var buf = make([]byte, 8)
-	*e = make([]byte, len(bytes)+8)
-	copy((*e)[8:], bytes)
-	expired = time.Now().Add(ttl).Unix()
-	binary.BigEndian.PutUint64(buf, uint64(expired))
-	copy((*e)[0:8], buf)
-	return expired
+//DefaultMaxConcurrentWrites default max count of sub caches written concurrently.
+const DefaultMaxConcurrentWrites = 4
+
+//LayerError error raised by sub cache.
+type LayerError struct {
+	//Layer index of sub cache.
+	Layer int
+	//Err error raised.
+	Err error
 }
-func (e *entry) Get() ([]byte, int64, error) {
-	var b = make([]byte, len(*e))
-	copy(b, *e)
-	var buf []byte
-	var expired int64
-	if len(b) < 8 {
-		return buf, expired, cache.ErrNotFound
-	}
-	expired = int64(binary.BigEndian.Uint64(b[0:8]))
-	if expired < time.Now().Unix() {
-		return buf, expired, cache.ErrNotFound
+
+//Error return error message.
+func (e *LayerError) Error() string {
+	return "cachegroup: sub cache " + strconv.Itoa(e.Layer) + ": " + e.Err.Error()
+}
+
+//Unwrap return wrapped error.
+func (e *LayerError) Unwrap() error {
+	return e.Err
+}
+
+//Errors errors raised by sub caches in layer order.
+//Errors matches any wrapped error with errors.Is.
+type Errors []*LayerError
+
+//Error return error message.
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for k := range e {
+		msgs[k] = e[k].Error()
 	}
-	buf = make([]byte, len(b)-8)
-	copy(buf, b[8:])
-	return buf, expired, nil
+	return strings.Join(msgs, "; ")
+}
 
+//Unwrap return wrapped errors.
+func (e Errors) Unwrap() []error {
+	result := make([]error, len(e))
+	for k := range e {
+		result[k] = e[k]
+	}
+	return result
 }
 
 //Expire set cache value expire duration by given key and ttl
@@ -58,12 +100,21 @@ func (c *Cache) Expire(key string, ttl time.Duration) error {
 	err = c.SetBytesValue(key, b, ttl)
 	return err
 }
+
+//setBytesCaches write entry to given leading sub caches concurrently,at most MaxConcurrentWrites sub caches at a time.
+//Index of sub cache in given caches is reported as layer in errors.
+//Return Errors of sub caches failed,or nil if all writes succeeded.
 func (c *Cache) setBytesCaches(key string, caches []*cache.Cache, bytes []byte, expired int64, mode int) error {
-	var finalErr error
-	var err error
 	var t time.Duration
 	t = time.Unix(expired, 0).Sub(time.Now())
-	for _, v := range caches {
+	workers := c.MaxConcurrentWrites
+	if workers <= 0 {
+		workers = DefaultMaxConcurrentWrites
+	}
+	sem := make(chan struct{}, workers)
+	errs := make([]error, len(caches))
+	wg := sync.WaitGroup{}
+	for k, v := range caches {
 		var ttl time.Duration
 		if t < 0 {
 			if v.TTL < 0 {
@@ -82,16 +133,36 @@ func (c *Cache) setBytesCaches(key string, caches []*cache.Cache, bytes []byte,
 				}
 			}
 		}
-		if mode == modeSet {
-			err = v.SetBytesValue(key, bytes, ttl)
-		} else {
-			err = v.UpdateBytesValue(key, bytes, ttl)
-		}
-		if err != nil && err != cache.ErrNotCacheable && err != cache.ErrEntryTooLarge {
-			finalErr = err
+		ttl = c.capTTL(k, ttl)
+		min := c.layerOptions(k).MinTTL
+		skip := min > 0 && ttl >= 0 && ttl < min
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(k int, v *cache.Cache, ttl time.Duration) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if skip {
+				errs[k] = v.Del(key)
+			} else if mode == modeSet {
+				errs[k] = v.SetBytesValue(key, bytes, ttl)
+			} else {
+				errs[k] = v.UpdateBytesValue(key, bytes, ttl)
+			}
+		}(k, v, ttl)
+	}
+	wg.Wait()
+	var result Errors
+	for k, err := range errs {
+		if err != nil && !errors.Is(err, cache.ErrNotCacheable) && !errors.Is(err, cache.ErrEntryTooLarge) {
+			result = append(result, &LayerError{Layer: k, Err: err})
 		}
 	}
-	return finalErr
+	if len(result) == 0 {
+		return nil
+	}
+	return result
 }
 
 //SetBytesValue Set bytes data to cache by given key.
@@ -100,8 +171,10 @@ func (c *Cache) SetBytesValue(key string, bytes []byte, ttl time.Duration) error
 	var err error
 	var e entry
 	expired := e.Set(bytes, ttl)
-	err = c.SubCaches[len(c.SubCaches)-1].SetBytesValue(key, []byte(e), ttl)
-	if err != cache.ErrNotCacheable && err != cache.ErrEntryTooLarge && err != nil {
+	c.markWritten(key)
+	last := len(c.SubCaches) - 1
+	err = c.SubCaches[last].SetBytesValue(key, []byte(e), c.capTTL(last, ttl))
+	if !errors.Is(err, cache.ErrNotCacheable) && !errors.Is(err, cache.ErrEntryTooLarge) && err != nil {
 		return err
 	}
 	err = c.setBytesCaches(key, c.SubCaches[0:len(c.SubCaches)-1], []byte(e), expired, modeSet)
@@ -114,8 +187,10 @@ func (c *Cache) UpdateBytesValue(key string, bytes []byte, ttl time.Duration) er
 	var err error
 	var e entry
 	expired := e.Set(bytes, ttl)
-	err = c.SubCaches[len(c.SubCaches)-1].UpdateBytesValue(key, []byte(e), ttl)
-	if err != cache.ErrNotCacheable && err != cache.ErrEntryTooLarge && err != nil {
+	c.markWritten(key)
+	last := len(c.SubCaches) - 1
+	err = c.SubCaches[last].UpdateBytesValue(key, []byte(e), c.capTTL(last, ttl))
+	if !errors.Is(err, cache.ErrNotCacheable) && !errors.Is(err, cache.ErrEntryTooLarge) && err != nil {
 		return err
 	}
 	err = c.setBytesCaches(key, c.SubCaches[0:len(c.SubCaches)-1], []byte(e), expired, modeUpdate)
@@ -128,85 +203,192 @@ func (c *Cache) GetBytesValue(key string) ([]byte, error) {
 	var err error
 	var bytes []byte
 	var buf []byte
-	expiredCache := []*cache.Cache{}
-	for _, v := range c.SubCaches {
-		bytes, err = v.GetBytesValue(key)
-		if err == cache.ErrNotFound {
-			expiredCache = append(expiredCache, v)
-		} else {
-			break
+	if c.writtenRecently(key) {
+		last := len(c.SubCaches) - 1
+		start := time.Now()
+		bytes, err = c.SubCaches[last].GetBytesValue(key)
+		if err == nil {
+			e := entry(bytes)
+			buf, _, err = e.Get()
+		}
+		c.observeGet(last, start, err)
+		if errors.Is(err, cache.ErrNotFound) {
+			return nil, cache.ErrNotFound
 		}
-	}
-	if err != nil {
 		return buf, err
 	}
-	e := entry(bytes)
-
-	buf, expired, err := e.Get()
-	if err != nil {
-		return buf, err
+	for k, v := range c.SubCaches {
+		start := time.Now()
+		bytes, err = v.GetBytesValue(key)
+		var expired int64
+		if err == nil {
+			e := entry(bytes)
+			buf, expired, err = e.Get()
+		}
+		c.observeGet(k, start, err)
+		if errors.Is(err, errKnownMissing) {
+			return nil, cache.ErrNotFound
+		}
+		if errors.Is(err, cache.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return buf, err
+		}
+		c.promote(key, k, bytes, expired)
+		return buf, nil
 	}
-	c.setBytesCaches(key, expiredCache, []byte(e), expired, modeSet)
-	return buf, nil
+	c.propagateMissing(key)
+	return nil, cache.ErrNotFound
 }
 
 //MGetBytesValue get multiple bytes data from cache by given keys.
+//Sub caches are looked up in order,and only keys missed in upper sub cache are looked up in lower sub cache.
+//Keys written recently in read-your-writes mode are only looked up in last sub cache.
+//Entries hit in lower sub cache are written back to upper sub caches by promotion policy.
 //Return data bytes map and any error if raised.
 func (c *Cache) MGetBytesValue(keys ...string) (map[string][]byte, error) {
-	emap, err := c.SubCaches[len(c.SubCaches)-1].MGetBytesValue(keys...)
-	if err != nil {
-		return nil, err
-	}
-	var data = make(map[string][]byte, len(emap))
-	for k := range emap {
-		if emap[k] != nil {
-			var e = entry(emap[k])
-			buf, _, err := e.Get()
-			if err == cache.ErrNotFound {
-				data[k] = nil
-			} else if err != nil {
+	last := len(c.SubCaches) - 1
+	var data = make(map[string][]byte, len(keys))
+	pending := make([]string, 0, len(keys))
+	recent := []string{}
+	recentSet := map[string]bool{}
+	for _, key := range keys {
+		if c.writtenRecently(key) {
+			recent = append(recent, key)
+			recentSet[key] = true
+		} else {
+			pending = append(pending, key)
+		}
+	}
+	for k, v := range c.SubCaches {
+		if k == last {
+			pending = append(pending, recent...)
+		}
+		if len(pending) == 0 {
+			continue
+		}
+		start := time.Now()
+		emap, err := v.MGetBytesValue(pending...)
+		if err != nil {
+			c.observeLookup(k, start, 0, 0, len(pending))
+			return nil, err
+		}
+		missed := pending[:0:0]
+		hit := 0
+		for _, key := range pending {
+			if emap[key] == nil {
+				missed = append(missed, key)
+				continue
+			}
+			var e = entry(emap[key])
+			buf, expired, err := e.Get()
+			if errors.Is(err, errKnownMissing) {
+				continue
+			}
+			if errors.Is(err, cache.ErrNotFound) {
+				if errors.Is(err, ErrCorruptedEntry) {
+					c.observeCorrupted(k)
+				}
+				missed = append(missed, key)
+				continue
+			}
+			if err != nil {
 				return nil, err
-			} else {
-				data[k] = buf
+			}
+			data[key] = buf
+			hit++
+			if !recentSet[key] {
+				c.promote(key, k, emap[key], expired)
 			}
 		}
+		c.observeLookup(k, start, hit, len(pending)-hit, 0)
+		pending = missed
+	}
+	for _, key := range pending {
+		c.propagateMissing(key)
 	}
 	return data, nil
 }
 
 //MSetBytesValue set multiple bytes data to cache with given key-value map.
+//Only last sub cache is written,entries and known missing markers in upper sub caches are kept until expired.
 //Return  any error if raised.
 func (c *Cache) MSetBytesValue(data map[string][]byte, ttl time.Duration) error {
 
 	var emap = make(map[string][]byte, len(data))
 	for k := range data {
+		c.markWritten(k)
 		var e entry
 		e.Set(data[k], ttl)
 		emap[k] = []byte(e)
 	}
-	return c.SubCaches[len(c.SubCaches)-1].MSetBytesValue(emap, ttl)
+	last := len(c.SubCaches) - 1
+	return c.SubCaches[last].MSetBytesValue(emap, c.capTTL(last, ttl))
+}
+
+//GetTTL get remaining ttl of given key.
+//Return ttl and any error raised.
+func (c *Cache) GetTTL(key string) (time.Duration, error) {
+	var err error
+	var bytes []byte
+	subcaches := c.SubCaches
+	if c.writtenRecently(key) {
+		subcaches = subcaches[len(subcaches)-1:]
+	}
+	for _, v := range subcaches {
+		bytes, err = v.GetBytesValue(key)
+		var expired int64
+		if err == nil {
+			e := entry(bytes)
+			_, expired, err = e.Get()
+		}
+		if errors.Is(err, errKnownMissing) {
+			break
+		}
+		if errors.Is(err, cache.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		return time.Unix(expired, 0).Sub(time.Now()), nil
+	}
+	return 0, cache.ErrNotFound
 }
 
 //SetCounter Set int val in cache by given key.Count cache and data cache are in two independent namespace.
 //Return any error raised.
 func (c *Cache) SetCounter(key string, v int64, ttl time.Duration) error {
+	if c.CounterMode == CounterLayered {
+		return c.setCounterLayered(key, v, ttl)
+	}
 	return c.SubCaches[len(c.SubCaches)-1].SetCounter(key, v, ttl)
 }
 
 //GetCounter Get int val from cache by given key.Count cache and data cache are in two independent namespace.
 //Return int data value and any error raised.
 func (c *Cache) GetCounter(key string) (int64, error) {
+	if c.CounterMode == CounterLayered {
+		return c.getCounterLayered(key)
+	}
 	return c.SubCaches[len(c.SubCaches)-1].GetCounter(key)
 }
 
 //IncrCounter Increase int val in cache by given key.Count cache and data cache are in two independent namespace.
 //Return int data value and any error raised.
 func (c *Cache) IncrCounter(key string, increment int64, ttl time.Duration) (int64, error) {
+	if c.CounterMode == CounterLayered {
+		return c.incrCounterLayered(key, increment, ttl)
+	}
 	return c.SubCaches[len(c.SubCaches)-1].IncrCounter(key, increment, ttl)
 }
 
 //ExpireCounter set cache counter  expire duration by given key and ttl
 func (c *Cache) ExpireCounter(key string, ttl time.Duration) error {
+	if c.CounterMode == CounterLayered {
+		return c.expireCounterLayered(key, ttl)
+	}
 	return c.SubCaches[len(c.SubCaches)-1].ExpireCounter(key, ttl)
 }
 
@@ -214,6 +396,7 @@ func (c *Cache) ExpireCounter(key string, ttl time.Duration) error {
 //Return any error raised.
 func (c *Cache) Del(key string) error {
 	var finalErr error
+	c.markWritten(key)
 	for _, v := range c.SubCaches {
 		err := v.Del(key)
 		if err != nil {
@@ -226,6 +409,9 @@ func (c *Cache) Del(key string) error {
 //DelCounter Delete int val in cache by given key.Count cache and data cache are in two independent namespace.
 //Return any error raised.
 func (c *Cache) DelCounter(key string) error {
+	if c.CounterMode == CounterLayered {
+		return c.delCounterLayered(key)
+	}
 	return c.SubCaches[len(c.SubCaches)-1].DelCounter(key)
 }
 
@@ -236,6 +422,83 @@ func (c *Cache) SetGCErrHandler(f func(err error)) {
 	}
 }
 
+//Ping check if all sub caches are reachable before context done.
+//Return any error raised.
+func (c *Cache) Ping(ctx context.Context) error {
+	for k := range c.SubCaches {
+		err := c.SubCaches[k].Ping(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//ExplainLayers explain how given key would be served by sub caches.
+//Sub caches are looked up in order,and only last sub cache is used if key is written recently in read-your-writes mode.
+//Value of sub cache serving key is read to check expired time stored in entry,which is counted by the sub cache.
+//Layer of explanation is set without found if key is answered by known missing marker.
+//Return explanation and any error raised.
+func (c *Cache) ExplainLayers(key string) (*cache.Explanation, error) {
+	result := &cache.Explanation{
+		Layer:  -1,
+		Layers: make([]*cache.Explanation, len(c.SubCaches)),
+	}
+	for k, v := range c.SubCaches {
+		e, err := v.Explain(key)
+		if err != nil {
+			return nil, err
+		}
+		result.Layers[k] = e
+	}
+	start := 0
+	if c.writtenRecently(key) {
+		start = len(c.SubCaches) - 1
+	}
+	for k := start; k < len(c.SubCaches); k++ {
+		if !result.Layers[k].Found {
+			continue
+		}
+		bytes, err := c.SubCaches[k].GetBytesValue(key)
+		if errors.Is(err, cache.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		e := entry(bytes)
+		buf, expired, err := e.Get()
+		if errors.Is(err, errKnownMissing) {
+			result.Layer = k
+			return result, nil
+		}
+		if errors.Is(err, cache.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		result.Found = true
+		result.Layer = k
+		result.Size = len(buf)
+		result.TTL = time.Unix(expired, 0).Sub(time.Now())
+		return result, nil
+	}
+	return result, nil
+}
+
+//Capabilities return capabilities supported by cache group.
+//Ttl inspection is always supported,counters depend on last sub cache,
+//batch operations,flush and ping depend on all sub caches.
+func (c *Cache) Capabilities() cache.Capabilities {
+	all := cache.CapabilityMGet | cache.CapabilityFlush | cache.CapabilityPing
+	for _, v := range c.SubCaches {
+		all = all & v.Capabilities()
+	}
+	last := c.SubCaches[len(c.SubCaches)-1].Capabilities() & cache.CapabilityCounter
+	return cache.CapabilityTTL | last | all
+}
+
 //Close Close cache.
 //Return any error if raised
 func (c *Cache) Close() error {
@@ -265,20 +528,11 @@ func (c *Cache) Flush() error {
 
 func init() {
 	cache.Register("cachegroup", func(loader func(interface{}) error) (cache.Driver, error) {
-		cc := Cache{}
-		caches := []*cache.OptionConfig{}
-		err := loader(&caches)
+		c := &Config{}
+		err := loader(c)
 		if err != nil {
 			return nil, err
 		}
-		cc.SubCaches = make([]*cache.Cache, len(caches))
-		for k, v := range caches {
-			subcache, err := cache.NewSubCache(v)
-			if err != nil {
-				return nil, err
-			}
-			cc.SubCaches[k] = subcache
-		}
-		return &cc, nil
+		return c.Create()
 	})
 }
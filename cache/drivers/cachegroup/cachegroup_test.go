@@ -1,6 +1,7 @@
 package cachegroup_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/herb-go/deprecated/cache"
@@ -86,7 +87,7 @@ func TestMSetMGet(t *testing.T) {
 		}
 	}
 	_, err = c.GetBytesValue(unusedKey)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 
@@ -193,7 +194,7 @@ func TestCloseAndFlush(t *testing.T) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKey, &resultDataModel)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 
@@ -239,7 +240,7 @@ func TestSearchUpdate(t *testing.T) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKeyUpdate, &resultDataModel)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 
@@ -268,7 +269,7 @@ func TestSearchUpdate(t *testing.T) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(testKeyBytesUpdate)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 }
@@ -312,7 +313,7 @@ func TestCounter(t *testing.T) {
 		t.Fatal(err)
 	}
 	resultDataInt, err = c.GetCounter(testKey)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 
@@ -365,15 +366,15 @@ func TestDefaulTTL(t *testing.T) {
 	}
 	time.Sleep(2000 * time.Millisecond)
 	err = c.Get(testKey, &resultDataModel)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(testKey2)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	resultDataInt, err = c.GetCounter(testKey3)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 }
@@ -501,15 +502,15 @@ func TestTTL(t *testing.T) {
 
 	time.Sleep(2000 * time.Millisecond)
 	err = c.Get(testKeyTTL1Second, &resultModelData)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(testKeyTTL1SecondBytes)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetCounter(testKeyTTL1SecondCounter)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKeyTTL3Second, &resultModelData)
@@ -564,27 +565,27 @@ func TestTTL(t *testing.T) {
 
 	time.Sleep(2000 * time.Millisecond)
 	err = c.Get(testKeyTTL1Second, &resultModelData)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(testKeyTTL1SecondBytes)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetCounter(testKeyTTL1SecondCounter)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKeyTTL3Second, &resultModelData)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(testKeyTTL3SecondBytes)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetCounter(testKeyTTL3SecondCounter)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKeyTTLRefresh, &resultModelData)
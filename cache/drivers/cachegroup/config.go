@@ -0,0 +1,114 @@
+package cachegroup
+
+import (
+	"errors"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+//ErrUnknownPromotionPolicy error raised when promotion policy name in config is unknown.
+var ErrUnknownPromotionPolicy = errors.New("cachegroup: unknown promotion policy")
+
+//ErrUnknownCounterMode error raised when counter mode name in config is unknown.
+var ErrUnknownCounterMode = errors.New("cachegroup: unknown counter mode")
+
+//PromotionPolicies promotion policies by name used in config.
+var PromotionPolicies = map[string]PromotionPolicy{
+	"":      PromoteSync,
+	"sync":  PromoteSync,
+	"none":  PromoteNone,
+	"async": PromoteAsync,
+	"hot":   PromoteIfHot,
+}
+
+//CounterModes counter modes by name used in config.
+var CounterModes = map[string]CounterMode{
+	"":        CounterLast,
+	"last":    CounterLast,
+	"layered": CounterLayered,
+}
+
+//LayerConfig options config of sub cache.
+type LayerConfig struct {
+	//MaxTTLInSecond max ttl in second of entries written to sub cache.
+	MaxTTLInSecond int64
+	//MinTTLInSecond min ttl in second of entries written to sub cache.
+	MinTTLInSecond int64
+	//NegativeTTLInSecond ttl in second of known missing markers written to sub cache.
+	NegativeTTLInSecond int64
+}
+
+//Options return layer options created by config.
+func (c *LayerConfig) Options() *LayerOptions {
+	return &LayerOptions{
+		MaxTTL:      time.Duration(c.MaxTTLInSecond) * time.Second,
+		MinTTL:      time.Duration(c.MinTTLInSecond) * time.Second,
+		NegativeTTL: time.Duration(c.NegativeTTLInSecond) * time.Second,
+	}
+}
+
+//Config cache group driver config.
+type Config struct {
+	//Caches configs of sub caches from first to last.
+	Caches []*cache.OptionConfig
+	//ReadYourWritesInSecond read-your-writes duration in second.
+	//Read-your-writes is disabled if not positive.
+	ReadYourWritesInSecond int64
+	//MaxConcurrentWrites max count of sub caches written concurrently.
+	//DefaultMaxConcurrentWrites will be used if not positive.
+	MaxConcurrentWrites int
+	//Promotion name of promotion policy in PromotionPolicies,like "async".
+	//PromoteSync will be used if empty.
+	Promotion string
+	//PromotionThreshold count of lower sub cache hits before entry promoted by "hot" policy.
+	//DefaultPromotionThreshold will be used if not positive.
+	PromotionThreshold int
+	//CounterMode name of counter mode in CounterModes,like "layered".
+	//CounterLast will be used if empty.
+	CounterMode string
+	//CounterSnapshotTTLInSecond ttl in second of counter snapshots in layered counter mode.
+	//Snapshots are disabled if not positive.
+	CounterSnapshotTTLInSecond int64
+	//Layers options configs of sub caches by index.
+	//Default options will be used if config of sub cache is nil.
+	Layers []*LayerConfig
+}
+
+//Create create new cache driver.
+//Return driver created and any error if raised.
+func (c *Config) Create() (cache.Driver, error) {
+	promotion, ok := PromotionPolicies[c.Promotion]
+	if !ok {
+		return nil, ErrUnknownPromotionPolicy
+	}
+	countermode, ok := CounterModes[c.CounterMode]
+	if !ok {
+		return nil, ErrUnknownCounterMode
+	}
+	cc := &Cache{
+		ReadYourWrites:      time.Duration(c.ReadYourWritesInSecond) * time.Second,
+		MaxConcurrentWrites: c.MaxConcurrentWrites,
+		Promotion:           promotion,
+		PromotionThreshold:  c.PromotionThreshold,
+		CounterMode:         countermode,
+		CounterSnapshotTTL:  time.Duration(c.CounterSnapshotTTLInSecond) * time.Second,
+	}
+	if len(c.Layers) > 0 {
+		cc.Layers = make([]*LayerOptions, len(c.Layers))
+		for k, v := range c.Layers {
+			if v != nil {
+				cc.Layers[k] = v.Options()
+			}
+		}
+	}
+	cc.SubCaches = make([]*cache.Cache, len(c.Caches))
+	for k, v := range c.Caches {
+		subcache, err := cache.NewSubCache(v)
+		if err != nil {
+			return nil, err
+		}
+		cc.SubCaches[k] = subcache
+	}
+	return cc, nil
+}
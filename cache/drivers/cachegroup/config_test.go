@@ -0,0 +1,58 @@
+package cachegroup
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+func newConfigTestOption(ttl int64) *cache.OptionConfig {
+	oc := cache.NewOptionConfig()
+	oc.Driver = "syncmapcache"
+	oc.TTL = ttl
+	oc.Marshaler = "json"
+	oc.Config = json.NewDecoder(bytes.NewBufferString(`{"Size":10000000}`)).Decode
+	return oc
+}
+
+func TestConfig(t *testing.T) {
+	config := &Config{
+		Caches:                     []*cache.OptionConfig{newConfigTestOption(60), newConfigTestOption(3600)},
+		ReadYourWritesInSecond:     5,
+		MaxConcurrentWrites:        2,
+		Promotion:                  "hot",
+		PromotionThreshold:         3,
+		CounterMode:                "layered",
+		CounterSnapshotTTLInSecond: 10,
+		Layers:                     []*LayerConfig{{MaxTTLInSecond: 60, MinTTLInSecond: 5, NegativeTTLInSecond: 3}},
+	}
+	d, err := config.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := d.(*Cache)
+	defer c.Close()
+	if len(c.SubCaches) != 2 || c.SubCaches[0].DefaultTTL() != time.Minute {
+		t.Fatal(c.SubCaches)
+	}
+	if c.ReadYourWrites != 5*time.Second || c.MaxConcurrentWrites != 2 || c.Promotion != PromoteIfHot || c.PromotionThreshold != 3 {
+		t.Fatal(c)
+	}
+	if c.CounterMode != CounterLayered || c.CounterSnapshotTTL != 10*time.Second {
+		t.Fatal(c)
+	}
+	if len(c.Layers) != 1 || *c.Layers[0] != (LayerOptions{MaxTTL: time.Minute, MinTTL: 5 * time.Second, NegativeTTL: 3 * time.Second}) {
+		t.Fatal(c.Layers)
+	}
+	_, err = (&Config{Promotion: "unknown"}).Create()
+	if err != ErrUnknownPromotionPolicy {
+		t.Fatal(err)
+	}
+	_, err = (&Config{CounterMode: "unknown"}).Create()
+	if err != ErrUnknownCounterMode {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,101 @@
+package cachegroup
+
+import (
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+//CounterMode mode deciding which sub caches counters are stored in.
+type CounterMode int
+
+//Counter modes.
+const (
+	//CounterLast store counters in last sub cache only.
+	CounterLast CounterMode = iota
+	//CounterLayered store counters in last sub cache as authoritative value,
+	//and fall back to upper sub caches from bottom to top if last sub cache fails,
+	//so that counters like rate limits keep working when last sub cache is briefly unavailable.
+	//Counter values are written to upper sub caches as snapshots if CounterSnapshotTTL is positive.
+	CounterLayered
+)
+
+var isCounterFailure = cache.IsRetryableError
+
+//snapshotCounter write counter value to upper sub caches as snapshot.
+//Snapshot errors are ignored.
+func (c *Cache) snapshotCounter(key string, v int64) {
+	if c.CounterSnapshotTTL <= 0 {
+		return
+	}
+	last := len(c.SubCaches) - 1
+	for k := last - 1; k >= 0; k-- {
+		c.SubCaches[k].SetCounter(key, v, c.capTTL(k, c.CounterSnapshotTTL))
+	}
+}
+
+//fallbackTTL return ttl of counters written to upper sub cache with given index when last sub cache fails.
+func (c *Cache) fallbackTTL(index int, ttl time.Duration) time.Duration {
+	if c.CounterSnapshotTTL > 0 && (ttl < 0 || ttl > c.CounterSnapshotTTL) {
+		ttl = c.CounterSnapshotTTL
+	}
+	return c.capTTL(index, ttl)
+}
+
+func (c *Cache) incrCounterLayered(key string, increment int64, ttl time.Duration) (int64, error) {
+	last := len(c.SubCaches) - 1
+	v, err := c.SubCaches[last].IncrCounter(key, increment, ttl)
+	if err == nil {
+		c.snapshotCounter(key, v)
+		return v, nil
+	}
+	for k := last - 1; k >= 0 && isCounterFailure(err); k-- {
+		v, err = c.SubCaches[k].IncrCounter(key, increment, c.fallbackTTL(k, ttl))
+	}
+	return v, err
+}
+
+func (c *Cache) setCounterLayered(key string, v int64, ttl time.Duration) error {
+	last := len(c.SubCaches) - 1
+	err := c.SubCaches[last].SetCounter(key, v, ttl)
+	if err == nil {
+		c.snapshotCounter(key, v)
+		return nil
+	}
+	for k := last - 1; k >= 0 && isCounterFailure(err); k-- {
+		err = c.SubCaches[k].SetCounter(key, v, c.fallbackTTL(k, ttl))
+	}
+	return err
+}
+
+func (c *Cache) getCounterLayered(key string) (int64, error) {
+	last := len(c.SubCaches) - 1
+	v, err := c.SubCaches[last].GetCounter(key)
+	for k := last - 1; k >= 0 && isCounterFailure(err); k-- {
+		v, err = c.SubCaches[k].GetCounter(key)
+	}
+	return v, err
+}
+
+func (c *Cache) expireCounterLayered(key string, ttl time.Duration) error {
+	last := len(c.SubCaches) - 1
+	err := c.SubCaches[last].ExpireCounter(key, ttl)
+	for k := last - 1; k >= 0; k-- {
+		c.SubCaches[k].ExpireCounter(key, c.fallbackTTL(k, ttl))
+	}
+	return err
+}
+
+func (c *Cache) delCounterLayered(key string) error {
+	var result Errors
+	for k, v := range c.SubCaches {
+		err := v.DelCounter(key)
+		if err != nil {
+			result = append(result, &LayerError{Layer: k, Err: err})
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
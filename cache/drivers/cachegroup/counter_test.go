@@ -0,0 +1,113 @@
+package cachegroup
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+var errTestCounter = errors.New("test counter error")
+
+type flakyCounterDriver struct {
+	cache.Driver
+	locker sync.Mutex
+	down   bool
+}
+
+func (d *flakyCounterDriver) setDown(down bool) {
+	d.locker.Lock()
+	defer d.locker.Unlock()
+	d.down = down
+}
+
+func (d *flakyCounterDriver) isDown() bool {
+	d.locker.Lock()
+	defer d.locker.Unlock()
+	return d.down
+}
+
+func (d *flakyCounterDriver) IncrCounter(key string, increment int64, ttl time.Duration) (int64, error) {
+	if d.isDown() {
+		return 0, errTestCounter
+	}
+	return d.Driver.IncrCounter(key, increment, ttl)
+}
+
+func (d *flakyCounterDriver) GetCounter(key string) (int64, error) {
+	if d.isDown() {
+		return 0, errTestCounter
+	}
+	return d.Driver.GetCounter(key)
+}
+
+func TestLayeredCounter(t *testing.T) {
+	local := newReadYourWritesTestSubCache()
+	remote := newReadYourWritesTestSubCache()
+	flaky := &flakyCounterDriver{Driver: remote.Driver}
+	remote.Driver = flaky
+	c := &Cache{
+		SubCaches:          []*cache.Cache{local, remote},
+		CounterMode:        CounterLayered,
+		CounterSnapshotTTL: time.Minute,
+	}
+	v, err := c.IncrCounter("counter", 2, time.Hour)
+	if err != nil || v != 2 {
+		t.Fatal(v, err)
+	}
+	v, err = local.GetCounter("counter")
+	if err != nil || v != 2 {
+		t.Fatal(v, err)
+	}
+	flaky.setDown(true)
+	v, err = c.IncrCounter("counter", 3, time.Hour)
+	if err != nil || v != 5 {
+		t.Fatal(v, err)
+	}
+	v, err = c.GetCounter("counter")
+	if err != nil || v != 5 {
+		t.Fatal(v, err)
+	}
+	flaky.setDown(false)
+	v, err = c.GetCounter("counter")
+	if err != nil || v != 2 {
+		t.Fatal(v, err)
+	}
+	err = c.DelCounter("counter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sub := range []*cache.Cache{local, remote} {
+		_, err = sub.GetCounter("counter")
+		if !errors.Is(err, cache.ErrNotFound) {
+			t.Fatal(err)
+		}
+	}
+	_, err = c.GetCounter("counter")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+}
+
+func TestLastCounter(t *testing.T) {
+	local := newReadYourWritesTestSubCache()
+	remote := newReadYourWritesTestSubCache()
+	flaky := &flakyCounterDriver{Driver: remote.Driver}
+	remote.Driver = flaky
+	c := &Cache{SubCaches: []*cache.Cache{local, remote}, CounterSnapshotTTL: time.Minute}
+	v, err := c.IncrCounter("counter", 2, time.Hour)
+	if err != nil || v != 2 {
+		t.Fatal(v, err)
+	}
+	_, err = local.GetCounter("counter")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	flaky.setDown(true)
+	_, err = c.IncrCounter("counter", 2, time.Hour)
+	if !errors.Is(err, errTestCounter) {
+		t.Fatal(err)
+	}
+}
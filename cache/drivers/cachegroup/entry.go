@@ -0,0 +1,124 @@
+package cachegroup
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+//Entry format:
+//
+//	version(1 byte) flags(1 byte) expired unix time(8 bytes) crc32 castagnoli checksum(4 bytes) value
+//
+//Checksum covers all bytes except checksum itself,
+//so that corrupted or truncated values from lossy sub caches are detected.
+//
+//Legacy entries written by previous versions are still accepted on read:
+//
+//	expired unix time(8 bytes) value
+//
+//Legacy entries have no checksum.Their first byte is always 0 as high byte of unix time,
+//which is never used as entry version.
+const (
+	entryVersion    = 1
+	entryHeaderSize = 14
+	entryCRCOffset  = 10
+)
+
+const (
+	legacyEntryVersion    = 0
+	legacyEntryHeaderSize = 8
+)
+
+//Entry flags.
+const (
+	//entryFlagMissing entry is a known missing marker without value.
+	entryFlagMissing = 1 << iota
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+//ErrCorruptedEntry error raised if entry read from sub cache is corrupted,truncated or in unknown format.
+//ErrCorruptedEntry matches cache.ErrNotFound with errors.Is,so that corrupted entries are treated as misses.
+var ErrCorruptedEntry = fmt.Errorf("cachegroup: corrupted entry: %w", cache.ErrNotFound)
+
+//errKnownMissing error raised if entry is a known missing marker not expired.
+var errKnownMissing = fmt.Errorf("cachegroup: known missing: %w", cache.ErrNotFound)
+
+type entry []byte
+
+func (e *entry) encode(flags byte, bytes []byte, expired int64) {
+	*e = make([]byte, entryHeaderSize+len(bytes))
+	(*e)[0] = entryVersion
+	(*e)[1] = flags
+	binary.BigEndian.PutUint64((*e)[2:entryCRCOffset], uint64(expired))
+	copy((*e)[entryHeaderSize:], bytes)
+	binary.BigEndian.PutUint32((*e)[entryCRCOffset:entryHeaderSize], e.checksum())
+}
+
+func (e entry) checksum() uint32 {
+	crc := crc32.Checksum(e[:entryCRCOffset], crcTable)
+	return crc32.Update(crc, crcTable, e[entryHeaderSize:])
+}
+
+//decode verify entry and return flags,expired time and value without copying.
+//Legacy entries are decoded without flags.
+//Return ErrCorruptedEntry if entry is corrupted.
+func (e entry) decode() (byte, int64, []byte, error) {
+	if len(e) >= legacyEntryHeaderSize && e[0] == legacyEntryVersion {
+		return 0, int64(binary.BigEndian.Uint64(e[:legacyEntryHeaderSize])), e[legacyEntryHeaderSize:], nil
+	}
+	if len(e) < entryHeaderSize || e[0] != entryVersion {
+		return 0, 0, nil, ErrCorruptedEntry
+	}
+	if binary.BigEndian.Uint32(e[entryCRCOffset:entryHeaderSize]) != e.checksum() {
+		return 0, 0, nil, ErrCorruptedEntry
+	}
+	return e[1], int64(binary.BigEndian.Uint64(e[2:entryCRCOffset])), e[entryHeaderSize:], nil
+}
+
+//Set encode given value which expires after given ttl to entry.
+//Return expired unix time.
+func (e *entry) Set(bytes []byte, ttl time.Duration) int64 {
+	expired := time.Now().Add(ttl).Unix()
+	e.encode(0, bytes, expired)
+	return expired
+}
+
+//Get decode value from entry.
+//Return value,expired unix time and any error raised.
+//Return cache.ErrNotFound if entry is expired,errKnownMissing if entry is a known missing marker not expired,
+//or ErrCorruptedEntry if entry is corrupted.
+func (e *entry) Get() ([]byte, int64, error) {
+	flags, expired, value, err := e.decode()
+	if err != nil {
+		return nil, expired, err
+	}
+	if expired < time.Now().Unix() {
+		return nil, expired, cache.ErrNotFound
+	}
+	if flags&entryFlagMissing != 0 {
+		return nil, expired, errKnownMissing
+	}
+	buf := make([]byte, len(value))
+	copy(buf, value)
+	return buf, expired, nil
+}
+
+//SetMissing set entry as known missing marker which expires after given ttl.
+func (e *entry) SetMissing(ttl time.Duration) {
+	e.encode(entryFlagMissing, nil, time.Now().Add(ttl).Unix())
+}
+
+//Missing check if entry is known missing marker.
+//Return whether entry is marker and whether marker is not expired.
+func (e entry) Missing() (bool, bool) {
+	flags, expired, _, err := e.decode()
+	if err != nil || flags&entryFlagMissing == 0 {
+		return false, false
+	}
+	return true, expired >= time.Now().Unix()
+}
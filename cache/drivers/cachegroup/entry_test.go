@@ -0,0 +1,121 @@
+package cachegroup
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+func TestEntry(t *testing.T) {
+	var e entry
+	expired := e.Set([]byte("value"), time.Hour)
+	if len(e) != entryHeaderSize+5 || e[0] != entryVersion {
+		t.Fatal(e)
+	}
+	bs, ex, err := e.Get()
+	if err != nil || string(bs) != "value" || ex != expired {
+		t.Fatal(string(bs), ex, err)
+	}
+	bs[0] = 'V'
+	bs, _, err = e.Get()
+	if err != nil || string(bs) != "value" {
+		t.Fatal(string(bs), err)
+	}
+	for _, corrupted := range []entry{
+		e[:len(e)-1],
+		e[:entryHeaderSize-1],
+		append(entry{2}, e[1:]...),
+		append(append(entry{}, e[:len(e)-1]...), 'V'),
+	} {
+		_, _, err = corrupted.Get()
+		if !errors.Is(err, ErrCorruptedEntry) || !errors.Is(err, cache.ErrNotFound) {
+			t.Fatal(corrupted, err)
+		}
+	}
+	legacy := make(entry, 13)
+	binary.BigEndian.PutUint64(legacy, uint64(expired))
+	copy(legacy[8:], "value")
+	bs, ex, err = legacy.Get()
+	if err != nil || string(bs) != "value" || ex != expired {
+		t.Fatal(string(bs), ex, err)
+	}
+	truncated := legacy[:legacyEntryHeaderSize-1]
+	_, _, err = truncated.Get()
+	if !errors.Is(err, ErrCorruptedEntry) {
+		t.Fatal(err)
+	}
+	binary.BigEndian.PutUint64(legacy, uint64(time.Now().Add(-time.Hour).Unix()))
+	_, _, err = legacy.Get()
+	if !errors.Is(err, cache.ErrNotFound) || errors.Is(err, ErrCorruptedEntry) {
+		t.Fatal(err)
+	}
+	e.Set([]byte("value"), -time.Hour)
+	_, _, err = e.Get()
+	if !errors.Is(err, cache.ErrNotFound) || errors.Is(err, ErrCorruptedEntry) {
+		t.Fatal(err)
+	}
+	e.SetMissing(time.Hour)
+	_, _, err = e.Get()
+	if !errors.Is(err, errKnownMissing) {
+		t.Fatal(err)
+	}
+}
+
+func TestCorruptedEntry(t *testing.T) {
+	local := newReadYourWritesTestSubCache()
+	remote := newReadYourWritesTestSubCache()
+	c := &Cache{SubCaches: []*cache.Cache{local, remote}}
+	err := c.SetBytesValue("key", []byte("value"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := local.GetBytesValue("key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	corrupted := append([]byte{}, bs...)
+	corrupted[len(corrupted)-1] = 'V'
+	err = local.SetBytesValue("key", corrupted, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err = c.GetBytesValue("key")
+	if err != nil || string(bs) != "value" {
+		t.Fatal(string(bs), err)
+	}
+	stats := c.LayerStats()
+	if stats[0].Corrupted != 1 || stats[0].Miss != 1 || stats[1].Hit != 1 {
+		t.Fatal(stats[0], stats[1])
+	}
+	bs, err = local.GetBytesValue("key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := entry(bs)
+	bs, _, err = e.Get()
+	if err != nil || string(bs) != "value" {
+		t.Fatal(string(bs), err)
+	}
+	err = remote.SetBytesValue("key", []byte("corrupted"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = local.Del("key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.GetBytesValue("key")
+	if err != cache.ErrNotFound {
+		t.Fatal(err)
+	}
+	data, err := c.MGetBytesValue("key")
+	if err != nil || len(data) != 0 {
+		t.Fatal(data, err)
+	}
+	if c.LayerStats()[1].Corrupted != 2 {
+		t.Fatal(c.LayerStats()[1])
+	}
+}
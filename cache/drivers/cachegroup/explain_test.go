@@ -0,0 +1,67 @@
+package cachegroup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+func TestExplain(t *testing.T) {
+	local := newReadYourWritesTestSubCache()
+	remote := newReadYourWritesTestSubCache()
+	local.TTL = time.Minute
+	g := &Cache{SubCaches: []*cache.Cache{local, remote}}
+	u := cache.NewUtil()
+	u.Marshaler = &cache.JSONMarshaler{}
+	u.MarshalerName = "json"
+	g.SetUtil(u)
+	c := cache.New()
+	c.Driver = g
+	c.TTL = time.Hour
+	e, err := c.Explain("key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Found || e.Layer != -1 || len(e.Layers) != 2 || e.Layers[0].Found || e.Layers[1].Found || e.TTL >= 0 {
+		t.Fatal(e)
+	}
+	if e.Key != "key" || e.FinalKey != cache.Key("key") || e.Marshaler != "json" {
+		t.Fatal(e)
+	}
+	err = c.Set("key", "value", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err = c.Explain("key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !e.Found || e.Layer != 0 || !e.Layers[0].Found || !e.Layers[1].Found {
+		t.Fatal(e)
+	}
+	if e.TTL <= 59*time.Minute || e.Layers[0].TTL > time.Minute || e.Layers[0].TTL <= 0 {
+		t.Fatal(e.TTL, e.Layers[0].TTL)
+	}
+	if e.Size != len(`"value"`) {
+		t.Fatal(e.Size)
+	}
+	err = local.Del(e.FinalKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err = c.Explain("key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !e.Found || e.Layer != 1 || e.Layers[0].Found {
+		t.Fatal(e)
+	}
+	e, err = c.Explain("key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Layers[0].Found {
+		t.Fatal("explain should not backfill sub caches")
+	}
+}
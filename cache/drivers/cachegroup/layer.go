@@ -0,0 +1,50 @@
+package cachegroup
+
+import "time"
+
+//LayerOptions options of sub cache.
+type LayerOptions struct {
+	//MaxTTL max ttl of entries written to sub cache.
+	//Ignored if not positive.
+	MaxTTL time.Duration
+	//MinTTL min ttl of entries written to sub cache.
+	//Entries with less ttl are deleted from sub cache instead of written,so that short-lived entries will not churn sub cache.
+	//Ignored for last sub cache or if not positive.
+	MinTTL time.Duration
+	//NegativeTTL ttl of known missing markers written to sub cache when key missed in all sub caches,
+	//so that following lookups of key are answered by sub cache in ttl without querying lower sub caches.
+	//Ignored for last sub cache.Markers are disabled if not positive.
+	NegativeTTL time.Duration
+}
+
+var defaultLayerOptions = &LayerOptions{}
+
+//layerOptions return options of sub cache with given index.
+func (c *Cache) layerOptions(index int) *LayerOptions {
+	if index < len(c.Layers) && c.Layers[index] != nil {
+		return c.Layers[index]
+	}
+	return defaultLayerOptions
+}
+
+//capTTL return ttl capped by max ttl of sub cache with given index.
+func (c *Cache) capTTL(index int, ttl time.Duration) time.Duration {
+	max := c.layerOptions(index).MaxTTL
+	if max > 0 && (ttl < 0 || ttl > max) {
+		return max
+	}
+	return ttl
+}
+
+//propagateMissing write known missing markers of given key to upper sub caches with positive negative ttl.
+func (c *Cache) propagateMissing(key string) {
+	for k, v := range c.SubCaches[:len(c.SubCaches)-1] {
+		ttl := c.layerOptions(k).NegativeTTL
+		if ttl <= 0 {
+			continue
+		}
+		var e entry
+		e.SetMissing(ttl)
+		v.SetBytesValue(key, []byte(e), ttl)
+	}
+}
@@ -0,0 +1,143 @@
+package cachegroup
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+func TestLayerTTL(t *testing.T) {
+	local := newReadYourWritesTestSubCache()
+	remote := newReadYourWritesTestSubCache()
+	c := &Cache{
+		SubCaches: []*cache.Cache{local, remote},
+		Layers: []*LayerOptions{
+			{MaxTTL: time.Minute, MinTTL: 10 * time.Second},
+			{MaxTTL: 30 * time.Minute},
+		},
+	}
+	err := c.SetBytesValue("key", []byte("value"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ttl, err := local.GetTTL("key")
+	if err != nil || ttl > time.Minute || ttl < 50*time.Second {
+		t.Fatal(ttl, err)
+	}
+	ttl, err = remote.GetTTL("key")
+	if err != nil || ttl > 30*time.Minute || ttl < 29*time.Minute {
+		t.Fatal(ttl, err)
+	}
+	err = c.SetBytesValue("key", []byte("short"), 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = local.GetBytesValue("key")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	bs, err := c.GetBytesValue("key")
+	if err != nil || string(bs) != "short" {
+		t.Fatal(string(bs), err)
+	}
+	_, err = local.GetBytesValue("key")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+}
+
+func TestNegativeMarker(t *testing.T) {
+	local := newReadYourWritesTestSubCache()
+	remote := newReadYourWritesTestSubCache()
+	c := &Cache{
+		SubCaches: []*cache.Cache{local, remote},
+		Layers: []*LayerOptions{
+			{NegativeTTL: time.Minute},
+		},
+	}
+	_, err := c.GetBytesValue("key")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	bs, err := local.GetBytesValue("key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if marker, valid := entry(bs).Missing(); !marker || !valid {
+		t.Fatal(marker, valid)
+	}
+	marker := entry(bs)
+	_, _, err = marker.Get()
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	var e entry
+	e.Set([]byte("value"), time.Hour)
+	err = remote.SetBytesValue("key", []byte(e), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.ResetLayerStats()
+	_, err = c.GetBytesValue("key")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	data, err := c.MGetBytesValue("key")
+	if err != nil || len(data) != 0 {
+		t.Fatal(data, err)
+	}
+	stats := c.LayerStats()
+	if stats[0].Miss != 2 || stats[1].Lookups() != 0 {
+		t.Fatal(stats[0], stats[1])
+	}
+	_, err = remote.GetBytesValue("key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.SetBytesValue("key", []byte("value"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err = c.GetBytesValue("key")
+	if err != nil || string(bs) != "value" {
+		t.Fatal(string(bs), err)
+	}
+	data, err = c.MGetBytesValue("notexist")
+	if err != nil || len(data) != 0 {
+		t.Fatal(data, err)
+	}
+	bs, err = local.GetBytesValue("notexist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if marker, valid := entry(bs).Missing(); !marker || !valid {
+		t.Fatal(marker, valid)
+	}
+	_, err = remote.GetBytesValue("notexist")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+}
+
+func TestExpiredNegativeMarker(t *testing.T) {
+	local := newReadYourWritesTestSubCache()
+	remote := newReadYourWritesTestSubCache()
+	c := &Cache{SubCaches: []*cache.Cache{local, remote}}
+	var e entry
+	e.SetMissing(-time.Minute)
+	err := local.SetBytesValue("key", []byte(e), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Set([]byte("value"), time.Hour)
+	err = remote.SetBytesValue("key", []byte(e), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := c.GetBytesValue("key")
+	if err != nil || string(bs) != "value" {
+		t.Fatal(string(bs), err)
+	}
+}
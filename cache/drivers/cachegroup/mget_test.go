@@ -0,0 +1,81 @@
+package cachegroup
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+func TestLayeredMGet(t *testing.T) {
+	local := newReadYourWritesTestSubCache()
+	remote := newReadYourWritesTestSubCache()
+	c := &Cache{SubCaches: []*cache.Cache{local, remote}}
+	var e entry
+	e.Set([]byte("local"), time.Hour)
+	err := local.SetBytesValue("a", []byte(e), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Set([]byte("remote"), time.Hour)
+	err = remote.SetBytesValue("a", []byte(e), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = remote.SetBytesValue("b", []byte(e), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := c.MGetBytesValue("a", "b", "notexist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 2 || string(data["a"]) != "local" || string(data["b"]) != "remote" || data["notexist"] != nil {
+		t.Fatal(data)
+	}
+	stats := c.LayerStats()
+	if stats[0].Hit != 1 || stats[0].Miss != 2 || stats[1].Hit != 1 || stats[1].Miss != 1 {
+		t.Fatal(stats[0], stats[1])
+	}
+	bs, err := local.GetBytesValue("b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e = entry(bs)
+	bs, _, err = e.Get()
+	if err != nil || string(bs) != "remote" {
+		t.Fatal(string(bs), err)
+	}
+}
+
+func TestLayeredMGetReadYourWrites(t *testing.T) {
+	local := newReadYourWritesTestSubCache()
+	remote := newReadYourWritesTestSubCache()
+	c := &Cache{SubCaches: []*cache.Cache{local, remote}, ReadYourWrites: time.Minute}
+	err := c.SetBytesValue("a", []byte("value"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var e entry
+	e.Set([]byte("stale"), time.Hour)
+	err = local.SetBytesValue("a", []byte(e), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = local.Del("notexist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := c.MGetBytesValue("a", "notexist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 1 || string(data["a"]) != "value" {
+		t.Fatal(data)
+	}
+	_, err = local.GetBytesValue("notexist")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,87 @@
+package cachegroup
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+//PromotionPolicy policy deciding how entries hit in lower sub cache are written back to upper sub caches which missed.
+type PromotionPolicy int
+
+//Promotion policies.
+const (
+	//PromoteSync write entry back to upper sub caches before value returned.
+	PromoteSync PromotionPolicy = iota
+	//PromoteNone never write entry back to upper sub caches.
+	PromoteNone
+	//PromoteAsync write entry back to upper sub caches in background.
+	PromoteAsync
+	//PromoteIfHot write entry back to upper sub caches only after entry hit in lower sub caches PromotionThreshold times.
+	PromoteIfHot
+)
+
+//DefaultPromotionThreshold default count of lower sub cache hits before entry promoted by PromoteIfHot policy.
+const DefaultPromotionThreshold = 2
+
+//MaxHotKeys max count of keys whose lower sub cache hits are counted by PromoteIfHot policy.
+//All hit counters are reset when exceeded.
+var MaxHotKeys = 10000
+
+//MaxAsyncPromotions max count of background promotions in progress.
+//Promotions are dropped when exceeded.
+var MaxAsyncPromotions int32 = 64
+
+type hotCounter struct {
+	locker sync.Mutex
+	hits   map[string]int
+}
+
+//hit increase hit count of given key.
+//Return true and reset hit count if count reaches given threshold.
+func (h *hotCounter) hit(key string, threshold int) bool {
+	h.locker.Lock()
+	defer h.locker.Unlock()
+	if h.hits == nil || len(h.hits) >= MaxHotKeys {
+		h.hits = map[string]int{}
+	}
+	h.hits[key]++
+	if h.hits[key] < threshold {
+		return false
+	}
+	delete(h.hits, key)
+	return true
+}
+
+//promote write entry hit in sub cache with given index back to upper sub caches by promotion policy.
+func (c *Cache) promote(key string, index int, bytes []byte, expired int64) {
+	if index == 0 {
+		return
+	}
+	switch c.Promotion {
+	case PromoteNone:
+		return
+	case PromoteAsync:
+		c.promoteAsync(key, index, bytes, expired)
+	case PromoteIfHot:
+		threshold := c.PromotionThreshold
+		if threshold <= 0 {
+			threshold = DefaultPromotionThreshold
+		}
+		if c.hotCounter.hit(key, threshold) {
+			c.setBytesCaches(key, c.SubCaches[:index], bytes, expired, modeSet)
+		}
+	default:
+		c.setBytesCaches(key, c.SubCaches[:index], bytes, expired, modeSet)
+	}
+}
+
+func (c *Cache) promoteAsync(key string, index int, bytes []byte, expired int64) {
+	if atomic.AddInt32(&c.asyncPromotions, 1) > MaxAsyncPromotions {
+		atomic.AddInt32(&c.asyncPromotions, -1)
+		return
+	}
+	go func() {
+		defer atomic.AddInt32(&c.asyncPromotions, -1)
+		c.setBytesCaches(key, c.SubCaches[:index], bytes, expired, modeSet)
+	}()
+}
@@ -0,0 +1,86 @@
+package cachegroup
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+func newPromotionTestCache(policy PromotionPolicy) (*Cache, *cache.Cache) {
+	local := newReadYourWritesTestSubCache()
+	remote := newReadYourWritesTestSubCache()
+	var e entry
+	e.Set([]byte("value"), time.Hour)
+	err := remote.SetBytesValue("key", []byte(e), time.Hour)
+	if err != nil {
+		panic(err)
+	}
+	return &Cache{SubCaches: []*cache.Cache{local, remote}, Promotion: policy}, local
+}
+
+func waitPromoted(local *cache.Cache) bool {
+	for i := 0; i < 100; i++ {
+		_, err := local.GetBytesValue("key")
+		if err == nil {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return false
+}
+
+func TestPromotion(t *testing.T) {
+	c, local := newPromotionTestCache(PromoteSync)
+	bs, err := c.GetBytesValue("key")
+	if err != nil || string(bs) != "value" {
+		t.Fatal(string(bs), err)
+	}
+	_, err = local.GetBytesValue("key")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, local = newPromotionTestCache(PromoteNone)
+	for i := 0; i < 3; i++ {
+		bs, err = c.GetBytesValue("key")
+		if err != nil || string(bs) != "value" {
+			t.Fatal(string(bs), err)
+		}
+	}
+	_, err = local.GetBytesValue("key")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+
+	c, local = newPromotionTestCache(PromoteAsync)
+	bs, err = c.GetBytesValue("key")
+	if err != nil || string(bs) != "value" {
+		t.Fatal(string(bs), err)
+	}
+	if !waitPromoted(local) {
+		t.Fatal("entry not promoted")
+	}
+
+	c, local = newPromotionTestCache(PromoteIfHot)
+	c.PromotionThreshold = 3
+	for i := 0; i < 2; i++ {
+		bs, err = c.GetBytesValue("key")
+		if err != nil || string(bs) != "value" {
+			t.Fatal(string(bs), err)
+		}
+		_, err = local.GetBytesValue("key")
+		if !errors.Is(err, cache.ErrNotFound) {
+			t.Fatal(i, err)
+		}
+	}
+	bs, err = c.GetBytesValue("key")
+	if err != nil || string(bs) != "value" {
+		t.Fatal(string(bs), err)
+	}
+	_, err = local.GetBytesValue("key")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
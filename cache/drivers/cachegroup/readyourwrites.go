@@ -0,0 +1,60 @@
+package cachegroup
+
+import (
+	"sync"
+	"time"
+)
+
+//MinReadYourWritesPruneSize min count of write markers before expired markers are pruned.
+var MinReadYourWritesPruneSize = 1024
+
+type writeMarkers struct {
+	locker    sync.Mutex
+	markers   map[string]int64
+	pruneSize int
+}
+
+func (m *writeMarkers) mark(key string, ttl time.Duration) {
+	now := time.Now().UnixNano()
+	m.locker.Lock()
+	defer m.locker.Unlock()
+	if m.markers == nil {
+		m.markers = map[string]int64{}
+	}
+	m.markers[key] = now + int64(ttl)
+	if len(m.markers) < m.pruneSize || len(m.markers) < MinReadYourWritesPruneSize {
+		return
+	}
+	for k, v := range m.markers {
+		if v < now {
+			delete(m.markers, k)
+		}
+	}
+	m.pruneSize = len(m.markers) * 2
+}
+
+func (m *writeMarkers) marked(key string) bool {
+	m.locker.Lock()
+	defer m.locker.Unlock()
+	expired, ok := m.markers[key]
+	if !ok {
+		return false
+	}
+	if expired < time.Now().UnixNano() {
+		delete(m.markers, key)
+		return false
+	}
+	return true
+}
+
+//markWritten record local write marker of given key if read-your-writes mode enabled.
+func (c *Cache) markWritten(key string) {
+	if c.ReadYourWrites > 0 {
+		c.writeMarkers.mark(key, c.ReadYourWrites)
+	}
+}
+
+//writtenRecently check if given key is written by this process in read-your-writes duration.
+func (c *Cache) writtenRecently(key string) bool {
+	return c.ReadYourWrites > 0 && c.writeMarkers.marked(key)
+}
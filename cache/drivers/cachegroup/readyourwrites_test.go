@@ -0,0 +1,71 @@
+package cachegroup
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+	_ "github.com/herb-go/deprecated/cache/drivers/syncmapcache"
+)
+
+func newReadYourWritesTestSubCache() *cache.Cache {
+	oc := cache.NewOptionConfig()
+	oc.Driver = "syncmapcache"
+	oc.TTL = 3600
+	oc.Marshaler = "json"
+	oc.Config = json.NewDecoder(bytes.NewBufferString(`{"Size":10000000}`)).Decode
+	c, err := cache.NewSubCache(oc)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func TestReadYourWrites(t *testing.T) {
+	local := newReadYourWritesTestSubCache()
+	remote := newReadYourWritesTestSubCache()
+	c := &Cache{SubCaches: []*cache.Cache{local, remote}}
+	err := c.SetBytesValue("key", []byte("value"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var e entry
+	e.Set([]byte("stale"), time.Hour)
+	err = local.SetBytesValue("key", []byte(e), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := c.GetBytesValue("key")
+	if err != nil || string(bs) != "stale" {
+		t.Fatal(string(bs), err)
+	}
+	c.ReadYourWrites = time.Hour
+	err = c.MSetBytesValue(map[string][]byte{"key": []byte("value2")}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err = c.GetBytesValue("key")
+	if err != nil || string(bs) != "value2" {
+		t.Fatal(string(bs), err)
+	}
+	err = remote.Del("key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.GetBytesValue("key")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	c.ReadYourWrites = time.Millisecond
+	err = c.MSetBytesValue(map[string][]byte{"key2": []byte("value")}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	if c.writtenRecently("key2") {
+		t.Fatal("marker not expired")
+	}
+}
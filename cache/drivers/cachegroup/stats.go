@@ -0,0 +1,138 @@
+package cachegroup
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+//LayerStats lookup statistics of sub cache.
+//Lookups by GetBytesValue and MGetBytesValue are counted.
+type LayerStats struct {
+	//Hit count of lookups served by sub cache.
+	Hit int64
+	//Miss count of lookups missed in sub cache,including expired entries.
+	Miss int64
+	//Error count of lookups failed in sub cache.
+	Error int64
+	//Corrupted count of corrupted entries read from sub cache,which are counted as misses too.
+	Corrupted int64
+	//Latency total duration of lookups in sub cache.
+	Latency time.Duration
+}
+
+//Lookups return count of lookups in sub cache.
+func (s *LayerStats) Lookups() int64 {
+	return s.Hit + s.Miss + s.Error
+}
+
+//HitRate return rate of lookups served by sub cache.
+//Return 0 if no lookup.
+func (s *LayerStats) HitRate() float64 {
+	lookups := s.Lookups()
+	if lookups == 0 {
+		return 0
+	}
+	return float64(s.Hit) / float64(lookups)
+}
+
+//AverageLatency return average duration of lookups in sub cache.
+//Return 0 if no lookup.
+func (s *LayerStats) AverageLatency() time.Duration {
+	lookups := s.Lookups()
+	if lookups == 0 {
+		return 0
+	}
+	return s.Latency / time.Duration(lookups)
+}
+
+type layerCounter struct {
+	hit       int64
+	miss      int64
+	error     int64
+	corrupted int64
+	latency   int64
+}
+
+type layerCounters struct {
+	once     sync.Once
+	counters []*layerCounter
+}
+
+//layer return counter of sub cache with given index.
+func (c *Cache) layer(index int) *layerCounter {
+	c.layerCounters.once.Do(func() {
+		c.layerCounters.counters = make([]*layerCounter, len(c.SubCaches))
+		for k := range c.layerCounters.counters {
+			c.layerCounters.counters[k] = &layerCounter{}
+		}
+	})
+	return c.layerCounters.counters[index]
+}
+
+//observeLookup record lookups in sub cache with given index.
+func (c *Cache) observeLookup(index int, start time.Time, hit int, miss int, failed int) {
+	l := c.layer(index)
+	atomic.AddInt64(&l.latency, int64(time.Since(start)))
+	if hit > 0 {
+		atomic.AddInt64(&l.hit, int64(hit))
+	}
+	if miss > 0 {
+		atomic.AddInt64(&l.miss, int64(miss))
+	}
+	if failed > 0 {
+		atomic.AddInt64(&l.error, int64(failed))
+	}
+}
+
+//observeCorrupted record corrupted entry read from sub cache with given index.
+func (c *Cache) observeCorrupted(index int) {
+	atomic.AddInt64(&c.layer(index).corrupted, 1)
+}
+
+//observeGet record lookup of single key in sub cache with given index by error returned.
+func (c *Cache) observeGet(index int, start time.Time, err error) {
+	switch {
+	case err == nil:
+		c.observeLookup(index, start, 1, 0, 0)
+	case errors.Is(err, cache.ErrNotFound):
+		if errors.Is(err, ErrCorruptedEntry) {
+			c.observeCorrupted(index)
+		}
+		c.observeLookup(index, start, 0, 1, 0)
+	default:
+		c.observeLookup(index, start, 0, 0, 1)
+	}
+}
+
+//LayerStats return lookup statistics of sub caches in order,
+//so that hits served by each sub cache can be told.
+func (c *Cache) LayerStats() []*LayerStats {
+	result := make([]*LayerStats, len(c.SubCaches))
+	for k := range c.SubCaches {
+		l := c.layer(k)
+		result[k] = &LayerStats{
+			Hit:       atomic.LoadInt64(&l.hit),
+			Miss:      atomic.LoadInt64(&l.miss),
+			Error:     atomic.LoadInt64(&l.error),
+			Corrupted: atomic.LoadInt64(&l.corrupted),
+			Latency:   time.Duration(atomic.LoadInt64(&l.latency)),
+		}
+	}
+	return result
+}
+
+//ResetLayerStats reset lookup statistics of all sub caches.
+func (c *Cache) ResetLayerStats() {
+	for k := range c.SubCaches {
+		l := c.layer(k)
+		atomic.StoreInt64(&l.hit, 0)
+		atomic.StoreInt64(&l.miss, 0)
+		atomic.StoreInt64(&l.error, 0)
+		atomic.StoreInt64(&l.corrupted, 0)
+		atomic.StoreInt64(&l.latency, 0)
+	}
+}
@@ -0,0 +1,78 @@
+package cachegroup
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+func TestLayerStats(t *testing.T) {
+	local := newReadYourWritesTestSubCache()
+	remote := newReadYourWritesTestSubCache()
+	c := &Cache{SubCaches: []*cache.Cache{local, remote}}
+	_, err := c.GetBytesValue("key")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	stats := c.LayerStats()
+	if len(stats) != 2 || stats[0].Miss != 1 || stats[1].Miss != 1 || stats[0].Hit != 0 || stats[1].Hit != 0 {
+		t.Fatal(stats[0], stats[1])
+	}
+	var e entry
+	e.Set([]byte("value"), time.Hour)
+	err = remote.SetBytesValue("key", []byte(e), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := c.GetBytesValue("key")
+	if err != nil || string(bs) != "value" {
+		t.Fatal(string(bs), err)
+	}
+	bs, err = c.GetBytesValue("key")
+	if err != nil || string(bs) != "value" {
+		t.Fatal(string(bs), err)
+	}
+	stats = c.LayerStats()
+	if stats[0].Hit != 1 || stats[0].Miss != 2 || stats[1].Hit != 1 || stats[1].Miss != 1 {
+		t.Fatal(stats[0], stats[1])
+	}
+	if stats[0].Lookups() != 3 || stats[0].HitRate() <= 0.33 || stats[0].HitRate() >= 0.34 {
+		t.Fatal(stats[0].Lookups(), stats[0].HitRate())
+	}
+	if stats[0].Latency <= 0 || stats[0].AverageLatency() <= 0 {
+		t.Fatal(stats[0].Latency)
+	}
+	data, err := c.MGetBytesValue("key", "notexist")
+	if err != nil || string(data["key"]) != "value" {
+		t.Fatal(data, err)
+	}
+	stats = c.LayerStats()
+	if stats[0].Hit != 2 || stats[0].Miss != 3 || stats[1].Hit != 1 || stats[1].Miss != 2 {
+		t.Fatal(stats[0], stats[1])
+	}
+	c.ResetLayerStats()
+	stats = c.LayerStats()
+	if stats[0].Lookups() != 0 || stats[1].Lookups() != 0 || stats[0].Latency != 0 || stats[0].AverageLatency() != 0 {
+		t.Fatal(stats[0], stats[1])
+	}
+}
+
+func TestLayerStatsReadYourWrites(t *testing.T) {
+	local := newReadYourWritesTestSubCache()
+	remote := newReadYourWritesTestSubCache()
+	c := &Cache{SubCaches: []*cache.Cache{local, remote}, ReadYourWrites: time.Minute}
+	err := c.SetBytesValue("key", []byte("value"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := c.GetBytesValue("key")
+	if err != nil || string(bs) != "value" {
+		t.Fatal(string(bs), err)
+	}
+	stats := c.LayerStats()
+	if stats[0].Lookups() != 0 || stats[1].Hit != 1 {
+		t.Fatal(stats[0], stats[1])
+	}
+}
@@ -0,0 +1,53 @@
+package cachegroup
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+var errTestWrite = errors.New("test write error")
+
+type failingDriver struct {
+	cache.Driver
+}
+
+func (d *failingDriver) SetBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	return errTestWrite
+}
+
+func TestConcurrentWrites(t *testing.T) {
+	caches := []*cache.Cache{}
+	for i := 0; i < 5; i++ {
+		caches = append(caches, newReadYourWritesTestSubCache())
+	}
+	caches[1].Driver = &failingDriver{Driver: caches[1].Driver}
+	caches[3].Driver = &failingDriver{Driver: caches[3].Driver}
+	c := &Cache{SubCaches: caches, MaxConcurrentWrites: 2}
+	err := c.SetBytesValue("key", []byte("value"), time.Hour)
+	if !errors.Is(err, errTestWrite) {
+		t.Fatal(err)
+	}
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 2 || errs[0].Layer != 1 || errs[1].Layer != 3 {
+		t.Fatal(err)
+	}
+	var layerErr *LayerError
+	if !errors.As(err, &layerErr) || layerErr.Layer != 1 {
+		t.Fatal(err)
+	}
+	for _, k := range []int{0, 2, 4} {
+		_, err := caches[k].GetBytesValue("key")
+		if err != nil {
+			t.Fatal(k, err)
+		}
+	}
+	caches[1].Driver = caches[1].Driver.(*failingDriver).Driver
+	caches[3].Driver = caches[3].Driver.(*failingDriver).Driver
+	err = c.SetBytesValue("key", []byte("value"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,384 @@
+//Package failovercache provides a cache driver which sends operations to primary cache,
+//and retries against secondary cache if primary cache fails.
+package failovercache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+//DefaultProbeInterval default interval between primary cache health probes.
+var DefaultProbeInterval = 5 * time.Second
+
+//ProbeKey key used to probe primary cache health.
+var ProbeKey = "failovercache.probe"
+
+//MaxDirtyKeys max count of keys and counters written to secondary cache which are tracked while primary cache is down.
+//Keys written after exceeded are not tracked,and may be stale in primary cache after switching back.
+var MaxDirtyKeys = 10000
+
+//dirtyKeys keys and counters written to secondary cache while primary cache is down.
+type dirtyKeys struct {
+	locker   sync.Mutex
+	keys     map[string]bool
+	counters map[string]bool
+}
+
+func (d *dirtyKeys) add(counter bool, keys ...string) {
+	d.locker.Lock()
+	defer d.locker.Unlock()
+	if d.keys == nil {
+		d.keys = map[string]bool{}
+		d.counters = map[string]bool{}
+	}
+	for _, k := range keys {
+		if len(d.keys)+len(d.counters) >= MaxDirtyKeys {
+			return
+		}
+		if counter {
+			d.counters[k] = true
+		} else {
+			d.keys[k] = true
+		}
+	}
+}
+
+//clean delete tracked keys and counters from given cache.
+//Keys and counters deleted are untracked.
+//Return any error if raised.
+func (d *dirtyKeys) clean(c *cache.Cache) error {
+	d.locker.Lock()
+	defer d.locker.Unlock()
+	for k := range d.keys {
+		err := c.Del(k)
+		if isFailure(err) {
+			return err
+		}
+		delete(d.keys, k)
+	}
+	for k := range d.counters {
+		err := c.DelCounter(k)
+		if isFailure(err) {
+			return err
+		}
+		delete(d.counters, k)
+	}
+	return nil
+}
+
+//Cache The failover cache driver.
+type Cache struct {
+	cache.DriverUtil
+	//Primary primary cache.
+	Primary *cache.Cache
+	//Secondary secondary cache.
+	Secondary *cache.Cache
+	//ProbeInterval interval between primary cache health probes when primary cache is down.
+	ProbeInterval time.Duration
+	down          int32
+	locker        sync.Mutex
+	quit          chan struct{}
+	switching     sync.RWMutex
+	dirty         dirtyKeys
+}
+
+func isFailure(err error) bool {
+	//Cache errors like ErrNotFound are results rather than failures,even wrapped with key context.
+	return cache.IsRetryableError(err)
+}
+
+//PrimaryDown return whether primary cache is marked as down.
+func (c *Cache) PrimaryDown() bool {
+	return atomic.LoadInt32(&c.down) == 1
+}
+
+func (c *Cache) markDown() {
+	if !atomic.CompareAndSwapInt32(&c.down, 0, 1) {
+		return
+	}
+	go c.probe()
+}
+
+//Probe check primary cache health,and switch back to primary cache if healthy.
+//Keys and counters written to secondary cache while primary cache is down are deleted from primary cache before switching back,
+//so that stale data in primary cache will not be read.
+//Return whether primary cache is healthy and switched back.
+func (c *Cache) Probe() bool {
+	_, err := c.Primary.GetBytesValue(ProbeKey)
+	if isFailure(err) {
+		return false
+	}
+	c.switching.Lock()
+	defer c.switching.Unlock()
+	if c.dirty.clean(c.Primary) != nil {
+		return false
+	}
+	atomic.StoreInt32(&c.down, 0)
+	return true
+}
+
+func (c *Cache) probe() {
+	interval := c.ProbeInterval
+	if interval <= 0 {
+		interval = DefaultProbeInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if c.Probe() {
+				return
+			}
+		case <-c.quitChan():
+			return
+		}
+	}
+}
+
+func (c *Cache) quitChan() chan struct{} {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	if c.quit == nil {
+		c.quit = make(chan struct{})
+	}
+	return c.quit
+}
+
+func (c *Cache) do(f func(sub *cache.Cache) error) error {
+	if !c.PrimaryDown() {
+		err := f(c.Primary)
+		if !isFailure(err) {
+			return err
+		}
+		c.markDown()
+	}
+	return f(c.Secondary)
+}
+
+//write send write operation of given keys to primary cache,or to secondary cache if primary cache fails.
+//Keys written to secondary cache are tracked and deleted from primary cache before switching back.
+func (c *Cache) write(counter bool, keys []string, f func(sub *cache.Cache) error) error {
+	for {
+		if !c.PrimaryDown() {
+			err := f(c.Primary)
+			if !isFailure(err) {
+				return err
+			}
+			c.markDown()
+		}
+		c.switching.RLock()
+		if !c.PrimaryDown() {
+			c.switching.RUnlock()
+			continue
+		}
+		c.dirty.add(counter, keys...)
+		err := f(c.Secondary)
+		c.switching.RUnlock()
+		return err
+	}
+}
+
+//SetBytesValue Set bytes data to cache by given key.
+//Return any error raised.
+func (c *Cache) SetBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	return c.write(false, []string{key}, func(sub *cache.Cache) error {
+		return sub.SetBytesValue(key, bytes, ttl)
+	})
+}
+
+//UpdateBytesValue Update bytes data to cache by given key only if the cache exist.
+//Return any error raised.
+func (c *Cache) UpdateBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	return c.write(false, []string{key}, func(sub *cache.Cache) error {
+		return sub.UpdateBytesValue(key, bytes, ttl)
+	})
+}
+
+//GetBytesValue Get bytes data from cache by given key.
+//Return data bytes and any error raised.
+func (c *Cache) GetBytesValue(key string) ([]byte, error) {
+	var data []byte
+	err := c.do(func(sub *cache.Cache) error {
+		var err error
+		data, err = sub.GetBytesValue(key)
+		return err
+	})
+	return data, err
+}
+
+//MGetBytesValue get multiple bytes data from cache by given keys.
+//Return data bytes map and any error if raised.
+func (c *Cache) MGetBytesValue(keys ...string) (map[string][]byte, error) {
+	var data map[string][]byte
+	err := c.do(func(sub *cache.Cache) error {
+		var err error
+		data, err = sub.MGetBytesValue(keys...)
+		return err
+	})
+	return data, err
+}
+
+//MSetBytesValue set multiple bytes data to cache with given key-value map.
+//Return  any error if raised.
+func (c *Cache) MSetBytesValue(data map[string][]byte, ttl time.Duration) error {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	return c.write(false, keys, func(sub *cache.Cache) error {
+		return sub.MSetBytesValue(data, ttl)
+	})
+}
+
+//Del Delete data in cache by given key.
+//Return any error raised.
+func (c *Cache) Del(key string) error {
+	return c.write(false, []string{key}, func(sub *cache.Cache) error {
+		return sub.Del(key)
+	})
+}
+
+//Expire set cache value expire duration by given key and ttl
+//Return any error raised.
+func (c *Cache) Expire(key string, ttl time.Duration) error {
+	return c.write(false, []string{key}, func(sub *cache.Cache) error {
+		return sub.Expire(key, ttl)
+	})
+}
+
+//SetCounter Set int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Return any error raised.
+func (c *Cache) SetCounter(key string, v int64, ttl time.Duration) error {
+	return c.write(true, []string{key}, func(sub *cache.Cache) error {
+		return sub.SetCounter(key, v, ttl)
+	})
+}
+
+//GetCounter Get int val from cache by given key.Count cache and data cache are in two independent namespace.
+//Return int data value and any error raised.
+func (c *Cache) GetCounter(key string) (int64, error) {
+	var v int64
+	err := c.do(func(sub *cache.Cache) error {
+		var err error
+		v, err = sub.GetCounter(key)
+		return err
+	})
+	return v, err
+}
+
+//IncrCounter Increase int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Return int data value and any error raised.
+func (c *Cache) IncrCounter(key string, increment int64, ttl time.Duration) (int64, error) {
+	var v int64
+	err := c.write(true, []string{key}, func(sub *cache.Cache) error {
+		var err error
+		v, err = sub.IncrCounter(key, increment, ttl)
+		return err
+	})
+	return v, err
+}
+
+//ExpireCounter set cache counter expire duration by given key and ttl
+//Return any error raised.
+func (c *Cache) ExpireCounter(key string, ttl time.Duration) error {
+	return c.write(true, []string{key}, func(sub *cache.Cache) error {
+		return sub.ExpireCounter(key, ttl)
+	})
+}
+
+//DelCounter Delete int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Return any error raised.
+func (c *Cache) DelCounter(key string) error {
+	return c.write(true, []string{key}, func(sub *cache.Cache) error {
+		return sub.DelCounter(key)
+	})
+}
+
+//SetGCErrHandler Set callback to handler error raised when gc.
+func (c *Cache) SetGCErrHandler(f func(err error)) {
+	c.Primary.SetGCErrHandler(f)
+	c.Secondary.SetGCErrHandler(f)
+}
+
+//Ping check if primary or secondary cache is reachable before context done.
+//Return error raised by secondary cache if both caches are unreachable.
+func (c *Cache) Ping(ctx context.Context) error {
+	err := c.Primary.Ping(ctx)
+	if err == nil {
+		return nil
+	}
+	return c.Secondary.Ping(ctx)
+}
+
+//Capabilities return capabilities supported by both primary and secondary caches.
+func (c *Cache) Capabilities() cache.Capabilities {
+	return c.Primary.Capabilities() & c.Secondary.Capabilities() & (cache.CapabilityMGet | cache.CapabilityCounter | cache.CapabilityFlush | cache.CapabilityPing)
+}
+
+//Close stop health probes and close both caches.
+//Return any error if raised
+func (c *Cache) Close() error {
+	close(c.quitChan())
+	err := c.Primary.Close()
+	err2 := c.Secondary.Close()
+	if err != nil {
+		return err
+	}
+	return err2
+}
+
+//Flush Delete all data in both caches.
+//Return any error if raised
+func (c *Cache) Flush() error {
+	err := c.Primary.Flush()
+	err2 := c.Secondary.Flush()
+	if err != nil {
+		return err
+	}
+	return err2
+}
+
+//Config failover cache driver config.
+type Config struct {
+	//Primary primary cache config.
+	Primary *cache.OptionConfig
+	//Secondary secondary cache config.
+	Secondary *cache.OptionConfig
+	//ProbeIntervalInSecond interval in second between primary cache health probes.
+	//DefaultProbeInterval will be used if not positive.
+	ProbeIntervalInSecond int64
+}
+
+//Create create new cache driver.
+//Return driver created and any error if raised.
+func (c *Config) Create() (cache.Driver, error) {
+	var err error
+	cc := &Cache{
+		ProbeInterval: time.Duration(c.ProbeIntervalInSecond) * time.Second,
+	}
+	cc.Primary, err = cache.NewSubCache(c.Primary)
+	if err != nil {
+		return nil, err
+	}
+	cc.Secondary, err = cache.NewSubCache(c.Secondary)
+	if err != nil {
+		return nil, err
+	}
+	return cc, nil
+}
+
+func init() {
+	cache.Register("failover", func(loader func(interface{}) error) (cache.Driver, error) {
+		c := &Config{}
+		err := loader(c)
+		if err != nil {
+			return nil, err
+		}
+		return c.Create()
+	})
+}
@@ -0,0 +1,169 @@
+package failovercache
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+	_ "github.com/herb-go/deprecated/cache/drivers/syncmapcache"
+)
+
+var errTestDown = errors.New("test cache down")
+
+type flakyDriver struct {
+	cache.Driver
+	locker sync.Mutex
+	down   bool
+}
+
+func (d *flakyDriver) setDown(down bool) {
+	d.locker.Lock()
+	defer d.locker.Unlock()
+	d.down = down
+}
+
+func (d *flakyDriver) isDown() bool {
+	d.locker.Lock()
+	defer d.locker.Unlock()
+	return d.down
+}
+
+func (d *flakyDriver) GetBytesValue(key string) ([]byte, error) {
+	if d.isDown() {
+		return nil, errTestDown
+	}
+	return d.Driver.GetBytesValue(key)
+}
+
+func (d *flakyDriver) SetBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	if d.isDown() {
+		return errTestDown
+	}
+	return d.Driver.SetBytesValue(key, bytes, ttl)
+}
+
+func newSubCache() *cache.Cache {
+	buf := bytes.NewBufferString(`{"Size":10000000}`)
+	oc := cache.NewOptionConfig()
+	oc.Driver = "syncmapcache"
+	oc.TTL = 3600
+	oc.Marshaler = "json"
+	oc.Config = json.NewDecoder(buf).Decode
+	c, err := cache.NewSubCache(oc)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func TestFailover(t *testing.T) {
+	primary := newSubCache()
+	flaky := &flakyDriver{Driver: primary.Driver}
+	primary.Driver = flaky
+	d := &Cache{
+		Primary:       primary,
+		Secondary:     newSubCache(),
+		ProbeInterval: 10 * time.Millisecond,
+	}
+	d.SetUtil(primary.Util())
+	c := cache.New()
+	c.Driver = d
+	c.TTL = time.Hour
+	defer c.Close()
+	err := c.SetBytesValue("test", []byte("primary"), cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	flaky.setDown(true)
+	_, err = c.GetBytesValue("test")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	if !d.PrimaryDown() {
+		t.Fatal(d.PrimaryDown())
+	}
+	err = c.SetBytesValue("test", []byte("secondary"), cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := c.GetBytesValue("test")
+	if err != nil || string(bs) != "secondary" {
+		t.Fatal(string(bs), err)
+	}
+	flaky.setDown(false)
+	time.Sleep(50 * time.Millisecond)
+	if d.PrimaryDown() {
+		t.Fatal(d.PrimaryDown())
+	}
+	_, err = c.GetBytesValue("test")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+}
+
+func TestFailoverDirtyKeys(t *testing.T) {
+	primary := newSubCache()
+	flaky := &flakyDriver{Driver: primary.Driver}
+	primary.Driver = flaky
+	d := &Cache{
+		Primary:       primary,
+		Secondary:     newSubCache(),
+		ProbeInterval: time.Hour,
+	}
+	d.SetUtil(primary.Util())
+	c := cache.New()
+	c.Driver = d
+	c.TTL = time.Hour
+	defer c.Close()
+	for _, k := range []string{"set", "del", "untouched"} {
+		err := c.SetBytesValue(k, []byte("primary"), cache.DefaultTTL)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := c.IncrCounter("counter", 1, cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	flaky.setDown(true)
+	err = c.SetBytesValue("set", []byte("secondary"), cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.PrimaryDown() {
+		t.Fatal(d.PrimaryDown())
+	}
+	err = c.Del("del")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.IncrCounter("counter", 5, cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Probe() {
+		t.Fatal(d.PrimaryDown())
+	}
+	flaky.setDown(false)
+	if !d.Probe() || d.PrimaryDown() {
+		t.Fatal(d.PrimaryDown())
+	}
+	for _, k := range []string{"set", "del"} {
+		_, err = c.GetBytesValue(k)
+		if !errors.Is(err, cache.ErrNotFound) {
+			t.Fatal(k, err)
+		}
+	}
+	bs, err := c.GetBytesValue("untouched")
+	if err != nil || string(bs) != "primary" {
+		t.Fatal(string(bs), err)
+	}
+	_, err = c.GetCounter("counter")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,551 @@
+//Package filecache provides cache driver which stores every entry as a file on disk.
+//Files are placed in hash sharded sub directories and written by atomic rename,
+//so it can be used to cache large blobs like rendered images which are too expensive to keep in memory.
+package filecache
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+//ErrRootRequired error raised when root directory is not configured.
+var ErrRootRequired = errors.New("filecache: root required")
+
+//MaxShardLevels max levels of sharded sub directories.
+const MaxShardLevels = 4
+
+//headerSize size of entry header.
+//Header stores expired time as 8 bytes big endian unix nano.
+const headerSize = 8
+
+//tempPrefix prefix of temporary files which are renamed to entry files after written.
+const tempPrefix = ".tmp-"
+
+//lockStripes count of lockers used to serialize writes of same entry file.
+const lockStripes = 256
+
+var defaultShardLevels = 2
+var defaultGCPeriod = 5 * time.Minute
+var defaultDirMode = os.FileMode(0700)
+
+//CounterEncoding encoding used to store counter values.
+var CounterEncoding cache.CounterEncoding = cache.BigEndianCounterEncoding{}
+
+//encodeHeader encode entry header with given ttl.
+//Zero expired time means entry never expires.
+func encodeHeader(ttl time.Duration) []byte {
+	header := make([]byte, headerSize)
+	if ttl > 0 {
+		binary.BigEndian.PutUint64(header, uint64(time.Now().Add(ttl).UnixNano()))
+	}
+	return header
+}
+
+//decodeHeader decode expired time in header of given entry.
+//Return expired time and whether entry is valid and not expired.
+func decodeHeader(bs []byte, now time.Time) (time.Time, bool) {
+	if len(bs) < headerSize {
+		return time.Time{}, false
+	}
+	var expired time.Time
+	if nano := int64(binary.BigEndian.Uint64(bs[0:headerSize])); nano != 0 {
+		expired = time.Unix(0, nano)
+		if !now.Before(expired) {
+			return expired, false
+		}
+	}
+	return expired, true
+}
+
+//Cache The file cache Driver.
+type Cache struct {
+	cache.DriverUtil
+	root         string
+	shardLevels  int
+	sync         bool
+	locks        [lockStripes]sync.Mutex
+	ticker       *time.Ticker
+	gcPeriod     time.Duration
+	quit         chan int
+	gcErrHandler func(err error)
+}
+
+//SetGCErrHandler Set callback to handler error raised when gc.
+func (c *Cache) SetGCErrHandler(f func(err error)) {
+	c.gcErrHandler = f
+	return
+}
+
+//path return entry file path and locker of given key.
+//File name is hex encoded sha256 hash of key,and every shard level uses next 2 hex chars as sub directory.
+func (c *Cache) path(key string) (string, *sync.Mutex) {
+	sum := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(sum[:])
+	paths := make([]string, 0, c.shardLevels+2)
+	paths = append(paths, c.root)
+	for i := 0; i < c.shardLevels; i++ {
+		paths = append(paths, name[i*2:i*2+2])
+	}
+	paths = append(paths, name)
+	return filepath.Join(paths...), &c.locks[sum[0]]
+}
+
+//read read entry file by given path.
+//Return data,expired time,whether entry exists and not expired and any error raised.
+func (c *Cache) read(path string) ([]byte, time.Time, bool, error) {
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, time.Time{}, false, nil
+		}
+		return nil, time.Time{}, false, err
+	}
+	expired, ok := decodeHeader(bs, time.Now())
+	if !ok {
+		return nil, expired, false, nil
+	}
+	return bs[headerSize:], expired, true, nil
+}
+
+//write write entry file by given path with given header and data.
+//Data is written to temporary file in same directory first,then renamed to entry file,
+//so readers never see partial written entry.
+func (c *Cache) write(path string, header []byte, data []byte) error {
+	dir := filepath.Dir(path)
+	err := os.MkdirAll(dir, defaultDirMode)
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(dir, tempPrefix)
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	_, err = f.Write(header)
+	if err == nil {
+		_, err = f.Write(data)
+	}
+	if err == nil && c.sync {
+		err = f.Sync()
+	}
+	closeerr := f.Close()
+	if err == nil {
+		err = closeerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+func (c *Cache) remove(path string) error {
+	err := os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+//gc delete expired entry files and stale temporary files left by interrupted writes.
+//Return last error raised.
+func (c *Cache) gc() error {
+	var lasterr error
+	now := time.Now()
+	err := filepath.Walk(c.root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if !os.IsNotExist(err) {
+				lasterr = err
+			}
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+		name := info.Name()
+		if strings.HasPrefix(name, tempPrefix) {
+			if now.Sub(info.ModTime()) > c.gcPeriod {
+				if err := c.remove(path); err != nil {
+					lasterr = err
+				}
+			}
+			return nil
+		}
+		if err := c.gcFile(path, name, now); err != nil {
+			lasterr = err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return lasterr
+}
+
+func (c *Cache) gcFile(path string, name string, now time.Time) error {
+	bs, err := hex.DecodeString(name)
+	if err != nil || len(bs) != sha256.Size {
+		return nil
+	}
+	locker := &c.locks[bs[0]]
+	locker.Lock()
+	defer locker.Unlock()
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	header := make([]byte, headerSize)
+	n, _ := f.Read(header)
+	f.Close()
+	if _, ok := decodeHeader(header[:n], now); ok {
+		return nil
+	}
+	return c.remove(path)
+}
+
+//SetBytesValue Set bytes data to cache by given key.
+//Return any error raised.
+func (c *Cache) SetBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	path, locker := c.path(key)
+	locker.Lock()
+	defer locker.Unlock()
+	return c.write(path, encodeHeader(ttl), bytes)
+}
+
+//UpdateBytesValue Update bytes data to cache by given key only if the cache exist.
+//Return any error raised.
+func (c *Cache) UpdateBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	path, locker := c.path(key)
+	locker.Lock()
+	defer locker.Unlock()
+	_, _, ok, err := c.read(path)
+	if err != nil || !ok {
+		return err
+	}
+	return c.write(path, encodeHeader(ttl), bytes)
+}
+
+//SetIfNotExists Set bytes data to cache by given key only if the key does not exist.
+//Writes are serialized in current process only.
+//Return whether data is set and any error raised.
+func (c *Cache) SetIfNotExists(key string, bytes []byte, ttl time.Duration) (bool, error) {
+	path, locker := c.path(key)
+	locker.Lock()
+	defer locker.Unlock()
+	_, _, ok, err := c.read(path)
+	if err != nil || ok {
+		return false, err
+	}
+	err = c.write(path, encodeHeader(ttl), bytes)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+//SetIfVersion Set bytes data to cache by given key only if current value version equals given version.
+//Empty version means key should not exist.
+//Writes are serialized in current process only.
+//Return whether data is set and any error raised.
+func (c *Cache) SetIfVersion(key string, bytes []byte, version string, ttl time.Duration) (bool, error) {
+	path, locker := c.path(key)
+	locker.Lock()
+	defer locker.Unlock()
+	data, _, ok, err := c.read(path)
+	if err != nil {
+		return false, err
+	}
+	if ok {
+		if version != cache.ValueVersion(data) {
+			return false, nil
+		}
+	} else if version != "" {
+		return false, nil
+	}
+	err = c.write(path, encodeHeader(ttl), bytes)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+//GetBytesValue Get bytes data from cache by given key.
+//Return data bytes and any error raised.
+func (c *Cache) GetBytesValue(key string) ([]byte, error) {
+	path, _ := c.path(key)
+	data, _, ok, err := c.read(path)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, cache.ErrNotFound
+	}
+	return data, nil
+}
+
+//MGetBytesValue get multiple bytes data from cache by given keys.
+//Return data bytes map and any error if raised.
+func (c *Cache) MGetBytesValue(keys ...string) (map[string][]byte, error) {
+	var result = make(map[string][]byte, len(keys))
+	for _, k := range keys {
+		path, _ := c.path(k)
+		data, _, ok, err := c.read(path)
+		if err != nil {
+			return result, err
+		}
+		if ok {
+			result[k] = data
+		}
+	}
+	return result, nil
+}
+
+//MSetBytesValue set multiple bytes data to cache with given key-value map.
+//Return  any error if raised.
+func (c *Cache) MSetBytesValue(data map[string][]byte, ttl time.Duration) error {
+	for k := range data {
+		err := c.SetBytesValue(k, data[k], ttl)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Capabilities return capabilities supported by cache.
+func (c *Cache) Capabilities() cache.Capabilities {
+	return cache.CapabilityCounter | cache.CapabilityFlush | cache.CapabilityTTL | cache.CapabilityNX | cache.CapabilityCAS | cache.CapabilitySize
+}
+
+//Usage return count and total bytes of entry files.
+//Expired entry files not deleted by gc yet are counted.
+//Return entry count,bytes used and any error raised.
+func (c *Cache) Usage() (int64, int64, error) {
+	var entries, bytes int64
+	err := filepath.Walk(c.root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() || strings.HasPrefix(info.Name(), tempPrefix) {
+			return nil
+		}
+		entries++
+		bytes += info.Size()
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return entries, bytes, nil
+}
+
+//Flush Delete all data in cache.
+//Root directory is kept.
+//Return any error if raised
+func (c *Cache) Flush() error {
+	files, err := ioutil.ReadDir(c.root)
+	if err != nil {
+		return err
+	}
+	for _, v := range files {
+		err = os.RemoveAll(filepath.Join(c.root, v.Name()))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Close Close cache.
+//Return any error if raised
+func (c *Cache) Close() error {
+	close(c.quit)
+	return nil
+}
+
+//Del Delete data in cache by given key.
+//Return any error raised.
+func (c *Cache) Del(key string) error {
+	path, locker := c.path(key)
+	locker.Lock()
+	defer locker.Unlock()
+	return c.remove(path)
+}
+
+//IncrCounter Increase int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Return int data value and any error raised.
+func (c *Cache) IncrCounter(key string, increment int64, ttl time.Duration) (int64, error) {
+	path, locker := c.path(key)
+	locker.Lock()
+	defer locker.Unlock()
+	var v int64
+	data, _, ok, err := c.read(path)
+	if err != nil {
+		return 0, err
+	}
+	if ok {
+		current, err := CounterEncoding.DecodeCounter(data)
+		if err == nil {
+			v = current
+		}
+	}
+	v = v + increment
+	err = c.write(path, encodeHeader(ttl), CounterEncoding.EncodeCounter(v))
+	if err != nil {
+		return 0, err
+	}
+	return v, nil
+}
+
+//SetCounter Set int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Return any error raised.
+func (c *Cache) SetCounter(key string, v int64, ttl time.Duration) error {
+	return c.SetBytesValue(key, CounterEncoding.EncodeCounter(v), ttl)
+}
+
+//GetCounter Get int val from cache by given key.Count cache and data cache are in two independent namespace.
+//Return int data value and any error raised.
+func (c *Cache) GetCounter(key string) (int64, error) {
+	data, err := c.GetBytesValue(key)
+	if err != nil {
+		return 0, err
+	}
+	v, err := CounterEncoding.DecodeCounter(data)
+	if err != nil {
+		return 0, cache.ErrNotFound
+	}
+	return v, nil
+}
+
+//DelCounter Delete int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Return any error raised.
+func (c *Cache) DelCounter(key string) error {
+	return c.Del(key)
+}
+
+//Expire set cache value expire duration by given key and ttl
+func (c *Cache) Expire(key string, ttl time.Duration) error {
+	path, locker := c.path(key)
+	locker.Lock()
+	defer locker.Unlock()
+	data, _, ok, err := c.read(path)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return cache.ErrNotFound
+	}
+	return c.write(path, encodeHeader(ttl), data)
+}
+
+//ExpireCounter set cache counter  expire duration by given key and ttl
+func (c *Cache) ExpireCounter(key string, ttl time.Duration) error {
+	return c.Expire(key, ttl)
+}
+
+//GetTTL get remaining ttl of given key.
+//Return ttl and any error raised.
+//Zero ttl means entry never expires.
+func (c *Cache) GetTTL(key string) (time.Duration, error) {
+	path, _ := c.path(key)
+	_, expired, ok, err := c.read(path)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, cache.ErrNotFound
+	}
+	if expired.IsZero() {
+		return 0, nil
+	}
+	return time.Until(expired), nil
+}
+
+//Config Cache driver config.
+type Config struct {
+	//Root root directory which stores entry files.
+	//Directory is created if not exists.
+	Root string
+	//ShardLevels levels of hash sharded sub directories.Default value is 2.
+	//Every level has up to 256 sub directories.
+	ShardLevels int
+	//Sync whether sync entry file to disk before renamed.
+	//Written data survives system crash,but write performance is reduced.
+	Sync bool
+	//GCPeriodInSecond period of gc.Default value is 5 minute.
+	GCPeriodInSecond int64
+}
+
+//Create create new cache driver.
+//Return driver created and any error if raised.
+func (config *Config) Create() (cache.Driver, error) {
+	if config.Root == "" {
+		return nil, ErrRootRequired
+	}
+	err := os.MkdirAll(config.Root, defaultDirMode)
+	if err != nil {
+		return nil, err
+	}
+	c := &Cache{
+		root:        config.Root,
+		shardLevels: config.ShardLevels,
+		sync:        config.Sync,
+		quit:        make(chan int),
+		gcPeriod:    time.Duration(config.GCPeriodInSecond) * time.Second,
+	}
+	if c.shardLevels <= 0 {
+		c.shardLevels = defaultShardLevels
+	}
+	if c.shardLevels > MaxShardLevels {
+		c.shardLevels = MaxShardLevels
+	}
+	if c.gcPeriod <= 0 {
+		c.gcPeriod = defaultGCPeriod
+	}
+	c.ticker = time.NewTicker(c.gcPeriod)
+	go func() {
+		for {
+			select {
+			case <-c.ticker.C:
+				err := c.gc()
+				if err != nil && c.gcErrHandler != nil {
+					c.gcErrHandler(err)
+				}
+			case <-c.quit:
+				c.ticker.Stop()
+				return
+			}
+		}
+	}()
+	return c, nil
+}
+
+func init() {
+	cache.Register("filecache", func(loader func(interface{}) error) (cache.Driver, error) {
+		c := &Config{}
+		err := loader(c)
+		if err != nil {
+			return nil, err
+		}
+		return c.Create()
+	})
+}
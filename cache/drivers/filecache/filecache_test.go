@@ -0,0 +1,125 @@
+package filecache
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+func newTestCache(t *testing.T, root string) *cache.Cache {
+	c := cache.New()
+	oc := cache.NewOptionConfig()
+	oc.Driver = "filecache"
+	oc.TTL = 3600
+	oc.Config = func(v interface{}) error {
+		v.(*Config).Root = root
+		return nil
+	}
+	oc.Marshaler = "json"
+	err := c.Init(oc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestFileCache(t *testing.T) {
+	root, err := ioutil.TempDir("", "filecache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	c := newTestCache(t, root)
+	err = c.Set("test", "value", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.Set("expired", "value", time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.IncrCounter("counter", 2, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := c.IncrCounter("counter", 3, time.Hour)
+	if err != nil || v != 5 {
+		t.Fatal(v, err)
+	}
+	ok, err := c.SetIfNotExists("test", []byte("new"), time.Hour)
+	if ok || err != nil {
+		t.Fatal(ok, err)
+	}
+	tmp, err := ioutil.TempFile(root, tempPrefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmp.Close()
+	old := time.Now().Add(-time.Hour)
+	err = os.Chtimes(tmp.Name(), old, old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	var result string
+	err = c.Get("expired", &result)
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	d := c.Driver.(*Cache)
+	entries, _, err := d.Usage()
+	if err != nil || entries != 3 {
+		t.Fatal(entries, err)
+	}
+	err = d.gc()
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, bytes, err := d.Usage()
+	if err != nil || entries != 2 || bytes == 0 {
+		t.Fatal(entries, bytes, err)
+	}
+	if _, err = os.Stat(tmp.Name()); !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	path, _ := d.path("test")
+	rel, err := filepath.Rel(root, path)
+	if err != nil || strings.Count(rel, string(filepath.Separator)) != defaultShardLevels {
+		t.Fatal(rel, err)
+	}
+	err = c.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c = newTestCache(t, root)
+	defer c.Close()
+	err = c.Get("test", &result)
+	if err != nil || result != "value" {
+		t.Fatal(result, err)
+	}
+	v, err = c.GetCounter("counter")
+	if err != nil || v != 5 {
+		t.Fatal(v, err)
+	}
+	ttl, err := c.GetTTL("test")
+	if err != nil || ttl <= 0 || ttl > time.Hour {
+		t.Fatal(ttl, err)
+	}
+	err = c.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.Get("test", &result)
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(root); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -3,20 +3,23 @@
 package freecache
 
 import (
+	"errors"
 	"time"
 
-	"encoding/binary"
-
 	"sync"
 
 	"github.com/coocood/freecache"
 	"github.com/herb-go/deprecated/cache"
 )
 
+//CounterEncoding encoding used to store counter values.
+var CounterEncoding cache.CounterEncoding = cache.BigEndianCounterEncoding{}
+
 //Cache The freecache cache Driver.
 type Cache struct {
 	cache.DriverUtil
 	freecache    *freecache.Cache
+	size         int64
 	gcErrHandler func(err error)
 	lock         sync.Mutex
 }
@@ -65,13 +68,25 @@ func (c *Cache) GetBytesValue(key string) ([]byte, error) {
 	return bytes, err
 }
 
+//Capabilities return capabilities supported by cache.
+func (c *Cache) Capabilities() cache.Capabilities {
+	return cache.CapabilityMGet | cache.CapabilityCounter | cache.CapabilityFlush | cache.CapabilityTTL | cache.CapabilitySize
+}
+
+//Usage return entry count and bytes used.
+//Freecache allocates all memory when created,so bytes used is the configured cache size.
+//Return entry count,bytes used and any error raised.
+func (c *Cache) Usage() (int64, int64, error) {
+	return c.freecache.EntryCount(), c.size, nil
+}
+
 //MGetBytesValue get multiple bytes data from cache by given keys.
 //Return data bytes map and any error if raised.
 func (c *Cache) MGetBytesValue(keys ...string) (map[string][]byte, error) {
 	var result = make(map[string][]byte, len(keys))
 	for k := range keys {
 		b, err := c.GetBytesValue(keys[k])
-		if err == cache.ErrNotFound {
+		if errors.Is(err, cache.ErrNotFound) {
 		} else if err != nil {
 			return result, err
 		} else {
@@ -131,17 +146,18 @@ func (c *Cache) IncrCounter(key string, increment int64, ttl time.Duration) (int
 	defer locker.Unlock()
 
 	bytes, err := c.freecache.Get([]byte(key))
-	if err == freecache.ErrNotFound || bytes == nil || len(bytes) != 8 {
+	if err == freecache.ErrNotFound || bytes == nil {
 		v = 0
 	} else if err != nil {
 		return v, err
 	} else {
-		v = int64(binary.BigEndian.Uint64(bytes[0:8]))
+		v, err = CounterEncoding.DecodeCounter(bytes)
+		if err != nil {
+			v = 0
+		}
 	}
 	v = v + increment
-	bytes = make([]byte, 8)
-	binary.BigEndian.PutUint64(bytes, uint64(v))
-	err = c.freecache.Set([]byte(key), bytes, int(ttl/time.Second))
+	err = c.freecache.Set([]byte(key), CounterEncoding.EncodeCounter(v), int(ttl/time.Second))
 	return v, err
 }
 
@@ -153,9 +169,7 @@ func (c *Cache) SetCounter(key string, v int64, ttl time.Duration) error {
 	locker.Lock()
 	defer locker.Unlock()
 
-	bytes := make([]byte, 8)
-	binary.BigEndian.PutUint64(bytes, uint64(v))
-	err = c.freecache.Set([]byte(key), bytes, int(ttl/time.Second))
+	err = c.freecache.Set([]byte(key), CounterEncoding.EncodeCounter(v), int(ttl/time.Second))
 	return err
 }
 
@@ -169,13 +183,16 @@ func (c *Cache) GetCounter(key string) (int64, error) {
 
 	bytes, err := c.freecache.Get([]byte(key))
 
-	if err == freecache.ErrNotFound || bytes == nil || len(bytes) != 8 {
+	if err == freecache.ErrNotFound || bytes == nil {
 		err = cache.ErrNotFound
 	}
 	if err != nil {
 		return 0, err
 	}
-	v = int64(binary.BigEndian.Uint64(bytes[0:8]))
+	v, err = CounterEncoding.DecodeCounter(bytes)
+	if err != nil {
+		return 0, cache.ErrNotFound
+	}
 	return v, nil
 }
 
@@ -208,6 +225,19 @@ func (c *Cache) Expire(key string, ttl time.Duration) error {
 	return err
 }
 
+//GetTTL get remaining ttl of given key.
+//Return ttl and any error raised.
+func (c *Cache) GetTTL(key string) (time.Duration, error) {
+	ttl, err := c.freecache.TTL([]byte(key))
+	if err == freecache.ErrNotFound {
+		return 0, cache.ErrNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(ttl) * time.Second, nil
+}
+
 //ExpireCounter set cache counter  expire duration by given key and ttl
 func (c *Cache) ExpireCounter(key string, ttl time.Duration) error {
 	locker, _ := c.Util().Locker(key)
@@ -238,6 +268,7 @@ type Config struct {
 func (config *Config) Create() (cache.Driver, error) {
 	cache := Cache{
 		freecache: freecache.NewCache(config.Size),
+		size:      int64(config.Size),
 	}
 	return &cache, nil
 }
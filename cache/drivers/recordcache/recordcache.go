@@ -0,0 +1,439 @@
+//Package recordcache provides a test-support cache driver which records every operation,
+//and can replay canned responses,so packages built on cache.Cacheable can be unit-tested without real drivers.
+package recordcache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+//Operation names recorded besides cache operation names like cache.OpGet.
+const (
+	OpExpireCounter = "expirecounter"
+	OpGetTTL        = "getttl"
+	OpClose         = "close"
+)
+
+//Operation operation recorded or canned response to replay.
+type Operation struct {
+	//Op operation name,such as cache.OpGet and cache.OpSetCounter.
+	Op string
+	//Key driver key,which includes key prefix added by cache.
+	Key string
+	//Value value set,or value returned by get operation.
+	Value []byte
+	//Counter counter value set,increment given,or counter value returned.
+	Counter int64
+	//TTL ttl given,or ttl returned by OpGetTTL.
+	TTL time.Duration
+	//Err error returned.
+	Err error
+}
+
+func (o *Operation) String() string {
+	return fmt.Sprintf("%s %q value=%q counter=%d ttl=%s err=%v", o.Op, o.Key, o.Value, o.Counter, o.TTL, o.Err)
+}
+
+//match check if operation matches expected operation.
+//Value,counter,ttl and error are only compared if set in expected operation.
+func (o *Operation) match(expected *Operation) bool {
+	if o.Op != expected.Op || o.Key != expected.Key {
+		return false
+	}
+	if expected.Value != nil && !bytes.Equal(o.Value, expected.Value) {
+		return false
+	}
+	if expected.Counter != 0 && o.Counter != expected.Counter {
+		return false
+	}
+	if expected.TTL != 0 && o.TTL != expected.TTL {
+		return false
+	}
+	if expected.Err != nil && !errors.Is(o.Err, expected.Err) {
+		return false
+	}
+	return true
+}
+
+type stubKey struct {
+	op  string
+	key string
+}
+
+//Cache The record cache driver.
+//Operations are forwarded to backend driver if no canned response matches.
+//Every get operation misses if backend driver is nil.
+type Cache struct {
+	cache.DriverUtil
+	//Backend driver operations are forwarded to.
+	Backend    cache.Driver
+	locker     sync.Mutex
+	operations []*Operation
+	stubs      map[stubKey][]*Operation
+}
+
+//New create new record cache driver with given backend driver.
+//Every get operation misses if backend is nil.
+func New(backend cache.Driver) *Cache {
+	return &Cache{
+		Backend: backend,
+		stubs:   map[stubKey][]*Operation{},
+	}
+}
+
+//Stub add canned responses.
+//Operation with same op and key will be answered by Value,Counter,TTL and Err of canned responses in order,
+//and last response will be used repeatedly.
+//Operations recorded by other record cache can be replayed by stubbing.
+//MGetBytesValue is answered by cache.OpGet responses.
+func (c *Cache) Stub(ops ...*Operation) {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	for _, v := range ops {
+		k := stubKey{op: v.Op, key: v.Key}
+		c.stubs[k] = append(c.stubs[k], v)
+	}
+}
+
+//stub return next canned response of given op and key.
+//Return nil if no canned response.
+func (c *Cache) stub(op string, key string) *Operation {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	k := stubKey{op: op, key: key}
+	responses := c.stubs[k]
+	if len(responses) == 0 {
+		return nil
+	}
+	if len(responses) > 1 {
+		c.stubs[k] = responses[1:]
+	}
+	return responses[0]
+}
+
+func (c *Cache) record(op *Operation) {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	c.operations = append(c.operations, op)
+}
+
+//Operations return operations recorded in order.
+func (c *Cache) Operations() []*Operation {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	result := make([]*Operation, len(c.operations))
+	copy(result, c.operations)
+	return result
+}
+
+//Reset clear recorded operations and canned responses.
+func (c *Cache) Reset() {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	c.operations = nil
+	c.stubs = map[stubKey][]*Operation{}
+}
+
+//Expect check if recorded operations match given operations in order.
+//Value,counter,ttl and error are only compared if set in given operation.
+//Return error describing first mismatch.
+func (c *Cache) Expect(ops ...*Operation) error {
+	recorded := c.Operations()
+	for k, v := range ops {
+		if k >= len(recorded) {
+			return fmt.Errorf("recordcache: operation %d expected %s,got none", k, v)
+		}
+		if !recorded[k].match(v) {
+			return fmt.Errorf("recordcache: operation %d expected %s,got %s", k, v, recorded[k])
+		}
+	}
+	if len(recorded) > len(ops) {
+		return fmt.Errorf("recordcache: unexpected operation %d %s", len(ops), recorded[len(ops)])
+	}
+	return nil
+}
+
+//SetBytesValue Set bytes data to cache by given key.
+//Return any error raised.
+func (c *Cache) SetBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	var err error
+	if s := c.stub(cache.OpSet, key); s != nil {
+		err = s.Err
+	} else if c.Backend != nil {
+		err = c.Backend.SetBytesValue(key, bytes, ttl)
+	}
+	c.record(&Operation{Op: cache.OpSet, Key: key, Value: bytes, TTL: ttl, Err: err})
+	return err
+}
+
+//UpdateBytesValue Update bytes data to cache by given key only if the cache exist.
+//Return any error raised.
+func (c *Cache) UpdateBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	var err error
+	if s := c.stub(cache.OpUpdate, key); s != nil {
+		err = s.Err
+	} else if c.Backend != nil {
+		err = c.Backend.UpdateBytesValue(key, bytes, ttl)
+	}
+	c.record(&Operation{Op: cache.OpUpdate, Key: key, Value: bytes, TTL: ttl, Err: err})
+	return err
+}
+
+func (c *Cache) get(key string) ([]byte, error) {
+	if s := c.stub(cache.OpGet, key); s != nil {
+		if s.Err == nil && s.Value == nil {
+			return nil, cache.ErrNotFound
+		}
+		return s.Value, s.Err
+	}
+	if c.Backend != nil {
+		return c.Backend.GetBytesValue(key)
+	}
+	return nil, cache.ErrNotFound
+}
+
+//GetBytesValue Get bytes data from cache by given key.
+//Return data bytes and any error raised.
+func (c *Cache) GetBytesValue(key string) ([]byte, error) {
+	data, err := c.get(key)
+	c.record(&Operation{Op: cache.OpGet, Key: key, Value: data, Err: err})
+	return data, err
+}
+
+//MGetBytesValue get multiple bytes data from cache by given keys.
+//Every key is recorded as an cache.OpMGet operation.
+//Return data bytes map and any error if raised.
+func (c *Cache) MGetBytesValue(keys ...string) (map[string][]byte, error) {
+	var result = make(map[string][]byte, len(keys))
+	for _, k := range keys {
+		data, err := c.get(k)
+		c.record(&Operation{Op: cache.OpMGet, Key: k, Value: data, Err: err})
+		if errors.Is(err, cache.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		result[k] = data
+	}
+	return result, nil
+}
+
+//MSetBytesValue set multiple bytes data to cache with given key-value map.
+//Every key is recorded as an cache.OpMSet operation.
+//Return  any error if raised.
+func (c *Cache) MSetBytesValue(data map[string][]byte, ttl time.Duration) error {
+	for k := range data {
+		var err error
+		if s := c.stub(cache.OpMSet, k); s != nil {
+			err = s.Err
+		} else if c.Backend != nil {
+			err = c.Backend.SetBytesValue(k, data[k], ttl)
+		}
+		c.record(&Operation{Op: cache.OpMSet, Key: k, Value: data[k], TTL: ttl, Err: err})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Del Delete data in cache by given key.
+//Return any error raised.
+func (c *Cache) Del(key string) error {
+	var err error
+	if s := c.stub(cache.OpDel, key); s != nil {
+		err = s.Err
+	} else if c.Backend != nil {
+		err = c.Backend.Del(key)
+	}
+	c.record(&Operation{Op: cache.OpDel, Key: key, Err: err})
+	return err
+}
+
+//Expire set cache value expire duration by given key and ttl
+//Return any error raised.
+func (c *Cache) Expire(key string, ttl time.Duration) error {
+	var err error
+	if s := c.stub(cache.OpExpire, key); s != nil {
+		err = s.Err
+	} else if c.Backend != nil {
+		err = c.Backend.Expire(key, ttl)
+	}
+	c.record(&Operation{Op: cache.OpExpire, Key: key, TTL: ttl, Err: err})
+	return err
+}
+
+//GetTTL get remaining ttl of given key.
+//Return ttl and any error raised.
+func (c *Cache) GetTTL(key string) (time.Duration, error) {
+	var ttl time.Duration
+	var err error
+	if s := c.stub(OpGetTTL, key); s != nil {
+		ttl, err = s.TTL, s.Err
+	} else if d, ok := c.Backend.(cache.TTLGetter); ok {
+		ttl, err = d.GetTTL(key)
+	} else if c.Backend != nil {
+		err = cache.ErrFeatureNotSupported
+	} else {
+		err = cache.ErrNotFound
+	}
+	c.record(&Operation{Op: OpGetTTL, Key: key, TTL: ttl, Err: err})
+	return ttl, err
+}
+
+//SetCounter Set int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Return any error raised.
+func (c *Cache) SetCounter(key string, v int64, ttl time.Duration) error {
+	var err error
+	if s := c.stub(cache.OpSetCounter, key); s != nil {
+		err = s.Err
+	} else if c.Backend != nil {
+		err = c.Backend.SetCounter(key, v, ttl)
+	}
+	c.record(&Operation{Op: cache.OpSetCounter, Key: key, Counter: v, TTL: ttl, Err: err})
+	return err
+}
+
+//GetCounter Get int val from cache by given key.Count cache and data cache are in two independent namespace.
+//Return int data value and any error raised.
+func (c *Cache) GetCounter(key string) (int64, error) {
+	var v int64
+	var err error
+	if s := c.stub(cache.OpGetCounter, key); s != nil {
+		v, err = s.Counter, s.Err
+	} else if c.Backend != nil {
+		v, err = c.Backend.GetCounter(key)
+	} else {
+		err = cache.ErrNotFound
+	}
+	c.record(&Operation{Op: cache.OpGetCounter, Key: key, Counter: v, Err: err})
+	return v, err
+}
+
+//IncrCounter Increase int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Increment is recorded as counter,and counter value returned can be stubbed.
+//Return int data value and any error raised.
+func (c *Cache) IncrCounter(key string, increment int64, ttl time.Duration) (int64, error) {
+	var v int64
+	var err error
+	if s := c.stub(cache.OpIncrCounter, key); s != nil {
+		v, err = s.Counter, s.Err
+	} else if c.Backend != nil {
+		v, err = c.Backend.IncrCounter(key, increment, ttl)
+	} else {
+		v = increment
+	}
+	c.record(&Operation{Op: cache.OpIncrCounter, Key: key, Counter: increment, TTL: ttl, Err: err})
+	return v, err
+}
+
+//ExpireCounter set cache counter  expire duration by given key and ttl
+//Return any error raised.
+func (c *Cache) ExpireCounter(key string, ttl time.Duration) error {
+	var err error
+	if s := c.stub(OpExpireCounter, key); s != nil {
+		err = s.Err
+	} else if c.Backend != nil {
+		err = c.Backend.ExpireCounter(key, ttl)
+	}
+	c.record(&Operation{Op: OpExpireCounter, Key: key, TTL: ttl, Err: err})
+	return err
+}
+
+//DelCounter Delete int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Return any error raised.
+func (c *Cache) DelCounter(key string) error {
+	var err error
+	if s := c.stub(cache.OpDelCounter, key); s != nil {
+		err = s.Err
+	} else if c.Backend != nil {
+		err = c.Backend.DelCounter(key)
+	}
+	c.record(&Operation{Op: cache.OpDelCounter, Key: key, Err: err})
+	return err
+}
+
+//SetGCErrHandler Set callback to handler error raised when gc.
+func (c *Cache) SetGCErrHandler(f func(err error)) {
+	if c.Backend != nil {
+		c.Backend.SetGCErrHandler(f)
+	}
+}
+
+//Ping check if backend is reachable before context done.
+//Return any error raised.
+func (c *Cache) Ping(ctx context.Context) error {
+	if d, ok := c.Backend.(cache.Pinger); ok {
+		return d.Ping(ctx)
+	}
+	return nil
+}
+
+//Capabilities return capabilities supported by cache.
+func (c *Cache) Capabilities() cache.Capabilities {
+	return cache.CapabilityMGet | cache.CapabilityCounter | cache.CapabilityFlush | cache.CapabilityTTL | cache.CapabilityPing
+}
+
+//Flush Delete all data in cache.
+//Return any error if raised
+func (c *Cache) Flush() error {
+	var err error
+	if s := c.stub(cache.OpFlush, ""); s != nil {
+		err = s.Err
+	} else if c.Backend != nil {
+		err = c.Backend.Flush()
+	}
+	c.record(&Operation{Op: cache.OpFlush, Err: err})
+	return err
+}
+
+//Close Close cache.
+//Return any error if raised
+func (c *Cache) Close() error {
+	var err error
+	if c.Backend != nil {
+		err = c.Backend.Close()
+	}
+	c.record(&Operation{Op: OpClose, Err: err})
+	return err
+}
+
+//Config Cache driver config.
+type Config struct {
+	//Backend optional backend cache driver config.
+	//Every get operation misses if backend is not set.
+	Backend *cache.OptionConfig
+}
+
+//Create create new cache driver.
+//Return driver created and any error if raised.
+func (c *Config) Create() (cache.Driver, error) {
+	if c.Backend == nil {
+		return New(nil), nil
+	}
+	backend, err := cache.NewSubCache(c.Backend)
+	if err != nil {
+		return nil, err
+	}
+	return New(backend), nil
+}
+
+func init() {
+	cache.Register("recordcache", func(loader func(interface{}) error) (cache.Driver, error) {
+		c := &Config{}
+		if loader != nil {
+			err := loader(c)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return c.Create()
+	})
+}
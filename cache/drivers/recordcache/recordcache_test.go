@@ -0,0 +1,152 @@
+package recordcache
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+	_ "github.com/herb-go/deprecated/cache/drivers/syncmapcache"
+)
+
+func newTestBackendConfig() *cache.OptionConfig {
+	oc := cache.NewOptionConfig()
+	oc.Driver = "syncmapcache"
+	oc.TTL = 3600
+	oc.Marshaler = "json"
+	oc.Config = json.NewDecoder(bytes.NewBufferString(`{"Size":10000000}`)).Decode
+	return oc
+}
+
+func newTestCache(backend *cache.OptionConfig) (*cache.Cache, *Cache) {
+	c := cache.New()
+	oc := cache.NewOptionConfig()
+	oc.Driver = "recordcache"
+	oc.TTL = 3600
+	oc.Marshaler = "json"
+	oc.Config = func(v interface{}) error {
+		v.(*Config).Backend = backend
+		return nil
+	}
+	err := c.Init(oc)
+	if err != nil {
+		panic(err)
+	}
+	return c, c.Driver.(*Cache)
+}
+
+func TestRecord(t *testing.T) {
+	c, d := newTestCache(newTestBackendConfig())
+	defer c.Close()
+	err := c.SetBytesValue("test", []byte("value"), time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := c.GetBytesValue("test")
+	if err != nil || string(bs) != "value" {
+		t.Fatal(string(bs), err)
+	}
+	err = c.Del("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.GetBytesValue("test")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	_, err = d.IncrCounter("counter", 2, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = d.Expect(
+		&Operation{Op: cache.OpSet, Key: cache.Key("test"), Value: []byte("value"), TTL: time.Minute},
+		&Operation{Op: cache.OpGet, Key: cache.Key("test"), Value: []byte("value")},
+		&Operation{Op: cache.OpDel, Key: cache.Key("test")},
+		&Operation{Op: cache.OpGet, Key: cache.Key("test"), Err: cache.ErrNotFound},
+		&Operation{Op: cache.OpIncrCounter, Key: "counter", Counter: 2},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Expect(&Operation{Op: cache.OpSet, Key: cache.Key("test")}) == nil {
+		t.Fatal("unexpected operations should fail expectation")
+	}
+	if d.Expect(
+		&Operation{Op: cache.OpSet, Key: cache.Key("test"), Value: []byte("other")},
+	) == nil {
+		t.Fatal("mismatched value should fail expectation")
+	}
+	d.Reset()
+	if len(d.Operations()) != 0 {
+		t.Fatal(d.Operations())
+	}
+}
+
+func TestReplay(t *testing.T) {
+	recorder, rd := newTestCache(newTestBackendConfig())
+	defer recorder.Close()
+	err := recorder.Set("test", "value", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result string
+	err = recorder.Get("test", &result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = recorder.Get("notexist", &result)
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	c, d := newTestCache(nil)
+	defer c.Close()
+	d.Stub(rd.Operations()...)
+	result = ""
+	err = c.Get("test", &result)
+	if err != nil || result != "value" {
+		t.Fatal(result, err)
+	}
+	err = c.Get("notexist", &result)
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	err = c.Get("unstubbed", &result)
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+}
+
+func TestStub(t *testing.T) {
+	errTest := errors.New("test error")
+	c, d := newTestCache(nil)
+	defer c.Close()
+	d.Stub(
+		&Operation{Op: cache.OpIncrCounter, Key: "counter", Counter: 10},
+		&Operation{Op: cache.OpIncrCounter, Key: "counter", Counter: 11},
+		&Operation{Op: cache.OpSet, Key: cache.Key("fail"), Err: errTest},
+	)
+	for _, expected := range []int64{10, 11, 11} {
+		v, err := d.IncrCounter("counter", 1, time.Minute)
+		if err != nil || v != expected {
+			t.Fatal(v, err)
+		}
+	}
+	err := c.SetBytesValue("fail", []byte("value"), time.Minute)
+	if !errors.Is(err, errTest) {
+		t.Fatal(err)
+	}
+	err = c.SetBytesValue("ok", []byte("value"), time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := c.MGetBytesValue("a", "b")
+	if err != nil || len(data) != 0 {
+		t.Fatal(data, err)
+	}
+	ops := d.Operations()
+	if len(ops) != 7 || ops[3].Err != errTest || ops[4].Key != cache.Key("ok") || ops[6].Op != cache.OpMGet {
+		t.Fatal(ops)
+	}
+}
@@ -0,0 +1,64 @@
+package remotecache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+//HTTPTransport remote cache transport which calls server by http with json body.
+type HTTPTransport struct {
+	//Client http client used.
+	Client *http.Client
+	//URL base url of remote cache server.
+	URL string
+	//Token token sent by bearer authorization header.
+	Token string
+}
+
+//Call call given operation with given request on remote cache server.
+//Return response and any transport error raised.
+func (t *HTTPTransport) Call(ctx context.Context, op string, req *Request) (*Response, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(t.URL, "/")+"/"+op, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set("Content-Type", "application/json")
+	if t.Token != "" {
+		r.Header.Set("Authorization", "Bearer "+t.Token)
+	}
+	hr, err := t.Client.Do(r)
+	if err != nil {
+		return nil, err
+	}
+	defer hr.Body.Close()
+	resp := &Response{}
+	err = json.NewDecoder(hr.Body).Decode(resp)
+	if err != nil || (hr.StatusCode != http.StatusOK && resp.Error == "") {
+		return nil, fmt.Errorf("remotecache: http status %d", hr.StatusCode)
+	}
+	return resp, nil
+}
+
+//Close close transport.
+func (t *HTTPTransport) Close() error {
+	t.Client.CloseIdleConnections()
+	return nil
+}
+
+func init() {
+	RegisterTransport("http", func(c *Config) (Transport, error) {
+		return &HTTPTransport{
+			Client: &http.Client{},
+			URL:    c.Address,
+			Token:  c.Token,
+		}, nil
+	})
+}
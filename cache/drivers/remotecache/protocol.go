@@ -0,0 +1,123 @@
+package remotecache
+
+import (
+	"errors"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+//Operation names served by remote cache server besides cache operation names like cache.OpGet.
+const (
+	OpExpireCounter = "expirecounter"
+	OpGetTTL        = "getttl"
+	OpSetNX         = "setnx"
+	OpPing          = "ping"
+	OpCapabilities  = "capabilities"
+)
+
+//Operations all operations served by remote cache server.
+var Operations = []string{
+	cache.OpGet,
+	cache.OpSet,
+	cache.OpUpdate,
+	cache.OpDel,
+	cache.OpMGet,
+	cache.OpMSet,
+	cache.OpExpire,
+	cache.OpIncrCounter,
+	cache.OpSetCounter,
+	cache.OpGetCounter,
+	cache.OpDelCounter,
+	OpExpireCounter,
+	OpGetTTL,
+	OpSetNX,
+	cache.OpFlush,
+	OpPing,
+	OpCapabilities,
+}
+
+//Request remote cache request.
+//Bytes values are encoded as base64 strings in json.
+type Request struct {
+	//Key key to operate.
+	Key string `json:",omitempty"`
+	//Keys keys to get by mget operation.
+	Keys []string `json:",omitempty"`
+	//Value bytes value to set.
+	Value []byte `json:",omitempty"`
+	//Values bytes values to set by mset operation.
+	Values map[string][]byte `json:",omitempty"`
+	//Counter counter value to set or increment to increase.
+	Counter int64 `json:",omitempty"`
+	//TTL ttl in nanosecond.
+	TTL int64 `json:",omitempty"`
+}
+
+//Response remote cache response.
+type Response struct {
+	//Value bytes value got.
+	Value []byte `json:",omitempty"`
+	//Values bytes values got by mget operation.
+	Values map[string][]byte `json:",omitempty"`
+	//Counter counter value got.
+	Counter int64 `json:",omitempty"`
+	//TTL ttl got in nanosecond.
+	TTL int64 `json:",omitempty"`
+	//OK whether setnx operation set value.
+	OK bool `json:",omitempty"`
+	//Capabilities comma separated names of capabilities supported by server cache.
+	Capabilities string `json:",omitempty"`
+	//Error error code.Empty if no error raised.
+	Error string `json:",omitempty"`
+	//Message error message.
+	Message string `json:",omitempty"`
+}
+
+//ErrUnauthorized error raised if request token is not accepted by server.
+var ErrUnauthorized = errors.New("remotecache: unauthorized")
+
+//ErrUnknownOperation error raised if operation is not served by server.
+var ErrUnknownOperation = errors.New("remotecache: unknown operation")
+
+//ErrorCodeUnknown error code of errors not listed in ErrorCodes.
+const ErrorCodeUnknown = "error"
+
+//ErrorCodes error codes of errors which are kept between server and client.
+var ErrorCodes = map[string]error{
+	"notfound":            cache.ErrNotFound,
+	"notsupported":        cache.ErrFeatureNotSupported,
+	"entrytoolarge":       cache.ErrEntryTooLarge,
+	"keytoolarge":         cache.ErrKeyTooLarge,
+	"keyunavailable":      cache.ErrKeyUnavailable,
+	"ttlnotavaliable":     cache.ErrTTLNotAvaliable,
+	"invalidcountervalue": cache.ErrInvalidCounterValue,
+	"unauthorized":        ErrUnauthorized,
+	"unknownoperation":    ErrUnknownOperation,
+}
+
+//SetError set error code and message of response by given error.
+func (r *Response) SetError(err error) {
+	if err == nil {
+		return
+	}
+	r.Error = ErrorCodeUnknown
+	r.Message = err.Error()
+	for code, e := range ErrorCodes {
+		if errors.Is(err, e) {
+			r.Error = code
+			return
+		}
+	}
+}
+
+//Err return error by response error code.
+//Return nil if no error raised.
+func (r *Response) Err() error {
+	if r.Error == "" {
+		return nil
+	}
+	if err, ok := ErrorCodes[r.Error]; ok {
+		return err
+	}
+	return errors.New("remotecache: " + r.Message)
+}
@@ -0,0 +1,327 @@
+//Package remotecache provides remote cache server which exposes cache operations over http or other transports,
+//and client driver which uses remote cache server as cache backend,
+//so non-Go services and sidecars can share same cache.
+package remotecache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+//DefaultTimeout default timeout of remote calls.
+const DefaultTimeout = 5 * time.Second
+
+//DefaultTransport default transport name.
+const DefaultTransport = "http"
+
+//ErrAddressRequired error raised if remote cache address is empty.
+var ErrAddressRequired = errors.New("remotecache: address required")
+
+//Transport remote cache transport interface.
+type Transport interface {
+	//Call call given operation with given request on remote cache server.
+	//Return response and any transport error raised.
+	Call(ctx context.Context, op string, req *Request) (*Response, error)
+	//Close close transport.
+	Close() error
+}
+
+//TransportFactory create transport with given config.
+//Return transport created and any error if raised.
+type TransportFactory func(c *Config) (Transport, error)
+
+var (
+	transportsMu sync.RWMutex
+	transports   = map[string]TransportFactory{}
+)
+
+//RegisterTransport makes a transport factory available by the provided name.
+//If RegisterTransport is called twice with the same name or if factory is nil,
+//it panics.
+func RegisterTransport(name string, f TransportFactory) {
+	transportsMu.Lock()
+	defer transportsMu.Unlock()
+	if f == nil {
+		panic(errors.New("remotecache: RegisterTransport factory is nil"))
+	}
+	if _, dup := transports[name]; dup {
+		panic(errors.New("remotecache: RegisterTransport called twice for transport " + name))
+	}
+	transports[name] = f
+}
+
+//NewTransport create new transport with given config.
+//Return transport created and any error if raised.
+func NewTransport(c *Config) (Transport, error) {
+	name := c.Transport
+	if name == "" {
+		name = DefaultTransport
+	}
+	transportsMu.RLock()
+	f, ok := transports[name]
+	transportsMu.RUnlock()
+	if !ok {
+		return nil, errors.New("remotecache: unknown transport " + name + " (forgotten import?)")
+	}
+	return f(c)
+}
+
+var capabilityNames = map[string]cache.Capabilities{
+	"mget":    cache.CapabilityMGet,
+	"counter": cache.CapabilityCounter,
+	"flush":   cache.CapabilityFlush,
+	"ttl":     cache.CapabilityTTL,
+	"nx":      cache.CapabilityNX,
+	"ping":    cache.CapabilityPing,
+}
+
+//Cache The remote cache driver.
+type Cache struct {
+	cache.DriverUtil
+	//Transport transport used to call remote cache server.
+	Transport Transport
+	//Timeout timeout of remote calls.
+	Timeout          time.Duration
+	capabilityLocker sync.Mutex
+	capabilities     cache.Capabilities
+	capabilityLoaded bool
+}
+
+//New create new remote cache driver with given transport.
+func New(t Transport) *Cache {
+	return &Cache{
+		Transport: t,
+		Timeout:   DefaultTimeout,
+	}
+}
+
+func (c *Cache) callContext(ctx context.Context, op string, req *Request) (*Response, error) {
+	resp, err := c.Transport.Call(ctx, op, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp, resp.Err()
+}
+
+func (c *Cache) call(op string, req *Request) (*Response, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+	return c.callContext(ctx, op, req)
+}
+
+//SetBytesValue Set bytes data to cache by given key.
+//Return any error raised.
+func (c *Cache) SetBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	_, err := c.call(cache.OpSet, &Request{Key: key, Value: bytes, TTL: int64(ttl)})
+	return err
+}
+
+//UpdateBytesValue Update bytes data to cache by given key only if the cache exist.
+//Return any error raised.
+func (c *Cache) UpdateBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	_, err := c.call(cache.OpUpdate, &Request{Key: key, Value: bytes, TTL: int64(ttl)})
+	return err
+}
+
+//GetBytesValue Get bytes data from cache by given key.
+//Return data bytes and any error raised.
+func (c *Cache) GetBytesValue(key string) ([]byte, error) {
+	resp, err := c.call(cache.OpGet, &Request{Key: key})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Value == nil {
+		return []byte{}, nil
+	}
+	return resp.Value, nil
+}
+
+//MGetBytesValue get multiple bytes data from cache by given keys.
+//Return data bytes map and any error if raised.
+func (c *Cache) MGetBytesValue(keys ...string) (map[string][]byte, error) {
+	resp, err := c.call(cache.OpMGet, &Request{Keys: keys})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Values == nil {
+		return map[string][]byte{}, nil
+	}
+	return resp.Values, nil
+}
+
+//MSetBytesValue set multiple bytes data to cache with given key-value map.
+//Return  any error if raised.
+func (c *Cache) MSetBytesValue(data map[string][]byte, ttl time.Duration) error {
+	_, err := c.call(cache.OpMSet, &Request{Values: data, TTL: int64(ttl)})
+	return err
+}
+
+//Del Delete data in cache by given key.
+//Return any error raised.
+func (c *Cache) Del(key string) error {
+	_, err := c.call(cache.OpDel, &Request{Key: key})
+	return err
+}
+
+//Expire set cache value expire duration by given key and ttl
+//Return any error raised.
+func (c *Cache) Expire(key string, ttl time.Duration) error {
+	_, err := c.call(cache.OpExpire, &Request{Key: key, TTL: int64(ttl)})
+	return err
+}
+
+//GetTTL get remaining ttl of given key.
+//Return ttl and any error raised.
+func (c *Cache) GetTTL(key string) (time.Duration, error) {
+	resp, err := c.call(OpGetTTL, &Request{Key: key})
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(resp.TTL), nil
+}
+
+//SetIfNotExists Set bytes data to cache by given key only if the key does not exist.
+//Return whether data is set and any error raised.
+func (c *Cache) SetIfNotExists(key string, bytes []byte, ttl time.Duration) (bool, error) {
+	resp, err := c.call(OpSetNX, &Request{Key: key, Value: bytes, TTL: int64(ttl)})
+	if err != nil {
+		return false, err
+	}
+	return resp.OK, nil
+}
+
+//SetCounter Set int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Return any error raised.
+func (c *Cache) SetCounter(key string, v int64, ttl time.Duration) error {
+	_, err := c.call(cache.OpSetCounter, &Request{Key: key, Counter: v, TTL: int64(ttl)})
+	return err
+}
+
+//GetCounter Get int val from cache by given key.Count cache and data cache are in two independent namespace.
+//Return int data value and any error raised.
+func (c *Cache) GetCounter(key string) (int64, error) {
+	resp, err := c.call(cache.OpGetCounter, &Request{Key: key})
+	if err != nil {
+		return 0, err
+	}
+	return resp.Counter, nil
+}
+
+//IncrCounter Increase int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Return int data value and any error raised.
+func (c *Cache) IncrCounter(key string, increment int64, ttl time.Duration) (int64, error) {
+	resp, err := c.call(cache.OpIncrCounter, &Request{Key: key, Counter: increment, TTL: int64(ttl)})
+	if err != nil {
+		return 0, err
+	}
+	return resp.Counter, nil
+}
+
+//ExpireCounter set cache counter  expire duration by given key and ttl
+//Return any error raised.
+func (c *Cache) ExpireCounter(key string, ttl time.Duration) error {
+	_, err := c.call(OpExpireCounter, &Request{Key: key, TTL: int64(ttl)})
+	return err
+}
+
+//DelCounter Delete int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Return any error raised.
+func (c *Cache) DelCounter(key string) error {
+	_, err := c.call(cache.OpDelCounter, &Request{Key: key})
+	return err
+}
+
+//SetGCErrHandler Set callback to handler error raised when gc.
+func (c *Cache) SetGCErrHandler(f func(err error)) {
+	return
+}
+
+//Ping check if remote cache server and its backend are reachable before context done.
+//Return any error raised.
+func (c *Cache) Ping(ctx context.Context) error {
+	_, err := c.callContext(ctx, OpPing, &Request{})
+	return err
+}
+
+//Capabilities return capabilities supported by remote cache server.
+//Capabilities are loaded from server on first successful call,
+//mget,counter,flush and ping are reported before loaded.
+func (c *Cache) Capabilities() cache.Capabilities {
+	c.capabilityLocker.Lock()
+	defer c.capabilityLocker.Unlock()
+	if c.capabilityLoaded {
+		return c.capabilities
+	}
+	resp, err := c.call(OpCapabilities, &Request{})
+	if err != nil {
+		return cache.CapabilityMGet | cache.CapabilityCounter | cache.CapabilityFlush | cache.CapabilityPing
+	}
+	var capabilities cache.Capabilities
+	for _, v := range strings.Split(resp.Capabilities, ",") {
+		capabilities = capabilities | capabilityNames[v]
+	}
+	c.capabilities = capabilities
+	c.capabilityLoaded = true
+	return c.capabilities
+}
+
+//Flush Delete all data in cache.
+//Return any error if raised
+func (c *Cache) Flush() error {
+	_, err := c.call(cache.OpFlush, &Request{})
+	return err
+}
+
+//Close Close cache.
+//Return any error if raised
+func (c *Cache) Close() error {
+	return c.Transport.Close()
+}
+
+//Config Cache driver config.
+type Config struct {
+	//Transport transport name.
+	//Default value is "http".
+	Transport string
+	//Address remote cache server address.
+	//Address of http transport is base url of server,like "http://127.0.0.1:8080/cache".
+	Address string
+	//Token token sent to server.
+	Token string
+	//TimeoutInSecond timeout of remote calls in second.
+	//Default value is 5.
+	TimeoutInSecond int64
+}
+
+//Create create new cache driver.
+//Return driver created and any error if raised.
+func (c *Config) Create() (cache.Driver, error) {
+	if c.Address == "" {
+		return nil, ErrAddressRequired
+	}
+	t, err := NewTransport(c)
+	if err != nil {
+		return nil, err
+	}
+	d := New(t)
+	if c.TimeoutInSecond > 0 {
+		d.Timeout = time.Duration(c.TimeoutInSecond) * time.Second
+	}
+	return d, nil
+}
+
+func init() {
+	cache.Register("remotecache", func(loader func(interface{}) error) (cache.Driver, error) {
+		c := &Config{}
+		err := loader(c)
+		if err != nil {
+			return nil, err
+		}
+		return c.Create()
+	})
+}
@@ -0,0 +1,167 @@
+package remotecache
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+	_ "github.com/herb-go/deprecated/cache/drivers/syncmapcache"
+)
+
+func newTestServer(token string) *httptest.Server {
+	oc := cache.NewOptionConfig()
+	oc.Driver = "syncmapcache"
+	oc.TTL = 3600
+	oc.Marshaler = "json"
+	oc.Config = json.NewDecoder(bytes.NewBufferString(`{"Size":10000000}`)).Decode
+	config := &ServerConfig{
+		Cache: oc,
+		Token: token,
+	}
+	s, err := config.CreateServer()
+	if err != nil {
+		panic(err)
+	}
+	return httptest.NewServer(s)
+}
+
+func newTestCache(address string, token string) *cache.Cache {
+	c := cache.New()
+	oc := cache.NewOptionConfig()
+	oc.Driver = "remotecache"
+	oc.TTL = 3600
+	oc.Marshaler = "json"
+	oc.Config = func(v interface{}) error {
+		config := v.(*Config)
+		config.Address = address
+		config.Token = token
+		return nil
+	}
+	err := c.Init(oc)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func TestRemoteCache(t *testing.T) {
+	s := newTestServer("")
+	defer s.Close()
+	c := newTestCache(s.URL, "")
+	defer c.Close()
+	var result string
+	err := c.Get("test", &result)
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	err = c.Set("test", "value", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.Get("test", &result)
+	if err != nil || result != "value" {
+		t.Fatal(result, err)
+	}
+	ttl, err := c.GetTTL("test")
+	if err != nil || ttl <= 0 || ttl > time.Hour {
+		t.Fatal(ttl, err)
+	}
+	ok, err := c.SetIfNotExists("test", []byte("other"), time.Hour)
+	if err != nil || ok {
+		t.Fatal(ok, err)
+	}
+	err = c.MSetBytesValue(map[string][]byte{"a": []byte("1"), "b": []byte("2")}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := c.MGetBytesValue("a", "b", "notexist")
+	if err != nil || len(data) != 2 || string(data["a"]) != "1" || string(data["b"]) != "2" {
+		t.Fatal(data, err)
+	}
+	err = c.Del("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.GetBytesValue("test")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	v, err := c.IncrCounter("counter", 2, time.Hour)
+	if err != nil || v != 2 {
+		t.Fatal(v, err)
+	}
+	v, err = c.IncrCounter("counter", 3, time.Hour)
+	if err != nil || v != 5 {
+		t.Fatal(v, err)
+	}
+	err = c.SetCounter("counter", 10, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err = c.GetCounter("counter")
+	if err != nil || v != 10 {
+		t.Fatal(v, err)
+	}
+	err = c.DelCounter("counter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.GetCounter("counter")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	err = c.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.GetBytesValue("a")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	if !c.Capabilities().Has(cache.CapabilityMGet | cache.CapabilityCounter | cache.CapabilityFlush | cache.CapabilityTTL) {
+		t.Fatal(c.Capabilities())
+	}
+}
+
+func TestToken(t *testing.T) {
+	s := newTestServer("secret")
+	defer s.Close()
+	c := newTestCache(s.URL, "wrong")
+	defer c.Close()
+	err := c.SetBytesValue("test", []byte("value"), time.Hour)
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Fatal(err)
+	}
+	c = newTestCache(s.URL, "secret")
+	defer c.Close()
+	err = c.SetBytesValue("test", []byte("value"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUnknownOperation(t *testing.T) {
+	s := newTestServer("")
+	defer s.Close()
+	c := newTestCache(s.URL, "")
+	defer c.Close()
+	_, err := c.Driver.(*Cache).call("notexist", &Request{})
+	if !errors.Is(err, ErrUnknownOperation) {
+		t.Fatal(err)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	_, err := (&Config{}).Create()
+	if err != ErrAddressRequired {
+		t.Fatal(err)
+	}
+	_, err = (&Config{Address: "127.0.0.1:8080", Transport: "notexist"}).Create()
+	if err == nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,168 @@
+package remotecache
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+//ServedCapabilities capabilities which can be served by remote cache server.
+const ServedCapabilities = cache.CapabilityMGet | cache.CapabilityCounter | cache.CapabilityFlush | cache.CapabilityTTL | cache.CapabilityNX | cache.CapabilityPing
+
+//DefaultMaxRequestSize default max request body size in bytes served by http handler.
+const DefaultMaxRequestSize = 32 * 1024 * 1024
+
+//Server remote cache server.
+//Server exposes cache operations over http with json body,
+//and can be registered to other transports like grpc.
+type Server struct {
+	//Cache cache which operations are served.
+	Cache *cache.Cache
+	//Token token requests should provide.
+	//Every request is accepted if token is empty.
+	Token string
+	//MaxRequestSize max request body size in bytes served by http handler.
+	MaxRequestSize int64
+}
+
+//NewServer create new remote cache server with given cache.
+func NewServer(c *cache.Cache) *Server {
+	return &Server{
+		Cache:          c,
+		MaxRequestSize: DefaultMaxRequestSize,
+	}
+}
+
+//Authorize check if given token is accepted by server.
+func (s *Server) Authorize(token string) bool {
+	if s.Token == "" {
+		return true
+	}
+	return subtle.ConstantTimeCompare([]byte(s.Token), []byte(token)) == 1
+}
+
+//Serve serve given operation with given request.
+//Errors are set to response.
+func (s *Server) Serve(ctx context.Context, op string, req *Request) *Response {
+	resp := &Response{}
+	resp.SetError(s.serve(ctx, op, req, resp))
+	return resp
+}
+
+func (s *Server) serve(ctx context.Context, op string, req *Request, resp *Response) error {
+	var err error
+	ttl := time.Duration(req.TTL)
+	c := s.Cache
+	switch op {
+	case cache.OpGet:
+		resp.Value, err = c.GetBytesValue(req.Key)
+	case cache.OpSet:
+		err = c.SetBytesValue(req.Key, req.Value, ttl)
+	case cache.OpUpdate:
+		err = c.UpdateBytesValue(req.Key, req.Value, ttl)
+	case cache.OpDel:
+		err = c.Del(req.Key)
+	case cache.OpMGet:
+		resp.Values, err = c.MGetBytesValue(req.Keys...)
+	case cache.OpMSet:
+		err = c.MSetBytesValue(req.Values, ttl)
+	case cache.OpExpire:
+		err = c.Expire(req.Key, ttl)
+	case cache.OpIncrCounter:
+		resp.Counter, err = c.IncrCounter(req.Key, req.Counter, ttl)
+	case cache.OpSetCounter:
+		err = c.SetCounter(req.Key, req.Counter, ttl)
+	case cache.OpGetCounter:
+		resp.Counter, err = c.GetCounter(req.Key)
+	case cache.OpDelCounter:
+		err = c.DelCounter(req.Key)
+	case OpExpireCounter:
+		err = c.ExpireCounter(req.Key, ttl)
+	case OpGetTTL:
+		ttl, err = c.GetTTL(req.Key)
+		resp.TTL = int64(ttl)
+	case OpSetNX:
+		resp.OK, err = c.SetIfNotExists(req.Key, req.Value, ttl)
+	case cache.OpFlush:
+		err = c.Flush()
+	case OpPing:
+		err = c.Ping(ctx)
+	case OpCapabilities:
+		resp.Capabilities = (c.Capabilities() & ServedCapabilities).String()
+	default:
+		err = ErrUnknownOperation
+	}
+	return err
+}
+
+func writeResponse(w http.ResponseWriter, status int, resp *Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+//ServeHTTP serve http request.
+//Operation name is last segment of request path,request and response are json encoded.
+//Token should be provided by bearer authorization header.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	resp := &Response{}
+	if !s.Authorize(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")) {
+		resp.SetError(ErrUnauthorized)
+		writeResponse(w, http.StatusUnauthorized, resp)
+		return
+	}
+	op := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	req := &Request{}
+	body := r.Body
+	if s.MaxRequestSize > 0 {
+		body = http.MaxBytesReader(w, body, s.MaxRequestSize)
+	}
+	err := json.NewDecoder(body).Decode(req)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	resp = s.Serve(r.Context(), op, req)
+	if resp.Error == "unknownoperation" {
+		writeResponse(w, http.StatusNotFound, resp)
+		return
+	}
+	writeResponse(w, http.StatusOK, resp)
+}
+
+//ServerConfig remote cache server config.
+type ServerConfig struct {
+	//Cache cache config which operations are served.
+	Cache *cache.OptionConfig
+	//Token token requests should provide.
+	//Every request is accepted if token is empty.
+	Token string
+	//MaxRequestSize max request body size in bytes served by http handler.
+	//Default value is 32MB.
+	MaxRequestSize int64
+}
+
+//CreateServer create new remote cache server.
+//Return server created and any error if raised.
+func (c *ServerConfig) CreateServer() (*Server, error) {
+	sc := cache.New()
+	err := sc.Init(c.Cache)
+	if err != nil {
+		return nil, err
+	}
+	s := NewServer(sc)
+	s.Token = c.Token
+	if c.MaxRequestSize > 0 {
+		s.MaxRequestSize = c.MaxRequestSize
+	}
+	return s, nil
+}
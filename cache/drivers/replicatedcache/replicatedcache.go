@@ -0,0 +1,501 @@
+//Package replicatedcache provides a cache driver which writes data to all replica caches,
+//and reads data from the nearest healthy replica,for multi-zone deployments which prefer availability to consistency.
+package replicatedcache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+//DefaultProbeInterval default interval between health probes of down replicas.
+var DefaultProbeInterval = 5 * time.Second
+
+//DefaultRepairQueueSize default max count of pending read repairs.
+var DefaultRepairQueueSize = 1024
+
+//ProbeKey key used to probe replica health.
+var ProbeKey = "replicatedcache.probe"
+
+//ErrNoReplicas raised when no replica is configured or all replicas are down.
+var ErrNoReplicas = errors.New("replicatedcache: no replicas")
+
+func isFailure(err error) bool {
+	//Cache errors like ErrNotFound are results rather than failures,even wrapped with key context.
+	return cache.IsRetryableError(err)
+}
+
+type replica struct {
+	cache *cache.Cache
+	down  int32
+}
+
+func (r *replica) isDown() bool {
+	return atomic.LoadInt32(&r.down) == 1
+}
+
+type repair struct {
+	key     string
+	data    []byte
+	ttl     time.Duration
+	targets []*replica
+}
+
+//Cache The replicated cache driver.
+type Cache struct {
+	cache.DriverUtil
+	//ProbeInterval interval between health probes of down replicas.
+	ProbeInterval time.Duration
+	replicas      []*replica
+	repairs       chan *repair
+	repaired      int64
+	dropped       int64
+	locker        sync.Mutex
+	quit          chan struct{}
+}
+
+//New create new replicated cache driver with given replicas ordered by distance,nearest first.
+//Read repair queue with given size is created,read repair is disabled if size is negative.
+func New(repairQueueSize int, replicas ...*cache.Cache) *Cache {
+	c := &Cache{
+		replicas: make([]*replica, len(replicas)),
+		quit:     make(chan struct{}),
+	}
+	for k := range replicas {
+		c.replicas[k] = &replica{cache: replicas[k]}
+	}
+	if repairQueueSize >= 0 {
+		c.repairs = make(chan *repair, repairQueueSize)
+		go c.repairLoop()
+	}
+	return c
+}
+
+//ReplicaDown return whether replica with given index is marked as down.
+func (c *Cache) ReplicaDown(index int) bool {
+	return c.replicas[index].isDown()
+}
+
+//Repaired return count of replicas repaired in background.
+func (c *Cache) Repaired() int64 {
+	return atomic.LoadInt64(&c.repaired)
+}
+
+//RepairDropped return count of read repairs dropped because repair queue is full.
+func (c *Cache) RepairDropped() int64 {
+	return atomic.LoadInt64(&c.dropped)
+}
+
+func (c *Cache) markDown(r *replica) {
+	if !atomic.CompareAndSwapInt32(&r.down, 0, 1) {
+		return
+	}
+	go c.probe(r)
+}
+
+//Probe check health of all down replicas,and mark healthy replicas as up.
+//Return whether all replicas are healthy.
+func (c *Cache) Probe() bool {
+	healthy := true
+	for _, r := range c.replicas {
+		if r.isDown() && !c.probeReplica(r) {
+			healthy = false
+		}
+	}
+	return healthy
+}
+
+func (c *Cache) probeReplica(r *replica) bool {
+	_, err := r.cache.GetBytesValue(ProbeKey)
+	if isFailure(err) {
+		return false
+	}
+	atomic.StoreInt32(&r.down, 0)
+	return true
+}
+
+func (c *Cache) probe(r *replica) {
+	interval := c.ProbeInterval
+	if interval <= 0 {
+		interval = DefaultProbeInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if c.probeReplica(r) {
+				return
+			}
+		case <-c.quit:
+			return
+		}
+	}
+}
+
+func (c *Cache) repairLoop() {
+	for {
+		select {
+		case r := <-c.repairs:
+			for _, target := range r.targets {
+				if target.isDown() {
+					continue
+				}
+				err := target.cache.SetBytesValue(r.key, r.data, r.ttl)
+				if isFailure(err) {
+					c.markDown(target)
+					continue
+				}
+				if err == nil {
+					atomic.AddInt64(&c.repaired, 1)
+				}
+			}
+		case <-c.quit:
+			return
+		}
+	}
+}
+
+//readRepair queue repair of replicas missing given key with data from source replica.
+//Repair is dropped if repair queue is full.
+func (c *Cache) readRepair(key string, data []byte, source *replica, targets []*replica) {
+	if c.repairs == nil || len(targets) == 0 {
+		return
+	}
+	ttl := cache.DefaultTTL
+	if source.cache.Capabilities().Has(cache.CapabilityTTL) {
+		remaining, err := source.cache.GetTTL(key)
+		if err != nil {
+			return
+		}
+		ttl = remaining
+		if ttl == 0 {
+			ttl = -1
+		}
+	}
+	select {
+	case c.repairs <- &repair{key: key, data: data, ttl: ttl, targets: targets}:
+	default:
+		atomic.AddInt64(&c.dropped, 1)
+	}
+}
+
+//read call given function with healthy replicas in order until function does not fail.
+//Replica is marked as down if function fails.
+//Return error of last called function,or ErrNoReplicas if no replica is healthy.
+func (c *Cache) read(f func(r *replica) error) error {
+	var err error = ErrNoReplicas
+	for _, r := range c.replicas {
+		if r.isDown() {
+			continue
+		}
+		err = f(r)
+		if !isFailure(err) {
+			return err
+		}
+		c.markDown(r)
+	}
+	return err
+}
+
+//write call given function with all healthy replicas.
+//Replica is marked as down if function fails.
+//Return first result which is not failure,
+//or error of last failed replica if all replicas failed.
+func (c *Cache) write(f func(r *replica) error) error {
+	var result error
+	var failure error = ErrNoReplicas
+	var succeeded bool
+	for _, r := range c.replicas {
+		if r.isDown() {
+			continue
+		}
+		err := f(r)
+		if isFailure(err) {
+			c.markDown(r)
+			failure = err
+			continue
+		}
+		if !succeeded {
+			succeeded = true
+			result = err
+		}
+	}
+	if !succeeded {
+		return failure
+	}
+	return result
+}
+
+//SetBytesValue Set bytes data to all replicas by given key.
+//Return any error raised.
+func (c *Cache) SetBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	return c.write(func(r *replica) error {
+		return r.cache.SetBytesValue(key, bytes, ttl)
+	})
+}
+
+//UpdateBytesValue Update bytes data to all replicas by given key only if the cache exist.
+//Return any error raised.
+func (c *Cache) UpdateBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	return c.write(func(r *replica) error {
+		return r.cache.UpdateBytesValue(key, bytes, ttl)
+	})
+}
+
+//GetBytesValue Get bytes data from nearest healthy replica which has given key.
+//Replicas are queried in order until data found,so cache miss queries all healthy replicas.
+//Nearer replicas missing given key are repaired in background.
+//Return data bytes and any error raised.
+func (c *Cache) GetBytesValue(key string) ([]byte, error) {
+	var missed []*replica
+	var lastErr error = ErrNoReplicas
+	for _, r := range c.replicas {
+		if r.isDown() {
+			continue
+		}
+		data, err := r.cache.GetBytesValue(key)
+		if err == nil {
+			c.readRepair(key, data, r, missed)
+			return data, nil
+		}
+		if isFailure(err) {
+			c.markDown(r)
+			lastErr = err
+			continue
+		}
+		if !errors.Is(err, cache.ErrNotFound) {
+			return nil, err
+		}
+		missed = append(missed, r)
+	}
+	if len(missed) > 0 {
+		return nil, cache.ErrNotFound
+	}
+	return nil, lastErr
+}
+
+//MGetBytesValue get multiple bytes data from nearest healthy replica by given keys.
+//Return data bytes map and any error if raised.
+func (c *Cache) MGetBytesValue(keys ...string) (map[string][]byte, error) {
+	var data map[string][]byte
+	err := c.read(func(r *replica) error {
+		var err error
+		data, err = r.cache.MGetBytesValue(keys...)
+		return err
+	})
+	return data, err
+}
+
+//MSetBytesValue set multiple bytes data to all replicas with given key-value map.
+//Return  any error if raised.
+func (c *Cache) MSetBytesValue(data map[string][]byte, ttl time.Duration) error {
+	return c.write(func(r *replica) error {
+		return r.cache.MSetBytesValue(data, ttl)
+	})
+}
+
+//Del Delete data in all replicas by given key.
+//Return any error raised.
+func (c *Cache) Del(key string) error {
+	return c.write(func(r *replica) error {
+		return r.cache.Del(key)
+	})
+}
+
+//Expire set cache value expire duration in all replicas by given key and ttl
+//Return any error raised.
+func (c *Cache) Expire(key string, ttl time.Duration) error {
+	return c.write(func(r *replica) error {
+		return r.cache.Expire(key, ttl)
+	})
+}
+
+//GetTTL get remaining ttl of given key from nearest healthy replica.
+//Return ttl and any error raised.
+func (c *Cache) GetTTL(key string) (time.Duration, error) {
+	var ttl time.Duration
+	err := c.read(func(r *replica) error {
+		var err error
+		ttl, err = r.cache.GetTTL(key)
+		return err
+	})
+	return ttl, err
+}
+
+//SetCounter Set int val in all replicas by given key.Count cache and data cache are in two independent namespace.
+//Return any error raised.
+func (c *Cache) SetCounter(key string, v int64, ttl time.Duration) error {
+	return c.write(func(r *replica) error {
+		return r.cache.SetCounter(key, v, ttl)
+	})
+}
+
+//GetCounter Get int val from nearest healthy replica by given key.Count cache and data cache are in two independent namespace.
+//Return int data value and any error raised.
+func (c *Cache) GetCounter(key string) (int64, error) {
+	var v int64
+	err := c.read(func(r *replica) error {
+		var err error
+		v, err = r.cache.GetCounter(key)
+		return err
+	})
+	return v, err
+}
+
+//IncrCounter Increase int val in nearest healthy replica by given key,then set result to other replicas,
+//so that counters in replicas are not diverged by replicated increments.
+//Count cache and data cache are in two independent namespace.
+//Return int data value and any error raised.
+func (c *Cache) IncrCounter(key string, increment int64, ttl time.Duration) (int64, error) {
+	var v int64
+	var source *replica
+	err := c.read(func(r *replica) error {
+		var err error
+		v, err = r.cache.IncrCounter(key, increment, ttl)
+		source = r
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	c.write(func(r *replica) error {
+		if r == source {
+			return nil
+		}
+		return r.cache.SetCounter(key, v, ttl)
+	})
+	return v, nil
+}
+
+//ExpireCounter set cache counter expire duration in all replicas by given key and ttl
+//Return any error raised.
+func (c *Cache) ExpireCounter(key string, ttl time.Duration) error {
+	return c.write(func(r *replica) error {
+		return r.cache.ExpireCounter(key, ttl)
+	})
+}
+
+//DelCounter Delete int val in all replicas by given key.Count cache and data cache are in two independent namespace.
+//Return any error raised.
+func (c *Cache) DelCounter(key string) error {
+	return c.write(func(r *replica) error {
+		return r.cache.DelCounter(key)
+	})
+}
+
+//SetGCErrHandler Set callback to handler error raised when gc.
+func (c *Cache) SetGCErrHandler(f func(err error)) {
+	for _, r := range c.replicas {
+		r.cache.SetGCErrHandler(f)
+	}
+}
+
+//Ping check if any replica is reachable before context done.
+//Return error raised by last replica if all replicas are unreachable.
+func (c *Cache) Ping(ctx context.Context) error {
+	var err error = ErrNoReplicas
+	for _, r := range c.replicas {
+		err = r.cache.Ping(ctx)
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+//Capabilities return capabilities supported by all replicas.
+func (c *Cache) Capabilities() cache.Capabilities {
+	all := cache.CapabilityMGet | cache.CapabilityCounter | cache.CapabilityFlush | cache.CapabilityPing | cache.CapabilityTTL
+	for _, r := range c.replicas {
+		all = all & r.cache.Capabilities()
+	}
+	return all
+}
+
+//Close stop health probes and read repairs,and close all replicas.
+//Return any error if raised
+func (c *Cache) Close() error {
+	c.locker.Lock()
+	select {
+	case <-c.quit:
+	default:
+		close(c.quit)
+	}
+	c.locker.Unlock()
+	var finalErr error
+	for _, r := range c.replicas {
+		err := r.cache.Close()
+		if err != nil {
+			finalErr = err
+		}
+	}
+	return finalErr
+}
+
+//Flush Delete all data in all replicas.
+//Return any error if raised
+func (c *Cache) Flush() error {
+	var finalErr error
+	for _, r := range c.replicas {
+		err := r.cache.Flush()
+		if err != nil {
+			finalErr = err
+		}
+	}
+	return finalErr
+}
+
+//Config replicated cache driver config.
+type Config struct {
+	//Replicas replica cache configs ordered by distance,nearest first.
+	Replicas []*cache.OptionConfig
+	//ProbeIntervalInSecond interval in second between health probes of down replicas.
+	//DefaultProbeInterval will be used if not positive.
+	ProbeIntervalInSecond int64
+	//RepairQueueSize max count of pending read repairs.
+	//DefaultRepairQueueSize will be used if zero.
+	//Read repair is disabled if negative.
+	RepairQueueSize int
+}
+
+//Create create new cache driver.
+//Return driver created and any error if raised.
+func (c *Config) Create() (cache.Driver, error) {
+	if len(c.Replicas) == 0 {
+		return nil, ErrNoReplicas
+	}
+	replicas := make([]*cache.Cache, len(c.Replicas))
+	for k, v := range c.Replicas {
+		sub, err := cache.NewSubCache(v)
+		if err != nil {
+			for _, created := range replicas[:k] {
+				created.Close()
+			}
+			return nil, err
+		}
+		replicas[k] = sub
+	}
+	size := c.RepairQueueSize
+	if size == 0 {
+		size = DefaultRepairQueueSize
+	}
+	cc := New(size, replicas...)
+	cc.ProbeInterval = time.Duration(c.ProbeIntervalInSecond) * time.Second
+	return cc, nil
+}
+
+func init() {
+	cache.Register("replicatedcache", func(loader func(interface{}) error) (cache.Driver, error) {
+		c := &Config{}
+		err := loader(c)
+		if err != nil {
+			return nil, err
+		}
+		return c.Create()
+	})
+}
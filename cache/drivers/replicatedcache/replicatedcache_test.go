@@ -0,0 +1,217 @@
+package replicatedcache
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+	_ "github.com/herb-go/deprecated/cache/drivers/syncmapcache"
+)
+
+var errTestDown = errors.New("test cache down")
+
+type flakyDriver struct {
+	cache.Driver
+	locker sync.Mutex
+	down   bool
+}
+
+func (d *flakyDriver) setDown(down bool) {
+	d.locker.Lock()
+	defer d.locker.Unlock()
+	d.down = down
+}
+
+func (d *flakyDriver) isDown() bool {
+	d.locker.Lock()
+	defer d.locker.Unlock()
+	return d.down
+}
+
+func (d *flakyDriver) GetBytesValue(key string) ([]byte, error) {
+	if d.isDown() {
+		return nil, errTestDown
+	}
+	return d.Driver.GetBytesValue(key)
+}
+
+func (d *flakyDriver) SetBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	if d.isDown() {
+		return errTestDown
+	}
+	return d.Driver.SetBytesValue(key, bytes, ttl)
+}
+
+func (d *flakyDriver) GetTTL(key string) (time.Duration, error) {
+	if d.isDown() {
+		return 0, errTestDown
+	}
+	return d.Driver.(cache.TTLGetter).GetTTL(key)
+}
+
+func newTestSubCacheConfig() *cache.OptionConfig {
+	oc := cache.NewOptionConfig()
+	oc.Driver = "syncmapcache"
+	oc.TTL = 3600
+	oc.Marshaler = "json"
+	oc.Config = json.NewDecoder(bytes.NewBufferString(`{"Size":10000000}`)).Decode
+	return oc
+}
+
+func newSubCache() *cache.Cache {
+	c, err := cache.NewSubCache(newTestSubCacheConfig())
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func waitRepaired(d *Cache, n int64) bool {
+	for i := 0; i < 100; i++ {
+		if d.Repaired() >= n {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return false
+}
+
+func TestReplicated(t *testing.T) {
+	local := newSubCache()
+	flaky := &flakyDriver{Driver: local.Driver}
+	local.Driver = flaky
+	remote := newSubCache()
+	d := New(DefaultRepairQueueSize, local, remote)
+	d.ProbeInterval = 10 * time.Millisecond
+	d.SetUtil(local.Util())
+	c := cache.New()
+	c.Driver = d
+	c.TTL = time.Hour
+	defer c.Close()
+	err := c.SetBytesValue("test", []byte("value"), cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []*cache.Cache{local, remote} {
+		bs, err := v.GetBytesValue(cache.Key("test"))
+		if err != nil || string(bs) != "value" {
+			t.Fatal(string(bs), err)
+		}
+	}
+	err = remote.SetBytesValue(cache.Key("test"), []byte("remote"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := c.GetBytesValue("test")
+	if err != nil || string(bs) != "value" {
+		t.Fatal(string(bs), err)
+	}
+	flaky.setDown(true)
+	bs, err = c.GetBytesValue("test")
+	if err != nil || string(bs) != "remote" {
+		t.Fatal(string(bs), err)
+	}
+	if !d.ReplicaDown(0) || d.ReplicaDown(1) {
+		t.Fatal(d.ReplicaDown(0), d.ReplicaDown(1))
+	}
+	err = c.SetBytesValue("test2", []byte("value2"), cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	flaky.setDown(false)
+	time.Sleep(50 * time.Millisecond)
+	if d.ReplicaDown(0) {
+		t.Fatal(d.ReplicaDown(0))
+	}
+	bs, err = c.GetBytesValue("test2")
+	if err != nil || string(bs) != "value2" {
+		t.Fatal(string(bs), err)
+	}
+	if !waitRepaired(d, 1) {
+		t.Fatal(d.Repaired())
+	}
+	bs, err = local.GetBytesValue(cache.Key("test2"))
+	if err != nil || string(bs) != "value2" {
+		t.Fatal(string(bs), err)
+	}
+	ttl, err := local.GetTTL(cache.Key("test2"))
+	if err != nil || ttl <= 59*time.Minute {
+		t.Fatal(ttl, err)
+	}
+	err = c.Del("test2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.GetBytesValue("test2")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	flaky.setDown(true)
+	remote.Driver = &flakyDriver{Driver: remote.Driver, down: true}
+	err = c.SetBytesValue("test3", []byte("value3"), cache.DefaultTTL)
+	if !errors.Is(err, errTestDown) {
+		t.Fatal(err)
+	}
+	_, err = c.GetBytesValue("test3")
+	if !errors.Is(err, ErrNoReplicas) {
+		t.Fatal(err)
+	}
+}
+
+func TestCounter(t *testing.T) {
+	local := newSubCache()
+	remote := newSubCache()
+	d := New(-1, local, remote)
+	defer d.Close()
+	v, err := d.IncrCounter("counter", 2, time.Hour)
+	if err != nil || v != 2 {
+		t.Fatal(v, err)
+	}
+	v, err = d.IncrCounter("counter", 3, time.Hour)
+	if err != nil || v != 5 {
+		t.Fatal(v, err)
+	}
+	for _, sub := range []*cache.Cache{local, remote} {
+		v, err = sub.GetCounter("counter")
+		if err != nil || v != 5 {
+			t.Fatal(v, err)
+		}
+	}
+	err = remote.SetBytesValue("key", []byte("value"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := d.GetBytesValue("key")
+	if err != nil || string(bs) != "value" {
+		t.Fatal(string(bs), err)
+	}
+	_, err = local.GetBytesValue("key")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	config := &Config{}
+	_, err := config.Create()
+	if err != ErrNoReplicas {
+		t.Fatal(err)
+	}
+	config.Replicas = []*cache.OptionConfig{newTestSubCacheConfig(), newTestSubCacheConfig()}
+	d, err := config.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	c := d.(*Cache)
+	if len(c.replicas) != 2 || cap(c.repairs) != DefaultRepairQueueSize {
+		t.Fatal(len(c.replicas), cap(c.repairs))
+	}
+	if !c.Capabilities().Has(cache.CapabilityTTL | cache.CapabilityFlush) {
+		t.Fatal(c.Capabilities())
+	}
+}
@@ -0,0 +1,356 @@
+//Package ringcache provides cache driver uses memory to store cache data.
+//Entries are stored in preallocated ring buffers indexed by maps without pointers,
+//so millions of small entries do not cause per-entry heap allocations and long gc pauses.
+package ringcache
+
+import (
+	"hash/fnv"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+var defaultSize = 64 * 1024 * 1024
+var defaultShards = 256
+
+//minShardSize min size of every shard.Shard count is reduced if shard size is smaller.
+var minShardSize = 64 * 1024
+
+//maxShardSize max size of every shard,so that entry offsets fit in uint32.
+//Shard count is increased if shard size is larger.
+var maxShardSize = 1 << 31
+
+//CounterEncoding encoding used to store counter values.
+var CounterEncoding cache.CounterEncoding = cache.BigEndianCounterEncoding{}
+
+func hashKey(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return h.Sum64()
+}
+
+func expiredTime(ttl time.Duration) int64 {
+	if ttl <= 0 {
+		return 0
+	}
+	return time.Now().Add(ttl).UnixNano()
+}
+
+//Cache The ring buffer cache Driver.
+type Cache struct {
+	cache.DriverUtil
+	shards    []*shard
+	shardSize int
+}
+
+//SetGCErrHandler Set callback to handler error raised when gc.
+func (c *Cache) SetGCErrHandler(f func(err error)) {
+	return
+}
+
+func (c *Cache) shard(key string) (*shard, uint64) {
+	h := hashKey(key)
+	return c.shards[h%uint64(len(c.shards))], h
+}
+
+func (c *Cache) checkSize(key string, data []byte) error {
+	if len(key) > maxKeyLength || headerSize+len(key)+len(data) > c.shardSize {
+		return cache.ErrEntryTooLarge
+	}
+	return nil
+}
+
+func (c *Cache) get(key string) ([]byte, bool) {
+	s, h := c.shard(key)
+	s.locker.RLock()
+	defer s.locker.RUnlock()
+	data, _, ok := s.get(h, key, time.Now().UnixNano())
+	if !ok {
+		return nil, false
+	}
+	bs := make([]byte, len(data))
+	copy(bs, data)
+	return bs, true
+}
+
+//SetBytesValue Set bytes data to cache by given key.
+//Return any error raised.
+func (c *Cache) SetBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	if err := c.checkSize(key, bytes); err != nil {
+		return err
+	}
+	s, h := c.shard(key)
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	s.set(h, key, bytes, expiredTime(ttl))
+	return nil
+}
+
+//UpdateBytesValue Update bytes data to cache by given key only if the cache exist.
+//Return any error raised.
+func (c *Cache) UpdateBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	if err := c.checkSize(key, bytes); err != nil {
+		return err
+	}
+	s, h := c.shard(key)
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	if _, _, ok := s.get(h, key, time.Now().UnixNano()); !ok {
+		return nil
+	}
+	s.set(h, key, bytes, expiredTime(ttl))
+	return nil
+}
+
+//SetIfNotExists Set bytes data to cache by given key only if the key does not exist.
+//Return whether data is set and any error raised.
+func (c *Cache) SetIfNotExists(key string, bytes []byte, ttl time.Duration) (bool, error) {
+	if err := c.checkSize(key, bytes); err != nil {
+		return false, err
+	}
+	s, h := c.shard(key)
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	if _, _, ok := s.get(h, key, time.Now().UnixNano()); ok {
+		return false, nil
+	}
+	s.set(h, key, bytes, expiredTime(ttl))
+	return true, nil
+}
+
+//SetIfVersion Set bytes data to cache by given key only if current value version equals given version.
+//Empty version means key should not exist.
+//Return whether data is set and any error raised.
+func (c *Cache) SetIfVersion(key string, bytes []byte, version string, ttl time.Duration) (bool, error) {
+	if err := c.checkSize(key, bytes); err != nil {
+		return false, err
+	}
+	s, h := c.shard(key)
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	data, _, ok := s.get(h, key, time.Now().UnixNano())
+	if ok {
+		if version != cache.ValueVersion(data) {
+			return false, nil
+		}
+	} else if version != "" {
+		return false, nil
+	}
+	s.set(h, key, bytes, expiredTime(ttl))
+	return true, nil
+}
+
+//GetBytesValue Get bytes data from cache by given key.
+//Return data bytes and any error raised.
+func (c *Cache) GetBytesValue(key string) ([]byte, error) {
+	data, ok := c.get(key)
+	if !ok {
+		return nil, cache.ErrNotFound
+	}
+	return data, nil
+}
+
+//MGetBytesValue get multiple bytes data from cache by given keys.
+//Return data bytes map and any error if raised.
+func (c *Cache) MGetBytesValue(keys ...string) (map[string][]byte, error) {
+	var result = make(map[string][]byte, len(keys))
+	for _, k := range keys {
+		if data, ok := c.get(k); ok {
+			result[k] = data
+		}
+	}
+	return result, nil
+}
+
+//MSetBytesValue set multiple bytes data to cache with given key-value map.
+//Return  any error if raised.
+func (c *Cache) MSetBytesValue(data map[string][]byte, ttl time.Duration) error {
+	for k := range data {
+		err := c.SetBytesValue(k, data[k], ttl)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Capabilities return capabilities supported by cache.
+func (c *Cache) Capabilities() cache.Capabilities {
+	return cache.CapabilityCounter | cache.CapabilityFlush | cache.CapabilityTTL | cache.CapabilityNX | cache.CapabilityCAS | cache.CapabilitySize
+}
+
+//Usage return entry count and bytes used by entries in ring buffers.
+//Expired entries not overwritten yet are counted.
+//Return entry count,bytes used and any error raised.
+func (c *Cache) Usage() (int64, int64, error) {
+	var entries, bytes int64
+	for _, s := range c.shards {
+		s.locker.RLock()
+		entries += int64(len(s.index))
+		bytes += int64(s.used)
+		s.locker.RUnlock()
+	}
+	return entries, bytes, nil
+}
+
+//Flush Delete all data in cache.
+//Return any error if raised
+func (c *Cache) Flush() error {
+	for _, s := range c.shards {
+		s.locker.Lock()
+		s.reset()
+		s.locker.Unlock()
+	}
+	return nil
+}
+
+//Close Close cache.
+//Return any error if raised
+func (c *Cache) Close() error {
+	return c.Flush()
+}
+
+//Del Delete data in cache by given key.
+//Return any error raised.
+func (c *Cache) Del(key string) error {
+	s, h := c.shard(key)
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	s.del(h, key)
+	return nil
+}
+
+//IncrCounter Increase int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Return int data value and any error raised.
+func (c *Cache) IncrCounter(key string, increment int64, ttl time.Duration) (int64, error) {
+	var v int64
+	s, h := c.shard(key)
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	data, _, ok := s.get(h, key, time.Now().UnixNano())
+	if ok {
+		current, err := CounterEncoding.DecodeCounter(data)
+		if err == nil {
+			v = current
+		}
+	}
+	v = v + increment
+	bs := CounterEncoding.EncodeCounter(v)
+	if err := c.checkSize(key, bs); err != nil {
+		return 0, err
+	}
+	s.set(h, key, bs, expiredTime(ttl))
+	return v, nil
+}
+
+//SetCounter Set int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Return any error raised.
+func (c *Cache) SetCounter(key string, v int64, ttl time.Duration) error {
+	return c.SetBytesValue(key, CounterEncoding.EncodeCounter(v), ttl)
+}
+
+//GetCounter Get int val from cache by given key.Count cache and data cache are in two independent namespace.
+//Return int data value and any error raised.
+func (c *Cache) GetCounter(key string) (int64, error) {
+	data, ok := c.get(key)
+	if !ok {
+		return 0, cache.ErrNotFound
+	}
+	v, err := CounterEncoding.DecodeCounter(data)
+	if err != nil {
+		return 0, cache.ErrNotFound
+	}
+	return v, nil
+}
+
+//DelCounter Delete int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Return any error raised.
+func (c *Cache) DelCounter(key string) error {
+	return c.Del(key)
+}
+
+//Expire set cache value expire duration by given key and ttl.
+//Expired time is updated in place without rewriting entry.
+func (c *Cache) Expire(key string, ttl time.Duration) error {
+	s, h := c.shard(key)
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	if _, _, ok := s.get(h, key, time.Now().UnixNano()); !ok {
+		return cache.ErrNotFound
+	}
+	s.setExpired(h, key, expiredTime(ttl))
+	return nil
+}
+
+//ExpireCounter set cache counter  expire duration by given key and ttl
+func (c *Cache) ExpireCounter(key string, ttl time.Duration) error {
+	return c.Expire(key, ttl)
+}
+
+//GetTTL get remaining ttl of given key.
+//Return ttl and any error raised.
+//Zero ttl means entry never expires.
+func (c *Cache) GetTTL(key string) (time.Duration, error) {
+	s, h := c.shard(key)
+	s.locker.RLock()
+	defer s.locker.RUnlock()
+	now := time.Now()
+	_, expired, ok := s.get(h, key, now.UnixNano())
+	if !ok {
+		return 0, cache.ErrNotFound
+	}
+	if expired == 0 {
+		return 0, nil
+	}
+	return time.Duration(expired - now.UnixNano()), nil
+}
+
+//Config Cache driver config.
+type Config struct {
+	//Size total ring buffer size in bytes.Default value is 64MB.
+	//Oldest entries are overwritten when ring buffer is full.
+	Size int
+	//Shards count of ring buffers which are locked independently.Default value is 256.
+	Shards int
+}
+
+//Create new cache driver.
+//Return cache driver created and any error if raised.
+func (config *Config) Create() (cache.Driver, error) {
+	size := config.Size
+	if size <= 0 {
+		size = defaultSize
+	}
+	shards := config.Shards
+	if shards <= 0 {
+		shards = defaultShards
+	}
+	if size/shards < minShardSize {
+		shards = size / minShardSize
+		if shards < 1 {
+			shards = 1
+		}
+	}
+	for size/shards > maxShardSize {
+		shards = shards * 2
+	}
+	c := &Cache{
+		shards:    make([]*shard, shards),
+		shardSize: size / shards,
+	}
+	for k := range c.shards {
+		c.shards[k] = newShard(c.shardSize)
+	}
+	return c, nil
+}
+
+func init() {
+	cache.Register("ringcache", func(loader func(interface{}) error) (cache.Driver, error) {
+		c := &Config{}
+		err := loader(c)
+		if err != nil {
+			return nil, err
+		}
+		return c.Create()
+	})
+}
@@ -0,0 +1,166 @@
+package ringcache
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+func newTestCache(size int) *cache.Cache {
+	c := cache.New()
+	oc := cache.NewOptionConfig()
+	oc.Driver = "ringcache"
+	oc.TTL = 3600
+	oc.Config = func(v interface{}) error {
+		v.(*Config).Size = size
+		return nil
+	}
+	oc.Marshaler = "json"
+	err := c.Init(oc)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func BenchmarkCacheRead(b *testing.B) {
+	c := newTestCache(10000000)
+	var data = bytes.Repeat([]byte("12345"), 100)
+	c.SetBytesValue("test", data, time.Hour)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			c.GetBytesValue("test")
+		}
+	})
+}
+
+func BenchmarkCacheWrite(b *testing.B) {
+	c := newTestCache(10000000)
+	var data = bytes.Repeat([]byte("12345"), 100)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			c.SetBytesValue("test", data, time.Hour)
+		}
+	})
+}
+
+func TestShard(t *testing.T) {
+	s := newShard(100)
+	data := bytes.Repeat([]byte("a"), 18)
+	for i := 0; i < 10; i++ {
+		key := strconv.Itoa(i)
+		s.set(hashKey(key), key, data, 0)
+		if _, _, ok := s.get(hashKey(key), key, 0); !ok {
+			t.Fatal(i)
+		}
+		if s.used > 100 || len(s.index) > 2 {
+			t.Fatal(s.used, len(s.index))
+		}
+	}
+	if _, _, ok := s.get(hashKey("8"), "8", 0); !ok {
+		t.Fatal()
+	}
+	if _, _, ok := s.get(hashKey("7"), "7", 0); ok {
+		t.Fatal()
+	}
+	s.set(hashKey("9"), "9", []byte("new"), 0)
+	result, _, ok := s.get(hashKey("9"), "9", 0)
+	if !ok || string(result) != "new" {
+		t.Fatal(string(result), ok)
+	}
+	s.del(hashKey("9"), "9")
+	if _, _, ok := s.get(hashKey("9"), "9", 0); ok {
+		t.Fatal()
+	}
+	s.set(hashKey("expired"), "expired", data, 10)
+	if _, _, ok := s.get(hashKey("expired"), "expired", 10); ok {
+		t.Fatal()
+	}
+	if _, _, ok := s.get(hashKey("expired"), "other", 0); ok {
+		t.Fatal()
+	}
+	s.reset()
+	if len(s.index) != 0 || s.used != 0 {
+		t.Fatal(s)
+	}
+}
+
+func TestRingCache(t *testing.T) {
+	c := newTestCache(minShardSize)
+	defer c.Close()
+	err := c.Set("test", "value", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result string
+	err = c.Get("test", &result)
+	if err != nil || result != "value" {
+		t.Fatal(result, err)
+	}
+	ttl, err := c.GetTTL("test")
+	if err != nil || ttl <= 0 || ttl > time.Hour {
+		t.Fatal(ttl, err)
+	}
+	err = c.Expire("test", time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	err = c.Get("test", &result)
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	ok, err := c.SetIfNotExists("test", []byte("value"), time.Hour)
+	if !ok || err != nil {
+		t.Fatal(ok, err)
+	}
+	ok, err = c.SetIfNotExists("test", []byte("new"), time.Hour)
+	if ok || err != nil {
+		t.Fatal(ok, err)
+	}
+	_, err = c.IncrCounter("counter", 2, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := c.IncrCounter("counter", 3, time.Hour)
+	if err != nil || v != 5 {
+		t.Fatal(v, err)
+	}
+	err = c.SetBytesValue("large", make([]byte, minShardSize), time.Hour)
+	if !errors.Is(err, cache.ErrEntryTooLarge) {
+		t.Fatal(err)
+	}
+	data := bytes.Repeat([]byte("a"), 1000)
+	for i := 0; i < 1000; i++ {
+		err = c.SetBytesValue(strconv.Itoa(i), data, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err = c.GetBytesValue("0")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	bs, err := c.GetBytesValue("999")
+	if err != nil || !bytes.Equal(bs, data) {
+		t.Fatal(err)
+	}
+	stats, err := c.Stats()
+	if err != nil || stats.Entries == 0 || stats.Entries > int64(minShardSize/1000) || stats.Bytes > int64(minShardSize) {
+		t.Fatal(stats, err)
+	}
+	err = c.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.GetBytesValue("999")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+}
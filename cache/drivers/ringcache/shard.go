@@ -0,0 +1,155 @@
+package ringcache
+
+import (
+	"encoding/binary"
+	"sync"
+)
+
+//Entry layout in ring buffer:
+//4 bytes entry size,8 bytes key hash,8 bytes expired unix nano,2 bytes key length,key and data.
+const (
+	offsetHash    = 4
+	offsetExpired = 12
+	offsetKeyLen  = 20
+	headerSize    = 22
+)
+
+//maxKeyLength max length of key stored in entry header.
+const maxKeyLength = 1<<16 - 1
+
+//shard ring buffer of entries with index from key hash to entry offset.
+//Index contains no pointers,so garbage collector does not scan entries.
+type shard struct {
+	locker sync.RWMutex
+	index  map[uint64]uint32
+	buf    []byte
+	//head offset of oldest entry.
+	head uint32
+	//tail offset where next entry is written.
+	tail uint32
+	//end end of written data when tail wrapped to start of buffer.
+	end uint32
+	//used bytes used by entries between head and tail,including overwritten and deleted entries.
+	used uint32
+}
+
+func newShard(size int) *shard {
+	return &shard{
+		index: map[uint64]uint32{},
+		buf:   make([]byte, size),
+	}
+}
+
+func (s *shard) reset() {
+	s.index = map[uint64]uint32{}
+	s.head = 0
+	s.tail = 0
+	s.end = 0
+	s.used = 0
+}
+
+//lookup find entry offset of given key.
+//Entry with same hash but different key is treated as not found.
+func (s *shard) lookup(hash uint64, key string) (uint32, bool) {
+	offset, ok := s.index[hash]
+	if !ok {
+		return 0, false
+	}
+	keylen := uint32(binary.BigEndian.Uint16(s.buf[offset+offsetKeyLen:]))
+	if string(s.buf[offset+headerSize:offset+headerSize+keylen]) != key {
+		return 0, false
+	}
+	return offset, true
+}
+
+//get get data and expired unix nano of given key.
+//Returned data refers to ring buffer,caller should copy data before lock released.
+//Return whether entry exists and not expired.
+func (s *shard) get(hash uint64, key string, now int64) ([]byte, int64, bool) {
+	offset, ok := s.lookup(hash, key)
+	if !ok {
+		return nil, 0, false
+	}
+	expired := int64(binary.BigEndian.Uint64(s.buf[offset+offsetExpired:]))
+	if expired != 0 && expired <= now {
+		return nil, expired, false
+	}
+	size := binary.BigEndian.Uint32(s.buf[offset:])
+	return s.buf[offset+headerSize+uint32(len(key)) : offset+size], expired, true
+}
+
+//setExpired set expired unix nano of given key in place.
+//Return whether entry exists.
+func (s *shard) setExpired(hash uint64, key string, expired int64) bool {
+	offset, ok := s.lookup(hash, key)
+	if !ok {
+		return false
+	}
+	binary.BigEndian.PutUint64(s.buf[offset+offsetExpired:], uint64(expired))
+	return true
+}
+
+func (s *shard) del(hash uint64, key string) {
+	if _, ok := s.lookup(hash, key); ok {
+		delete(s.index, hash)
+	}
+}
+
+//evict evict oldest entry at head.
+func (s *shard) evict() {
+	size := binary.BigEndian.Uint32(s.buf[s.head:])
+	hash := binary.BigEndian.Uint64(s.buf[s.head+offsetHash:])
+	if offset, ok := s.index[hash]; ok && offset == s.head {
+		delete(s.index, hash)
+	}
+	s.head += size
+	s.used -= size
+	if s.head == s.end && s.tail <= s.head {
+		s.head = 0
+		s.end = 0
+	}
+}
+
+//alloc evict oldest entries until n bytes available at tail.
+//Entry size should not be larger than buffer size.
+//Return offset of allocated space.
+func (s *shard) alloc(n uint32) uint32 {
+	size := uint32(len(s.buf))
+	for {
+		if s.used == 0 {
+			s.head = 0
+			s.tail = 0
+			s.end = 0
+		}
+		if s.tail > s.head || s.used == 0 {
+			if size-s.tail >= n {
+				break
+			}
+			s.end = s.tail
+			s.tail = 0
+			continue
+		}
+		if s.head-s.tail >= n {
+			break
+		}
+		s.evict()
+	}
+	offset := s.tail
+	s.tail += n
+	s.used += n
+	return offset
+}
+
+//set write entry of given key to ring buffer and update index.
+func (s *shard) set(hash uint64, key string, data []byte, expired int64) {
+	n := uint32(headerSize + len(key) + len(data))
+	offset := s.alloc(n)
+	b := s.buf[offset : offset+n]
+	binary.BigEndian.PutUint32(b, n)
+	binary.BigEndian.PutUint64(b[offsetHash:], hash)
+	binary.BigEndian.PutUint64(b[offsetExpired:], uint64(expired))
+	binary.BigEndian.PutUint16(b[offsetKeyLen:], uint16(len(key)))
+	copy(b[headerSize:], key)
+	copy(b[headerSize+len(key):], data)
+	s.index[hash] = offset
+}
@@ -0,0 +1,304 @@
+//Package ristrettocache provides cache driver uses memory to store cache data.
+//Using github.com/dgraph-io/ristretto as driver.
+//Entries are admitted and evicted by TinyLFU policy within memory budget in bytes,
+//so hot entries are kept when key popularity is skewed.
+package ristrettocache
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/ristretto"
+	"github.com/herb-go/deprecated/cache"
+)
+
+var defaultBufferItems int64 = 64
+
+//defaultEntrySize estimated average entry size used to compute default counter number.
+var defaultEntrySize int64 = 1024
+
+var minNumCounters int64 = 1000
+
+//CounterEncoding encoding used to store counter values.
+var CounterEncoding cache.CounterEncoding = cache.BigEndianCounterEncoding{}
+
+//entry value stored in ristretto cache.
+//Key is stored with data so that evicted entries can be reported with key.
+type entry struct {
+	key  string
+	data []byte
+}
+
+//Cache The ristretto cache Driver.
+type Cache struct {
+	cache.DriverUtil
+	ristretto     *ristretto.Cache
+	asyncWrites   bool
+	lock          sync.Mutex
+	evictedLocker sync.RWMutex
+	onEvicted     func(key string, value []byte)
+}
+
+//SetGCErrHandler Set callback to handler error raised when gc.
+func (c *Cache) SetGCErrHandler(f func(err error)) {
+	return
+}
+
+//OnEvicted register callback called with key and value of every entry evicted due to memory budget or expiration.
+func (c *Cache) OnEvicted(f func(key string, value []byte)) {
+	c.evictedLocker.Lock()
+	c.onEvicted = f
+	c.evictedLocker.Unlock()
+}
+
+func (c *Cache) evicted(item *ristretto.Item) {
+	e, ok := item.Value.(*entry)
+	if !ok {
+		return
+	}
+	c.evictedLocker.RLock()
+	f := c.onEvicted
+	c.evictedLocker.RUnlock()
+	if f != nil {
+		f(e.key, e.data)
+	}
+}
+
+func (c *Cache) get(key string) ([]byte, bool) {
+	v, ok := c.ristretto.Get(key)
+	if !ok {
+		return nil, false
+	}
+	e, ok := v.(*entry)
+	if !ok {
+		return nil, false
+	}
+	return e.data, true
+}
+
+//set set data to ristretto cache with data size as cost.
+//Set is waited to be applied unless async writes is enabled.
+//Entry may be rejected by admission policy,in which case data will not be cached.
+func (c *Cache) set(key string, data []byte, ttl time.Duration) {
+	bs := make([]byte, len(data))
+	copy(bs, data)
+	if ttl < 0 {
+		ttl = 0
+	}
+	c.ristretto.SetWithTTL(key, &entry{key: key, data: bs}, int64(len(key)+len(bs)), ttl)
+	if !c.asyncWrites {
+		c.ristretto.Wait()
+	}
+}
+
+//SetBytesValue Set bytes data to cache by given key.
+//Return any error raised.
+func (c *Cache) SetBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.set(key, bytes, ttl)
+	return nil
+}
+
+//UpdateBytesValue Update bytes data to cache by given key only if the cache exist.
+//Return any error raised.
+func (c *Cache) UpdateBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, ok := c.get(key); !ok {
+		return nil
+	}
+	c.set(key, bytes, ttl)
+	return nil
+}
+
+//GetBytesValue Get bytes data from cache by given key.
+//Return data bytes and any error raised.
+func (c *Cache) GetBytesValue(key string) ([]byte, error) {
+	data, ok := c.get(key)
+	if !ok {
+		return nil, cache.ErrNotFound
+	}
+	bs := make([]byte, len(data))
+	copy(bs, data)
+	return bs, nil
+}
+
+//MGetBytesValue get multiple bytes data from cache by given keys.
+//Return data bytes map and any error if raised.
+func (c *Cache) MGetBytesValue(keys ...string) (map[string][]byte, error) {
+	var result = make(map[string][]byte, len(keys))
+	for _, k := range keys {
+		if data, ok := c.get(k); ok {
+			bs := make([]byte, len(data))
+			copy(bs, data)
+			result[k] = bs
+		}
+	}
+	return result, nil
+}
+
+//MSetBytesValue set multiple bytes data to cache with given key-value map.
+//Return  any error if raised.
+func (c *Cache) MSetBytesValue(data map[string][]byte, ttl time.Duration) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for k := range data {
+		c.set(k, data[k], ttl)
+	}
+	return nil
+}
+
+//Capabilities return capabilities supported by cache.
+func (c *Cache) Capabilities() cache.Capabilities {
+	return cache.CapabilityCounter | cache.CapabilityFlush | cache.CapabilityTTL
+}
+
+//Flush Delete all data in cache.
+//Return any error if raised
+func (c *Cache) Flush() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.ristretto.Clear()
+	return nil
+}
+
+//Close Close cache.
+//Return any error if raised
+func (c *Cache) Close() error {
+	c.ristretto.Close()
+	return nil
+}
+
+//Del Delete data in cache by given key.
+//Return any error raised.
+func (c *Cache) Del(key string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.ristretto.Del(key)
+	return nil
+}
+
+//IncrCounter Increase int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Return int data value and any error raised.
+func (c *Cache) IncrCounter(key string, increment int64, ttl time.Duration) (int64, error) {
+	var v int64
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	data, ok := c.get(key)
+	if ok {
+		current, err := CounterEncoding.DecodeCounter(data)
+		if err == nil {
+			v = current
+		}
+	}
+	v = v + increment
+	c.set(key, CounterEncoding.EncodeCounter(v), ttl)
+	return v, nil
+}
+
+//SetCounter Set int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Return any error raised.
+func (c *Cache) SetCounter(key string, v int64, ttl time.Duration) error {
+	return c.SetBytesValue(key, CounterEncoding.EncodeCounter(v), ttl)
+}
+
+//GetCounter Get int val from cache by given key.Count cache and data cache are in two independent namespace.
+//Return int data value and any error raised.
+func (c *Cache) GetCounter(key string) (int64, error) {
+	data, ok := c.get(key)
+	if !ok {
+		return 0, cache.ErrNotFound
+	}
+	v, err := CounterEncoding.DecodeCounter(data)
+	if err != nil {
+		return 0, cache.ErrNotFound
+	}
+	return v, nil
+}
+
+//DelCounter Delete int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Return any error raised.
+func (c *Cache) DelCounter(key string) error {
+	return c.Del(key)
+}
+
+//Expire set cache value expire duration by given key and ttl
+func (c *Cache) Expire(key string, ttl time.Duration) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	data, ok := c.get(key)
+	if !ok {
+		return cache.ErrNotFound
+	}
+	c.set(key, data, ttl)
+	return nil
+}
+
+//ExpireCounter set cache counter  expire duration by given key and ttl
+func (c *Cache) ExpireCounter(key string, ttl time.Duration) error {
+	return c.Expire(key, ttl)
+}
+
+//GetTTL get remaining ttl of given key.
+//Return ttl and any error raised.
+//Zero ttl means entry never expires.
+func (c *Cache) GetTTL(key string) (time.Duration, error) {
+	ttl, ok := c.ristretto.GetTTL(key)
+	if !ok {
+		return 0, cache.ErrNotFound
+	}
+	return ttl, nil
+}
+
+//Config Cache driver config.
+type Config struct {
+	//Size memory budget in bytes.
+	//Cost of every entry is length of key and data.
+	Size int64
+	//NumCounters number of keys to track access frequency.
+	//Recommended value is 10 times of max entries.
+	//Default value is computed from size with 1KB average entry size.
+	NumCounters int64
+	//AsyncWrites whether return before written data is applied.
+	//Write performance is improved,but data set may not be read immediately.
+	AsyncWrites bool
+}
+
+//Create new cache driver.
+//Return cache driver created and any error if raised.
+func (config *Config) Create() (cache.Driver, error) {
+	c := &Cache{
+		asyncWrites: config.AsyncWrites,
+	}
+	numCounters := config.NumCounters
+	if numCounters <= 0 {
+		numCounters = config.Size / defaultEntrySize * 10
+	}
+	if numCounters < minNumCounters {
+		numCounters = minNumCounters
+	}
+	r, err := ristretto.NewCache(&ristretto.Config{
+		NumCounters:        numCounters,
+		MaxCost:            config.Size,
+		BufferItems:        defaultBufferItems,
+		IgnoreInternalCost: true,
+		OnEvict:            c.evicted,
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.ristretto = r
+	return c, nil
+}
+
+func init() {
+	cache.Register("ristrettocache", func(loader func(interface{}) error) (cache.Driver, error) {
+		c := &Config{}
+		err := loader(c)
+		if err != nil {
+			return nil, err
+		}
+		return c.Create()
+	})
+}
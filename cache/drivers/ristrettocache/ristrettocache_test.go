@@ -0,0 +1,108 @@
+package ristrettocache
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+func newTestCache(size int64) *cache.Cache {
+	c := cache.New()
+	oc := cache.NewOptionConfig()
+	oc.Driver = "ristrettocache"
+	oc.TTL = 3600
+	oc.Config = func(v interface{}) error {
+		v.(*Config).Size = size
+		return nil
+	}
+	oc.Marshaler = "json"
+	err := c.Init(oc)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func BenchmarkCacheRead(b *testing.B) {
+	c := newTestCache(10000000)
+	defer c.Close()
+	var data = bytes.Repeat([]byte("12345"), 100)
+	c.SetBytesValue("test", data, time.Hour)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			c.GetBytesValue("test")
+		}
+	})
+}
+
+func TestRistrettoCache(t *testing.T) {
+	c := newTestCache(10000000)
+	defer c.Close()
+	err := c.Set("test", "value", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result string
+	err = c.Get("test", &result)
+	if err != nil || result != "value" {
+		t.Fatal(result, err)
+	}
+	ttl, err := c.GetTTL("test")
+	if err != nil || ttl <= 0 || ttl > time.Hour {
+		t.Fatal(ttl, err)
+	}
+	_, err = c.IncrCounter("counter", 2, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := c.IncrCounter("counter", 3, time.Hour)
+	if err != nil || v != 5 {
+		t.Fatal(v, err)
+	}
+	err = c.Del("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.Get("test", &result)
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	err = c.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.GetCounter("counter")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+}
+
+func TestMemoryBudget(t *testing.T) {
+	c := newTestCache(100000)
+	defer c.Close()
+	if c.OnEvicted(func(key string, value []byte) {}) != nil {
+		t.Fatal()
+	}
+	data := bytes.Repeat([]byte("a"), 1000)
+	for i := 0; i < 1000; i++ {
+		err := c.SetBytesValue(strconv.Itoa(i), data, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	var found int
+	for i := 0; i < 1000; i++ {
+		_, err := c.GetBytesValue(strconv.Itoa(i))
+		if err == nil {
+			found++
+		}
+	}
+	if found == 0 || found > 100 {
+		t.Fatal(found)
+	}
+}
@@ -0,0 +1,276 @@
+//Package schemacache provides a cache driver decorator which tags entries with application schema version.
+//Entries written by other schema versions are treated as misses,so that deploys changing struct layouts
+//never unmarshal stale incompatible data.
+package schemacache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+//Schema modes
+const (
+	//ModeKey prepend schema version to every key.
+	//Entries of other versions are never read and expire by ttl.
+	ModeKey = "key"
+	//ModeValue embed schema version in value header.
+	//Keys are shared by all versions,so that deleting key in one version invalidates entries of all versions.
+	ModeValue = "value"
+)
+
+//ErrEmptyVersion error raised if schema version is empty.
+var ErrEmptyVersion = errors.New("schemacache: empty schema version")
+
+//ErrUnknownMode error raised if schema mode is unknown.
+var ErrUnknownMode = errors.New("schemacache: unknown schema mode")
+
+//ErrVersionTooLong error raised if schema version is longer than 255 bytes in value mode.
+var ErrVersionTooLong = errors.New("schemacache: schema version too long")
+
+//Cache The schema version cache driver.
+type Cache struct {
+	cache.DriverUtil
+	//Cache wrapped cache.
+	Cache *cache.Cache
+	//Version application schema version.
+	Version string
+	//Mode schema mode,ModeKey or ModeValue.
+	Mode   string
+	header []byte
+	prefix string
+}
+
+//New create new schema version cache driver with given wrapped cache,version and mode.
+//Return driver created and any error if raised.
+func New(wrapped *cache.Cache, version string, mode string) (*Cache, error) {
+	if version == "" {
+		return nil, ErrEmptyVersion
+	}
+	c := &Cache{
+		Cache:   wrapped,
+		Version: version,
+		Mode:    mode,
+	}
+	switch mode {
+	case ModeKey:
+		c.prefix = version + cache.KeyPrefix
+	case ModeValue:
+		if len(version) > 255 {
+			return nil, ErrVersionTooLong
+		}
+		c.header = append([]byte{byte(len(version))}, version...)
+	default:
+		return nil, ErrUnknownMode
+	}
+	return c, nil
+}
+
+func (c *Cache) key(key string) string {
+	return c.prefix + key
+}
+
+func (c *Cache) encode(data []byte) []byte {
+	if c.header == nil {
+		return data
+	}
+	buf := make([]byte, len(c.header)+len(data))
+	copy(buf, c.header)
+	copy(buf[len(c.header):], data)
+	return buf
+}
+
+//decode return data without header.
+//Return ErrNotFound if data is written by other schema version.
+func (c *Cache) decode(data []byte) ([]byte, error) {
+	if c.header == nil {
+		return data, nil
+	}
+	if !bytes.HasPrefix(data, c.header) {
+		return nil, cache.ErrNotFound
+	}
+	return data[len(c.header):], nil
+}
+
+//SetBytesValue Set bytes data to cache by given key.
+//Return any error raised.
+func (c *Cache) SetBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	return c.Cache.SetBytesValue(c.key(key), c.encode(bytes), ttl)
+}
+
+//UpdateBytesValue Update bytes data to cache by given key only if the cache exist.
+//In value mode entries written by other schema versions are also updated.
+//Return any error raised.
+func (c *Cache) UpdateBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	return c.Cache.UpdateBytesValue(c.key(key), c.encode(bytes), ttl)
+}
+
+//GetBytesValue Get bytes data from cache by given key.
+//Return ErrNotFound if entry is written by other schema version.
+//Return data bytes and any error raised.
+func (c *Cache) GetBytesValue(key string) ([]byte, error) {
+	data, err := c.Cache.GetBytesValue(c.key(key))
+	if err != nil {
+		return nil, err
+	}
+	return c.decode(data)
+}
+
+//MGetBytesValue get multiple bytes data from cache by given keys.
+//Entries written by other schema versions are omitted.
+//Return data bytes map and any error if raised.
+func (c *Cache) MGetBytesValue(keys ...string) (map[string][]byte, error) {
+	keymap := make(map[string]string, len(keys))
+	wrappedkeys := make([]string, len(keys))
+	for k := range keys {
+		wrappedkeys[k] = c.key(keys[k])
+		keymap[wrappedkeys[k]] = keys[k]
+	}
+	data, err := c.Cache.MGetBytesValue(wrappedkeys...)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string][]byte, len(data))
+	for k, v := range data {
+		decoded, err := c.decode(v)
+		if err != nil {
+			continue
+		}
+		result[keymap[k]] = decoded
+	}
+	return result, nil
+}
+
+//MSetBytesValue set multiple bytes data to cache with given key-value map.
+//Return  any error if raised.
+func (c *Cache) MSetBytesValue(data map[string][]byte, ttl time.Duration) error {
+	encoded := make(map[string][]byte, len(data))
+	for k, v := range data {
+		encoded[c.key(k)] = c.encode(v)
+	}
+	return c.Cache.MSetBytesValue(encoded, ttl)
+}
+
+//Del Delete data in cache by given key.
+//Return any error raised.
+func (c *Cache) Del(key string) error {
+	return c.Cache.Del(c.key(key))
+}
+
+//Expire set cache value expire duration by given key and ttl
+//Return any error raised.
+func (c *Cache) Expire(key string, ttl time.Duration) error {
+	return c.Cache.Expire(c.key(key), ttl)
+}
+
+//SetCounter Set int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Counters are not tagged in value mode.
+//Return any error raised.
+func (c *Cache) SetCounter(key string, v int64, ttl time.Duration) error {
+	return c.Cache.SetCounter(c.key(key), v, ttl)
+}
+
+//GetCounter Get int val from cache by given key.Count cache and data cache are in two independent namespace.
+//Counters are not tagged in value mode.
+//Return int data value and any error raised.
+func (c *Cache) GetCounter(key string) (int64, error) {
+	return c.Cache.GetCounter(c.key(key))
+}
+
+//IncrCounter Increase int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Counters are not tagged in value mode.
+//Return int data value and any error raised.
+func (c *Cache) IncrCounter(key string, increment int64, ttl time.Duration) (int64, error) {
+	return c.Cache.IncrCounter(c.key(key), increment, ttl)
+}
+
+//ExpireCounter set cache counter expire duration by given key and ttl
+//Return any error raised.
+func (c *Cache) ExpireCounter(key string, ttl time.Duration) error {
+	return c.Cache.ExpireCounter(c.key(key), ttl)
+}
+
+//DelCounter Delete int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Return any error raised.
+func (c *Cache) DelCounter(key string) error {
+	return c.Cache.DelCounter(c.key(key))
+}
+
+//SetGCErrHandler Set callback to handler error raised when gc.
+func (c *Cache) SetGCErrHandler(f func(err error)) {
+	c.Cache.SetGCErrHandler(f)
+}
+
+//Ping check if wrapped cache is reachable before context done.
+//Return any error raised.
+func (c *Cache) Ping(ctx context.Context) error {
+	return c.Cache.Ping(ctx)
+}
+
+//Capabilities return capabilities supported by wrapped cache.
+func (c *Cache) Capabilities() cache.Capabilities {
+	return c.Cache.Capabilities() & (cache.CapabilityMGet | cache.CapabilityCounter | cache.CapabilityFlush | cache.CapabilityPing)
+}
+
+//Close Close wrapped cache.
+//Return any error if raised
+func (c *Cache) Close() error {
+	return c.Cache.Close()
+}
+
+//Flush Delete all data in wrapped cache.
+//Entries of all schema versions are deleted.
+//Return any error if raised
+func (c *Cache) Flush() error {
+	return c.Cache.Flush()
+}
+
+//Config schema version cache driver config.
+type Config struct {
+	//Cache wrapped cache config.
+	Cache *cache.OptionConfig
+	//Version application schema version.
+	Version string
+	//Mode schema mode,"key" or "value".
+	//ModeKey will be used if empty.
+	Mode string
+}
+
+//Create create new cache driver.
+//Return driver created and any error if raised.
+func (c *Config) Create() (cache.Driver, error) {
+	mode := c.Mode
+	if mode == "" {
+		mode = ModeKey
+	}
+	if mode != ModeKey && mode != ModeValue {
+		return nil, ErrUnknownMode
+	}
+	if c.Version == "" {
+		return nil, ErrEmptyVersion
+	}
+	wrapped, err := cache.NewSubCache(c.Cache)
+	if err != nil {
+		return nil, err
+	}
+	d, err := New(wrapped, c.Version, mode)
+	if err != nil {
+		wrapped.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+func init() {
+	cache.Register("schemacache", func(loader func(interface{}) error) (cache.Driver, error) {
+		c := &Config{}
+		err := loader(c)
+		if err != nil {
+			return nil, err
+		}
+		return c.Create()
+	})
+}
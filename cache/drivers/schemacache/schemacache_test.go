@@ -0,0 +1,122 @@
+package schemacache
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+	_ "github.com/herb-go/deprecated/cache/drivers/syncmapcache"
+)
+
+func newSubCache() *cache.Cache {
+	buf := bytes.NewBufferString(`{"Size":10000000}`)
+	oc := cache.NewOptionConfig()
+	oc.Driver = "syncmapcache"
+	oc.TTL = 3600
+	oc.Marshaler = "json"
+	oc.Config = json.NewDecoder(buf).Decode
+	c, err := cache.NewSubCache(oc)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func newTestCache(wrapped *cache.Cache, version string, mode string) *cache.Cache {
+	d, err := New(wrapped, version, mode)
+	if err != nil {
+		panic(err)
+	}
+	d.SetUtil(wrapped.Util())
+	c := cache.New()
+	c.Driver = d
+	c.TTL = time.Hour
+	return c
+}
+
+func testSchema(t *testing.T, mode string) (*cache.Cache, *cache.Cache) {
+	wrapped := newSubCache()
+	v1 := newTestCache(wrapped, "v1", mode)
+	v2 := newTestCache(wrapped, "v2", mode)
+	err := v1.SetBytesValue("test", []byte("v1"), cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := v1.GetBytesValue("test")
+	if err != nil || string(bs) != "v1" {
+		t.Fatal(string(bs), err)
+	}
+	_, err = v2.GetBytesValue("test")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	data, err := v2.MGetBytesValue("test")
+	if err != nil || len(data) != 0 {
+		t.Fatal(data, err)
+	}
+	err = v2.SetBytesValue("test2", []byte("v2"), cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err = v2.MGetBytesValue("test", "test2")
+	if err != nil || len(data) != 1 || string(data["test2"]) != "v2" {
+		t.Fatal(data, err)
+	}
+	return v1, v2
+}
+
+func TestKeyMode(t *testing.T) {
+	v1, v2 := testSchema(t, ModeKey)
+	err := v2.SetBytesValue("test", []byte("v2"), cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := v1.GetBytesValue("test")
+	if err != nil || string(bs) != "v1" {
+		t.Fatal(string(bs), err)
+	}
+	_, err = v2.IncrCounter("counter", 1, cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = v1.GetCounter("counter")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+}
+
+func TestValueMode(t *testing.T) {
+	v1, v2 := testSchema(t, ModeValue)
+	err := v2.Del("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = v1.GetBytesValue("test")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	_, err = v2.IncrCounter("counter", 1, cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := v1.GetCounter("counter")
+	if err != nil || v != 1 {
+		t.Fatal(v, err)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	c := &Config{Version: "v1", Mode: "unknown"}
+	_, err := c.Create()
+	if err != ErrUnknownMode {
+		t.Fatal(err)
+	}
+	c = &Config{}
+	_, err = c.Create()
+	if err != ErrEmptyVersion {
+		t.Fatal(err)
+	}
+}
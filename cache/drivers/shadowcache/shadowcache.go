@@ -0,0 +1,278 @@
+//Package shadowcache provides a cache driver which writes to both old and new cache,
+//and reads from new cache with fallback to old cache.
+//Used to migrate data between cache drivers without downtime.
+package shadowcache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+//Stats migration stats.
+type Stats struct {
+	//Reads count of read operations.
+	Reads int64
+	//Fallbacks count of reads which fell back to old cache.
+	Fallbacks int64
+	//Compared count of reads compared with old cache.
+	Compared int64
+	//Mismatches count of compared reads which value in new cache is different from old cache.
+	Mismatches int64
+	//OldErrors count of errors raised by old cache when writing.
+	OldErrors int64
+}
+
+//Cache The shadow cache driver.
+type Cache struct {
+	cache.DriverUtil
+	//Old cache which is migrating from.
+	Old *cache.Cache
+	//New cache which is migrating to.
+	New *cache.Cache
+	//Compare whether compare value in new cache with old cache when reading.
+	Compare bool
+	//Backfill whether copy value to new cache when reading falls back to old cache.
+	Backfill bool
+	//OnMismatch hook called with key when value in new cache is different from old cache.
+	OnMismatch func(key string)
+	stats      Stats
+}
+
+//Stats return copy of migration stats.
+func (c *Cache) Stats() *Stats {
+	return &Stats{
+		Reads:      atomic.LoadInt64(&c.stats.Reads),
+		Fallbacks:  atomic.LoadInt64(&c.stats.Fallbacks),
+		Compared:   atomic.LoadInt64(&c.stats.Compared),
+		Mismatches: atomic.LoadInt64(&c.stats.Mismatches),
+		OldErrors:  atomic.LoadInt64(&c.stats.OldErrors),
+	}
+}
+
+func (c *Cache) write(newErr error, oldErr error) error {
+	if oldErr != nil && !errors.Is(oldErr, cache.ErrNotFound) {
+		atomic.AddInt64(&c.stats.OldErrors, 1)
+	}
+	if newErr != nil {
+		return newErr
+	}
+	return oldErr
+}
+
+func (c *Cache) compare(key string, data []byte) {
+	atomic.AddInt64(&c.stats.Compared, 1)
+	old, err := c.Old.GetBytesValue(key)
+	if err == nil && bytes.Equal(old, data) {
+		return
+	}
+	atomic.AddInt64(&c.stats.Mismatches, 1)
+	if c.OnMismatch != nil {
+		c.OnMismatch(key)
+	}
+}
+
+//SetBytesValue Set bytes data to both caches by given key.
+//Return any error raised.
+func (c *Cache) SetBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	return c.write(c.New.SetBytesValue(key, bytes, ttl), c.Old.SetBytesValue(key, bytes, ttl))
+}
+
+//UpdateBytesValue Update bytes data to both caches by given key only if the cache exist.
+//Return any error raised.
+func (c *Cache) UpdateBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	return c.write(c.New.UpdateBytesValue(key, bytes, ttl), c.Old.UpdateBytesValue(key, bytes, ttl))
+}
+
+//GetBytesValue Get bytes data from new cache by given key.
+//Old cache will be used if data not found in new cache.
+//Return data bytes and any error raised.
+func (c *Cache) GetBytesValue(key string) ([]byte, error) {
+	atomic.AddInt64(&c.stats.Reads, 1)
+	data, err := c.New.GetBytesValue(key)
+	if err == nil {
+		if c.Compare {
+			c.compare(key, data)
+		}
+		return data, nil
+	}
+	if !errors.Is(err, cache.ErrNotFound) {
+		return nil, err
+	}
+	atomic.AddInt64(&c.stats.Fallbacks, 1)
+	data, err = c.Old.GetBytesValue(key)
+	if err != nil {
+		return nil, err
+	}
+	if c.Backfill {
+		c.New.SetBytesValue(key, data, cache.DefaultTTL)
+	}
+	return data, nil
+}
+
+//MGetBytesValue get multiple bytes data from new cache by given keys.
+//Old cache will be used for keys not found in new cache.
+//Return data bytes map and any error if raised.
+func (c *Cache) MGetBytesValue(keys ...string) (map[string][]byte, error) {
+	atomic.AddInt64(&c.stats.Reads, int64(len(keys)))
+	data, err := c.New.MGetBytesValue(keys...)
+	if err != nil {
+		return nil, err
+	}
+	missed := []string{}
+	for _, k := range keys {
+		if data[k] == nil {
+			missed = append(missed, k)
+		} else if c.Compare {
+			c.compare(k, data[k])
+		}
+	}
+	if len(missed) == 0 {
+		return data, nil
+	}
+	atomic.AddInt64(&c.stats.Fallbacks, int64(len(missed)))
+	old, err := c.Old.MGetBytesValue(missed...)
+	if err != nil {
+		return nil, err
+	}
+	for k := range old {
+		if old[k] != nil {
+			data[k] = old[k]
+		}
+	}
+	return data, nil
+}
+
+//MSetBytesValue set multiple bytes data to both caches with given key-value map.
+//Return  any error if raised.
+func (c *Cache) MSetBytesValue(data map[string][]byte, ttl time.Duration) error {
+	return c.write(c.New.MSetBytesValue(data, ttl), c.Old.MSetBytesValue(data, ttl))
+}
+
+//Del Delete data in both caches by given key.
+//Return any error raised.
+func (c *Cache) Del(key string) error {
+	return c.write(c.New.Del(key), c.Old.Del(key))
+}
+
+//Expire set cache value expire duration in both caches by given key and ttl
+//Return any error raised.
+func (c *Cache) Expire(key string, ttl time.Duration) error {
+	return c.write(c.New.Expire(key, ttl), c.Old.Expire(key, ttl))
+}
+
+//SetCounter Set int val in both caches by given key.Count cache and data cache are in two independent namespace.
+//Return any error raised.
+func (c *Cache) SetCounter(key string, v int64, ttl time.Duration) error {
+	return c.write(c.New.SetCounter(key, v, ttl), c.Old.SetCounter(key, v, ttl))
+}
+
+//GetCounter Get int val from new cache by given key.Count cache and data cache are in two independent namespace.
+//Old cache will be used if counter not found in new cache.
+//Return int data value and any error raised.
+func (c *Cache) GetCounter(key string) (int64, error) {
+	v, err := c.New.GetCounter(key)
+	if !errors.Is(err, cache.ErrNotFound) {
+		return v, err
+	}
+	return c.Old.GetCounter(key)
+}
+
+//IncrCounter Increase int val in both caches by given key.Count cache and data cache are in two independent namespace.
+//Return int data value in new cache and any error raised.
+func (c *Cache) IncrCounter(key string, increment int64, ttl time.Duration) (int64, error) {
+	v, err := c.New.IncrCounter(key, increment, ttl)
+	_, oldErr := c.Old.IncrCounter(key, increment, ttl)
+	return v, c.write(err, oldErr)
+}
+
+//ExpireCounter set cache counter expire duration in both caches by given key and ttl
+//Return any error raised.
+func (c *Cache) ExpireCounter(key string, ttl time.Duration) error {
+	return c.write(c.New.ExpireCounter(key, ttl), c.Old.ExpireCounter(key, ttl))
+}
+
+//DelCounter Delete int val in both caches by given key.Count cache and data cache are in two independent namespace.
+//Return any error raised.
+func (c *Cache) DelCounter(key string) error {
+	return c.write(c.New.DelCounter(key), c.Old.DelCounter(key))
+}
+
+//SetGCErrHandler Set callback to handler error raised when gc.
+func (c *Cache) SetGCErrHandler(f func(err error)) {
+	c.New.SetGCErrHandler(f)
+	c.Old.SetGCErrHandler(f)
+}
+
+//Ping check if both caches are reachable before context done.
+//Return any error raised.
+func (c *Cache) Ping(ctx context.Context) error {
+	err := c.Old.Ping(ctx)
+	if err != nil {
+		return err
+	}
+	return c.New.Ping(ctx)
+}
+
+//Capabilities return capabilities supported by both old and new caches.
+func (c *Cache) Capabilities() cache.Capabilities {
+	return c.Old.Capabilities() & c.New.Capabilities() & (cache.CapabilityMGet | cache.CapabilityCounter | cache.CapabilityFlush | cache.CapabilityPing)
+}
+
+//Close Close both caches.
+//Return any error if raised
+func (c *Cache) Close() error {
+	return c.write(c.New.Close(), c.Old.Close())
+}
+
+//Flush Delete all data in both caches.
+//Return any error if raised
+func (c *Cache) Flush() error {
+	return c.write(c.New.Flush(), c.Old.Flush())
+}
+
+//Config shadow cache driver config.
+type Config struct {
+	//Old config of cache which is migrating from.
+	Old *cache.OptionConfig
+	//New config of cache which is migrating to.
+	New *cache.OptionConfig
+	//Compare whether compare value in new cache with old cache when reading.
+	Compare bool
+	//Backfill whether copy value to new cache when reading falls back to old cache.
+	Backfill bool
+}
+
+//Create create new cache driver.
+//Return driver created and any error if raised.
+func (c *Config) Create() (cache.Driver, error) {
+	var err error
+	cc := &Cache{
+		Compare:  c.Compare,
+		Backfill: c.Backfill,
+	}
+	cc.Old, err = cache.NewSubCache(c.Old)
+	if err != nil {
+		return nil, err
+	}
+	cc.New, err = cache.NewSubCache(c.New)
+	if err != nil {
+		return nil, err
+	}
+	return cc, nil
+}
+
+func init() {
+	cache.Register("shadowcache", func(loader func(interface{}) error) (cache.Driver, error) {
+		c := &Config{}
+		err := loader(c)
+		if err != nil {
+			return nil, err
+		}
+		return c.Create()
+	})
+}
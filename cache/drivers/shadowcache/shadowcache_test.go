@@ -0,0 +1,107 @@
+package shadowcache
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+	_ "github.com/herb-go/deprecated/cache/drivers/syncmapcache"
+)
+
+func newSubCache() *cache.Cache {
+	buf := bytes.NewBufferString(`{"Size":10000000}`)
+	oc := cache.NewOptionConfig()
+	oc.Driver = "syncmapcache"
+	oc.TTL = 3600
+	oc.Marshaler = "json"
+	oc.Config = json.NewDecoder(buf).Decode
+	c, err := cache.NewSubCache(oc)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func newTestCache() (*cache.Cache, *Cache) {
+	d := &Cache{
+		Old: newSubCache(),
+		New: newSubCache(),
+	}
+	d.SetUtil(d.New.Util())
+	c := cache.New()
+	c.Driver = d
+	c.TTL = time.Hour
+	return c, d
+}
+
+func TestShadowCache(t *testing.T) {
+	c, d := newTestCache()
+	err := d.Old.SetBytesValue(cache.Key("old"), []byte("old"), cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.SetBytesValue("test", []byte("value"), cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := d.Old.GetBytesValue(cache.Key("test"))
+	if err != nil || string(bs) != "value" {
+		t.Fatal(string(bs), err)
+	}
+	bs, err = d.New.GetBytesValue(cache.Key("test"))
+	if err != nil || string(bs) != "value" {
+		t.Fatal(string(bs), err)
+	}
+	bs, err = c.GetBytesValue("old")
+	if err != nil || string(bs) != "old" {
+		t.Fatal(string(bs), err)
+	}
+	_, err = d.New.GetBytesValue(cache.Key("old"))
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	d.Backfill = true
+	_, err = c.GetBytesValue("old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err = d.New.GetBytesValue(cache.Key("old"))
+	if err != nil || string(bs) != "old" {
+		t.Fatal(string(bs), err)
+	}
+	d.Compare = true
+	var mismatched string
+	d.OnMismatch = func(key string) {
+		mismatched = key
+	}
+	err = d.Old.SetBytesValue(cache.Key("test"), []byte("changed"), cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err = c.GetBytesValue("test")
+	if err != nil || string(bs) != "value" {
+		t.Fatal(string(bs), err)
+	}
+	if mismatched != cache.Key("test") {
+		t.Fatal(mismatched)
+	}
+	data, err := c.MGetBytesValue("test", "old", "notexists")
+	if err != nil || len(data) != 2 {
+		t.Fatal(data, err)
+	}
+	err = c.Del("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.GetBytesValue("test")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	stats := d.Stats()
+	if stats.Reads != 7 || stats.Fallbacks != 4 || stats.Compared != 3 || stats.Mismatches != 2 {
+		t.Fatal(stats)
+	}
+}
@@ -0,0 +1,62 @@
+package shardedcache
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+)
+
+//hashString hash given string to uint64.
+//Fnv hash is mixed by splitmix64 finalizer so that similar strings like virtual node names are spread on ring.
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+type point struct {
+	hash  uint64
+	shard *shard
+}
+
+//ring consistent hash ring.
+//Every shard owns weight*virtualNodes points computed from shard name,
+//so only keys owned by added or removed shard are moved.
+type ring struct {
+	points []point
+}
+
+func newRing(shards []*shard, virtualNodes int) *ring {
+	r := &ring{}
+	for _, s := range shards {
+		n := s.weight * virtualNodes
+		for i := 0; i < n; i++ {
+			r.points = append(r.points, point{hash: hashString(s.name + "#" + strconv.Itoa(i)), shard: s})
+		}
+	}
+	sort.Slice(r.points, func(i, j int) bool {
+		if r.points[i].hash == r.points[j].hash {
+			return r.points[i].shard.name < r.points[j].shard.name
+		}
+		return r.points[i].hash < r.points[j].hash
+	})
+	return r
+}
+
+//get get shard owns given key,which is the first point clockwise from key hash.
+func (r *ring) get(key string) *shard {
+	h := hashString(key)
+	i := sort.Search(len(r.points), func(i int) bool {
+		return r.points[i].hash >= h
+	})
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.points[i].shard
+}
@@ -0,0 +1,450 @@
+//Package shardedcache provides a cache driver which spreads keys across sub caches by consistent hashing,
+//so very large key spaces can be stored in multiple cache instances.
+package shardedcache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+//DefaultVirtualNodes default count of points on hash ring of every shard with weight 1.
+var DefaultVirtualNodes = 160
+
+var (
+	//ErrNoShards raised when operating sharded cache without shards.
+	ErrNoShards = errors.New("shardedcache: no shards")
+	//ErrShardNameRequired raised when shard name is empty.
+	ErrShardNameRequired = errors.New("shardedcache: shard name required")
+	//ErrShardNameDuplicated raised when adding shard with existing name.
+	ErrShardNameDuplicated = errors.New("shardedcache: shard name duplicated")
+	//ErrShardNotFound raised when removing shard not exists.
+	ErrShardNotFound = errors.New("shardedcache: shard not found")
+)
+
+type shard struct {
+	name   string
+	weight int
+	cache  *cache.Cache
+}
+
+//Cache The sharded cache driver.
+type Cache struct {
+	cache.DriverUtil
+	//virtualNodes count of points on hash ring of every shard with weight 1.
+	//More points make keys spread more evenly,but cost more memory.
+	virtualNodes int
+	locker       sync.RWMutex
+	shards       []*shard
+	ring         *ring
+}
+
+//New create new sharded cache driver with given virtual nodes count.
+//DefaultVirtualNodes will be used if virtual nodes is not positive.
+func New(virtualNodes int) *Cache {
+	if virtualNodes <= 0 {
+		virtualNodes = DefaultVirtualNodes
+	}
+	return &Cache{
+		virtualNodes: virtualNodes,
+		ring:         &ring{},
+	}
+}
+
+//AddShard add sub cache as shard with given name and weight.
+//Name decides positions of shard on hash ring,so it should be stable,for example address of cache server.
+//Weight is proportion of keys stored in shard,1 will be used if weight is not positive.
+//Only keys owned by new shard on hash ring are moved to new shard.
+//Return any error raised.
+func (c *Cache) AddShard(name string, sub *cache.Cache, weight int) error {
+	if name == "" {
+		return ErrShardNameRequired
+	}
+	if weight <= 0 {
+		weight = 1
+	}
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	for _, v := range c.shards {
+		if v.name == name {
+			return ErrShardNameDuplicated
+		}
+	}
+	shards := make([]*shard, len(c.shards), len(c.shards)+1)
+	copy(shards, c.shards)
+	shards = append(shards, &shard{name: name, weight: weight, cache: sub})
+	c.shards = shards
+	c.ring = newRing(shards, c.virtualNodes)
+	return nil
+}
+
+//RemoveShard remove shard by given name.
+//Only keys owned by removed shard are moved to other shards.
+//Removed sub cache is not closed.
+//Return sub cache removed and any error raised.
+func (c *Cache) RemoveShard(name string) (*cache.Cache, error) {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	for k, v := range c.shards {
+		if v.name == name {
+			shards := make([]*shard, 0, len(c.shards)-1)
+			shards = append(shards, c.shards[:k]...)
+			shards = append(shards, c.shards[k+1:]...)
+			c.shards = shards
+			c.ring = newRing(shards, c.virtualNodes)
+			return v.cache, nil
+		}
+	}
+	return nil, ErrShardNotFound
+}
+
+//Shards return names of all shards in added order.
+func (c *Cache) Shards() []string {
+	c.locker.RLock()
+	defer c.locker.RUnlock()
+	names := make([]string, len(c.shards))
+	for k, v := range c.shards {
+		names[k] = v.name
+	}
+	return names
+}
+
+//Locate return name of shard which owns given driver key.
+//Return ErrNoShards if no shard added.
+func (c *Cache) Locate(key string) (string, error) {
+	s, err := c.shard(key)
+	if err != nil {
+		return "", err
+	}
+	return s.name, nil
+}
+
+func (c *Cache) shard(key string) (*shard, error) {
+	c.locker.RLock()
+	defer c.locker.RUnlock()
+	if len(c.ring.points) == 0 {
+		return nil, ErrNoShards
+	}
+	return c.ring.get(key), nil
+}
+
+func (c *Cache) all() []*shard {
+	c.locker.RLock()
+	defer c.locker.RUnlock()
+	return c.shards
+}
+
+func (c *Cache) route(key string) (*cache.Cache, error) {
+	s, err := c.shard(key)
+	if err != nil {
+		return nil, err
+	}
+	return s.cache, nil
+}
+
+//group group given keys by shards which own keys.
+func (c *Cache) group(keys []string) (map[*shard][]string, error) {
+	c.locker.RLock()
+	defer c.locker.RUnlock()
+	if len(c.ring.points) == 0 {
+		return nil, ErrNoShards
+	}
+	result := map[*shard][]string{}
+	for _, k := range keys {
+		s := c.ring.get(k)
+		result[s] = append(result[s], k)
+	}
+	return result, nil
+}
+
+//SetBytesValue Set bytes data to cache by given key.
+//Return any error raised.
+func (c *Cache) SetBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	sub, err := c.route(key)
+	if err != nil {
+		return err
+	}
+	return sub.SetBytesValue(key, bytes, ttl)
+}
+
+//UpdateBytesValue Update bytes data to cache by given key only if the cache exist.
+//Return any error raised.
+func (c *Cache) UpdateBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	sub, err := c.route(key)
+	if err != nil {
+		return err
+	}
+	return sub.UpdateBytesValue(key, bytes, ttl)
+}
+
+//GetBytesValue Get bytes data from cache by given key.
+//Return data bytes and any error raised.
+func (c *Cache) GetBytesValue(key string) ([]byte, error) {
+	sub, err := c.route(key)
+	if err != nil {
+		return nil, err
+	}
+	return sub.GetBytesValue(key)
+}
+
+//MGetBytesValue get multiple bytes data from cache by given keys.
+//Keys are grouped by shards,and every shard is queried once.
+//Return data bytes map and any error if raised.
+func (c *Cache) MGetBytesValue(keys ...string) (map[string][]byte, error) {
+	groups, err := c.group(keys)
+	if err != nil {
+		return nil, err
+	}
+	var result = make(map[string][]byte, len(keys))
+	for s, skeys := range groups {
+		data, err := s.cache.MGetBytesValue(skeys...)
+		if err != nil {
+			return nil, err
+		}
+		for k := range data {
+			result[k] = data[k]
+		}
+	}
+	return result, nil
+}
+
+//MSetBytesValue set multiple bytes data to cache with given key-value map.
+//Data are grouped by shards,and every shard is written once.
+//Return  any error if raised.
+func (c *Cache) MSetBytesValue(data map[string][]byte, ttl time.Duration) error {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	groups, err := c.group(keys)
+	if err != nil {
+		return err
+	}
+	for s, skeys := range groups {
+		sdata := make(map[string][]byte, len(skeys))
+		for _, k := range skeys {
+			sdata[k] = data[k]
+		}
+		err = s.cache.MSetBytesValue(sdata, ttl)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//SetIfNotExists Set bytes data to cache by given key only if the key does not exist.
+//Return whether data is set and any error raised.
+func (c *Cache) SetIfNotExists(key string, bytes []byte, ttl time.Duration) (bool, error) {
+	sub, err := c.route(key)
+	if err != nil {
+		return false, err
+	}
+	return sub.SetIfNotExists(key, bytes, ttl)
+}
+
+//SetIfVersion Set bytes data to cache by given key only if current value version equals given version.
+//Empty version means key should not exist.
+//Return whether data is set and any error raised.
+func (c *Cache) SetIfVersion(key string, bytes []byte, version string, ttl time.Duration) (bool, error) {
+	sub, err := c.route(key)
+	if err != nil {
+		return false, err
+	}
+	return sub.SetIfVersion(key, bytes, version, ttl)
+}
+
+//Del Delete data in cache by given key.
+//Return any error raised.
+func (c *Cache) Del(key string) error {
+	sub, err := c.route(key)
+	if err != nil {
+		return err
+	}
+	return sub.Del(key)
+}
+
+//Expire set cache value expire duration by given key and ttl
+//Return any error raised.
+func (c *Cache) Expire(key string, ttl time.Duration) error {
+	sub, err := c.route(key)
+	if err != nil {
+		return err
+	}
+	return sub.Expire(key, ttl)
+}
+
+//GetTTL get remaining ttl of given key.
+//Return ttl and any error raised.
+func (c *Cache) GetTTL(key string) (time.Duration, error) {
+	sub, err := c.route(key)
+	if err != nil {
+		return 0, err
+	}
+	return sub.GetTTL(key)
+}
+
+//SetCounter Set int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Return any error raised.
+func (c *Cache) SetCounter(key string, v int64, ttl time.Duration) error {
+	sub, err := c.route(key)
+	if err != nil {
+		return err
+	}
+	return sub.SetCounter(key, v, ttl)
+}
+
+//GetCounter Get int val from cache by given key.Count cache and data cache are in two independent namespace.
+//Return int data value and any error raised.
+func (c *Cache) GetCounter(key string) (int64, error) {
+	sub, err := c.route(key)
+	if err != nil {
+		return 0, err
+	}
+	return sub.GetCounter(key)
+}
+
+//IncrCounter Increase int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Return int data value and any error raised.
+func (c *Cache) IncrCounter(key string, increment int64, ttl time.Duration) (int64, error) {
+	sub, err := c.route(key)
+	if err != nil {
+		return 0, err
+	}
+	return sub.IncrCounter(key, increment, ttl)
+}
+
+//ExpireCounter set cache counter expire duration by given key and ttl
+//Return any error raised.
+func (c *Cache) ExpireCounter(key string, ttl time.Duration) error {
+	sub, err := c.route(key)
+	if err != nil {
+		return err
+	}
+	return sub.ExpireCounter(key, ttl)
+}
+
+//DelCounter Delete int val in cache by given key.Count cache and data cache are in two independent namespace.
+//Return any error raised.
+func (c *Cache) DelCounter(key string) error {
+	sub, err := c.route(key)
+	if err != nil {
+		return err
+	}
+	return sub.DelCounter(key)
+}
+
+//SetGCErrHandler Set callback to handler error raised when gc.
+func (c *Cache) SetGCErrHandler(f func(err error)) {
+	for _, v := range c.all() {
+		v.cache.SetGCErrHandler(f)
+	}
+}
+
+//Ping check if all shards are reachable before context done.
+//Return any error raised.
+func (c *Cache) Ping(ctx context.Context) error {
+	for _, v := range c.all() {
+		err := v.cache.Ping(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Capabilities return capabilities supported by all shards.
+func (c *Cache) Capabilities() cache.Capabilities {
+	shards := c.all()
+	if len(shards) == 0 {
+		return 0
+	}
+	all := cache.CapabilityMGet | cache.CapabilityCounter | cache.CapabilityFlush | cache.CapabilityPing | cache.CapabilityTTL | cache.CapabilityNX | cache.CapabilityCAS
+	for _, v := range shards {
+		all = all & v.cache.Capabilities()
+	}
+	return all
+}
+
+//Close Close all shards.
+//Return any error if raised
+func (c *Cache) Close() error {
+	var finalErr error
+	for _, v := range c.all() {
+		err := v.cache.Close()
+		if err != nil {
+			finalErr = err
+		}
+	}
+	return finalErr
+}
+
+//Flush Delete all data in all shards.
+//Return any error if raised
+func (c *Cache) Flush() error {
+	var finalErr error
+	for _, v := range c.all() {
+		err := v.cache.Flush()
+		if err != nil {
+			finalErr = err
+		}
+	}
+	return finalErr
+}
+
+//ShardConfig shard config.
+type ShardConfig struct {
+	//Name shard name which decides positions of shard on hash ring.
+	//Keys are moved if name changed,so name should be stable,for example address of cache server.
+	Name string
+	//Weight proportion of keys stored in shard.Default value is 1.
+	Weight int
+	//Cache sub cache config.
+	Cache *cache.OptionConfig
+}
+
+//Config sharded cache driver config.
+type Config struct {
+	//Shards shard configs.
+	Shards []*ShardConfig
+	//VirtualNodes count of points on hash ring of every shard with weight 1.
+	//DefaultVirtualNodes will be used if not positive.
+	VirtualNodes int
+}
+
+//Create create new cache driver.
+//Return driver created and any error if raised.
+func (c *Config) Create() (cache.Driver, error) {
+	if len(c.Shards) == 0 {
+		return nil, ErrNoShards
+	}
+	cc := New(c.VirtualNodes)
+	for _, v := range c.Shards {
+		sub, err := cache.NewSubCache(v.Cache)
+		if err != nil {
+			cc.Close()
+			return nil, err
+		}
+		err = cc.AddShard(v.Name, sub, v.Weight)
+		if err != nil {
+			sub.Close()
+			cc.Close()
+			return nil, err
+		}
+	}
+	return cc, nil
+}
+
+func init() {
+	cache.Register("shardedcache", func(loader func(interface{}) error) (cache.Driver, error) {
+		c := &Config{}
+		err := loader(c)
+		if err != nil {
+			return nil, err
+		}
+		return c.Create()
+	})
+}
@@ -0,0 +1,258 @@
+package shardedcache
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+	_ "github.com/herb-go/deprecated/cache/drivers/syncmapcache"
+)
+
+func newTestSubCacheConfig() *cache.OptionConfig {
+	oc := cache.NewOptionConfig()
+	oc.Driver = "syncmapcache"
+	oc.TTL = 3600
+	oc.Marshaler = "json"
+	oc.Config = json.NewDecoder(bytes.NewBufferString(`{"Size":10000000}`)).Decode
+	return oc
+}
+
+func newTestSubCache() *cache.Cache {
+	c, err := cache.NewSubCache(newTestSubCacheConfig())
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func newTestCache(shards ...string) *Cache {
+	c := New(0)
+	for _, v := range shards {
+		err := c.AddShard(v, newTestSubCache(), 1)
+		if err != nil {
+			panic(err)
+		}
+	}
+	return c
+}
+
+func locateAll(t *testing.T, c *Cache, n int) map[string]string {
+	result := map[string]string{}
+	for i := 0; i < n; i++ {
+		key := "key" + strconv.Itoa(i)
+		name, err := c.Locate(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result[key] = name
+	}
+	return result
+}
+
+func TestRebalance(t *testing.T) {
+	total := 10000
+	c := newTestCache("redis1", "redis2", "redis3")
+	before := locateAll(t, c, total)
+	counts := map[string]int{}
+	for _, v := range before {
+		counts[v]++
+	}
+	for _, name := range c.Shards() {
+		if counts[name] < total/3*7/10 || counts[name] > total/3*13/10 {
+			t.Fatal(counts)
+		}
+	}
+	err := c.AddShard("redis4", newTestSubCache(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	after := locateAll(t, c, total)
+	moved := 0
+	for k := range before {
+		if before[k] != after[k] {
+			if after[k] != "redis4" {
+				t.Fatal(k, before[k], after[k])
+			}
+			moved++
+		}
+	}
+	if moved < total/4*7/10 || moved > total/4*13/10 {
+		t.Fatal(moved)
+	}
+	_, err = c.RemoveShard("redis4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	removed := locateAll(t, c, total)
+	for k := range before {
+		if before[k] != removed[k] {
+			t.Fatal(k, before[k], removed[k])
+		}
+	}
+	_, err = c.RemoveShard("redis2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	removed = locateAll(t, c, total)
+	for k := range before {
+		if before[k] != "redis2" && before[k] != removed[k] {
+			t.Fatal(k, before[k], removed[k])
+		}
+	}
+	_, err = c.RemoveShard("redis2")
+	if err != ErrShardNotFound {
+		t.Fatal(err)
+	}
+}
+
+func TestWeight(t *testing.T) {
+	total := 10000
+	c := newTestCache("redis1")
+	err := c.AddShard("redis2", newTestSubCache(), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := map[string]int{}
+	for _, v := range locateAll(t, c, total) {
+		counts[v]++
+	}
+	if counts["redis2"] < counts["redis1"]*2 {
+		t.Fatal(counts)
+	}
+}
+
+func TestShardErrors(t *testing.T) {
+	c := New(0)
+	_, err := c.GetBytesValue("key")
+	if err != ErrNoShards {
+		t.Fatal(err)
+	}
+	if c.Capabilities() != 0 {
+		t.Fatal(c.Capabilities())
+	}
+	err = c.AddShard("", newTestSubCache(), 1)
+	if err != ErrShardNameRequired {
+		t.Fatal(err)
+	}
+	err = c.AddShard("redis1", newTestSubCache(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.AddShard("redis1", newTestSubCache(), 1)
+	if err != ErrShardNameDuplicated {
+		t.Fatal(err)
+	}
+}
+
+func TestOperations(t *testing.T) {
+	c := newTestCache("redis1", "redis2", "redis3")
+	data := map[string][]byte{}
+	keys := []string{}
+	for i := 0; i < 100; i++ {
+		key := "key" + strconv.Itoa(i)
+		keys = append(keys, key)
+		data[key] = []byte(key)
+	}
+	err := c.MSetBytesValue(data, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range c.all() {
+		n := 0
+		for _, key := range keys {
+			if _, err := v.cache.GetBytesValue(key); err == nil {
+				n++
+				name, _ := c.Locate(key)
+				if name != v.name {
+					t.Fatal(key, name, v.name)
+				}
+			}
+		}
+		if n == 0 {
+			t.Fatal(v.name)
+		}
+	}
+	result, err := c.MGetBytesValue(append(keys, "notexists")...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != len(keys) {
+		t.Fatal(len(result))
+	}
+	for _, key := range keys {
+		if string(result[key]) != key {
+			t.Fatal(key, string(result[key]))
+		}
+	}
+	bs, err := c.GetBytesValue("key1")
+	if err != nil || string(bs) != "key1" {
+		t.Fatal(string(bs), err)
+	}
+	err = c.Del("key1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.GetBytesValue("key1")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	ok, err := c.SetIfNotExists("key2", []byte("new"), time.Hour)
+	if err != nil || ok {
+		t.Fatal(ok, err)
+	}
+	v, err := c.IncrCounter("counter", 2, time.Hour)
+	if err != nil || v != 2 {
+		t.Fatal(v, err)
+	}
+	ttl, err := c.GetTTL("key2")
+	if err != nil || ttl <= 0 || ttl > time.Hour {
+		t.Fatal(ttl, err)
+	}
+	if !c.Capabilities().Has(cache.CapabilityTTL | cache.CapabilityFlush) {
+		t.Fatal(c.Capabilities())
+	}
+	err = c.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.GetBytesValue("key2")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	err = c.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	config := &Config{}
+	_, err := config.Create()
+	if err != ErrNoShards {
+		t.Fatal(err)
+	}
+	config.Shards = []*ShardConfig{
+		{Name: "redis1", Cache: newTestSubCacheConfig()},
+		{Name: "redis2", Weight: 2, Cache: newTestSubCacheConfig()},
+	}
+	d, err := config.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := d.(*Cache)
+	if len(c.Shards()) != 2 || c.virtualNodes != DefaultVirtualNodes || len(c.ring.points) != 3*DefaultVirtualNodes {
+		t.Fatal(c.Shards(), len(c.ring.points))
+	}
+	config.Shards = []*ShardConfig{
+		{Name: "redis1", Cache: newTestSubCacheConfig()},
+		{Name: "redis1", Cache: newTestSubCacheConfig()},
+	}
+	_, err = config.Create()
+	if err != ErrShardNameDuplicated {
+		t.Fatal(err)
+	}
+}
@@ -3,6 +3,7 @@ package syncmapcache
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"testing"
 	"time"
 
@@ -81,7 +82,7 @@ func TestFlush(t *testing.T) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue("test")
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	if d.used != 0 {
@@ -108,7 +109,7 @@ func TestDel(t *testing.T) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue("test")
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	if d.used != 0 {
@@ -140,7 +141,7 @@ func TestGc(t *testing.T) {
 	}
 	time.Sleep(1 * time.Second)
 	_, err = c.GetBytesValue("test")
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	if d.used != 4 {
@@ -203,12 +204,59 @@ func TestAutoRemove(t *testing.T) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue("test")
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	err = c.SetBytesValue("testtest", []byte("testtest"), 0)
-	if err != cache.ErrEntryTooLarge {
+	if !errors.Is(err, cache.ErrEntryTooLarge) {
 		t.Fatal(err)
 	}
 
 }
+
+func TestOnEvicted(t *testing.T) {
+	c := newAutoRemoveTestCache(300)
+	evicted := map[string]string{}
+	err := c.OnEvicted(func(key string, value []byte) {
+		evicted[key] = string(value)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.SetBytesValue("test", []byte("test"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.Del("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evicted) != 0 {
+		t.Fatal(evicted)
+	}
+	err = c.SetBytesValue("test", []byte("test"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.SetBytesValue("tes", []byte("tes"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evicted) != 1 || evicted["test"] != "test" {
+		t.Fatal(evicted)
+	}
+	d := c.Driver.(*Cache)
+	err = c.SetBytesValue("t", []byte("t"), time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	d.gc()
+	if len(evicted) != 2 || evicted["t"] != "t" {
+		t.Fatal(evicted)
+	}
+	err = cache.New().OnEvicted(nil)
+	if err != cache.ErrFeatureNotSupported {
+		t.Fatal(err)
+	}
+}
@@ -2,6 +2,10 @@
 package syncmapcache
 
 import (
+	"container/heap"
+	"errors"
+	"sort"
+	"strings"
 	"sync/atomic"
 	"time"
 	"unsafe"
@@ -19,6 +23,39 @@ const defaultSize = 50000000
 type entry struct {
 	Expired time.Time
 	Data    []byte
+	item    *expiryItem
+}
+
+//expiryItem item in expiry heap which records when key expires.
+type expiryItem struct {
+	key     string
+	expired time.Time
+	index   int
+}
+
+//expiryHeap min heap of expiry items ordered by expired time.
+type expiryHeap []*expiryItem
+
+func (h expiryHeap) Len() int           { return len(h) }
+func (h expiryHeap) Less(i, j int) bool { return h[i].expired.Before(h[j].expired) }
+func (h expiryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+func (h *expiryHeap) Push(x interface{}) {
+	item := x.(*expiryItem)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+func (h *expiryHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	item.index = -1
+	*h = old[:n-1]
+	return item
 }
 
 //Cache The gocache cache Driver.
@@ -31,6 +68,8 @@ type Cache struct {
 	writelock       sync.Mutex
 	GCInterval      time.Duration
 	gcErrHandler    func(err error)
+	onEvicted       func(key string, value []byte)
+	expiry          expiryHeap
 	C               chan int
 	flushC          chan int
 	forceDeleteKeyC chan interface{}
@@ -44,20 +83,22 @@ func (c *Cache) setDatamap(m *sync.Map) {
 }
 func (c *Cache) flush() {
 	c.writelock.Lock()
-	c.writelock.Unlock()
 	close(c.flushC)
 	c.flushC = make(chan int)
 	c.setDatamap(&sync.Map{})
 	c.used = 0
-	go c.forceDeleteKeyQueue()
+	c.expiry = nil
+	flushC := c.flushC
+	c.writelock.Unlock()
+	go c.forceDeleteKeyQueue(flushC)
 }
-func (c *Cache) forceDeleteKeyQueue() {
+func (c *Cache) forceDeleteKeyQueue(flushC chan int) {
 	var ok = true
 	for ok {
 		c.datamap().Range(func(key interface{}, value interface{}) bool {
 			select {
 			case c.forceDeleteKeyC <- key:
-			case <-c.flushC:
+			case <-flushC:
 				ok = false
 				return false
 			}
@@ -67,20 +108,44 @@ func (c *Cache) forceDeleteKeyQueue() {
 		c.forceDeleteKeyC <- nil
 	}
 }
+
+//gc delete expired entries popped from expiry heap,
+//so that cost is proportional to expired entries instead of all entries.
 func (c *Cache) gc() {
 	c.writelock.Lock()
 	defer c.writelock.Unlock()
 	m := c.datamap()
-	m.Range(func(key interface{}, value interface{}) bool {
-		e := value.(*entry)
-		if e.Expired.Before(time.Now()) {
-			size := int64(len(e.Data))
-			c.used = c.used - size
-			m.Delete(key)
+	now := time.Now()
+	for c.expiry.Len() > 0 && c.expiry[0].expired.Before(now) {
+		item := heap.Pop(&c.expiry).(*expiryItem)
+		v, ok := m.Load(item.key)
+		if ok == false || v == nil {
+			continue
 		}
-		return true
-	})
+		e := v.(*entry)
+		size := int64(len(e.Data))
+		c.used = c.used - size
+		m.Delete(item.key)
+		c.evicted(item.key, e)
+	}
+}
+
+//track track expiry of given new entry in expiry heap.
+//Heap item of old entry is reused if exists.
+func (c *Cache) track(key string, e *entry, old interface{}) {
+	if old != nil {
+		item := old.(*entry).item
+		if item != nil && item.index >= 0 {
+			item.expired = e.Expired
+			e.item = item
+			heap.Fix(&c.expiry, item.index)
+			return
+		}
+	}
+	e.item = &expiryItem{key: key, expired: e.Expired}
+	heap.Push(&c.expiry, e.item)
 }
+
 func (c *Cache) get(key string) ([]byte, bool) {
 	v, ok := c.datamap().Load(key)
 	if ok == false {
@@ -105,6 +170,16 @@ func (c *Cache) rm(key interface{}) {
 	e := v.(*entry)
 	size := int64(len(e.Data))
 	c.used = c.used - size
+	if e.item != nil && e.item.index >= 0 {
+		heap.Remove(&c.expiry, e.item.index)
+	}
+}
+func (c *Cache) evicted(key interface{}, e *entry) {
+	if c.onEvicted == nil {
+		return
+	}
+	k, _ := key.(string)
+	c.onEvicted(k, e.Data)
 }
 func (c *Cache) delete(key string) {
 	c.writelock.Lock()
@@ -115,7 +190,11 @@ func (c *Cache) makeRoom(length int64) {
 	for c.used+length > c.Size {
 		key := <-c.forceDeleteKeyC
 		if key != nil {
+			v, ok := c.datamap().Load(key)
 			c.rm(key)
+			if ok && v != nil {
+				c.evicted(key, v.(*entry))
+			}
 		}
 	}
 }
@@ -130,6 +209,7 @@ func (c *Cache) set(key string, data []byte, ttl time.Duration) {
 		Expired: time.Now().Add(ttl),
 		Data:    data,
 	}
+	c.track(key, e, v)
 	c.datamap().Store(key, e)
 	delta = int64(len(data))
 	if ok == false || v == nil {
@@ -151,11 +231,21 @@ func (c *Cache) replace(key string, data []byte, ttl time.Duration) {
 		Expired: time.Now().Add(ttl),
 		Data:    data,
 	}
+	c.track(key, e, v)
 	c.datamap().Store(key, e)
 	size := int64(len(data)) - int64(len(e.Data))
 	c.used = c.used + size
 }
 
+//OnEvicted register callback called with key and value of every entry evicted due to size limit or expiration.
+//Entries deleted or flushed are not reported.
+//Callback is called synchronously with driver write lock held,so it should not write to cache.
+func (c *Cache) OnEvicted(f func(key string, value []byte)) {
+	c.writelock.Lock()
+	defer c.writelock.Unlock()
+	c.onEvicted = f
+}
+
 //SetGCErrHandler Set callback to handler error raised when gc.
 func (c *Cache) SetGCErrHandler(f func(err error)) {
 	c.gcErrHandler = f
@@ -172,6 +262,95 @@ func (c *Cache) SetBytesValue(key string, bs []byte, ttl time.Duration) error {
 	return nil
 }
 
+//SetIfNotExists Set bytes data to cache by given key only if the key does not exist.
+//Return whether data is set and any error raised.
+func (c *Cache) SetIfNotExists(key string, bs []byte, ttl time.Duration) (bool, error) {
+	if int64(len(bs)) >= c.Size {
+		return false, cache.ErrEntryTooLarge
+	}
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	_, found := c.get(key)
+	if found {
+		return false, nil
+	}
+	c.set(key, bs, ttl)
+	return true, nil
+}
+
+//SetIfVersion Set bytes data to cache by given key only if current value version equals given version.
+//Empty version means key should not exist.
+//Return whether data is set and any error raised.
+func (c *Cache) SetIfVersion(key string, bs []byte, version string, ttl time.Duration) (bool, error) {
+	if int64(len(bs)) >= c.Size {
+		return false, cache.ErrEntryTooLarge
+	}
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	data, found := c.get(key)
+	if found {
+		if version != cache.ValueVersion(data) {
+			return false, nil
+		}
+	} else if version != "" {
+		return false, nil
+	}
+	c.set(key, bs, ttl)
+	return true, nil
+}
+
+//Keys list keys with given prefix from given cursor.
+//Keys are returned in lexical order,and cursor is the last key returned.
+//Return keys,next cursor and any error raised.
+//Empty next cursor means iteration finished.
+func (c *Cache) Keys(prefix string, cursor string, count int) ([]string, string, error) {
+	now := time.Now()
+	keys := []string{}
+	c.datamap().Range(func(key interface{}, value interface{}) bool {
+		k, ok := key.(string)
+		if !ok || !strings.HasPrefix(k, prefix) || k <= cursor {
+			return true
+		}
+		e, ok := value.(*entry)
+		if !ok || !now.Before(e.Expired) {
+			return true
+		}
+		keys = append(keys, k)
+		return true
+	})
+	sort.Strings(keys)
+	if count <= 0 || len(keys) <= count {
+		return keys, "", nil
+	}
+	keys = keys[:count]
+	return keys, keys[count-1], nil
+}
+
+//Rename move value of old key to new key atomically.
+//Remaining ttl of old key is kept.
+//Return cache.ErrNotFound if old key not exists.
+func (c *Cache) Rename(oldKey string, newKey string) error {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	c.writelock.Lock()
+	defer c.writelock.Unlock()
+	v, ok := c.datamap().Load(oldKey)
+	if ok == false || v == nil || !time.Now().Before(v.(*entry).Expired) {
+		return cache.ErrNotFound
+	}
+	old := v.(*entry)
+	c.rm(oldKey)
+	c.rm(newKey)
+	e := &entry{
+		Expired: old.Expired,
+		Data:    old.Data,
+	}
+	c.track(newKey, e, nil)
+	c.datamap().Store(newKey, e)
+	c.used = c.used + int64(len(e.Data))
+	return nil
+}
+
 //UpdateBytesValue Update bytes data to cache by given key only if the cache exist.
 //Return any error raised.
 func (c *Cache) UpdateBytesValue(key string, bs []byte, ttl time.Duration) error {
@@ -192,13 +371,32 @@ func (c *Cache) GetBytesValue(key string) ([]byte, error) {
 	return nil, cache.ErrNotFound
 }
 
+//Capabilities return capabilities supported by cache.
+func (c *Cache) Capabilities() cache.Capabilities {
+	return cache.CapabilityMGet | cache.CapabilityCounter | cache.CapabilityFlush | cache.CapabilityTTL | cache.CapabilityNX | cache.CapabilityCAS | cache.CapabilityIteration | cache.CapabilitySize | cache.CapabilityRename
+}
+
+//Usage return entry count and bytes used by values.
+//Expired entries not collected by gc yet are counted.
+//Return entry count,bytes used and any error raised.
+func (c *Cache) Usage() (int64, int64, error) {
+	c.writelock.Lock()
+	defer c.writelock.Unlock()
+	var entries int64
+	c.datamap().Range(func(key interface{}, value interface{}) bool {
+		entries++
+		return true
+	})
+	return entries, c.used, nil
+}
+
 //MGetBytesValue get multiple bytes data from cache by given keys.
 //Return data bytes map and any error if raised.
 func (c *Cache) MGetBytesValue(keys ...string) (map[string][]byte, error) {
 	var result = make(map[string][]byte, len(keys))
 	for k := range keys {
 		b, err := c.GetBytesValue(keys[k])
-		if err == cache.ErrNotFound {
+		if errors.Is(err, cache.ErrNotFound) {
 		} else if err != nil {
 			return result, err
 		} else {
@@ -313,6 +511,20 @@ func (c *Cache) Expire(key string, ttl time.Duration) error {
 	return nil
 }
 
+//GetTTL get remaining ttl of given key.
+//Return ttl and any error raised.
+func (c *Cache) GetTTL(key string) (time.Duration, error) {
+	v, ok := c.datamap().Load(key)
+	if ok == false || v == nil {
+		return 0, cache.ErrNotFound
+	}
+	ttl := v.(*entry).Expired.Sub(time.Now())
+	if ttl <= 0 {
+		return 0, cache.ErrNotFound
+	}
+	return ttl, nil
+}
+
 //ExpireCounter set cache counter  expire duration by given key and ttl
 func (c *Cache) ExpireCounter(key string, ttl time.Duration) error {
 	locker, _ := c.Util().Locker(key)
@@ -345,7 +557,7 @@ func (config *Config) Create() (cache.Driver, error) {
 	cache.setDatamap(&sync.Map{})
 
 	gctick := time.Tick(time.Duration(config.CleanupIntervalInSecond) * time.Second)
-	go cache.forceDeleteKeyQueue()
+	go cache.forceDeleteKeyQueue(cache.flushC)
 	go func() {
 		for {
 			select {
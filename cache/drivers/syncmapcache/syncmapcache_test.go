@@ -3,6 +3,8 @@ package syncmapcache
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"strconv"
 	"testing"
 	"time"
 
@@ -75,7 +77,7 @@ func TestMSetMGet(t *testing.T) {
 		}
 	}
 	_, err = c.GetBytesValue(unusedKey)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 
@@ -99,7 +101,7 @@ func TestMSetMGet(t *testing.T) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(unusedKey)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	d, err = c.MGetBytesValue(mixedKeys...)
@@ -198,7 +200,7 @@ func TestCloseAndFlush(t *testing.T) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKey, &resultDataModel)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 
@@ -244,7 +246,7 @@ func TestSearchUpdate(t *testing.T) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKeyUpdate, &resultDataModel)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 
@@ -273,7 +275,7 @@ func TestSearchUpdate(t *testing.T) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(testKeyBytesUpdate)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 }
@@ -317,7 +319,7 @@ func TestCounter(t *testing.T) {
 		t.Fatal(err)
 	}
 	resultDataInt, err = c.GetCounter(testKey)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 
@@ -370,15 +372,15 @@ func TestDefaulTTL(t *testing.T) {
 	}
 	time.Sleep(2000 * time.Millisecond)
 	err = c.Get(testKey, &resultDataModel)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(testKey2)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	resultDataInt, err = c.GetCounter(testKey3)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 }
@@ -506,15 +508,15 @@ func TestTTL(t *testing.T) {
 
 	time.Sleep(2000 * time.Millisecond)
 	err = c.Get(testKeyTTL1Second, &resultModelData)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(testKeyTTL1SecondBytes)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetCounter(testKeyTTL1SecondCounter)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKeyTTL3Second, &resultModelData)
@@ -569,27 +571,27 @@ func TestTTL(t *testing.T) {
 
 	time.Sleep(2000 * time.Millisecond)
 	err = c.Get(testKeyTTL1Second, &resultModelData)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(testKeyTTL1SecondBytes)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetCounter(testKeyTTL1SecondCounter)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKeyTTL3Second, &resultModelData)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(testKeyTTL3SecondBytes)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetCounter(testKeyTTL3SecondCounter)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKeyTTLRefresh, &resultModelData)
@@ -619,3 +621,102 @@ func TestTTL(t *testing.T) {
 	}
 
 }
+
+func TestStats(t *testing.T) {
+	c := newTestCache(3600)
+	if !c.Capabilities().Has(cache.CapabilitySize) {
+		t.Fatal(c.Capabilities())
+	}
+	err := c.SetBytesValue("test", []byte("12345"), cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.SetBytesValue("test2", []byte("123"), cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.GetBytesValue("notexist")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	stats, err := c.Stats()
+	if err != nil || stats.Entries != 2 || stats.Bytes != 8 || stats.Hit != 0 || stats.Miss != 1 {
+		t.Fatal(stats, err)
+	}
+	bs, err := c.GetBytesValue("test2")
+	if err != nil || string(bs) != "123" {
+		t.Fatal(string(bs), err)
+	}
+	stats, err = c.Stats()
+	if err != nil || stats.Hit != 1 || stats.Miss != 1 {
+		t.Fatal(stats, err)
+	}
+	err = c.Del("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err = c.Stats()
+	if err != nil || stats.Entries != 1 || stats.Bytes != 3 {
+		t.Fatal(stats, err)
+	}
+}
+
+func TestExpiryHeap(t *testing.T) {
+	c := newGCTestCache(300)
+	d := c.Driver.(*Cache)
+	for i := 0; i < 100; i++ {
+		err := d.SetBytesValue(strconv.Itoa(i), []byte("test"), time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := d.SetBytesValue("expired", []byte("test"), time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = d.SetBytesValue("expired", []byte("test"), time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.expiry.Len() != 101 {
+		t.Fatal(d.expiry.Len())
+	}
+	if d.expiry[0].key != "expired" {
+		t.Fatal(d.expiry[0].key)
+	}
+	err = d.Del("0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.expiry.Len() != 100 {
+		t.Fatal(d.expiry.Len())
+	}
+	time.Sleep(2 * time.Millisecond)
+	d.gc()
+	if d.expiry.Len() != 99 || d.used != 99*4 {
+		t.Fatal(d.expiry.Len(), d.used)
+	}
+	_, err = d.GetBytesValue("expired")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	err = d.Expire("1", time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.expiry[0].key != "1" {
+		t.Fatal(d.expiry[0].key)
+	}
+	time.Sleep(2 * time.Millisecond)
+	d.gc()
+	if d.expiry.Len() != 98 {
+		t.Fatal(d.expiry.Len())
+	}
+	err = d.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.expiry.Len() != 0 {
+		t.Fatal(d.expiry.Len())
+	}
+}
@@ -1,6 +1,7 @@
 package versioncache
 
 import (
+	"context"
 	"errors"
 	"strconv"
 	"time"
@@ -37,6 +38,21 @@ func (c *Cache) Flush() error {
 	return finalErr
 }
 
+//Ping check if both local and remote caches are reachable before context done.
+//Return any error raised.
+func (c *Cache) Ping(ctx context.Context) error {
+	err := c.Remote.Ping(ctx)
+	if err != nil {
+		return err
+	}
+	return c.Local.Ping(ctx)
+}
+
+//Capabilities return capabilities supported by both local and remote caches.
+func (c *Cache) Capabilities() cache.Capabilities {
+	return c.Local.Capabilities() & c.Remote.Capabilities() & (cache.CapabilityMGet | cache.CapabilityCounter | cache.CapabilityFlush | cache.CapabilityPing)
+}
+
 //Close Close cache.
 //Return any error if raised
 func (c *Cache) Close() error {
@@ -300,7 +316,7 @@ func (c *Cache) GetBytesValue(key string) ([]byte, error) {
 	}
 	versionKey := key + cache.KeyPrefix + string(b)
 	b, err = c.Local.GetBytesValue(versionKey)
-	if err == cache.ErrNotFound {
+	if errors.Is(err, cache.ErrNotFound) {
 		b, err = c.Remote.GetBytesValue(versionKey)
 		if err != nil {
 			return b, err
@@ -318,7 +334,7 @@ func (c *Cache) Expire(key string, ttl time.Duration) error {
 		return err
 	}
 	t, b, err := c.getVersion(key)
-	if err == cache.ErrNotFound {
+	if errors.Is(err, cache.ErrNotFound) {
 		return nil
 	}
 	if err != nil {
@@ -93,6 +93,48 @@ func (c *DummyCache) Expire(key string, ttl time.Duration) error {
 	return nil
 }
 
+//GetTTL get remaining ttl of given key.
+//Always return ErrNotFound.
+func (c *DummyCache) GetTTL(key string) (time.Duration, error) {
+	return 0, ErrNotFound
+}
+
+//SetIfNotExists Set bytes data to cache by given key only if the key does not exist.
+//Always return true.
+func (c *DummyCache) SetIfNotExists(key string, bytes []byte, ttl time.Duration) (bool, error) {
+	return true, nil
+}
+
+//GetWithVersion Get bytes data and value version from cache by given key.
+//Always return ErrNotFound.
+func (c *DummyCache) GetWithVersion(key string) ([]byte, string, error) {
+	return nil, "", ErrNotFound
+}
+
+//SetIfVersion Set bytes data to cache by given key only if current value version equals given version.
+//Always return true.
+func (c *DummyCache) SetIfVersion(key string, bytes []byte, version string, ttl time.Duration) (bool, error) {
+	return true, nil
+}
+
+//Keys list keys with given prefix from given cursor.
+//Always return empty result.
+func (c *DummyCache) Keys(prefix string, cursor string, count int) ([]string, string, error) {
+	return []string{}, "", nil
+}
+
+//Rename move value of old key to new key.
+//Always return ErrNotFound.
+func (c *DummyCache) Rename(oldKey string, newKey string) error {
+	return ErrNotFound
+}
+
+//Explain explain how Get of given key would be served.
+//Always return explanation of value not found.
+func (c *DummyCache) Explain(key string) (*Explanation, error) {
+	return &Explanation{Key: key, TTL: -1, Layer: -1}, nil
+}
+
 //ExpireCounter set cache counter  expire duration by given key and ttl
 func (c *DummyCache) ExpireCounter(key string, ttl time.Duration) error {
 	return nil
@@ -122,5 +164,9 @@ func init() {
 	Register("dummycache", func(loader func(interface{}) error) (Driver, error) {
 		return &DummyCache{}, nil
 	})
+	//nullcache alias of dummycache,so caching can be disabled by configuration.
+	Register("nullcache", func(loader func(interface{}) error) (Driver, error) {
+		return &DummyCache{}, nil
+	})
 
 }
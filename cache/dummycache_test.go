@@ -1,6 +1,7 @@
 package cache_test
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -34,7 +35,7 @@ func TestDummyCache(t *testing.T) {
 		t.Errorf("Update error %s", err)
 	}
 	err = c.Get(testKey, &model)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Errorf("Get error %s", err)
 	}
 	err = c.SetBytesValue(testKey, testBytes, testTTL)
@@ -46,7 +47,7 @@ func TestDummyCache(t *testing.T) {
 		t.Errorf("UpdateBytesValue error %s", err)
 	}
 	_, err = c.GetBytesValue(testKey)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Errorf("GetBytesValue error %s", err)
 	}
 	r, err := c.MGetBytesValue("test", "test2")
@@ -73,7 +74,7 @@ func TestDummyCache(t *testing.T) {
 		t.Errorf("SetCounter error %s", err)
 	}
 	_, err = c.GetCounter(testKey)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Errorf("GetCounter error %s", err)
 	}
 	err = c.DelCounter(testKey)
@@ -99,3 +100,38 @@ func TestDummyCache(t *testing.T) {
 	}
 
 }
+
+func TestNullCache(t *testing.T) {
+	c := cache.New()
+	oc := cache.NewOptionConfig()
+	oc.Driver = "nullcache"
+	oc.TTL = 3600
+	oc.Marshaler = "json"
+	err := c.Init(oc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.Set("key", "value", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result string
+	err = c.Get("key", &result)
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	called := 0
+	loader := func(key string) (interface{}, error) {
+		called++
+		return "loaded", nil
+	}
+	for i := 0; i < 2; i++ {
+		err = c.Load("key", &result, 0, loader)
+		if err != nil || result != "loaded" {
+			t.Fatal(result, err)
+		}
+	}
+	if called != 2 {
+		t.Fatal(called)
+	}
+}
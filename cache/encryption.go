@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+)
+
+//ErrUnknownEncryptionKey error raised when key id of encrypted value is not in key ring.
+var ErrUnknownEncryptionKey = errors.New("cache: unknown encryption key")
+
+//ErrInvalidEncryptedData error raised when encrypted value is malformed or fails authentication.
+var ErrInvalidEncryptedData = errors.New("cache: invalid encrypted data")
+
+//Encryptor value encryptor used by cache.
+//Values are encrypted by AES-GCM with current key,and tagged with key id,
+//so that values encrypted by old keys in key ring can still be decrypted after key rotation.
+type Encryptor struct {
+	current string
+	keys    map[string]cipher.AEAD
+}
+
+//NewEncryptor create new encryptor which encrypts values with given key id and key.
+//Key must be 16,24 or 32 bytes to select AES-128,AES-192 or AES-256.
+//Return encryptor and any error if raised.
+func NewEncryptor(id string, key []byte) (*Encryptor, error) {
+	e := &Encryptor{
+		keys: map[string]cipher.AEAD{},
+	}
+	err := e.AddKey(id, key)
+	if err != nil {
+		return nil, err
+	}
+	e.current = id
+	return e, nil
+}
+
+//AddKey add key with given id to key ring.
+//Keys in key ring are only used to decrypt values.
+//Return any error if raised.
+func (e *Encryptor) AddKey(id string, key []byte) error {
+	if len(id) > 255 {
+		return errors.New("cache: encryption key id too long")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+	e.keys[id] = aead
+	return nil
+}
+
+//KeyID return id of key used to encrypt values.
+func (e *Encryptor) KeyID() string {
+	return e.current
+}
+
+//Encrypt encrypt value to bytes stored in driver.
+//Encrypted bytes are key id length byte,key id,nonce and sealed value.
+//Return encrypted bytes and any error if raised.
+func (e *Encryptor) Encrypt(data []byte) ([]byte, error) {
+	aead := e.keys[e.current]
+	idlen := len(e.current)
+	noncesize := aead.NonceSize()
+	result := make([]byte, 1+idlen+noncesize, 1+idlen+noncesize+len(data)+aead.Overhead())
+	result[0] = byte(idlen)
+	copy(result[1:], e.current)
+	nonce := result[1+idlen:]
+	_, err := io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(result, nonce, data, result[1:1+idlen]), nil
+}
+
+//Decrypt decrypt bytes stored in driver to value.
+//Value encrypted by any key in key ring can be decrypted.
+//Return decrypted value and any error if raised.
+func (e *Encryptor) Decrypt(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return data, nil
+	}
+	idlen := int(data[0])
+	if len(data) < 1+idlen {
+		return nil, ErrInvalidEncryptedData
+	}
+	id := data[1 : 1+idlen]
+	aead, ok := e.keys[string(id)]
+	if !ok {
+		return nil, ErrUnknownEncryptionKey
+	}
+	noncesize := aead.NonceSize()
+	if len(data) < 1+idlen+noncesize+aead.Overhead() {
+		return nil, ErrInvalidEncryptedData
+	}
+	nonce := data[1+idlen : 1+idlen+noncesize]
+	result, err := aead.Open(nil, nonce, data[1+idlen+noncesize:], id)
+	if err != nil {
+		return nil, ErrInvalidEncryptedData
+	}
+	return result, nil
+}
+
+//EncryptionConfig encryption config.
+type EncryptionConfig struct {
+	//KeyID id of key used to encrypt values.
+	KeyID string
+	//Key base64 encoded key used to encrypt values.
+	//Key must be 16,24 or 32 bytes after decoding.
+	Key string
+	//KeyRing base64 encoded old keys by key id,used to decrypt values after key rotation.
+	KeyRing map[string]string
+}
+
+//CreateEncryptor create encryptor with config.
+//Return encryptor and any error if raised.
+func (c *EncryptionConfig) CreateEncryptor() (*Encryptor, error) {
+	key, err := base64.StdEncoding.DecodeString(c.Key)
+	if err != nil {
+		return nil, err
+	}
+	e, err := NewEncryptor(c.KeyID, key)
+	if err != nil {
+		return nil, err
+	}
+	for id, v := range c.KeyRing {
+		if id == c.KeyID {
+			continue
+		}
+		key, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return nil, err
+		}
+		err = e.AddKey(id, key)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
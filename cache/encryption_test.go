@@ -0,0 +1,102 @@
+package cache_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+func newEncryptedTestCache(encryption *cache.EncryptionConfig) *cache.Cache {
+	c := cache.New()
+	oc := cache.NewOptionConfig()
+	oc.Driver = "syncmapcache"
+	oc.TTL = 3600
+	oc.Config = json.NewDecoder(bytes.NewBufferString(`{"Size":10000000}`)).Decode
+	oc.Marshaler = "json"
+	oc.Compression = "gzip"
+	oc.CompressionMinSize = 64
+	oc.Encryption = encryption
+	err := c.Init(oc)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func TestEncryption(t *testing.T) {
+	key1 := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("1", 32)))
+	key2 := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("2", 16)))
+	c := newEncryptedTestCache(&cache.EncryptionConfig{KeyID: "v1", Key: key1})
+	large := strings.Repeat("secretdata", 100)
+	err := c.Set("large", large, cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.SetBytesValue("small", []byte("secret"), cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := c.Driver.GetBytesValue(c.FinalKey("small"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw[0] != 2 || string(raw[1:3]) != "v1" || bytes.Contains(raw, []byte("secret")) {
+		t.Fatal(raw)
+	}
+	raw, err = c.Driver.GetBytesValue(c.FinalKey("large"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(raw) >= len(large) {
+		t.Fatal(len(raw))
+	}
+	var v string
+	err = c.Get("large", &v)
+	if err != nil || v != large {
+		t.Fatal(v, err)
+	}
+	rotated := newEncryptedTestCache(&cache.EncryptionConfig{KeyID: "v2", Key: key2, KeyRing: map[string]string{"v1": key1}})
+	rotated.Driver = c.Driver
+	bs, err := rotated.GetBytesValue("small")
+	if err != nil || string(bs) != "secret" {
+		t.Fatal(string(bs), err)
+	}
+	err = rotated.SetBytesValue("small", []byte("secret2"), cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.GetBytesValue("small")
+	if !errors.Is(err, cache.ErrUnknownEncryptionKey) {
+		t.Fatal(err)
+	}
+	raw, err = c.Driver.GetBytesValue(c.FinalKey("large"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw[len(raw)-1] ^= 1
+	err = c.Driver.SetBytesValue(c.FinalKey("large"), raw, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.GetBytesValue("large")
+	if !errors.Is(err, cache.ErrInvalidEncryptedData) {
+		t.Fatal(err)
+	}
+}
+
+func TestEncryptionConfig(t *testing.T) {
+	c := cache.New()
+	oc := cache.NewOptionConfig()
+	oc.Driver = "dummycache"
+	oc.Encryption = &cache.EncryptionConfig{KeyID: "v1", Key: base64.StdEncoding.EncodeToString([]byte("shortkey"))}
+	err := c.Init(oc)
+	if err == nil {
+		t.Fatal(err)
+	}
+}
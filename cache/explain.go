@@ -0,0 +1,121 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+//Explanation explanation of how Get of given key would be served.
+type Explanation struct {
+	//Key key given.
+	Key string
+	//FinalKey key passed to driver,including prefixes of cache,node or collection.
+	FinalKey string
+	//Hashed whether final key is hashed because it is longer than KeyHashThreshold.
+	Hashed bool
+	//Marshaler name of marshaler used to unmarshal value.
+	Marshaler string
+	//Found whether Get would be served with value.
+	Found bool
+	//NegativeCached whether "not found" result of loader is cached,
+	//which makes Load return ErrNegativeCached without calling loader.
+	NegativeCached bool
+	//TTL remaining ttl of value.
+	//Zero means value never expires.
+	//Negative means value not found or driver does not support ttl inspection.
+	TTL time.Duration
+	//Size size in bytes of value stored in driver,after compressed and encrypted.
+	Size int
+	//Layer index of sub cache which would serve Get,for layered drivers like cachegroup.
+	//-1 if driver is not layered or no sub cache would serve.
+	Layer int
+	//Layers explanations of every sub cache in lookup order,for layered drivers like cachegroup.
+	Layers []*Explanation
+}
+
+//LayerExplainer optional interface for drivers composed of sub caches.
+type LayerExplainer interface {
+	//ExplainLayers explain how given driver key would be served by sub caches.
+	//Found,TTL,Size,Layer and Layers fields of explanation returned are used.
+	//Return explanation and any error raised.
+	ExplainLayers(key string) (*Explanation, error)
+}
+
+func marshalerName(m Marshaler) string {
+	if m == nil {
+		return ""
+	}
+	return fmt.Sprintf("%T", m)
+}
+
+//Explain explain how Get of given key would be served without changing cache,
+//so that cache misses can be debugged without modifying application code.
+//Hit and miss counters are not changed and hooks are not called.
+//Return explanation and any error raised.
+func (c *Cache) Explain(key string) (*Explanation, error) {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
+	if key == "" {
+		return nil, ErrKeyUnavailable
+	}
+	u := c.Driver.Util()
+	e, err := c.explain(key)
+	if err != nil {
+		return nil, WrapKeyError(LayerDriver, key, err)
+	}
+	e.Key = key
+	e.FinalKey = c.getKey(key)
+	e.Hashed = e.FinalKey != Key(key)
+	if u != nil {
+		e.Marshaler = u.MarshalerName
+		if e.Marshaler == "" {
+			e.Marshaler = marshalerName(u.Marshaler)
+		}
+		if !e.Found && u.NegativeTTL > 0 {
+			_, err = c.getBytesValue(c.getKey(key + negativeKeySuffix))
+			e.NegativeCached = err == nil
+		}
+	}
+	return e, nil
+}
+
+func (c *Cache) explain(key string) (*Explanation, error) {
+	k := c.getKey(key)
+	if d, ok := c.Driver.(LayerExplainer); ok {
+		e, err := d.ExplainLayers(k)
+		if err != nil {
+			return nil, err
+		}
+		if !e.Found {
+			e.TTL = -1
+		}
+		return e, nil
+	}
+	e := &Explanation{
+		TTL:   -1,
+		Layer: -1,
+	}
+	data, err := c.getBytesValue(k)
+	if errors.Is(err, ErrNotFound) {
+		return e, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	e.Found = true
+	e.Size = len(data)
+	d, ok := c.Driver.(TTLGetter)
+	if ok && DriverCapabilities(c.Driver).Has(CapabilityTTL) {
+		ttl, err := d.GetTTL(k)
+		if errors.Is(err, ErrNotFound) {
+			e.Found = false
+			e.Size = 0
+			return e, nil
+		}
+		if err == nil {
+			e.TTL = ttl
+		}
+	}
+	return e, nil
+}
@@ -0,0 +1,83 @@
+package cache_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+func TestExplain(t *testing.T) {
+	c := newTestCache(3600)
+	_, err := c.Explain("")
+	if !errors.Is(err, cache.ErrKeyUnavailable) {
+		t.Fatal(err)
+	}
+	hits, misses := c.Hit(), c.Miss()
+	e, err := c.Explain("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Found || e.TTL >= 0 || e.Size != 0 || e.Layer != -1 || e.Layers != nil {
+		t.Fatal(e)
+	}
+	if e.Key != "test" || e.FinalKey != cache.Key("test") || e.Hashed || e.Marshaler != "json" {
+		t.Fatal(e)
+	}
+	err = c.Set("test", "value", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err = c.Explain("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !e.Found || e.Size != len(`"value"`) || e.TTL <= 59*time.Second || e.TTL > time.Minute {
+		t.Fatal(e)
+	}
+	if c.Hit() != hits || c.Miss() != misses {
+		t.Fatal(c.Hit(), c.Miss())
+	}
+	c.KeyHashThreshold = 10
+	longkey := strings.Repeat("a", 20)
+	e, err = c.Explain(longkey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !e.Hashed || e.FinalKey == cache.Key(longkey) {
+		t.Fatal(e)
+	}
+	c.KeyHashThreshold = 0
+	c.Util().NegativeTTL = time.Hour
+	err = c.Load("notfound", nil, 0, func(key string) (interface{}, error) {
+		return nil, cache.ErrNotFound
+	})
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	e, err = c.Explain("notfound")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Found || !e.NegativeCached {
+		t.Fatal(e)
+	}
+	n := cache.NewNode(c, "node")
+	err = n.Set("test", "value", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err = n.Explain("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !e.Found || e.Key != "test" || e.FinalKey == cache.Key("test") {
+		t.Fatal(e)
+	}
+	e, err = cache.Explain(cache.Dummy(), "test")
+	if err != nil || e.Found {
+		t.Fatal(e, err)
+	}
+}
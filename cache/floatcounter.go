@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+//ErrCounterConflict error raised when counter is modified concurrently too many times.
+var ErrCounterConflict = errors.New("cache: counter update conflict")
+
+//FloatCounterRetry max retry times when updating float counter by SetIfVersion.
+var FloatCounterRetry = 10
+
+func encodeFloatCounter(v float64) []byte {
+	return []byte(strconv.FormatFloat(v, 'f', -1, 64))
+}
+
+func decodeFloatCounter(data []byte) (float64, error) {
+	v, err := strconv.ParseFloat(string(data), 64)
+	if err != nil {
+		return 0, ErrInvalidCounterValue
+	}
+	return v, nil
+}
+
+func getFloatCounter(d Driver, key string) (float64, error) {
+	bs, err := d.GetBytesValue(key)
+	if err != nil {
+		return 0, err
+	}
+	return decodeFloatCounter(bs)
+}
+
+func incrFloatCounter(d Driver, key string, increment float64, ttl time.Duration) (float64, error) {
+	cas, ok := d.(CASSetter)
+	if !ok || !DriverCapabilities(d).Has(CapabilityCAS) {
+		locker, _ := d.Util().Locker(key)
+		locker.Lock()
+		defer locker.Unlock()
+		v, err := getFloatCounter(d, key)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return 0, err
+		}
+		v = v + increment
+		return v, d.SetBytesValue(key, encodeFloatCounter(v), ttl)
+	}
+	for i := 0; i < FloatCounterRetry; i++ {
+		var v float64
+		var version string
+		bs, err := d.GetBytesValue(key)
+		if err == nil {
+			version = ValueVersion(bs)
+			v, err = decodeFloatCounter(bs)
+			if err != nil {
+				return 0, err
+			}
+		} else if !errors.Is(err, ErrNotFound) {
+			return 0, err
+		}
+		v = v + increment
+		ok, err := cas.SetIfVersion(key, encodeFloatCounter(v), version, ttl)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			return v, nil
+		}
+	}
+	return 0, ErrCounterConflict
+}
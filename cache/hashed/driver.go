@@ -2,6 +2,7 @@ package hashed
 
 import (
 	"encoding/binary"
+	"errors"
 	"time"
 
 	"github.com/herb-go/deprecated/cache"
@@ -231,7 +232,7 @@ func (d *Driver) MGetBytesValue(keys ...string) (map[string][]byte, error) {
 	for k := range keys {
 		bs, err := d.GetBytesValue(keys[k])
 		if err != nil {
-			if err == cache.ErrNotFound {
+			if errors.Is(err, cache.ErrNotFound) {
 				continue
 			}
 			return nil, err
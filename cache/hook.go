@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+//DefaultHooksQueueSize default size of hook event queue.
+var DefaultHooksQueueSize = 1024
+
+//Event cache operation event passed to hooks.
+type Event struct {
+	//Key key passed to cache.
+	Key string
+	//Op operation name.
+	Op string
+	//Size value size in bytes.
+	Size int
+	//Err error returned by operation.
+	Err error
+}
+
+//Hook function called after cache operation.
+type Hook func(e *Event)
+
+//Hooks cache hook dispatcher.
+//Events are dispatched in a background goroutine.
+//Events will be dropped if queue is full,so hooks never block cache operations.
+type Hooks struct {
+	locker  sync.RWMutex
+	hooks   map[string][]Hook
+	count   int32
+	queue   chan *Event
+	stopped chan struct{}
+	start   sync.Once
+	stop    sync.Once
+	dropped int64
+}
+
+//NewHooks create new hooks with given queue size.
+func NewHooks(size int) *Hooks {
+	return &Hooks{
+		hooks:   map[string][]Hook{},
+		queue:   make(chan *Event, size),
+		stopped: make(chan struct{}),
+	}
+}
+
+//Subscribe register hook which will be called after given operation.
+func (h *Hooks) Subscribe(op string, hook Hook) {
+	h.locker.Lock()
+	h.hooks[op] = append(h.hooks[op], hook)
+	h.locker.Unlock()
+	atomic.AddInt32(&h.count, 1)
+	h.start.Do(func() {
+		go h.run()
+	})
+}
+
+//Dropped return count of events dropped because queue is full.
+func (h *Hooks) Dropped() int64 {
+	return atomic.LoadInt64(&h.dropped)
+}
+
+//Stop stop dispatching events.
+func (h *Hooks) Stop() {
+	h.stop.Do(func() {
+		close(h.stopped)
+	})
+}
+
+func (h *Hooks) emit(op string, key string, size int, err error) {
+	if atomic.LoadInt32(&h.count) == 0 {
+		return
+	}
+	e := &Event{
+		Key:  key,
+		Op:   op,
+		Size: size,
+		Err:  err,
+	}
+	select {
+	case h.queue <- e:
+	default:
+		atomic.AddInt64(&h.dropped, 1)
+	}
+}
+
+func (h *Hooks) dispatch(e *Event) {
+	h.locker.RLock()
+	hooks := h.hooks[e.Op]
+	h.locker.RUnlock()
+	for _, hook := range hooks {
+		hook(e)
+	}
+}
+
+func (h *Hooks) run() {
+	for {
+		select {
+		case e := <-h.queue:
+			h.dispatch(e)
+		case <-h.stopped:
+			return
+		}
+	}
+}
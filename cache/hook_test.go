@@ -0,0 +1,92 @@
+package cache_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+func TestHooks(t *testing.T) {
+	c := newTestCache(3600)
+	events := make(chan *cache.Event, 10)
+	hook := func(e *cache.Event) {
+		events <- e
+	}
+	c.OnSet(hook)
+	c.OnGet(hook)
+	c.OnDel(hook)
+	c.OnExpire(hook)
+	next := func() *cache.Event {
+		select {
+		case e := <-events:
+			return e
+		case <-time.After(time.Second):
+			t.Fatal("hook not called")
+		}
+		return nil
+	}
+	err := c.SetBytesValue("test", []byte("value"), cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := next()
+	if e.Op != cache.OpSet || e.Key != "test" || e.Size != 5 || e.Err != nil {
+		t.Fatal(e)
+	}
+	_, err = c.GetBytesValue("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e = next()
+	if e.Op != cache.OpGet || e.Key != "test" || e.Size != 5 || e.Err != nil {
+		t.Fatal(e)
+	}
+	err = c.Expire("test", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e = next()
+	if e.Op != cache.OpExpire || e.Key != "test" {
+		t.Fatal(e)
+	}
+	err = c.Del("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e = next()
+	if e.Op != cache.OpDel || e.Key != "test" {
+		t.Fatal(e)
+	}
+	_, err = c.GetBytesValue("test")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	e = next()
+	if e.Op != cache.OpGet || !errors.Is(e.Err, cache.ErrNotFound) {
+		t.Fatal(e)
+	}
+	err = c.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHooksDropped(t *testing.T) {
+	c := newTestCache(3600)
+	block := make(chan bool)
+	defer close(block)
+	c.OnSet(func(e *cache.Event) {
+		<-block
+	})
+	for i := 0; i < cache.DefaultHooksQueueSize+2; i++ {
+		err := c.SetBytesValue("test", []byte("value"), cache.DefaultTTL)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if c.Hooks().Dropped() == 0 {
+		t.Fatal(c.Hooks().Dropped())
+	}
+}
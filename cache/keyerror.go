@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"errors"
+	"strconv"
+)
+
+//Error layers of KeyError.
+const (
+	//LayerDriver error raised by cache driver.
+	LayerDriver = "driver"
+	//LayerMarshaler error raised by marshaler when marshaling or unmarshaling value.
+	LayerMarshaler = "marshaler"
+	//LayerCodec error raised when compressing,encrypting or checking size of value.
+	LayerCodec = "codec"
+	//LayerNode error raised by node when resolving node key.
+	LayerNode = "node"
+)
+
+//KeyError error raised by cache operation with failing key and layer.
+//KeyError matches wrapped error with errors.Is,for example errors.Is(err, ErrNotFound).
+type KeyError struct {
+	//Layer layer where error raised,like LayerDriver.
+	Layer string
+	//Key failing key passed to cache.
+	Key string
+	//Err wrapped error.
+	Err error
+}
+
+//Error return error message.
+func (e *KeyError) Error() string {
+	return "cache " + e.Layer + " error on key " + strconv.Quote(e.Key) + ": " + e.Err.Error()
+}
+
+//Unwrap return wrapped error.
+func (e *KeyError) Unwrap() error {
+	return e.Err
+}
+
+//WrapKeyError wrap error with given layer and key.
+//Return nil if err is nil,or err itself if err already carries key context,
+//so that innermost layer is reported.
+func WrapKeyError(layer string, key string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var e *KeyError
+	if errors.As(err, &e) {
+		return err
+	}
+	return &KeyError{
+		Layer: layer,
+		Key:   key,
+		Err:   err,
+	}
+}
+
+//ErrorKey return failing key and layer of given error.
+//Return empty strings if error carries no key context.
+func ErrorKey(err error) (key string, layer string) {
+	var e *KeyError
+	if errors.As(err, &e) {
+		return e.Key, e.Layer
+	}
+	return "", ""
+}
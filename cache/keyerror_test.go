@@ -0,0 +1,53 @@
+package cache_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/herb-go/deprecated/cache"
+	"github.com/herb-go/deprecated/cache/drivers/syncmapcache"
+)
+
+func TestKeyError(t *testing.T) {
+	c := newTestCache(3600)
+	_, err := c.GetBytesValue("notexist")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	key, layer := cache.ErrorKey(err)
+	if key != "notexist" || layer != cache.LayerDriver {
+		t.Fatal(key, layer)
+	}
+	if err.Error() != `cache driver error on key "notexist": Entry not found` {
+		t.Fatal(err.Error())
+	}
+	err = c.Set("func", func() {}, 0)
+	key, layer = cache.ErrorKey(err)
+	if err == nil || key != "func" || layer != cache.LayerMarshaler {
+		t.Fatal(err, key, layer)
+	}
+	c.MaxEntrySize = 1
+	err = c.SetBytesValue("large", []byte("large"), 0)
+	key, layer = cache.ErrorKey(err)
+	if !errors.Is(err, cache.ErrEntryTooLarge) || key != "large" || layer != cache.LayerCodec {
+		t.Fatal(err, key, layer)
+	}
+	wrapped := cache.WrapKeyError(cache.LayerNode, "outer", err)
+	if wrapped != err {
+		t.Fatal(wrapped)
+	}
+	if cache.WrapKeyError(cache.LayerDriver, "key", nil) != nil {
+		t.Fatal()
+	}
+	if key, layer = cache.ErrorKey(cache.ErrNotFound); key != "" || layer != "" {
+		t.Fatal(key, layer)
+	}
+	d := &flakyDriver{Cache: c.Driver.(*syncmapcache.Cache), failures: 1}
+	c.Driver = d
+	n := cache.NewNode(c, "node")
+	_, err = n.GetBytesValue("test")
+	key, layer = cache.ErrorKey(err)
+	if !errors.Is(err, errFlaky) || key != "test" || layer != cache.LayerNode {
+		t.Fatal(err, key, layer)
+	}
+}
@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+//ContextLoader cache value loader used in load with context.
+//Load value with given context and key.
+//Return loaded value and any error if raised.
+type ContextLoader func(ctx context.Context, key string) (interface{}, error)
+
+//ContextMLoader cache value loader used in mload with context.
+//Load values with given context and missing keys.
+//Return loaded values map and any error if raised.
+//Keys not found should not be included in map.
+type ContextMLoader func(ctx context.Context, missing []string) (map[string]interface{}, error)
+
+//LoaderContext derive context passed to loader from given context.
+//If ctx has deadline,derived context deadline is earlier by given margin,
+//so that there is time left to write loaded value to cache.
+//Context is not changed if it has no deadline or margin is not positive.
+//Return derived context and cancel function.
+func LoaderContext(ctx context.Context, margin time.Duration) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok || margin <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, deadline.Add(-margin))
+}
+
+//LoadContext Get data model from given cache by key.If data not found,call loader with derived context to get current data value and save to cache.
+//Loader context deadline is earlier than ctx deadline by util LoadDeadlineMargin.
+//Return ctx error without loading if ctx is done.
+//Return any error raised.
+func LoadContext(ctx context.Context, c Cacheable, key string, v interface{}, ttl time.Duration, loader ContextLoader) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+	margin := c.Util().LoadDeadlineMargin
+	return c.Load(key, v, ttl, func(key string) (interface{}, error) {
+		lctx, cancel := LoaderContext(ctx, margin)
+		defer cancel()
+		return loader(lctx, key)
+	})
+}
+
+//MLoadContext Get data models from given cache by keys.Data not found will be loaded by one loader call with derived context and saved to cache.
+//Loader context deadline is earlier than ctx deadline by util LoadDeadlineMargin.
+//Return ctx error without loading if ctx is done.
+//Return any error raised.
+func MLoadContext(ctx context.Context, c Cacheable, keys []string, v map[string]interface{}, ttl time.Duration, loader ContextMLoader) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+	margin := c.Util().LoadDeadlineMargin
+	return MLoad(c, keys, v, ttl, func(missing []string) (map[string]interface{}, error) {
+		lctx, cancel := LoaderContext(ctx, margin)
+		defer cancel()
+		return loader(lctx, missing)
+	})
+}
+
+//LoadContext Get data model from cache by given key.If data not found,call loader with derived context to get current data value and save to cache.
+//Loader context deadline is earlier than ctx deadline by util LoadDeadlineMargin.
+//Return any error raised.
+func (c *Cache) LoadContext(ctx context.Context, key string, v interface{}, ttl time.Duration, loader ContextLoader) error {
+	return LoadContext(ctx, c, key, v, ttl, loader)
+}
+
+//MLoadContext Get data models from cache by given keys.Data not found will be loaded by one loader call with derived context and saved to cache.
+//Loader context deadline is earlier than ctx deadline by util LoadDeadlineMargin.
+//Return any error raised.
+func (c *Cache) MLoadContext(ctx context.Context, keys []string, v map[string]interface{}, ttl time.Duration, loader ContextMLoader) error {
+	return MLoadContext(ctx, c, keys, v, ttl, loader)
+}
@@ -0,0 +1,62 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+func TestLoadContext(t *testing.T) {
+	var result string
+	var remaining time.Duration
+	loader := func(ctx context.Context, key string) (interface{}, error) {
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			return nil, cache.ErrNotFound
+		}
+		remaining = time.Until(deadline)
+		return key, nil
+	}
+	c := newTestCache(3600)
+	c.Util().LoadDeadlineMargin = 500 * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err := c.LoadContext(ctx, "test", &result, 0, loader)
+	if err != nil || result != "test" {
+		t.Fatal(result, err)
+	}
+	if remaining <= 0 || remaining > 500*time.Millisecond {
+		t.Fatal(remaining)
+	}
+	result = ""
+	err = cache.LoadContext(ctx, c, "test2", &result, 0, loader)
+	if err != nil || result != "test2" {
+		t.Fatal(result, err)
+	}
+	mresult := map[string]interface{}{"m1": new(string), "m2": new(string)}
+	err = c.MLoadContext(ctx, []string{"m1", "m2"}, mresult, 0, func(ctx context.Context, missing []string) (map[string]interface{}, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Fatal(ctx)
+		}
+		data := map[string]interface{}{}
+		for _, v := range missing {
+			data[v] = v
+		}
+		return data, nil
+	})
+	if err != nil || *(mresult["m1"].(*string)) != "m1" {
+		t.Fatal(mresult, err)
+	}
+	cancel()
+	err = c.LoadContext(ctx, "test3", &result, 0, loader)
+	if err != context.Canceled {
+		t.Fatal(err)
+	}
+	lctx, lcancel := cache.LoaderContext(context.Background(), time.Second)
+	defer lcancel()
+	if _, ok := lctx.Deadline(); ok {
+		t.Fatal(lctx)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strings"
 	"sync"
 )
 
@@ -62,21 +63,92 @@ func MarshalerFactories() []string {
 }
 
 //NewMarshaler create new marshaler with given name.
+//Name not registered in format "marshaler+codec",for example "msgpack+zstd",
+//creates registered marshaler wrapped with registered compression codec by NewCompressedMarshaler.
 //Return marshaler created and any error if raised.
 func NewMarshaler(name string) (Marshaler, error) {
 	marshalerFactorysMu.RLock()
 	factoryi, ok := marshalerFactories[name]
 	marshalerFactorysMu.RUnlock()
 	if !ok {
+		if i := strings.LastIndex(name, CompressedMarshalerSeparator); i > 0 {
+			return newCompressedMarshalerByName(name[:i], name[i+len(CompressedMarshalerSeparator):])
+		}
 		errmsg := "cache: unknown marshaler %q (forgotten import?)"
 		if name == "msgpack" {
 			errmsg = errmsg + "\nYou should add \n\timport _ \"github.com/herb-go/deprecated/cache/marshalers/msgpackmarshaler\" \nto your code."
 		}
+		if name == "gob" {
+			errmsg = errmsg + "\nYou should add \n\timport _ \"github.com/herb-go/deprecated/cache/marshalers/gobmarshaler\" \nto your code."
+		}
+		if name == "protobuf" {
+			errmsg = errmsg + "\nYou should add \n\timport _ \"github.com/herb-go/deprecated/cache/marshalers/protobufmarshaler\" \nto your code."
+		}
 		return nil, fmt.Errorf(errmsg, name)
 	}
 	return factoryi()
 }
 
+//CompressedMarshalerSeparator separator between marshaler name and codec name in compressed marshaler name,
+//for example "msgpack+zstd".
+const CompressedMarshalerSeparator = "+"
+
+//CompressedMarshaler marshaler decorator which compresses data marshaled by inner marshaler.
+//Data is prefixed with codec header byte same as cache compression.
+type CompressedMarshaler struct {
+	//Marshaler inner marshaler.
+	Marshaler Marshaler
+	//Compressor compressor used.
+	Compressor *Compressor
+}
+
+//NewCompressedMarshaler create new marshaler which wraps given inner marshaler with compression.
+//Data not smaller than threshold in bytes will be compressed by registered codec with given name.
+//DefaultCompressionMinSize will be used if threshold is not positive.
+//Return marshaler created and any error if raised.
+func NewCompressedMarshaler(inner Marshaler, codec string, threshold int) (*CompressedMarshaler, error) {
+	if threshold <= 0 {
+		threshold = DefaultCompressionMinSize
+	}
+	c, err := NewCompressor(codec, threshold)
+	if err != nil {
+		return nil, err
+	}
+	return &CompressedMarshaler{
+		Marshaler:  inner,
+		Compressor: c,
+	}, nil
+}
+
+func newCompressedMarshalerByName(name string, codec string) (Marshaler, error) {
+	inner, err := NewMarshaler(name)
+	if err != nil {
+		return nil, err
+	}
+	return NewCompressedMarshaler(inner, codec, DefaultCompressionMinSize)
+}
+
+//Marshal Marshal data model to  bytes.
+//Return marshaled bytes and any erro rasied.
+func (m *CompressedMarshaler) Marshal(v interface{}) ([]byte, error) {
+	data, err := m.Marshaler.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return m.Compressor.Encode(data)
+}
+
+//Unmarshal Unmarshal bytes to data model.
+//Parameter v should be pointer to empty data model which data filled in.
+//Return any error raseid.
+func (m *CompressedMarshaler) Unmarshal(bytes []byte, v interface{}) error {
+	data, err := m.Compressor.Decode(bytes)
+	if err != nil {
+		return err
+	}
+	return m.Marshaler.Unmarshal(data, v)
+}
+
 //JSONMarshaler Marshaler which marshal data as JSON format
 type JSONMarshaler struct {
 }
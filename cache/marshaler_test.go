@@ -68,3 +68,42 @@ func TestNotExistMarshler(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestCompressedMarshaler(t *testing.T) {
+	large := strings.Repeat("testdata", 1000)
+	marshaler, err := cache.NewMarshaler("json+gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := marshaler.Marshal(large)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bs[0] != cache.CodecHeaderGzip || len(bs) >= len(large) {
+		t.Fatal(len(bs))
+	}
+	var v string
+	err = marshaler.Unmarshal(bs, &v)
+	if err != nil || v != large {
+		t.Fatal(err)
+	}
+	bs, err = marshaler.Marshal("small")
+	if err != nil || bs[0] != cache.CodecHeaderNone || string(bs[1:]) != `"small"` {
+		t.Fatal(string(bs), err)
+	}
+	compressed, err := cache.NewCompressedMarshaler(&cache.JSONMarshaler{}, "gzip", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if compressed.Compressor.MinSize != 4 {
+		t.Fatal(compressed.Compressor.MinSize)
+	}
+	_, err = cache.NewMarshaler("json+notexist")
+	if err == nil {
+		t.Fatal(err)
+	}
+	_, err = cache.NewMarshaler("notexist+gzip")
+	if err == nil || !strings.Contains(err.Error(), "notexist") {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,51 @@
+package gobmarshaler
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+//GobMarshaler gob marshaler.
+//Concrete types stored in interface values should be registered with Register or RegisterName before marshaling.
+type GobMarshaler struct {
+}
+
+//Marshal Marshal data model to  bytes.
+//Return marshaled bytes and any erro rasied.
+func (m *GobMarshaler) Marshal(v interface{}) ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	err := gob.NewEncoder(buf).Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+//Unmarshal Unmarshal bytes to data model.
+//Parameter v should be pointer to empty data model which data filled in.
+//Return any error raseid.
+func (m *GobMarshaler) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+//Register register concrete types of given values,so that they can be marshaled as interface values.
+//Types are registered by their default gob names.
+func Register(values ...interface{}) {
+	for _, v := range values {
+		gob.Register(v)
+	}
+}
+
+//RegisterName register concrete type of given value with given name,so that it can be marshaled as interface value.
+//Name should be kept unchanged when type is renamed or moved,or cached data will not be unmarshaled.
+func RegisterName(name string, value interface{}) {
+	gob.RegisterName(name, value)
+}
+
+func init() {
+	cache.RegisterMarshaler("gob", func() (cache.Marshaler, error) {
+		return &GobMarshaler{}, nil
+	})
+}
@@ -0,0 +1,64 @@
+package gobmarshaler
+
+import (
+	"testing"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+type testShape interface {
+	Area() int
+}
+
+type testRect struct {
+	Width  int
+	Height int
+}
+
+func (r *testRect) Area() int {
+	return r.Width * r.Height
+}
+
+type testSquare struct {
+	Side int
+}
+
+func (s testSquare) Area() int {
+	return s.Side * s.Side
+}
+
+type testModel struct {
+	Name   string
+	Shapes []testShape
+}
+
+func init() {
+	Register(&testRect{})
+	RegisterName("gobmarshaler.testSquare", testSquare{})
+}
+
+func TestGob(t *testing.T) {
+	var testdata = &testModel{
+		Name:   "test",
+		Shapes: []testShape{&testRect{Width: 2, Height: 3}, testSquare{Side: 4}},
+	}
+	marshaler, err := cache.NewMarshaler("gob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := marshaler.Marshal(testdata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v = &testModel{}
+	err = marshaler.Unmarshal(bs, v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Name != "test" || len(v.Shapes) != 2 || v.Shapes[0].Area() != 6 || v.Shapes[1].Area() != 16 {
+		t.Fatal(v)
+	}
+	if _, ok := v.Shapes[0].(*testRect); !ok {
+		t.Fatal(v.Shapes[0])
+	}
+}
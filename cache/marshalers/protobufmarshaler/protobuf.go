@@ -0,0 +1,68 @@
+package protobufmarshaler
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/herb-go/deprecated/cache"
+	"google.golang.org/protobuf/proto"
+)
+
+//ErrNotProtoMessage error raised if value marshaled or unmarshaled is not a proto message.
+var ErrNotProtoMessage = errors.New("protobufmarshaler: value is not a proto message")
+
+//ProtobufMarshaler protocol buffers marshaler.
+//Only proto messages or pointers to proto message pointers can be marshaled and unmarshaled.
+type ProtobufMarshaler struct {
+}
+
+//Marshal Marshal data model to  bytes.
+//Return marshaled bytes and any erro rasied.
+//Return ErrNotProtoMessage if v is not a proto message.
+func (m *ProtobufMarshaler) Marshal(v interface{}) ([]byte, error) {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrNotProtoMessage, v)
+	}
+	return proto.Marshal(msg)
+}
+
+//Unmarshal Unmarshal bytes to data model.
+//Parameter v should be proto message or pointer to proto message pointer which data filled in.
+//Nil message pointer will be allocated.
+//Return any error raseid.
+//Return ErrNotProtoMessage if v is not a proto message.
+func (m *ProtobufMarshaler) Unmarshal(bytes []byte, v interface{}) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		msg, ok = messageOf(v)
+		if !ok {
+			return fmt.Errorf("%w: %T", ErrNotProtoMessage, v)
+		}
+	}
+	return proto.Unmarshal(bytes, msg)
+}
+
+//messageOf return message pointed by given pointer to message pointer.
+//Nil message pointer will be allocated.
+func messageOf(v interface{}) (proto.Message, bool) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Ptr {
+		return nil, false
+	}
+	elem := rv.Elem()
+	if _, ok := elem.Interface().(proto.Message); !ok {
+		return nil, false
+	}
+	if elem.IsNil() {
+		elem.Set(reflect.New(elem.Type().Elem()))
+	}
+	return elem.Interface().(proto.Message), true
+}
+
+func init() {
+	cache.RegisterMarshaler("protobuf", func() (cache.Marshaler, error) {
+		return &ProtobufMarshaler{}, nil
+	})
+}
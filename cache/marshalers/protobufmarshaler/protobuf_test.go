@@ -0,0 +1,45 @@
+package protobufmarshaler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/herb-go/deprecated/cache"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestProtobuf(t *testing.T) {
+	marshaler, err := cache.NewMarshaler("protobuf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := marshaler.Marshal(wrapperspb.String("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v = &wrapperspb.StringValue{}
+	err = marshaler.Unmarshal(bs, v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.GetValue() != "test" {
+		t.Fatal(v)
+	}
+	var p *wrapperspb.StringValue
+	err = marshaler.Unmarshal(bs, &p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.GetValue() != "test" {
+		t.Fatal(p)
+	}
+	_, err = marshaler.Marshal(map[string]string{"test": "test"})
+	if !errors.Is(err, ErrNotProtoMessage) {
+		t.Fatal(err)
+	}
+	var s string
+	err = marshaler.Unmarshal(bs, &s)
+	if !errors.Is(err, ErrNotProtoMessage) {
+		t.Fatal(err)
+	}
+}
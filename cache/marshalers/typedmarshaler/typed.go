@@ -0,0 +1,249 @@
+//Package typedmarshaler provides a composite marshaler which routes values to marshalers by go type,
+//so that heterogeneous caches can store values of different formats side by side.
+//
+//Marshaled data is prefixed with a header of tag length(1 byte) and tag of marshaler used,
+//and unmarshaled by the marshaler registered with same tag.
+package typedmarshaler
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+//ErrUnknownTag error raised if data header tag is not registered.
+var ErrUnknownTag = errors.New("typedmarshaler: unknown tag")
+
+//ErrInvalidHeader error raised if data header is missing or truncated.
+var ErrInvalidHeader = errors.New("typedmarshaler: invalid header")
+
+//ErrInvalidTag error raised if tag registered is empty or longer than 255 bytes.
+var ErrInvalidTag = errors.New("typedmarshaler: invalid tag")
+
+//ErrNoMarshaler error raised if no marshaler is registered for value type and no default marshaler is set.
+var ErrNoMarshaler = errors.New("typedmarshaler: no marshaler for type")
+
+//DefaultTag tag of default marshaler used by New.
+const DefaultTag = "json"
+
+//RawTag tag of raw marshaler registered by New.
+const RawTag = "raw"
+
+type route struct {
+	tag       string
+	marshaler cache.Marshaler
+}
+
+type interfaceRoute struct {
+	route
+	iface reflect.Type
+}
+
+//TypedMarshaler composite marshaler which routes values to marshalers by go type.
+//Concrete types are looked up first,then interfaces in registration order,then default marshaler.
+type TypedMarshaler struct {
+	locker     sync.RWMutex
+	tags       map[string]cache.Marshaler
+	types      map[reflect.Type]*route
+	interfaces []*interfaceRoute
+	fallback   *route
+}
+
+//New create new typed marshaler.
+//Json marshaler is registered as default marshaler with DefaultTag,
+//and RawMarshaler is registered for []byte with RawTag.
+func New() *TypedMarshaler {
+	m := &TypedMarshaler{
+		tags:  map[string]cache.Marshaler{},
+		types: map[reflect.Type]*route{},
+	}
+	m.MustSetDefault(DefaultTag, &cache.JSONMarshaler{})
+	m.MustRegisterType(RawTag, []byte(nil), RawMarshaler{})
+	return m
+}
+
+func (m *TypedMarshaler) registerTag(tag string, marshaler cache.Marshaler) error {
+	if tag == "" || len(tag) > 255 {
+		return ErrInvalidTag
+	}
+	m.tags[tag] = marshaler
+	return nil
+}
+
+//RegisterType register marshaler with given tag for concrete type of given sample value.
+//Values of sample type or pointer to sample type are marshaled by given marshaler.
+//Return any error if raised.
+func (m *TypedMarshaler) RegisterType(tag string, sample interface{}, marshaler cache.Marshaler) error {
+	m.locker.Lock()
+	defer m.locker.Unlock()
+	err := m.registerTag(tag, marshaler)
+	if err != nil {
+		return err
+	}
+	m.types[reflect.TypeOf(sample)] = &route{tag: tag, marshaler: marshaler}
+	return nil
+}
+
+//MustRegisterType register marshaler with given tag for concrete type of given sample value.
+//Panic if any error raised.
+func (m *TypedMarshaler) MustRegisterType(tag string, sample interface{}, marshaler cache.Marshaler) {
+	err := m.RegisterType(tag, sample, marshaler)
+	if err != nil {
+		panic(err)
+	}
+}
+
+//RegisterInterface register marshaler with given tag for interface pointed by given pointer,
+//for example (*proto.Message)(nil).
+//Values implementing interface are marshaled by given marshaler if their concrete types are not registered.
+//Return any error if raised.
+func (m *TypedMarshaler) RegisterInterface(tag string, ifacePtr interface{}, marshaler cache.Marshaler) error {
+	t := reflect.TypeOf(ifacePtr)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Interface {
+		return fmt.Errorf("typedmarshaler: %T is not a pointer to interface", ifacePtr)
+	}
+	m.locker.Lock()
+	defer m.locker.Unlock()
+	err := m.registerTag(tag, marshaler)
+	if err != nil {
+		return err
+	}
+	m.interfaces = append(m.interfaces, &interfaceRoute{route: route{tag: tag, marshaler: marshaler}, iface: t.Elem()})
+	return nil
+}
+
+//MustRegisterInterface register marshaler with given tag for interface pointed by given pointer.
+//Panic if any error raised.
+func (m *TypedMarshaler) MustRegisterInterface(tag string, ifacePtr interface{}, marshaler cache.Marshaler) {
+	err := m.RegisterInterface(tag, ifacePtr, marshaler)
+	if err != nil {
+		panic(err)
+	}
+}
+
+//SetDefault set marshaler with given tag used for values of types not registered.
+//Default marshaler is removed if given marshaler is nil,and ErrNoMarshaler will be raised for types not registered.
+//Return any error if raised.
+func (m *TypedMarshaler) SetDefault(tag string, marshaler cache.Marshaler) error {
+	m.locker.Lock()
+	defer m.locker.Unlock()
+	if marshaler == nil {
+		m.fallback = nil
+		return nil
+	}
+	err := m.registerTag(tag, marshaler)
+	if err != nil {
+		return err
+	}
+	m.fallback = &route{tag: tag, marshaler: marshaler}
+	return nil
+}
+
+//MustSetDefault set marshaler with given tag used for values of types not registered.
+//Panic if any error raised.
+func (m *TypedMarshaler) MustSetDefault(tag string, marshaler cache.Marshaler) {
+	err := m.SetDefault(tag, marshaler)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (m *TypedMarshaler) route(v interface{}) *route {
+	m.locker.RLock()
+	defer m.locker.RUnlock()
+	t := reflect.TypeOf(v)
+	if t != nil {
+		if r, ok := m.types[t]; ok {
+			return r
+		}
+		if t.Kind() == reflect.Ptr {
+			if r, ok := m.types[t.Elem()]; ok {
+				return r
+			}
+		}
+		for _, r := range m.interfaces {
+			if t.Implements(r.iface) {
+				return &r.route
+			}
+		}
+	}
+	return m.fallback
+}
+
+//Marshal Marshal data model to  bytes.
+//Return marshaled bytes and any erro rasied.
+func (m *TypedMarshaler) Marshal(v interface{}) ([]byte, error) {
+	r := m.route(v)
+	if r == nil {
+		return nil, fmt.Errorf("%w: %T", ErrNoMarshaler, v)
+	}
+	data, err := r.marshaler.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 1+len(r.tag)+len(data))
+	buf[0] = byte(len(r.tag))
+	copy(buf[1:], r.tag)
+	copy(buf[1+len(r.tag):], data)
+	return buf, nil
+}
+
+//Unmarshal Unmarshal bytes to data model.
+//Parameter v should be pointer to empty data model which data filled in.
+//Data is unmarshaled by marshaler registered with tag in data header.
+//Return any error raseid.
+func (m *TypedMarshaler) Unmarshal(bytes []byte, v interface{}) error {
+	if len(bytes) == 0 || len(bytes) < 1+int(bytes[0]) {
+		return ErrInvalidHeader
+	}
+	tag := string(bytes[1 : 1+int(bytes[0])])
+	m.locker.RLock()
+	marshaler, ok := m.tags[tag]
+	m.locker.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownTag, tag)
+	}
+	return marshaler.Unmarshal(bytes[1+int(bytes[0]):], v)
+}
+
+//RawMarshaler marshaler which stores raw bytes without encoding.
+//Only []byte or *[]byte values can be marshaled,and only *[]byte can be unmarshaled.
+type RawMarshaler struct {
+}
+
+//Marshal Marshal data model to  bytes.
+//Return marshaled bytes and any erro rasied.
+func (RawMarshaler) Marshal(v interface{}) ([]byte, error) {
+	switch data := v.(type) {
+	case []byte:
+		return data, nil
+	case *[]byte:
+		return *data, nil
+	}
+	return nil, fmt.Errorf("typedmarshaler: raw marshaler can not marshal %T", v)
+}
+
+//Unmarshal Unmarshal bytes to data model.
+//Parameter v should be *[]byte which data filled in.
+//Return any error raseid.
+func (RawMarshaler) Unmarshal(bytes []byte, v interface{}) error {
+	data, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("typedmarshaler: raw marshaler can not unmarshal to %T", v)
+	}
+	*data = append([]byte{}, bytes...)
+	return nil
+}
+
+//DefaultTypedMarshaler typed marshaler registered as "typed" marshaler.
+//Types should be registered to it before values marshaled.
+var DefaultTypedMarshaler = New()
+
+func init() {
+	cache.RegisterMarshaler("typed", func() (cache.Marshaler, error) {
+		return DefaultTypedMarshaler, nil
+	})
+}
@@ -0,0 +1,102 @@
+package typedmarshaler
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/herb-go/deprecated/cache"
+	"github.com/herb-go/deprecated/cache/marshalers/gobmarshaler"
+)
+
+type testUpper interface {
+	Upper() string
+}
+
+type testText struct {
+	Text string
+}
+
+func (t *testText) Upper() string {
+	return strings.ToUpper(t.Text)
+}
+
+type upperMarshaler struct {
+}
+
+func (upperMarshaler) Marshal(v interface{}) ([]byte, error) {
+	return []byte(v.(testUpper).Upper()), nil
+}
+
+func (upperMarshaler) Unmarshal(bytes []byte, v interface{}) error {
+	v.(*testText).Text = string(bytes)
+	return nil
+}
+
+type testModel struct {
+	Name string
+}
+
+func TestTyped(t *testing.T) {
+	m := New()
+	m.MustRegisterType("gob", testModel{}, &gobmarshaler.GobMarshaler{})
+	m.MustRegisterInterface("upper", (*testUpper)(nil), upperMarshaler{})
+	bs, err := m.Marshal(&testModel{Name: "gob"})
+	if err != nil || !strings.HasPrefix(string(bs), "\x03gob") {
+		t.Fatal(string(bs), err)
+	}
+	model := &testModel{}
+	err = m.Unmarshal(bs, model)
+	if err != nil || model.Name != "gob" {
+		t.Fatal(model, err)
+	}
+	bs, err = m.Marshal(&testText{Text: "text"})
+	if err != nil || string(bs) != "\x05upperTEXT" {
+		t.Fatal(string(bs), err)
+	}
+	text := &testText{}
+	err = m.Unmarshal(bs, text)
+	if err != nil || text.Text != "TEXT" {
+		t.Fatal(text, err)
+	}
+	bs, err = m.Marshal([]byte("raw"))
+	if err != nil || string(bs) != "\x03rawraw" {
+		t.Fatal(string(bs), err)
+	}
+	var raw []byte
+	err = m.Unmarshal(bs, &raw)
+	if err != nil || string(raw) != "raw" {
+		t.Fatal(string(raw), err)
+	}
+	bs, err = m.Marshal(map[string]string{"test": "json"})
+	if err != nil || string(bs) != "\x04json{\"test\":\"json\"}" {
+		t.Fatal(string(bs), err)
+	}
+	err = m.Unmarshal([]byte("\x07unknown{}"), &raw)
+	if !errors.Is(err, ErrUnknownTag) {
+		t.Fatal(err)
+	}
+	err = m.Unmarshal([]byte("\x07raw"), &raw)
+	if err != ErrInvalidHeader {
+		t.Fatal(err)
+	}
+	m.MustSetDefault("", nil)
+	_, err = m.Marshal(1)
+	if !errors.Is(err, ErrNoMarshaler) {
+		t.Fatal(err)
+	}
+	err = m.RegisterInterface("upper", testText{}, upperMarshaler{})
+	if err == nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRegisteredMarshaler(t *testing.T) {
+	marshaler, err := cache.NewMarshaler("typed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if marshaler != DefaultTypedMarshaler {
+		t.Fatal(marshaler)
+	}
+}
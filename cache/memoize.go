@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"time"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+//MemoizeKey return cache key of given function arguments.
+//Arguments are marshaled by marshaler of given cacheable and hashed by sha256.
+//Return key and any error if raised.
+func MemoizeKey(c Cacheable, args ...interface{}) (string, error) {
+	bs, err := c.Util().Marshal(args)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(bs)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+//Memoize wrap given function with given cacheable.
+//Fn must be a function which returns a value,or a value and an error.
+//Results are cached with keys derived from arguments by MemoizeKey,
+//so every memoized function should use its own cacheable,like a Node.
+//Error returned by fn will not be cached.
+//If fn returns only a value,fn will be called directly when cache raises error.
+//Return wrapped function with same type as fn,which should be type asserted by caller,for example:
+//
+//	getUser := cache.Memoize(cache.NewNode(c, "user"), time.Minute, loadUser).(func(string) (*User, error))
+//
+//Panic if fn is not a supported function.
+func Memoize(c Cacheable, ttl time.Duration, fn interface{}) interface{} {
+	fv := reflect.ValueOf(fn)
+	ft := fv.Type()
+	if ft.Kind() != reflect.Func {
+		panic("cache: memoize non-function " + ft.String())
+	}
+	withErr := ft.NumOut() == 2 && ft.Out(1) == errorType
+	if ft.NumOut() != 1 && !withErr {
+		panic("cache: memoize function must return a value,or a value and an error " + ft.String())
+	}
+	outType := ft.Out(0)
+	call := func(in []reflect.Value) []reflect.Value {
+		if ft.IsVariadic() {
+			return fv.CallSlice(in)
+		}
+		return fv.Call(in)
+	}
+	return reflect.MakeFunc(ft, func(in []reflect.Value) []reflect.Value {
+		args := make([]interface{}, len(in))
+		for k := range in {
+			args[k] = in[k].Interface()
+		}
+		result := reflect.New(outType)
+		key, err := MemoizeKey(c, args...)
+		if err == nil {
+			err = c.Load(key, result.Interface(), ttl, func(string) (interface{}, error) {
+				out := call(in)
+				if withErr && !out[1].IsNil() {
+					return nil, out[1].Interface().(error)
+				}
+				v := reflect.New(outType)
+				v.Elem().Set(out[0])
+				return v.Interface(), nil
+			})
+		}
+		if !withErr {
+			if err != nil {
+				return call(in)
+			}
+			return []reflect.Value{result.Elem()}
+		}
+		if err != nil {
+			return []reflect.Value{reflect.Zero(outType), reflect.ValueOf(&err).Elem()}
+		}
+		return []reflect.Value{result.Elem(), reflect.Zero(errorType)}
+	}).Interface()
+}
@@ -0,0 +1,78 @@
+package cache_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+type memoizeResult struct {
+	Name  string
+	Count int
+}
+
+func TestMemoize(t *testing.T) {
+	c := newTestCache(3600)
+	var calls int
+	fn := func(name string, count int) (*memoizeResult, error) {
+		calls++
+		if name == "" {
+			return nil, errors.New("empty name")
+		}
+		return &memoizeResult{Name: name, Count: count}, nil
+	}
+	memoized := cache.Memoize(cache.NewNode(c, "memoize"), time.Minute, fn).(func(string, int) (*memoizeResult, error))
+	for i := 0; i < 3; i++ {
+		r, err := memoized("test", 1)
+		if err != nil || r.Name != "test" || r.Count != 1 {
+			t.Fatal(r, err)
+		}
+	}
+	if calls != 1 {
+		t.Fatal(calls)
+	}
+	r, err := memoized("test", 2)
+	if err != nil || r.Count != 2 || calls != 2 {
+		t.Fatal(r, err, calls)
+	}
+	_, err = memoized("", 1)
+	if err == nil || err.Error() != "empty name" {
+		t.Fatal(err)
+	}
+	_, err = memoized("", 1)
+	if err == nil || calls != 4 {
+		t.Fatal(err, calls)
+	}
+}
+
+func TestMemoizeVariadic(t *testing.T) {
+	c := newTestCache(3600)
+	var calls int
+	fn := func(values ...int) string {
+		calls++
+		var result string
+		for _, v := range values {
+			result = result + strconv.Itoa(v)
+		}
+		return result
+	}
+	memoized := cache.Memoize(cache.NewNode(c, "variadic"), time.Minute, fn).(func(...int) string)
+	if memoized(1, 2, 3) != "123" || memoized(1, 2, 3) != "123" || calls != 1 {
+		t.Fatal(calls)
+	}
+	if memoized(1, 2) != "12" || calls != 2 {
+		t.Fatal(calls)
+	}
+}
+
+func TestMemoizePanic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("not panic")
+		}
+	}()
+	cache.Memoize(newTestCache(3600), time.Minute, func() {})
+}
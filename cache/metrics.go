@@ -0,0 +1,285 @@
+package cache
+
+import (
+	"errors"
+	"time"
+)
+
+//Cache operation names used in metrics.
+const (
+	OpGet         = "get"
+	OpSet         = "set"
+	OpUpdate      = "update"
+	OpDel         = "del"
+	OpLoad        = "load"
+	OpMGet        = "mget"
+	OpMSet        = "mset"
+	OpExpire      = "expire"
+	OpIncrCounter = "incrcounter"
+	OpSetCounter  = "setcounter"
+	OpGetCounter  = "getcounter"
+	OpDelCounter  = "delcounter"
+	OpFlush       = "flush"
+)
+
+//Metrics cache metrics interface.
+type Metrics interface {
+	//ObserveOperation record cache operation with cache name,operation name,duration,value size in bytes and error returned.
+	//ErrNotFound will not be passed as error.
+	ObserveOperation(name string, op string, duration time.Duration, size int, err error)
+	//ObserveHits record cache hits and misses count of read operation.
+	ObserveHits(name string, hits int, misses int)
+}
+
+//EntrySizeObserver optional metrics interface which records size of every value stored.
+//Sizes of values set by Set and Update method are computed by marshaling value with cache util.
+type EntrySizeObserver interface {
+	//ObserveEntrySize record size in bytes of value stored in cache with given name.
+	ObserveEntrySize(name string, size int)
+}
+
+//MetricsCacheable cacheable which records metrics of cache operations.
+type MetricsCacheable struct {
+	*Proxy
+	//Name cache name passed to metrics.
+	Name string
+	//Metrics metrics which records operations.
+	Metrics Metrics
+}
+
+//NewMetricsCacheable create new metrics cacheable with given cacheable,name and metrics.
+func NewMetricsCacheable(c Cacheable, name string, m Metrics) *MetricsCacheable {
+	return &MetricsCacheable{
+		Proxy:   NewProxy(c),
+		Name:    name,
+		Metrics: m,
+	}
+}
+
+func (c *MetricsCacheable) observe(op string, start time.Time, size int, err error) {
+	if errors.Is(err, ErrNotFound) {
+		err = nil
+	}
+	c.Metrics.ObserveOperation(c.Name, op, time.Now().Sub(start), size, err)
+}
+
+func (c *MetricsCacheable) observeEntrySize(size int) {
+	o, ok := c.Metrics.(EntrySizeObserver)
+	if ok {
+		o.ObserveEntrySize(c.Name, size)
+	}
+}
+
+func (c *MetricsCacheable) entrySizeObserved() bool {
+	_, ok := c.Metrics.(EntrySizeObserver)
+	return ok
+}
+
+func (c *MetricsCacheable) observeHit(err error) {
+	if err == nil {
+		c.Metrics.ObserveHits(c.Name, 1, 0)
+	} else if errors.Is(err, ErrNotFound) {
+		c.Metrics.ObserveHits(c.Name, 0, 1)
+	}
+}
+
+//Set Set data model to cache by given key.
+//If ttl is DefaultTTL(0),use default ttl in config instead.
+//Return any error raised.
+func (c *MetricsCacheable) Set(key string, v interface{}, ttl time.Duration) error {
+	if c.entrySizeObserved() {
+		bs, err := c.Util().Marshal(v)
+		if err != nil {
+			return err
+		}
+		return c.SetBytesValue(key, bs, ttl)
+	}
+	start := time.Now()
+	err := c.Cacheable.Set(key, v, ttl)
+	c.observe(OpSet, start, 0, err)
+	return err
+}
+
+//Get Get data model from cache by given key.
+//Parameter v should be pointer to empty data model which data filled in.
+//Return any error raised.
+func (c *MetricsCacheable) Get(key string, v interface{}) error {
+	start := time.Now()
+	err := c.Cacheable.Get(key, v)
+	c.observe(OpGet, start, 0, err)
+	c.observeHit(err)
+	return err
+}
+
+//Update Update data model to cache by given key only if the cache exist.
+//If ttl is DefaultTTL(0),use default ttl in config instead.
+//Return any error raised.
+func (c *MetricsCacheable) Update(key string, v interface{}, ttl time.Duration) error {
+	if c.entrySizeObserved() {
+		bs, err := c.Util().Marshal(v)
+		if err != nil {
+			return err
+		}
+		return c.UpdateBytesValue(key, bs, ttl)
+	}
+	start := time.Now()
+	err := c.Cacheable.Update(key, v, ttl)
+	c.observe(OpUpdate, start, 0, err)
+	return err
+}
+
+//Load Get data model from cache by given key.If data not found,call loader to get current data value and save to cache.
+//If ttl is DefaultTTL(0),use default ttl in config instead.
+//Return any error raised.
+func (c *MetricsCacheable) Load(key string, v interface{}, ttl time.Duration, loader Loader) error {
+	var hit = true
+	var l = func(key string) (interface{}, error) {
+		hit = false
+		return loader(key)
+	}
+	start := time.Now()
+	err := c.Cacheable.Load(key, v, ttl, l)
+	c.observe(OpLoad, start, 0, err)
+	if err == nil && hit {
+		c.Metrics.ObserveHits(c.Name, 1, 0)
+	} else if err == nil {
+		c.Metrics.ObserveHits(c.Name, 0, 1)
+	}
+	return err
+}
+
+//SetBytesValue Set bytes data to cache by given key.
+//If ttl is DefaultTTL(0),use default ttl in config instead.
+//Return any error raised.
+func (c *MetricsCacheable) SetBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	start := time.Now()
+	err := c.Cacheable.SetBytesValue(key, bytes, ttl)
+	c.observe(OpSet, start, len(bytes), err)
+	if err == nil {
+		c.observeEntrySize(len(bytes))
+	}
+	return err
+}
+
+//UpdateBytesValue Update bytes data to cache by given key only if the cache exist.
+//If ttl is DefaultTTL(0),use default ttl in config instead.
+//Return any error raised.
+func (c *MetricsCacheable) UpdateBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	start := time.Now()
+	err := c.Cacheable.UpdateBytesValue(key, bytes, ttl)
+	c.observe(OpUpdate, start, len(bytes), err)
+	if err == nil {
+		c.observeEntrySize(len(bytes))
+	}
+	return err
+}
+
+//GetBytesValue Get bytes data from cache by given key.
+//Return data bytes and any error raised.
+func (c *MetricsCacheable) GetBytesValue(key string) ([]byte, error) {
+	start := time.Now()
+	bs, err := c.Cacheable.GetBytesValue(key)
+	c.observe(OpGet, start, len(bs), err)
+	c.observeHit(err)
+	return bs, err
+}
+
+//MGetBytesValue get multiple bytes data from cache by given keys.
+//Return data bytes map and any error if raised.
+func (c *MetricsCacheable) MGetBytesValue(keys ...string) (map[string][]byte, error) {
+	start := time.Now()
+	data, err := c.Cacheable.MGetBytesValue(keys...)
+	var size int
+	for k := range data {
+		size = size + len(data[k])
+	}
+	c.observe(OpMGet, start, size, err)
+	if err == nil {
+		c.Metrics.ObserveHits(c.Name, len(data), len(keys)-len(data))
+	}
+	return data, err
+}
+
+//MSetBytesValue set multiple bytes data to cache with given key-value map.
+//Return  any error if raised.
+func (c *MetricsCacheable) MSetBytesValue(data map[string][]byte, ttl time.Duration) error {
+	start := time.Now()
+	err := c.Cacheable.MSetBytesValue(data, ttl)
+	var size int
+	for k := range data {
+		size = size + len(data[k])
+	}
+	c.observe(OpMSet, start, size, err)
+	if err == nil {
+		for k := range data {
+			c.observeEntrySize(len(data[k]))
+		}
+	}
+	return err
+}
+
+//Del Delete data in cache by given name.
+//Return any error raised.
+func (c *MetricsCacheable) Del(key string) error {
+	start := time.Now()
+	err := c.Cacheable.Del(key)
+	c.observe(OpDel, start, 0, err)
+	return err
+}
+
+//Expire set cache value expire duration by given key and ttl
+//Return any error raised.
+func (c *MetricsCacheable) Expire(key string, ttl time.Duration) error {
+	start := time.Now()
+	err := c.Cacheable.Expire(key, ttl)
+	c.observe(OpExpire, start, 0, err)
+	return err
+}
+
+//IncrCounter Increase int val in cache by given key.Count cache and data cache are in two independent namespace.
+//If ttl is DefaultTTL(0),use default ttl in config instead.
+//Return int data value and any error raised.
+func (c *MetricsCacheable) IncrCounter(key string, increment int64, ttl time.Duration) (int64, error) {
+	start := time.Now()
+	v, err := c.Cacheable.IncrCounter(key, increment, ttl)
+	c.observe(OpIncrCounter, start, 0, err)
+	return v, err
+}
+
+//SetCounter Set int val in cache by given key.Count cache and data cache are in two independent namespace.
+//If ttl is DefaultTTL(0),use default ttl in config instead.
+//Return any error raised.
+func (c *MetricsCacheable) SetCounter(key string, v int64, ttl time.Duration) error {
+	start := time.Now()
+	err := c.Cacheable.SetCounter(key, v, ttl)
+	c.observe(OpSetCounter, start, 0, err)
+	return err
+}
+
+//GetCounter Get int val from cache by given key.Count cache and data cache are in two independent namespace.
+//Return int data value and any error raised.
+func (c *MetricsCacheable) GetCounter(key string) (int64, error) {
+	start := time.Now()
+	v, err := c.Cacheable.GetCounter(key)
+	c.observe(OpGetCounter, start, 0, err)
+	c.observeHit(err)
+	return v, err
+}
+
+//DelCounter Delete int val in cache by given name.Count cache and data cache are in two independent namespace.
+//Return any error raised.
+func (c *MetricsCacheable) DelCounter(key string) error {
+	start := time.Now()
+	err := c.Cacheable.DelCounter(key)
+	c.observe(OpDelCounter, start, 0, err)
+	return err
+}
+
+//Flush Delete all data in cache.
+//Return any error if raised.
+func (c *MetricsCacheable) Flush() error {
+	start := time.Now()
+	err := c.Cacheable.Flush()
+	c.observe(OpFlush, start, 0, err)
+	return err
+}
@@ -0,0 +1,142 @@
+//Package prometheusmetrics provides cache metrics collector uses prometheus.
+package prometheusmetrics
+
+import (
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+//DefaultNamespace default metrics namespace.
+var DefaultNamespace = "herb_cache"
+
+//DefaultLatencyBuckets default buckets of operation latency histogram in second.
+var DefaultLatencyBuckets = []float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1}
+
+//DefaultSizeBuckets default buckets of value size histogram in byte.
+var DefaultSizeBuckets = prometheus.ExponentialBuckets(64, 4, 8)
+
+//Metrics prometheus cache metrics collector.
+type Metrics struct {
+	//Operations operations counter with cache and op labels.
+	Operations *prometheus.CounterVec
+	//Errors errors counter with cache and op labels.
+	Errors *prometheus.CounterVec
+	//Hits cache hits counter with cache label.
+	Hits *prometheus.CounterVec
+	//Misses cache misses counter with cache label.
+	Misses *prometheus.CounterVec
+	//Latency operation latency histogram with cache and op labels.
+	Latency *prometheus.HistogramVec
+	//Size value size histogram with cache and op labels.
+	Size *prometheus.HistogramVec
+	//EntrySize stored entry size histogram with cache label.
+	EntrySize *prometheus.HistogramVec
+}
+
+//New create new prometheus metrics with given namespace.
+//DefaultNamespace will be used if namespace is empty.
+func New(namespace string) *Metrics {
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+	return &Metrics{
+		Operations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "operations_total",
+			Help:      "Total number of cache operations.",
+		}, []string{"cache", "op"}),
+		Errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "errors_total",
+			Help:      "Total number of cache operation errors.",
+		}, []string{"cache", "op"}),
+		Hits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "hits_total",
+			Help:      "Total number of cache hits.",
+		}, []string{"cache"}),
+		Misses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "misses_total",
+			Help:      "Total number of cache misses.",
+		}, []string{"cache"}),
+		Latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "operation_duration_seconds",
+			Help:      "Cache operation latency in seconds.",
+			Buckets:   DefaultLatencyBuckets,
+		}, []string{"cache", "op"}),
+		Size: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "value_size_bytes",
+			Help:      "Cache value size in bytes.",
+			Buckets:   DefaultSizeBuckets,
+		}, []string{"cache", "op"}),
+		EntrySize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "entry_size_bytes",
+			Help:      "Size of every cache entry stored in bytes.",
+			Buckets:   DefaultSizeBuckets,
+		}, []string{"cache"}),
+	}
+}
+
+//ObserveOperation record cache operation with cache name,operation name,duration,value size in bytes and error returned.
+func (m *Metrics) ObserveOperation(name string, op string, duration time.Duration, size int, err error) {
+	m.Operations.WithLabelValues(name, op).Inc()
+	m.Latency.WithLabelValues(name, op).Observe(duration.Seconds())
+	if size > 0 {
+		m.Size.WithLabelValues(name, op).Observe(float64(size))
+	}
+	if err != nil {
+		m.Errors.WithLabelValues(name, op).Inc()
+	}
+}
+
+//ObserveHits record cache hits and misses count of read operation.
+func (m *Metrics) ObserveHits(name string, hits int, misses int) {
+	if hits > 0 {
+		m.Hits.WithLabelValues(name).Add(float64(hits))
+	}
+	if misses > 0 {
+		m.Misses.WithLabelValues(name).Add(float64(misses))
+	}
+}
+
+//ObserveEntrySize record size in bytes of value stored in cache with given name.
+func (m *Metrics) ObserveEntrySize(name string, size int) {
+	m.EntrySize.WithLabelValues(name).Observe(float64(size))
+}
+
+//Describe sends the super-set of all possible descriptors of metrics collected by this collector.
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	m.Operations.Describe(ch)
+	m.Errors.Describe(ch)
+	m.Hits.Describe(ch)
+	m.Misses.Describe(ch)
+	m.Latency.Describe(ch)
+	m.Size.Describe(ch)
+	m.EntrySize.Describe(ch)
+}
+
+//Collect is called by the Prometheus registry when collecting metrics.
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.Operations.Collect(ch)
+	m.Errors.Collect(ch)
+	m.Hits.Collect(ch)
+	m.Misses.Collect(ch)
+	m.Latency.Collect(ch)
+	m.Size.Collect(ch)
+	m.EntrySize.Collect(ch)
+}
+
+//Wrap wrap given cacheable with metrics cacheable which reports to m with given name.
+func (m *Metrics) Wrap(c cache.Cacheable, name string) *cache.MetricsCacheable {
+	return cache.NewMetricsCacheable(c, name, m)
+}
+
+var _ cache.Metrics = &Metrics{}
+var _ cache.EntrySizeObserver = &Metrics{}
+var _ prometheus.Collector = &Metrics{}
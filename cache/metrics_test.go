@@ -0,0 +1,86 @@
+package cache_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+type testMetrics struct {
+	locker     sync.Mutex
+	operations map[string]int
+	sizes      map[string]int
+	errors     int
+	hits       int
+	misses     int
+}
+
+func (m *testMetrics) ObserveOperation(name string, op string, duration time.Duration, size int, err error) {
+	m.locker.Lock()
+	defer m.locker.Unlock()
+	m.operations[name+"."+op]++
+	m.sizes[name+"."+op] += size
+	if err != nil {
+		m.errors++
+	}
+}
+
+func (m *testMetrics) ObserveHits(name string, hits int, misses int) {
+	m.locker.Lock()
+	defer m.locker.Unlock()
+	m.hits += hits
+	m.misses += misses
+}
+
+func newTestMetrics() *testMetrics {
+	return &testMetrics{
+		operations: map[string]int{},
+		sizes:      map[string]int{},
+	}
+}
+
+func TestMetricsCacheable(t *testing.T) {
+	m := newTestMetrics()
+	c := cache.NewMetricsCacheable(cache.NewNode(newTestCache(3600), "node"), "test", m)
+	_, err := c.GetBytesValue("key")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	err = c.SetBytesValue("key", []byte("12345"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.GetBytesValue("key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.MGetBytesValue("key", "notexists")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result string
+	err = c.Load("load", &result, 0, testLoader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.Load("load", &result, 0, testLoader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.SetBytesValue("key", []byte("12345"), -1)
+	if err == nil {
+		t.Fatal(err)
+	}
+	if m.operations["test.get"] != 2 || m.operations["test.set"] != 2 || m.operations["test.mget"] != 1 || m.operations["test.load"] != 2 {
+		t.Fatal(m.operations)
+	}
+	if m.sizes["test.get"] != 5 || m.sizes["test.set"] != 10 || m.sizes["test.mget"] != 5 {
+		t.Fatal(m.sizes)
+	}
+	if m.hits != 3 || m.misses != 3 || m.errors != 1 {
+		t.Fatal(m.hits, m.misses, m.errors)
+	}
+}
@@ -3,6 +3,8 @@ package cache
 import (
 	"bytes"
 	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
 )
 
 //TokenMask The []bytes of alphabet and number to generate token.
@@ -62,3 +64,10 @@ func NewRandMaskedBytes(mask []byte, length int, origin []byte) ([]byte, error)
 		}
 	}
 }
+
+//ValueVersion return version of given cached value.
+//Version is hex encoded sha1 sum of value,so that it can be computed by redis lua script.
+func ValueVersion(value []byte) string {
+	sum := sha1.Sum(value)
+	return hex.EncodeToString(sum[:])
+}
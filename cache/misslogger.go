@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"math/rand"
+	"strings"
+	"time"
+)
+
+//MissLog cache miss log entry recorded by miss logger.
+type MissLog struct {
+	//Key missed key.
+	Key string
+	//Prefix key prefix,which is the part of key before last separator.
+	Prefix string
+	//LoaderDuration duration of loader call.
+	LoaderDuration time.Duration
+	//Size marshaled size of loaded value in bytes.
+	Size int
+	//Err error returned by loader.
+	Err error
+}
+
+//MissLogger cacheable which records sampled cache misses of Load method.
+type MissLogger struct {
+	*Proxy
+	//SampleRate fraction of misses to record,from 0 to 1.
+	SampleRate float64
+	//Separator key separator used to get key prefix.
+	//Default value is KeyPrefix,so that key prefix is node prefix when wrapping Node.
+	Separator string
+	//Logger function which records miss log.
+	Logger func(l *MissLog)
+}
+
+//NewMissLogger create new miss logger with given cacheable,sample rate and logger function.
+func NewMissLogger(c Cacheable, rate float64, logger func(l *MissLog)) *MissLogger {
+	return &MissLogger{
+		Proxy:      NewProxy(c),
+		SampleRate: rate,
+		Separator:  KeyPrefix,
+		Logger:     logger,
+	}
+}
+
+func (m *MissLogger) prefix(key string) string {
+	if m.Separator == "" {
+		return ""
+	}
+	i := strings.LastIndex(key, m.Separator)
+	if i < 0 {
+		return ""
+	}
+	return key[:i]
+}
+
+//Load Get data model from cache by given key.If data not found,call loader to get current data value and save to cache.
+//Sampled loader calls will be recorded by logger.
+//If ttl is DefaultTTL(0),use default ttl in config instead.
+//Return any error raised.
+func (m *MissLogger) Load(key string, v interface{}, ttl time.Duration, loader Loader) error {
+	if m.SampleRate <= 0 || m.Logger == nil {
+		return m.Cacheable.Load(key, v, ttl, loader)
+	}
+	var l = func(k string) (interface{}, error) {
+		if rand.Float64() >= m.SampleRate {
+			return loader(k)
+		}
+		start := time.Now()
+		result, err := loader(k)
+		log := &MissLog{
+			Key:            k,
+			Prefix:         m.prefix(k),
+			LoaderDuration: time.Now().Sub(start),
+			Err:            err,
+		}
+		if err == nil {
+			bs, err := m.Cacheable.Util().Marshal(result)
+			if err == nil {
+				log.Size = len(bs)
+			}
+		}
+		m.Logger(log)
+		return result, err
+	}
+	return m.Cacheable.Load(key, v, ttl, l)
+}
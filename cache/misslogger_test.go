@@ -0,0 +1,43 @@
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+func TestMissLogger(t *testing.T) {
+	c := newTestCache(3600)
+	logs := []*cache.MissLog{}
+	logger := cache.NewMissLogger(cache.NewNode(c, "user"), 1, func(l *cache.MissLog) {
+		logs = append(logs, l)
+	})
+	loader := func(key string) (interface{}, error) {
+		time.Sleep(time.Millisecond)
+		return "value", nil
+	}
+	var v string
+	err := logger.Load("test", &v, cache.DefaultTTL, loader)
+	if err != nil || v != "value" {
+		t.Fatal(v, err)
+	}
+	err = logger.Load("test", &v, cache.DefaultTTL, loader)
+	if err != nil || v != "value" {
+		t.Fatal(v, err)
+	}
+	if len(logs) != 1 {
+		t.Fatal(logs)
+	}
+	if logs[0].Prefix != "user" || logs[0].Size != len(`"value"`) || logs[0].LoaderDuration < time.Millisecond || logs[0].Err != nil {
+		t.Fatal(logs[0])
+	}
+	logger.SampleRate = 0
+	err = logger.Load("test2", &v, cache.DefaultTTL, loader)
+	if err != nil || v != "value" {
+		t.Fatal(v, err)
+	}
+	if len(logs) != 1 {
+		t.Fatal(logs)
+	}
+}
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"errors"
+	"reflect"
+	"time"
+)
+
+func mloadFromCache(c Cacheable, keys []string, v map[string]interface{}, ttl time.Duration, loader MLoader) error {
+	var filtered = make([]string, 0, len(keys))
+	var keysmap = make(map[string]bool, len(keys))
+	for _, k := range keys {
+		if k == "" {
+			return ErrKeyUnavailable
+		}
+		if keysmap[k] || v[k] == nil {
+			continue
+		}
+		keysmap[k] = true
+		filtered = append(filtered, k)
+	}
+	if len(filtered) == 0 {
+		return nil
+	}
+	data, err := c.MGetBytesValue(filtered...)
+	if err != nil {
+		return err
+	}
+	var missing = make([]string, 0, len(filtered))
+	for _, k := range filtered {
+		bs, ok := data[k]
+		if !ok || bs == nil {
+			missing = append(missing, k)
+			continue
+		}
+		err = c.Util().Unmarshal(bs, v[k])
+		if err != nil {
+			return err
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	loaded, err := loader(missing)
+	if err != nil {
+		return err
+	}
+	var values = make(map[string][]byte, len(loaded))
+	for _, k := range missing {
+		result, ok := loaded[k]
+		if !ok {
+			delete(v, k)
+			continue
+		}
+		reflect.Indirect(reflect.ValueOf(v[k])).Set(reflect.Indirect(reflect.ValueOf(result)))
+		bs, err := c.Util().Marshal(v[k])
+		if errors.Is(err, ErrNotCacheable) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		values[k] = bs
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	err = c.MSetBytesValue(values, ttl)
+	if errors.Is(err, ErrNotCacheable) || errors.Is(err, ErrEntryTooLarge) || errors.Is(err, ErrKeyTooLarge) {
+		return nil
+	}
+	return err
+}
@@ -1,16 +1,41 @@
 package cache
 
 import (
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
+var (
+	generationKeySuffix = string([]byte{0, 0, 71})
+	generationKeyPrefix = string([]byte{0, 0})
+)
+
 //Node cache Collection
 //Node is Permanent-able sub cache create from other cacheable.
+//Node stores a generation number in counter of underlying cache,which is bumped by Flush to invalidate all data in node.
+//Generation is cached in node for NodeGenerationCacheDuration,so operations in this duration do not read generation counter again.
+//Flush in current process takes effect immediately,flush in other processes takes effect after cached generation expired.
+//Generation counter expires after CollectionTTLMultiple times of default ttl,and its ttl is refreshed when read,
+//so node in use keeps its generation.
+//If node is idle longer than counter ttl,generation falls back to 0.
+//Data in node should not live longer than CollectionTTLMultiple times of default ttl,
+//otherwise data of previous generations may reappear after counter expired.
 type Node struct {
-	Cache  Cacheable
-	Prefix string
+	refreshed  int64
+	locker     sync.Mutex
+	generation int64
+	loaded     time.Time
+	Cache      Cacheable
+	Prefix     string
 }
 
+//NodeGenerationCacheDuration duration generation number cached in node.
+//Generation counter is read in every operation if duration is not positive.
+var NodeGenerationCacheDuration = time.Second
+
 //NewNode create new cache node with given cacheable and prefix.
 //Return node created.
 func NewNode(c Cacheable, prefix string) *Node {
@@ -30,17 +55,111 @@ func (c *Node) Miss() int64 {
 	return c.Cache.Miss()
 }
 
+//Capabilities return capabilities supported by underlying cache.
+//Node supports flush only if underlying cache supports counters.
+func (n *Node) Capabilities() Capabilities {
+	c := CacheableCapabilities(n.Cache)
+	if c.Has(CapabilityCounter) {
+		return c | CapabilityFlush
+	}
+	return c &^ CapabilityFlush
+}
+
+//Generation return current generation number of node.
+//Generation is 0 if node has never been flushed.
+//Return generation and any error if raised.
+func (n *Node) Generation() (int64, error) {
+	if !CacheableCapabilities(n.Cache).Has(CapabilityCounter) {
+		return 0, nil
+	}
+	if g, ok := n.cachedGeneration(); ok {
+		return g, nil
+	}
+	g, err := n.Cache.GetCounter(n.Prefix + generationKeySuffix)
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrFeatureNotSupported) {
+		g, err = 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	err = n.refreshGeneration()
+	if err != nil {
+		return 0, err
+	}
+	n.cacheGeneration(g)
+	return g, nil
+}
+
+func (n *Node) cachedGeneration() (int64, bool) {
+	n.locker.Lock()
+	defer n.locker.Unlock()
+	if n.loaded.IsZero() || time.Since(n.loaded) >= NodeGenerationCacheDuration {
+		return 0, false
+	}
+	return n.generation, true
+}
+
+func (n *Node) cacheGeneration(g int64) {
+	n.locker.Lock()
+	n.generation = g
+	n.loaded = time.Now()
+	n.locker.Unlock()
+}
+
+func (n *Node) generationTTL() time.Duration {
+	return n.Cache.DefaultTTL() * time.Duration(CollectionTTLMultiple)
+}
+
+//refreshGeneration extend ttl of generation counter.
+//Counter ttl is refreshed at most once every default ttl in current process.
+func (n *Node) refreshGeneration() error {
+	ttl := n.Cache.DefaultTTL()
+	if ttl <= 0 {
+		return nil
+	}
+	now := time.Now().UnixNano()
+	last := atomic.LoadInt64(&n.refreshed)
+	if now-last < int64(ttl) || !atomic.CompareAndSwapInt64(&n.refreshed, last, now) {
+		return nil
+	}
+	err := n.Cache.ExpireCounter(n.Prefix+generationKeySuffix, n.generationTTL())
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrFeatureNotSupported) {
+		return nil
+	}
+	return err
+}
+
+func (n *Node) generationPrefix() (string, error) {
+	g, err := n.Generation()
+	if err != nil {
+		return "", err
+	}
+	if g == 0 {
+		return n.Prefix + KeyPrefix, nil
+	}
+	return n.Prefix + generationKeyPrefix + strconv.FormatInt(g, 10) + KeyPrefix, nil
+}
+
 //GetCacheKey return raw cache key by given key.
+//Key contains current generation number of node if node has been flushed.
+//Error raised when reading generation is wrapped in KeyError with LayerNode and given key.
 //Return key and any error if raised.
 func (n *Node) GetCacheKey(key string) (string, error) {
-	return n.Prefix + KeyPrefix + key, nil
+	prefix, err := n.generationPrefix()
+	if err != nil {
+		return "", &KeyError{Layer: LayerNode, Key: key, Err: err}
+	}
+	return prefix + key, nil
 }
 
 //MustGetCacheKey return raw cache key by given key.
 //Return key.
 //Panic if any error raised.
 func (n *Node) MustGetCacheKey(key string) string {
-	k, _ := n.GetCacheKey(key)
+	k, err := n.GetCacheKey(key)
+	if err != nil {
+		panic(err)
+	}
 	return k
 }
 
@@ -48,7 +167,10 @@ func (n *Node) MustGetCacheKey(key string) string {
 //If ttl is DefaultTTL(0),use default ttl in config instead.
 //Return any error raised.
 func (n *Node) Set(key string, v interface{}, ttl time.Duration) error {
-	k := n.MustGetCacheKey(key)
+	k, err := n.GetCacheKey(key)
+	if err != nil {
+		return err
+	}
 	return n.Cache.Set(k, v, ttl)
 }
 
@@ -56,7 +178,10 @@ func (n *Node) Set(key string, v interface{}, ttl time.Duration) error {
 //If ttl is DefaultTTL(0),use default ttl in config instead.
 //Return any error raised.
 func (n *Node) Update(key string, v interface{}, TTL time.Duration) error {
-	k := n.MustGetCacheKey(key)
+	k, err := n.GetCacheKey(key)
+	if err != nil {
+		return err
+	}
 	return n.Cache.Update(k, v, TTL)
 }
 
@@ -64,7 +189,10 @@ func (n *Node) Update(key string, v interface{}, TTL time.Duration) error {
 //Parameter v should be pointer to empty data model which data filled in.
 //Return any error raised.
 func (n *Node) Get(key string, v interface{}) error {
-	k := n.MustGetCacheKey(key)
+	k, err := n.GetCacheKey(key)
+	if err != nil {
+		return err
+	}
 	return n.Cache.Get(k, v)
 }
 
@@ -72,14 +200,20 @@ func (n *Node) Get(key string, v interface{}) error {
 //If ttl is DefaultTTL(0),use default ttl in config instead.
 //Return any error raised.
 func (n *Node) SetBytesValue(key string, bytes []byte, ttl time.Duration) error {
-	k := n.MustGetCacheKey(key)
+	k, err := n.GetCacheKey(key)
+	if err != nil {
+		return err
+	}
 	return n.Cache.SetBytesValue(k, bytes, ttl)
 }
 
 //GetBytesValue Get bytes data from cache by given key.
 //Return data bytes and any error raised.
 func (n *Node) GetBytesValue(key string) ([]byte, error) {
-	k := n.MustGetCacheKey(key)
+	k, err := n.GetCacheKey(key)
+	if err != nil {
+		return nil, err
+	}
 	return n.Cache.GetBytesValue(k)
 }
 
@@ -87,7 +221,10 @@ func (n *Node) GetBytesValue(key string) ([]byte, error) {
 //If ttl is DefaultTTL(0),use default ttl in config instead.
 //Return any error raised.
 func (n *Node) UpdateBytesValue(key string, bytes []byte, TTL time.Duration) error {
-	k := n.MustGetCacheKey(key)
+	k, err := n.GetCacheKey(key)
+	if err != nil {
+		return err
+	}
 	return n.Cache.UpdateBytesValue(k, bytes, TTL)
 }
 
@@ -95,9 +232,13 @@ func (n *Node) UpdateBytesValue(key string, bytes []byte, TTL time.Duration) err
 //Return data bytes map and any error if raised.
 func (n *Node) MGetBytesValue(keys ...string) (map[string][]byte, error) {
 	var result map[string][]byte
+	prefix, err := n.generationPrefix()
+	if err != nil {
+		return result, err
+	}
 	var prefixedKeys = make([]string, len(keys))
 	for k := range keys {
-		prefixedKeys[k] = n.MustGetCacheKey(keys[k])
+		prefixedKeys[k] = prefix + keys[k]
 	}
 	data, err := n.Cache.MGetBytesValue(prefixedKeys...)
 	if err != nil {
@@ -105,7 +246,7 @@ func (n *Node) MGetBytesValue(keys ...string) (map[string][]byte, error) {
 	}
 	result = make(map[string][]byte, len(data))
 	for k := range data {
-		result[k[len(n.Prefix+KeyPrefix):]] = data[k]
+		result[k[len(prefix):]] = data[k]
 	}
 	return result, nil
 }
@@ -113,9 +254,13 @@ func (n *Node) MGetBytesValue(keys ...string) (map[string][]byte, error) {
 //MSetBytesValue set multiple bytes data to cache with given key-value map.
 //Return  any error if raised.
 func (n *Node) MSetBytesValue(data map[string][]byte, ttl time.Duration) error {
+	prefix, err := n.generationPrefix()
+	if err != nil {
+		return err
+	}
 	var prefixed = make(map[string][]byte, len(data))
 	for k := range data {
-		prefixed[n.MustGetCacheKey(k)] = data[k]
+		prefixed[prefix+k] = data[k]
 	}
 	return n.Cache.MSetBytesValue(prefixed, ttl)
 }
@@ -123,7 +268,10 @@ func (n *Node) MSetBytesValue(data map[string][]byte, ttl time.Duration) error {
 //Del Delete data in cache by given name.
 //Return any error raised.
 func (n *Node) Del(key string) error {
-	k := n.MustGetCacheKey(key)
+	k, err := n.GetCacheKey(key)
+	if err != nil {
+		return err
+	}
 	return n.Cache.Del(k)
 }
 
@@ -131,22 +279,52 @@ func (n *Node) Del(key string) error {
 //If ttl is DefaultTTL(0),use default ttl in config instead.
 //Return int data value and any error raised.
 func (n *Node) IncrCounter(key string, increment int64, ttl time.Duration) (int64, error) {
-	k := n.MustGetCacheKey(key)
+	k, err := n.GetCacheKey(key)
+	if err != nil {
+		return 0, err
+	}
 	return n.Cache.IncrCounter(k, increment, ttl)
 }
 
+//IncrFloatCounter Increase float val in cache by given key.Float counters share namespace with int counters.
+//If ttl is DefaultTTL(0),use default ttl in config instead.
+//Return float data value and any error raised.
+func (n *Node) IncrFloatCounter(key string, increment float64, ttl time.Duration) (float64, error) {
+	k, err := n.GetCacheKey(key)
+	if err != nil {
+		return 0, err
+	}
+	return IncrFloatCounter(n.Cache, k, increment, ttl)
+}
+
+//GetFloatCounter Get float val from cache by given key.Float counters share namespace with int counters.
+//Return float data value and any error raised.
+func (n *Node) GetFloatCounter(key string) (float64, error) {
+	k, err := n.GetCacheKey(key)
+	if err != nil {
+		return 0, err
+	}
+	return GetFloatCounter(n.Cache, k)
+}
+
 //SetCounter Set int val in cache by given key.Count cache and data cache are in two independent namespace.
 //If ttl is DefaultTTL(0),use default ttl in config instead.
 //Return any error raised.
 func (n *Node) SetCounter(key string, v int64, ttl time.Duration) error {
-	k := n.MustGetCacheKey(key)
+	k, err := n.GetCacheKey(key)
+	if err != nil {
+		return err
+	}
 	return n.Cache.SetCounter(k, v, ttl)
 }
 
 //GetCounter Get int val from cache by given key.Count cache and data cache are in two independent namespace.
 //Return int data value and any error raised.
 func (n *Node) GetCounter(key string) (int64, error) {
-	k := n.MustGetCacheKey(key)
+	k, err := n.GetCacheKey(key)
+	if err != nil {
+		return 0, err
+	}
 	return n.Cache.GetCounter(k)
 }
 
@@ -162,8 +340,17 @@ func (n *Node) Load(key string, v interface{}, TTL time.Duration, loader Loader)
 }
 
 //Flush Delete all data in cache.
+//Generation number of node will be bumped,so that all data in node are logically invalidated and expire by their ttl.
+//Generation number expires after CollectionTTLMultiple times of default ttl unless it is read.
+//Return any error raised.
 func (n *Node) Flush() error {
-	return ErrFeatureNotSupported
+	g, err := n.Cache.IncrCounter(n.Prefix+generationKeySuffix, 1, n.generationTTL())
+	if err != nil {
+		return err
+	}
+	atomic.StoreInt64(&n.refreshed, time.Now().UnixNano())
+	n.cacheGeneration(g)
+	return nil
 }
 
 //DefaultTTL return cache default ttl
@@ -190,6 +377,96 @@ func (n *Node) Expire(key string, ttl time.Duration) error {
 	return n.Cache.Expire(k, ttl)
 }
 
+//GetTTL get remaining ttl of given key.
+//Return ttl and any error raised.
+func (n *Node) GetTTL(key string) (time.Duration, error) {
+	k, err := n.GetCacheKey(key)
+	if err != nil {
+		return 0, err
+	}
+	return GetTTL(n.Cache, k)
+}
+
+//SetIfNotExists Set bytes data to cache by given key only if the key does not exist.
+//If ttl is DefaultTTL(0),use default ttl in config instead.
+//Return whether data is set and any error raised.
+func (n *Node) SetIfNotExists(key string, bytes []byte, ttl time.Duration) (bool, error) {
+	k, err := n.GetCacheKey(key)
+	if err != nil {
+		return false, err
+	}
+	return SetIfNotExists(n.Cache, k, bytes, ttl)
+}
+
+//GetWithVersion Get bytes data and value version from cache by given key.
+//Return data bytes,version and any error raised.
+func (n *Node) GetWithVersion(key string) ([]byte, string, error) {
+	k, err := n.GetCacheKey(key)
+	if err != nil {
+		return nil, "", err
+	}
+	return GetWithVersion(n.Cache, k)
+}
+
+//SetIfVersion Set bytes data to cache by given key only if current value version equals given version.
+//Empty version means key should not exist.
+//If ttl is DefaultTTL(0),use default ttl in config instead.
+//Return whether data is set and any error raised.
+func (n *Node) SetIfVersion(key string, bytes []byte, version string, ttl time.Duration) (bool, error) {
+	k, err := n.GetCacheKey(key)
+	if err != nil {
+		return false, err
+	}
+	return SetIfVersion(n.Cache, k, bytes, version, ttl)
+}
+
+//Keys list keys in node with given prefix from given cursor.
+//Keys of flushed generations are not included.
+//Empty cursor means start from beginning.
+//Return keys,next cursor and any error raised.
+//Empty next cursor means iteration finished.
+func (n *Node) Keys(prefix string, cursor string, count int) ([]string, string, error) {
+	base, err := n.generationPrefix()
+	if err != nil {
+		return nil, "", err
+	}
+	keys, next, err := Keys(n.Cache, base+prefix, cursor, count)
+	if err != nil {
+		return nil, "", err
+	}
+	return trimKeys(keys, base), next, nil
+}
+
+//Rename move value of old key to new key in node.
+//Return ErrNotFound if old key not exists.
+//Return any error raised.
+func (n *Node) Rename(oldKey string, newKey string) error {
+	oldk, err := n.GetCacheKey(oldKey)
+	if err != nil {
+		return err
+	}
+	newk, err := n.GetCacheKey(newKey)
+	if err != nil {
+		return err
+	}
+	return Rename(n.Cache, oldk, newk)
+}
+
+//Explain explain how Get of given key in node would be served.
+//Return explanation and any error raised.
+func (n *Node) Explain(key string) (*Explanation, error) {
+	k, err := n.GetCacheKey(key)
+	if err != nil {
+		return nil, err
+	}
+	e, err := Explain(n.Cache, k)
+	if err != nil {
+		return nil, err
+	}
+	e.Key = key
+	return e, nil
+}
+
 //ExpireCounter set cache counter  expire duration by given key and ttl
 func (n *Node) ExpireCounter(key string, ttl time.Duration) error {
 	k, err := n.GetCacheKey(key)
@@ -217,8 +494,9 @@ func (n *Node) Collection(prefix string) *Collection {
 }
 
 //Node get a cache node with given prefix
+//Sub node will be flushed with node.
 func (n *Node) Node(prefix string) *Node {
-	return NewNode(n.Cache, n.MustGetCacheKey(prefix))
+	return NewNode(n, prefix)
 }
 
 //Field retuan a cache field with given field name
@@ -229,7 +507,30 @@ func (n *Node) Field(fieldname string) *Field {
 	}
 }
 
+//MLoad Get data models from cache by given keys.Data not found will be loaded by one loader call and saved to cache.
+//Parameter v should be map of key and pointer to empty data model which data filled in.
+//Keys not found by loader will be deleted from v.
+//If ttl is DefaultTTL(0),use default ttl in config instead.
+//Return any error raised.
+func (n *Node) MLoad(keys []string, v map[string]interface{}, ttl time.Duration, loader MLoader) error {
+	return mloadFromCache(n, keys, v, ttl, loader)
+}
+
 //FinalKey get final key which passed to cache driver .
+//Key contains current generation number of node.
+//Key of generation 0 is returned if generation can not be read.
 func (n *Node) FinalKey(key string) string {
-	return n.Cache.FinalKey(n.Prefix + KeyPrefix + key)
+	prefix, err := n.generationPrefix()
+	if err != nil {
+		prefix = n.Prefix + KeyPrefix
+	}
+	return n.Cache.FinalKey(prefix + key)
+}
+
+func (n *Node) negativeCached(key string) bool {
+	k, err := n.GetCacheKey(key)
+	if err != nil {
+		return false
+	}
+	return negativeCached(n.Cache, k)
 }
@@ -3,6 +3,7 @@ package cache_test
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"testing"
 	"time"
 
@@ -76,7 +77,7 @@ func TestNodeMSetMGet(t *testing.T) {
 		}
 	}
 	_, err = c.GetBytesValue(unusedKey)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 
@@ -100,7 +101,7 @@ func TestNodeMSetMGet(t *testing.T) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(unusedKey)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	d, err = c.MGetBytesValue(mixedKeys...)
@@ -195,7 +196,11 @@ func TestNodeCloseAndFlush(t *testing.T) {
 		t.Errorf("Cache get result error %s", resultDataModel)
 	}
 	err = c.Flush()
-	if err != cache.ErrFeatureNotSupported {
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.Get(testKey, &resultDataModel)
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	ttl := c.DefaultTTL()
@@ -239,7 +244,7 @@ func TestNodeUpdate(t *testing.T) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKeyUpdate, &resultDataModel)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 
@@ -268,7 +273,7 @@ func TestNodeUpdate(t *testing.T) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(testKeyBytesUpdate)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 }
@@ -354,15 +359,15 @@ func TestNodeDefaulTTL(t *testing.T) {
 	}
 	time.Sleep(2000 * time.Millisecond)
 	err = c.Get(testKey, &resultDataModel)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(testKey2)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	resultDataInt, err = c.GetCounter(testKey3)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 }
@@ -529,15 +534,15 @@ func TestNodeTTL(t *testing.T) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKeyTTL1Second, &resultModelData)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(testKeyTTL1SecondBytes)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetCounter(testKeyTTL1SecondCounter)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKeyTTL3Second, &resultModelData)
@@ -604,27 +609,27 @@ func TestNodeTTL(t *testing.T) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKeyTTL1Second, &resultModelData)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(testKeyTTL1SecondBytes)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetCounter(testKeyTTL1SecondCounter)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKeyTTL3Second, &resultModelData)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetBytesValue(testKeyTTL3SecondBytes)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	_, err = c.GetCounter(testKeyTTL3SecondCounter)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 	err = c.Get(testKeyTTLRefresh, &resultModelData)
@@ -657,7 +662,7 @@ func TestNodeTTL(t *testing.T) {
 		t.Fatal(err)
 	}
 	_, err = c.GetCounter(testKeyTTLExpireCounter)
-	if err != cache.ErrNotFound {
+	if !errors.Is(err, cache.ErrNotFound) {
 		t.Fatal(err)
 	}
 }
@@ -717,3 +722,188 @@ func TestNodeMisc(t *testing.T) {
 		t.Fatal(sf)
 	}
 }
+
+func TestNodeKeys(t *testing.T) {
+	n := newNodeTestCache(3600)
+	for _, k := range []string{"a1", "a2", "a3", "b1"} {
+		err := n.Set(k, k, cache.DefaultTTL)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := n.IncrCounter("a4", 1, cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = n.Cache.Set("a5", "a5", cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, cursor, err := n.Keys("a", "", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || keys[0] != "a1" || keys[1] != "a2" || cursor == "" {
+		t.Fatal(keys, cursor)
+	}
+	keys, cursor, err = n.Keys("a", cursor, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0] != "a3" || cursor != "" {
+		t.Fatal(keys, cursor)
+	}
+	keys, _, err = n.Keys("", "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 4 {
+		t.Fatal(keys)
+	}
+}
+
+func TestNodeFlush(t *testing.T) {
+	n := newNodeTestCache(3600)
+	sub := n.Node("sub")
+	other := cache.NewNode(n.Cache, "other")
+	for _, c := range []cache.Cacheable{n, sub, other} {
+		err := c.SetBytesValue("key", []byte("value"), cache.DefaultTTL)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	g, err := n.Generation()
+	if err != nil || g != 0 {
+		t.Fatal(g, err)
+	}
+	k, err := n.GetCacheKey("key")
+	if err != nil || k != n.Prefix+cache.KeyPrefix+"key" {
+		t.Fatal(k, err)
+	}
+	err = n.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err = n.Generation()
+	if err != nil || g != 1 {
+		t.Fatal(g, err)
+	}
+	for _, c := range []cache.Cacheable{n, sub} {
+		_, err = c.GetBytesValue("key")
+		if !errors.Is(err, cache.ErrNotFound) {
+			t.Fatal(err)
+		}
+		data, err := c.MGetBytesValue("key")
+		if err != nil || len(data) != 0 {
+			t.Fatal(data, err)
+		}
+	}
+	bs, err := other.GetBytesValue("key")
+	if err != nil || string(bs) != "value" {
+		t.Fatal(string(bs), err)
+	}
+	err = n.MSetBytesValue(map[string][]byte{"key": []byte("newvalue")}, cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := n.MGetBytesValue("key")
+	if err != nil || string(data["key"]) != "newvalue" {
+		t.Fatal(data, err)
+	}
+	keys, _, err := n.Keys("", "", 0)
+	if err != nil || len(keys) != 1 || keys[0] != "key" {
+		t.Fatal(keys, err)
+	}
+}
+
+func TestNodeGenerationExpiry(t *testing.T) {
+	multiple := cache.CollectionTTLMultiple
+	cache.CollectionTTLMultiple = 2
+	duration := cache.NodeGenerationCacheDuration
+	cache.NodeGenerationCacheDuration = 100 * time.Millisecond
+	defer func() {
+		cache.CollectionTTLMultiple = multiple
+		cache.NodeGenerationCacheDuration = duration
+	}()
+	n := newNodeTestCache(1)
+	err := n.SetBytesValue("key", []byte("value"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = n.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 6; i++ {
+		time.Sleep(500 * time.Millisecond)
+		g, err := n.Generation()
+		if err != nil || g != 1 {
+			t.Fatal(i, g, err)
+		}
+		_, err = n.GetBytesValue("key")
+		if !errors.Is(err, cache.ErrNotFound) {
+			t.Fatal(i, err)
+		}
+	}
+	time.Sleep(2500 * time.Millisecond)
+	g, err := n.Generation()
+	if err != nil || g != 0 {
+		t.Fatal(g, err)
+	}
+	bs, err := n.GetBytesValue("key")
+	if err != nil || string(bs) != "value" {
+		t.Fatal(string(bs), err)
+	}
+}
+
+func TestNodeGenerationCache(t *testing.T) {
+	duration := cache.NodeGenerationCacheDuration
+	cache.NodeGenerationCacheDuration = 200 * time.Millisecond
+	defer func() {
+		cache.NodeGenerationCacheDuration = duration
+	}()
+	n := newNodeTestCache(3600)
+	other := cache.NewNode(n.Cache, n.Prefix)
+	err := n.SetBytesValue("key", []byte("value"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := other.GetBytesValue("key")
+	if err != nil || string(bs) != "value" {
+		t.Fatal(string(bs), err)
+	}
+	err = n.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = n.GetBytesValue("key")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	bs, err = other.GetBytesValue("key")
+	if err != nil || string(bs) != "value" {
+		t.Fatal(string(bs), err)
+	}
+	time.Sleep(300 * time.Millisecond)
+	_, err = other.GetBytesValue("key")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+}
+
+func TestNodeFinalKey(t *testing.T) {
+	n := newNodeTestCache(3600)
+	for i := 0; i < 2; i++ {
+		key, err := n.GetCacheKey("key")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n.FinalKey("key") != n.Cache.FinalKey(key) {
+			t.Fatal(i, n.FinalKey("key"), key)
+		}
+		err = n.Flush()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -19,15 +19,79 @@ type OptionConfig struct {
 	Driver    string
 	TTL       int64
 	Marshaler string
-	Config    func(v interface{}) error `config:", lazyload"`
+	//NegativeTTL ttl in second of "not found" result cached by loader.
+	NegativeTTL int64
+	//LoadDeadlineMargin time in milliseconds reserved for writing loaded value to cache when loading with context deadline.
+	LoadDeadlineMargin int64
+	//Compression registered codec name used to compress values,like "gzip".
+	//Values will not be compressed if empty.
+	Compression string
+	//CompressionMinSize min size in bytes of value to compress.
+	//DefaultCompressionMinSize will be used if not positive.
+	CompressionMinSize int
+	//Encryption encryption config used to encrypt values.
+	//Values will not be encrypted if nil.
+	Encryption *EncryptionConfig
+	//MaxEntrySize max size in bytes of value stored in driver.
+	//Value size will not be limited if not positive.
+	MaxEntrySize int
+	//KeyHashThreshold max size in bytes of final key passed to driver.
+	//Larger keys will be hashed by sha256.
+	//Keys will not be hashed if not positive.
+	KeyHashThreshold int
+	//MinTTL min ttl in second of values and counters.
+	//Smaller ttl will be clamped.
+	//Ttl will not be clamped if not positive.
+	MinTTL int64
+	//MaxTTL max ttl in second of values and counters.
+	//Larger ttl will be clamped.
+	//Ttl will not be clamped if not positive.
+	MaxTTL int64
+	//OperationTimeout max duration in milliseconds of every driver call.
+	//Calls are not limited if not positive.
+	OperationTimeout int64
+	//RetryCount max times to retry driver calls failed with transient error.
+	RetryCount int
+	//RetryBackoff duration in milliseconds to wait before first retry,doubled after every retry.
+	RetryBackoff int64
+	Config       func(v interface{}) error `config:", lazyload"`
 }
 
+//DefaultCompressionMinSize default min size in bytes of value to compress.
+var DefaultCompressionMinSize = 1024
+
 //ApplyTo apply option to given cache.
 //Return any error if raised.
 func (o *OptionConfig) ApplyTo(cache *Cache) error {
-	if o.TTL < 0 {
+	if o.TTL < 0 || o.NegativeTTL < 0 || o.LoadDeadlineMargin < 0 {
+		return ErrTTLNotAvaliable
+	}
+	if o.MinTTL > 0 && o.MaxTTL > 0 && o.MinTTL > o.MaxTTL {
 		return ErrTTLNotAvaliable
 	}
+	if o.OperationTimeout < 0 || o.RetryCount < 0 || o.RetryBackoff < 0 {
+		return ErrInvalidRetryPolicy
+	}
+	var compressor *Compressor
+	if o.Compression != "" {
+		minsize := o.CompressionMinSize
+		if minsize <= 0 {
+			minsize = DefaultCompressionMinSize
+		}
+		c, err := NewCompressor(o.Compression, minsize)
+		if err != nil {
+			return err
+		}
+		compressor = c
+	}
+	var encryptor *Encryptor
+	if o.Encryption != nil {
+		e, err := o.Encryption.CreateEncryptor()
+		if err != nil {
+			return err
+		}
+		encryptor = e
+	}
 	driver, err := NewDriver(o.Driver, o.Config)
 	if err != nil {
 		return err
@@ -43,7 +107,19 @@ func (o *OptionConfig) ApplyTo(cache *Cache) error {
 	}
 	u := NewUtil()
 	u.Marshaler = marshaler
+	u.MarshalerName = mname
+	u.NegativeTTL = time.Duration(o.NegativeTTL * int64(time.Second))
+	u.LoadDeadlineMargin = time.Duration(o.LoadDeadlineMargin * int64(time.Millisecond))
 	driver.SetUtil(u)
 	cache.TTL = time.Duration(o.TTL * int64(time.Second))
+	cache.Compressor = compressor
+	cache.Encryptor = encryptor
+	cache.MaxEntrySize = o.MaxEntrySize
+	cache.KeyHashThreshold = o.KeyHashThreshold
+	cache.MinTTL = time.Duration(o.MinTTL * int64(time.Second))
+	cache.MaxTTL = time.Duration(o.MaxTTL * int64(time.Second))
+	cache.OperationTimeout = time.Duration(o.OperationTimeout * int64(time.Millisecond))
+	cache.RetryCount = o.RetryCount
+	cache.RetryBackoff = time.Duration(o.RetryBackoff * int64(time.Millisecond))
 	return nil
 }
@@ -1,5 +1,9 @@
 package cache
 
+import "time"
+
+//Proxy cacheable which forwards all operations to wrapped cacheable.
+//Optional operations return ErrFeatureNotSupported if wrapped cacheable does not support them.
 type Proxy struct {
 	Cacheable
 }
@@ -13,3 +17,72 @@ func NewProxy(c Cacheable) *Proxy {
 func ProxyWithPrefix(c Cacheable, prefix string) *Proxy {
 	return NewProxy(NewCollection(c, prefix, DefaultTTL))
 }
+
+//MLoad Get data models from wrapped cacheable by given keys.Data not found will be loaded by one loader call and saved to cache.
+//Return any error raised.
+func (p *Proxy) MLoad(keys []string, v map[string]interface{}, ttl time.Duration, loader MLoader) error {
+	return MLoad(p.Cacheable, keys, v, ttl, loader)
+}
+
+//GetTTL get remaining ttl of given key from wrapped cacheable.
+//Return ttl and any error raised.
+func (p *Proxy) GetTTL(key string) (time.Duration, error) {
+	return GetTTL(p.Cacheable, key)
+}
+
+//SetIfNotExists Set bytes data to wrapped cacheable by given key only if the key does not exist.
+//Return whether data is set and any error raised.
+func (p *Proxy) SetIfNotExists(key string, bytes []byte, ttl time.Duration) (bool, error) {
+	return SetIfNotExists(p.Cacheable, key, bytes, ttl)
+}
+
+//GetWithVersion Get bytes data and value version from wrapped cacheable by given key.
+//Return data bytes,version and any error raised.
+func (p *Proxy) GetWithVersion(key string) ([]byte, string, error) {
+	return GetWithVersion(p.Cacheable, key)
+}
+
+//SetIfVersion Set bytes data to wrapped cacheable by given key only if current value version equals given version.
+//Return whether data is set and any error raised.
+func (p *Proxy) SetIfVersion(key string, bytes []byte, version string, ttl time.Duration) (bool, error) {
+	return SetIfVersion(p.Cacheable, key, bytes, version, ttl)
+}
+
+//IncrFloatCounter Increase float val in wrapped cacheable by given key.
+//Return float data value and any error raised.
+func (p *Proxy) IncrFloatCounter(key string, increment float64, ttl time.Duration) (float64, error) {
+	return IncrFloatCounter(p.Cacheable, key, increment, ttl)
+}
+
+//GetFloatCounter Get float val from wrapped cacheable by given key.
+//Return float data value and any error raised.
+func (p *Proxy) GetFloatCounter(key string) (float64, error) {
+	return GetFloatCounter(p.Cacheable, key)
+}
+
+//Keys list keys with given prefix from given cursor in wrapped cacheable.
+//Return keys,next cursor and any error raised.
+func (p *Proxy) Keys(prefix string, cursor string, count int) ([]string, string, error) {
+	return Keys(p.Cacheable, prefix, cursor, count)
+}
+
+//Rename move value of old key to new key in wrapped cacheable.
+//Return any error raised.
+func (p *Proxy) Rename(oldKey string, newKey string) error {
+	return Rename(p.Cacheable, oldKey, newKey)
+}
+
+//Explain explain how Get of given key would be served by wrapped cacheable.
+//Return explanation and any error raised.
+func (p *Proxy) Explain(key string) (*Explanation, error) {
+	return Explain(p.Cacheable, key)
+}
+
+//Capabilities return capabilities supported by wrapped cacheable.
+func (p *Proxy) Capabilities() Capabilities {
+	return CacheableCapabilities(p.Cacheable)
+}
+
+func (p *Proxy) negativeCached(key string) bool {
+	return negativeCached(p.Cacheable, key)
+}
@@ -0,0 +1,120 @@
+//Package ratelimit provides fixed-window and sliding-window rate limiter built on cache counters.
+package ratelimit
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+//ErrInvalidLimit error raised when limit or window is not positive.
+var ErrInvalidLimit = errors.New("ratelimit: invalid limit")
+
+//Limiter rate limiter which stores request count in cache counters.
+//Limiter shares limits between service instances if cache is shared.
+type Limiter struct {
+	//Cache cache which stores counters.
+	Cache cache.Cacheable
+	//Sliding whether use sliding window.
+	//Sliding window weights previous window count by its overlap with the window ending now,
+	//which avoids burst at window boundary.
+	//Fixed window is used if false.
+	Sliding bool
+	now     func() time.Time
+}
+
+//New create fixed-window rate limiter with given cache.
+func New(c cache.Cacheable) *Limiter {
+	return &Limiter{
+		Cache: c,
+		now:   time.Now,
+	}
+}
+
+//NewSliding create sliding-window rate limiter with given cache.
+func NewSliding(c cache.Cacheable) *Limiter {
+	l := New(c)
+	l.Sliding = true
+	return l
+}
+
+func buildKey(key string, index int64) string {
+	return key + cache.KeyPrefix + strconv.FormatInt(index, 10)
+}
+
+func counterTTL(window time.Duration) time.Duration {
+	//Counter ttl is rounded up to seconds,as most drivers store ttl in seconds.
+	return (window/time.Second + 1) * time.Second
+}
+
+//Allow count one request of given key and check if request is allowed
+//by given limit in given window.
+//Return whether request is allowed,remaining request count in current window,
+//time when current window resets and any error if raised.
+func (l *Limiter) Allow(key string, limit int, window time.Duration) (bool, int, time.Time, error) {
+	if limit <= 0 || window <= 0 {
+		return false, 0, time.Time{}, ErrInvalidLimit
+	}
+	if l.Sliding {
+		return l.allowSliding(key, limit, window)
+	}
+	return l.allowFixed(key, limit, window)
+}
+
+func (l *Limiter) allowFixed(key string, limit int, window time.Duration) (bool, int, time.Time, error) {
+	now := l.now().UnixNano()
+	index := now / int64(window)
+	reset := time.Unix(0, (index+1)*int64(window))
+	count, err := l.Cache.IncrCounter(buildKey(key, index), 1, counterTTL(window))
+	if err != nil {
+		return false, 0, reset, err
+	}
+	if count > int64(limit) {
+		return false, 0, reset, nil
+	}
+	return true, limit - int(count), reset, nil
+}
+
+func (l *Limiter) allowSliding(key string, limit int, window time.Duration) (bool, int, time.Time, error) {
+	now := l.now().UnixNano()
+	index := now / int64(window)
+	reset := time.Unix(0, (index+1)*int64(window))
+	previous, err := l.Cache.GetCounter(buildKey(key, index-1))
+	if errors.Is(err, cache.ErrNotFound) {
+		previous = 0
+	} else if err != nil {
+		return false, 0, reset, err
+	}
+	currentKey := buildKey(key, index)
+	current, err := l.Cache.IncrCounter(currentKey, 1, counterTTL(2*window))
+	if err != nil {
+		return false, 0, reset, err
+	}
+	elapsed := float64(now-index*int64(window)) / float64(window)
+	estimated := int64(float64(previous)*(1-elapsed)) + current
+	if estimated > int64(limit) {
+		//Rejected requests are not counted,so that blocked clients recover when window slides.
+		_, err = l.Cache.IncrCounter(currentKey, -1, counterTTL(2*window))
+		if err != nil {
+			return false, 0, reset, err
+		}
+		return false, 0, reset, nil
+	}
+	return true, limit - int(estimated), reset, nil
+}
+
+//Reset delete counters of given key in current and previous window.
+//Return any error if raised.
+func (l *Limiter) Reset(key string, window time.Duration) error {
+	if window <= 0 {
+		return ErrInvalidLimit
+	}
+	index := l.now().UnixNano() / int64(window)
+	err := l.Cache.DelCounter(buildKey(key, index))
+	if err != nil {
+		return err
+	}
+	return l.Cache.DelCounter(buildKey(key, index-1))
+}
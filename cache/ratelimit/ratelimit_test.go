@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+	_ "github.com/herb-go/deprecated/cache/drivers/syncmapcache"
+)
+
+func newTestCache() *cache.Cache {
+	c := cache.New()
+	oc := cache.NewOptionConfig()
+	oc.Driver = "syncmapcache"
+	oc.TTL = int64(time.Hour)
+	oc.Config = nil
+	oc.Marshaler = "json"
+	err := c.Init(oc)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func TestFixed(t *testing.T) {
+	l := New(newTestCache())
+	now := time.Unix(1000, 0)
+	l.now = func() time.Time { return now }
+	for i := 0; i < 3; i++ {
+		ok, remaining, reset, err := l.Allow("test", 3, time.Minute)
+		if err != nil || !ok || remaining != 2-i || !reset.Equal(time.Unix(1020, 0)) {
+			t.Fatal(ok, remaining, reset, err)
+		}
+	}
+	ok, remaining, _, err := l.Allow("test", 3, time.Minute)
+	if err != nil || ok || remaining != 0 {
+		t.Fatal(ok, remaining, err)
+	}
+	ok, _, _, err = l.Allow("test2", 3, time.Minute)
+	if err != nil || !ok {
+		t.Fatal(ok, err)
+	}
+	now = time.Unix(1020, 0)
+	ok, remaining, _, err = l.Allow("test", 3, time.Minute)
+	if err != nil || !ok || remaining != 2 {
+		t.Fatal(ok, remaining, err)
+	}
+	err = l.Reset("test", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, remaining, _, err = l.Allow("test", 3, time.Minute)
+	if err != nil || !ok || remaining != 2 {
+		t.Fatal(ok, remaining, err)
+	}
+	_, _, _, err = l.Allow("test", 0, time.Minute)
+	if err != ErrInvalidLimit {
+		t.Fatal(err)
+	}
+}
+
+func TestSliding(t *testing.T) {
+	l := NewSliding(newTestCache())
+	now := time.Unix(1020, 0)
+	l.now = func() time.Time { return now }
+	for i := 0; i < 4; i++ {
+		ok, _, _, err := l.Allow("test", 4, time.Minute)
+		if err != nil || !ok {
+			t.Fatal(ok, err)
+		}
+	}
+	ok, _, _, err := l.Allow("test", 4, time.Minute)
+	if err != nil || ok {
+		t.Fatal(ok, err)
+	}
+	//Half of previous window overlaps,so previous window counts as 2.
+	now = time.Unix(1110, 0)
+	for i := 0; i < 2; i++ {
+		ok, remaining, _, err := l.Allow("test", 4, time.Minute)
+		if err != nil || !ok || remaining != 1-i {
+			t.Fatal(ok, remaining, err)
+		}
+	}
+	ok, _, _, err = l.Allow("test", 4, time.Minute)
+	if err != nil || ok {
+		t.Fatal(ok, err)
+	}
+	now = time.Unix(1140, 0)
+	ok, remaining, _, err := l.Allow("test", 4, time.Minute)
+	if err != nil || !ok || remaining != 1 {
+		t.Fatal(ok, remaining, err)
+	}
+}
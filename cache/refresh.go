@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type refreshCall struct {
+	done chan struct{}
+	err  error
+}
+
+//RefreshRegistry registry of in-flight background refreshes.
+//Refreshes of same key are deduplicated,so only one refresh runs at a time for every key.
+type RefreshRegistry struct {
+	locker       sync.Mutex
+	calls        map[string]*refreshCall
+	started      int64
+	deduplicated int64
+}
+
+//NewRefreshRegistry create new refresh registry.
+func NewRefreshRegistry() *RefreshRegistry {
+	return &RefreshRegistry{
+		calls: map[string]*refreshCall{},
+	}
+}
+
+//Refresh run fn in background for given key unless refresh of same key is in flight.
+//Return whether new refresh is started.
+func (r *RefreshRegistry) Refresh(key string, fn func() error) bool {
+	r.locker.Lock()
+	if _, ok := r.calls[key]; ok {
+		r.locker.Unlock()
+		atomic.AddInt64(&r.deduplicated, 1)
+		return false
+	}
+	call := &refreshCall{done: make(chan struct{})}
+	r.calls[key] = call
+	r.locker.Unlock()
+	atomic.AddInt64(&r.started, 1)
+	go func() {
+		defer func() {
+			r.locker.Lock()
+			delete(r.calls, key)
+			r.locker.Unlock()
+			close(call.done)
+		}()
+		call.err = fn()
+	}()
+	return true
+}
+
+//Wait wait in-flight refresh of given key finished.
+//Return error returned by refresh,or nil if no refresh of key is in flight.
+func (r *RefreshRegistry) Wait(key string) error {
+	r.locker.Lock()
+	call, ok := r.calls[key]
+	r.locker.Unlock()
+	if !ok {
+		return nil
+	}
+	<-call.done
+	return call.err
+}
+
+//InFlight return count of refreshes in flight.
+func (r *RefreshRegistry) InFlight() int {
+	r.locker.Lock()
+	defer r.locker.Unlock()
+	return len(r.calls)
+}
+
+//InFlightKeys return keys of refreshes in flight.
+func (r *RefreshRegistry) InFlightKeys() []string {
+	r.locker.Lock()
+	defer r.locker.Unlock()
+	keys := make([]string, 0, len(r.calls))
+	for k := range r.calls {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+//Started return count of refreshes started.
+func (r *RefreshRegistry) Started() int64 {
+	return atomic.LoadInt64(&r.started)
+}
+
+//Deduplicated return count of refresh requests skipped because refresh of same key was in flight.
+func (r *RefreshRegistry) Deduplicated() int64 {
+	return atomic.LoadInt64(&r.deduplicated)
+}
+
+//RefreshInBackground call loader in background and save loaded value to given cache by key.
+//Refreshes are deduplicated by final key in util refresh registry.
+//If ttl is DefaultTTL(0),use default ttl in config instead.
+//Return whether new refresh is started.
+func RefreshInBackground(c Cacheable, key string, ttl time.Duration, loader Loader) bool {
+	return c.Util().Refreshes.Refresh(c.FinalKey(key), func() error {
+		v, err := loader(key)
+		if err != nil {
+			return err
+		}
+		return c.Set(key, v, ttl)
+	})
+}
+
+//LoadWithRefresh Get data model from given cache by key.If data not found,call loader to get current data value and save to cache.
+//If data found but remaining ttl is less than refreshBefore,cached data is returned
+//and data is refreshed in background by RefreshInBackground.
+//Background refresh is skipped if cache cannot inspect ttl.
+//Return any error raised.
+func LoadWithRefresh(c Cacheable, key string, v interface{}, ttl time.Duration, refreshBefore time.Duration, loader Loader) error {
+	err := c.Get(key, v)
+	if err != nil {
+		return c.Load(key, v, ttl, loader)
+	}
+	remaining, err := GetTTL(c, key)
+	if err == nil && remaining < refreshBefore {
+		RefreshInBackground(c, key, ttl, loader)
+	}
+	return nil
+}
+
+//RefreshInBackground call loader in background and save loaded value to cache by given key.
+//Return whether new refresh is started.
+func (c *Cache) RefreshInBackground(key string, ttl time.Duration, loader Loader) bool {
+	return RefreshInBackground(c, key, ttl, loader)
+}
+
+//LoadWithRefresh Get data model from cache by given key.If data not found,call loader to get current data value and save to cache.
+//If remaining ttl is less than refreshBefore,cached data is returned and refreshed in background.
+//Return any error raised.
+func (c *Cache) LoadWithRefresh(key string, v interface{}, ttl time.Duration, refreshBefore time.Duration, loader Loader) error {
+	return LoadWithRefresh(c, key, v, ttl, refreshBefore, loader)
+}
@@ -0,0 +1,90 @@
+package cache_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+func TestRefreshRegistry(t *testing.T) {
+	r := cache.NewRefreshRegistry()
+	release := make(chan struct{})
+	errRefresh := errors.New("refresh error")
+	if !r.Refresh("test", func() error {
+		<-release
+		return errRefresh
+	}) {
+		t.Fatal()
+	}
+	if r.Refresh("test", func() error { return nil }) {
+		t.Fatal()
+	}
+	if r.InFlight() != 1 || len(r.InFlightKeys()) != 1 || r.InFlightKeys()[0] != "test" {
+		t.Fatal(r.InFlightKeys())
+	}
+	close(release)
+	if err := r.Wait("test"); err != errRefresh {
+		t.Fatal(err)
+	}
+	if r.InFlight() != 0 || r.Started() != 1 || r.Deduplicated() != 1 {
+		t.Fatal(r.InFlight(), r.Started(), r.Deduplicated())
+	}
+	if err := r.Wait("notexist"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadWithRefresh(t *testing.T) {
+	c := newTestCache(3600)
+	var calls int64
+	release := make(chan struct{})
+	loader := func(key string) (interface{}, error) {
+		if atomic.AddInt64(&calls, 1) > 1 {
+			<-release
+		}
+		return "value", nil
+	}
+	var result string
+	err := c.LoadWithRefresh("test", &result, time.Hour, time.Minute, loader)
+	if err != nil || result != "value" || atomic.LoadInt64(&calls) != 1 {
+		t.Fatal(result, err, calls)
+	}
+	err = c.LoadWithRefresh("test", &result, time.Hour, time.Minute, loader)
+	if err != nil || atomic.LoadInt64(&calls) != 1 {
+		t.Fatal(err, calls)
+	}
+	err = c.Expire("test", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		result = ""
+		err = c.LoadWithRefresh("test", &result, time.Hour, time.Minute, loader)
+		if err != nil || result != "value" {
+			t.Fatal(result, err)
+		}
+	}
+	refreshes := c.Util().Refreshes
+	if refreshes.InFlight() != 1 || refreshes.Deduplicated() != 9 {
+		t.Fatal(refreshes.InFlight(), refreshes.Deduplicated())
+	}
+	close(release)
+	err = refreshes.Wait(c.FinalKey("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt64(&calls) != 2 {
+		t.Fatal(calls)
+	}
+	ttl, err := c.GetTTL("test")
+	if err != nil || ttl < time.Minute {
+		t.Fatal(ttl, err)
+	}
+	n := cache.NewNode(c, "node")
+	if n.Util().Refreshes != refreshes {
+		t.Fatal(n.Util().Refreshes)
+	}
+}
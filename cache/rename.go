@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"errors"
+	"time"
+)
+
+//Rename move value of old key to new key.
+//Existing value of new key is overwritten,and remaining ttl of old key is kept.
+//Driver native implement will be used if driver supports CapabilityRename,
+//otherwise value is copied to new key and old key is deleted.
+//Copied value expires with remaining ttl if driver supports CapabilityTTL,or default ttl otherwise.
+//Counters are not renamed.
+//Return ErrNotFound if old key not exists.
+//Return any error raised.
+func (c *Cache) Rename(oldKey string, newKey string) error {
+	c.reloadLocker.RLock()
+	defer c.reloadLocker.RUnlock()
+	if oldKey == "" || newKey == "" {
+		return ErrKeyUnavailable
+	}
+	if oldKey == newKey {
+		return nil
+	}
+	oldk := c.getKey(oldKey)
+	newk := c.getKey(newKey)
+	var size int
+	var err error
+	d, ok := c.Driver.(Renamer)
+	if ok && DriverCapabilities(c.Driver).Has(CapabilityRename) {
+		err = c.exec(func(Driver) error {
+			return d.Rename(oldk, newk)
+		})
+	} else {
+		size, err = c.copyAndDelete(oldk, newk)
+	}
+	err = WrapKeyError(LayerDriver, oldKey, err)
+	c.hooks.emit(OpDel, oldKey, 0, err)
+	c.hooks.emit(OpSet, newKey, size, err)
+	return err
+}
+
+//copyAndDelete copy raw value of old driver key to new driver key,then delete old key.
+//Old key is locked by util locker,which is only atomic in current process.
+//Return size of value copied and any error raised.
+func (c *Cache) copyAndDelete(oldk string, newk string) (int, error) {
+	locker, _ := c.Driver.Util().Locker(oldk)
+	locker.Lock()
+	defer locker.Unlock()
+	data, err := c.Driver.GetBytesValue(oldk)
+	if err != nil {
+		return 0, err
+	}
+	ttl := c.TTL
+	d, ok := c.Driver.(TTLGetter)
+	if ok && DriverCapabilities(c.Driver).Has(CapabilityTTL) {
+		var remaining time.Duration
+		remaining, err = d.GetTTL(oldk)
+		if errors.Is(err, ErrNotFound) {
+			return 0, err
+		}
+		if err == nil {
+			ttl = remaining
+		}
+	}
+	err = c.Driver.SetBytesValue(newk, data, ttl)
+	if err != nil {
+		return 0, err
+	}
+	return len(data), c.Driver.Del(oldk)
+}
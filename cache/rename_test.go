@@ -0,0 +1,68 @@
+package cache_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+func TestRename(t *testing.T) {
+	c := newTestCache(3600)
+	err := c.Rename("", "new")
+	if !errors.Is(err, cache.ErrKeyUnavailable) {
+		t.Fatal(err)
+	}
+	testRename(t, c)
+	testRename(t, cache.NewNode(c, "node"))
+	testRename(t, cache.NewCollection(c, "collection", 0))
+	emulated := newTestCache(3600)
+	emulated.Driver = &testNoTTLDriver{emulated.Driver}
+	testRename(t, emulated)
+	if !errors.Is(cache.Rename(cache.Dummy(), "old", "new"), cache.ErrNotFound) {
+		t.Fatal()
+	}
+}
+
+func testRename(t *testing.T, c cache.Cacheable) {
+	err := cache.Rename(c, "old", "new")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	err = c.Set("old", "value", 10*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.Set("new", "overwritten", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cache.Rename(c, "old", "new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result string
+	err = c.Get("old", &result)
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	err = c.Get("new", &result)
+	if err != nil || result != "value" {
+		t.Fatal(result, err)
+	}
+	if cache.CacheableCapabilities(c).Has(cache.CapabilityTTL) {
+		ttl, err := cache.GetTTL(c, "new")
+		if err != nil || ttl <= 9*time.Minute || ttl > 10*time.Minute {
+			t.Fatal(ttl, err)
+		}
+	}
+	err = cache.Rename(c, "new", "new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.Get("new", &result)
+	if err != nil || result != "value" {
+		t.Fatal(result, err)
+	}
+}
@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+//ErrOperationTimeout error raised when driver operation does not finish in operation timeout.
+var ErrOperationTimeout = errors.New("cache: operation timeout")
+
+//ErrInvalidRetryPolicy error raised when operation timeout or retry option is negative.
+var ErrInvalidRetryPolicy = errors.New("cache: invalid retry policy")
+
+var unretryableErrors = []error{
+	ErrNotFound,
+	ErrNotCacheable,
+	ErrEntryTooLarge,
+	ErrKeyTooLarge,
+	ErrKeyUnavailable,
+	ErrFeatureNotSupported,
+	ErrTTLNotAvaliable,
+	ErrInvalidCounterValue,
+	ErrOperationTimeout,
+}
+
+//IsRetryableError whether driver operation failed with given error should be retried.
+//Cache errors like ErrNotFound are results rather than failures,and are not retryable.
+//ErrOperationTimeout is not retryable,as timed out operation may be still running,
+//and retrying would pile up stuck operations on slow backend.
+func IsRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+	for _, v := range unretryableErrors {
+		if errors.Is(err, v) {
+			return false
+		}
+	}
+	return true
+}
+
+//callOnce call driver operation with operation timeout.
+//Operation keeps running in background after timeout,its result is dropped.
+//Operations running in background are counted,so that driver is closed after they finish.
+func (c *Cache) callOnce(d Driver, op func(d Driver) (interface{}, error)) (interface{}, error) {
+	if c.OperationTimeout <= 0 {
+		return op(d)
+	}
+	type result struct {
+		value interface{}
+		err   error
+	}
+	ch := make(chan result, 1)
+	calls := c.calls
+	if calls != nil {
+		calls.Add(1)
+	}
+	go func() {
+		if calls != nil {
+			defer calls.Done()
+		}
+		v, err := op(d)
+		ch <- result{value: v, err: err}
+	}()
+	timer := time.NewTimer(c.OperationTimeout)
+	defer timer.Stop()
+	select {
+	case r := <-ch:
+		return r.value, r.err
+	case <-timer.C:
+		return nil, ErrOperationTimeout
+	}
+}
+
+//call call driver operation with operation timeout and retry policy.
+//Operation is retried at most RetryCount times if failed with retryable error.
+//Reload locker should be held by caller.
+func (c *Cache) call(op func(d Driver) (interface{}, error)) (interface{}, error) {
+	d := c.Driver
+	backoff := c.RetryBackoff
+	for i := 0; ; i++ {
+		v, err := c.callOnce(d, op)
+		if i >= c.RetryCount || !IsRetryableError(err) {
+			return v, err
+		}
+		if backoff > 0 {
+			time.Sleep(backoff)
+			backoff = backoff * 2
+		}
+	}
+}
+
+//exec call driver operation which returns only error with operation timeout and retry policy.
+//Reload locker should be held by caller.
+func (c *Cache) exec(op func(d Driver) error) error {
+	_, err := c.call(func(d Driver) (interface{}, error) {
+		return nil, op(d)
+	})
+	return err
+}
+
+//callNoRetry call driver operation which is not idempotent with operation timeout only.
+//Reload locker should be held by caller.
+func (c *Cache) callNoRetry(op func(d Driver) (interface{}, error)) (interface{}, error) {
+	return c.callOnce(c.Driver, op)
+}
+
+//waitCalls wait until all driver operations running in background of given wait group finish.
+func waitCalls(calls *sync.WaitGroup) {
+	if calls != nil {
+		calls.Wait()
+	}
+}
@@ -0,0 +1,123 @@
+package cache_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+	"github.com/herb-go/deprecated/cache/drivers/syncmapcache"
+)
+
+var errFlaky = errors.New("flaky driver error")
+
+type flakyDriver struct {
+	*syncmapcache.Cache
+	failures int64
+	calls    int64
+	finished int64
+	delay    time.Duration
+}
+
+func (d *flakyDriver) fail() error {
+	atomic.AddInt64(&d.calls, 1)
+	time.Sleep(d.delay)
+	defer atomic.AddInt64(&d.finished, 1)
+	if atomic.AddInt64(&d.failures, -1) >= 0 {
+		return errFlaky
+	}
+	return nil
+}
+
+func (d *flakyDriver) GetBytesValue(key string) ([]byte, error) {
+	if err := d.fail(); err != nil {
+		return nil, err
+	}
+	return d.Cache.GetBytesValue(key)
+}
+
+func (d *flakyDriver) GetCounter(key string) (int64, error) {
+	if err := d.fail(); err != nil {
+		return 0, err
+	}
+	return d.Cache.GetCounter(key)
+}
+
+func (d *flakyDriver) IncrCounter(key string, increment int64, ttl time.Duration) (int64, error) {
+	if err := d.fail(); err != nil {
+		return 0, err
+	}
+	return d.Cache.IncrCounter(key, increment, ttl)
+}
+
+func TestRetry(t *testing.T) {
+	c := newTestCache(3600)
+	d := &flakyDriver{Cache: c.Driver.(*syncmapcache.Cache)}
+	c.Driver = d
+	err := c.SetBytesValue("test", []byte("test"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.failures = 2
+	_, err = c.GetBytesValue("test")
+	if !errors.Is(err, errFlaky) || d.calls != 1 {
+		t.Fatal(err, d.calls)
+	}
+	c.RetryCount = 2
+	c.RetryBackoff = time.Millisecond
+	d.failures, d.calls = 2, 0
+	bs, err := c.GetBytesValue("test")
+	if err != nil || string(bs) != "test" || d.calls != 3 {
+		t.Fatal(string(bs), err, d.calls)
+	}
+	d.failures, d.calls = 0, 0
+	_, err = c.GetBytesValue("notexist")
+	if !errors.Is(err, cache.ErrNotFound) || d.calls != 1 {
+		t.Fatal(err, d.calls)
+	}
+	d.failures, d.calls = 1, 0
+	_, err = c.IncrCounter("counter", 1, 0)
+	if !errors.Is(err, errFlaky) || d.calls != 1 {
+		t.Fatal(err, d.calls)
+	}
+	if cache.IsRetryableError(cache.ErrNotFound) || cache.IsRetryableError(cache.ErrOperationTimeout) || !cache.IsRetryableError(errFlaky) {
+		t.Fatal()
+	}
+}
+
+func TestOperationTimeout(t *testing.T) {
+	c := newTestCache(3600)
+	d := &flakyDriver{Cache: c.Driver.(*syncmapcache.Cache), delay: 50 * time.Millisecond}
+	c.Driver = d
+	c.OperationTimeout = 5 * time.Millisecond
+	c.RetryCount = 3
+	_, err := c.GetBytesValue("test")
+	if !errors.Is(err, cache.ErrOperationTimeout) || atomic.LoadInt64(&d.calls) != 1 {
+		t.Fatal(err, atomic.LoadInt64(&d.calls))
+	}
+	c.OperationTimeout = time.Second
+	_, err = c.GetBytesValue("test")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	c.OperationTimeout = 5 * time.Millisecond
+	_, err = c.GetBytesValue("test")
+	if !errors.Is(err, cache.ErrOperationTimeout) {
+		t.Fatal(err)
+	}
+	err = c.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt64(&d.finished) != atomic.LoadInt64(&d.calls) {
+		t.Fatal(atomic.LoadInt64(&d.finished), atomic.LoadInt64(&d.calls))
+	}
+	oc := cache.NewOptionConfig()
+	oc.Driver = "dummycache"
+	oc.RetryCount = -1
+	err = oc.ApplyTo(cache.New())
+	if !errors.Is(err, cache.ErrInvalidRetryPolicy) {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+//DefaultSizeHistogramBuckets default upper bounds in bytes of size histogram buckets.
+var DefaultSizeHistogramBuckets = []int{64, 256, 1024, 4096, 16384, 65536, 262144, 1048576}
+
+//SizeDistribution size distribution of values stored in cache.
+type SizeDistribution struct {
+	//Buckets upper bounds in bytes of buckets.
+	Buckets []int
+	//Counts count of values in every bucket.
+	//Last count is count of values larger than last bucket.
+	Counts []int64
+	//Count total count of values.
+	Count int64
+	//Sum total size of values.
+	Sum int64
+	//Max max size of values.
+	Max int
+}
+
+func newSizeDistribution(buckets []int) *SizeDistribution {
+	return &SizeDistribution{
+		Buckets: buckets,
+		Counts:  make([]int64, len(buckets)+1),
+	}
+}
+
+func (d *SizeDistribution) observe(size int) {
+	i := sort.SearchInts(d.Buckets, size)
+	d.Counts[i]++
+	d.Count++
+	d.Sum = d.Sum + int64(size)
+	if size > d.Max {
+		d.Max = size
+	}
+}
+
+func (d *SizeDistribution) clone() *SizeDistribution {
+	result := *d
+	result.Counts = make([]int64, len(d.Counts))
+	copy(result.Counts, d.Counts)
+	return &result
+}
+
+//SizeHistogram in-process metrics which tracks size distribution of values stored per cache.
+//Used with MetricsCacheable to tune entry size limits and compression cutoffs.
+type SizeHistogram struct {
+	locker        sync.Mutex
+	buckets       []int
+	distributions map[string]*SizeDistribution
+}
+
+//NewSizeHistogram create new size histogram with given bucket upper bounds in bytes.
+//DefaultSizeHistogramBuckets will be used if buckets is empty.
+func NewSizeHistogram(buckets ...int) *SizeHistogram {
+	if len(buckets) == 0 {
+		buckets = DefaultSizeHistogramBuckets
+	}
+	b := make([]int, len(buckets))
+	copy(b, buckets)
+	sort.Ints(b)
+	return &SizeHistogram{
+		buckets:       b,
+		distributions: map[string]*SizeDistribution{},
+	}
+}
+
+//ObserveOperation record cache operation.
+//Size histogram only records entry sizes,so operations are ignored.
+func (h *SizeHistogram) ObserveOperation(name string, op string, duration time.Duration, size int, err error) {
+}
+
+//ObserveHits record cache hits and misses count.
+//Size histogram only records entry sizes,so hits are ignored.
+func (h *SizeHistogram) ObserveHits(name string, hits int, misses int) {
+}
+
+//ObserveEntrySize record size in bytes of value stored in cache with given name.
+func (h *SizeHistogram) ObserveEntrySize(name string, size int) {
+	h.locker.Lock()
+	defer h.locker.Unlock()
+	d := h.distributions[name]
+	if d == nil {
+		d = newSizeDistribution(h.buckets)
+		h.distributions[name] = d
+	}
+	d.observe(size)
+}
+
+//Distribution return copy of size distribution of cache with given name.
+func (h *SizeHistogram) Distribution(name string) *SizeDistribution {
+	h.locker.Lock()
+	defer h.locker.Unlock()
+	d := h.distributions[name]
+	if d == nil {
+		return newSizeDistribution(h.buckets)
+	}
+	return d.clone()
+}
+
+//Names return names of caches observed.
+func (h *SizeHistogram) Names() []string {
+	h.locker.Lock()
+	defer h.locker.Unlock()
+	result := make([]string, 0, len(h.distributions))
+	for k := range h.distributions {
+		result = append(result, k)
+	}
+	sort.Strings(result)
+	return result
+}
+
+//Wrap wrap given cacheable with metrics cacheable which reports to h with given name.
+func (h *SizeHistogram) Wrap(c Cacheable, name string) *MetricsCacheable {
+	return NewMetricsCacheable(c, name, h)
+}
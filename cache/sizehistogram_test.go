@@ -0,0 +1,48 @@
+package cache_test
+
+import (
+	"testing"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+func TestSizeHistogram(t *testing.T) {
+	h := cache.NewSizeHistogram(10, 100)
+	c := h.Wrap(newTestCache(3600), "test")
+	err := c.SetBytesValue("small", make([]byte, 5), cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.SetBytesValue("medium", make([]byte, 100), cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.MSetBytesValue(map[string][]byte{"large": make([]byte, 101), "small2": make([]byte, 10)}, cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.Set("value", "12345678", cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v string
+	err = c.Get("value", &v)
+	if err != nil || v != "12345678" {
+		t.Fatal(v, err)
+	}
+	d := h.Distribution("test")
+	if d.Count != 5 || d.Sum != 226 || d.Max != 101 {
+		t.Fatal(d)
+	}
+	if d.Counts[0] != 3 || d.Counts[1] != 1 || d.Counts[2] != 1 {
+		t.Fatal(d.Counts)
+	}
+	names := h.Names()
+	if len(names) != 1 || names[0] != "test" {
+		t.Fatal(names)
+	}
+	d = h.Distribution("notexists")
+	if d.Count != 0 || len(d.Counts) != 3 {
+		t.Fatal(d)
+	}
+}
@@ -0,0 +1,153 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+//StoreReader function which reads value from persistent store by given key.
+//Should return ErrNotFound if value not exists.
+type StoreReader func(key string) (interface{}, error)
+
+//StoreWriter function which writes value to persistent store by given key.
+type StoreWriter func(key string, v interface{}) error
+
+//StoreDeleter function which deletes value from persistent store by given key.
+type StoreDeleter func(key string) error
+
+type storeOperation struct {
+	key    string
+	value  interface{}
+	delete bool
+	done   chan error
+}
+
+//Store read-through/write-through wrapper which keeps cache and persistent store in sync.
+type Store struct {
+	//Cache cacheable which caches values.
+	Cache Cacheable
+	//Reader function which reads value from persistent store.
+	Reader StoreReader
+	//Writer function which writes value to persistent store.
+	Writer StoreWriter
+	//Deleter function which deletes value from persistent store.
+	Deleter StoreDeleter
+	//TTL cache ttl.
+	TTL time.Duration
+	//OnWriteError hook called with error raised when writing behind.
+	OnWriteError func(key string, err error)
+	locker       sync.RWMutex
+	queue        chan *storeOperation
+	stopped      chan struct{}
+}
+
+//NewStore create new store with given cacheable,reader,writer and deleter.
+func NewStore(c Cacheable, reader StoreReader, writer StoreWriter, deleter StoreDeleter) *Store {
+	return &Store{
+		Cache:   c,
+		Reader:  reader,
+		Writer:  writer,
+		Deleter: deleter,
+		TTL:     DefaultTTL,
+	}
+}
+
+//EnableWriteBehind enable write-behind buffering with given queue size.
+//Put will return after value cached,and value will be written to persistent store in background.
+//Errors raised in background will be passed to OnWriteError.
+func (s *Store) EnableWriteBehind(size int) {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	if s.queue != nil {
+		return
+	}
+	s.queue = make(chan *storeOperation, size)
+	s.stopped = make(chan struct{})
+	go s.run(s.queue, s.stopped)
+}
+
+func (s *Store) exec(op *storeOperation) error {
+	if op.delete {
+		return s.Deleter(op.key)
+	}
+	return s.Writer(op.key, op.value)
+}
+
+func (s *Store) run(queue chan *storeOperation, stopped chan struct{}) {
+	defer close(stopped)
+	for op := range queue {
+		err := s.exec(op)
+		if op.done != nil {
+			op.done <- err
+			continue
+		}
+		if err != nil && s.OnWriteError != nil {
+			s.OnWriteError(op.key, err)
+		}
+	}
+}
+
+//Close write all buffered values to persistent store and disable write-behind buffering.
+func (s *Store) Close() {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	if s.queue == nil {
+		return
+	}
+	close(s.queue)
+	<-s.stopped
+	s.queue = nil
+}
+
+//Get get value from cache by given key.
+//If value not found in cache,value will be read from persistent store and cached.
+//Parameter v should be pointer to empty data model which data filled in.
+//Return any error raised.
+func (s *Store) Get(key string, v interface{}) error {
+	return s.Cache.Load(key, v, s.TTL, Loader(s.Reader))
+}
+
+//Put write value to persistent store and cache by given key.
+//If write-behind is enabled,value will be written to persistent store in background.
+//Return any error raised.
+func (s *Store) Put(key string, v interface{}) error {
+	s.locker.RLock()
+	defer s.locker.RUnlock()
+	if s.queue != nil {
+		err := s.Cache.Set(key, v, s.TTL)
+		if err != nil {
+			return err
+		}
+		s.queue <- &storeOperation{key: key, value: v}
+		return nil
+	}
+	err := s.Writer(key, v)
+	if err != nil {
+		return err
+	}
+	err = s.Cache.Set(key, v, s.TTL)
+	if err != nil {
+		return s.Cache.Del(key)
+	}
+	return nil
+}
+
+//Delete delete value from persistent store and then invalidate cache by given key.
+//If write-behind is enabled,Delete will wait until all buffered values written.
+//Return any error raised.
+func (s *Store) Delete(key string) error {
+	s.locker.RLock()
+	defer s.locker.RUnlock()
+	var err error
+	if s.queue != nil {
+		done := make(chan error, 1)
+		s.queue <- &storeOperation{key: key, delete: true, done: done}
+		err = <-done
+	} else {
+		err = s.Deleter(key)
+	}
+	if err != nil {
+		return err
+	}
+	return s.Cache.Del(key)
+}
@@ -0,0 +1,109 @@
+package cache_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+type testPersistentStore struct {
+	locker sync.Mutex
+	data   map[string]string
+	reads  int
+}
+
+func (s *testPersistentStore) Read(key string) (interface{}, error) {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	s.reads++
+	v, ok := s.data[key]
+	if !ok {
+		return nil, cache.ErrNotFound
+	}
+	return &v, nil
+}
+
+func (s *testPersistentStore) Write(key string, v interface{}) error {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	s.data[key] = *(v.(*string))
+	return nil
+}
+
+func (s *testPersistentStore) Delete(key string) error {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	delete(s.data, key)
+	return nil
+}
+
+func TestStore(t *testing.T) {
+	ps := &testPersistentStore{data: map[string]string{"exists": "value"}}
+	s := cache.NewStore(newTestCache(3600), ps.Read, ps.Write, ps.Delete)
+	var v string
+	err := s.Get("exists", &v)
+	if err != nil || v != "value" {
+		t.Fatal(v, err)
+	}
+	err = s.Get("exists", &v)
+	if err != nil || v != "value" || ps.reads != 1 {
+		t.Fatal(v, err, ps.reads)
+	}
+	err = s.Get("notexists", &v)
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	newvalue := "newvalue"
+	err = s.Put("exists", &newvalue)
+	if err != nil || ps.data["exists"] != "newvalue" {
+		t.Fatal(err, ps.data)
+	}
+	err = s.Get("exists", &v)
+	if err != nil || v != "newvalue" || ps.reads != 2 {
+		t.Fatal(v, err, ps.reads)
+	}
+	err = s.Delete("exists")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.Get("exists", &v)
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+}
+
+func TestStoreWriteBehind(t *testing.T) {
+	ps := &testPersistentStore{data: map[string]string{}}
+	s := cache.NewStore(newTestCache(3600), ps.Read, ps.Write, ps.Delete)
+	s.EnableWriteBehind(10)
+	value := "value"
+	err := s.Put("test", &value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v string
+	err = s.Get("test", &v)
+	if err != nil || v != "value" || ps.reads != 0 {
+		t.Fatal(v, err, ps.reads)
+	}
+	err = s.Put("test2", &value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.Delete("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps.locker.Lock()
+	_, ok := ps.data["test"]
+	ps.locker.Unlock()
+	if ok {
+		t.Fatal(ps.data)
+	}
+	s.Close()
+	if ps.data["test2"] != "value" {
+		t.Fatal(ps.data)
+	}
+}
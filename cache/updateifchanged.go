@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"bytes"
+	"errors"
+	"time"
+)
+
+//UpdateIfChanged update data model to given cache by key only if the cache exists and marshaled value changed.
+//Marshaled value is compared with stored value after decompressed and decrypted,
+//so that writes of recomputed but unchanged values are skipped.
+//If ttl is DefaultTTL(0),use default ttl in config instead.
+//Ttl of unchanged value is not refreshed.
+//Return whether value is written and any error raised.
+func UpdateIfChanged(c Cacheable, key string, v interface{}, ttl time.Duration) (bool, error) {
+	if key == "" {
+		return false, ErrKeyUnavailable
+	}
+	bs, err := c.Util().Marshaler.Marshal(v)
+	if err != nil {
+		return false, WrapKeyError(LayerMarshaler, key, err)
+	}
+	current, err := c.GetBytesValue(key)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if bytes.Equal(current, bs) {
+		return false, nil
+	}
+	err = c.UpdateBytesValue(key, bs, ttl)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+//UpdateIfChanged update data model to cache by given key only if the cache exists and marshaled value changed.
+//If ttl is DefaultTTL(0),use default ttl in config instead.
+//Return whether value is written and any error raised.
+func (c *Cache) UpdateIfChanged(key string, v interface{}, ttl time.Duration) (bool, error) {
+	return UpdateIfChanged(c, key, v, ttl)
+}
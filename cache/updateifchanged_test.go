@@ -0,0 +1,49 @@
+package cache_test
+
+import (
+	"testing"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+func TestUpdateIfChanged(t *testing.T) {
+	c := newTestCache(3600)
+	changed, err := c.UpdateIfChanged("test", "value", 0)
+	if changed || err != nil {
+		t.Fatal(changed, err)
+	}
+	err = c.Set("test", "value", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed, err = c.UpdateIfChanged("test", "value", 0)
+	if changed || err != nil {
+		t.Fatal(changed, err)
+	}
+	changed, err = c.UpdateIfChanged("test", "newvalue", 0)
+	if !changed || err != nil {
+		t.Fatal(changed, err)
+	}
+	var result string
+	err = c.Get("test", &result)
+	if err != nil || result != "newvalue" {
+		t.Fatal(result, err)
+	}
+	n := cache.NewNode(c, "node")
+	err = n.Set("test", 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed, err = cache.UpdateIfChanged(n, "test", 1, 0)
+	if changed || err != nil {
+		t.Fatal(changed, err)
+	}
+	changed, err = cache.UpdateIfChanged(n, "test", 2, 0)
+	if !changed || err != nil {
+		t.Fatal(changed, err)
+	}
+	_, err = c.UpdateIfChanged("", 1, 0)
+	if err != cache.ErrKeyUnavailable {
+		t.Fatal(err)
+	}
+}
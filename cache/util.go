@@ -1,40 +1,187 @@
 package cache
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
 	"sync"
+	"time"
 )
 
+//ErrLockTimeout raised when locker is not acquired before context done.
+var ErrLockTimeout = errors.New("cache: lock timeout")
+
+//LockerPollInterval first interval between attempts when waiting for locker with context.
+//Interval doubles after each failed attempt up to MaxLockerPollInterval.
+var LockerPollInterval = time.Millisecond
+
+//MaxLockerPollInterval max interval between attempts when waiting for locker with context.
+var MaxLockerPollInterval = 20 * time.Millisecond
+
+//LockOwner owner info of locked locker.
+type LockOwner struct {
+	//LockedAt time when locker was locked.
+	LockedAt time.Time
+	//Stack stack of goroutine which locked locker.
+	Stack []byte
+}
+
+//LockTimeoutError error raised when locker is not acquired before context done.
+//errors.Is(err, ErrLockTimeout) returns true.
+type LockTimeoutError struct {
+	//Key locker key.
+	Key string
+	//Owner owner of locker when timeout.
+	//Owner is nil if owner is not recorded.
+	Owner *LockOwner
+}
+
+//Error return error message.
+func (e *LockTimeoutError) Error() string {
+	if e.Owner == nil {
+		return fmt.Sprintf("%s (key %q)", ErrLockTimeout.Error(), e.Key)
+	}
+	return fmt.Sprintf("%s (key %q,held since %s)\n%s", ErrLockTimeout.Error(), e.Key, e.Owner.LockedAt.Format(time.RFC3339Nano), e.Owner.Stack)
+}
+
+//Unwrap return ErrLockTimeout.
+func (e *LockTimeoutError) Unwrap() error {
+	return ErrLockTimeout
+}
+
 //NewUtil create new util
 func NewUtil() *Util {
 	return &Util{
-		locks: &sync.Map{},
+		Refreshes: NewRefreshRegistry(),
+		locks:     &sync.Map{},
 	}
 }
 
 //Locker cache locker
 type Locker struct {
 	sync.RWMutex
-	Map *sync.Map
-	Key string
+	Map         *sync.Map
+	Key         string
+	recordOwner bool
+	ownerLocker sync.Mutex
+	owner       *LockOwner
+}
+
+func (l *Locker) setOwner(owner *LockOwner) {
+	l.ownerLocker.Lock()
+	l.owner = owner
+	l.ownerLocker.Unlock()
+}
+
+func (l *Locker) recordLocked() {
+	if l.recordOwner {
+		l.setOwner(&LockOwner{LockedAt: time.Now(), Stack: debug.Stack()})
+	}
+}
+
+//Owner return owner of locker.
+//Return nil if locker is not locked for writing or owner is not recorded.
+func (l *Locker) Owner() *LockOwner {
+	l.ownerLocker.Lock()
+	defer l.ownerLocker.Unlock()
+	return l.owner
+}
+
+//Lock lock locker for writing.
+//Owner will be recorded if util RecordLockOwner is true.
+func (l *Locker) Lock() {
+	l.RWMutex.Lock()
+	l.recordLocked()
 }
 
 //Unlock unlock and delete locker
 func (l *Locker) Unlock() {
+	l.setOwner(nil)
 	l.RWMutex.Unlock()
 	l.Map.Delete(l.Key)
 }
 
+//wait poll given try lock function until it succeeds or context done.
+//No goroutine is left behind,so locker is never acquired after wait returns timeout.
+func (l *Locker) wait(ctx context.Context, trylock func() bool) error {
+	if trylock() {
+		return nil
+	}
+	interval := LockerPollInterval
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			if trylock() {
+				return nil
+			}
+			if interval < MaxLockerPollInterval {
+				interval = interval * 2
+				if interval > MaxLockerPollInterval {
+					interval = MaxLockerPollInterval
+				}
+			}
+			timer.Reset(interval)
+		case <-ctx.Done():
+			return &LockTimeoutError{Key: l.Key, Owner: l.Owner()}
+		}
+	}
+}
+
+//TryLock lock locker for writing until given context done.
+//Return *LockTimeoutError if locker is not acquired before context done.
+func (l *Locker) TryLock(ctx context.Context) error {
+	err := l.wait(ctx, l.RWMutex.TryLock)
+	if err != nil {
+		return err
+	}
+	l.recordLocked()
+	return nil
+}
+
+//TryRLock lock locker for reading until given context done.
+//Return *LockTimeoutError if locker is not acquired before context done.
+func (l *Locker) TryRLock(ctx context.Context) error {
+	return l.wait(ctx, l.RWMutex.TryRLock)
+}
+
 //Util cache util
 type Util struct {
 	Marshaler Marshaler
+	//MarshalerName registered name of marshaler,used in explanations.
+	MarshalerName string
+	//NegativeTTL ttl of "not found" result cached by loader.
+	//Negative caching is disabled if NegativeTTL is not positive.
+	NegativeTTL time.Duration
+	//LockTimeout max time waiting for locker when loading data.
+	//Waiting is not limited if LockTimeout is not positive.
+	LockTimeout time.Duration
+	//LoadDeadlineMargin time reserved for writing loaded value to cache when loading with context.
+	//Loader context deadline is earlier than given context deadline by LoadDeadlineMargin.
+	//Loader context deadline is not changed if not positive.
+	LoadDeadlineMargin time.Duration
+	//RecordLockOwner whether lockers record owner stack when locked for writing.
+	//Owner will be included in LockTimeoutError.
+	RecordLockOwner bool
+	//Refreshes registry of in-flight background refreshes.
+	//Registry is shared by cloned utils.
+	Refreshes *RefreshRegistry
 	locks     *sync.Map
 }
 
 //Clone clone util
 func (u *Util) Clone() *Util {
 	return &Util{
-		Marshaler: u.Marshaler,
-		locks:     u.locks,
+		Marshaler:          u.Marshaler,
+		MarshalerName:      u.MarshalerName,
+		NegativeTTL:        u.NegativeTTL,
+		LockTimeout:        u.LockTimeout,
+		LoadDeadlineMargin: u.LoadDeadlineMargin,
+		RecordLockOwner:    u.RecordLockOwner,
+		Refreshes:          u.Refreshes,
+		locks:              u.locks,
 	}
 }
 
@@ -42,13 +189,40 @@ func (u *Util) Clone() *Util {
 //Return locker and if locker is locked.
 func (u *Util) Locker(key string) (*Locker, bool) {
 	newlocker := &Locker{
-		Map: u.locks,
-		Key: key,
+		Map:         u.locks,
+		Key:         key,
+		recordOwner: u.RecordLockOwner,
 	}
 	v, ok := u.locks.LoadOrStore(key, newlocker)
 	return v.(*Locker), ok
 }
 
+//Lock lock given locker for writing.
+//Locker will wait no longer than LockTimeout if LockTimeout is positive.
+//Return *LockTimeoutError if timeout.
+func (u *Util) Lock(l *Locker) error {
+	if u.LockTimeout <= 0 {
+		l.Lock()
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), u.LockTimeout)
+	defer cancel()
+	return l.TryLock(ctx)
+}
+
+//RLock lock given locker for reading.
+//Locker will wait no longer than LockTimeout if LockTimeout is positive.
+//Return *LockTimeoutError if timeout.
+func (u *Util) RLock(l *Locker) error {
+	if u.LockTimeout <= 0 {
+		l.RLock()
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), u.LockTimeout)
+	defer cancel()
+	return l.TryRLock(ctx)
+}
+
 //Marshal Marshal data model to  bytes.
 //Return marshaled bytes and any error rasied.
 func (u *Util) Marshal(v interface{}) ([]byte, error) {
@@ -1,6 +1,9 @@
 package cache_test
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -97,3 +100,85 @@ func TestLaterLoader(t *testing.T) {
 		t.Fatal(result5)
 	}
 }
+
+func TestLockerTryLock(t *testing.T) {
+	u := cache.NewUtil()
+	u.RecordLockOwner = true
+	l, _ := u.Locker("test")
+	l.Lock()
+	owner := l.Owner()
+	if owner == nil || !strings.Contains(string(owner.Stack), "TestLockerTryLock") {
+		t.Fatal(owner)
+	}
+	l2, ok := u.Locker("test")
+	if !ok || l2 != l {
+		t.Fatal(ok)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := l2.TryRLock(ctx)
+	if !errors.Is(err, cache.ErrLockTimeout) {
+		t.Fatal(err)
+	}
+	lockerr, ok := err.(*cache.LockTimeoutError)
+	if !ok || lockerr.Key != "test" || lockerr.Owner != owner {
+		t.Fatal(err)
+	}
+	l.Unlock()
+	if l.Owner() != nil {
+		t.Fatal(l.Owner())
+	}
+	l3, _ := u.Locker("test")
+	err = l3.TryLock(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	l3.Unlock()
+}
+
+func TestLockerTryLockAbandoned(t *testing.T) {
+	u := cache.NewUtil()
+	l, _ := u.Locker("test")
+	l.Lock()
+	for i := 0; i < 10; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		err := l.TryLock(ctx)
+		cancel()
+		if !errors.Is(err, cache.ErrLockTimeout) {
+			t.Fatal(err)
+		}
+	}
+	l.RWMutex.Unlock()
+	if !l.RWMutex.TryLock() {
+		t.Fatal("locker acquired by abandoned waiter")
+	}
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		l.RWMutex.Unlock()
+	}()
+	err := l.TryRLock(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.RWMutex.RUnlock()
+}
+
+func TestLoadLockTimeout(t *testing.T) {
+	c := newTestCache(3600)
+	c.Util().LockTimeout = 10 * time.Millisecond
+	defer func() {
+		c.Util().LockTimeout = 0
+	}()
+	l, _ := c.Util().Locker(c.FinalKey("test"))
+	l.Lock()
+	var result string
+	err := c.Load("test", &result, 0, testLaterLoader)
+	if !errors.Is(err, cache.ErrLockTimeout) {
+		t.Fatal(err)
+	}
+	l.Unlock()
+	err = c.Load("test", &result, 0, testLaterLoader)
+	if err != nil || result != "test" {
+		t.Fatal(result, err)
+	}
+}
@@ -0,0 +1,154 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+//DefaultWarmerConcurrency default max count of keys loaded concurrently by warmer.
+var DefaultWarmerConcurrency = 8
+
+//KeySource source which provides keys to warm.
+type KeySource interface {
+	//Keys return keys which should be warmed.
+	//Return keys and any error if raised.
+	Keys() ([]string, error)
+}
+
+//KeyList key source of static key list.
+type KeyList []string
+
+//Keys return keys which should be warmed.
+func (l KeyList) Keys() ([]string, error) {
+	return l, nil
+}
+
+//WarmProgress progress of cache warming.
+type WarmProgress struct {
+	//Total count of keys to warm.
+	Total int
+	//Warmed count of keys loaded and cached.
+	Warmed int
+	//Skipped count of keys already cached or not found by loader.
+	Skipped int
+	//Failed count of keys failed to load or cache.
+	Failed int
+}
+
+//Finished count of keys finished.
+func (p *WarmProgress) Finished() int {
+	return p.Warmed + p.Skipped + p.Failed
+}
+
+//Warmer cache warmer which pre-populates cache with loader concurrently.
+//Warmer is used at service startup before traffic is admitted.
+type Warmer struct {
+	//Cache cacheable to warm.
+	Cache Cacheable
+	//Loader loader which loads value by key.
+	Loader Loader
+	//TTL cache ttl.
+	TTL time.Duration
+	//Concurrency max count of keys loaded concurrently.
+	Concurrency int
+	//SkipCached whether skip keys already cached.
+	SkipCached bool
+	//OnProgress hook called with progress copy after every key finished.
+	OnProgress func(p WarmProgress)
+	//OnError hook called with key and error raised when warming key.
+	OnError func(key string, err error)
+}
+
+//NewWarmer create new warmer with given cacheable and loader.
+func NewWarmer(c Cacheable, loader Loader) *Warmer {
+	return &Warmer{
+		Cache:       c,
+		Loader:      loader,
+		TTL:         DefaultTTL,
+		Concurrency: DefaultWarmerConcurrency,
+	}
+}
+
+func (w *Warmer) warm(key string) (bool, error) {
+	if w.SkipCached {
+		_, err := w.Cache.GetBytesValue(key)
+		if err == nil {
+			return false, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			return false, err
+		}
+	}
+	v, err := w.Loader(key)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	err = w.Cache.Set(key, v, w.TTL)
+	if errors.Is(err, ErrNotCacheable) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+//Warm load and cache given keys concurrently.
+//Errors raised when warming keys will be passed to OnError and will not stop warming.
+//Return final progress.
+func (w *Warmer) Warm(keys ...string) *WarmProgress {
+	concurrency := w.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultWarmerConcurrency
+	}
+	progress := &WarmProgress{Total: len(keys)}
+	locker := sync.Mutex{}
+	queue := make(chan string)
+	wg := sync.WaitGroup{}
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range queue {
+				warmed, err := w.warm(key)
+				if err != nil && w.OnError != nil {
+					w.OnError(key, err)
+				}
+				locker.Lock()
+				switch {
+				case err != nil:
+					progress.Failed++
+				case warmed:
+					progress.Warmed++
+				default:
+					progress.Skipped++
+				}
+				p := *progress
+				if w.OnProgress != nil {
+					w.OnProgress(p)
+				}
+				locker.Unlock()
+			}
+		}()
+	}
+	for _, key := range keys {
+		queue <- key
+	}
+	close(queue)
+	wg.Wait()
+	return progress
+}
+
+//WarmFrom load and cache keys provided by given key source.
+//Return final progress and any error raised by key source.
+func (w *Warmer) WarmFrom(source KeySource) (*WarmProgress, error) {
+	keys, err := source.Keys()
+	if err != nil {
+		return nil, err
+	}
+	return w.Warm(keys...), nil
+}
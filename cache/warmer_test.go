@@ -0,0 +1,64 @@
+package cache_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+func TestWarmer(t *testing.T) {
+	c := newNodeTestCache(3600)
+	err := c.Set("cached", "old", cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errLoad := errors.New("load error")
+	var loads int64
+	w := cache.NewWarmer(c, func(key string) (interface{}, error) {
+		atomic.AddInt64(&loads, 1)
+		switch key {
+		case "missing":
+			return nil, cache.ErrNotFound
+		case "broken":
+			return nil, errLoad
+		}
+		return key + "value", nil
+	})
+	w.SkipCached = true
+	w.Concurrency = 2
+	var progressed int64
+	w.OnProgress = func(p cache.WarmProgress) {
+		atomic.AddInt64(&progressed, 1)
+		if p.Total != 5 || p.Finished() > p.Total {
+			t.Error(p)
+		}
+	}
+	var failed string
+	w.OnError = func(key string, err error) {
+		failed = key
+		if err != errLoad {
+			t.Error(err)
+		}
+	}
+	p, err := w.WarmFrom(cache.KeyList{"key1", "key2", "cached", "missing", "broken"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Total != 5 || p.Warmed != 2 || p.Skipped != 2 || p.Failed != 1 || p.Finished() != 5 {
+		t.Fatal(p)
+	}
+	if progressed != 5 || loads != 4 || failed != "broken" {
+		t.Fatal(progressed, loads, failed)
+	}
+	var v string
+	err = c.Get("key1", &v)
+	if err != nil || v != "key1value" {
+		t.Fatal(v, err)
+	}
+	err = c.Get("cached", &v)
+	if err != nil || v != "old" {
+		t.Fatal(v, err)
+	}
+}
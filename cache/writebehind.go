@@ -0,0 +1,364 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+//DefaultWriteBehindQueueSize default size of write-behind queue.
+var DefaultWriteBehindQueueSize = 1024
+
+//DefaultWriteBehindBatchSize default max count of entries persisted in one batch.
+var DefaultWriteBehindBatchSize = 100
+
+//DefaultWriteBehindInterval default max interval between batches.
+var DefaultWriteBehindInterval = time.Second
+
+//WriteBehindEntry entry which should be persisted to slow layer.
+type WriteBehindEntry struct {
+	//Key entry key.
+	Key string
+	//Bytes entry data.
+	Bytes []byte
+	//TTL entry ttl.
+	TTL time.Duration
+	//Updated whether entry should only be updated if exists.
+	Updated bool
+	//Expired whether only ttl of entry should be changed.
+	//Bytes is nil if expired is true.
+	Expired bool
+	//Deleted whether entry is deleted.
+	Deleted bool
+}
+
+//WriteBehindPersister function which persists batch of entries to slow layer.
+//Return any error if raised.
+type WriteBehindPersister func(entries []*WriteBehindEntry) error
+
+//CacheablePersister create write-behind persister which persists entries to given cacheable.
+func CacheablePersister(c Cacheable) WriteBehindPersister {
+	return func(entries []*WriteBehindEntry) error {
+		for _, e := range entries {
+			var err error
+			if e.Deleted {
+				err = c.Del(e.Key)
+			} else if e.Expired {
+				err = c.Expire(e.Key, e.TTL)
+			} else if e.Updated {
+				err = c.UpdateBytesValue(e.Key, e.Bytes, e.TTL)
+			} else {
+				err = c.SetBytesValue(e.Key, e.Bytes, e.TTL)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+//WriteBehind cacheable which returns after writing to fast layer,
+//and persists written entries to slow layer in background in batches.
+//Counters,Flush and Rename can not be persisted in background,and return ErrFeatureNotSupported.
+type WriteBehind struct {
+	*Proxy
+	//Persister function which persists entries to slow layer.
+	Persister WriteBehindPersister
+	//BatchSize max count of entries persisted in one batch.
+	BatchSize int
+	//Interval max interval between batches.
+	Interval time.Duration
+	//Retry retry times if persister returns error.
+	Retry int
+	//RetryInterval interval between retries.
+	RetryInterval time.Duration
+	errHandler    func(err error)
+	queue         chan *WriteBehindEntry
+	stopped       chan struct{}
+	locker        sync.RWMutex
+}
+
+//NewWriteBehind create new write-behind cacheable with given fast layer and persister.
+func NewWriteBehind(fast Cacheable, persister WriteBehindPersister) *WriteBehind {
+	return &WriteBehind{
+		Proxy:         NewProxy(fast),
+		Persister:     persister,
+		BatchSize:     DefaultWriteBehindBatchSize,
+		Interval:      DefaultWriteBehindInterval,
+		RetryInterval: time.Second,
+	}
+}
+
+//SetErrHandler Set callback to handler error raised when persisting entries.
+func (w *WriteBehind) SetErrHandler(f func(err error)) {
+	w.errHandler = f
+}
+
+//Start start background worker with given queue size.
+//DefaultWriteBehindQueueSize will be used if size is not positive.
+func (w *WriteBehind) Start(size int) {
+	w.locker.Lock()
+	defer w.locker.Unlock()
+	if w.queue != nil {
+		return
+	}
+	if size <= 0 {
+		size = DefaultWriteBehindQueueSize
+	}
+	w.queue = make(chan *WriteBehindEntry, size)
+	w.stopped = make(chan struct{})
+	go w.run(w.queue, w.stopped)
+}
+
+func (w *WriteBehind) persist(entries []*WriteBehindEntry) {
+	var err error
+	for i := 0; i <= w.Retry; i++ {
+		if i > 0 {
+			time.Sleep(w.RetryInterval)
+		}
+		err = w.Persister(entries)
+		if err == nil {
+			return
+		}
+	}
+	if w.errHandler != nil {
+		w.errHandler(err)
+	}
+}
+
+func (w *WriteBehind) run(queue chan *WriteBehindEntry, stopped chan struct{}) {
+	defer close(stopped)
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+	batch := make([]*WriteBehindEntry, 0, w.BatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		w.persist(batch)
+		batch = make([]*WriteBehindEntry, 0, w.BatchSize)
+	}
+	for {
+		select {
+		case e, ok := <-queue:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, e)
+			if len(batch) >= w.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+//Close persist all queued entries and stop background worker.
+//Wrapped fast layer will not be closed.
+//Return any error if raised.
+func (w *WriteBehind) Close() error {
+	w.locker.Lock()
+	defer w.locker.Unlock()
+	if w.queue == nil {
+		return nil
+	}
+	close(w.queue)
+	<-w.stopped
+	w.queue = nil
+	return nil
+}
+
+func (w *WriteBehind) enqueue(e *WriteBehindEntry) {
+	w.locker.RLock()
+	defer w.locker.RUnlock()
+	if w.queue == nil {
+		w.persist([]*WriteBehindEntry{e})
+		return
+	}
+	w.queue <- e
+}
+
+//Set Set data model to fast layer by given key and persist to slow layer in background.
+//If ttl is DefaultTTL(0),use default ttl in config instead.
+//Return any error raised.
+func (w *WriteBehind) Set(key string, v interface{}, ttl time.Duration) error {
+	bs, err := w.Util().Marshal(v)
+	if err != nil {
+		return err
+	}
+	return w.SetBytesValue(key, bs, ttl)
+}
+
+//SetBytesValue Set bytes data to fast layer by given key and persist to slow layer in background.
+//If ttl is DefaultTTL(0),use default ttl in config instead.
+//Return any error raised.
+func (w *WriteBehind) SetBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	err := w.Cacheable.SetBytesValue(key, bytes, ttl)
+	if err != nil {
+		return err
+	}
+	if ttl == DefaultTTL {
+		ttl = w.DefaultTTL()
+	}
+	w.enqueue(&WriteBehindEntry{Key: key, Bytes: bytes, TTL: ttl})
+	return nil
+}
+
+//Update Update data model to fast layer by given key only if the cache exist,and update slow layer in background.
+//If ttl is DefaultTTL(0),use default ttl in config instead.
+//Return any error raised.
+func (w *WriteBehind) Update(key string, v interface{}, ttl time.Duration) error {
+	bs, err := w.Util().Marshal(v)
+	if err != nil {
+		return err
+	}
+	return w.UpdateBytesValue(key, bs, ttl)
+}
+
+//UpdateBytesValue Update bytes data to fast layer by given key only if the cache exist,and update slow layer in background.
+//If ttl is DefaultTTL(0),use default ttl in config instead.
+//Return any error raised.
+func (w *WriteBehind) UpdateBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	err := w.Cacheable.UpdateBytesValue(key, bytes, ttl)
+	if err != nil {
+		return err
+	}
+	if ttl == DefaultTTL {
+		ttl = w.DefaultTTL()
+	}
+	w.enqueue(&WriteBehindEntry{Key: key, Bytes: bytes, TTL: ttl, Updated: true})
+	return nil
+}
+
+//MSetBytesValue set multiple bytes data to fast layer with given key-value map and persist to slow layer in background.
+//Return  any error if raised.
+func (w *WriteBehind) MSetBytesValue(data map[string][]byte, ttl time.Duration) error {
+	err := w.Cacheable.MSetBytesValue(data, ttl)
+	if err != nil {
+		return err
+	}
+	if ttl == DefaultTTL {
+		ttl = w.DefaultTTL()
+	}
+	for k := range data {
+		w.enqueue(&WriteBehindEntry{Key: k, Bytes: data[k], TTL: ttl})
+	}
+	return nil
+}
+
+//Del Delete data in fast layer by given name and delete from slow layer in background.
+//Return any error raised.
+func (w *WriteBehind) Del(key string) error {
+	err := w.Cacheable.Del(key)
+	if err != nil {
+		return err
+	}
+	w.enqueue(&WriteBehindEntry{Key: key, Deleted: true})
+	return nil
+}
+
+//SetIfNotExists Set bytes data to fast layer by given key only if the key does not exist,
+//and persist to slow layer in background if data is set.
+//Return whether data is set and any error raised.
+func (w *WriteBehind) SetIfNotExists(key string, bytes []byte, ttl time.Duration) (bool, error) {
+	ok, err := w.Proxy.SetIfNotExists(key, bytes, ttl)
+	if err != nil || !ok {
+		return ok, err
+	}
+	if ttl == DefaultTTL {
+		ttl = w.DefaultTTL()
+	}
+	w.enqueue(&WriteBehindEntry{Key: key, Bytes: bytes, TTL: ttl})
+	return true, nil
+}
+
+//SetIfVersion Set bytes data to fast layer by given key only if current value version equals given version,
+//and persist to slow layer in background if data is set.
+//Return whether data is set and any error raised.
+func (w *WriteBehind) SetIfVersion(key string, bytes []byte, version string, ttl time.Duration) (bool, error) {
+	ok, err := w.Proxy.SetIfVersion(key, bytes, version, ttl)
+	if err != nil || !ok {
+		return ok, err
+	}
+	if ttl == DefaultTTL {
+		ttl = w.DefaultTTL()
+	}
+	w.enqueue(&WriteBehindEntry{Key: key, Bytes: bytes, TTL: ttl})
+	return true, nil
+}
+
+//Expire set cache value expire duration in fast layer by given key and ttl,and change ttl in slow layer in background.
+//If ttl is DefaultTTL(0),use default ttl in config instead.
+//Return any error raised.
+func (w *WriteBehind) Expire(key string, ttl time.Duration) error {
+	err := w.Cacheable.Expire(key, ttl)
+	if err != nil {
+		return err
+	}
+	if ttl == DefaultTTL {
+		ttl = w.DefaultTTL()
+	}
+	w.enqueue(&WriteBehindEntry{Key: key, TTL: ttl, Expired: true})
+	return nil
+}
+
+//SetCounter Set int val in cache by given key.
+//Return ErrFeatureNotSupported.
+func (w *WriteBehind) SetCounter(key string, v int64, ttl time.Duration) error {
+	return ErrFeatureNotSupported
+}
+
+//GetCounter Get int val from cache by given key.
+//Return ErrFeatureNotSupported.
+func (w *WriteBehind) GetCounter(key string) (int64, error) {
+	return 0, ErrFeatureNotSupported
+}
+
+//IncrCounter Increase int val in cache by given key.
+//Return ErrFeatureNotSupported.
+func (w *WriteBehind) IncrCounter(key string, increment int64, ttl time.Duration) (int64, error) {
+	return 0, ErrFeatureNotSupported
+}
+
+//ExpireCounter set cache counter expire duration by given key and ttl.
+//Return ErrFeatureNotSupported.
+func (w *WriteBehind) ExpireCounter(key string, ttl time.Duration) error {
+	return ErrFeatureNotSupported
+}
+
+//DelCounter Delete int val in cache by given name.
+//Return ErrFeatureNotSupported.
+func (w *WriteBehind) DelCounter(key string) error {
+	return ErrFeatureNotSupported
+}
+
+//IncrFloatCounter Increase float val in cache by given key.
+//Return ErrFeatureNotSupported.
+func (w *WriteBehind) IncrFloatCounter(key string, increment float64, ttl time.Duration) (float64, error) {
+	return 0, ErrFeatureNotSupported
+}
+
+//GetFloatCounter Get float val from cache by given key.
+//Return ErrFeatureNotSupported.
+func (w *WriteBehind) GetFloatCounter(key string) (float64, error) {
+	return 0, ErrFeatureNotSupported
+}
+
+//Flush Delete all data in cache.
+//Return ErrFeatureNotSupported.
+func (w *WriteBehind) Flush() error {
+	return ErrFeatureNotSupported
+}
+
+//Rename move value of old key to new key.
+//Return ErrFeatureNotSupported.
+func (w *WriteBehind) Rename(oldKey string, newKey string) error {
+	return ErrFeatureNotSupported
+}
+
+//Capabilities return capabilities supported by fast layer except counters,flush and rename.
+func (w *WriteBehind) Capabilities() Capabilities {
+	return w.Proxy.Capabilities() &^ (CapabilityCounter | CapabilityFloatCounter | CapabilityFlush | CapabilityRename)
+}
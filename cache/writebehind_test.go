@@ -0,0 +1,134 @@
+package cache_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+func TestWriteBehind(t *testing.T) {
+	fast := newTestCache(3600)
+	slow := newTestCache(3600)
+	w := cache.NewWriteBehind(fast, cache.CacheablePersister(slow))
+	w.BatchSize = 2
+	w.Interval = time.Hour
+	w.Start(10)
+	err := w.SetBytesValue("test", []byte("value"), cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := fast.GetBytesValue("test")
+	if err != nil || string(bs) != "value" {
+		t.Fatal(string(bs), err)
+	}
+	err = w.Set("test2", "value2", cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = w.Del("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = slow.GetBytesValue("test")
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	var v string
+	err = slow.Get("test2", &v)
+	if err != nil || v != "value2" {
+		t.Fatal(v, err)
+	}
+}
+
+func TestWriteBehindRetry(t *testing.T) {
+	var locker sync.Mutex
+	var calls int
+	errPersist := errors.New("persist error")
+	w := cache.NewWriteBehind(newTestCache(3600), func(entries []*cache.WriteBehindEntry) error {
+		locker.Lock()
+		defer locker.Unlock()
+		calls++
+		return errPersist
+	})
+	w.Retry = 2
+	w.RetryInterval = time.Millisecond
+	var handled error
+	w.SetErrHandler(func(err error) {
+		handled = err
+	})
+	w.Start(10)
+	err := w.SetBytesValue("test", []byte("value"), cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 || handled != errPersist {
+		t.Fatal(calls, handled)
+	}
+}
+
+func TestWriteBehindOperations(t *testing.T) {
+	fast := newTestCache(3600)
+	slow := newTestCache(3600)
+	w := cache.NewWriteBehind(fast, cache.CacheablePersister(slow))
+	w.Start(10)
+	ok, err := w.SetIfNotExists("nx", []byte("value"), cache.DefaultTTL)
+	if err != nil || !ok {
+		t.Fatal(ok, err)
+	}
+	ok, err = w.SetIfNotExists("nx", []byte("value2"), cache.DefaultTTL)
+	if err != nil || ok {
+		t.Fatal(ok, err)
+	}
+	_, version, err := w.GetWithVersion("nx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, err = w.SetIfVersion("nx", []byte("value3"), version, cache.DefaultTTL)
+	if err != nil || !ok {
+		t.Fatal(ok, err)
+	}
+	err = w.Expire("nx", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, err = range []error{
+		w.SetCounter("counter", 1, cache.DefaultTTL),
+		w.DelCounter("counter"),
+		w.Flush(),
+		w.Rename("nx", "renamed"),
+	} {
+		if !errors.Is(err, cache.ErrFeatureNotSupported) {
+			t.Fatal(err)
+		}
+	}
+	_, err = w.IncrCounter("counter", 1, cache.DefaultTTL)
+	if !errors.Is(err, cache.ErrFeatureNotSupported) {
+		t.Fatal(err)
+	}
+	if w.Capabilities().Has(cache.CapabilityCounter) || w.Capabilities().Has(cache.CapabilityFlush) {
+		t.Fatal(w.Capabilities())
+	}
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := slow.GetBytesValue("nx")
+	if err != nil || string(bs) != "value3" {
+		t.Fatal(string(bs), err)
+	}
+	ttl, err := cache.GetTTL(slow, "nx")
+	if err != nil || ttl > time.Minute {
+		t.Fatal(ttl, err)
+	}
+}
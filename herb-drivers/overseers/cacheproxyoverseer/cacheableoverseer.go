@@ -1,6 +1,8 @@
 package cacheproxyoverseer
 
 import (
+	"time"
+
 	"github.com/herb-go/deprecated/cache"
 	"github.com/herb-go/worker"
 )
@@ -13,6 +15,9 @@ type Config struct {
 func (c *Config) ApplyTo(o *worker.PlainOverseer) error {
 	o.WithIntroduction("Cacheproxy workers")
 	o.WithTrainFunc(func(w []*worker.Worker) error {
+		proxies := map[string]*cache.Proxy{}
+		configs := map[string]*cache.OptionConfig{}
+		chains := map[string][]string{}
 		for _, v := range w {
 			proxy := GetCacheProxyByID(v.Name)
 			if proxy == nil {
@@ -27,6 +32,35 @@ func (c *Config) ApplyTo(o *worker.PlainOverseer) error {
 			if err != nil {
 				return err
 			}
+			chain := &ChainConfig{}
+			err = t.TranningPlan(chain)
+			if err != nil {
+				return err
+			}
+			proxies[v.Name] = proxy
+			configs[v.Name] = config
+			if len(chain.Chain) > 0 {
+				chains[v.Name] = chain.Chain
+			}
+		}
+		err := checkChainCycle(chains)
+		if err != nil {
+			return err
+		}
+		for _, v := range w {
+			proxy, ok := proxies[v.Name]
+			if !ok {
+				continue
+			}
+			config := configs[v.Name]
+			if chain, ok := chains[v.Name]; ok {
+				proxycache, err := NewChain(chain, time.Duration(config.TTL)*time.Second, config.Marshaler)
+				if err != nil {
+					return err
+				}
+				proxy.Cacheable = proxycache
+				continue
+			}
 			proxycache := cache.New()
 			err = config.ApplyTo(proxycache)
 			if err != nil {
@@ -0,0 +1,145 @@
+package cacheproxyoverseer
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+	"github.com/herb-go/deprecated/cache/drivers/failovercache"
+	"github.com/herb-go/deprecated/herb-drivers/overseers/cacheoverseer"
+)
+
+//ErrChainedWorkerNotFound error raised when chained worker is neither cache proxy worker nor cache worker.
+var ErrChainedWorkerNotFound = errors.New("cacheproxyoverseer: chained worker not found")
+
+//ErrChainCycle error raised when cache proxies chain to each other.
+var ErrChainCycle = errors.New("cacheproxyoverseer: proxy chain cycle")
+
+//ChainConfig proxy chaining config in tranning plan.
+type ChainConfig struct {
+	//Chain ids of cache proxy or cache workers which proxy chains to,in failover order.
+	//Operations are sent to first worker,and failed over to next worker if failed.
+	//Driver config in tranning plan is ignored if chain is not empty.
+	Chain []string
+}
+
+//workerDriver cache driver which sends operations to chained worker.
+type workerDriver struct {
+	cache.Cacheable
+}
+
+//SetGCErrHandler Set callback to handler error raised when gc.
+//Chained worker handles its own gc errors.
+func (d *workerDriver) SetGCErrHandler(f func(err error)) {}
+
+//SetUtil set util of cache driver.
+//Chained worker keeps its own util.
+func (d *workerDriver) SetUtil(u *cache.Util) {}
+
+//Close close cache driver.
+//Chained worker is managed by its own overseer and is not closed.
+func (d *workerDriver) Close() error {
+	return nil
+}
+
+//findWorker find cache proxy worker or cache worker by given id.
+//Cache proxy is returned itself,so that retraining of chained proxy takes effect.
+func findWorker(id string) cache.Cacheable {
+	if p := GetCacheProxyByID(id); p != nil {
+		return p
+	}
+	return cacheoverseer.GetCacheByID(id)
+}
+
+func newWorkerCache(c cache.Cacheable, ttl time.Duration) *cache.Cache {
+	cc := cache.New()
+	cc.Driver = &workerDriver{Cacheable: c}
+	cc.TTL = ttl
+	return cc
+}
+
+//newChainUtil create util of failover driver in chain with given marshaler name.
+//DefaultMarshaler will be used if marshaler name is empty.
+func newChainUtil(marshaler string) (*cache.Util, error) {
+	if marshaler == "" {
+		marshaler = cache.DefaultMarshaler
+	}
+	m, err := cache.NewMarshaler(marshaler)
+	if err != nil {
+		return nil, err
+	}
+	u := cache.NewUtil()
+	u.Marshaler = m
+	u.MarshalerName = marshaler
+	return u, nil
+}
+
+//createChain create cache which sends operations to given workers in failover order.
+//Failover drivers created use marshaler with given name.
+//Return cache created and any error if raised.
+func createChain(workers []cache.Cacheable, ttl time.Duration, marshaler string) (*cache.Cache, error) {
+	result := newWorkerCache(workers[len(workers)-1], ttl)
+	for i := len(workers) - 2; i >= 0; i-- {
+		u, err := newChainUtil(marshaler)
+		if err != nil {
+			return nil, err
+		}
+		d := &failovercache.Cache{
+			Primary:   newWorkerCache(workers[i], ttl),
+			Secondary: result,
+		}
+		d.SetUtil(u)
+		c := cache.New()
+		c.Driver = d
+		c.TTL = ttl
+		result = c
+	}
+	return result, nil
+}
+
+//checkChainCycle check whether proxies chain to each other with given chains of proxies by id.
+func checkChainCycle(chains map[string][]string) error {
+	visiting := map[string]bool{}
+	checked := map[string]bool{}
+	var visit func(id string) error
+	visit = func(id string) error {
+		if checked[id] {
+			return nil
+		}
+		if visiting[id] {
+			return fmt.Errorf("%w: %s", ErrChainCycle, id)
+		}
+		visiting[id] = true
+		for _, v := range chains[id] {
+			err := visit(v)
+			if err != nil {
+				return err
+			}
+		}
+		delete(visiting, id)
+		checked[id] = true
+		return nil
+	}
+	for id := range chains {
+		err := visit(id)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//NewChain create cache chaining to workers with given ids in failover order.
+//Values are marshaled by marshaler with given name,DefaultMarshaler will be used if empty.
+//Return cache created and any error if raised.
+func NewChain(ids []string, ttl time.Duration, marshaler string) (*cache.Cache, error) {
+	workers := make([]cache.Cacheable, len(ids))
+	for k, id := range ids {
+		workers[k] = findWorker(id)
+		if workers[k] == nil {
+			return nil, fmt.Errorf("%w: %s", ErrChainedWorkerNotFound, id)
+		}
+	}
+	return createChain(workers, ttl, marshaler)
+}
@@ -0,0 +1,145 @@
+package cacheproxyoverseer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/cache"
+	_ "github.com/herb-go/deprecated/cache/drivers/syncmapcache"
+)
+
+var errTestDown = errors.New("test worker down")
+
+type testDriver struct {
+	cache.Driver
+	locker sync.Mutex
+	down   bool
+	sets   int
+}
+
+func (d *testDriver) setDown(down bool) {
+	d.locker.Lock()
+	defer d.locker.Unlock()
+	d.down = down
+}
+
+func (d *testDriver) isDown() bool {
+	d.locker.Lock()
+	defer d.locker.Unlock()
+	return d.down
+}
+
+func (d *testDriver) setCount() int {
+	d.locker.Lock()
+	defer d.locker.Unlock()
+	return d.sets
+}
+
+func (d *testDriver) GetBytesValue(key string) ([]byte, error) {
+	if d.isDown() {
+		return nil, errTestDown
+	}
+	return d.Driver.GetBytesValue(key)
+}
+
+func (d *testDriver) SetBytesValue(key string, bytes []byte, ttl time.Duration) error {
+	if d.isDown() {
+		return errTestDown
+	}
+	d.locker.Lock()
+	d.sets++
+	d.locker.Unlock()
+	return d.Driver.SetBytesValue(key, bytes, ttl)
+}
+
+func newTestWorker() (*cache.Cache, *testDriver) {
+	oc := cache.NewOptionConfig()
+	oc.Driver = "syncmapcache"
+	oc.TTL = 3600
+	oc.Marshaler = "json"
+	oc.Config = json.NewDecoder(bytes.NewBufferString(`{"Size":10000000}`)).Decode
+	c, err := cache.NewSubCache(oc)
+	if err != nil {
+		panic(err)
+	}
+	d := &testDriver{Driver: c.Driver}
+	c.Driver = d
+	return c, d
+}
+
+func TestChain(t *testing.T) {
+	first, firstDriver := newTestWorker()
+	second, secondDriver := newTestWorker()
+	c, err := createChain([]cache.Cacheable{first, second}, time.Hour, "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	err = c.Set("test", "first", cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if firstDriver.setCount() != 1 || secondDriver.setCount() != 0 {
+		t.Fatal(firstDriver.setCount(), secondDriver.setCount())
+	}
+	var v string
+	err = c.Get("test", &v)
+	if err != nil || v != "first" {
+		t.Fatal(v, err)
+	}
+	firstDriver.setDown(true)
+	err = c.Get("test", &v)
+	if !errors.Is(err, cache.ErrNotFound) {
+		t.Fatal(err)
+	}
+	err = c.Set("test", "second", cache.DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if firstDriver.setCount() != 1 || secondDriver.setCount() != 1 {
+		t.Fatal(firstDriver.setCount(), secondDriver.setCount())
+	}
+	err = c.Get("test", &v)
+	if err != nil || v != "second" {
+		t.Fatal(v, err)
+	}
+	_, err = createChain([]cache.Cacheable{first, second}, time.Hour, "notexist")
+	if err == nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckChainCycle(t *testing.T) {
+	err := checkChainCycle(map[string][]string{
+		"a": {"b", "c"},
+		"b": {"c"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = checkChainCycle(map[string][]string{
+		"a": {"b"},
+		"b": {"c"},
+		"c": {"a"},
+	})
+	if !errors.Is(err, ErrChainCycle) {
+		t.Fatal(err)
+	}
+	err = checkChainCycle(map[string][]string{
+		"a": {"a"},
+	})
+	if !errors.Is(err, ErrChainCycle) {
+		t.Fatal(err)
+	}
+}
+
+func TestChainedWorkerNotFound(t *testing.T) {
+	_, err := NewChain([]string{"cacheproxyoverseer.notexist"}, time.Hour, "json")
+	if !errors.Is(err, ErrChainedWorkerNotFound) {
+		t.Fatal(err)
+	}
+}
@@ -20,10 +20,16 @@ func (d *Directive) ApplyTo(s *member.Service) error {
 }
 
 type Config struct {
+	//Realm application realm of member service.
+	//Realm will not be changed if empty.
+	Realm      string
 	Directives []*Directive
 }
 
 func (c *Config) ApplyTo(s *member.Service) error {
+	if c.Realm != "" {
+		s.Realm = c.Realm
+	}
 	for k := range c.Directives {
 		err := c.Directives[k].ApplyTo(s)
 		if err != nil {
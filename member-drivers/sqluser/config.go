@@ -1,71 +1,278 @@
 package sqluser
 
 import (
+	"errors"
+
 	"github.com/herb-go/datasource/sql/db"
+	"github.com/herb-go/datasource/sql/querybuilder"
 	"github.com/herb-go/deprecated/member"
 	"github.com/herb-go/uniqueid"
 )
 
+//ErrDatabaseRequired error raised when database config is empty.
+var ErrDatabaseRequired = errors.New("sqluser database config required")
+
+//ErrDialectNotFound error raised when dialect name is not registered.
+var ErrDialectNotFound = errors.New("sqluser dialect not found")
+
+//ErrUnknownFlag error raised when flag name is unknown.
+var ErrUnknownFlag = errors.New("sqluser unknown flag")
+
+//ErrUnknownModule error raised when module name in table options is unknown.
+var ErrUnknownModule = errors.New("sqluser unknown module")
+
+//FlagNames flag names which can be used in config.
+var FlagNames = map[string]int{
+	"account":  FlagWithAccount,
+	"password": FlagWithPassword,
+	"token":    FlagWithToken,
+	"user":     FlagWithUser,
+}
+
+//Config sqluser config
 type Config struct {
-	Database      *db.Config
-	TableAccount  string
+	//Database database config.
+	Database *db.Config
+	//TableAccount account table name.
+	TableAccount string
+	//TablePassword password table name.
 	TablePassword string
-	TableToken    string
-	TableUser     string
-	Prefix        string
+	//TableToken token table name.
+	TableToken string
+	//TableUser user table name.
+	TableUser string
+	//TableErasure erasure receipt table name.
+	//Erasure receipt will not be recorded if empty.
+	TableErasure string
+	//Prefix table name prefix.
+	Prefix string
+	//Columns column names.
+	//Default column name will be used if empty.
+	Columns Columns
+	//Flags enabled modules.Avaliable values are "account","password","token","user".
+	//If empty,modules with non-empty table name will be enabled.
+	Flags []string
+	//HashMethod hash method which used to generate new password.
+	//DefaultHashMethod will be used if empty.
+	HashMethod string
+	//PasswordKey static key used in passwrod hash generater.
+	PasswordKey string
+	//LegacyPasswordKeys rotated password keys which still can be used to verify password.
+	LegacyPasswordKeys []string
+	//Dialect registered dialect name.
+	//Portable queries will be used if empty.
+	Dialect string
+	//TableOptions table options of all tables used by generated DDL.
+	//Default table options will be used if nil.
+	TableOptions *TableOptions
+	//ModuleTableOptions table options of modules used by generated DDL,keyed by module name.
+	//Avaliable keys are "account","password","token","user","erasure".
+	ModuleTableOptions map[string]*TableOptions
+	//MaxLockRetries max retries of upsert operations when lock wait timeout or deadlock error raised.
+	//Default value is 0,which means upsert operations will not be retried.
+	MaxLockRetries int
+	//IDObfuscationKey secret key used to obfuscate user ids exposed by member service.
+	//User ids will not be obfuscated if empty.
+	IDObfuscationKey string
 }
 
-func (c *Config) ApplyToUser(u *User) error {
-	var err error
-	database := db.New()
-	err = c.Database.ApplyTo(database)
+//Flag return sqluser create flag.
+//Return flag and any error if raised.
+func (c *Config) Flag() (int, error) {
+	var flag int
+	if len(c.Flags) == 0 {
+		if c.TableAccount != "" {
+			flag = flag | FlagWithAccount
+		}
+		if c.TablePassword != "" {
+			flag = flag | FlagWithPassword
+		}
+		if c.TableToken != "" {
+			flag = flag | FlagWithToken
+		}
+		if c.TableUser != "" {
+			flag = flag | FlagWithUser
+		}
+		return flag, nil
+	}
+	for _, v := range c.Flags {
+		f, ok := FlagNames[v]
+		if !ok {
+			return 0, ErrUnknownFlag
+		}
+		flag = flag | f
+	}
+	return flag, nil
+}
+
+//Validate validate config.
+//Return any error if raised.
+func (c *Config) Validate() error {
+	if c.Database == nil {
+		return ErrDatabaseRequired
+	}
+	_, err := c.Flag()
 	if err != nil {
 		return err
 	}
-	var flag int
-	if c.TableAccount != "" {
-		flag = flag | FlagWithAccount
+	if c.HashMethod != "" && HashFuncMap[c.HashMethod] == nil {
+		return ErrHashMethodNotFound
+	}
+	if c.Dialect != "" && Dialects[c.Dialect] == nil {
+		return ErrDialectNotFound
+	}
+	for k := range c.ModuleTableOptions {
+		if _, ok := FlagNames[k]; !ok && k != ModuleErasure {
+			return ErrUnknownModule
+		}
+	}
+	return nil
+}
+
+func mergeColumns(c Columns) Columns {
+	columns := DefaultColumns()
+	if c.UID != "" {
+		columns.UID = c.UID
+	}
+	if c.Keyword != "" {
+		columns.Keyword = c.Keyword
+	}
+	if c.Account != "" {
+		columns.Account = c.Account
+	}
+	if c.CreatedTime != "" {
+		columns.CreatedTime = c.CreatedTime
+	}
+	if c.UpdatedTime != "" {
+		columns.UpdatedTime = c.UpdatedTime
+	}
+	if c.HashMethod != "" {
+		columns.HashMethod = c.HashMethod
+	}
+	if c.Salt != "" {
+		columns.Salt = c.Salt
 	}
-	if c.TablePassword != "" {
-		flag = flag | FlagWithPassword
+	if c.Password != "" {
+		columns.Password = c.Password
 	}
-	if c.TableAccount != "" {
-		flag = flag | FlagWithAccount
+	if c.Token != "" {
+		columns.Token = c.Token
 	}
-	if c.TableToken != "" {
-		flag = flag | FlagWithToken
+	if c.Status != "" {
+		columns.Status = c.Status
 	}
+	if c.ExpiredTime != "" {
+		columns.ExpiredTime = c.ExpiredTime
+	}
+	if c.IP != "" {
+		columns.IP = c.IP
+	}
+	if c.Source != "" {
+		columns.Source = c.Source
+	}
+	if c.UserAgentHash != "" {
+		columns.UserAgentHash = c.UserAgentHash
+	}
+	return columns
+}
+
+func tableName(name string, defaultName string) string {
+	if name == "" {
+		return defaultName
+	}
+	return name
+}
+
+//ApplyTo apply config to sqluser.
+//Return any error if raised.
+func (c *Config) ApplyTo(u *User) error {
+	err := c.Validate()
+	if err != nil {
+		return err
+	}
+	flag, err := c.Flag()
+	if err != nil {
+		return err
+	}
+	database := db.New()
+	err = c.Database.ApplyTo(database)
+	if err != nil {
+		return err
+	}
+	q := querybuilder.New()
+	q.Driver = database.Driver()
 	u.DB = database
+	u.QueryBuilder = q
+	u.flagLocker.Lock()
 	u.Flag = flag
+	u.flagLocker.Unlock()
 	u.UIDGenerater = uniqueid.DefaultGenerator.GenerateID
-	u.Tables.AccountMapperName = c.TableAccount
-	u.Tables.PasswordMapperName = c.TablePassword
-	u.Tables.UserMapperName = c.TableUser
-	u.Tables.TokenMapperName = c.TableToken
+	if u.TokenGenerater == nil {
+		u.TokenGenerater = Timestamp
+	}
+	if u.SaltGenerater == nil {
+		u.SaltGenerater = RandomBytes
+	}
+	u.Tables.AccountMapperName = tableName(c.TableAccount, DefaultAccountMapperName)
+	u.Tables.PasswordMapperName = tableName(c.TablePassword, DefaultPasswordMapperName)
+	u.Tables.UserMapperName = tableName(c.TableUser, DefaultUserMapperName)
+	u.Tables.TokenMapperName = tableName(c.TableToken, DefaultTokenMapperName)
+	u.Tables.ErasureMapperName = c.TableErasure
 	u.AddTablePrefix(c.Prefix)
+	u.Columns = mergeColumns(c.Columns)
+	u.HashMethod = c.HashMethod
+	if u.HashMethod == "" {
+		u.HashMethod = DefaultHashMethod
+	}
+	u.PasswordKey = c.PasswordKey
+	u.LegacyPasswordKeys = c.LegacyPasswordKeys
+	u.Dialect = nil
+	if c.Dialect != "" {
+		u.Dialect = Dialects[c.Dialect]
+	}
+	u.TableOptions = c.TableOptions
+	u.ModulesTableOptions = c.ModuleTableOptions
+	u.MaxLockRetries = c.MaxLockRetries
 	return nil
 }
+
+//ApplyToUser apply config to sqluser.
+//Deprecated: use ApplyTo instead.
+func (c *Config) ApplyToUser(u *User) error {
+	return c.ApplyTo(u)
+}
+
+//Execute install sqluser modules to member service.
+//Return any error if raised.
 func (c *Config) Execute(s *member.Service) error {
-	u := New(nil, nil, 0)
-	err := c.ApplyToUser(u)
+	u := &User{}
+	err := c.ApplyTo(u)
 	if err != nil {
 		return err
 	}
-	if c.TableAccount != "" {
+	if u.HasFlag(FlagWithAccount) {
 		u.Account().Execute(s)
 	}
-	if c.TablePassword != "" {
+	if u.HasFlag(FlagWithPassword) {
 		u.Password().Execute(s)
 	}
-	if c.TableUser != "" {
+	if u.HasFlag(FlagWithUser) {
 		u.User().Execute(s)
 	}
-	if c.TableToken != "" {
+	if u.HasFlag(FlagWithToken) {
 		u.Token().Execute(s)
 	}
+	if c.IDObfuscationKey != "" {
+		o, err := NewIDObfuscator([]byte(c.IDObfuscationKey))
+		if err != nil {
+			return err
+		}
+		s.IDEncoder = o
+	}
 	return nil
 }
 
+//DirectiveFactory sqluser directive factory.
 var DirectiveFactory = func(loader func(v interface{}) error) (member.Directive, error) {
 	c := &Config{}
 	err := loader(c)
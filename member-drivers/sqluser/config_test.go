@@ -0,0 +1,59 @@
+package sqluser
+
+import (
+	"testing"
+
+	"github.com/herb-go/datasource/sql/db"
+)
+
+func TestConfigFlag(t *testing.T) {
+	c := &Config{
+		TableAccount: "account",
+		TableUser:    "user",
+	}
+	flag, err := c.Flag()
+	if err != nil || flag != FlagWithAccount|FlagWithUser {
+		t.Fatal(flag, err)
+	}
+	c.Flags = []string{"password", "token"}
+	flag, err = c.Flag()
+	if err != nil || flag != FlagWithPassword|FlagWithToken {
+		t.Fatal(flag, err)
+	}
+	c.Flags = []string{"notexist"}
+	_, err = c.Flag()
+	if err != ErrUnknownFlag {
+		t.Fatal(err)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	c := &Config{}
+	if err := c.Validate(); err != ErrDatabaseRequired {
+		t.Fatal(err)
+	}
+	c.Database = &db.Config{}
+	c.HashMethod = "notexist"
+	if err := c.Validate(); err != ErrHashMethodNotFound {
+		t.Fatal(err)
+	}
+	c.HashMethod = DefaultHashMethod
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	c.ModuleTableOptions = map[string]*TableOptions{"notexist": &TableOptions{}}
+	if err := c.Validate(); err != ErrUnknownModule {
+		t.Fatal(err)
+	}
+	c.ModuleTableOptions = map[string]*TableOptions{"account": &TableOptions{}, ModuleErasure: &TableOptions{}}
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMergeColumns(t *testing.T) {
+	columns := mergeColumns(Columns{UID: "user_id"})
+	if columns.UID != "user_id" || columns.Account != "account" {
+		t.Fatal(columns)
+	}
+}
@@ -0,0 +1,103 @@
+package sqluser
+
+import (
+	"strconv"
+	"strings"
+)
+
+//Dialect sql dialect interface.
+//Implement this interface to support databases which do not share syntax with built-in dialects.
+type Dialect interface {
+	//UpsertCommand return command which inserts a row into given table,or updates columns in updateColumns if keys conflict.
+	//Command args should be values of columns in order.
+	//Return empty string if upsert is not supported.
+	UpsertCommand(table string, keys []string, columns []string, updateColumns []string) string
+	//ReturningClause return clause which returns given columns from inserted or updated rows.
+	//Return empty string if returning clause is not supported.
+	ReturningClause(columns ...string) string
+	//LockCommand return given select command modified to lock selected rows until transaction finished.
+	//Hint can be appended to command,or inserted after table name as table hint.
+	//Return given command unchanged if row lock is not supported.
+	LockCommand(command string) string
+}
+
+//MySQLDialect dialect for mysql and compatible databases like TiDB.
+type MySQLDialect struct{}
+
+//UpsertCommand return command which inserts a row into given table,or updates columns in updateColumns if keys conflict.
+func (d MySQLDialect) UpsertCommand(table string, keys []string, columns []string, updateColumns []string) string {
+	updates := make([]string, len(updateColumns))
+	for k, v := range updateColumns {
+		updates[k] = v + "=VALUES(" + v + ")"
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(columns)), ",")
+	return "INSERT INTO " + table + " (" + strings.Join(columns, ",") + ") VALUES (" + placeholders + ") ON DUPLICATE KEY UPDATE " + strings.Join(updates, ",")
+}
+
+//ReturningClause return clause which returns given columns from inserted or updated rows.
+//Mysql does not support returning clause.
+func (d MySQLDialect) ReturningClause(columns ...string) string {
+	return ""
+}
+
+//LockCommand return given select command with "FOR UPDATE" appended.
+func (d MySQLDialect) LockCommand(command string) string {
+	return command + " FOR UPDATE"
+}
+
+//PostgresDialect dialect for postgresql and compatible databases like CockroachDB.
+type PostgresDialect struct{}
+
+//UpsertCommand return command which inserts a row into given table,or updates columns in updateColumns if keys conflict.
+func (d PostgresDialect) UpsertCommand(table string, keys []string, columns []string, updateColumns []string) string {
+	updates := make([]string, len(updateColumns))
+	for k, v := range updateColumns {
+		updates[k] = v + "=EXCLUDED." + v
+	}
+	placeholders := make([]string, len(columns))
+	for k := range columns {
+		placeholders[k] = "$" + strconv.Itoa(k+1)
+	}
+	return "INSERT INTO " + table + " (" + strings.Join(columns, ",") + ") VALUES (" + strings.Join(placeholders, ",") + ") ON CONFLICT (" + strings.Join(keys, ",") + ") DO UPDATE SET " + strings.Join(updates, ",")
+}
+
+//ReturningClause return clause which returns given columns from inserted or updated rows.
+func (d PostgresDialect) ReturningClause(columns ...string) string {
+	if len(columns) == 0 {
+		return ""
+	}
+	return " RETURNING " + strings.Join(columns, ",")
+}
+
+//LockCommand return given select command with "FOR UPDATE" appended.
+func (d PostgresDialect) LockCommand(command string) string {
+	return command + " FOR UPDATE"
+}
+
+//Dialects registered dialects by name.
+//Dialect is only used if selected by Config.Dialect or User.Dialect.
+//You can register custom dialect into this map.
+var Dialects = map[string]Dialect{
+	"mysql":       MySQLDialect{},
+	"tidb":        MySQLDialect{},
+	"postgres":    PostgresDialect{},
+	"cockroachdb": PostgresDialect{},
+}
+
+//lockCommand return select command which locks selected rows by user dialect.
+//Return given command unchanged if dialect not set.
+func (u *User) lockCommand(command string) string {
+	if u.Dialect == nil {
+		return command
+	}
+	return u.Dialect.LockCommand(command)
+}
+
+//upsertCommand return upsert command of user dialect.
+//Return empty string if dialect not set or upsert not supported.
+func (u *User) upsertCommand(table string, keys []string, columns []string, updateColumns []string) string {
+	if u.Dialect == nil {
+		return ""
+	}
+	return u.Dialect.UpsertCommand(table, keys, columns, updateColumns)
+}
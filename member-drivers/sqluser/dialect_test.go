@@ -0,0 +1,44 @@
+package sqluser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDialect(t *testing.T) {
+	var d Dialect = MySQLDialect{}
+	cmd := d.UpsertCommand("token", []string{"uid"}, []string{"uid", "token"}, []string{"token"})
+	if cmd != "INSERT INTO token (uid,token) VALUES (?,?) ON DUPLICATE KEY UPDATE token=VALUES(token)" {
+		t.Fatal(cmd)
+	}
+	if d.ReturningClause("uid") != "" || d.LockCommand("SELECT uid FROM token") != "SELECT uid FROM token FOR UPDATE" {
+		t.Fatal(d)
+	}
+	d = PostgresDialect{}
+	cmd = d.UpsertCommand("token", []string{"uid"}, []string{"uid", "token"}, []string{"token"})
+	if cmd != "INSERT INTO token (uid,token) VALUES ($1,$2) ON CONFLICT (uid) DO UPDATE SET token=EXCLUDED.token" {
+		t.Fatal(cmd)
+	}
+	if d.ReturningClause("uid", "token") != " RETURNING uid,token" {
+		t.Fatal(d.ReturningClause("uid", "token"))
+	}
+	if d.LockCommand("SELECT uid FROM token") != "SELECT uid FROM token FOR UPDATE" {
+		t.Fatal(d)
+	}
+	u := &User{}
+	if u.upsertCommand("token", nil, nil, nil) != "" || u.lockCommand("SELECT uid FROM token") != "SELECT uid FROM token" {
+		t.Fatal(u)
+	}
+	u.Dialect = tableHintDialect{}
+	if u.lockCommand("SELECT uid FROM token WHERE uid=?") != "SELECT uid FROM token WITH (UPDLOCK,ROWLOCK) WHERE uid=?" {
+		t.Fatal(u.lockCommand("SELECT uid FROM token WHERE uid=?"))
+	}
+}
+
+type tableHintDialect struct {
+	MySQLDialect
+}
+
+func (d tableHintDialect) LockCommand(command string) string {
+	return strings.Replace(command, " WHERE ", " WITH (UPDLOCK,ROWLOCK) WHERE ", 1)
+}
@@ -0,0 +1,180 @@
+package sqluser
+
+import "strings"
+
+//AccountNormalizer normalize account to canonical form which logically duplicate accounts share.
+type AccountNormalizer func(account string) string
+
+//NormalizeEmail case-insensitive email normalizer.
+//Email is trimmed and lowercased.
+func NormalizeEmail(account string) string {
+	return strings.ToLower(strings.TrimSpace(account))
+}
+
+//NormalizeUsername trimmed username normalizer.
+func NormalizeUsername(account string) string {
+	return strings.TrimSpace(account)
+}
+
+//DuplicateAccounts logically duplicate accounts which share same normalized account.
+type DuplicateAccounts struct {
+	//Keyword account keyword.
+	Keyword string
+	//Normalized normalized account.
+	Normalized string
+	//Accounts duplicate accounts ordered by created time.
+	//First account is the primary account which will be kept when merging.
+	Accounts []AccountModel
+}
+
+//UIDs return distinct user ids of duplicate accounts,primary account user first.
+func (d *DuplicateAccounts) UIDs() []string {
+	result := []string{}
+	seen := map[string]bool{}
+	for _, v := range d.Accounts {
+		if !seen[v.UID] {
+			seen[v.UID] = true
+			result = append(result, v.UID)
+		}
+	}
+	return result
+}
+
+//DuplicateMergeResult result of merging duplicate accounts.
+type DuplicateMergeResult struct {
+	//UID user id which owns normalized account after merging.
+	UID string
+	//Account normalized account.
+	Account string
+	//Removed duplicate accounts removed.
+	Removed []AccountModel
+	//MergedUIDs user ids other than UID whose accounts were removed.
+	//Caller should merge or clean up data of these users.
+	MergedUIDs []string
+}
+
+func groupDuplicateAccounts(keyword string, accounts []AccountModel, normalizer AccountNormalizer) []*DuplicateAccounts {
+	groups := map[string]*DuplicateAccounts{}
+	order := []string{}
+	for _, v := range accounts {
+		normalized := normalizer(v.Account)
+		g, ok := groups[normalized]
+		if !ok {
+			g = &DuplicateAccounts{
+				Keyword:    keyword,
+				Normalized: normalized,
+			}
+			groups[normalized] = g
+			order = append(order, normalized)
+		}
+		g.Accounts = append(g.Accounts, v)
+	}
+	result := []*DuplicateAccounts{}
+	for _, v := range order {
+		if len(groups[v].Accounts) > 1 {
+			result = append(result, groups[v])
+		}
+	}
+	return result
+}
+
+//FindDuplicates scan accounts with given keyword in account table,
+//and report logically duplicate accounts under given normalizer.
+//Accounts are scanned in created time order,so that oldest account in every group is the primary account.
+//Return duplicate accounts and any error if raised.
+func (a *AccountMapper) FindDuplicates(keyword string, normalizer AccountNormalizer) ([]*DuplicateAccounts, error) {
+	if err := a.User.mustHaveFlag(FlagWithAccount); err != nil {
+		return nil, err
+	}
+	query := a.User.QueryBuilder
+	columns := a.User.Columns
+	Select := query.NewSelectQuery()
+	Select.Select.Add(columns.UID, columns.Account, columns.CreatedTime)
+	Select.From.Add(a.TableName())
+	Select.Where.Condition = query.Equal(columns.Keyword, keyword)
+	q := Select.Query()
+	cmd := q.QueryCommand() + " ORDER BY " + columns.CreatedTime + "," + columns.UID
+	rows, err := a.DB().Query(cmd, q.QueryArgs()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	accounts := []AccountModel{}
+	for rows.Next() {
+		v := AccountModel{Keyword: keyword}
+		err = rows.Scan(&v.UID, &v.Account, &v.CreatedTime)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, v)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+	return groupDuplicateAccounts(keyword, accounts, normalizer), nil
+}
+
+//MergeDuplicates merge duplicate accounts in one transaction.
+//Accounts other than primary account are removed,and primary account is renamed to normalized account.
+//Users of removed accounts are reported in result,their other data are not changed.
+//Member service caches should be cleaned by caller.
+//Return merge result and any error if raised.
+func (a *AccountMapper) MergeDuplicates(d *DuplicateAccounts) (*DuplicateMergeResult, error) {
+	if err := a.User.mustHaveFlag(FlagWithAccount); err != nil {
+		return nil, err
+	}
+	if len(d.Accounts) == 0 {
+		return nil, nil
+	}
+	query := a.User.QueryBuilder
+	columns := a.User.Columns
+	primary := d.Accounts[0]
+	result := &DuplicateMergeResult{
+		UID:        primary.UID,
+		Account:    d.Normalized,
+		Removed:    []AccountModel{},
+		MergedUIDs: []string{},
+	}
+	tx, err := a.DB().Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	merged := map[string]bool{}
+	for _, v := range d.Accounts[1:] {
+		Delete := query.NewDeleteQuery(a.TableName())
+		Delete.Where.Condition = query.And(
+			query.Equal(columns.UID, v.UID),
+			query.Equal(columns.Keyword, d.Keyword),
+			query.Equal(columns.Account, v.Account),
+		)
+		_, err = Delete.Query().Exec(tx)
+		if err != nil {
+			return nil, err
+		}
+		result.Removed = append(result.Removed, v)
+		if v.UID != primary.UID && !merged[v.UID] {
+			merged[v.UID] = true
+			result.MergedUIDs = append(result.MergedUIDs, v.UID)
+		}
+	}
+	if primary.Account != d.Normalized {
+		Update := query.NewUpdateQuery(a.TableName())
+		Update.Update.Add(columns.Account, d.Normalized)
+		Update.Where.Condition = query.And(
+			query.Equal(columns.UID, primary.UID),
+			query.Equal(columns.Keyword, d.Keyword),
+			query.Equal(columns.Account, primary.Account),
+		)
+		_, err = Update.Query().Exec(tx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
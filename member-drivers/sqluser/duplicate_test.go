@@ -0,0 +1,59 @@
+package sqluser
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/herb-go/user"
+)
+
+func TestGroupDuplicateAccounts(t *testing.T) {
+	accounts := []AccountModel{
+		AccountModel{UID: "1", Account: "User@Example.com"},
+		AccountModel{UID: "2", Account: "other@example.com"},
+		AccountModel{UID: "3", Account: " user@example.com"},
+		AccountModel{UID: "1", Account: "user@example.com"},
+	}
+	groups := groupDuplicateAccounts("email", accounts, NormalizeEmail)
+	if len(groups) != 1 || groups[0].Normalized != "user@example.com" || len(groups[0].Accounts) != 3 {
+		t.Fatal(groups)
+	}
+	uids := groups[0].UIDs()
+	if len(uids) != 2 || uids[0] != "1" || uids[1] != "3" {
+		t.Fatal(uids)
+	}
+	groups = groupDuplicateAccounts("name", accounts, NormalizeUsername)
+	if len(groups) != 0 {
+		t.Fatal(groups)
+	}
+}
+
+func TestMergeDuplicates(t *testing.T) {
+	var U = New(InitDB(), uidGenerator, FlagWithAccount)
+	var accounts = []string{"Dup@Example.com", "dup@example.com", "other@example.com"}
+	for k, v := range accounts {
+		err := U.Account().Insert("dup"+strconv.Itoa(k), accountype, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	groups, err := U.Account().FindDuplicates(accountype, NormalizeEmail)
+	if err != nil || len(groups) != 1 || len(groups[0].Accounts) != 2 {
+		t.Fatal(groups, err)
+	}
+	result, err := U.Account().MergeDuplicates(groups[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.UID != groups[0].Accounts[0].UID || len(result.Removed) != 1 || len(result.MergedUIDs) != 1 {
+		t.Fatal(result)
+	}
+	uid, err := U.Account().AccountToUID(&user.Account{Keyword: accountype, Account: "dup@example.com"})
+	if err != nil || uid != result.UID {
+		t.Fatal(uid, err)
+	}
+	groups, err = U.Account().FindDuplicates(accountype, NormalizeEmail)
+	if err != nil || len(groups) != 0 {
+		t.Fatal(groups, err)
+	}
+}
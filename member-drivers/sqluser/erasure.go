@@ -0,0 +1,160 @@
+package sqluser
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/herb-go/deprecated/member"
+)
+
+//ErasedAccountPrefix prefix of tombstone which replaces erased account.
+var ErasedAccountPrefix = "erased:"
+
+//ErasureReceipt receipt of user data erasure.
+type ErasureReceipt struct {
+	//UID user id erased.
+	UID string
+	//Accounts count of accounts scrubbed.
+	Accounts int64
+	//Passwords count of password rows wiped.
+	Passwords int64
+	//Tokens count of token rows wiped.
+	Tokens int64
+	//ErasedTime erasure timestamp in second.
+	ErasedTime int64
+}
+
+func erasedAccount() (string, error) {
+	bs := make([]byte, 16)
+	_, err := rand.Read(bs)
+	if err != nil {
+		return "", err
+	}
+	return ErasedAccountPrefix + hex.EncodeToString(bs), nil
+}
+
+//ErasureTableName return actual erasure receipt database table name.
+//Return empty string if erasure receipt table is not configured.
+func (u *User) ErasureTableName() string {
+	if u.Tables.ErasureMapperName == "" {
+		return ""
+	}
+	return u.DB.BuildTableName(u.Tables.ErasureMapperName)
+}
+
+//Anonymize irreversibly erase data of given user in one transaction.
+//Accounts are replaced by random tombstones and provenance is cleared,
+//password and token rows are deleted,and user status is set to member.StatusRevoked.
+//Modules disabled are skipped.
+//Erasure receipt will be recorded if erasure receipt table is configured.
+//Member service caches should be cleaned by caller.
+//Return erasure receipt and any error if raised.
+func (u *User) Anonymize(uid string) (*ErasureReceipt, error) {
+	query := u.QueryBuilder
+	columns := u.Columns
+	receipt := &ErasureReceipt{
+		UID:        uid,
+		ErasedTime: time.Now().Unix(),
+	}
+	tx, err := u.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if u.HasFlag(FlagWithAccount) {
+		Select := query.NewSelectQuery()
+		Select.Select.Add(columns.Keyword, columns.Account)
+		Select.From.Add(u.AccountTableName())
+		Select.Where.Condition = query.Equal(columns.UID, uid)
+		q := Select.Query()
+		rows, err := tx.Query(u.lockCommand(q.QueryCommand()), q.QueryArgs()...)
+		if err != nil {
+			return nil, err
+		}
+		accounts := []AccountModel{}
+		for rows.Next() {
+			v := AccountModel{UID: uid}
+			err = rows.Scan(&v.Keyword, &v.Account)
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+			accounts = append(accounts, v)
+		}
+		rows.Close()
+		for _, v := range accounts {
+			tombstone, err := erasedAccount()
+			if err != nil {
+				return nil, err
+			}
+			Update := query.NewUpdateQuery(u.AccountTableName())
+			Update.Update.Add(columns.Account, tombstone)
+			if columns.IP != "" {
+				Update.Update.Add(columns.IP, "")
+			}
+			if columns.Source != "" {
+				Update.Update.Add(columns.Source, "")
+			}
+			if columns.UserAgentHash != "" {
+				Update.Update.Add(columns.UserAgentHash, "")
+			}
+			Update.Where.Condition = query.And(
+				query.Equal(columns.UID, uid),
+				query.Equal(columns.Keyword, v.Keyword),
+				query.Equal(columns.Account, v.Account),
+			)
+			_, err = Update.Query().Exec(tx)
+			if err != nil {
+				return nil, err
+			}
+		}
+		receipt.Accounts = int64(len(accounts))
+	}
+	if u.HasFlag(FlagWithPassword) {
+		Delete := query.NewDeleteQuery(u.PasswordTableName())
+		Delete.Where.Condition = query.Equal(columns.UID, uid)
+		r, err := Delete.Query().Exec(tx)
+		if err != nil {
+			return nil, err
+		}
+		receipt.Passwords, err = r.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if u.HasFlag(FlagWithToken) {
+		Delete := query.NewDeleteQuery(u.TokenTableName())
+		Delete.Where.Condition = query.Equal(columns.UID, uid)
+		r, err := Delete.Query().Exec(tx)
+		if err != nil {
+			return nil, err
+		}
+		receipt.Tokens, err = r.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if u.HasFlag(FlagWithUser) {
+		Update := query.NewUpdateQuery(u.UserTableName())
+		Update.Update.
+			Add(columns.Status, member.StatusRevoked).
+			Add(columns.UpdatedTime, receipt.ErasedTime)
+		Update.Where.Condition = query.Equal(columns.UID, uid)
+		_, err = Update.Query().Exec(tx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if table := u.ErasureTableName(); table != "" {
+		Insert := query.NewInsertQuery(table)
+		Insert.Insert.
+			Add(columns.UID, uid).
+			Add(columns.CreatedTime, receipt.ErasedTime)
+		_, err = Insert.Query().Exec(tx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return receipt, tx.Commit()
+}
@@ -0,0 +1,112 @@
+package sqluser
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/herb-go/deprecated/member"
+	"github.com/herb-go/user"
+)
+
+func TestErasedAccount(t *testing.T) {
+	a, err := erasedAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := erasedAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(a, ErasedAccountPrefix) || len(a) != len(ErasedAccountPrefix)+32 || a == b {
+		t.Fatal(a, b)
+	}
+}
+
+func TestAnonymize(t *testing.T) {
+	u := NewWithPrefix(InitDB(), uidGenerator, FlagWithAccount|FlagWithPassword|FlagWithToken|FlagWithUser, "erasure_")
+	u.Columns.IP = "ip"
+	u.Columns.Source = "source"
+	u.Columns.UserAgentHash = "user_agent_hash"
+	u.Tables.ErasureMapperName = "erasure_receipt"
+	initSchemaTestDB(u)
+	account, err := user.CaseSensitiveAcountProvider.NewAccount(accountype, "erasureaccount")
+	if err != nil {
+		panic(err)
+	}
+	uid, err := u.Account().RegisterWithProvenance(account, &Provenance{IP: "127.0.0.1", Source: "web", UserAgent: "Mozilla/5.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = u.Password().UpdatePassword(uid, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = u.Token().InsertOrUpdate(uid, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = u.User().InsertOrUpdate(uid, member.StatusNormal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receipt, err := u.Anonymize(uid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.UID != uid || receipt.Accounts != 1 || receipt.Passwords != 1 || receipt.Tokens != 1 || receipt.ErasedTime == 0 {
+		t.Fatal(receipt)
+	}
+	_, err = u.Account().Find(account.Keyword, account.Account)
+	if err != sql.ErrNoRows {
+		t.Fatal(err)
+	}
+	accounts, err := u.Account().FindAllByUID(uid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 1 || accounts[0].Keyword != account.Keyword || !strings.HasPrefix(accounts[0].Account, ErasedAccountPrefix) {
+		t.Fatal(accounts)
+	}
+	tombstone, err := user.CaseSensitiveAcountProvider.NewAccount(accountype, accounts[0].Account)
+	if err != nil {
+		panic(err)
+	}
+	p, err := u.Account().FindProvenance(tombstone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.UID != uid || p.IP != "" || p.Source != "" || p.UserAgentHash != "" {
+		t.Fatal(p)
+	}
+	_, err = u.Password().Find(uid)
+	if err != sql.ErrNoRows {
+		t.Fatal(err)
+	}
+	tokens, err := u.Token().FindAllByUID(uid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 0 {
+		t.Fatal(tokens)
+	}
+	statuses, err := u.User().Statuses(uid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statuses[uid] != member.StatusRevoked {
+		t.Fatal(statuses)
+	}
+	Select := u.QueryBuilder.NewSelectQuery()
+	Select.Select.Add("COUNT(*)")
+	Select.From.Add(u.ErasureTableName())
+	Select.Where.Condition = u.QueryBuilder.Equal(u.Columns.UID, uid)
+	var count int64
+	err = Select.QueryRow(u.DB).Scan(&count)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatal(count)
+	}
+}
@@ -0,0 +1,115 @@
+package sqluser
+
+import (
+	"errors"
+	"time"
+
+	"github.com/herb-go/deprecated/member"
+)
+
+//ErrExpiryDisabled error raised when account expiry column is not configured.
+var ErrExpiryDisabled = errors.New("sqluser account expiry disabled")
+
+func (u *UserMapper) mustHaveExpiry() error {
+	if err := u.User.mustHaveFlag(FlagWithUser); err != nil {
+		return err
+	}
+	if u.User.Columns.ExpiredTime == "" {
+		return ErrExpiryDisabled
+	}
+	return nil
+}
+
+//SetExpiry set user expiry time.
+//Zero time means user never expires.
+//Return any error if raised.
+func (u *UserMapper) SetExpiry(uid string, expired time.Time) error {
+	if err := u.mustHaveExpiry(); err != nil {
+		return err
+	}
+	query := u.User.QueryBuilder
+	columns := u.User.Columns
+	var ts int64
+	if !expired.IsZero() {
+		ts = expired.Unix()
+	}
+	Update := query.NewUpdateQuery(u.TableName())
+	Update.Update.
+		Add(columns.ExpiredTime, ts).
+		Add(columns.UpdatedTime, time.Now().Unix())
+	Update.Where.Condition = query.Equal(columns.UID, uid)
+	_, err := Update.Query().Exec(u.DB())
+	return err
+}
+
+//Expiries return expiry time map of given uid list.
+//Users never expire will not be included.
+//Return expiry map and any error if raised.
+func (u *UserMapper) Expiries(uid ...string) (map[string]time.Time, error) {
+	if err := u.mustHaveExpiry(); err != nil {
+		return nil, err
+	}
+	models, err := u.FindAllByUID(uid...)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]time.Time{}
+	for _, v := range models {
+		if v.ExpiredTime > 0 {
+			result[v.UID] = time.Unix(v.ExpiredTime, 0)
+		}
+	}
+	return result, nil
+}
+
+//ExpireUsers update status of normal users expired before given time to member.StatusExpired.
+//Return uid list of users updated and any error if raised.
+func (u *UserMapper) ExpireUsers(now time.Time) ([]string, error) {
+	if err := u.mustHaveExpiry(); err != nil {
+		return nil, err
+	}
+	query := u.User.QueryBuilder
+	columns := u.User.Columns
+	tx, err := u.DB().Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	Select := query.NewSelectQuery()
+	Select.Select.Add(columns.UID)
+	Select.From.Add(u.TableName())
+	Select.Where.Condition = query.And(
+		query.Equal(columns.Status, member.StatusNormal),
+		query.New(columns.ExpiredTime+" > 0"),
+		query.New(columns.ExpiredTime+" <= ?", now.Unix()),
+	)
+	q := Select.Query()
+	rows, err := tx.Query(u.User.lockCommand(q.QueryCommand()), q.QueryArgs()...)
+	if err != nil {
+		return nil, err
+	}
+	uids := []string{}
+	for rows.Next() {
+		var uid string
+		err = rows.Scan(&uid)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		uids = append(uids, uid)
+	}
+	rows.Close()
+	if len(uids) == 0 {
+		return uids, nil
+	}
+	Update := query.NewUpdateQuery(u.TableName())
+	Update.Update.
+		Add(columns.Status, member.StatusExpired).
+		Add(columns.UpdatedTime, now.Unix())
+	Update.Where.Condition = query.In(columns.UID, uids)
+	_, err = Update.Query().Exec(tx)
+	if err != nil {
+		return nil, err
+	}
+	return uids, tx.Commit()
+}
@@ -0,0 +1,149 @@
+package sqluser
+
+import (
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/member"
+)
+
+func TestExpiry(t *testing.T) {
+	u := NewWithPrefix(InitDB(), uidGenerator, FlagWithUser, "expiry_")
+	u.Columns.ExpiredTime = "expired_time"
+	initSchemaTestDB(u)
+	users := u.User()
+	expired, err := uidGenerator()
+	if err != nil {
+		panic(err)
+	}
+	active, err := uidGenerator()
+	if err != nil {
+		panic(err)
+	}
+	banned, err := uidGenerator()
+	if err != nil {
+		panic(err)
+	}
+	for _, v := range []string{expired, active} {
+		err = users.InsertOrUpdate(v, member.StatusNormal)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = users.InsertOrUpdate(banned, member.StatusBanned)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expiries, err := users.Expiries(expired, active, banned)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expiries) != 0 {
+		t.Fatal(expiries)
+	}
+	past := time.Unix(time.Now().Add(-time.Hour).Unix(), 0)
+	future := time.Unix(time.Now().Add(time.Hour).Unix(), 0)
+	for uid, v := range map[string]time.Time{expired: past, active: future, banned: past} {
+		err = users.SetExpiry(uid, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	expiries, err = users.Expiries(expired, active, banned)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expiries) != 3 || !expiries[expired].Equal(past) || !expiries[active].Equal(future) {
+		t.Fatal(expiries)
+	}
+	statuses, err := users.Statuses(expired, active, banned)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statuses[expired] != member.StatusExpired || statuses[active] != member.StatusNormal || statuses[banned] != member.StatusBanned {
+		t.Fatal(statuses)
+	}
+	models, err := users.FindAllByUID(expired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(models) != 1 || member.Status(models[0].Status) != member.StatusNormal || models[0].ExpiredTime != past.Unix() {
+		t.Fatal(models)
+	}
+	uids, err := users.ExpireUsers(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(uids) != 1 || uids[0] != expired {
+		t.Fatal(uids)
+	}
+	models, err = users.FindAllByUID(expired, banned)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range models {
+		if v.UID == expired && member.Status(v.Status) != member.StatusExpired {
+			t.Fatal(v)
+		}
+		if v.UID == banned && member.Status(v.Status) != member.StatusBanned {
+			t.Fatal(v)
+		}
+	}
+	uids, err = users.ExpireUsers(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(uids) != 0 {
+		t.Fatal(uids)
+	}
+	err = users.SetExpiry(active, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expiries, err = users.Expiries(active)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expiries) != 0 {
+		t.Fatal(expiries)
+	}
+}
+
+func TestExpiryDisabled(t *testing.T) {
+	u := New(InitDB(), uidGenerator, FlagWithUser)
+	users := u.User()
+	uid, err := uidGenerator()
+	if err != nil {
+		panic(err)
+	}
+	err = users.InsertOrUpdate(uid, member.StatusNormal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = users.SetExpiry(uid, time.Now())
+	if err != ErrExpiryDisabled {
+		t.Fatal(err)
+	}
+	_, err = users.Expiries(uid)
+	if err != ErrExpiryDisabled {
+		t.Fatal(err)
+	}
+	_, err = users.ExpireUsers(time.Now())
+	if err != ErrExpiryDisabled {
+		t.Fatal(err)
+	}
+	models, err := users.FindAllByUID(uid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(models) != 1 || member.Status(models[0].Status) != member.StatusNormal || models[0].ExpiredTime != 0 {
+		t.Fatal(models)
+	}
+	statuses, err := users.Statuses(uid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statuses[uid] != member.StatusNormal {
+		t.Fatal(statuses)
+	}
+}
@@ -0,0 +1,85 @@
+package sqluser
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+
+	"github.com/herb-go/deprecated/member"
+)
+
+//ErrIDObfuscationKeyRequired error raised when id obfuscation key is empty.
+var ErrIDObfuscationKeyRequired = errors.New("sqluser id obfuscation key required")
+
+//ivSize size of synthetic iv in external id.
+const ivSize = aes.BlockSize
+
+//IDObfuscator deterministic reversible user id encoder in AES-SIV style.
+//Synthetic iv is computed by HMAC-SHA256 of user id,and user id is encrypted by AES-CTR with synthetic iv.
+//Same user id is always encoded to same external id,and tampered external ids are rejected when decoding.
+type IDObfuscator struct {
+	macKey []byte
+	block  cipher.Block
+}
+
+func deriveKey(key []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+//NewIDObfuscator create new id obfuscator with given secret key.
+//Mac key and encryption key are derived from secret key.
+//Return id obfuscator and any error if raised.
+func NewIDObfuscator(key []byte) (*IDObfuscator, error) {
+	if len(key) == 0 {
+		return nil, ErrIDObfuscationKeyRequired
+	}
+	block, err := aes.NewCipher(deriveKey(key, "sqluser-id-encryption"))
+	if err != nil {
+		return nil, err
+	}
+	return &IDObfuscator{
+		macKey: deriveKey(key, "sqluser-id-mac"),
+		block:  block,
+	}, nil
+}
+
+func (o *IDObfuscator) iv(uid []byte) []byte {
+	mac := hmac.New(sha256.New, o.macKey)
+	mac.Write(uid)
+	return mac.Sum(nil)[:ivSize]
+}
+
+//EncodeID encode user id to url safe external id.
+//Return external id and any error if raised.
+func (o *IDObfuscator) EncodeID(uid string) (string, error) {
+	data := []byte(uid)
+	iv := o.iv(data)
+	result := make([]byte, ivSize+len(data))
+	copy(result, iv)
+	cipher.NewCTR(o.block, iv).XORKeyStream(result[ivSize:], data)
+	return base64.RawURLEncoding.EncodeToString(result), nil
+}
+
+//DecodeID decode external id to user id.
+//Return user id and any error if raised.
+//Return member.ErrInvalidExternalID if external id is malformed or tampered.
+func (o *IDObfuscator) DecodeID(id string) (string, error) {
+	bs, err := base64.RawURLEncoding.DecodeString(id)
+	if err != nil || len(bs) < ivSize {
+		return "", member.ErrInvalidExternalID
+	}
+	iv := bs[:ivSize]
+	data := make([]byte, len(bs)-ivSize)
+	cipher.NewCTR(o.block, iv).XORKeyStream(data, bs[ivSize:])
+	if !hmac.Equal(iv, o.iv(data)) {
+		return "", member.ErrInvalidExternalID
+	}
+	return string(data), nil
+}
+
+var _ member.IDEncoder = &IDObfuscator{}
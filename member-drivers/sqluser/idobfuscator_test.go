@@ -0,0 +1,56 @@
+package sqluser
+
+import (
+	"testing"
+
+	"github.com/herb-go/deprecated/member"
+)
+
+func TestIDObfuscator(t *testing.T) {
+	_, err := NewIDObfuscator(nil)
+	if err != ErrIDObfuscationKeyRequired {
+		t.Fatal(err)
+	}
+	o, err := NewIDObfuscator([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := o.EncodeID("12345")
+	if err != nil || id == "12345" {
+		t.Fatal(id, err)
+	}
+	id2, err := o.EncodeID("12345")
+	if err != nil || id2 != id {
+		t.Fatal(id2, err)
+	}
+	uid, err := o.DecodeID(id)
+	if err != nil || uid != "12345" {
+		t.Fatal(uid, err)
+	}
+	other, err := o.EncodeID("12346")
+	if err != nil || other == id {
+		t.Fatal(other, err)
+	}
+	tampered := []byte(id)
+	if tampered[len(tampered)-1] == 'A' {
+		tampered[len(tampered)-1] = 'B'
+	} else {
+		tampered[len(tampered)-1] = 'A'
+	}
+	_, err = o.DecodeID(string(tampered))
+	if err != member.ErrInvalidExternalID {
+		t.Fatal(err)
+	}
+	_, err = o.DecodeID("!")
+	if err != member.ErrInvalidExternalID {
+		t.Fatal(err)
+	}
+	o2, err := NewIDObfuscator([]byte("other"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = o2.DecodeID(id)
+	if err != member.ErrInvalidExternalID {
+		t.Fatal(err)
+	}
+}
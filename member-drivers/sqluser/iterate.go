@@ -0,0 +1,72 @@
+package sqluser
+
+import "strconv"
+
+//DefaultIterateBatchSize default batch size used by UserMapper.Iterate.
+var DefaultIterateBatchSize = 1000
+
+//Iterate page through all users in user table ordered by uid,and call fn with every batch.
+//Keyset pagination is used,so that rows are not skipped when earlier pages are updated by fn.
+//DefaultIterateBatchSize will be used if batchSize is not positive.
+//Iteration stops if fn returns any error.
+//Return any error if raised.
+func (u *UserMapper) Iterate(batchSize int, fn func([]UserModel) error) error {
+	if !u.User.HasFlag(FlagWithUser) {
+		return nil
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultIterateBatchSize
+	}
+	query := u.User.QueryBuilder
+	columns := u.User.Columns
+	var last string
+	var started bool
+	for {
+		Select := query.NewSelectQuery()
+		Select.Select.Add(columns.UID, columns.Status)
+		if columns.ExpiredTime != "" {
+			Select.Select.Add(columns.ExpiredTime)
+		}
+		Select.From.Add(u.TableName())
+		if started {
+			Select.Where.Condition = query.New(columns.UID+" > ?", last)
+		}
+		q := Select.Query()
+		cmd := q.QueryCommand() + " ORDER BY " + columns.UID + " LIMIT " + strconv.Itoa(batchSize)
+		rows, err := u.DB().Query(cmd, q.QueryArgs()...)
+		if err != nil {
+			return err
+		}
+		result := make([]UserModel, 0, batchSize)
+		for rows.Next() {
+			v := UserModel{}
+			if columns.ExpiredTime != "" {
+				err = rows.Scan(&v.UID, &v.Status, &v.ExpiredTime)
+			} else {
+				err = rows.Scan(&v.UID, &v.Status)
+			}
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			result = append(result, v)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return err
+		}
+		if len(result) == 0 {
+			return nil
+		}
+		err = fn(result)
+		if err != nil {
+			return err
+		}
+		if len(result) < batchSize {
+			return nil
+		}
+		last = result[len(result)-1].UID
+		started = true
+	}
+}
@@ -0,0 +1,38 @@
+package sqluser
+
+import (
+	"testing"
+
+	"github.com/herb-go/deprecated/member"
+)
+
+func TestIterate(t *testing.T) {
+	var U = New(InitDB(), uidGenerator, FlagWithUser)
+	var uids = []string{"iterate1", "iterate2", "iterate3", "iterate4", "iterate5"}
+	for _, v := range uids {
+		err := U.User().InsertOrUpdate(v, member.StatusNormal)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	var result = []string{}
+	var batches = 0
+	err := U.User().Iterate(2, func(users []UserModel) error {
+		batches++
+		for _, v := range users {
+			result = append(result, v.UID)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if batches != 3 || len(result) != len(uids) {
+		t.Fatal(batches, result)
+	}
+	for k := range uids {
+		if result[k] != uids[k] {
+			t.Fatal(result)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package sqluser
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+//FlagAll sql user create flag with all modules
+const FlagAll = FlagWithAccount | FlagWithPassword | FlagWithToken | FlagWithUser
+
+//ErrModuleDisabled error raised when writing data to disabled module.
+var ErrModuleDisabled = errors.New("sqluser module disabled")
+
+//ModuleDependencies modules which given module depends on.
+//Password and token data are stored by uid,which is registered in account module and has status in user module.
+var ModuleDependencies = map[int]int{
+	FlagWithPassword: FlagWithAccount | FlagWithUser,
+	FlagWithToken:    FlagWithAccount | FlagWithUser,
+}
+
+//ModuleDependencyError error raised when module changing breaks module dependencies.
+type ModuleDependencyError struct {
+	//Module flag of module which depends on disabled modules.
+	Module int
+	//Missing flag of dependencies disabled.
+	Missing int
+}
+
+func flagNames(flag int) string {
+	names := []string{}
+	for k, v := range FlagNames {
+		if flag&v != 0 {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+//Error return error message.
+func (e *ModuleDependencyError) Error() string {
+	return fmt.Sprintf("sqluser module %s depends on disabled module %s", flagNames(e.Module), flagNames(e.Missing))
+}
+
+//checkModuleDependencies check if modules enabled in flag depend on disabled modules.
+//Only dependencies relating to changed modules are checked,so that modules created with broken dependencies can still be changed.
+func checkModuleDependencies(flag int, changed int) error {
+	for _, module := range []int{FlagWithAccount, FlagWithPassword, FlagWithToken, FlagWithUser} {
+		if flag&module == 0 {
+			continue
+		}
+		missing := ModuleDependencies[module] &^ flag
+		if missing != 0 && (module&changed != 0 || missing&changed != 0) {
+			return &ModuleDependencyError{Module: module, Missing: missing}
+		}
+	}
+	return nil
+}
+
+//EnableModule enable modules by given flag at runtime.
+//Return ErrUnknownFlag if flag contains unknown module.
+//Return *ModuleDependencyError if module enabled depends on disabled modules.
+func (u *User) EnableModule(flag int) error {
+	if flag&^FlagAll != 0 {
+		return ErrUnknownFlag
+	}
+	u.flagLocker.Lock()
+	defer u.flagLocker.Unlock()
+	result := u.Flag | flag
+	err := checkModuleDependencies(result, flag)
+	if err != nil {
+		return err
+	}
+	u.Flag = result
+	return nil
+}
+
+//DisableModule disable modules by given flag at runtime.
+//Disabled module acts as empty module:reading returns no data and writing returns ErrModuleDisabled.
+//Return ErrUnknownFlag if flag contains unknown module.
+//Return *ModuleDependencyError if enabled modules depend on module disabled.
+func (u *User) DisableModule(flag int) error {
+	if flag&^FlagAll != 0 {
+		return ErrUnknownFlag
+	}
+	u.flagLocker.Lock()
+	defer u.flagLocker.Unlock()
+	result := u.Flag &^ flag
+	err := checkModuleDependencies(result, flag)
+	if err != nil {
+		return err
+	}
+	u.Flag = result
+	return nil
+}
+
+func (u *User) mustHaveFlag(flag int) error {
+	if !u.HasFlag(flag) {
+		return ErrModuleDisabled
+	}
+	return nil
+}
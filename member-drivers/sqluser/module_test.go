@@ -0,0 +1,69 @@
+package sqluser
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestModule(t *testing.T) {
+	u := &User{Flag: FlagWithAccount | FlagWithUser | FlagWithPassword}
+	if u.HasFlag(FlagWithToken) || u.mustHaveFlag(FlagWithToken) != ErrModuleDisabled {
+		t.Fatal(u.HasFlag(FlagWithToken))
+	}
+	err := u.EnableModule(FlagWithToken)
+	if err != nil || !u.HasFlag(FlagWithToken) || u.mustHaveFlag(FlagWithToken) != nil {
+		t.Fatal(err)
+	}
+	err = u.DisableModule(FlagWithPassword)
+	if err != nil || u.HasFlag(FlagWithPassword) || !u.HasFlag(FlagWithAccount) {
+		t.Fatal(err)
+	}
+	if u.EnableModule(16) != ErrUnknownFlag || u.DisableModule(16) != ErrUnknownFlag {
+		t.Fatal(u.HasFlag(16))
+	}
+}
+
+func TestModuleDependencies(t *testing.T) {
+	u := &User{Flag: FlagAll}
+	var dependencyErr *ModuleDependencyError
+	for _, flag := range []int{FlagWithAccount, FlagWithUser} {
+		err := u.DisableModule(flag)
+		if !errors.As(err, &dependencyErr) || dependencyErr.Module != FlagWithPassword || dependencyErr.Missing != flag {
+			t.Fatal(err)
+		}
+		if !u.HasFlag(flag) {
+			t.Fatal(flag)
+		}
+	}
+	err := u.DisableModule(FlagWithPassword | FlagWithToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = u.DisableModule(FlagWithAccount | FlagWithUser)
+	if err != nil || u.HasFlag(FlagAll) {
+		t.Fatal(err)
+	}
+	err = u.EnableModule(FlagWithToken)
+	if !errors.As(err, &dependencyErr) || dependencyErr.Module != FlagWithToken || dependencyErr.Missing != FlagWithAccount|FlagWithUser {
+		t.Fatal(err)
+	}
+	if dependencyErr.Error() != "sqluser module token depends on disabled module account,user" {
+		t.Fatal(dependencyErr.Error())
+	}
+	if u.HasFlag(FlagWithToken) {
+		t.Fatal(err)
+	}
+	err = u.EnableModule(FlagWithAccount | FlagWithUser | FlagWithToken)
+	if err != nil || !u.HasFlag(FlagWithToken) {
+		t.Fatal(err)
+	}
+	u = &User{Flag: FlagWithAccount | FlagWithPassword}
+	err = u.DisableModule(FlagWithAccount)
+	if !errors.As(err, &dependencyErr) || dependencyErr.Module != FlagWithPassword {
+		t.Fatal(err)
+	}
+	err = u.EnableModule(FlagWithToken)
+	if !errors.As(err, &dependencyErr) || dependencyErr.Module != FlagWithToken || dependencyErr.Missing != FlagWithUser {
+		t.Fatal(err)
+	}
+}
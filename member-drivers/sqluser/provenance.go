@@ -0,0 +1,108 @@
+package sqluser
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+
+	"github.com/herb-go/datasource/sql/querybuilder"
+	"github.com/herb-go/user"
+)
+
+//Provenance account provenance data stored with account for fraud analysis.
+type Provenance struct {
+	//IP registration ip.
+	IP string
+	//Source registration source or channel.
+	Source string
+	//UserAgent registration user agent.
+	//Only hash of user agent will be stored.
+	UserAgent string
+}
+
+//HashUserAgent return hex encoded sha256 hash of given user agent.
+//Return empty string if user agent is empty.
+func HashUserAgent(useragent string) string {
+	if useragent == "" {
+		return ""
+	}
+	s256 := sha256.Sum256([]byte(useragent))
+	return hex.EncodeToString(s256[:])
+}
+
+func (a *AccountMapper) addProvenance(Insert *querybuilder.InsertQuery, p *Provenance) {
+	if p == nil {
+		return
+	}
+	columns := a.User.Columns
+	if columns.IP != "" {
+		Insert.Insert.Add(columns.IP, p.IP)
+	}
+	if columns.Source != "" {
+		Insert.Insert.Add(columns.Source, p.Source)
+	}
+	if columns.UserAgentHash != "" {
+		Insert.Insert.Add(columns.UserAgentHash, HashUserAgent(p.UserAgent))
+	}
+}
+
+//ProvenanceModel account provenance data model.
+type ProvenanceModel struct {
+	//UID user id.
+	UID string
+	//IP registration ip.
+	IP string
+	//Source registration source or channel.
+	Source string
+	//UserAgentHash hash of registration user agent.
+	UserAgentHash string
+}
+
+//FindProvenance find provenance of given account.
+//Provenance columns not configured or null will be empty.
+//Return provenance model and any error if raised.
+//Return sql.ErrNoRows if account not found.
+func (a *AccountMapper) FindProvenance(account *user.Account) (*ProvenanceModel, error) {
+	query := a.User.QueryBuilder
+	columns := a.User.Columns
+	if !a.User.HasFlag(FlagWithAccount) {
+		return nil, sql.ErrNoRows
+	}
+	result := &ProvenanceModel{}
+	Select := query.NewSelectQuery()
+	Select.Select.Add(columns.UID)
+	if columns.IP != "" {
+		Select.Select.Add(columns.IP)
+	}
+	if columns.Source != "" {
+		Select.Select.Add(columns.Source)
+	}
+	if columns.UserAgentHash != "" {
+		Select.Select.Add(columns.UserAgentHash)
+	}
+	Select.From.Add(a.TableName())
+	Select.Where.Condition = query.And(
+		query.Equal(columns.Keyword, account.Keyword),
+		query.Equal(columns.Account, account.Account),
+	)
+	row := Select.QueryRow(a.DB())
+	var ip, source, useragenthash sql.NullString
+	r := Select.Result().Bind(columns.UID, &result.UID)
+	if columns.IP != "" {
+		r = r.Bind(columns.IP, &ip)
+	}
+	if columns.Source != "" {
+		r = r.Bind(columns.Source, &source)
+	}
+	if columns.UserAgentHash != "" {
+		r = r.Bind(columns.UserAgentHash, &useragenthash)
+	}
+	err := r.ScanFrom(row)
+	if err != nil {
+		return nil, err
+	}
+	result.IP = ip.String
+	result.Source = source.String
+	result.UserAgentHash = useragenthash.String
+	return result, nil
+}
@@ -0,0 +1,79 @@
+package sqluser
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/herb-go/user"
+)
+
+func TestHashUserAgent(t *testing.T) {
+	if HashUserAgent("") != "" {
+		t.Fatal(HashUserAgent(""))
+	}
+	h := HashUserAgent("Mozilla/5.0")
+	if len(h) != 64 || h != HashUserAgent("Mozilla/5.0") || h == HashUserAgent("curl/7.0") {
+		t.Fatal(h)
+	}
+}
+
+func TestProvenance(t *testing.T) {
+	u := NewWithPrefix(InitDB(), uidGenerator, FlagWithAccount, "provenance_")
+	u.Columns.IP = "ip"
+	u.Columns.Source = "source"
+	u.Columns.UserAgentHash = "user_agent_hash"
+	initSchemaTestDB(u)
+	registered, err := user.CaseSensitiveAcountProvider.NewAccount(accountype, "registered")
+	if err != nil {
+		panic(err)
+	}
+	bound, err := user.CaseSensitiveAcountProvider.NewAccount(accountype, "bound")
+	if err != nil {
+		panic(err)
+	}
+	plain, err := user.CaseSensitiveAcountProvider.NewAccount(accountype, "plain")
+	if err != nil {
+		panic(err)
+	}
+	uid, err := u.Account().RegisterWithProvenance(registered, &Provenance{IP: "127.0.0.1", Source: "web", UserAgent: "Mozilla/5.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = u.Account().BindWithProvenance(uid, bound, &Provenance{IP: "::1", Source: "app", UserAgent: "curl/7.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = u.Account().BindWithProvenance(uid, plain, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := u.Account().FindProvenance(registered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.UID != uid || p.IP != "127.0.0.1" || p.Source != "web" || p.UserAgentHash != HashUserAgent("Mozilla/5.0") {
+		t.Fatal(p)
+	}
+	p, err = u.Account().FindProvenance(bound)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.UID != uid || p.IP != "::1" || p.Source != "app" || p.UserAgentHash != HashUserAgent("curl/7.0") {
+		t.Fatal(p)
+	}
+	p, err = u.Account().FindProvenance(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.UID != uid || p.IP != "" || p.Source != "" || p.UserAgentHash != "" {
+		t.Fatal(p)
+	}
+	notexist, err := user.CaseSensitiveAcountProvider.NewAccount(accountype, "notexist")
+	if err != nil {
+		panic(err)
+	}
+	_, err = u.Account().FindProvenance(notexist)
+	if err != sql.ErrNoRows {
+		t.Fatal(err)
+	}
+}
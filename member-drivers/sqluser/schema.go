@@ -0,0 +1,182 @@
+package sqluser
+
+import "strings"
+
+//ModuleErasure module name of erasure receipt table used in table options.
+const ModuleErasure = "erasure"
+
+//TableOptions mysql table options used by generated DDL.
+//Empty fields will not override default options.
+type TableOptions struct {
+	//Engine table engine.
+	Engine string
+	//Charset default character set of table.
+	Charset string
+	//Collation default collation of table.
+	Collation string
+	//BinaryCollation collation of case-sensitive columns,like account,password and token.
+	BinaryCollation string
+	//RowFormat table row format.
+	//Server default row format will be used if empty.
+	RowFormat string
+}
+
+//DefaultTableOptions return default table options.
+//Accounts are case-sensitive with default options,
+//use account provider which normalizes account to make lookups case-insensitive.
+func DefaultTableOptions() *TableOptions {
+	return &TableOptions{
+		Engine:          "InnoDB",
+		Charset:         "utf8mb4",
+		Collation:       "utf8mb4_general_ci",
+		BinaryCollation: "utf8mb4_bin",
+	}
+}
+
+//Merge return new table options which fields are overridden by non-empty fields of given options.
+func (o *TableOptions) Merge(options *TableOptions) *TableOptions {
+	result := *o
+	if options == nil {
+		return &result
+	}
+	if options.Engine != "" {
+		result.Engine = options.Engine
+	}
+	if options.Charset != "" {
+		result.Charset = options.Charset
+	}
+	if options.Collation != "" {
+		result.Collation = options.Collation
+	}
+	if options.BinaryCollation != "" {
+		result.BinaryCollation = options.BinaryCollation
+	}
+	if options.RowFormat != "" {
+		result.RowFormat = options.RowFormat
+	}
+	return &result
+}
+
+//Clause return table options clause appended to create table command.
+func (o *TableOptions) Clause() string {
+	clause := ""
+	if o.Engine != "" {
+		clause = clause + " ENGINE=" + o.Engine
+	}
+	if o.Charset != "" {
+		clause = clause + " DEFAULT CHARACTER SET " + o.Charset
+	}
+	if o.Collation != "" {
+		clause = clause + " COLLATE " + o.Collation
+	}
+	if o.RowFormat != "" {
+		clause = clause + " ROW_FORMAT=" + o.RowFormat
+	}
+	return clause
+}
+
+func (o *TableOptions) binary() string {
+	clause := ""
+	if o.Charset != "" {
+		clause = clause + " CHARACTER SET " + o.Charset
+	}
+	if o.BinaryCollation != "" {
+		clause = clause + " COLLATE " + o.BinaryCollation
+	}
+	return clause
+}
+
+//ModuleTableOptions return table options of given module name.
+//Module options override TableOptions,which override DefaultTableOptions.
+func (u *User) ModuleTableOptions(module string) *TableOptions {
+	o := DefaultTableOptions().Merge(u.TableOptions)
+	if u.ModulesTableOptions != nil {
+		o = o.Merge(u.ModulesTableOptions[module])
+	}
+	return o
+}
+
+func createTableCommand(table string, columns []string, keys []string, o *TableOptions) string {
+	lines := append(columns, keys...)
+	return "CREATE TABLE " + table + "(\n    " + strings.Join(lines, ",\n    ") + "\n)" + o.Clause() + ";"
+}
+
+//CreateTableCommands return mysql compatible create table commands of enabled modules
+//with configured column names and table options.
+//Erasure receipt table will be included if configured.
+func (u *User) CreateTableCommands() []string {
+	columns := u.Columns
+	result := []string{}
+	if u.HasFlag(FlagWithAccount) {
+		o := u.ModuleTableOptions("account")
+		c := []string{
+			columns.UID + " VARCHAR(255) NOT NULL",
+			columns.Keyword + " VARCHAR(255) NOT NULL",
+			columns.Account + " VARCHAR(255)" + o.binary() + " NOT NULL",
+			columns.CreatedTime + " BIGINT NOT NULL",
+		}
+		for _, v := range []string{columns.IP, columns.Source, columns.UserAgentHash} {
+			if v != "" {
+				c = append(c, v+" VARCHAR(255)")
+			}
+		}
+		keys := []string{
+			"PRIMARY KEY(" + columns.Keyword + "," + columns.Account + ")",
+			"INDEX(" + columns.UID + ")",
+			"INDEX(" + columns.CreatedTime + "," + columns.UID + ")",
+		}
+		result = append(result, createTableCommand(u.AccountTableName(), c, keys, o))
+	}
+	if u.HasFlag(FlagWithPassword) {
+		o := u.ModuleTableOptions("password")
+		c := []string{
+			columns.UID + " VARCHAR(255) NOT NULL",
+			columns.HashMethod + " VARCHAR(255)",
+			columns.Salt + " VARCHAR(255)" + o.binary() + " NOT NULL",
+			columns.Password + " VARCHAR(255)" + o.binary() + " NOT NULL",
+			columns.UpdatedTime + " BIGINT NOT NULL",
+		}
+		keys := []string{"PRIMARY KEY(" + columns.UID + ")"}
+		result = append(result, createTableCommand(u.PasswordTableName(), c, keys, o))
+	}
+	if u.HasFlag(FlagWithToken) {
+		o := u.ModuleTableOptions("token")
+		c := []string{
+			columns.UID + " VARCHAR(255) NOT NULL",
+			columns.UpdatedTime + " BIGINT NOT NULL",
+			columns.Token + " VARCHAR(255)" + o.binary(),
+		}
+		keys := []string{"PRIMARY KEY(" + columns.UID + ")"}
+		result = append(result, createTableCommand(u.TokenTableName(), c, keys, o))
+	}
+	if u.HasFlag(FlagWithUser) {
+		o := u.ModuleTableOptions("user")
+		c := []string{
+			columns.UID + " VARCHAR(255) NOT NULL",
+			columns.CreatedTime + " BIGINT NOT NULL",
+			columns.UpdatedTime + " BIGINT NOT NULL",
+			columns.Status + " INT NOT NULL",
+		}
+		if columns.ExpiredTime != "" {
+			c = append(c, columns.ExpiredTime+" BIGINT NOT NULL DEFAULT 0")
+		}
+		keys := []string{
+			"PRIMARY KEY(" + columns.UID + ")",
+			"INDEX(" + columns.CreatedTime + "," + columns.UID + ")",
+		}
+		result = append(result, createTableCommand(u.UserTableName(), c, keys, o))
+	}
+	if table := u.ErasureTableName(); table != "" {
+		o := u.ModuleTableOptions(ModuleErasure)
+		c := []string{
+			columns.UID + " VARCHAR(255) NOT NULL",
+			columns.CreatedTime + " BIGINT NOT NULL",
+		}
+		keys := []string{
+			"INDEX(" + columns.UID + ")",
+			"INDEX(" + columns.CreatedTime + ")",
+		}
+		result = append(result, createTableCommand(table, c, keys, o))
+	}
+	return result
+}
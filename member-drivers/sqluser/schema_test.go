@@ -0,0 +1,82 @@
+package sqluser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/herb-go/datasource/sql/db"
+)
+
+func TestTableOptions(t *testing.T) {
+	o := DefaultTableOptions().Merge(&TableOptions{RowFormat: "DYNAMIC"})
+	if o.Engine != "InnoDB" || o.RowFormat != "DYNAMIC" {
+		t.Fatal(o)
+	}
+	clause := o.Clause()
+	if clause != " ENGINE=InnoDB DEFAULT CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci ROW_FORMAT=DYNAMIC" {
+		t.Fatal(clause)
+	}
+	u := New(db.New(), nil, FlagWithAccount|FlagWithUser)
+	u.TableOptions = &TableOptions{Engine: "MyISAM"}
+	u.ModulesTableOptions = map[string]*TableOptions{
+		"account": &TableOptions{Charset: "latin1", BinaryCollation: "latin1_bin"},
+	}
+	if o := u.ModuleTableOptions("account"); o.Engine != "MyISAM" || o.Charset != "latin1" || o.Collation != "utf8mb4_general_ci" {
+		t.Fatal(o)
+	}
+	if o := u.ModuleTableOptions("user"); o.Engine != "MyISAM" || o.Charset != "utf8mb4" {
+		t.Fatal(o)
+	}
+}
+
+func TestCreateTableCommands(t *testing.T) {
+	u := New(db.New(), nil, FlagWithAccount|FlagWithPassword)
+	u.Columns.IP = "ip"
+	commands := u.CreateTableCommands()
+	if len(commands) != 2 {
+		t.Fatal(commands)
+	}
+	if !strings.Contains(commands[0], "account VARCHAR(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL") ||
+		!strings.Contains(commands[0], "ip VARCHAR(255)") ||
+		!strings.HasSuffix(commands[0], " ENGINE=InnoDB DEFAULT CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci;") {
+		t.Fatal(commands[0])
+	}
+	if !strings.Contains(commands[1], "password VARCHAR(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL") {
+		t.Fatal(commands[1])
+	}
+	u.Tables.ErasureMapperName = "erasure"
+	u.ModulesTableOptions = map[string]*TableOptions{
+		ModuleErasure: &TableOptions{Engine: "Archive"},
+	}
+	commands = u.CreateTableCommands()
+	if len(commands) != 3 || !strings.Contains(commands[2], "ENGINE=Archive") {
+		t.Fatal(commands)
+	}
+}
+
+//initSchemaTestDB recreate tables of given user by CreateTableCommands,
+//so that optional columns and tables not included in mysql schema files can be tested.
+func initSchemaTestDB(u *User) {
+	tables := []string{}
+	if u.HasFlag(FlagWithAccount) {
+		tables = append(tables, u.AccountTableName())
+	}
+	if u.HasFlag(FlagWithPassword) {
+		tables = append(tables, u.PasswordTableName())
+	}
+	if u.HasFlag(FlagWithToken) {
+		tables = append(tables, u.TokenTableName())
+	}
+	if u.HasFlag(FlagWithUser) {
+		tables = append(tables, u.UserTableName())
+	}
+	if table := u.ErasureTableName(); table != "" {
+		tables = append(tables, table)
+	}
+	for _, v := range tables {
+		u.QueryBuilder.New("DROP TABLE IF EXISTS " + v).MustExec(u.DB)
+	}
+	for _, v := range u.CreateTableCommands() {
+		u.QueryBuilder.New(v).MustExec(u.DB)
+	}
+}
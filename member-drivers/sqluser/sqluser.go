@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"strconv"
+	"sync"
 	"time"
 
 	"crypto/rand"
@@ -82,6 +83,7 @@ func New(db db.Database, uidgenerater func() (string, error), flag int) *User {
 			TokenMapperName:    DefaultTokenMapperName,
 			UserMapperName:     DefaultUserMapperName,
 		},
+		Columns:        DefaultColumns(),
 		HashMethod:     DefaultHashMethod,
 		UIDGenerater:   uidgenerater,
 		TokenGenerater: Timestamp,
@@ -91,12 +93,64 @@ func New(db db.Database, uidgenerater func() (string, error), flag int) *User {
 	}
 }
 
+//NewWithPrefix create User framework with given database ,uidgenerater,falg and table prefix.
+func NewWithPrefix(db db.Database, uidgenerater func() (string, error), flag int, prefix string) *User {
+	u := New(db, uidgenerater, flag)
+	u.AddTablePrefix(prefix)
+	return u
+}
+
 //Tables struct stores table info.
 type Tables struct {
 	AccountMapperName  string
 	PasswordMapperName string
 	TokenMapperName    string
 	UserMapperName     string
+	//ErasureMapperName erasure receipt table name.
+	//Erasure receipt will not be recorded if empty.
+	ErasureMapperName string
+}
+
+//Columns struct stores column names.
+type Columns struct {
+	UID         string
+	Keyword     string
+	Account     string
+	CreatedTime string
+	UpdatedTime string
+	HashMethod  string
+	Salt        string
+	Password    string
+	Token       string
+	Status      string
+	//ExpiredTime expiry timestamp column of user table.
+	//Account expiry is disabled if empty.
+	ExpiredTime string
+	//IP registration ip provenance column of account table.
+	//Column will not be used if empty.
+	IP string
+	//Source registration source provenance column of account table.
+	//Column will not be used if empty.
+	Source string
+	//UserAgentHash user agent hash provenance column of account table.
+	//Column will not be used if empty.
+	UserAgentHash string
+}
+
+//DefaultColumns return default column names.
+func DefaultColumns() Columns {
+	return Columns{
+		UID:         "uid",
+		Keyword:     "keyword",
+		Account:     "account",
+		CreatedTime: "created_time",
+		UpdatedTime: "updated_time",
+		HashMethod:  "hash_method",
+		Salt:        "salt",
+		Password:    "password",
+		Token:       "token",
+		Status:      "status",
+	}
 }
 
 //RandomBytes string generater return random bytes.
@@ -121,8 +175,12 @@ type User struct {
 	DB db.Database
 	//Tables table name info.
 	Tables Tables
+	//Columns column name info.
+	Columns Columns
 	//Flag sqluser modules create flg.
-	Flag int
+	//Use HasFlag to check modules and EnableModule and DisableModule to change modules at runtime.
+	Flag       int
+	flagLocker sync.RWMutex
 	//UIDGenerater string generater for uid
 	//default value is uuid
 	UIDGenerater func() (string, error)
@@ -139,8 +197,32 @@ type User struct {
 	//default value is empty.
 	//You can change this value after sqluser init.
 	PasswordKey string
+	//LegacyPasswordKeys rotated password keys which still can be used to verify password.
+	//Password verified by legacy key will be rehashed with PasswordKey.
+	LegacyPasswordKeys []string
 	//QueryBuilder sql query builder
 	QueryBuilder *querybuilder.Builder
+	//Dialect sql dialect used to build upsert and lock commands.
+	//Portable queries which update then insert rows in transaction will be used if nil.
+	//Dialect upsert commands require unique keys on uid,or uid and keyword columns.
+	Dialect Dialect
+	//TableOptions table options of all tables used by CreateTableCommands.
+	//DefaultTableOptions will be used if nil.
+	TableOptions *TableOptions
+	//ModulesTableOptions table options of modules used by CreateTableCommands,keyed by module name.
+	ModulesTableOptions map[string]*TableOptions
+	//Metrics metrics which records duration and retries of upsert operations.
+	//Upsert operations will not be recorded if nil.
+	Metrics Metrics
+	//MaxLockRetries max retries of upsert operations when retryable error raised.
+	//Default value is 0,which means upsert operations will not be retried.
+	MaxLockRetries int
+	//LockRetryInterval base interval of jittered backoff between upsert retries.
+	//DefaultLockRetryInterval will be used if not positive.
+	LockRetryInterval time.Duration
+	//RetryableError func which checks if error raised by upsert operations is retryable.
+	//IsLockError will be used if nil.
+	RetryableError func(err error) bool
 }
 
 //AddTablePrefix add prefix to user table names.
@@ -149,10 +231,15 @@ func (u *User) AddTablePrefix(prefix string) {
 	u.Tables.PasswordMapperName = prefix + u.Tables.PasswordMapperName
 	u.Tables.TokenMapperName = prefix + u.Tables.TokenMapperName
 	u.Tables.UserMapperName = prefix + u.Tables.UserMapperName
+	if u.Tables.ErasureMapperName != "" {
+		u.Tables.ErasureMapperName = prefix + u.Tables.ErasureMapperName
+	}
 }
 
 //HasFlag check if sqluser module created with special flag.
 func (u *User) HasFlag(flag int) bool {
+	u.flagLocker.RLock()
+	defer u.flagLocker.RUnlock()
 	return u.Flag&flag != 0
 }
 
@@ -224,7 +311,11 @@ func (a *AccountMapper) Execute(service *member.Service) {
 //Unbind unbind account from user.
 //Return any error if raised.
 func (a *AccountMapper) Unbind(uid string, account *user.Account) error {
+	if err := a.User.mustHaveFlag(FlagWithAccount); err != nil {
+		return err
+	}
 	query := a.User.QueryBuilder
+	columns := a.User.Columns
 	tx, err := a.DB().Begin()
 	if err != nil {
 		return err
@@ -232,9 +323,9 @@ func (a *AccountMapper) Unbind(uid string, account *user.Account) error {
 	defer tx.Rollback()
 	Delete := query.NewDeleteQuery(a.TableName())
 	Delete.Where.Condition = query.And(
-		query.Equal("account.uid", uid),
-		query.Equal("account.keyword", account.Keyword),
-		query.Equal("account.account", account.Account),
+		query.Equal("account."+columns.UID, uid),
+		query.Equal("account."+columns.Keyword, account.Keyword),
+		query.Equal("account."+columns.Account, account.Account),
 	)
 	_, err = Delete.Query().Exec(tx)
 	if err != nil {
@@ -248,7 +339,19 @@ func (a *AccountMapper) Unbind(uid string, account *user.Account) error {
 //Return any error if raised.
 //If account exists, error user.ErrAccountBindingExists will raised.
 func (a *AccountMapper) Bind(uid string, account *user.Account) error {
+	return a.BindWithProvenance(uid, account, nil)
+}
+
+//BindWithProvenance bind account to user with given provenance.
+//Provenance will be ignored if nil.
+//Return any error if raised.
+//If account exists, error user.ErrAccountBindingExists will raised.
+func (a *AccountMapper) BindWithProvenance(uid string, account *user.Account, p *Provenance) error {
+	if err := a.User.mustHaveFlag(FlagWithAccount); err != nil {
+		return err
+	}
 	query := a.User.QueryBuilder
+	columns := a.User.Columns
 	tx, err := a.DB().Begin()
 	if err != nil {
 		return err
@@ -256,13 +359,14 @@ func (a *AccountMapper) Bind(uid string, account *user.Account) error {
 	defer tx.Rollback()
 	var u = ""
 	Select := query.NewSelectQuery()
-	Select.Select.Add("account.uid")
+	Select.Select.Add("account." + columns.UID)
 	Select.From.AddAlias("account", a.TableName())
 	Select.Where.Condition = query.And(
-		query.Equal("keyword", account.Keyword),
-		query.Equal("account", account.Account),
+		query.Equal(columns.Keyword, account.Keyword),
+		query.Equal(columns.Account, account.Account),
 	)
-	row := Select.QueryRow(a.DB())
+	q := Select.Query()
+	row := tx.QueryRow(a.User.lockCommand(q.QueryCommand()), q.QueryArgs()...)
 	err = row.Scan(&u)
 	if err != nil {
 		if err != sql.ErrNoRows {
@@ -276,10 +380,11 @@ func (a *AccountMapper) Bind(uid string, account *user.Account) error {
 	var CreatedTime = time.Now().Unix()
 	Insert := query.NewInsertQuery(a.TableName())
 	Insert.Insert.
-		Add("uid", uid).
-		Add("keyword", account.Keyword).
-		Add("account", account.Account).
-		Add("created_time", CreatedTime)
+		Add(columns.UID, uid).
+		Add(columns.Keyword, account.Keyword).
+		Add(columns.Account, account.Account).
+		Add(columns.CreatedTime, CreatedTime)
+	a.addProvenance(Insert, p)
 	_, err = Insert.Query().Exec(tx)
 	if err != nil {
 		return err
@@ -291,7 +396,22 @@ func (a *AccountMapper) Bind(uid string, account *user.Account) error {
 //UIDGenerater used when create new user.
 //Return user id and any error if raised.
 func (a *AccountMapper) FindOrInsert(UIDGenerater func() (string, error), account *user.Account) (string, bool, error) {
+	if err := a.User.mustHaveFlag(FlagWithAccount); err != nil {
+		return "", false, err
+	}
+	var uid string
+	var created bool
+	err := a.User.upsert(OpAccountFindOrInsert, func() error {
+		var err error
+		uid, created, err = a.findOrInsert(UIDGenerater, account)
+		return err
+	})
+	return uid, created, err
+}
+
+func (a *AccountMapper) findOrInsert(UIDGenerater func() (string, error), account *user.Account) (string, bool, error) {
 	query := a.User.QueryBuilder
+	columns := a.User.Columns
 	var result = AccountModel{}
 	tx, err := a.DB().Begin()
 	if err != nil {
@@ -300,17 +420,18 @@ func (a *AccountMapper) FindOrInsert(UIDGenerater func() (string, error), accoun
 	defer tx.Rollback()
 	Select := query.NewSelectQuery()
 	Select.From.AddAlias("account", a.TableName())
-	Select.Select.Add("account.uid", "account.keyword", "account.account", "account.created_time")
+	Select.Select.Add("account."+columns.UID, "account."+columns.Keyword, "account."+columns.Account, "account."+columns.CreatedTime)
 	Select.Where.Condition = query.And(
-		query.Equal("account.keyword", account.Keyword),
-		query.Equal("account.account", account.Account),
+		query.Equal("account."+columns.Keyword, account.Keyword),
+		query.Equal("account."+columns.Account, account.Account),
 	)
-	row := Select.QueryRow(a.DB())
+	q := Select.Query()
+	row := tx.QueryRow(a.User.lockCommand(q.QueryCommand()), q.QueryArgs()...)
 	err = Select.Result().
-		Bind("account.uid", &result.UID).
-		Bind("account.keyword", &result.Keyword).
-		Bind("account.account", &result.Account).
-		Bind("account.created_time", &result.CreatedTime).
+		Bind("account."+columns.UID, &result.UID).
+		Bind("account."+columns.Keyword, &result.Keyword).
+		Bind("account."+columns.Account, &result.Account).
+		Bind("account."+columns.CreatedTime, &result.CreatedTime).
 		ScanFrom(row)
 	if err == nil {
 		return result.UID, false, nil
@@ -322,10 +443,10 @@ func (a *AccountMapper) FindOrInsert(UIDGenerater func() (string, error), accoun
 	var CreatedTime = time.Now().Unix()
 	Insert := query.NewInsertQuery(a.TableName())
 	Insert.Insert.
-		Add("uid", uid).
-		Add("keyword", account.Keyword).
-		Add("account", account.Account).
-		Add("created_time", CreatedTime)
+		Add(columns.UID, uid).
+		Add(columns.Keyword, account.Keyword).
+		Add(columns.Account, account.Account).
+		Add(columns.CreatedTime, CreatedTime)
 	_, err = Insert.Query().Exec(tx)
 	if err != nil {
 		return "", false, err
@@ -333,10 +454,10 @@ func (a *AccountMapper) FindOrInsert(UIDGenerater func() (string, error), accoun
 	if a.User.HasFlag(FlagWithUser) {
 		Insert := query.NewInsertQuery(a.User.UserTableName())
 		Insert.Insert.
-			Add("uid", uid).
-			Add("status", member.StatusNormal).
-			Add("created_time", CreatedTime).
-			Add("updated_time", CreatedTime)
+			Add(columns.UID, uid).
+			Add(columns.Status, member.StatusNormal).
+			Add(columns.CreatedTime, CreatedTime).
+			Add(columns.UpdatedTime, CreatedTime)
 		_, err = Insert.Query().Exec(tx)
 		if err != nil {
 			return "", false, err
@@ -349,7 +470,19 @@ func (a *AccountMapper) FindOrInsert(UIDGenerater func() (string, error), accoun
 //Return any error if raised.
 //If account exists,member.ErrAccountRegisterExists will raise.
 func (a *AccountMapper) Insert(uid string, keyword string, account string) error {
+	return a.InsertWithProvenance(uid, keyword, account, nil)
+}
+
+//InsertWithProvenance create new user with given account and provenance.
+//Provenance will be ignored if nil.
+//Return any error if raised.
+//If account exists,member.ErrAccountRegisterExists will raise.
+func (a *AccountMapper) InsertWithProvenance(uid string, keyword string, account string, p *Provenance) error {
+	if err := a.User.mustHaveFlag(FlagWithAccount); err != nil {
+		return err
+	}
 	query := a.User.QueryBuilder
+	columns := a.User.Columns
 	tx, err := a.DB().Begin()
 	if err != nil {
 		return err
@@ -357,13 +490,14 @@ func (a *AccountMapper) Insert(uid string, keyword string, account string) error
 	defer tx.Rollback()
 	var u = ""
 	Select := query.NewSelectQuery()
-	Select.Select.Add("uid")
+	Select.Select.Add(columns.UID)
 	Select.From.Add(a.TableName())
 	Select.Where.Condition = query.And(
-		query.Equal("keyword", keyword),
-		query.Equal("account", account),
+		query.Equal(columns.Keyword, keyword),
+		query.Equal(columns.Account, account),
 	)
-	row := Select.QueryRow(a.DB())
+	q := Select.Query()
+	row := tx.QueryRow(a.User.lockCommand(q.QueryCommand()), q.QueryArgs()...)
 	err = row.Scan(&u)
 	if err != nil {
 		if err != sql.ErrNoRows {
@@ -375,10 +509,11 @@ func (a *AccountMapper) Insert(uid string, keyword string, account string) error
 	var CreatedTime = time.Now().Unix()
 	Insert := query.NewInsertQuery(a.TableName())
 	Insert.Insert.
-		Add("uid", uid).
-		Add("keyword", keyword).
-		Add("account", account).
-		Add("created_time", CreatedTime)
+		Add(columns.UID, uid).
+		Add(columns.Keyword, keyword).
+		Add(columns.Account, account).
+		Add(columns.CreatedTime, CreatedTime)
+	a.addProvenance(Insert, p)
 	_, err = Insert.Query().Exec(tx)
 	if err != nil {
 		return err
@@ -386,10 +521,10 @@ func (a *AccountMapper) Insert(uid string, keyword string, account string) error
 	if a.User.HasFlag(FlagWithUser) {
 		Insert := query.NewInsertQuery(a.User.UserTableName())
 		Insert.Insert.
-			Add("uid", uid).
-			Add("status", member.StatusNormal).
-			Add("created_time", CreatedTime).
-			Add("updated_time", CreatedTime)
+			Add(columns.UID, uid).
+			Add(columns.Status, member.StatusNormal).
+			Add(columns.CreatedTime, CreatedTime).
+			Add(columns.UpdatedTime, CreatedTime)
 		_, err = Insert.Query().Exec(tx)
 		if err != nil {
 			return err
@@ -402,23 +537,24 @@ func (a *AccountMapper) Insert(uid string, keyword string, account string) error
 //Return account model and any error if raised.
 func (a *AccountMapper) Find(keyword string, account string) (AccountModel, error) {
 	query := a.User.QueryBuilder
+	columns := a.User.Columns
 	var result = AccountModel{}
-	if keyword == "" || account == "" {
+	if keyword == "" || account == "" || !a.User.HasFlag(FlagWithAccount) {
 		return result, sql.ErrNoRows
 	}
 	Select := query.NewSelectQuery()
-	Select.Select.Add("uid", "keyword", "account", "created_time")
+	Select.Select.Add(columns.UID, columns.Keyword, columns.Account, columns.CreatedTime)
 	Select.From.Add(a.TableName())
 	Select.Where.Condition = query.And(
-		query.Equal("keyword", keyword),
-		query.Equal("account", account),
+		query.Equal(columns.Keyword, keyword),
+		query.Equal(columns.Account, account),
 	)
 	row := Select.QueryRow(a.DB())
 	err := Select.Result().
-		Bind("uid", &result.UID).
-		Bind("keyword", &result.Keyword).
-		Bind("account", &result.Account).
-		Bind("created_time", &result.CreatedTime).
+		Bind(columns.UID, &result.UID).
+		Bind(columns.Keyword, &result.Keyword).
+		Bind(columns.Account, &result.Account).
+		Bind(columns.CreatedTime, &result.CreatedTime).
 		ScanFrom(row)
 	return result, err
 }
@@ -427,14 +563,15 @@ func (a *AccountMapper) Find(keyword string, account string) (AccountModel, erro
 //Retrun account models and any error if rased.
 func (a *AccountMapper) FindAllByUID(uids ...string) ([]AccountModel, error) {
 	query := a.User.QueryBuilder
+	columns := a.User.Columns
 	var result = []AccountModel{}
-	if len(uids) == 0 {
+	if len(uids) == 0 || !a.User.HasFlag(FlagWithAccount) {
 		return result, nil
 	}
 	Select := query.NewSelectQuery()
-	Select.Select.Add("account.uid", "account.keyword", "account.account")
+	Select.Select.Add("account."+columns.UID, "account."+columns.Keyword, "account."+columns.Account)
 	Select.From.AddAlias("account", a.TableName())
-	Select.Where.Condition = query.In("account.uid", uids)
+	Select.Where.Condition = query.In("account."+columns.UID, uids)
 	rows, err := Select.QueryRows(a.DB())
 	if err != nil {
 		return nil, err
@@ -443,9 +580,9 @@ func (a *AccountMapper) FindAllByUID(uids ...string) ([]AccountModel, error) {
 	for rows.Next() {
 		v := AccountModel{}
 		err := Select.Result().
-			Bind("account.uid", &v.UID).
-			Bind("account.keyword", &v.Keyword).
-			Bind("account.account", &v.Account).
+			Bind("account."+columns.UID, &v.UID).
+			Bind("account."+columns.Keyword, &v.Keyword).
+			Bind("account."+columns.Account, &v.Account).
 			ScanFrom(rows)
 		if err != nil {
 			return nil, err
@@ -489,11 +626,19 @@ func (a *AccountMapper) AccountToUID(account *user.Account) (uid string, err err
 //Return user id and any error if raised.
 //If account exists,member.ErrAccountRegisterExists will raise.
 func (a *AccountMapper) Register(account *user.Account) (uid string, err error) {
+	return a.RegisterWithProvenance(account, nil)
+}
+
+//RegisterWithProvenance register a user with special account and provenance.
+//Provenance will be ignored if nil.
+//Return user id and any error if raised.
+//If account exists,member.ErrAccountRegisterExists will raise.
+func (a *AccountMapper) RegisterWithProvenance(account *user.Account, p *Provenance) (uid string, err error) {
 	uid, err = a.User.UIDGenerater()
 	if err != nil {
 		return
 	}
-	err = a.Insert(uid, account.Keyword, account.Account)
+	err = a.InsertWithProvenance(uid, account.Keyword, account.Account, p)
 	return
 }
 
@@ -543,29 +688,30 @@ func (p *PasswordMapper) Execute(service *member.Service) {
 
 //PasswordChangeable return password changeable
 func (p *PasswordMapper) PasswordChangeable() bool {
-	return true
+	return p.User.HasFlag(FlagWithPassword)
 }
 
 //Find find password model by userd id.
 //Return any error if raised.
 func (p *PasswordMapper) Find(uid string) (PasswordModel, error) {
 	query := p.User.QueryBuilder
+	columns := p.User.Columns
 	var result = PasswordModel{}
-	if uid == "" {
+	if uid == "" || !p.User.HasFlag(FlagWithPassword) {
 		return result, sql.ErrNoRows
 	}
 	Select := query.NewSelectQuery()
-	Select.Select.Add("password.hash_method", "password.salt", "password.password", "password.updated_time")
+	Select.Select.Add("password."+columns.HashMethod, "password."+columns.Salt, "password."+columns.Password, "password."+columns.UpdatedTime)
 	Select.From.AddAlias("password", p.TableName())
-	Select.Where.Condition = query.Equal("uid", uid)
+	Select.Where.Condition = query.Equal(columns.UID, uid)
 	q := Select.Query()
 	row := p.DB().QueryRow(q.QueryCommand(), q.QueryArgs()...)
 	result.UID = uid
 	args := Select.Result().
-		Bind("password.hash_method", &result.HashMethod).
-		Bind("password.salt", &result.Salt).
-		Bind("password.password", &result.Password).
-		Bind("password.updated_time", &result.UpdatedTime).
+		Bind("password."+columns.HashMethod, &result.HashMethod).
+		Bind("password."+columns.Salt, &result.Salt).
+		Bind("password."+columns.Password, &result.Password).
+		Bind("password."+columns.UpdatedTime, &result.UpdatedTime).
 		Pointers()
 
 	err := row.Scan(args...)
@@ -575,8 +721,27 @@ func (p *PasswordMapper) Find(uid string) (PasswordModel, error) {
 //InsertOrUpdate insert or update password model.
 //Return any error if raised.
 func (p *PasswordMapper) InsertOrUpdate(model *PasswordModel) error {
-	query := p.User.QueryBuilder
+	if err := p.User.mustHaveFlag(FlagWithPassword); err != nil {
+		return err
+	}
+	return p.User.upsert(OpPasswordInsertOrUpdate, func() error {
+		return p.insertOrUpdate(model)
+	})
+}
 
+func (p *PasswordMapper) insertOrUpdate(model *PasswordModel) error {
+	query := p.User.QueryBuilder
+	columns := p.User.Columns
+	upsert := p.User.upsertCommand(
+		p.TableName(),
+		[]string{columns.UID},
+		[]string{columns.UID, columns.HashMethod, columns.Salt, columns.Password, columns.UpdatedTime},
+		[]string{columns.HashMethod, columns.Salt, columns.Password, columns.UpdatedTime},
+	)
+	if upsert != "" {
+		_, err := p.DB().Exec(upsert, model.UID, model.HashMethod, model.Salt, model.Password, model.UpdatedTime)
+		return err
+	}
 	tx, err := p.DB().Begin()
 	if err != nil {
 		return err
@@ -584,11 +749,11 @@ func (p *PasswordMapper) InsertOrUpdate(model *PasswordModel) error {
 	defer tx.Rollback()
 	Update := query.NewUpdateQuery(p.TableName())
 	Update.Update.
-		Add("hash_method", model.HashMethod).
-		Add("salt", model.Salt).
-		Add("password", model.Password).
-		Add("updated_time", model.UpdatedTime)
-	Update.Where.Condition = query.Equal("uid", model.UID)
+		Add(columns.HashMethod, model.HashMethod).
+		Add(columns.Salt, model.Salt).
+		Add(columns.Password, model.Password).
+		Add(columns.UpdatedTime, model.UpdatedTime)
+	Update.Where.Condition = query.Equal(columns.UID, model.UID)
 	r, err := Update.Query().Exec(tx)
 
 	if err != nil {
@@ -603,11 +768,11 @@ func (p *PasswordMapper) InsertOrUpdate(model *PasswordModel) error {
 	}
 	Insert := query.NewInsertQuery(p.TableName())
 	Insert.Insert.
-		Add("uid", model.UID).
-		Add("hash_method", model.HashMethod).
-		Add("salt", model.Salt).
-		Add("password", model.Password).
-		Add("updated_time", model.UpdatedTime)
+		Add(columns.UID, model.UID).
+		Add(columns.HashMethod, model.HashMethod).
+		Add(columns.Salt, model.Salt).
+		Add(columns.Password, model.Password).
+		Add(columns.UpdatedTime, model.UpdatedTime)
 	_, err = Insert.Query().Exec(tx)
 	if err != nil {
 		return err
@@ -618,6 +783,7 @@ func (p *PasswordMapper) InsertOrUpdate(model *PasswordModel) error {
 //VerifyPassword Verify user password.
 //Return verify and any error if raised.
 //if user not found,error member.ErrUserNotFound will be raised.
+//If password verified by legacy password key,password will be rehashed with current password key.
 func (p *PasswordMapper) VerifyPassword(uid string, password string) (bool, error) {
 	model, err := p.Find(uid)
 	if err == sql.ErrNoRows {
@@ -634,12 +800,27 @@ func (p *PasswordMapper) VerifyPassword(uid string, password string) (bool, erro
 	if err != nil {
 		return false, err
 	}
-	return bytes.Compare(hashed, model.Password) == 0, nil
+	if bytes.Compare(hashed, model.Password) == 0 {
+		return true, nil
+	}
+	for _, key := range p.User.LegacyPasswordKeys {
+		hashed, err = hash(key, model.Salt, password)
+		if err != nil {
+			return false, err
+		}
+		if bytes.Compare(hashed, model.Password) == 0 {
+			return true, p.UpdatePassword(uid, password)
+		}
+	}
+	return false, nil
 }
 
 //UpdatePassword update user password.If user password does not exist,new password record will be created.
 //Return any error if raised.
 func (p *PasswordMapper) UpdatePassword(uid string, password string) error {
+	if err := p.User.mustHaveFlag(FlagWithPassword); err != nil {
+		return err
+	}
 	salt, err := p.User.SaltGenerater()
 	if err != nil {
 		return err
@@ -691,19 +872,38 @@ func (t *TokenMapper) Execute(service *member.Service) {
 
 //InsertOrUpdate insert or update user token record.
 func (t *TokenMapper) InsertOrUpdate(uid string, token string) error {
-	query := t.User.QueryBuilder
+	if err := t.User.mustHaveFlag(FlagWithToken); err != nil {
+		return err
+	}
+	return t.User.upsert(OpTokenInsertOrUpdate, func() error {
+		return t.insertOrUpdate(uid, token)
+	})
+}
 
+func (t *TokenMapper) insertOrUpdate(uid string, token string) error {
+	query := t.User.QueryBuilder
+	columns := t.User.Columns
+	var CreatedTime = time.Now().Unix()
+	upsert := t.User.upsertCommand(
+		t.TableName(),
+		[]string{columns.UID},
+		[]string{columns.UID, columns.Token, columns.UpdatedTime},
+		[]string{columns.Token, columns.UpdatedTime},
+	)
+	if upsert != "" {
+		_, err := t.DB().Exec(upsert, uid, token, CreatedTime)
+		return err
+	}
 	tx, err := t.DB().Begin()
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
-	var CreatedTime = time.Now().Unix()
 	Update := query.NewUpdateQuery(t.TableName())
 	Update.Update.
-		Add("token", token).
-		Add("updated_time", CreatedTime)
-	Update.Where.Condition = query.Equal("uid", uid)
+		Add(columns.Token, token).
+		Add(columns.UpdatedTime, CreatedTime)
+	Update.Where.Condition = query.Equal(columns.UID, uid)
 	r, err := Update.Query().Exec(tx)
 	if err != nil {
 		return err
@@ -717,9 +917,9 @@ func (t *TokenMapper) InsertOrUpdate(uid string, token string) error {
 	}
 	Insert := query.NewInsertQuery(t.TableName())
 	Insert.Insert.
-		Add("uid", uid).
-		Add("token", token).
-		Add("updated_time", CreatedTime)
+		Add(columns.UID, uid).
+		Add(columns.Token, token).
+		Add(columns.UpdatedTime, CreatedTime)
 	_, err = Insert.Query().Exec(tx)
 	if err != nil {
 		return err
@@ -731,14 +931,15 @@ func (t *TokenMapper) InsertOrUpdate(uid string, token string) error {
 //Return token models and any error if raised.
 func (t *TokenMapper) FindAllByUID(uids ...string) ([]TokenModel, error) {
 	query := t.User.QueryBuilder
+	columns := t.User.Columns
 	var result = []TokenModel{}
-	if len(uids) == 0 {
+	if len(uids) == 0 || !t.User.HasFlag(FlagWithToken) {
 		return result, nil
 	}
 	Select := query.NewSelectQuery()
-	Select.Select.Add("token.uid", "token.token")
+	Select.Select.Add("token."+columns.UID, "token."+columns.Token)
 	Select.From.AddAlias("token", t.TableName())
-	Select.Where.Condition = query.In("token.uid", uids)
+	Select.Where.Condition = query.In("token."+columns.UID, uids)
 	rows, err := Select.QueryRows(t.DB())
 	if err != nil {
 		return nil, err
@@ -774,6 +975,9 @@ func (t *TokenMapper) Tokens(uid ...string) (member.Tokens, error) {
 //Revoke revoke and regenerate a new token to user.if revoke record does not exist,a new record will be created.
 //Return new user token and any error if raised.
 func (t *TokenMapper) Revoke(uid string) (string, error) {
+	if err := t.User.mustHaveFlag(FlagWithToken); err != nil {
+		return "", err
+	}
 	token, err := t.User.TokenGenerater()
 	if err != nil {
 		return "", err
@@ -808,15 +1012,19 @@ func (u *UserMapper) Execute(service *member.Service) {
 //Return User model list and any error if raised.
 func (u *UserMapper) FindAllByUID(uids ...string) ([]UserModel, error) {
 	query := u.User.QueryBuilder
+	columns := u.User.Columns
 
 	var result = []UserModel{}
-	if len(uids) == 0 {
+	if len(uids) == 0 || !u.User.HasFlag(FlagWithUser) {
 		return result, nil
 	}
 	Select := query.NewSelectQuery()
-	Select.Select.Add("user.uid", "user.status")
+	Select.Select.Add("user."+columns.UID, "user."+columns.Status)
+	if columns.ExpiredTime != "" {
+		Select.Select.Add("user." + columns.ExpiredTime)
+	}
 	Select.From.AddAlias("user", u.TableName())
-	Select.Where.Condition = query.In("user.uid", uids)
+	Select.Where.Condition = query.In("user."+columns.UID, uids)
 	rows, err := Select.QueryRows(u.DB())
 	if err != nil {
 		return nil, err
@@ -824,7 +1032,11 @@ func (u *UserMapper) FindAllByUID(uids ...string) ([]UserModel, error) {
 	defer rows.Close()
 	for rows.Next() {
 		v := UserModel{}
-		err = rows.Scan(&v.UID, &v.Status)
+		if columns.ExpiredTime != "" {
+			err = rows.Scan(&v.UID, &v.Status, &v.ExpiredTime)
+		} else {
+			err = rows.Scan(&v.UID, &v.Status)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -836,18 +1048,38 @@ func (u *UserMapper) FindAllByUID(uids ...string) ([]UserModel, error) {
 //InsertOrUpdate insert or update user model with status.
 //Return any error if raised.
 func (u *UserMapper) InsertOrUpdate(uid string, status member.Status) error {
+	if err := u.User.mustHaveFlag(FlagWithUser); err != nil {
+		return err
+	}
+	return u.User.upsert(OpUserInsertOrUpdate, func() error {
+		return u.insertOrUpdate(uid, status)
+	})
+}
+
+func (u *UserMapper) insertOrUpdate(uid string, status member.Status) error {
 	query := u.User.QueryBuilder
+	columns := u.User.Columns
+	var CreatedTime = time.Now().Unix()
+	upsert := u.User.upsertCommand(
+		u.TableName(),
+		[]string{columns.UID},
+		[]string{columns.UID, columns.Status, columns.UpdatedTime, columns.CreatedTime},
+		[]string{columns.Status, columns.UpdatedTime},
+	)
+	if upsert != "" {
+		_, err := u.DB().Exec(upsert, uid, status, CreatedTime, CreatedTime)
+		return err
+	}
 	tx, err := u.DB().Begin()
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
-	var CreatedTime = time.Now().Unix()
 	Update := query.NewUpdateQuery(u.TableName())
 	Update.Update.
-		Add("status", status).
-		Add("updated_time", CreatedTime)
-	Update.Where.Condition = query.Equal("uid", uid)
+		Add(columns.Status, status).
+		Add(columns.UpdatedTime, CreatedTime)
+	Update.Where.Condition = query.Equal(columns.UID, uid)
 	r, err := Update.Query().Exec(tx)
 	if err != nil {
 		return err
@@ -861,10 +1093,10 @@ func (u *UserMapper) InsertOrUpdate(uid string, status member.Status) error {
 	}
 	Insert := query.NewInsertQuery(u.TableName())
 	Insert.Insert.
-		Add("uid", uid).
-		Add("status", status).
-		Add("updated_time", CreatedTime).
-		Add("created_time", CreatedTime)
+		Add(columns.UID, uid).
+		Add(columns.Status, status).
+		Add(columns.UpdatedTime, CreatedTime).
+		Add(columns.CreatedTime, CreatedTime)
 	_, err = Insert.Query().Exec(tx)
 	if err != nil {
 		return err
@@ -873,6 +1105,7 @@ func (u *UserMapper) InsertOrUpdate(uid string, status member.Status) error {
 }
 
 //Statuses get member  status map by user id list.
+//Normal users expired will be returned as member.StatusExpired.
 //Return  status map and any error if rasied.
 //User unfound in token map will be false.
 func (u *UserMapper) Statuses(uid ...string) (member.StatusMap, error) {
@@ -881,8 +1114,13 @@ func (u *UserMapper) Statuses(uid ...string) (member.StatusMap, error) {
 		return nil, err
 	}
 	result := member.StatusMap{}
+	now := time.Now().Unix()
 	for _, v := range models {
-		result[v.UID] = member.Status(v.Status)
+		status := member.Status(v.Status)
+		if status == member.StatusNormal && v.ExpiredTime > 0 && v.ExpiredTime <= now {
+			status = member.StatusExpired
+		}
+		result[v.UID] = status
 	}
 	return result, nil
 }
@@ -898,6 +1136,27 @@ func (u *UserMapper) SetStatus(uid string, status member.Status) error {
 	return u.InsertOrUpdate(uid, status)
 }
 
+//CountStatus count users with given status.
+//Normal users expired are not counted.
+//Return user count and any error if raised.
+func (u *UserMapper) CountStatus(status member.Status) (int64, error) {
+	query := u.User.QueryBuilder
+	columns := u.User.Columns
+	Select := query.NewSelectQuery()
+	Select.Select.Add("COUNT(*)")
+	Select.From.Add(u.TableName())
+	Select.Where.Condition = query.Equal(columns.Status, status)
+	if status == member.StatusNormal && columns.ExpiredTime != "" {
+		Select.Where.Condition = query.And(
+			Select.Where.Condition,
+			query.New("("+columns.ExpiredTime+" = 0 OR "+columns.ExpiredTime+" > ?)", time.Now().Unix()),
+		)
+	}
+	var count int64
+	err := Select.QueryRow(u.DB()).Scan(&count)
+	return count, err
+}
+
 //UserModel user data model
 type UserModel struct {
 	//UID user id
@@ -908,4 +1167,7 @@ type UserModel struct {
 	UpdateTIme int64
 	//Status user status
 	Status int
+	//ExpiredTime expiry timestamp in second.
+	//User never expires if ExpiredTime is 0.
+	ExpiredTime int64
 }
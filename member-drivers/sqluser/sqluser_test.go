@@ -39,6 +39,7 @@ func TestInterface(t *testing.T) {
 	U.Password().Execute(service)
 	U.Token().Execute(service)
 	U.User().Execute(service)
+	var _ member.StatusCounter = U.User()
 }
 
 func TestSqluser(t *testing.T) {
@@ -191,6 +192,10 @@ func TestSqluser(t *testing.T) {
 	if u[uid1] != member.StatusBanned {
 		t.Error(u[uid1])
 	}
+	bans, err := userdm.CountStatus(member.StatusBanned)
+	if err != nil || bans != 1 {
+		t.Fatal(bans, err)
+	}
 	err = userdm.SetStatus(uid1, member.StatusNormal)
 	u, err = userdm.Statuses(uid1, uid2, account1plus.Account)
 	if err != nil {
@@ -0,0 +1,98 @@
+package sqluser
+
+import (
+	"math/rand"
+	"strings"
+	"time"
+)
+
+//Upsert operation names used in metrics.
+const (
+	OpAccountFindOrInsert    = "account.findorinsert"
+	OpPasswordInsertOrUpdate = "password.insertorupdate"
+	OpTokenInsertOrUpdate    = "token.insertorupdate"
+	OpUserInsertOrUpdate     = "user.insertorupdate"
+)
+
+//DefaultLockRetryInterval default base interval between upsert retries.
+const DefaultLockRetryInterval = 10 * time.Millisecond
+
+//Metrics sqluser metrics interface.
+//Upsert operations run in one transaction by default.
+//If dialect is set,password,token and user upserts run as a single statement without transaction.
+type Metrics interface {
+	//ObserveUpsert record upsert operation with operation name,total duration of all attempts including retry backoff,retry count and error returned.
+	ObserveUpsert(op string, duration time.Duration, retries int, err error)
+}
+
+//MetricsFunc metrics func which implements Metrics interface.
+type MetricsFunc func(op string, duration time.Duration, retries int, err error)
+
+//ObserveUpsert record upsert operation by calling metrics func.
+func (f MetricsFunc) ObserveUpsert(op string, duration time.Duration, retries int, err error) {
+	f(op, duration, retries, err)
+}
+
+//lockErrorMessages messages of lock wait timeout and deadlock errors returned by built-in dialect databases.
+var lockErrorMessages = []string{
+	"Lock wait timeout exceeded",
+	"Deadlock found",
+	"deadlock detected",
+	"could not serialize access",
+	"lock timeout",
+}
+
+//IsLockError check if error is lock wait timeout or deadlock error which can be retried.
+func IsLockError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	for _, v := range lockErrorMessages {
+		if strings.Contains(msg, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (u *User) retryable(err error) bool {
+	if u.RetryableError != nil {
+		return u.RetryableError(err)
+	}
+	return IsLockError(err)
+}
+
+//lockRetryDelay return jittered backoff delay before given retry.
+//Delay doubles every retry,and is randomized between half and full of it
+//so that conflicting writers do not retry at the same time.
+func (u *User) lockRetryDelay(retry int) time.Duration {
+	interval := u.LockRetryInterval
+	if interval <= 0 {
+		interval = DefaultLockRetryInterval
+	}
+	if retry > 10 {
+		retry = 10
+	}
+	d := interval << uint(retry-1)
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+//upsert run upsert operation with given name.
+//Operation is retried up to MaxLockRetries times with jittered backoff if retryable error raised.
+//Total duration and retry count are recorded to metrics if set.
+//Return any error raised by last attempt.
+func (u *User) upsert(op string, fn func() error) error {
+	start := time.Now()
+	var retries int
+	err := fn()
+	for err != nil && retries < u.MaxLockRetries && u.retryable(err) {
+		retries++
+		time.Sleep(u.lockRetryDelay(retries))
+		err = fn()
+	}
+	if u.Metrics != nil {
+		u.Metrics.ObserveUpsert(op, time.Since(start), retries, err)
+	}
+	return err
+}
@@ -0,0 +1,85 @@
+package sqluser
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestIsLockError(t *testing.T) {
+	if IsLockError(nil) || IsLockError(errors.New("Duplicate entry")) {
+		t.Fatal()
+	}
+	if !IsLockError(errors.New("Error 1205: Lock wait timeout exceeded; try restarting transaction")) {
+		t.Fatal()
+	}
+	if !IsLockError(errors.New("pq: deadlock detected")) {
+		t.Fatal()
+	}
+}
+
+func TestUpsert(t *testing.T) {
+	var observedOp string
+	var observedRetries int
+	var observedErr error
+	u := &User{
+		Metrics: MetricsFunc(func(op string, duration time.Duration, retries int, err error) {
+			observedOp = op
+			observedRetries = retries
+			observedErr = err
+		}),
+	}
+	lockErr := errors.New("Deadlock found when trying to get lock")
+	var calls int
+	fn := func() error {
+		calls++
+		if calls < 3 {
+			return lockErr
+		}
+		return nil
+	}
+	err := u.upsert(OpTokenInsertOrUpdate, fn)
+	if err != lockErr || calls != 1 || observedOp != OpTokenInsertOrUpdate || observedRetries != 0 || observedErr != lockErr {
+		t.Fatal(err, calls, observedOp, observedRetries, observedErr)
+	}
+	calls = 0
+	u.MaxLockRetries = 5
+	u.LockRetryInterval = 2 * time.Millisecond
+	start := time.Now()
+	err = u.upsert(OpUserInsertOrUpdate, fn)
+	if err != nil || calls != 3 || observedOp != OpUserInsertOrUpdate || observedRetries != 2 || observedErr != nil {
+		t.Fatal(err, calls, observedOp, observedRetries, observedErr)
+	}
+	if time.Since(start) < 3*time.Millisecond {
+		t.Fatal(time.Since(start))
+	}
+	calls = 0
+	u.RetryableError = func(err error) bool {
+		return false
+	}
+	err = u.upsert(OpUserInsertOrUpdate, fn)
+	if err != lockErr || calls != 1 || observedRetries != 0 {
+		t.Fatal(err, calls, observedRetries)
+	}
+}
+
+func TestLockRetryDelay(t *testing.T) {
+	u := &User{}
+	for i := 0; i < 100; i++ {
+		d := u.lockRetryDelay(1)
+		if d < DefaultLockRetryInterval/2 || d > DefaultLockRetryInterval {
+			t.Fatal(d)
+		}
+	}
+	u.LockRetryInterval = time.Millisecond
+	for i := 0; i < 100; i++ {
+		d := u.lockRetryDelay(3)
+		if d < 2*time.Millisecond || d > 4*time.Millisecond {
+			t.Fatal(d)
+		}
+	}
+	d := u.lockRetryDelay(100)
+	if d < 256*time.Millisecond || d > 512*time.Millisecond {
+		t.Fatal(d)
+	}
+}
@@ -1,7 +1,9 @@
 package tomluser
 
 import (
+	"os"
 	"sync"
+	"time"
 
 	"github.com/herb-go/deprecated/member"
 	"github.com/herb-go/providers/herb/statictoml"
@@ -17,7 +19,9 @@ func Flush() {
 }
 
 type Data struct {
-	Users []*User
+	//Include user files included,resolved relative to current file.
+	Include []string
+	Users   []*User
 }
 
 func NewData() *Data {
@@ -31,6 +35,15 @@ type Config struct {
 	AsAccountsProvider bool
 	AsRoleProvider     bool
 	HashMode           string
+	//ReadOnly load users as read only store.
+	//Mutation methods will return ErrReadOnlyStore instead of changing users.
+	ReadOnly bool
+	//ReconcileInterval interval in seconds to reload users from source.
+	//Reconciliation is disabled if not positive.
+	ReconcileInterval int64
+	//ExpandEnv expand ${NAME} and ${NAME:-default} in user fields and accounts with environment variables,
+	//so that secrets like initial admin password hash can be injected without baking into file.
+	ExpandEnv bool
 }
 
 func (c *Config) Load() (*Users, error) {
@@ -46,14 +59,15 @@ func (c *Config) Load() (*Users, error) {
 	}
 	u = NewUsers()
 	u.Source = c.Source
-	data := NewData()
-	err = u.Source.Load(data)
+	u.ReadOnly = c.ReadOnly
+	if c.ExpandEnv {
+		u.Env = os.LookupEnv
+	}
+	files, err := loadUserFiles(u.Source)
 	if err != nil {
 		return nil, err
 	}
-	for k := range data.Users {
-		u.addUser(data.Users[k])
-	}
+	u.setFiles(files)
 	return u, nil
 }
 func (c *Config) Execute(m *member.Service) error {
@@ -61,6 +75,9 @@ func (c *Config) Execute(m *member.Service) error {
 	if err != nil {
 		return err
 	}
+	if c.ReconcileInterval > 0 {
+		u.StartReconcile(time.Duration(c.ReconcileInterval) * time.Second)
+	}
 	if c.AsAccountsProvider {
 		m.AccountsProvider = u
 	}
@@ -0,0 +1,123 @@
+package tomluser
+
+import (
+	"strings"
+
+	"github.com/herb-go/user"
+)
+
+//expandEnv replace ${NAME} and ${NAME:-default} in s with environment variables looked up by given function.
+//Default value is used if variable is unset or empty.
+//Other "$" characters are kept as they are,so that password hashes are not changed.
+func expandEnv(s string, lookup func(key string) (string, bool)) string {
+	if !strings.Contains(s, "${") {
+		return s
+	}
+	var result strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(s[start:], "}")
+		if end < 0 {
+			break
+		}
+		end = start + end
+		name := s[start+2 : end]
+		def := ""
+		if i := strings.Index(name, ":-"); i >= 0 {
+			name, def = name[:i], name[i+2:]
+		}
+		value, ok := lookup(name)
+		if !ok || value == "" {
+			value = def
+		}
+		result.WriteString(s[:start])
+		result.WriteString(value)
+		s = s[end+1:]
+	}
+	result.WriteString(s)
+	return result.String()
+}
+
+type envField struct {
+	raw      string
+	expanded string
+}
+
+//userTemplate raw values of user fields which contain environment variables.
+type userTemplate struct {
+	fields   map[string]envField
+	accounts map[string]*user.Account
+}
+
+func accountKey(a *user.Account) string {
+	return a.Keyword + "\n" + a.Account
+}
+
+//expandUser expand environment variables in user fields.
+//Return template of raw values,or nil if nothing expanded.
+func (u *Users) expandUser(usr *User) *userTemplate {
+	if u.Env == nil {
+		return nil
+	}
+	t := &userTemplate{
+		fields:   map[string]envField{},
+		accounts: map[string]*user.Account{},
+	}
+	for name, field := range map[string]*string{
+		"UID":      &usr.UID,
+		"Password": &usr.Password,
+		"HashMode": &usr.HashMode,
+		"Salt":     &usr.Salt,
+	} {
+		expanded := expandEnv(*field, u.Env)
+		if expanded != *field {
+			t.fields[name] = envField{raw: *field, expanded: expanded}
+			*field = expanded
+		}
+	}
+	for k, a := range usr.Accounts {
+		expanded := &user.Account{
+			Keyword: expandEnv(a.Keyword, u.Env),
+			Account: expandEnv(a.Account, u.Env),
+		}
+		if expanded.Keyword != a.Keyword || expanded.Account != a.Account {
+			t.accounts[accountKey(expanded)] = a
+			usr.Accounts[k] = expanded
+		}
+	}
+	if len(t.fields) == 0 && len(t.accounts) == 0 {
+		return nil
+	}
+	return t
+}
+
+//rawUser return copy of user with unchanged expanded values replaced by raw values,
+//so that environment variables are not written back to files.
+func (u *Users) rawUser(usr *User) *User {
+	t := u.templates[usr.UID]
+	if t == nil {
+		return usr
+	}
+	raw := *usr
+	for name, field := range map[string]*string{
+		"UID":      &raw.UID,
+		"Password": &raw.Password,
+		"HashMode": &raw.HashMode,
+		"Salt":     &raw.Salt,
+	} {
+		if f, ok := t.fields[name]; ok && *field == f.expanded {
+			*field = f.raw
+		}
+	}
+	raw.Accounts = make([]*user.Account, len(usr.Accounts))
+	for k, a := range usr.Accounts {
+		raw.Accounts[k] = a
+		if r, ok := t.accounts[accountKey(a)]; ok {
+			raw.Accounts[k] = r
+		}
+	}
+	return &raw
+}
@@ -0,0 +1,112 @@
+package tomluser
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/herb-go/deprecated/member"
+	"github.com/herb-go/providers/herb/statictoml"
+	"github.com/herb-go/user"
+)
+
+func TestExpandEnv(t *testing.T) {
+	env := map[string]string{"NAME": "admin", "EMPTY": ""}
+	lookup := func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+	for raw, expected := range map[string]string{
+		"plain":                 "plain",
+		"${NAME}":               "admin",
+		"pre-${NAME}-post":      "pre-admin-post",
+		"${MISSING}":            "",
+		"${MISSING:-root}":      "root",
+		"${EMPTY:-root}":        "root",
+		"${NAME:-root}":         "admin",
+		"$2a$10$hash":           "$2a$10$hash",
+		"${NAME}${NAME}":        "adminadmin",
+		"${unclosed":            "${unclosed",
+		"$NAME":                 "$NAME",
+		"${NAME}/${MISSING:-x}": "admin/x",
+	} {
+		if result := expandEnv(raw, lookup); result != expected {
+			t.Fatal(raw, result)
+		}
+	}
+}
+
+func TestEnvUsers(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "")
+	if tmpdir == "" || err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+	os.Setenv("TOMLUSER_TEST_HASH", "passwordhash")
+	defer os.Unsetenv("TOMLUSER_TEST_HASH")
+	source := statictoml.Source(path.Join(tmpdir, "test.static.toml"))
+	data := NewData()
+	admin := NewUser()
+	admin.UID = "admin"
+	admin.Password = "${TOMLUSER_TEST_HASH}"
+	acc := user.NewAccount()
+	acc.Keyword = "name"
+	acc.Account = "${TOMLUSER_TEST_ADMIN:-root}"
+	admin.Accounts = append(admin.Accounts, acc)
+	bot := NewUser()
+	bot.UID = "bot"
+	data.Users = append(data.Users, admin, bot)
+	err = source.Save(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Config{
+		Source:    source,
+		ExpandEnv: true,
+	}
+	users, err := c.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if users.uidmap["admin"].Password != "passwordhash" {
+		t.Fatal(users.uidmap["admin"].Password)
+	}
+	account := user.NewAccount()
+	account.Keyword = "name"
+	account.Account = "root"
+	uid, err := users.AccountToUID(account)
+	if uid != "admin" || err != nil {
+		t.Fatal(uid, err)
+	}
+	err = users.SetStatus("bot", member.StatusBanned)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloaded := NewData()
+	err = source.Load(reloaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range reloaded.Users {
+		if v.UID == "admin" {
+			if v.Password != "${TOMLUSER_TEST_HASH}" || v.Accounts[0].Account != "${TOMLUSER_TEST_ADMIN:-root}" {
+				t.Fatal(v.Password, v.Accounts[0].Account)
+			}
+		}
+	}
+	err = users.UpdatePassword("admin", "newpassword")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloaded = NewData()
+	err = source.Load(reloaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range reloaded.Users {
+		if v.UID == "admin" && (v.Password == "${TOMLUSER_TEST_HASH}" || v.Password == "passwordhash") {
+			t.Fatal(v.Password)
+		}
+	}
+}
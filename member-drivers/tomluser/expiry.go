@@ -0,0 +1,70 @@
+package tomluser
+
+import (
+	"sort"
+	"time"
+
+	"github.com/herb-go/deprecated/member"
+)
+
+//SetExpiry set user expiry time.
+//Zero time means user never expires.
+//Expired status set by sweeper will be reset.
+//Return any error if raised.
+func (u *Users) SetExpiry(uid string, expired time.Time) error {
+	if u.ReadOnly {
+		return ErrReadOnlyStore
+	}
+	u.locker.Lock()
+	defer u.locker.Unlock()
+	user := u.uidmap[uid]
+	if user == nil {
+		return member.ErrUserNotFound
+	}
+	if expired.IsZero() {
+		user.ExpiredAt = 0
+	} else {
+		user.ExpiredAt = expired.Unix()
+	}
+	user.Expired = false
+	return u.save()
+}
+
+//Expiries return expiry time map of given uid list.
+//Users never expire will not be included.
+//Return expiry map and any error if raised.
+func (u *Users) Expiries(uid ...string) (map[string]time.Time, error) {
+	u.locker.RLock()
+	defer u.locker.RUnlock()
+	result := map[string]time.Time{}
+	for _, id := range uid {
+		user := u.uidmap[id]
+		if user == nil || user.ExpiredAt <= 0 {
+			continue
+		}
+		result[id] = time.Unix(user.ExpiredAt, 0)
+	}
+	return result, nil
+}
+
+//ExpireUsers transition users expired before given time to expired status.
+//Users expired are reported as member.StatusExpired by Statuses even before transitioned.
+//Transition is kept in memory only if users store is read only.
+//Return uid list of users transitioned and any error if raised.
+func (u *Users) ExpireUsers(now time.Time) ([]string, error) {
+	u.locker.Lock()
+	defer u.locker.Unlock()
+	result := []string{}
+	for uid, user := range u.uidmap {
+		if user.Expired || !user.IsExpired(now) {
+			continue
+		}
+		user.Expired = true
+		result = append(result, uid)
+	}
+	if len(result) == 0 {
+		return result, nil
+	}
+	sort.Strings(result)
+	return result, u.save()
+}
@@ -0,0 +1,98 @@
+package tomluser
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/herb-go/deprecated/member"
+	"github.com/herb-go/providers/herb/statictoml"
+)
+
+func TestExpiry(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "")
+	if tmpdir == "" || err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+	source := path.Join(tmpdir, "test.static.toml")
+	data := NewData()
+	u := NewUser()
+	u.UID = "testuid"
+	data.Users = append(data.Users, u)
+	contractor := NewUser()
+	contractor.UID = "contractor"
+	contractor.ExpiredAt = time.Now().Add(-time.Hour).Unix()
+	data.Users = append(data.Users, contractor)
+	err = statictoml.Source(source).Save(data)
+	if err != nil {
+		panic(err)
+	}
+	c := &Config{
+		Source:           statictoml.Source(source),
+		AsStatusProvider: true,
+	}
+	m := member.New()
+	err = c.Execute(m)
+	if err != nil {
+		panic(err)
+	}
+	expired := []string{}
+	m.OnUserExpired = func(uid string) {
+		expired = append(expired, uid)
+	}
+	ss := member.NewStatusStore()
+	err = m.Status().Load(ss, u.UID, contractor.UID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := ss.Get(u.UID); *status != member.StatusNormal {
+		t.Fatal(status)
+	}
+	if status := ss.Get(contractor.UID); *status != member.StatusExpired {
+		t.Fatal(status)
+	}
+	uids, err := m.Status().SweepExpired()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(uids) != 1 || uids[0] != contractor.UID || len(expired) != 1 || expired[0] != contractor.UID {
+		t.Fatal(uids, expired)
+	}
+	uids, err = m.Status().SweepExpired()
+	if err != nil || len(uids) != 0 {
+		t.Fatal(uids, err)
+	}
+	err = m.Status().SetExpiry(u.UID, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expiries, err := m.Status().Expiries(u.UID, contractor.UID)
+	if err != nil || len(expiries) != 2 {
+		t.Fatal(expiries, err)
+	}
+	err = m.Status().SetExpiry(contractor.UID, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss = member.NewStatusStore()
+	err = m.Status().Load(ss, contractor.UID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := ss.Get(contractor.UID); *status != member.StatusNormal {
+		t.Fatal(status)
+	}
+	reloaded := NewData()
+	err = statictoml.Source(source).Load(reloaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range reloaded.Users {
+		if v.UID == u.UID && v.ExpiredAt == 0 {
+			t.Fatal(v)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package tomluser
+
+import (
+	"errors"
+	"path/filepath"
+
+	"github.com/herb-go/providers/herb/statictoml"
+)
+
+//ErrIncludeCycle error raised when user files include each other.
+var ErrIncludeCycle = errors.New("tomluser include cycle")
+
+type userFile struct {
+	Source  statictoml.Source
+	Include []string
+	Users   []*User
+}
+
+type fileLoader struct {
+	loaded   map[string]bool
+	visiting map[string]bool
+	files    []*userFile
+}
+
+func (l *fileLoader) load(source statictoml.Source) error {
+	abs, err := source.Abs()
+	if err != nil {
+		return err
+	}
+	key := string(abs)
+	if l.visiting[key] {
+		return ErrIncludeCycle
+	}
+	if l.loaded[key] {
+		return nil
+	}
+	l.visiting[key] = true
+	defer delete(l.visiting, key)
+	data := NewData()
+	err = source.Load(data)
+	if err != nil {
+		return err
+	}
+	l.loaded[key] = true
+	l.files = append(l.files, &userFile{
+		Source:  source,
+		Include: data.Include,
+		Users:   data.Users,
+	})
+	dir := filepath.Dir(key)
+	for _, v := range data.Include {
+		if !filepath.IsAbs(v) {
+			v = filepath.Join(dir, v)
+		}
+		err = l.load(statictoml.Source(v))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//loadUserFiles load given source and all included files.
+//Included paths are resolved relative to the including file.
+//Files included more than once are loaded only once.
+//Return loaded files with given source first and any error if raised.
+func loadUserFiles(source statictoml.Source) ([]*userFile, error) {
+	l := &fileLoader{
+		loaded:   map[string]bool{},
+		visiting: map[string]bool{},
+	}
+	err := l.load(source)
+	if err != nil {
+		return nil, err
+	}
+	return l.files, nil
+}
+
+//setFiles replace users with users in given files.
+//Environment variables in users are expanded if Env is set.
+//Users lock should be held by caller.
+func (u *Users) setFiles(files []*userFile) {
+	u.uidmap = map[string]*User{}
+	u.accountmap = map[string][]*User{}
+	u.files = make([]*userFile, len(files))
+	u.userFiles = map[string]statictoml.Source{}
+	u.templates = map[string]*userTemplate{}
+	for k, f := range files {
+		u.files[k] = &userFile{Source: f.Source, Include: f.Include}
+		for _, user := range f.Users {
+			if t := u.expandUser(user); t != nil {
+				u.templates[user.UID] = t
+			}
+			u.addUser(user)
+			u.userFiles[user.UID] = f.Source
+		}
+	}
+}
+
+//saveFiles save users back to files which they are loaded from.
+//New users are saved to root file.
+func (u *Users) saveFiles() error {
+	data := make(map[statictoml.Source]*Data, len(u.files))
+	for _, f := range u.files {
+		data[f.Source] = &Data{Include: f.Include, Users: []*User{}}
+	}
+	root := u.files[0].Source
+	for uid, user := range u.uidmap {
+		source, ok := u.userFiles[uid]
+		if !ok {
+			source = root
+		}
+		data[source].Users = append(data[source].Users, u.rawUser(user))
+	}
+	for _, f := range u.files {
+		err := f.Source.Save(data[f.Source])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
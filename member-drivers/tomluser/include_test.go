@@ -0,0 +1,84 @@
+package tomluser
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/herb-go/providers/herb/statictoml"
+)
+
+func TestInclude(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "")
+	if tmpdir == "" || err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+	err = os.Mkdir(path.Join(tmpdir, "groups"), 0700)
+	if err != nil {
+		panic(err)
+	}
+	source := statictoml.Source(path.Join(tmpdir, "test.static.toml"))
+	root := NewData()
+	root.Include = []string{"groups/admins.toml", "groups/bots.toml"}
+	u1 := NewUser()
+	u1.UID = "user1"
+	root.Users = append(root.Users, u1)
+	admins := NewData()
+	admins.Include = []string{"bots.toml"}
+	u2 := NewUser()
+	u2.UID = "admin1"
+	admins.Users = append(admins.Users, u2)
+	bots := NewData()
+	u3 := NewUser()
+	u3.UID = "bot1"
+	bots.Users = append(bots.Users, u3)
+	for f, data := range map[string]*Data{"test.static.toml": root, "groups/admins.toml": admins, "groups/bots.toml": bots} {
+		err = statictoml.Source(path.Join(tmpdir, f)).Save(data)
+		if err != nil {
+			panic(err)
+		}
+	}
+	c := &Config{
+		Source: source,
+	}
+	users, err := c.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, uid := range []string{"user1", "admin1", "bot1"} {
+		if users.uidmap[uid] == nil {
+			t.Fatal(uid)
+		}
+	}
+	err = users.UpdatePassword("admin1", "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloaded := NewData()
+	err = statictoml.Source(path.Join(tmpdir, "groups/admins.toml")).Load(reloaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reloaded.Users) != 1 || reloaded.Users[0].UID != "admin1" || reloaded.Users[0].Password == "" || len(reloaded.Include) != 1 {
+		t.Fatal(reloaded)
+	}
+	reloaded = NewData()
+	err = source.Load(reloaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reloaded.Users) != 1 || reloaded.Users[0].UID != "user1" {
+		t.Fatal(reloaded)
+	}
+	bots.Include = []string{"../test.static.toml"}
+	err = statictoml.Source(path.Join(tmpdir, "groups/bots.toml")).Save(bots)
+	if err != nil {
+		panic(err)
+	}
+	_, err = users.Reconcile()
+	if err != ErrIncludeCycle {
+		t.Fatal(err)
+	}
+}
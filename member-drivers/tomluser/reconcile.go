@@ -0,0 +1,117 @@
+package tomluser
+
+import (
+	"reflect"
+	"time"
+)
+
+//Change types
+const (
+	//ChangeUserAdded user added to source.
+	ChangeUserAdded = "added"
+	//ChangeUserRemoved user removed from source.
+	ChangeUserRemoved = "removed"
+	//ChangeRolesChanged user roles changed in source.
+	ChangeRolesChanged = "roleschanged"
+	//ChangeUserUpdated user password,status,expiry or accounts changed in source.
+	ChangeUserUpdated = "updated"
+)
+
+//Change user change found by reconciliation.
+type Change struct {
+	//Type change type.
+	Type string
+	//UID changed user id.
+	UID string
+	//User current user.
+	//Removed user if change type is ChangeUserRemoved.
+	User *User
+}
+
+func diffUser(old *User, current *User) []*Change {
+	result := []*Change{}
+	if !reflect.DeepEqual(old.Roles, current.Roles) {
+		result = append(result, &Change{Type: ChangeRolesChanged, UID: current.UID, User: current})
+	}
+	if old.Password != current.Password ||
+		old.HashMode != current.HashMode ||
+		old.Salt != current.Salt ||
+		old.Banned != current.Banned ||
+		old.ExpiredAt != current.ExpiredAt ||
+		old.Expired != current.Expired ||
+		!reflect.DeepEqual(old.Accounts, current.Accounts) {
+		result = append(result, &Change{Type: ChangeUserUpdated, UID: current.UID, User: current})
+	}
+	return result
+}
+
+//Reconcile reload users from source and included files,and replace in-memory users.
+//OnChange hook will be called with every change found.
+//Return changes and any error if raised.
+func (u *Users) Reconcile() ([]*Change, error) {
+	files, err := loadUserFiles(u.Source)
+	if err != nil {
+		return nil, err
+	}
+	changes := []*Change{}
+	u.locker.Lock()
+	old := u.uidmap
+	u.setFiles(files)
+	for _, f := range files {
+		for _, user := range f.Users {
+			olduser, ok := old[user.UID]
+			if !ok {
+				changes = append(changes, &Change{Type: ChangeUserAdded, UID: user.UID, User: user})
+				continue
+			}
+			changes = append(changes, diffUser(olduser, user)...)
+		}
+	}
+	for uid := range old {
+		if u.uidmap[uid] == nil {
+			changes = append(changes, &Change{Type: ChangeUserRemoved, UID: uid, User: old[uid]})
+		}
+	}
+	u.locker.Unlock()
+	if u.OnChange != nil {
+		for _, c := range changes {
+			u.OnChange(c)
+		}
+	}
+	return changes, nil
+}
+
+//StartReconcile start reconciliation loop which calls Reconcile every given interval.
+//Errors raised will be passed to OnReconcileError if not nil.
+func (u *Users) StartReconcile(interval time.Duration) {
+	u.StopReconcile()
+	stop := make(chan struct{})
+	u.reconcileLocker.Lock()
+	u.reconcileStop = stop
+	u.reconcileLocker.Unlock()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				_, err := u.Reconcile()
+				if err != nil && u.OnReconcileError != nil {
+					u.OnReconcileError(err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+//StopReconcile stop reconciliation loop if started.
+func (u *Users) StopReconcile() {
+	u.reconcileLocker.Lock()
+	defer u.reconcileLocker.Unlock()
+	if u.reconcileStop != nil {
+		close(u.reconcileStop)
+		u.reconcileStop = nil
+	}
+}
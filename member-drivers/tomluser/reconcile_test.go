@@ -0,0 +1,79 @@
+package tomluser
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/herb-go/herbsecurity/authorize/role"
+	"github.com/herb-go/providers/herb/statictoml"
+)
+
+func TestReconcile(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "")
+	if tmpdir == "" || err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+	source := statictoml.Source(path.Join(tmpdir, "test.static.toml"))
+	data := NewData()
+	u1 := NewUser()
+	u1.UID = "user1"
+	u2 := NewUser()
+	u2.UID = "user2"
+	data.Users = append(data.Users, u1, u2)
+	err = source.Save(data)
+	if err != nil {
+		panic(err)
+	}
+	c := &Config{
+		Source: source,
+	}
+	users, err := c.Load()
+	if err != nil {
+		panic(err)
+	}
+	changes, err := users.Reconcile()
+	if len(changes) != 0 || err != nil {
+		t.Fatal(changes, err)
+	}
+	data = NewData()
+	u1 = NewUser()
+	u1.UID = "user1"
+	u1.Roles.Append(role.NewRole("admin"))
+	u3 := NewUser()
+	u3.UID = "user3"
+	data.Users = append(data.Users, u1, u3)
+	err = source.Save(data)
+	if err != nil {
+		panic(err)
+	}
+	result := make(chan *Change, 10)
+	users.OnChange = func(c *Change) {
+		result <- c
+	}
+	users.StartReconcile(10 * time.Millisecond)
+	defer users.StopReconcile()
+	found := map[string]string{}
+	for len(found) < 3 {
+		select {
+		case c := <-result:
+			found[c.UID] = c.Type
+		case <-time.After(time.Second):
+			t.Fatal(found)
+		}
+	}
+	if found["user1"] != ChangeRolesChanged || found["user2"] != ChangeUserRemoved || found["user3"] != ChangeUserAdded {
+		t.Fatal(found)
+	}
+	users.StopReconcile()
+	roles, err := users.Roles("user1", "user2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if (*roles)["user2"] != nil || (*roles)["user1"] == nil || len(*(*roles)["user1"]) != 1 {
+		t.Fatal(roles)
+	}
+}
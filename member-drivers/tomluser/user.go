@@ -28,6 +28,11 @@ type User struct {
 	Accounts []*user.Account
 	Banned   bool
 	Roles    *role.Roles
+	//ExpiredAt user expiry timestamp in second.
+	//User never expires if ExpiredAt is 0.
+	ExpiredAt int64
+	//Expired whether user is transitioned to expired status by sweeper.
+	Expired bool
 }
 
 func (u *User) Clone() *User {
@@ -38,6 +43,8 @@ func (u *User) Clone() *User {
 	newuser.Accounts = make([]*user.Account, len(newuser.Accounts))
 	copy(newuser.Accounts, u.Accounts)
 	newuser.Banned = u.Banned
+	newuser.ExpiredAt = u.ExpiredAt
+	newuser.Expired = u.Expired
 	roles := make(role.Roles, len(*u.Roles))
 	newuser.Roles = &roles
 	copy(*newuser.Roles, *u.Roles)
@@ -51,6 +58,13 @@ func (u *User) SetTo(newuser *User) {
 	newuser.Accounts = u.Accounts
 	newuser.Banned = u.Banned
 	newuser.Roles = u.Roles
+	newuser.ExpiredAt = u.ExpiredAt
+	newuser.Expired = u.Expired
+}
+
+//IsExpired check if user is expired at given time.
+func (u *User) IsExpired(now time.Time) bool {
+	return u.Expired || (u.ExpiredAt > 0 && u.ExpiredAt <= now.Unix())
 }
 
 func (u *User) VerifyPassword(password string) (bool, error) {
@@ -1,7 +1,9 @@
 package tomluser
 
 import (
+	"errors"
 	"sync"
+	"time"
 
 	"github.com/herb-go/uniqueid"
 
@@ -11,6 +13,9 @@ import (
 	"github.com/herb-go/deprecated/member"
 )
 
+//ErrReadOnlyStore error raised when mutating users in read only store.
+var ErrReadOnlyStore = errors.New("tomluser store is read only")
+
 type Users struct {
 	Source     statictoml.Source
 	locker     sync.RWMutex
@@ -18,6 +23,22 @@ type Users struct {
 	accountmap map[string][]*User
 	idFactory  func() (string, error)
 	HashMode   string
+	//ReadOnly whether users store is read only.
+	//All mutation methods will return ErrReadOnlyStore if true.
+	ReadOnly bool
+	//OnChange hook called with every change found by reconciliation.
+	OnChange func(c *Change)
+	//OnReconcileError hook called with error raised in reconciliation loop.
+	OnReconcileError func(err error)
+	reconcileLocker  sync.Mutex
+	reconcileStop    chan struct{}
+	files            []*userFile
+	userFiles        map[string]statictoml.Source
+	//Env environment variable lookup function used to expand ${NAME} and ${NAME:-default} in loaded users.
+	//Expansion is disabled if nil.
+	//Expanded values unchanged are saved back as raw values.
+	Env       func(key string) (string, bool)
+	templates map[string]*userTemplate
 }
 
 func NewUsers() *Users {
@@ -32,11 +53,17 @@ func (u *Users) getAllUsers() *Data {
 	data := NewData()
 	data.Users = make([]*User, 0, len(u.uidmap))
 	for k := range u.uidmap {
-		data.Users = append(data.Users, u.uidmap[k])
+		data.Users = append(data.Users, u.rawUser(u.uidmap[k]))
 	}
 	return data
 }
 func (u *Users) save() error {
+	if u.ReadOnly {
+		return nil
+	}
+	if len(u.files) > 1 {
+		return u.saveFiles()
+	}
 	return u.Source.Save(u.getAllUsers())
 }
 
@@ -46,6 +73,7 @@ func (u *Users) Statuses(uid ...string) (member.StatusMap, error) {
 	u.locker.RLock()
 	defer u.locker.RUnlock()
 	m := member.StatusMap{}
+	now := time.Now()
 	for _, id := range uid {
 		user := u.uidmap[id]
 		if user == nil {
@@ -53,6 +81,8 @@ func (u *Users) Statuses(uid ...string) (member.StatusMap, error) {
 		}
 		if user.Banned {
 			m[id] = member.StatusBanned
+		} else if user.IsExpired(now) {
+			m[id] = member.StatusExpired
 		} else {
 			m[id] = member.StatusNormal
 		}
@@ -63,6 +93,9 @@ func (u *Users) Statuses(uid ...string) (member.StatusMap, error) {
 //SetStatus set user status.
 //Return any error if raised.
 func (u *Users) SetStatus(uid string, status member.Status) error {
+	if u.ReadOnly {
+		return ErrReadOnlyStore
+	}
 	u.locker.Lock()
 	defer u.locker.Unlock()
 	if u.uidmap[uid] == nil {
@@ -91,12 +124,15 @@ func (u *Users) VerifyPassword(uid string, password string) (bool, error) {
 
 //PasswordChangeable return password changeable
 func (u *Users) PasswordChangeable() bool {
-	return true
+	return !u.ReadOnly
 }
 
 //UpdatePassword update user password
 //Return any error if raised
 func (u *Users) UpdatePassword(uid string, password string) error {
+	if u.ReadOnly {
+		return ErrReadOnlyStore
+	}
 	u.locker.Lock()
 	defer u.locker.Unlock()
 	user := u.uidmap[uid]
@@ -181,6 +217,9 @@ func (u *Users) register(account *user.Account) (uid string, err error) {
 //Return created user id and any error if raised.
 //Privoder should return ErrAccountRegisterExists if account is used.
 func (u *Users) Register(account *user.Account) (uid string, err error) {
+	if u.ReadOnly {
+		return "", ErrReadOnlyStore
+	}
 	u.locker.Lock()
 	defer u.locker.Unlock()
 	uid, err = u.accountToUID(account)
@@ -205,6 +244,9 @@ func (u *Users) AccountToUIDOrRegister(account *user.Account) (uid string, regis
 	if uid != "" {
 		return
 	}
+	if u.ReadOnly {
+		return "", false, ErrReadOnlyStore
+	}
 	uid, err = u.register(account)
 	if err != nil {
 		return "", false, err
@@ -216,6 +258,9 @@ func (u *Users) AccountToUIDOrRegister(account *user.Account) (uid string, regis
 //Return any error if raised.
 //If account exists,user.ErrAccountBindingExists should be rasied.
 func (u *Users) BindAccount(uid string, account *user.Account) error {
+	if u.ReadOnly {
+		return ErrReadOnlyStore
+	}
 	u.locker.Lock()
 	defer u.locker.Unlock()
 	accountuser := u.uidmap[uid]
@@ -238,6 +283,9 @@ func (u *Users) BindAccount(uid string, account *user.Account) error {
 //Return any error if raised.
 //If account not exists,user.ErrAccountUnbindingNotExists should be rasied.
 func (u *Users) UnbindAccount(uid string, account *user.Account) error {
+	if u.ReadOnly {
+		return ErrReadOnlyStore
+	}
 	u.locker.Lock()
 	defer u.locker.Unlock()
 	accountid, err := u.accountToUID(account)
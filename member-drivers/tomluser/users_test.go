@@ -265,3 +265,83 @@ func TestConfig(t *testing.T) {
 		t.Fatal(status)
 	}
 }
+
+func TestReadOnly(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "")
+	if tmpdir == "" || err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+	source := path.Join(tmpdir, "test.static.toml")
+	data := NewData()
+	acc := user.NewAccount()
+	acc.Account = "testaccount"
+	acc.Keyword = "testkeyword"
+	acctoregister := user.NewAccount()
+	acctoregister.Account = "acctoregister"
+	acctoregister.Keyword = "testkeyword"
+	u := NewUser()
+	u.UID = "testuid"
+	u.Password = "password"
+	u.Accounts = append(u.Accounts, acc)
+	data.Users = append(data.Users, u)
+	err = statictoml.Source(source).Save(data)
+	if err != nil {
+		panic(err)
+	}
+	c := &Config{
+		Source:             statictoml.Source(source),
+		AsPasswordProvider: true,
+		AsStatusProvider:   true,
+		AsAccountsProvider: true,
+		ReadOnly:           true,
+	}
+	m := member.New()
+	err = c.Execute(m)
+	if err != nil {
+		panic(err)
+	}
+	if m.Password().PasswordChangeable() {
+		t.Fatal("password should not be changeable")
+	}
+	err = m.Password().UpdatePassword(u.UID, "newpassword")
+	if err != ErrReadOnlyStore {
+		t.Fatal(err)
+	}
+	ok, err := m.Password().VerifyPassword(u.UID, "password")
+	if !ok || err != nil {
+		t.Fatal(ok, err)
+	}
+	err = m.Status().SetStatus(u.UID, member.StatusBanned)
+	if err != ErrReadOnlyStore {
+		t.Fatal(err)
+	}
+	ss := member.NewStatusStore()
+	err = m.Status().Load(ss, u.UID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := ss.Get(u.UID); *status != member.StatusNormal {
+		t.Fatal(status)
+	}
+	_, err = m.Accounts().Register(acctoregister)
+	if err != ErrReadOnlyStore {
+		t.Fatal(err)
+	}
+	uid, ok, err := m.Accounts().AccountToUIDOrRegister(acc)
+	if uid != u.UID || ok || err != nil {
+		t.Fatal(uid, ok, err)
+	}
+	_, _, err = m.Accounts().AccountToUIDOrRegister(acctoregister)
+	if err != ErrReadOnlyStore {
+		t.Fatal(err)
+	}
+	err = m.Accounts().BindAccount(u.UID, acctoregister)
+	if err != ErrReadOnlyStore {
+		t.Fatal(err)
+	}
+	err = m.Accounts().UnbindAccount(u.UID, acc)
+	if err != ErrReadOnlyStore {
+		t.Fatal(err)
+	}
+}
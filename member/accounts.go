@@ -46,7 +46,7 @@ func (s *ServiceAccounts) loader(keys ...string) (map[string]interface{}, error)
 	}
 	result = map[string]interface{}{}
 	for k := range *data {
-		v := (*data)[k]
+		v := s.service.realmAccounts((*data)[k])
 		result[k] = &v
 	}
 	return result, nil
@@ -54,7 +54,7 @@ func (s *ServiceAccounts) loader(keys ...string) (map[string]interface{}, error)
 
 //Cache Return member accounts cache.
 func (s *ServiceAccounts) Cache() cache.Cacheable {
-	return s.service.AccountsCache
+	return s.service.realmCache(s.service.AccountsCache)
 }
 
 //Clean clean accounts cache by uid.
@@ -80,20 +80,32 @@ func (s *ServiceAccounts) Load(accounts datastore.Store, keys ...string) error {
 //Register create new user with given account.
 //Return created user id and any error if raised.
 func (s *ServiceAccounts) Register(account *user.Account) (uid string, err error) {
-	return s.service.AccountsProvider.Register(account)
+	uid, err = s.service.AccountsProvider.Register(s.service.RealmAccount(account))
+	if err != nil {
+		return "", err
+	}
+	s.service.Analytics.registered()
+	return uid, nil
 }
 
 //AccountToUID query uid by user account.
 //Return user id and any error if raised.
 //Return empty string as userid if account not found.
 func (s *ServiceAccounts) AccountToUID(account *user.Account) (uid string, err error) {
-	return s.service.AccountsProvider.AccountToUID(account)
+	return s.service.AccountsProvider.AccountToUID(s.service.RealmAccount(account))
 }
 
 //AccountToUIDOrRegister query uid by user account.Register user if account not found.
 //Return user id ,whether registered and any error if raised.
 func (s *ServiceAccounts) AccountToUIDOrRegister(account *user.Account) (uid string, registerd bool, err error) {
-	return s.service.AccountsProvider.AccountToUIDOrRegister(account)
+	uid, registerd, err = s.service.AccountsProvider.AccountToUIDOrRegister(s.service.RealmAccount(account))
+	if err != nil {
+		return "", false, err
+	}
+	if registerd {
+		s.service.Analytics.registered()
+	}
+	return uid, registerd, nil
 }
 
 //BindAccount bind account to user.
@@ -101,7 +113,7 @@ func (s *ServiceAccounts) AccountToUIDOrRegister(account *user.Account) (uid str
 //Return any error if raised.
 //If account exists,user.ErrAccountBindingExists should be rasied.
 func (s *ServiceAccounts) BindAccount(uid string, account *user.Account) error {
-	err := s.service.AccountsProvider.BindAccount(uid, account)
+	err := s.service.AccountsProvider.BindAccount(uid, s.service.RealmAccount(account))
 	if err != nil {
 		return err
 	}
@@ -113,7 +125,7 @@ func (s *ServiceAccounts) BindAccount(uid string, account *user.Account) error {
 //Return any error if raised.
 //If account not exists,user.ErrAccountUnbindingNotExists should be rasied.
 func (s *ServiceAccounts) UnbindAccount(uid string, account *user.Account) error {
-	err := s.service.AccountsProvider.UnbindAccount(uid, account)
+	err := s.service.AccountsProvider.UnbindAccount(uid, s.service.RealmAccount(account))
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,167 @@
+package member
+
+import (
+	"sync"
+	"time"
+)
+
+//DefaultAnalyticsInterval default interval of analytics reports.
+var DefaultAnalyticsInterval = time.Hour
+
+//StatusCounter optional status provider interface which can count users by status.
+type StatusCounter interface {
+	//CountStatus count users with given status.
+	//Return user count and any error if raised.
+	CountStatus(status Status) (int64, error)
+}
+
+//AnalyticsReport anonymized aggregate events in one period.
+//Report contains only counts,no user identifiers are included.
+type AnalyticsReport struct {
+	//Start start time of period.
+	Start time.Time
+	//End end time of period.
+	End time.Time
+	//Registrations count of users registered in period.
+	Registrations int64
+	//LoginSucceeded count of password verifications succeeded in period.
+	LoginSucceeded int64
+	//LoginFailed count of password verifications failed in period.
+	LoginFailed int64
+	//Bans count of users banned in period.
+	Bans int64
+	//ActiveBans count of users currently banned.
+	//ActiveBans is -1 if status provider does not implement StatusCounter.
+	ActiveBans int64
+}
+
+//FailedLoginRatio return ratio of failed logins in period.
+//Return 0 if no login in period.
+func (r *AnalyticsReport) FailedLoginRatio() float64 {
+	total := r.LoginSucceeded + r.LoginFailed
+	if total == 0 {
+		return 0
+	}
+	return float64(r.LoginFailed) / float64(total)
+}
+
+//AnalyticsSink metrics sink which receives analytics reports.
+type AnalyticsSink interface {
+	//ObserveAnalytics receive analytics report.
+	ObserveAnalytics(r *AnalyticsReport)
+}
+
+//AnalyticsSinkFunc analytics sink function.
+type AnalyticsSinkFunc func(r *AnalyticsReport)
+
+//ObserveAnalytics receive analytics report.
+func (f AnalyticsSinkFunc) ObserveAnalytics(r *AnalyticsReport) {
+	f(r)
+}
+
+//Analytics anonymized analytics counter of member service.
+type Analytics struct {
+	//Sink metrics sink which receives reports.
+	Sink    AnalyticsSink
+	locker  sync.Mutex
+	current *AnalyticsReport
+}
+
+//NewAnalytics create new analytics with given sink.
+func NewAnalytics(sink AnalyticsSink) *Analytics {
+	return &Analytics{
+		Sink:    sink,
+		current: &AnalyticsReport{Start: time.Now()},
+	}
+}
+
+func (a *Analytics) add(f func(r *AnalyticsReport)) {
+	if a == nil {
+		return
+	}
+	a.locker.Lock()
+	defer a.locker.Unlock()
+	f(a.current)
+}
+
+func (a *Analytics) registered() {
+	a.add(func(r *AnalyticsReport) {
+		r.Registrations++
+	})
+}
+
+func (a *Analytics) login(succeeded bool) {
+	a.add(func(r *AnalyticsReport) {
+		if succeeded {
+			r.LoginSucceeded++
+		} else {
+			r.LoginFailed++
+		}
+	})
+}
+
+func (a *Analytics) banned() {
+	a.add(func(r *AnalyticsReport) {
+		r.Bans++
+	})
+}
+
+func (a *Analytics) rotate() *AnalyticsReport {
+	a.locker.Lock()
+	defer a.locker.Unlock()
+	r := a.current
+	r.End = time.Now()
+	a.current = &AnalyticsReport{Start: r.End}
+	return r
+}
+
+//ReportAnalytics report counts since last report to analytics sink and reset counts.
+//Active bans will be counted if status provider implements StatusCounter.
+//Return any error if raised.
+func (s *Service) ReportAnalytics() error {
+	if s.Analytics == nil {
+		return nil
+	}
+	r := s.Analytics.rotate()
+	r.ActiveBans = -1
+	if p, ok := s.StatusProvider.(StatusCounter); ok {
+		count, err := p.CountStatus(StatusBanned)
+		if err != nil {
+			return err
+		}
+		r.ActiveBans = count
+	}
+	if s.Analytics.Sink != nil {
+		s.Analytics.Sink.ObserveAnalytics(r)
+	}
+	return nil
+}
+
+//StartAnalyticsReporter start background reporter which calls ReportAnalytics every given interval.
+//DefaultAnalyticsInterval will be used if interval is not positive.
+//Errors raised will be passed to onError if not nil.
+//Return function which stops reporter.
+func (s *Service) StartAnalyticsReporter(interval time.Duration, onError func(err error)) func() {
+	if interval <= 0 {
+		interval = DefaultAnalyticsInterval
+	}
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				err := s.ReportAnalytics()
+				if err != nil && onError != nil {
+					onError(err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+	}
+}
@@ -0,0 +1,70 @@
+package member
+
+import (
+	"testing"
+)
+
+func TestAnalytics(t *testing.T) {
+	s := testService()
+	var reports []*AnalyticsReport
+	s.Analytics = NewAnalytics(AnalyticsSinkFunc(func(r *AnalyticsReport) {
+		reports = append(reports, r)
+	}))
+	uid, err := s.Accounts().Register(newTestAccount("analytics1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.Accounts().Register(newTestAccount("analytics1"))
+	if err != ErrAccountRegisterExists {
+		t.Fatal(err)
+	}
+	_, registered, err := s.Accounts().AccountToUIDOrRegister(newTestAccount("analytics2"))
+	if err != nil || !registered {
+		t.Fatal(registered, err)
+	}
+	_, registered, err = s.Accounts().AccountToUIDOrRegister(newTestAccount("analytics2"))
+	if err != nil || registered {
+		t.Fatal(registered, err)
+	}
+	err = s.Password().UpdatePassword(uid, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.Password().Authenticate(uid, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.Password().Authenticate(uid, "wrong")
+	if err != ErrBadCredentials {
+		t.Fatal(err)
+	}
+	err = s.Status().SetStatus(uid, StatusBanned)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.Password().Authenticate(uid, "password")
+	if err != ErrBanned {
+		t.Fatal(err)
+	}
+	err = s.ReportAnalytics()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 1 {
+		t.Fatal(reports)
+	}
+	r := reports[0]
+	if r.Registrations != 2 || r.LoginSucceeded != 1 || r.LoginFailed != 2 || r.Bans != 1 || r.ActiveBans != -1 {
+		t.Fatal(r)
+	}
+	if r.FailedLoginRatio() < 0.66 || r.FailedLoginRatio() > 0.67 {
+		t.Fatal(r.FailedLoginRatio())
+	}
+	err = s.ReportAnalytics()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 2 || reports[1].Registrations != 0 || !reports[1].Start.Equal(r.End) {
+		t.Fatal(reports)
+	}
+}
@@ -15,15 +15,16 @@ type Authorizer struct {
 
 //Authorize Authorize http request.
 func (a *Authorizer) Authorize(r *http.Request) (bool, error) {
-	uid, err := a.Service.IdentifyRequest(r)
+	var service = a.Service.RequestService(r)
+	uid, err := service.IdentifyRequest(r)
 	if err != nil {
 		return false, err
 	}
 	if uid == "" {
 		return false, nil
 	}
-	var members = a.Service.GetMembersFromRequest(r)
-	if a.Service.StatusProvider != nil {
+	var members = service.GetMembersFromRequest(r)
+	if service.StatusProvider != nil {
 		_, err = members.LoadStatus(uid)
 		if err != nil {
 			return false, err
@@ -33,7 +34,7 @@ func (a *Authorizer) Authorize(r *http.Request) (bool, error) {
 		}
 	}
 
-	if a.Service.RoleProvider == nil {
+	if service.RoleProvider == nil {
 		return true, nil
 	}
 	if a.PolicyLoader == nil {
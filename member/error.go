@@ -2,6 +2,77 @@ package member
 
 import "errors"
 
+//Error member error with user-safe message code.
+//Api layers can map error to http response by code without string matching.
+type Error struct {
+	//Code user-safe message code.
+	Code string
+	//Message error message.
+	Message string
+	//unavailable whether error is raised by unavailable user status.
+	unavailable bool
+}
+
+//Error return error message.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+//Is report whether error matches target.
+//Errors raised by unavailable user status match ErrUserBanned,
+//so that callers checking errors.Is(err, ErrUserBanned) keep working.
+func (e *Error) Is(target error) bool {
+	return e.unavailable && target == ErrUserBanned
+}
+
+//NewError create new member error with given code and message.
+func NewError(code string, message string) *Error {
+	return &Error{
+		Code:    code,
+		Message: message,
+	}
+}
+
+func newStatusError(code string, message string) *Error {
+	e := NewError(code, message)
+	e.unavailable = true
+	return e
+}
+
+//ErrorCode return user-safe message code of given error.
+//Return empty string if error is not a member error.
+func ErrorCode(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return ""
+}
+
+//CodeBadCredentials message code for bad credentials.
+const CodeBadCredentials = "bad-credentials"
+
+//CodeAccountLocked message code for locked account.
+const CodeAccountLocked = "account-locked"
+
+//CodeBanned message code for banned user.
+const CodeBanned = "banned"
+
+//CodeNeedsVerification message code for user needs verification.
+const CodeNeedsVerification = "needs-verification"
+
+//ErrBadCredentials errors rasied when user not found or password not match.
+var ErrBadCredentials = NewError(CodeBadCredentials, "bad credentials")
+
+//ErrAccountLocked errors rasied when user status is revoked or expired.
+var ErrAccountLocked = newStatusError(CodeAccountLocked, "account locked")
+
+//ErrNeedsVerification errors rasied when user status is pending.
+var ErrNeedsVerification = newStatusError(CodeNeedsVerification, "needs verification")
+
+//ErrBanned errors rasied when user status is banned.
+var ErrBanned = ErrUserBanned
+
 //ErrRegisteredDataNotMap errors rasied when registered user data struct is not a map struct.
 var ErrRegisteredDataNotMap = errors.New("registered user data is not a map")
 
@@ -9,7 +80,7 @@ var ErrRegisteredDataNotMap = errors.New("registered user data is not a map")
 var ErrAccountRegisterExists = errors.New("account registered exists")
 
 //ErrUserBanned errors rasied when user status is banned.
-var ErrUserBanned = errors.New("user banned")
+var ErrUserBanned = NewError(CodeBanned, "user banned")
 
 //ErrUserNotFound errors rasied when user is not found.
 var ErrUserNotFound = errors.New("user not found")
@@ -25,3 +96,26 @@ var ErrStatusNotSupport = errors.New("user status not  support")
 
 //ErrPasswordNotChangeable errors raised when password provider not support change password.
 var ErrPasswordNotChangeable = errors.New("password not changeable")
+
+//StatusError return member error of given user status.
+//Drivers only report user status,status errors are returned by Service.
+//Returned errors match ErrUserBanned with errors.Is,but not with ==.
+//Return nil if status is avaliable.
+func StatusError(s *Status) error {
+	if IsAvaliable(s) {
+		return nil
+	}
+	if s == nil {
+		return ErrBanned
+	}
+	switch *s {
+	case StatusPending:
+		return ErrNeedsVerification
+	case StatusRevoked, StatusExpired:
+		return ErrAccountLocked
+	}
+	return ErrBanned
+}
+
+//ErrInvalidExternalID errors raised when external id can not be decoded to user id.
+var ErrInvalidExternalID = errors.New("invalid external id")
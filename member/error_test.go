@@ -0,0 +1,50 @@
+package member
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestStatusError(t *testing.T) {
+	var testcases = map[Status]error{
+		StatusNormal:  nil,
+		StatusBanned:  ErrBanned,
+		StatusRevoked: ErrAccountLocked,
+		StatusPending: ErrNeedsVerification,
+		StatusExpired: ErrAccountLocked,
+	}
+	for status, expected := range testcases {
+		var s = status
+		if err := StatusError(&s); err != expected {
+			t.Fatal(status, err)
+		}
+	}
+	if err := StatusError(nil); err != ErrBanned {
+		t.Fatal(err)
+	}
+	for _, err := range []error{ErrAccountLocked, ErrNeedsVerification, fmt.Errorf("login: %w", ErrAccountLocked), ErrUserBanned} {
+		if !errors.Is(err, ErrUserBanned) {
+			t.Fatal(err)
+		}
+	}
+	if errors.Is(ErrBadCredentials, ErrUserBanned) || errors.Is(ErrAccountLocked, ErrNeedsVerification) {
+		t.Fatal(ErrBadCredentials)
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	if ErrorCode(ErrBadCredentials) != CodeBadCredentials {
+		t.Fatal(ErrorCode(ErrBadCredentials))
+	}
+	if ErrorCode(ErrUserBanned) != CodeBanned {
+		t.Fatal(ErrorCode(ErrUserBanned))
+	}
+	err := fmt.Errorf("login: %w", ErrNeedsVerification)
+	if ErrorCode(err) != CodeNeedsVerification || !errors.Is(err, ErrNeedsVerification) {
+		t.Fatal(err)
+	}
+	if ErrorCode(ErrUserNotFound) != "" || ErrorCode(nil) != "" {
+		t.Fatal(ErrorCode(ErrUserNotFound))
+	}
+}
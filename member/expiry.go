@@ -0,0 +1,103 @@
+package member
+
+import "time"
+
+//ExpiryProvider optional status provider interface which supports scheduled account expiry.
+//Expired users should be reported as StatusExpired by Statuses.
+type ExpiryProvider interface {
+	//SetExpiry set user expiry time.
+	//Zero time means user never expires.
+	//Return any error if raised.
+	SetExpiry(uid string, expired time.Time) error
+	//Expiries return expiry time map of given uid list.
+	//Users never expire will not be included.
+	//Return expiry map and any error if raised.
+	Expiries(uid ...string) (map[string]time.Time, error)
+	//ExpireUsers transition users expired before given time to StatusExpired.
+	//Return uid list of users transitioned and any error if raised.
+	ExpireUsers(now time.Time) ([]string, error)
+}
+
+func (s *ServiceStatus) expiryProvider() (ExpiryProvider, error) {
+	p, ok := s.service.StatusProvider.(ExpiryProvider)
+	if !ok {
+		return nil, ErrFeatureNotSupported
+	}
+	return p, nil
+}
+
+//SetExpiry set user expiry time.
+//Zero time means user never expires.
+//User status cache will be cleaned.
+//Return ErrFeatureNotSupported if status provider does not implement ExpiryProvider.
+func (s *ServiceStatus) SetExpiry(uid string, expired time.Time) error {
+	p, err := s.expiryProvider()
+	if err != nil {
+		return err
+	}
+	err = p.SetExpiry(uid, expired)
+	if err != nil {
+		return err
+	}
+	return s.Clean(uid)
+}
+
+//Expiries return expiry time map of given uid list.
+//Return ErrFeatureNotSupported if status provider does not implement ExpiryProvider.
+func (s *ServiceStatus) Expiries(uid ...string) (map[string]time.Time, error) {
+	p, err := s.expiryProvider()
+	if err != nil {
+		return nil, err
+	}
+	return p.Expiries(uid...)
+}
+
+//SweepExpired transition expired users to StatusExpired.
+//Status cache of expired users will be cleaned,and service OnUserExpired hook will be called with every expired user.
+//Return uid list of expired users and any error if raised.
+//Return ErrFeatureNotSupported if status provider does not implement ExpiryProvider.
+func (s *ServiceStatus) SweepExpired() ([]string, error) {
+	p, err := s.expiryProvider()
+	if err != nil {
+		return nil, err
+	}
+	uids, err := p.ExpireUsers(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	for _, uid := range uids {
+		err = s.Clean(uid)
+		if err != nil {
+			return nil, err
+		}
+		if s.service.OnUserExpired != nil {
+			s.service.OnUserExpired(uid)
+		}
+	}
+	return uids, nil
+}
+
+//StartExpirySweeper start background sweeper which calls SweepExpired every given interval.
+//Errors raised will be passed to onError if not nil.
+//Return function which stops sweeper.
+func (s *ServiceStatus) StartExpirySweeper(interval time.Duration, onError func(err error)) func() {
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				_, err := s.SweepExpired()
+				if err != nil && onError != nil {
+					onError(err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+	}
+}
@@ -0,0 +1,47 @@
+package member
+
+//IDEncoder reversible encoder which converts user ids to external ids and back,
+//so that internal user ids are not exposed.
+type IDEncoder interface {
+	//EncodeID encode user id to external id.
+	//Return external id and any error if raised.
+	EncodeID(uid string) (string, error)
+	//DecodeID decode external id to user id.
+	//Return user id and any error if raised.
+	//ErrInvalidExternalID should be returned if external id is malformed or tampered.
+	DecodeID(id string) (string, error)
+}
+
+//ExternalID return external id of given user id by service id encoder.
+//Return user id as is if id encoder not set.
+//Return external id and any error if raised.
+func (s *Service) ExternalID(uid string) (string, error) {
+	if s.IDEncoder == nil {
+		return uid, nil
+	}
+	return s.IDEncoder.EncodeID(uid)
+}
+
+//ExternalIDs return external ids of given user id list by service id encoder.
+//Return external ids in same order and any error if raised.
+func (s *Service) ExternalIDs(uid ...string) ([]string, error) {
+	result := make([]string, len(uid))
+	for k, v := range uid {
+		id, err := s.ExternalID(v)
+		if err != nil {
+			return nil, err
+		}
+		result[k] = id
+	}
+	return result, nil
+}
+
+//UIDFromExternalID decode incoming external id to user id by service id encoder.
+//Return external id as is if id encoder not set.
+//Return user id and any error if raised.
+func (s *Service) UIDFromExternalID(id string) (string, error) {
+	if s.IDEncoder == nil {
+		return id, nil
+	}
+	return s.IDEncoder.DecodeID(id)
+}
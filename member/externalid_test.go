@@ -0,0 +1,48 @@
+package member
+
+import (
+	"strings"
+	"testing"
+)
+
+type testIDEncoder struct{}
+
+func (e testIDEncoder) EncodeID(uid string) (string, error) {
+	return "ext-" + uid, nil
+}
+
+func (e testIDEncoder) DecodeID(id string) (string, error) {
+	if !strings.HasPrefix(id, "ext-") {
+		return "", ErrInvalidExternalID
+	}
+	return strings.TrimPrefix(id, "ext-"), nil
+}
+
+func TestExternalID(t *testing.T) {
+	s := New()
+	id, err := s.ExternalID("uid")
+	if err != nil || id != "uid" {
+		t.Fatal(id, err)
+	}
+	uid, err := s.UIDFromExternalID("uid")
+	if err != nil || uid != "uid" {
+		t.Fatal(uid, err)
+	}
+	s.IDEncoder = testIDEncoder{}
+	ids, err := s.ExternalIDs("uid1", "uid2")
+	if err != nil || len(ids) != 2 || ids[0] != "ext-uid1" || ids[1] != "ext-uid2" {
+		t.Fatal(ids, err)
+	}
+	uid, err = s.UIDFromExternalID("ext-uid1")
+	if err != nil || uid != "uid1" {
+		t.Fatal(uid, err)
+	}
+	_, err = s.UIDFromExternalID("uid1")
+	if err != ErrInvalidExternalID {
+		t.Fatal(err)
+	}
+	s.Reset()
+	if s.IDEncoder != nil {
+		t.Fatal(s.IDEncoder)
+	}
+}
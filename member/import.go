@@ -0,0 +1,397 @@
+package member
+
+import (
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/herb-go/user"
+)
+
+//DefaultImportChunkSize default count of records imported in one chunk.
+var DefaultImportChunkSize = 100
+
+//ErrImportAccountEmpty errors raised when account of import record is empty.
+var ErrImportAccountEmpty = errors.New("import account empty")
+
+//ErrImportDuplicated errors raised when import record duplicates an earlier record.
+var ErrImportDuplicated = errors.New("import record duplicated")
+
+//ErrImportRolledBack errors raised when import record is rolled back because other record in same chunk failed.
+//Rolled back users are compensated rather than deleted,see ImportResult.RolledBack.
+var ErrImportRolledBack = errors.New("import record rolled back")
+
+//ErrImportCSVColumnMissing errors raised when required column is missing in csv header.
+var ErrImportCSVColumnMissing = errors.New("import csv column missing")
+
+//ImportRecord user record to import.
+type ImportRecord struct {
+	//Line line number of record in source,starts from 1.
+	Line int `json:"-"`
+	//Keyword registered account keyword.
+	Keyword string `json:"keyword"`
+	//Account account name.
+	Account string `json:"account"`
+	//Password user password.
+	//Password will not be set if empty.
+	Password string `json:"password"`
+	//Status user status.
+	//Status will not be set if nil.
+	Status *Status `json:"status"`
+}
+
+//ImportFailure failed import record with error.
+type ImportFailure struct {
+	//Record failed record.
+	Record *ImportRecord
+	//Err error raised.
+	Err error
+}
+
+//ImportedUser imported user.
+type ImportedUser struct {
+	//Record imported record.
+	Record *ImportRecord
+	//UID registered user id.
+	UID string
+}
+
+//ImportProgress import progress reported after every chunk.
+type ImportProgress struct {
+	//Total total count of records.
+	Total int
+	//Processed count of records processed.
+	Processed int
+	//Imported count of records imported.
+	Imported int
+	//Failed count of records failed.
+	Failed int
+}
+
+//ImportResult import result.
+type ImportResult struct {
+	//Imported imported users.
+	Imported []*ImportedUser
+	//Failures failed records.
+	Failures []*ImportFailure
+	//RolledBack users registered but rolled back because other record in same chunk failed.
+	//Providers have no delete operation,so user ids remain in providers,
+	//with accounts unbound,passwords replaced by random unusable ones and status revoked.
+	RolledBack []*ImportedUser
+	//RollbackErrors errors raised when rolling back failed chunks.
+	RollbackErrors []error
+}
+
+//ImportOptions import options.
+type ImportOptions struct {
+	//ChunkSize count of records imported in one chunk.
+	//If any record in chunk failed,imported records in same chunk will be rolled back.
+	//Chunks are not database transactions,rollback is done by compensating operations.
+	//DefaultImportChunkSize will be used if not positive.
+	ChunkSize int
+	//OnProgress callback called after every chunk.
+	OnProgress func(p *ImportProgress)
+}
+
+//ParseImportCSV parse import records from csv reader.
+//First row should be header which contains keyword and account columns,
+//password and status columns are optional.
+//Return records and any error if raised.
+func ParseImportCSV(r io.Reader) ([]*ImportRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err == io.EOF {
+		return []*ImportRecord{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := map[string]int{}
+	for k, v := range header {
+		columns[strings.ToLower(strings.TrimSpace(v))] = k
+	}
+	if _, ok := columns["keyword"]; !ok {
+		return nil, ErrImportCSVColumnMissing
+	}
+	if _, ok := columns["account"]; !ok {
+		return nil, ErrImportCSVColumnMissing
+	}
+	field := func(row []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return row[i]
+	}
+	result := []*ImportRecord{}
+	line := 1
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line++
+		record := &ImportRecord{
+			Line:     line,
+			Keyword:  field(row, "keyword"),
+			Account:  field(row, "account"),
+			Password: field(row, "password"),
+		}
+		if s := field(row, "status"); s != "" {
+			i, err := strconv.Atoi(s)
+			if err != nil {
+				return nil, err
+			}
+			status := Status(i)
+			record.Status = &status
+		}
+		result = append(result, record)
+	}
+	return result, nil
+}
+
+//ParseImportJSON parse import records from json reader.
+//Json data should be array of records.
+//Return records and any error if raised.
+func ParseImportJSON(r io.Reader) ([]*ImportRecord, error) {
+	result := []*ImportRecord{}
+	err := json.NewDecoder(r).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+	for k := range result {
+		result[k].Line = k + 1
+	}
+	return result, nil
+}
+
+type serviceImport struct {
+	service *Service
+	result  *ImportResult
+}
+
+func (i *serviceImport) validate(records []*ImportRecord) []*ImportRecord {
+	valid := make([]*ImportRecord, 0, len(records))
+	seen := map[string]bool{}
+	for _, r := range records {
+		var err error
+		if r.Account == "" {
+			err = ErrImportAccountEmpty
+		} else if _, ok := i.service.AccountProviders[r.Keyword]; !ok {
+			err = ErrAccountKeywordNotRegistered
+		} else if r.Password != "" && (i.service.PasswordProvider == nil || !i.service.PasswordProvider.PasswordChangeable()) {
+			err = ErrPasswordNotChangeable
+		} else if r.Status != nil && (i.service.StatusProvider == nil || !i.service.StatusProvider.SupportedStatus()[*r.Status]) {
+			err = ErrStatusNotSupport
+		}
+		if err == nil {
+			var account *user.Account
+			account, err = i.service.NewAccount(r.Keyword, r.Account)
+			if err == nil {
+				key := account.Keyword + "\n" + account.Account
+				if seen[key] {
+					err = ErrImportDuplicated
+				}
+				seen[key] = true
+			}
+		}
+		if err != nil {
+			i.result.Failures = append(i.result.Failures, &ImportFailure{Record: r, Err: err})
+			continue
+		}
+		valid = append(valid, r)
+	}
+	return valid
+}
+
+func (i *serviceImport) importRecord(r *ImportRecord) (string, error) {
+	account, err := i.service.NewAccount(r.Keyword, r.Account)
+	if err != nil {
+		return "", err
+	}
+	uid, err := i.service.Accounts().AccountToUID(account)
+	if err != nil {
+		return "", err
+	}
+	if uid != "" {
+		return "", ErrAccountRegisterExists
+	}
+	uid, err = i.service.Accounts().Register(account)
+	if err != nil {
+		return "", err
+	}
+	if r.Password != "" {
+		err = i.service.Password().UpdatePassword(uid, r.Password)
+		if err != nil {
+			return uid, err
+		}
+	}
+	if r.Status != nil {
+		err = i.service.Status().SetStatus(uid, *r.Status)
+		if err != nil {
+			return uid, err
+		}
+	}
+	return uid, nil
+}
+
+//unusablePassword return random password which is never given to anyone.
+func unusablePassword() (string, error) {
+	buf := make([]byte, 32)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+//rollbackStatus return status set to rolled back users.
+//Return false if neither revoked nor banned status supported.
+func (i *serviceImport) rollbackStatus() (Status, bool) {
+	supported := i.service.StatusProvider.SupportedStatus()
+	if supported[StatusRevoked] {
+		return StatusRevoked, true
+	}
+	if supported[StatusBanned] {
+		return StatusBanned, true
+	}
+	return StatusNormal, false
+}
+
+//rollbackUser unbind account of given imported user,
+//replace password set by import with random unusable one and revoke user status.
+func (i *serviceImport) rollbackUser(u *ImportedUser) []error {
+	var errs []error
+	account, err := i.service.NewAccount(u.Record.Keyword, u.Record.Account)
+	if err == nil {
+		err = i.service.Accounts().UnbindAccount(u.UID, account)
+	}
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if u.Record.Password != "" {
+		password, err := unusablePassword()
+		if err == nil {
+			err = i.service.Password().UpdatePassword(u.UID, password)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if i.service.StatusProvider != nil {
+		if status, ok := i.rollbackStatus(); ok {
+			err = i.service.Status().SetStatus(u.UID, status)
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errs
+}
+
+func (i *serviceImport) rollback(imported []*ImportedUser) {
+	for _, u := range imported {
+		i.result.RollbackErrors = append(i.result.RollbackErrors, i.rollbackUser(u)...)
+	}
+	i.result.RolledBack = append(i.result.RolledBack, imported...)
+}
+
+func (i *serviceImport) importChunk(chunk []*ImportRecord) {
+	imported := make([]*ImportedUser, 0, len(chunk))
+	for k, r := range chunk {
+		uid, err := i.importRecord(r)
+		if uid != "" {
+			imported = append(imported, &ImportedUser{Record: r, UID: uid})
+		}
+		if err != nil {
+			i.rollback(imported)
+			for _, v := range chunk[:k] {
+				i.result.Failures = append(i.result.Failures, &ImportFailure{Record: v, Err: ErrImportRolledBack})
+			}
+			i.result.Failures = append(i.result.Failures, &ImportFailure{Record: r, Err: err})
+			for _, v := range chunk[k+1:] {
+				i.result.Failures = append(i.result.Failures, &ImportFailure{Record: v, Err: ErrImportRolledBack})
+			}
+			return
+		}
+	}
+	i.result.Imported = append(i.result.Imported, imported...)
+}
+
+//Import validate,deduplicate and import given records into configured providers in chunks.
+//Records with invalid or duplicated accounts are reported as failures without importing.
+//If any record in chunk failed,users imported in same chunk are rolled back and all records in chunk are reported as failures.
+//Rolled back users are not deleted,as providers have no delete operation.
+//Their accounts are unbound,passwords set by import are replaced by random unusable ones and statuses are revoked.
+//Return import result and any error if raised.
+func (s *Service) Import(records []*ImportRecord, opt *ImportOptions) (*ImportResult, error) {
+	if s.AccountsProvider == nil {
+		return nil, ErrFeatureNotSupported
+	}
+	if opt == nil {
+		opt = &ImportOptions{}
+	}
+	chunksize := opt.ChunkSize
+	if chunksize <= 0 {
+		chunksize = DefaultImportChunkSize
+	}
+	i := &serviceImport{
+		service: s,
+		result: &ImportResult{
+			Imported:       []*ImportedUser{},
+			Failures:       []*ImportFailure{},
+			RolledBack:     []*ImportedUser{},
+			RollbackErrors: []error{},
+		},
+	}
+	valid := i.validate(records)
+	progress := &ImportProgress{
+		Total:     len(records),
+		Processed: len(records) - len(valid),
+		Failed:    len(i.result.Failures),
+	}
+	for start := 0; start < len(valid); start = start + chunksize {
+		end := start + chunksize
+		if end > len(valid) {
+			end = len(valid)
+		}
+		i.importChunk(valid[start:end])
+		progress.Processed = progress.Processed + end - start
+		progress.Imported = len(i.result.Imported)
+		progress.Failed = len(i.result.Failures)
+		if opt.OnProgress != nil {
+			p := *progress
+			opt.OnProgress(&p)
+		}
+	}
+	return i.result, nil
+}
+
+//ImportCSV parse records from csv reader and import into configured providers.
+//Return import result and any error if raised.
+func (s *Service) ImportCSV(r io.Reader, opt *ImportOptions) (*ImportResult, error) {
+	records, err := ParseImportCSV(r)
+	if err != nil {
+		return nil, err
+	}
+	return s.Import(records, opt)
+}
+
+//ImportJSON parse records from json reader and import into configured providers.
+//Return import result and any error if raised.
+func (s *Service) ImportJSON(r io.Reader, opt *ImportOptions) (*ImportResult, error) {
+	records, err := ParseImportJSON(r)
+	if err != nil {
+		return nil, err
+	}
+	return s.Import(records, opt)
+}
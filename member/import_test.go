@@ -0,0 +1,117 @@
+package member
+
+import (
+	"strings"
+	"testing"
+)
+
+type testChangeablePasswordProvider struct {
+	*testPasswordProvider
+}
+
+func (p *testChangeablePasswordProvider) PasswordChangeable() bool {
+	return true
+}
+
+func TestImport(t *testing.T) {
+	s := testService()
+	passwords := newTestPasswordProvider()
+	s.PasswordProvider = &testChangeablePasswordProvider{passwords}
+	uid, err := s.Accounts().Register(newTestAccount("exists"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	csvdata := "keyword,account,password,status\n" +
+		"test,user1,pass1,\n" +
+		"test,user2,,1\n" +
+		"test,user1,pass,\n" +
+		"unknown,user3,,\n" +
+		"test,,,\n" +
+		"test,user4,,9\n" +
+		"test,user5,pass5,\n" +
+		"test,exists,,\n" +
+		"test,user6,,\n"
+	var progresses []*ImportProgress
+	result, err := s.ImportCSV(strings.NewReader(csvdata), &ImportOptions{
+		ChunkSize: 2,
+		OnProgress: func(p *ImportProgress) {
+			progresses = append(progresses, p)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Imported) != 3 || len(result.Failures) != 6 || len(result.RollbackErrors) != 0 {
+		t.Fatal(result)
+	}
+	imported := map[string]string{}
+	for _, v := range result.Imported {
+		imported[v.Record.Account] = v.UID
+	}
+	if imported["user1"] == "" || imported["user2"] == "" || imported["user6"] == "" {
+		t.Fatal(imported)
+	}
+	failures := map[int]error{}
+	for _, v := range result.Failures {
+		failures[v.Record.Line] = v.Err
+	}
+	if failures[4] != ErrImportDuplicated ||
+		failures[5] != ErrAccountKeywordNotRegistered ||
+		failures[6] != ErrImportAccountEmpty ||
+		failures[7] != ErrStatusNotSupport ||
+		failures[8] != ErrImportRolledBack ||
+		failures[9] != ErrAccountRegisterExists {
+		t.Fatal(failures)
+	}
+	if passwords.Passwords[imported["user1"]] != "pass1" {
+		t.Fatal(passwords.Passwords)
+	}
+	statuses, err := s.StatusProvider.Statuses(imported["user2"])
+	if err != nil || statuses[imported["user2"]] != StatusBanned {
+		t.Fatal(statuses, err)
+	}
+	u, err := s.Accounts().AccountToUID(newTestAccount("user5"))
+	if err != nil || u != "" {
+		t.Fatal(u, err)
+	}
+	if len(result.RolledBack) != 1 || result.RolledBack[0].Record.Account != "user5" {
+		t.Fatal(result.RolledBack)
+	}
+	rolledback := result.RolledBack[0].UID
+	if passwords.Passwords[rolledback] == "" || passwords.Passwords[rolledback] == "pass5" {
+		t.Fatal(passwords.Passwords)
+	}
+	statuses, err = s.StatusProvider.Statuses(rolledback)
+	if err != nil || statuses[rolledback] != StatusRevoked {
+		t.Fatal(statuses, err)
+	}
+	u, err = s.Accounts().AccountToUID(newTestAccount("exists"))
+	if err != nil || u != uid {
+		t.Fatal(u, err)
+	}
+	if len(progresses) != 3 {
+		t.Fatal(progresses)
+	}
+	last := progresses[2]
+	if last.Total != 9 || last.Processed != 9 || last.Imported != 3 || last.Failed != 6 {
+		t.Fatal(last)
+	}
+}
+
+func TestImportJSON(t *testing.T) {
+	s := testService()
+	result, err := s.ImportJSON(strings.NewReader(`[{"keyword":"test","account":"json1"},{"keyword":"test","account":"json2","status":3}]`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Imported) != 2 || len(result.Failures) != 0 {
+		t.Fatal(result)
+	}
+	if result.Imported[1].Record.Line != 2 || *result.Imported[1].Record.Status != StatusPending {
+		t.Fatal(result.Imported[1].Record)
+	}
+	_, err = ParseImportCSV(strings.NewReader("account,password\nuser,pass\n"))
+	if err != ErrImportCSVColumnMissing {
+		t.Fatal(err)
+	}
+}
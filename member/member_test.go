@@ -2,6 +2,7 @@ package member
 
 import (
 	"encoding/json"
+	"errors"
 	"io/ioutil"
 	"net/http"
 	"net/http/cookiejar"
@@ -293,6 +294,18 @@ func TestService(t *testing.T) {
 	if resp.StatusCode != 200 {
 		t.Error(resp.StatusCode)
 	}
+	err = service.Status().SetStatus(uid, StatusPending)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err = service.Password().VerifyPassword(uid, password)
+	if err != ErrUserBanned || result {
+		t.Fatal(result, err)
+	}
+	err = service.Password().Authenticate(uid, password)
+	if err != ErrNeedsVerification || !errors.Is(err, ErrUserBanned) {
+		t.Fatal(err)
+	}
 	err = service.Status().SetStatus(uid, StatusBanned)
 	if err != nil {
 		t.Fatal(err)
@@ -301,6 +314,10 @@ func TestService(t *testing.T) {
 	if err != ErrUserBanned {
 		t.Fatal(err)
 	}
+	err = service.Password().Authenticate(uid, password)
+	if err != ErrBanned || ErrorCode(err) != CodeBanned {
+		t.Fatal(err)
+	}
 
 	req, err = http.NewRequest("POST", s.URL+"/echo", nil)
 	if err != nil {
@@ -51,3 +51,11 @@ func OptionSubCache(store *session.Store, c cache.Cacheable) OptionFunc {
 		return nil
 	}
 }
+
+//OptionRealm option set application realm of service.
+func OptionRealm(realm string) OptionFunc {
+	return func(s *Service) error {
+		s.Realm = realm
+		return nil
+	}
+}
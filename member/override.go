@@ -0,0 +1,132 @@
+package member
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+//ContextNameOverride context name stores provider override name.
+var ContextNameOverride = ContextType("members-override")
+
+//ProviderOverride provider set which serves special requests instead of service providers.
+//Nil providers will not be overridden.
+type ProviderOverride struct {
+	//StatusProvider user status provider.
+	StatusProvider StatusProvider
+	//AccountsProvider user accounts provider.
+	AccountsProvider AccountsProvider
+	//TokenProvider user token provider.
+	TokenProvider TokenProvider
+	//PasswordProvider user password provider.
+	PasswordProvider PasswordProvider
+	//RoleProvider user roles provider.
+	RoleProvider RolesProvider
+	//ProfilesProviders user profiles providers.
+	ProfilesProviders []ProfilesProvider
+	//Cache cache used by overridden providers.
+	//Dummy cache will be used if nil,so that data from different providers will not be mixed.
+	Cache cache.Cacheable
+}
+
+//NewProviderOverride create new provider override.
+func NewProviderOverride() *ProviderOverride {
+	return &ProviderOverride{}
+}
+
+//ApplyTo apply provider override to service.
+func (o *ProviderOverride) ApplyTo(s *Service) {
+	if o.StatusProvider != nil {
+		s.StatusProvider = o.StatusProvider
+	}
+	if o.AccountsProvider != nil {
+		s.AccountsProvider = o.AccountsProvider
+	}
+	if o.TokenProvider != nil {
+		s.TokenProvider = o.TokenProvider
+	}
+	if o.PasswordProvider != nil {
+		s.PasswordProvider = o.PasswordProvider
+	}
+	if o.RoleProvider != nil {
+		s.RoleProvider = o.RoleProvider
+	}
+	if o.ProfilesProviders != nil {
+		s.ProfilesProviders = o.ProfilesProviders
+	}
+	if o.Cache == nil {
+		s.StatusCache = cache.Dummy()
+		s.AccountsCache = cache.Dummy()
+		s.TokenCache = cache.Dummy()
+		s.RoleCache = cache.Dummy()
+		s.DataCache = cache.Dummy()
+		return
+	}
+	s.StatusCache = cache.NewCollection(o.Cache, prefixCacheStatus, cache.DefaultTTL)
+	s.AccountsCache = cache.NewCollection(o.Cache, prefixCacheAccount, cache.DefaultTTL)
+	s.TokenCache = cache.NewCollection(o.Cache, prefixCacheToken, cache.DefaultTTL)
+	s.RoleCache = cache.NewCollection(o.Cache, prefixCacheRole, cache.DefaultTTL)
+	s.DataCache = cache.NewCollection(o.Cache, prefixCacheData, cache.DefaultTTL)
+}
+
+//RegisterOverride register provider override with given name.
+//Overridden service is created from current service when registered,
+//so override should be registered after service providers installed.
+//Registered override will be removed if given override is nil.
+//Overrides map is not guarded by lock,overrides should be registered before service serving requests.
+func (s *Service) RegisterOverride(name string, o *ProviderOverride) {
+	if o == nil {
+		delete(s.Overrides, name)
+		return
+	}
+	service := *s
+	service.Overrides = nil
+	o.ApplyTo(&service)
+	s.Overrides[name] = &service
+}
+
+//Override return service whose providers are overridden by given override name.
+//Return service itself if override not registered.
+func (s *Service) Override(name string) *Service {
+	service, ok := s.Overrides[name]
+	if !ok {
+		return s
+	}
+	return service
+}
+
+//RequestService return service which should serve given http request.
+//Return service itself if no override selected in request.
+func (s *Service) RequestService(r *http.Request) *Service {
+	if len(s.Overrides) == 0 {
+		return s
+	}
+	name, ok := r.Context().Value(ContextNameOverride).(string)
+	if !ok || name == "" {
+		return s
+	}
+	return s.Override(name)
+}
+
+//SetRequestOverride select provider override by name for given http request.
+func SetRequestOverride(r *http.Request, name string) {
+	var ctx = context.WithValue(r.Context(), ContextNameOverride, name)
+	*r = *r.WithContext(ctx)
+}
+
+//OverrideMiddleware return middleware which select provider override by given selector.
+//Request will be served by service providers if selector return empty string.
+//Middleware should be used before any members data loaded.
+func (s *Service) OverrideMiddleware(selector func(r *http.Request) (string, error)) func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	return func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		name, err := selector(r)
+		if err != nil {
+			panic(err)
+		}
+		if name != "" {
+			SetRequestOverride(r, name)
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,61 @@
+package member
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/herb-go/deprecated/cache"
+)
+
+func TestOverride(t *testing.T) {
+	s := New()
+	newTestAccountProvider().Execute(s)
+	newTestPasswordProvider().Execute(s)
+	staging := newTestPasswordProvider()
+	o := NewProviderOverride()
+	o.PasswordProvider = staging
+	s.RegisterOverride("staging", o)
+	r := httptest.NewRequest("GET", "/", nil)
+	if s.RequestService(r) != s {
+		t.Fatal(r)
+	}
+	SetRequestOverride(r, "notexists")
+	if s.RequestService(r) != s {
+		t.Fatal(r)
+	}
+	SetRequestOverride(r, "staging")
+	rs := s.RequestService(r)
+	if rs == s || rs.PasswordProvider != staging || rs.AccountsProvider != s.AccountsProvider {
+		t.Fatal(rs)
+	}
+	if rs.RequestService(r) != rs || s.RequestService(r) != rs {
+		t.Fatal(rs)
+	}
+	o.Cache = cache.Dummy()
+	s.RegisterOverride("staging", o)
+	rs = s.Override("staging")
+	if _, ok := rs.DataCache.(*cache.Collection); !ok || rs.DataCache == s.DataCache {
+		t.Fatal(rs.DataCache)
+	}
+	var served bool
+	r = httptest.NewRequest("GET", "/", nil)
+	s.OverrideMiddleware(func(r *http.Request) (string, error) {
+		return "staging", nil
+	})(httptest.NewRecorder(), r, func(w http.ResponseWriter, r *http.Request) {
+		served = true
+		if s.RequestService(r).PasswordProvider != staging {
+			t.Fatal(r)
+		}
+		if s.GetMembersFromRequest(r).Service.PasswordProvider != staging {
+			t.Fatal(r)
+		}
+	})
+	if !served {
+		t.Fatal(served)
+	}
+	s.RegisterOverride("staging", nil)
+	if s.Override("staging") != s {
+		t.Fatal(s.Overrides)
+	}
+}
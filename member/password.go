@@ -1,5 +1,7 @@
 package member
 
+import "errors"
+
 //PasswordProvider  member password provider interface
 type PasswordProvider interface {
 	VerifyPassword(uid string, password string) (bool, error)
@@ -22,8 +24,25 @@ func (s *ServicePassword) UpdatePassword(uid string, password string) error {
 }
 
 //VerifyPassword Verify user password.
+//Return ErrUserBanned if user is not avaliable,use Authenticate to get detailed status error.
 //Return verify result and any error if raised
 func (s *ServicePassword) VerifyPassword(uid string, password string) (bool, error) {
+	result, err := s.verify(uid, password)
+	if errors.Is(err, ErrUserBanned) {
+		return false, ErrUserBanned
+	}
+	return result, err
+}
+
+func (s *ServicePassword) verify(uid string, password string) (bool, error) {
+	result, err := s.verifyPassword(uid, password)
+	if err == nil || err == ErrUserNotFound || ErrorCode(err) != "" {
+		s.service.Analytics.login(err == nil && result)
+	}
+	return result, err
+}
+
+func (s *ServicePassword) verifyPassword(uid string, password string) (bool, error) {
 	result, err := s.service.PasswordProvider.VerifyPassword(uid, password)
 	if !result || err != nil {
 		return result, err
@@ -34,13 +53,31 @@ func (s *ServicePassword) VerifyPassword(uid string, password string) (bool, err
 		if err != nil {
 			return false, err
 		}
-		if !IsAvaliable(statusStore.Get(uid)) {
-			return false, ErrUserBanned
+		err = StatusError(statusStore.Get(uid))
+		if err != nil {
+			return false, err
 		}
 	}
 	return true, nil
 }
 
+//Authenticate verify user password and status.
+//Return ErrBadCredentials if user not found or password not match,
+//or status error returned by StatusError if user is not avaliable.
+func (s *ServicePassword) Authenticate(uid string, password string) error {
+	result, err := s.verify(uid, password)
+	if err == ErrUserNotFound {
+		return ErrBadCredentials
+	}
+	if err != nil {
+		return err
+	}
+	if !result {
+		return ErrBadCredentials
+	}
+	return nil
+}
+
 //PasswordChangeable return password changeable
 func (s *ServicePassword) PasswordChangeable() bool {
 	return s.service.PasswordProvider.PasswordChangeable()
@@ -0,0 +1,90 @@
+package member
+
+//PasswordChain password provider which verifies password by providers in order.
+//First provider is the primary store,later providers are legacy stores,
+//so that users can be migrated off legacy identity systems incrementally.
+type PasswordChain struct {
+	//Providers password providers in verification order.
+	//First provider is the primary provider.
+	Providers []PasswordProvider
+	//Fallthrough whether try next provider when password not match.
+	//Next provider is always tried when user not found by provider.
+	Fallthrough bool
+	//Migrate whether save password verified by non-primary provider to primary provider.
+	//Password is migrated only if primary provider is password changeable.
+	Migrate bool
+	//OnMigrateError hook called with user id and error raised when migrating password.
+	//Verification result is not affected by migration error.
+	OnMigrateError func(uid string, err error)
+}
+
+//NewPasswordChain create new password chain with given providers.
+func NewPasswordChain(providers ...PasswordProvider) *PasswordChain {
+	return &PasswordChain{
+		Providers: providers,
+	}
+}
+
+func (c *PasswordChain) migrate(uid string, password string) {
+	primary := c.Providers[0]
+	if !primary.PasswordChangeable() {
+		return
+	}
+	err := primary.UpdatePassword(uid, password)
+	if err != nil && c.OnMigrateError != nil {
+		c.OnMigrateError(uid, err)
+	}
+}
+
+//VerifyPassword verify user password by providers in order.
+//Return ErrUserNotFound if user not found by any provider.
+//Return verify result and any error if raised.
+func (c *PasswordChain) VerifyPassword(uid string, password string) (bool, error) {
+	var found bool
+	for k, p := range c.Providers {
+		result, err := p.VerifyPassword(uid, password)
+		if err == ErrUserNotFound {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		found = true
+		if result {
+			if k > 0 && c.Migrate {
+				c.migrate(uid, password)
+			}
+			return true, nil
+		}
+		if !c.Fallthrough {
+			return false, nil
+		}
+	}
+	if !found {
+		return false, ErrUserNotFound
+	}
+	return false, nil
+}
+
+//PasswordChangeable return password changeable of primary provider.
+func (c *PasswordChain) PasswordChangeable() bool {
+	if len(c.Providers) == 0 {
+		return false
+	}
+	return c.Providers[0].PasswordChangeable()
+}
+
+//UpdatePassword update user password in primary provider.
+//Return any error if raised
+func (c *PasswordChain) UpdatePassword(uid string, password string) error {
+	if len(c.Providers) == 0 {
+		return ErrFeatureNotSupported
+	}
+	return c.Providers[0].UpdatePassword(uid, password)
+}
+
+//Execute apply password chain to service as password provider.
+func (c *PasswordChain) Execute(service *Service) error {
+	service.PasswordProvider = c
+	return nil
+}
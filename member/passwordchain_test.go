@@ -0,0 +1,118 @@
+package member
+
+import (
+	"errors"
+	"testing"
+)
+
+type testChainPasswordProvider struct {
+	testPasswordProvider
+	changeable bool
+	err        error
+}
+
+func (p *testChainPasswordProvider) PasswordChangeable() bool {
+	return p.changeable
+}
+
+func (p *testChainPasswordProvider) VerifyPassword(uid string, password string) (bool, error) {
+	if p.err != nil {
+		return false, p.err
+	}
+	if _, ok := p.Passwords[uid]; !ok {
+		return false, ErrUserNotFound
+	}
+	return p.testPasswordProvider.VerifyPassword(uid, password)
+}
+
+func (p *testChainPasswordProvider) UpdatePassword(uid string, password string) error {
+	if p.err != nil {
+		return p.err
+	}
+	return p.testPasswordProvider.UpdatePassword(uid, password)
+}
+
+func newTestChainPasswordProvider(changeable bool) *testChainPasswordProvider {
+	return &testChainPasswordProvider{
+		testPasswordProvider: *newTestPasswordProvider(),
+		changeable:           changeable,
+	}
+}
+
+type migrateFailedPasswordProvider struct {
+	*testChainPasswordProvider
+	updateErr error
+}
+
+func (p *migrateFailedPasswordProvider) UpdatePassword(uid string, password string) error {
+	return p.updateErr
+}
+
+func TestPasswordChain(t *testing.T) {
+	primary := newTestChainPasswordProvider(true)
+	legacy := newTestChainPasswordProvider(false)
+	primary.Passwords["both"] = "new"
+	legacy.Passwords["both"] = "old"
+	legacy.Passwords["legacy"] = "legacy"
+	c := NewPasswordChain(primary, legacy)
+	s := New()
+	c.Execute(s)
+	if s.PasswordProvider != c || !c.PasswordChangeable() {
+		t.Fatal(s.PasswordProvider)
+	}
+	result, err := c.VerifyPassword("notexist", "password")
+	if result || err != ErrUserNotFound {
+		t.Fatal(result, err)
+	}
+	result, err = c.VerifyPassword("both", "old")
+	if result || err != nil {
+		t.Fatal(result, err)
+	}
+	c.Fallthrough = true
+	result, err = c.VerifyPassword("both", "old")
+	if !result || err != nil {
+		t.Fatal(result, err)
+	}
+	result, err = c.VerifyPassword("legacy", "wrong")
+	if result || err != nil {
+		t.Fatal(result, err)
+	}
+	result, err = c.VerifyPassword("legacy", "legacy")
+	if !result || err != nil || primary.Passwords["legacy"] != "" {
+		t.Fatal(result, err, primary.Passwords)
+	}
+	c.Migrate = true
+	result, err = c.VerifyPassword("legacy", "legacy")
+	if !result || err != nil || primary.Passwords["legacy"] != "legacy" {
+		t.Fatal(result, err, primary.Passwords)
+	}
+	errTest := errors.New("test error")
+	var migrateErr error
+	c.OnMigrateError = func(uid string, err error) {
+		migrateErr = err
+	}
+	legacy.Passwords["failed"] = "failed"
+	primary.err = errTest
+	c.Fallthrough = false
+	result, err = c.VerifyPassword("failed", "failed")
+	if result || err != errTest {
+		t.Fatal(result, err)
+	}
+	primary.err = nil
+	failing := newTestChainPasswordProvider(true)
+	failing.Passwords["other"] = "other"
+	c.Providers = []PasswordProvider{&migrateFailedPasswordProvider{failing, errTest}, legacy}
+	result, err = c.VerifyPassword("failed", "failed")
+	if !result || err != nil || migrateErr != errTest {
+		t.Fatal(result, err, migrateErr)
+	}
+	c.Providers = []PasswordProvider{primary, legacy}
+	primary.err = errTest
+	err = c.UpdatePassword("both", "password")
+	if err != errTest {
+		t.Fatal(err)
+	}
+	if NewPasswordChain().UpdatePassword("uid", "password") != ErrFeatureNotSupported {
+		t.Fatal()
+	}
+}
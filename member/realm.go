@@ -0,0 +1,75 @@
+package member
+
+import (
+	"strings"
+
+	"github.com/herb-go/deprecated/cache"
+	"github.com/herb-go/user"
+)
+
+//RealmSeparator separator between realm and account keyword.
+var RealmSeparator = "/"
+
+//RealmStatusProvider optional status provider interface which stores user statuses per realm.
+type RealmStatusProvider interface {
+	//RealmStatuses return status map of given uid list in given realm.
+	//Users without realm status should not be included.
+	//Return status map and any error if raised.
+	RealmStatuses(realm string, uid ...string) (StatusMap, error)
+	//SetRealmStatus set user status in given realm.
+	//Return any error if raised.
+	SetRealmStatus(realm string, uid string, status Status) error
+}
+
+func (s *Service) realmKeyword(keyword string) string {
+	return s.Realm + RealmSeparator + keyword
+}
+
+//RealmAccount return account scoped in service realm,which is passed to accounts provider.
+//Return given account if service realm is empty.
+func (s *Service) RealmAccount(account *user.Account) *user.Account {
+	if s.Realm == "" || account == nil {
+		return account
+	}
+	scoped := *account
+	scoped.Keyword = s.realmKeyword(account.Keyword)
+	return &scoped
+}
+
+//realmAccounts return accounts in service realm with realm removed from keyword.
+func (s *Service) realmAccounts(accounts user.Accounts) user.Accounts {
+	if s.Realm == "" {
+		return accounts
+	}
+	prefix := s.realmKeyword("")
+	result := user.Accounts{}
+	for _, v := range accounts {
+		if v == nil || !strings.HasPrefix(v.Keyword, prefix) {
+			continue
+		}
+		account := *v
+		account.Keyword = strings.TrimPrefix(v.Keyword, prefix)
+		result = append(result, &account)
+	}
+	return result
+}
+
+//realmCache return cache scoped in service realm.
+func (s *Service) realmCache(c cache.Cacheable) cache.Cacheable {
+	if s.Realm == "" {
+		return c
+	}
+	return cache.NewNode(c, s.Realm)
+}
+
+//realmStatusProvider return realm status provider if service realm is not empty and status provider supports realms.
+func (s *Service) realmStatusProvider() RealmStatusProvider {
+	if s.Realm == "" {
+		return nil
+	}
+	p, ok := s.StatusProvider.(RealmStatusProvider)
+	if !ok {
+		return nil
+	}
+	return p
+}
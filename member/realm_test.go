@@ -0,0 +1,109 @@
+package member
+
+import (
+	"testing"
+
+	"github.com/herb-go/user"
+)
+
+type testRealmStatusService struct {
+	*testStatusService
+	RealmStatusMap map[string]StatusMap
+}
+
+func (s *testRealmStatusService) RealmStatuses(realm string, uid ...string) (StatusMap, error) {
+	var r = StatusMap{}
+	for _, v := range uid {
+		status, ok := s.RealmStatusMap[realm][v]
+		if ok {
+			r[v] = status
+		}
+	}
+	return r, nil
+}
+
+func (s *testRealmStatusService) SetRealmStatus(realm string, uid string, status Status) error {
+	if s.RealmStatusMap[realm] == nil {
+		s.RealmStatusMap[realm] = StatusMap{}
+	}
+	s.RealmStatusMap[realm][uid] = status
+	return nil
+}
+
+func TestRealm(t *testing.T) {
+	accounts := newTestAccountProvider()
+	statuses := &testRealmStatusService{
+		testStatusService: newTestStatusProvider(),
+		RealmStatusMap:    map[string]StatusMap{},
+	}
+	app1 := New()
+	app1.Init(OptionRealm("app1"))
+	app1.AccountsProvider = accounts
+	app1.StatusProvider = statuses
+	app1.RegisterAccountProvider("test", user.CaseSensitiveAcountProvider)
+	app2 := New()
+	app2.Init(OptionRealm("app2"))
+	app2.AccountsProvider = accounts
+	app2.StatusProvider = statuses
+	app2.RegisterAccountProvider("test", user.CaseSensitiveAcountProvider)
+	account, err := app1.NewAccount("test", "account")
+	if err != nil {
+		t.Fatal(err)
+	}
+	uid, err := app1.Accounts().Register(account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored := accounts.AccountsMap[uid][0]; stored.Keyword != "app1"+RealmSeparator+"test" {
+		t.Fatal(stored)
+	}
+	found, err := app2.Accounts().AccountToUID(account)
+	if err != nil || found != "" {
+		t.Fatal(found, err)
+	}
+	uid2, err := app2.Accounts().Register(account)
+	if err != nil || uid2 == uid {
+		t.Fatal(uid2, err)
+	}
+	err = app2.Accounts().BindAccount(uid, account)
+	if err != user.ErrAccountBindingExists {
+		t.Fatal(err)
+	}
+	store := NewAccountsStore()
+	err = app1.Accounts().Load(store, uid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := store.Get(uid); len(a) != 1 || a[0].Keyword != "test" || a[0].Account != "account" {
+		t.Fatal(a)
+	}
+	store = NewAccountsStore()
+	err = app2.Accounts().Load(store, uid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := store.Get(uid); len(a) != 0 {
+		t.Fatal(a)
+	}
+	statuses.StatusMap[uid] = StatusNormal
+	err = app1.Status().SetStatus(uid, StatusBanned)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss := NewStatusStore()
+	err = app1.Status().Load(ss, uid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := ss.Get(uid); *s != StatusBanned {
+		t.Fatal(*s)
+	}
+	ss = NewStatusStore()
+	err = app2.Status().Load(ss, uid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := ss.Get(uid); *s != StatusNormal {
+		t.Fatal(*s)
+	}
+}
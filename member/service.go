@@ -22,6 +22,7 @@ const prefixCacheStatus = "S"
 const prefixCacheAccount = "A"
 const prefixCacheToken = "T"
 const prefixCacheRole = "R"
+const prefixCacheData = "D"
 
 //DefaultSessionUIDFieldName default user id session field name when create member service.
 const DefaultSessionUIDFieldName = "herb-member-uid"
@@ -77,6 +78,20 @@ type Service struct {
 	ProfilesProviders []ProfilesProvider
 	//AccountProviders registered account provider map.
 	AccountProviders map[string]user.AccountProvider
+	//Overrides services overridden by registered provider overrides by override name.
+	Overrides map[string]*Service
+	//Realm application realm which scopes account keywords and user statuses,
+	//so that one user store can serve multiple applications.
+	//Empty realm means no scoping.
+	Realm string
+	//OnUserExpired hook called with user id when user transitioned to expired status by SweepExpired.
+	OnUserExpired func(uid string)
+	//Analytics anonymized analytics counter.
+	//Analytics is disabled if nil.
+	Analytics *Analytics
+	//IDEncoder encoder which converts user ids to external ids exposed to clients.
+	//User ids are exposed as is if nil.
+	IDEncoder IDEncoder
 }
 
 func (s *Service) Reset() {
@@ -92,6 +107,11 @@ func (s *Service) Reset() {
 	s.RoleProvider = nil
 	s.DataProviders = map[string]*datastore.DataSource{}
 	s.AccountProviders = map[string]user.AccountProvider{}
+	s.Overrides = map[string]*Service{}
+	s.OnUserExpired = nil
+	s.Analytics = nil
+	s.IDEncoder = nil
+	s.Realm = ""
 	s.StatusCache = cache.Dummy()
 	s.AccountsCache = cache.Dummy()
 	s.TokenCache = cache.Dummy()
@@ -194,7 +214,7 @@ func (s *Service) GetMembersFromRequest(r *http.Request) (members *Members) {
 			return members
 		}
 	}
-	members = NewMembers(s)
+	members = NewMembers(s.RequestService(r))
 	var ctx = context.WithValue(r.Context(), contextName, members)
 	*r = *r.WithContext(ctx)
 	return members
@@ -222,6 +242,7 @@ func (s *Service) MemberTokenField() *session.Field {
 //Return user id and any error raised.
 //If user is not logged in,returned user id will by empty string.
 func (s *Service) IdentifyRequest(r *http.Request) (uid string, err error) {
+	s = s.RequestService(r)
 	uid, err = s.UIDField().IdentifyRequest(r)
 	if err != nil {
 		return "", err
@@ -303,6 +324,7 @@ func (s *Service) RolesAuthorizeMiddleware(ruleNames ...string) func(w http.Resp
 
 //Login login giver user to http request
 func (s *Service) Login(w http.ResponseWriter, r *http.Request, id string) error {
+	s = s.RequestService(r)
 	err := s.UIDField().Login(w, r, id)
 	if err != nil {
 		return err
@@ -331,6 +353,7 @@ func New() *Service {
 	return &Service{
 		DataProviders:    map[string]*datastore.DataSource{},
 		AccountProviders: map[string]user.AccountProvider{},
+		Overrides:        map[string]*Service{},
 		StatusCache:      cache.Dummy(),
 		AccountsCache:    cache.Dummy(),
 		TokenCache:       cache.Dummy(),
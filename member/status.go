@@ -95,6 +95,7 @@ type ServiceStatus struct {
 }
 
 //Load load and cache user  status from provider.
+//Realm status will be used if user is normal in provider and service realm status is avaliable.
 //Return any error if raised.
 func (s *ServiceStatus) Load(statusMap datastore.Store, keys ...string) error {
 	return datastore.Load(
@@ -111,7 +112,7 @@ func (s *ServiceStatus) Load(statusMap datastore.Store, keys ...string) error {
 
 //Cache Return member  status cache.
 func (s *ServiceStatus) Cache() cache.Cacheable {
-	return s.service.StatusCache
+	return s.service.realmCache(s.service.StatusCache)
 }
 
 //Clean clean  status cache by uid.
@@ -120,6 +121,7 @@ func (s *ServiceStatus) Clean(uid string) error {
 }
 
 //SetStatus set user  status.
+//Status will be set in service realm if service realm is not empty and status provider implements RealmStatusProvider.
 //user status cache will be cleand.
 //Return any error if raised.
 func (s *ServiceStatus) SetStatus(uid string, status Status) error {
@@ -127,10 +129,18 @@ func (s *ServiceStatus) SetStatus(uid string, status Status) error {
 	if !ok {
 		return ErrStatusNotSupport
 	}
-	err := s.service.StatusProvider.SetStatus(uid, status)
+	var err error
+	if p := s.service.realmStatusProvider(); p != nil {
+		err = p.SetRealmStatus(s.service.Realm, uid, status)
+	} else {
+		err = s.service.StatusProvider.SetStatus(uid, status)
+	}
 	if err != nil {
 		return err
 	}
+	if status == StatusBanned {
+		s.service.Analytics.banned()
+	}
 	return s.Clean(uid)
 
 }
@@ -141,6 +151,17 @@ func (s *ServiceStatus) loader(keys ...string) (map[string]interface{}, error) {
 	if err != nil {
 		return result, err
 	}
+	if p := s.service.realmStatusProvider(); p != nil {
+		realmdata, err := p.RealmStatuses(s.service.Realm, keys...)
+		if err != nil {
+			return result, err
+		}
+		for k, v := range realmdata {
+			if status, ok := data[k]; ok && status == StatusNormal {
+				data[k] = v
+			}
+		}
+	}
 	result = map[string]interface{}{}
 	for k := range data {
 		v := data[k]
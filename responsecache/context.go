@@ -2,6 +2,7 @@ package responsecache
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"time"
 
@@ -45,7 +46,7 @@ func (c ContextField) ServeMiddleware(w http.ResponseWriter, r *http.Request, ne
 			return page, nil
 		})
 		if err != nil {
-			if err != cache.ErrEntryTooLarge && err != cache.ErrNotCacheable {
+			if !errors.Is(err, cache.ErrEntryTooLarge) && !errors.Is(err, cache.ErrNotCacheable) {
 				panic(err)
 			}
 			return
@@ -6,6 +6,7 @@ package session
 import (
 	"crypto/md5"
 	"encoding/hex"
+	"errors"
 	"net/url"
 	"time"
 
@@ -128,7 +129,7 @@ func (s *CacheDriver) ConvertPrefix(prefix string) (output string, err error) {
 func (s *CacheDriver) Load(v *Session) (err error) {
 	token := v.token
 	bytes, err := s.Cache.GetBytesValue(token)
-	if err == cache.ErrNotFound {
+	if errors.Is(err, cache.ErrNotFound) {
 		err = ErrDataNotFound
 	}
 	if err != nil {